@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/store"
+)
+
+// changeLog records the changes of an atomic run in the issue providers, so they can be undone when
+// an item fails, see rollback.
+type changeLog struct {
+	issues   []store.Record   // Issues created, in the order they were created
+	projects []createdProject // Projects created by --create-missing-projects
+	epic     *epicSource      // Epic broken down by --from-issue, with its original body
+	removed  int              // Issues deleted or closed by the rollback, for the run summary
+}
+
+// createdProject is a project created by the run, and the provider it was created in.
+type createdProject struct {
+	issues  provider.Provider
+	name    string
+	project *provider.ProjectInfo
+}
+
+// rollback undoes the changes of a failed atomic run, last first: the issues created are deleted,
+// or closed when the provider cannot delete them, the projects created are deleted and the body of
+// the epic broken down is restored. It returns the records of the issues rolled back and the
+// changes that must be undone by hand.
+func (g *generator) rollback(ctx context.Context) ([]store.Record, []string) {
+	var rolledBack []store.Record
+	var manual []string
+	for _, record := range slices.Backward(g.atomic.issues) {
+		target, ok := g.target(record.Provider)
+		if !ok {
+			continue
+		}
+		if record.Number == 0 {
+			// Draft items are only referenced by their project item
+			manual = append(manual, fmt.Sprintf("%s %s %q", record.Provider, record.Kind, record.Title))
+			continue
+		}
+		var err error
+		action := "deleted"
+		switch p := target.provider.(type) {
+		case provider.IssueDeleter:
+			err = p.DeleteIssue(ctx, record.Number)
+		case provider.IssueCloser:
+			action = "closed"
+			err = p.CloseIssue(ctx, record.Number)
+		default:
+			err = fmt.Errorf("the provider cannot delete or close issues")
+		}
+		if err != nil {
+			slog.Error("failed to roll back issue", "provider", record.Provider, "number", record.Number, "error", err)
+			manual = append(manual, fmt.Sprintf("%s #%d %q", record.Provider, record.Number, record.Title))
+			continue
+		}
+		slog.Info("issue rolled back", "provider", record.Provider, "kind", record.Kind, "number", record.Number, "action", action)
+		rolledBack = append(rolledBack, record)
+	}
+
+	for _, created := range slices.Backward(g.atomic.projects) {
+		deleter, ok := created.issues.(provider.ProjectDeleter)
+		if !ok {
+			manual = append(manual, fmt.Sprintf("project %q", created.name))
+			continue
+		}
+		if err := deleter.DeleteProject(ctx, created.project); err != nil {
+			slog.Error("failed to roll back project", "project", created.name, "error", err)
+			manual = append(manual, fmt.Sprintf("project %q", created.name))
+			continue
+		}
+		slog.Info("project rolled back", "project", created.name)
+	}
+
+	if epic := g.atomic.epic; epic != nil && g.epics[epic.target] != nil && len(g.epics[epic.target].stories) > 0 {
+		target, _ := g.target(epic.target)
+		if _, err := target.provider.EditIssue(ctx, epic.issue.GetNumber(), "", epic.issue.GetBody()); err != nil {
+			slog.Error("failed to restore the epic body", "provider", epic.target, "number", epic.issue.GetNumber(), "error", err)
+			manual = append(manual, fmt.Sprintf("%s body of epic #%d", epic.target, epic.issue.GetNumber()))
+		}
+	}
+	return rolledBack, manual
+}
+
+// target returns the issue target with the given provider name.
+func (g *generator) target(name string) (issueTarget, bool) {
+	for _, t := range g.targets {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return issueTarget{}, false
+}
+
+// rollBackRun rolls back a failed atomic run and removes its issues from the mapping store. It
+// returns an error listing the changes left in the trackers, nil when the rollback is complete.
+func (g *generator) rollBackRun(ctx context.Context) error {
+	slog.Warn("atomic run failed, rolling back", "issues", len(g.atomic.issues), "projects", len(g.atomic.projects))
+	rolledBack, manual := g.rollback(ctx)
+	g.atomic.removed = len(rolledBack)
+	if g.state != nil && len(rolledBack) > 0 {
+		if err := g.state.Remove(ctx, rolledBack...); err != nil {
+			slog.Warn("failed to remove the rolled back issues from the mapping store", "error", err)
+		}
+	}
+	slog.Info("run rolled back", "issues", len(rolledBack))
+	if len(manual) > 0 {
+		return fmt.Errorf("failed to roll back, remove them by hand: %s", strings.Join(manual, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/document"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/version"
+	"github.com/spf13/cobra"
+)
+
+var breakdownCmd = &cobra.Command{
+	Use:   "breakdown",
+	Short: "Turn a product requirements document into epics, stories and tasks",
+	Long: `Read a product requirements document (Markdown, text, Word or PDF), ask the LLM to propose a
+tree of epics, User Stories and tasks, and create the issues after the proposal is approved. The
+stories are linked to their epics as in the hierarchy mode of generate.`,
+	RunE: runBreakdown,
+}
+
+func init() {
+	rootCmd.AddCommand(breakdownCmd)
+	breakdownCmd.Flags().String("doc", "", "Path to the product requirements document (.md, .txt, .docx or .pdf)")
+	breakdownCmd.Flags().StringP("language", "g", "english", "Language to generate the content (e.g., english, portuguese)")
+	breakdownCmd.Flags().String("criteria-format", string(prompt.CriteriaGherkin), "Acceptance criteria format: gherkin (Given/When/Then), checklist (rendered as a task list) or bullets")
+	breakdownCmd.Flags().StringSlice("provider", nil, "Issue providers to create the items in, comma separated (github, gitea, asana, redmine, azure, console); defaults to github when GITHUB_* variables are set, console otherwise")
+	breakdownCmd.Flags().String("console-output", string(provider.ConsoleText), "Output of the console provider: text, pretty (boxed and colored), json (one record per line) or yaml")
+	breakdownCmd.Flags().BoolP("yes", "y", false, "Create the proposed backlog without asking for approval")
+	if err := breakdownCmd.MarkFlagRequired("doc"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'doc' flag as required: %v", err))
+	}
+}
+
+// runBreakdown proposes the backlog of a document and creates it once approved.
+func runBreakdown(cmd *cobra.Command, _ []string) error {
+	docPath, _ := cmd.Flags().GetString("doc")
+	language, _ := cmd.Flags().GetString("language")
+	criteriaFormat, _ := cmd.Flags().GetString("criteria-format")
+	providerNames, _ := cmd.Flags().GetStringSlice("provider")
+	consoleOutput, _ := cmd.Flags().GetString("console-output")
+	approved, _ := cmd.Flags().GetBool("yes")
+	if !prompt.CriteriaFormat(criteriaFormat).IsValid() {
+		return fmt.Errorf("invalid criteria format: %s (expected gherkin, checklist or bullets)", criteriaFormat)
+	}
+
+	doc, err := document.Extract(docPath)
+	if err != nil {
+		return err
+	}
+	llmProvider, err := llm.NewProvider(newLLMConfig())
+	if err != nil {
+		return err
+	}
+	planner, ok := llmProvider.(llm.Planner)
+	if !ok {
+		return fmt.Errorf("the LLM provider cannot propose a backlog (supported by openai, bedrock and mock)")
+	}
+	targets, err := newIssueTargets(providerNames, consoleOutput)
+	if err != nil {
+		return err
+	}
+
+	slog.Info("proposing backlog", "doc", docPath, "characters", len(doc))
+	plan, usage, err := planner.Plan(cmd.Context(), doc, language, prompt.CriteriaFormat(criteriaFormat))
+	if err != nil {
+		return err
+	}
+	slog.Info("backlog proposed", "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+
+	out := cmd.OutOrStdout()
+	printPlan(out, plan)
+	if !approved {
+		if approved, err = confirmPlan(cmd.InOrStdin(), out, plan); err != nil {
+			return err
+		}
+		if !approved {
+			_, _ = fmt.Fprintln(out, "Aborted, no issues were created.")
+			return nil
+		}
+	}
+
+	items, contents := planItems(plan, filepath.Base(docPath))
+	g := &generator{
+		llm:            llm.NewStaticProvider(contents),
+		targets:        targets,
+		language:       language,
+		autoTasks:      true,
+		hierarchy:      true,
+		epics:          make(map[string]*epicRef),
+		criteriaFormat: prompt.CriteriaFormat(criteriaFormat),
+		headings:       i18n.For(language, appConfig.Headings),
+		noEmoji:        noEmoji,
+	}
+	return createItems(cmd, g, docPath, items, consoleOutput)
+}
+
+// createItems runs the generator over items read from source rather than a spreadsheet, recording
+// the run in the state database and printing the run summary.
+func createItems(cmd *cobra.Command, g *generator, source string, items []reader.Item, consoleOutput string) error {
+	var err error
+	if g.policy, err = newPolicyEngine(); err != nil {
+		return err
+	}
+	if g.preHooks, err = newHookRunner(appConfig.Hooks.PreGenerate); err != nil {
+		return err
+	}
+	if g.hooks, err = newHookRunner(appConfig.Hooks.PostCreate); err != nil {
+		return err
+	}
+	if g.computed, err = newComputedFields(appConfig.Computed); err != nil {
+		return err
+	}
+	if stateDB != "" {
+		if g.state, err = store.Open(stateDB); err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := g.state.Close(); cerr != nil {
+				slog.Warn("failed to close state database", "error", cerr)
+			}
+		}()
+	}
+
+	g.runID, g.source = store.NewRunID(), source
+	startedAt := time.Now()
+	if g.state != nil {
+		if err := g.state.StartRun(cmd.Context(), store.Run{ID: g.runID, Source: source, StartedAt: startedAt, Version: version.Get().Short()}); err != nil {
+			return err
+		}
+	}
+	rows, err := reader.Stream(itemsReader(items))
+	if err != nil {
+		return err
+	}
+	runErr := g.run(cmd.Context(), rows, errorPolicyFail)
+	printRunSummary(summaryWriter(cmd, g.targets, consoleOutput), g.runID, g, time.Since(startedAt))
+	if g.state != nil {
+		status := store.StatusCompleted
+		if runErr != nil {
+			status = store.StatusFailed
+		}
+		if err := g.state.FinishRun(context.WithoutCancel(cmd.Context()), g.runID, status); err != nil {
+			slog.Warn("failed to record run status", "run_id", g.runID, "error", err)
+		}
+	}
+	return runErr
+}
+
+// printPlan prints the proposed backlog as a numbered tree.
+func printPlan(w io.Writer, plan *llm.Plan) {
+	_, _ = fmt.Fprintln(w, "Proposed backlog:")
+	for i, epic := range plan.Epics {
+		_, _ = fmt.Fprintf(w, "\n%d. [Epic] %s\n", i+1, epic.Title)
+		for j, story := range epic.Stories {
+			estimate := ""
+			if story.Estimate > 0 {
+				estimate = fmt.Sprintf(" (%d points)", story.Estimate)
+			}
+			_, _ = fmt.Fprintf(w, "   %d.%d [User Story] %s%s\n", i+1, j+1, story.Title, estimate)
+			for _, task := range story.Tasks {
+				_, _ = fmt.Fprintf(w, "        - [Task] %s\n", task)
+			}
+		}
+	}
+	_, _ = fmt.Fprintln(w)
+}
+
+// confirmPlan asks whether the proposed backlog is created.
+func confirmPlan(in io.Reader, out io.Writer, plan *llm.Plan) (bool, error) {
+	epics, stories, tasks := plan.Counts()
+	return confirm(bufio.NewScanner(in), out, fmt.Sprintf("Create %d epics, %d stories and %d tasks?", epics, stories, tasks))
+}
+
+// confirm asks a yes or no question, which needs an explicit yes.
+func confirm(scanner *bufio.Scanner, out io.Writer, question string) (bool, error) {
+	_, _ = fmt.Fprintf(out, "%s [y/N]: ", question)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return false, err
+		}
+		return false, nil
+	}
+	answer := strings.ToLower(strings.TrimSpace(scanner.Text()))
+	return answer == "y" || answer == "yes", nil
+}
+
+// planItems converts a plan into the rows of a hierarchy run, each epic followed by its stories,
+// along with the content of each row by row ID.
+func planItems(plan *llm.Plan, source string) ([]reader.Item, map[string]*llm.GeneratedContent) {
+	var items []reader.Item
+	contents := map[string]*llm.GeneratedContent{}
+	add := func(itemType prompt.ItemType, content *llm.GeneratedContent) {
+		ref := reader.SourceRef{File: source, Row: len(items) + 1}
+		id := strconv.Itoa(ref.Row)
+		content.Type = itemType.String()
+		items = append(items, reader.Item{ID: id, Source: ref, Type: itemType, Context: content.Title})
+		contents[id] = content
+	}
+	for _, epic := range plan.Epics {
+		add(prompt.Epic, &llm.GeneratedContent{Title: epic.Title, Description: epic.Description, AcceptanceCriteria: epic.AcceptanceCriteria})
+		for _, story := range epic.Stories {
+			add(prompt.UserStory, &llm.GeneratedContent{
+				Title:              story.Title,
+				Description:        story.Description,
+				AcceptanceCriteria: story.AcceptanceCriteria,
+				SuggestedTasks:     story.Tasks,
+				Estimate:           story.Estimate,
+			})
+		}
+	}
+	return items, contents
+}
+
+// itemsReader reads items built by a command, such as the rows of a plan.
+type itemsReader []reader.Item
+
+func (r itemsReader) Read() ([]reader.Item, error) {
+	return r, nil
+}
@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// chatHelp lists the commands of the chat.
+const chatHelp = `Discuss the issue with the LLM and ask for changes; the proposed versions are kept as a draft.
+Commands:
+  /show   print the draft of the issue
+  /apply  update the issue with the draft
+  /quit   leave the chat (asks to apply pending changes)
+  /help   print this help`
+
+var chatCmd = &cobra.Command{
+	Use:   "chat",
+	Short: "Refine an existing issue in a conversation with the LLM",
+	Long: `Open an interactive chat about an existing issue, where the LLM answers questions and proposes new
+versions of the title and body as you ask for changes. The agreed version is written to the issue
+with /apply.`,
+	RunE: runChat,
+}
+
+func init() {
+	rootCmd.AddCommand(chatCmd)
+	chatCmd.Flags().Int("issue", 0, "Number of the issue to refine")
+	chatCmd.Flags().String("provider", providerGitHub, "Issue provider where the issue is (github)")
+	chatCmd.Flags().StringP("language", "g", "english", "Language of the conversation and the changes (e.g., english, portuguese)")
+	if err := chatCmd.MarkFlagRequired("issue"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'issue' flag as required: %v", err))
+	}
+}
+
+// runChat reads the issue and runs the chat until the user quits.
+func runChat(cmd *cobra.Command, _ []string) error {
+	number, _ := cmd.Flags().GetInt("issue")
+	providerName, _ := cmd.Flags().GetString("provider")
+	language, _ := cmd.Flags().GetString("language")
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	getter, ok := issues.(provider.IssueGetter)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot read issues (supported by github)", providerName)
+	}
+	llmProvider, err := llm.NewProvider(newLLMConfig())
+	if err != nil {
+		return err
+	}
+	chatter, ok := llmProvider.(llm.Chatter)
+	if !ok {
+		return fmt.Errorf("the LLM provider cannot chat (supported by openai, bedrock and mock)")
+	}
+
+	issue, err := getter.GetIssue(cmd.Context(), number)
+	if err != nil {
+		return fmt.Errorf("failed to read the issue: %w", err)
+	}
+	chat := llm.NewIssueChat(chatter, issue.GetTitle(), issue.GetBody(), language)
+	applied := issueDraft{title: issue.GetTitle(), body: issue.GetBody()}
+
+	in := bufio.NewScanner(cmd.InOrStdin())
+	out := cmd.OutOrStdout()
+	_, _ = fmt.Fprintf(out, "Chatting about #%d: %s\n%s\n", number, issue.GetTitle(), chatHelp)
+	for {
+		_, _ = fmt.Fprint(out, "\n> ")
+		if !in.Scan() {
+			if err := in.Err(); err != nil {
+				return err
+			}
+			break
+		}
+		text := strings.TrimSpace(in.Text())
+		switch text {
+		case "":
+			continue
+		case "/help":
+			_, _ = fmt.Fprintln(out, chatHelp)
+			continue
+		case "/show":
+			printDraft(out, chat)
+			continue
+		case "/apply":
+			if err := applyDraft(cmd, issues, number, chat, &applied); err != nil {
+				return err
+			}
+			continue
+		case "/quit", "/exit":
+		default:
+			reply, err := chat.Send(cmd.Context(), text)
+			if err != nil {
+				_, _ = fmt.Fprintf(out, "Error: %v\n", err)
+				continue
+			}
+			_, _ = fmt.Fprintln(out, reply.Message)
+			if reply.Changed() {
+				_, _ = fmt.Fprintln(out, "(draft updated: /show to review it, /apply to update the issue)")
+			}
+			continue
+		}
+		break
+	}
+
+	if applied.title != chat.Title || applied.body != chat.Body {
+		approved, err := confirm(in, out, "\nThe draft has changes that were not applied. Apply them?")
+		if err != nil {
+			return err
+		}
+		if approved {
+			if err := applyDraft(cmd, issues, number, chat, &applied); err != nil {
+				return err
+			}
+		}
+	}
+	_, _ = fmt.Fprintf(out, "Chat used %d prompt and %d completion tokens.\n", chat.Usage.PromptTokens, chat.Usage.CompletionTokens)
+	return nil
+}
+
+// issueDraft is a version of an issue.
+type issueDraft struct {
+	title string
+	body  string
+}
+
+// printDraft prints the draft of the issue.
+func printDraft(w io.Writer, chat *llm.IssueChat) {
+	_, _ = fmt.Fprintf(w, "Title: %s\n\n%s\n", chat.Title, chat.Body)
+}
+
+// applyDraft updates the issue with the draft, sending only the fields changed since the last
+// applied version.
+func applyDraft(cmd *cobra.Command, issues provider.Provider, number int, chat *llm.IssueChat, applied *issueDraft) error {
+	var title, body string
+	if chat.Title != applied.title {
+		title = chat.Title
+	}
+	if chat.Body != applied.body {
+		body = chat.Body
+	}
+	if title == "" && body == "" {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "Nothing to apply, the issue is up to date.")
+		return nil
+	}
+	issue, err := issues.EditIssue(cmd.Context(), number, title, body)
+	if err != nil {
+		return fmt.Errorf("failed to update the issue: %w", err)
+	}
+	*applied = issueDraft{title: chat.Title, body: chat.Body}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Updated %s\n", issue.GetHTMLURL())
+	return nil
+}
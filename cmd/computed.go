@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/expr"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/reader"
+)
+
+// computedVariables are the variables of the computed field expressions: the columns of the row
+// and, as Title, Description, AcceptanceCriteria, Tasks and Estimate, the generated content.
+var computedVariables = []string{
+	"Type", "Parent", "Context", "Criteria", "Labels", "Assignees", "Milestone", "Priority", "Repository",
+	"Sensitivity", "Status", "Extra", "Row", "Title", "Description", "AcceptanceCriteria", "Tasks", "Estimate",
+}
+
+// computedFields derives the title, labels and project of the issues of an item from the
+// expressions of the configuration file.
+type computedFields struct {
+	title   *expr.Program
+	labels  []*expr.Program
+	project *expr.Program
+}
+
+// computedValues are the fields derived for an item, empty when not computed.
+type computedValues struct {
+	title   string
+	labels  []string
+	project string
+}
+
+// newComputedFields compiles the expressions of the computed fields, returning nil when there are
+// none.
+func newComputedFields(c config.Computed) (*computedFields, error) {
+	if c.Title == "" && len(c.Labels) == 0 && c.Project == "" {
+		return nil, nil
+	}
+	f := &computedFields{}
+	compile := func(field, source string) (*expr.Program, error) {
+		if source == "" {
+			return nil, nil
+		}
+		p, err := expr.Compile(source, computedVariables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load computed %s: %w", field, err)
+		}
+		return p, nil
+	}
+	var err error
+	if f.title, err = compile("title", c.Title); err != nil {
+		return nil, err
+	}
+	if f.project, err = compile("project", c.Project); err != nil {
+		return nil, err
+	}
+	for _, source := range c.Labels {
+		p, err := compile("labels", source)
+		if err != nil {
+			return nil, err
+		}
+		f.labels = append(f.labels, p)
+	}
+	slog.Debug("computed fields loaded", "title", f.title != nil, "labels", len(f.labels), "project", f.project != nil)
+	return f, nil
+}
+
+// eval evaluates the expressions for an item and its generated content. A nil computedFields
+// computes nothing.
+func (f *computedFields) eval(item reader.Item, content *llm.GeneratedContent) (computedValues, error) {
+	var values computedValues
+	if f == nil {
+		return values, nil
+	}
+	env := expr.Env{
+		"Type":               item.Type.String(),
+		"Parent":             item.Parent,
+		"Context":            item.Context,
+		"Criteria":           item.Criteria,
+		"Labels":             item.Labels,
+		"Assignees":          item.Assignees,
+		"Milestone":          item.Milestone,
+		"Priority":           item.Priority,
+		"Repository":         item.Repository,
+		"Sensitivity":        item.Sensitivity,
+		"Status":             item.Status,
+		"Extra":              item.Extra,
+		"Row":                item.Source.Row,
+		"Title":              content.Title,
+		"Description":        content.Description,
+		"AcceptanceCriteria": content.AcceptanceCriteria,
+		"Tasks":              content.SuggestedTasks,
+		"Estimate":           content.Estimate,
+	}
+
+	var err error
+	if f.title != nil {
+		if values.title, err = f.title.StringValue(env); err != nil {
+			return values, fmt.Errorf("computed title: %w", err)
+		}
+	}
+	if f.project != nil {
+		if values.project, err = f.project.StringValue(env); err != nil {
+			return values, fmt.Errorf("computed project: %w", err)
+		}
+	}
+	for _, p := range f.labels {
+		labels, err := p.Strings(env)
+		if err != nil {
+			return values, fmt.Errorf("computed labels: %w", err)
+		}
+		for _, label := range labels {
+			if !slices.Contains(values.labels, label) {
+				values.labels = append(values.labels, label)
+			}
+		}
+	}
+	return values, nil
+}
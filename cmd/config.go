@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Check the configuration of aigile",
+	Long:  `Inspect the configuration file, the environment variables and the flags of a run before starting it.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the configuration file, the LLM and issue provider variables and the input of a run, reporting all the problems at once",
+	Long: `Check the configuration file, the LLM_* variables, the variables of the issue providers and, when given,
+the input of a run, and report all the problems at once with the way to fix them. generate runs the same
+checks before starting.`,
+	Annotations: map[string]string{deferConfigValidation: "true", skipPromptsResolution: "true"},
+	RunE:        runConfigValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+	configValidateCmd.Flags().StringP("file", "f", "", "Path to the XLSX file or Google Sheets URL of the run")
+	configValidateCmd.Flags().String("google-credentials-file", "", "Path to the Google Service Account credentials JSON file of the run")
+	configValidateCmd.Flags().StringSlice("provider", nil, "Issue providers of the run, comma separated (github, gitea, asana, redmine, azure, console); defaults to github when GITHUB_* variables are set, console otherwise")
+}
+
+// runConfigValidate reports the problems of the configuration file and of the settings of a run.
+func runConfigValidate(cmd *cobra.Command, _ []string) error {
+	file, _ := cmd.Flags().GetString("file")
+	googleCredentials, _ := cmd.Flags().GetString("google-credentials-file")
+	providers, _ := cmd.Flags().GetStringSlice("provider")
+
+	var problems config.Problems
+	if err := appConfig.Validate(); err != nil && !errors.As(err, &problems) {
+		return err
+	}
+	problems = append(problems, validateSettings(runSettings{file: file, googleCredentials: googleCredentials, providers: providers})...)
+	if err := problems.Err(); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "The configuration is valid.")
+	return nil
+}
+
+// runSettings are the flags of a run checked along with the environment.
+type runSettings struct {
+	file              string // XLSX file or Google Sheets URL, empty when the run does not read one
+	googleCredentials string
+	providers         []string // Names given to --provider, empty for the default
+}
+
+// validateSettings checks the settings of a run that the configuration file cannot check on its
+// own: the input, the LLM_* variables, the variables of the issue providers and the computed field
+// expressions. It returns all the problems found rather than the first one.
+func validateSettings(s runSettings) config.Problems {
+	var problems config.Problems
+	validateInput(&problems, s)
+	validateLLM(&problems, "LLM_", newLLMConfig())
+	if local := newLocalLLMConfig(); local != nil {
+		validateLLM(&problems, "LLM_LOCAL_", *local)
+	}
+	if value := os.Getenv("LLM_CONTEXT_WINDOW"); value != "" {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			problems.Add("LLM_CONTEXT_WINDOW", fmt.Sprintf("invalid number of tokens %q", value), "set it to the context window of the model, e.g. 128000")
+		}
+	}
+	if value := os.Getenv("LLM_VISION"); value != "" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			problems.Add("LLM_VISION", fmt.Sprintf("invalid boolean %q", value), "set it to true or false")
+		}
+	}
+	validateIssueProviders(&problems, s.providers)
+	if _, err := newComputedFields(appConfig.Computed); err != nil {
+		problems.Add("computed", strings.TrimPrefix(err.Error(), "failed to load computed "), "see the Computed Fields section of the README for the variables and functions")
+	}
+	return problems
+}
+
+// validateInput checks the file of a run and the credentials it needs.
+func validateInput(problems *config.Problems, s runSettings) {
+	if s.googleCredentials != "" {
+		if _, err := os.Stat(s.googleCredentials); err != nil {
+			problems.Add("--google-credentials-file", fmt.Sprintf("file not found: %s", s.googleCredentials), "")
+		}
+	}
+	switch {
+	case s.file == "":
+	case strings.HasPrefix(s.file, "https://docs.google.com/spreadsheets/"):
+		if s.googleCredentials == "" {
+			problems.Add("--google-credentials-file", "is required to read a Google Sheets URL",
+				"pass the JSON key file of a service account the spreadsheet is shared with")
+		}
+	default:
+		if _, err := os.Stat(s.file); err != nil {
+			problems.Add("--file", fmt.Sprintf("file not found: %s", s.file), "")
+		}
+	}
+}
+
+// validateLLM checks the configuration of an LLM provider read from the variables with the prefix,
+// LLM_ or LLM_LOCAL_.
+func validateLLM(problems *config.Problems, prefix string, c llm.Config) {
+	switch c.Provider {
+	case "", "openai":
+		if c.Endpoint != "" {
+			if u, err := url.Parse(c.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				problems.Add(prefix+"ENDPOINT", fmt.Sprintf("invalid URL %q", c.Endpoint), "use the base URL of the API, e.g. http://localhost:11434/v1")
+				return
+			}
+		}
+		switch {
+		case llm.IsAzureEndpoint(c.Endpoint):
+			if c.APIKey == "" {
+				problems.Add(prefix+"API_KEY", "is required for Azure OpenAI", "set it to a key of the Azure OpenAI resource")
+			}
+			if c.Model == "" {
+				problems.Add(prefix+"MODEL", "is required for Azure OpenAI", "set it to the name of the deployment of the model")
+			}
+		case c.Endpoint == "" && c.APIKey == "":
+			problems.Add(prefix+"API_KEY", "is required for the OpenAI API",
+				"set it to an OpenAI API key, or set "+prefix+"ENDPOINT to an OpenAI-compatible API such as Ollama")
+		}
+	case "bedrock":
+		if c.Model == "" {
+			problems.Add(prefix+"MODEL", "is required for Amazon Bedrock", "set it to the ID of a model enabled in the account, e.g. anthropic.claude-3-5-sonnet-20240620-v1:0")
+		}
+	case "replay":
+		if c.Dir == "" {
+			problems.Add("LLM_REPLAY_DIR", "is required for the replay provider", "set it to the --record-dir of a previous run")
+		} else if _, err := os.Stat(c.Dir); err != nil {
+			problems.Add("LLM_REPLAY_DIR", fmt.Sprintf("directory not found: %s", c.Dir), "")
+		}
+	case "mock":
+	default:
+		problems.Add(prefix+"PROVIDER", fmt.Sprintf("unsupported LLM provider %q", c.Provider), "use openai (also for Azure OpenAI and compatible APIs), bedrock, replay or mock")
+	}
+}
+
+// providerVariables are the environment variables required by each issue provider. A group of
+// several names requires at least one of them.
+var providerVariables = map[string][][]string{
+	providerGitHub:      {{"GITHUB_TOKEN"}, {"GITHUB_OWNER"}, {"GITHUB_REPO"}},
+	providerGitea:       {{"GITEA_URL"}, {"GITEA_TOKEN"}, {"GITEA_OWNER"}, {"GITEA_REPO"}},
+	providerForgejo:     {{"GITEA_URL"}, {"GITEA_TOKEN"}, {"GITEA_OWNER"}, {"GITEA_REPO"}},
+	providerAsana:       {{"ASANA_TOKEN"}, {"ASANA_WORKSPACE", "ASANA_PROJECT"}},
+	providerRedmine:     {{"REDMINE_URL"}, {"REDMINE_API_KEY"}},
+	providerAzure:       {{"AZURE_DEVOPS_TOKEN"}, {"AZURE_DEVOPS_ORG", "AZURE_DEVOPS_URL"}, {"AZURE_DEVOPS_PROJECT"}},
+	providerAzureDevOps: {{"AZURE_DEVOPS_TOKEN"}, {"AZURE_DEVOPS_ORG", "AZURE_DEVOPS_URL"}, {"AZURE_DEVOPS_PROJECT"}},
+	providerConsole:     {},
+}
+
+// variableFixes are the suggested fixes of the missing provider variables.
+var variableFixes = map[string]string{
+	"GITHUB_TOKEN":       "create a token with the repo, project and read:org scopes",
+	"GITHUB_OWNER":       "set it to the user or organization owning the repository",
+	"GITHUB_REPO":        "set it to the name of the repository",
+	"GITEA_TOKEN":        "create an access token with the write:issue and read:repository scopes",
+	"ASANA_TOKEN":        "create a personal access token in the developer console of Asana",
+	"REDMINE_API_KEY":    "copy the API access key of the account page of Redmine",
+	"AZURE_DEVOPS_ORG":   "set it to the name of the organization, or AZURE_DEVOPS_URL to the URL of an Azure DevOps Server collection",
+	"AZURE_DEVOPS_TOKEN": "create a personal access token with the Work Items (Read & write) scope",
+}
+
+// validateIssueProviders checks the variables of the issue providers of a run.
+func validateIssueProviders(problems *config.Problems, names []string) {
+	if len(names) == 0 {
+		// A partial GitHub configuration silently falls back to the console
+		var missing []string
+		for _, group := range providerVariables[providerGitHub] {
+			if os.Getenv(group[0]) == "" {
+				missing = append(missing, group[0])
+			}
+		}
+		if len(missing) > 0 && len(missing) < len(providerVariables[providerGitHub]) {
+			for _, name := range missing {
+				problems.Add(name, "is not set, so the issues are printed in the console instead of created in GitHub",
+					"set GITHUB_TOKEN, GITHUB_OWNER and GITHUB_REPO, or pass --provider console")
+			}
+		}
+		return
+	}
+
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		groups, ok := providerVariables[name]
+		if !ok {
+			problems.Add("--provider", fmt.Sprintf("unsupported issue provider %q", name), "use github, gitea, forgejo, asana, redmine, azure or console")
+			continue
+		}
+		for _, group := range groups {
+			set := false
+			for _, variable := range group {
+				set = set || os.Getenv(variable) != ""
+			}
+			if !set {
+				problems.Add(strings.Join(group, " or "), fmt.Sprintf("is required for the %s provider", name), variableFixes[group[0]])
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/document"
+	"github.com/leocomelli/aigile/internal/index"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/similar"
+)
+
+// docRetriever retrieves the excerpts of the product documentation most relevant to each item, so
+// the generated stories are grounded in what the product actually does.
+type docRetriever struct {
+	embeddings *issueEmbeddings
+	chunks     map[string][]document.Chunk // Chunks of each document, by path
+	index      *similar.Index              // Chunks by their number in the document, with the path as URL
+	limit      int
+	minScore   float64
+}
+
+// newDocRetriever loads and indexes the documentation at paths, embedding only the chunks of the
+// documents that changed since the last run.
+func newDocRetriever(ctx context.Context, paths []string, limit int, minScore float64) (*docRetriever, error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid number of documentation excerpts: %d (expected a positive number)", limit)
+	}
+	if minScore < 0 || minScore > 1 {
+		return nil, fmt.Errorf("invalid documentation minimum score: %v (expected a similarity between 0 and 1)", minScore)
+	}
+	chunks, err := document.Load(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, fmt.Errorf("no documentation found in %s", strings.Join(paths, ", "))
+	}
+	embeddings, err := openIssueEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &docRetriever{embeddings: embeddings, chunks: chunks, index: &similar.Index{}, limit: limit, minScore: minScore}
+	files := make([]string, 0, len(chunks))
+	for file := range chunks {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var total index.Stats
+	for _, file := range files {
+		scope := docScope(file)
+		entries := make([]index.Issue, len(chunks[file]))
+		numbers := make([]int, len(chunks[file]))
+		for i, chunk := range chunks[file] {
+			entries[i] = index.Issue{Number: i, Title: chunk.Heading, Body: chunk.Text, URL: file}
+			numbers[i] = i
+		}
+		ix, stats, err := embeddings.store.Refresh(ctx, scope, entries, embeddings.embedder, embeddings.model)
+		if err != nil {
+			embeddings.Close()
+			return nil, err
+		}
+		if _, err := embeddings.store.Prune(ctx, scope, numbers); err != nil {
+			embeddings.Close()
+			return nil, err
+		}
+		r.index.Merge(ix)
+		total.Embedded += stats.Embedded
+		total.Tokens += stats.Tokens
+	}
+	slog.Info("documentation indexed", "documents", len(files), "chunks", r.index.Len(), "embedded", total.Embedded, "tokens", total.Tokens)
+	return r, nil
+}
+
+// retrieve returns the excerpts of the documentation most similar to the item, each with the
+// document and heading it comes from. Failures are logged and the item is generated without them,
+// as are internal-only rows when the embeddings are not computed locally.
+func (r *docRetriever) retrieve(ctx context.Context, item reader.Item) []string {
+	if r == nil {
+		return nil
+	}
+	if !r.embeddings.allows(item) {
+		slog.Debug("documentation not retrieved for internal-only row", "row", item.ID)
+		return nil
+	}
+	vector, err := r.embeddings.embed(ctx, similar.Text(item.Parent, item.Context+"\n"+strings.Join(item.Criteria, "\n")))
+	if err != nil {
+		slog.Warn("failed to retrieve documentation", "row", item.ID, "error", err)
+		return nil
+	}
+	matches := r.index.Nearest(vector, r.limit, r.minScore)
+	excerpts := make([]string, 0, len(matches))
+	for _, m := range matches {
+		chunk := r.chunks[m.URL][m.Number]
+		source := chunk.Source
+		if chunk.Heading != "" {
+			source += ", " + chunk.Heading
+		}
+		excerpts = append(excerpts, fmt.Sprintf("From %s:\n%s", source, chunk.Text))
+		slog.Debug("documentation retrieved", "row", item.ID, "source", source, "score", m.Score)
+	}
+	return excerpts
+}
+
+// close closes the index database.
+func (r *docRetriever) close() {
+	r.embeddings.Close()
+}
+
+// docScope is the index scope of the chunks of a document, by its absolute path, so editing a
+// document only embeds its own chunks again.
+func docScope(file string) string {
+	if abs, err := filepath.Abs(file); err == nil {
+		file = abs
+	}
+	return "docs:" + file
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/similar"
+)
+
+// Actions on the likely duplicates of the generated items.
+const (
+	duplicatesOff  = "off"
+	duplicatesWarn = "warn"
+	duplicatesSkip = "skip"
+)
+
+// duplicateChecker compares the generated items with the open issues of each target by the
+// similarity of their embeddings, so likely duplicates are flagged instead of created blindly.
+type duplicateChecker struct {
+	embeddings *issueEmbeddings
+	threshold  float64
+	action     string
+	indexes    map[string]*similar.Index // Open issues of each target, nil when they cannot be listed
+}
+
+// newDuplicateChecker creates the checker of the action, nil when the check is off.
+func newDuplicateChecker(action string, threshold float64) (*duplicateChecker, error) {
+	switch action {
+	case duplicatesOff:
+		return nil, nil
+	case duplicatesWarn, duplicatesSkip:
+	default:
+		return nil, fmt.Errorf("invalid duplicates action: %s (expected off, warn or skip)", action)
+	}
+	if threshold <= 0 || threshold > 1 {
+		return nil, fmt.Errorf("invalid duplicate threshold: %v (expected a similarity between 0 and 1)", threshold)
+	}
+	embeddings, err := openIssueEmbeddings()
+	if err != nil {
+		return nil, err
+	}
+	return &duplicateChecker{embeddings: embeddings, threshold: threshold, action: action, indexes: map[string]*similar.Index{}}, nil
+}
+
+// find returns the open issue of the target most similar to the content, nil when none reaches the
+// threshold, along with the embedding of the content, to index the issue created for it.
+func (d *duplicateChecker) find(ctx context.Context, target issueTarget, content *llm.GeneratedContent) (*similar.Match, []float32, error) {
+	index, err := d.index(ctx, target)
+	if err != nil || index == nil {
+		return nil, nil, err
+	}
+	vector, err := d.embeddings.embed(ctx, similar.Text(content.Title, content.Description+"\n"+strings.Join(content.AcceptanceCriteria, "\n")))
+	if err != nil {
+		return nil, nil, err
+	}
+	if matches := index.Nearest(vector, 1, d.threshold); len(matches) > 0 {
+		return &matches[0], vector, nil
+	}
+	return nil, vector, nil
+}
+
+// add indexes an issue created in the target, so the next items are compared with it too, and
+// stores its embedding for the next runs.
+func (d *duplicateChecker) add(ctx context.Context, target string, issue provider.Issue, vector []float32) {
+	if index := d.indexes[target]; index != nil && vector != nil {
+		index.Add(similar.Document{Number: issue.GetNumber(), Title: issue.GetTitle(), URL: issue.GetHTMLURL(), Vector: vector})
+		d.embeddings.put(ctx, target, issue, vector)
+	}
+}
+
+// close closes the index database.
+func (d *duplicateChecker) close() {
+	d.embeddings.Close()
+}
+
+// index returns the index of the open issues of a target, embedding them on first use. Targets
+// that cannot list or embed their issues are not checked, the error is only returned once.
+func (d *duplicateChecker) index(ctx context.Context, target issueTarget) (*similar.Index, error) {
+	if index, ok := d.indexes[target.name]; ok {
+		return index, nil
+	}
+	lister, ok := target.provider.(provider.IssueLister)
+	if !ok {
+		slog.Warn("the provider cannot list issues, duplicates are not checked", "provider", target.name)
+		d.indexes[target.name] = nil
+		return nil, nil
+	}
+	d.indexes[target.name] = nil
+	issues, err := lister.ListIssues(ctx, provider.IssueFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	index, err := d.embeddings.index(ctx, target.name, issues)
+	if err != nil {
+		return nil, err
+	}
+	d.indexes[target.name] = index
+	return index, nil
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/index"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/similar"
+)
+
+// issueEmbeddings embeds the issues of the providers, reusing the embeddings stored in the index
+// database configured by --index-db for the issues that did not change.
+type issueEmbeddings struct {
+	embedder llm.Embedder
+	model    string // Provider and model of the embeddings, stored with them to detect a model change
+	local    bool   // The provider keeps the data on premises, so internal-only rows can be embedded
+	store    *index.Index
+}
+
+// openIssueEmbeddings creates the provider of the embeddings, configured by newEmbeddingConfig,
+// and opens the index database.
+func openIssueEmbeddings() (*issueEmbeddings, error) {
+	config := newEmbeddingConfig()
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	embedder, ok := p.(llm.Embedder)
+	if !ok {
+		return nil, fmt.Errorf("the LLM provider cannot compute embeddings (supported by openai and mock), set LLM_EMBEDDING_PROVIDER")
+	}
+	store, err := index.Open(indexDB)
+	if err != nil {
+		return nil, err
+	}
+	return &issueEmbeddings{embedder: embedder, model: embeddingModel(config), local: llm.IsLocal(config), store: store}, nil
+}
+
+// allows reports whether the content of an item may be sent to the provider of the embeddings:
+// internal-only rows are only embedded by a local provider.
+func (e *issueEmbeddings) allows(item reader.Item) bool {
+	return e.local || !strings.EqualFold(strings.TrimSpace(item.Sensitivity), llm.SensitivityInternalOnly)
+}
+
+// Close closes the index database.
+func (e *issueEmbeddings) Close() {
+	if err := e.store.Close(); err != nil {
+		slog.Warn("failed to close index database", "error", err)
+	}
+}
+
+// index returns the similarity index of the issues of a provider, embedding the ones new or
+// changed since they were stored.
+func (e *issueEmbeddings) index(ctx context.Context, name string, issues []provider.Issue) (*similar.Index, error) {
+	scope := issueScope(name)
+	ix, stats, err := e.store.Refresh(ctx, scope, indexIssues(issues), e.embedder, e.model)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("issues indexed", "scope", scope, "issues", ix.Len(), "embedded", stats.Embedded, "tokens", stats.Tokens)
+	return ix, nil
+}
+
+// embed returns the embedding of a text.
+func (e *issueEmbeddings) embed(ctx context.Context, text string) ([]float32, error) {
+	vectors, _, err := e.embedder.Embed(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// put stores the embedding of an issue created by the run.
+func (e *issueEmbeddings) put(ctx context.Context, name string, issue provider.Issue, vector []float32) {
+	if err := e.store.Put(ctx, issueScope(name), e.model, indexIssues([]provider.Issue{issue})[0], vector); err != nil {
+		slog.Warn("failed to store embedding", "number", issue.GetNumber(), "error", err)
+	}
+}
+
+// indexIssues converts the issues of a provider to the issues of the index.
+func indexIssues(issues []provider.Issue) []index.Issue {
+	converted := make([]index.Issue, len(issues))
+	for i, issue := range issues {
+		converted[i] = index.Issue{Number: issue.GetNumber(), Title: issue.GetTitle(), Body: issue.GetBody(), URL: issue.GetHTMLURL()}
+	}
+	return converted
+}
+
+// embeddingModel identifies the provider and model of the embeddings, e.g. openai/text-embedding-3-small.
+func embeddingModel(config llm.Config) string {
+	name, model := config.Provider, config.EmbeddingModel
+	if name == "" {
+		name = "openai"
+	}
+	if model == "" {
+		model = llm.DefaultEmbeddingModel
+	}
+	if name == "mock" {
+		return name
+	}
+	return name + "/" + model
+}
+
+// issueScope identifies the repository of an issue provider, which the issue numbers belong to.
+func issueScope(name string) string {
+	switch name {
+	case providerGitHub:
+		scope := os.Getenv("GITHUB_OWNER") + "/" + os.Getenv("GITHUB_REPO")
+		if baseURL := os.Getenv("GITHUB_API_URL"); baseURL != "" {
+			scope = strings.TrimSuffix(baseURL, "/") + "/" + scope
+		}
+		return name + ":" + scope
+	case providerGitea, providerForgejo:
+		return name + ":" + strings.TrimSuffix(os.Getenv("GITEA_URL"), "/") + "/" + os.Getenv("GITEA_OWNER") + "/" + os.Getenv("GITEA_REPO")
+	case providerRedmine:
+		return name + ":" + strings.TrimSuffix(os.Getenv("REDMINE_URL"), "/") + "/" + os.Getenv("REDMINE_PROJECT")
+	case providerAsana:
+		return name + ":" + os.Getenv("ASANA_WORKSPACE") + "/" + os.Getenv("ASANA_PROJECT")
+	case providerAzure, providerAzureDevOps:
+		return name + ":" + strings.TrimSuffix(azureDevOpsURL(), "/") + "/" + os.Getenv("AZURE_DEVOPS_PROJECT")
+	}
+	return name
+}
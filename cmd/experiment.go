@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"text/tabwriter"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/quality"
+)
+
+// variantLabelPrefix is the prefix of the label with the prompt variant of an experiment.
+const variantLabelPrefix = "prompt-variant:"
+
+// newExperiment creates an A/B experiment between the provider of the current prompts (A) and a
+// provider chain built the same way with the prompts of dir (B).
+func newExperiment(variantA llm.Provider, config llm.Config, localConfig *llm.Config, dir string, wrap func(llm.Provider) (llm.Provider, error)) (*llm.ExperimentProvider, error) {
+	if config.Provider == "mock" || config.Provider == "replay" {
+		slog.Warn("the LLM provider does not use prompts, both variants of the experiment will be the same", "provider", config.Provider)
+	}
+	config.PromptsDir = dir
+	if localConfig != nil {
+		c := *localConfig
+		c.PromptsDir = dir
+		localConfig = &c
+	}
+	variantB, err := llm.NewProviderWithPolicy(config, localConfig, wrap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create experiment variant B: %w", err)
+	}
+	slog.Info("running prompt experiment", "variant_a", promptsDirName(promptsDir), "variant_b", dir)
+	return llm.NewExperimentProvider(quality.NewChecker(),
+		llm.Variant{Name: "A", Provider: variantA},
+		llm.Variant{Name: "B", Provider: variantB})
+}
+
+// promptsDirName describes the prompts of a directory, which may be empty for the built-in ones.
+func promptsDirName(dir string) string {
+	if dir == "" {
+		return "builtin"
+	}
+	return dir
+}
+
+// printExperimentReport prints the comparison of the quality scores of the experiment variants.
+func printExperimentReport(out io.Writer, reports []llm.VariantReport) {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VARIANT\tGENERATED\tFAILED\tMEAN SCORE\tMIN\tMAX")
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%d\t%d\n", r.Name, r.Generated, r.Failed, r.MeanScore, r.MinScore, r.MaxScore)
+	}
+	if err := w.Flush(); err != nil {
+		slog.Warn("failed to print experiment report", "error", err)
+	}
+}
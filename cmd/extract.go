@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/leocomelli/aigile/internal/document"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/spf13/cobra"
+)
+
+// evidenceColumn is the X- column where the quote supporting each candidate story is written.
+const evidenceColumn = "Evidence"
+
+var extractCmd = &cobra.Command{
+	Use:   "extract",
+	Short: "Extract candidate User Stories from a meeting transcript or notes",
+	Long: `Read a meeting transcript or notes file (text, Markdown, WebVTT or SubRip captions, Word or PDF), ask
+the LLM for the User Stories discussed and write them to an XLSX file in the input format of generate,
+with the quote of the transcript supporting each story, so they can be reviewed and edited before
+generating the issues. With --generate, the issues are created after the candidates are approved.`,
+	RunE: runExtract,
+}
+
+func init() {
+	rootCmd.AddCommand(extractCmd)
+	extractCmd.Flags().String("transcript", "", "Path to the meeting transcript or notes (.txt, .md, .vtt, .srt, .docx or .pdf)")
+	extractCmd.Flags().StringP("output", "o", "backlog.xlsx", "XLSX file where the candidate stories are written")
+	extractCmd.Flags().StringP("language", "g", "english", "Language to generate the content (e.g., english, portuguese)")
+	extractCmd.Flags().Bool("generate", false, "Generate and create the issues of the candidate stories after they are approved")
+	extractCmd.Flags().BoolP("yes", "y", false, "With --generate, create the issues without asking for approval")
+	extractCmd.Flags().Bool("auto-tasks", false, "With --generate, automatically generate and create tasks for each user story")
+	extractCmd.Flags().String("criteria-format", string(prompt.CriteriaGherkin), "Acceptance criteria format: gherkin (Given/When/Then), checklist (rendered as a task list) or bullets")
+	extractCmd.Flags().StringSlice("provider", nil, "Issue providers to create the items in, comma separated (github, gitea, asana, redmine, azure, console); defaults to github when GITHUB_* variables are set, console otherwise")
+	extractCmd.Flags().String("console-output", string(provider.ConsoleText), "Output of the console provider: text, pretty (boxed and colored), json (one record per line) or yaml")
+	if err := extractCmd.MarkFlagRequired("transcript"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'transcript' flag as required: %v", err))
+	}
+}
+
+// runExtract writes the candidate stories of a transcript and generates their issues when asked.
+func runExtract(cmd *cobra.Command, _ []string) error {
+	transcriptPath, _ := cmd.Flags().GetString("transcript")
+	output, _ := cmd.Flags().GetString("output")
+	language, _ := cmd.Flags().GetString("language")
+	generate, _ := cmd.Flags().GetBool("generate")
+	approved, _ := cmd.Flags().GetBool("yes")
+	autoTasks, _ := cmd.Flags().GetBool("auto-tasks")
+	criteriaFormat, _ := cmd.Flags().GetString("criteria-format")
+	providerNames, _ := cmd.Flags().GetStringSlice("provider")
+	consoleOutput, _ := cmd.Flags().GetString("console-output")
+	if !prompt.CriteriaFormat(criteriaFormat).IsValid() {
+		return fmt.Errorf("invalid criteria format: %s (expected gherkin, checklist or bullets)", criteriaFormat)
+	}
+
+	transcript, err := document.Extract(transcriptPath)
+	if err != nil {
+		return err
+	}
+	llmProvider, err := llm.NewProvider(newLLMConfig())
+	if err != nil {
+		return err
+	}
+	extractor, ok := llmProvider.(llm.StoryExtractor)
+	if !ok {
+		return fmt.Errorf("the LLM provider cannot extract stories (supported by openai, bedrock and mock)")
+	}
+
+	slog.Info("extracting stories", "transcript", transcriptPath, "characters", len(transcript))
+	candidates, usage, err := extractor.ExtractStories(cmd.Context(), transcript, language)
+	if err != nil {
+		return err
+	}
+	slog.Info("stories extracted", "stories", len(candidates), "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+
+	out := cmd.OutOrStdout()
+	if len(candidates) == 0 {
+		_, _ = fmt.Fprintln(out, "No stories were found in the transcript.")
+		return nil
+	}
+	if err := reader.WriteXLSX(output, candidateItems(candidates)); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(out, "Wrote %d candidate stories to %s.\n", len(candidates), output)
+	if !generate {
+		_, _ = fmt.Fprintf(out, "Review the file and create the issues with: aigile generate --file %s\n", output)
+		return nil
+	}
+
+	printCandidates(out, candidates)
+	if !approved {
+		if approved, err = confirm(bufio.NewScanner(cmd.InOrStdin()), out, fmt.Sprintf("Generate %d stories?", len(candidates))); err != nil {
+			return err
+		}
+		if !approved {
+			_, _ = fmt.Fprintf(out, "Aborted, no issues were created. Review the file and create the issues with: aigile generate --file %s\n", output)
+			return nil
+		}
+	}
+
+	// The issues are generated from the written file, as generate would, so the state and the
+	// source references match a later run over the reviewed file.
+	items, err := reader.NewXLSXReader(output).Read()
+	if err != nil {
+		return err
+	}
+	targets, err := newIssueTargets(providerNames, consoleOutput)
+	if err != nil {
+		return err
+	}
+	g := &generator{
+		llm:            llmProvider,
+		targets:        targets,
+		language:       language,
+		autoTasks:      autoTasks,
+		epics:          make(map[string]*epicRef),
+		criteriaFormat: prompt.CriteriaFormat(criteriaFormat),
+		headings:       i18n.For(language, appConfig.Headings),
+		noEmoji:        noEmoji,
+	}
+	return createItems(cmd, g, output, items, consoleOutput)
+}
+
+// candidateItems converts the candidate stories into User Story rows, keeping the supporting quote
+// in the Evidence column.
+func candidateItems(candidates []llm.StoryCandidate) []reader.Item {
+	items := make([]reader.Item, 0, len(candidates))
+	for _, c := range candidates {
+		item := reader.Item{Type: prompt.UserStory, Parent: c.Parent, Context: c.Context, Criteria: c.Criteria}
+		if c.Evidence != "" {
+			item.Extra = map[string]string{evidenceColumn: c.Evidence}
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// printCandidates prints the candidate stories with the quote supporting each one.
+func printCandidates(w io.Writer, candidates []llm.StoryCandidate) {
+	_, _ = fmt.Fprintln(w, "Candidate stories:")
+	for i, c := range candidates {
+		_, _ = fmt.Fprintf(w, "\n%d. [%s] %s\n", i+1, c.Parent, c.Context)
+		for _, criterion := range c.Criteria {
+			_, _ = fmt.Fprintf(w, "     - %s\n", criterion)
+		}
+		if c.Evidence != "" {
+			_, _ = fmt.Fprintf(w, "     > %s\n", c.Evidence)
+		}
+	}
+	_, _ = fmt.Fprintln(w)
+}
@@ -2,18 +2,52 @@
 package cmd
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/leocomelli/aigile/internal/emoji"
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/leocomelli/aigile/internal/hook"
+	"github.com/leocomelli/aigile/internal/i18n"
 	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/policy"
+	"github.com/leocomelli/aigile/internal/prompt"
 	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/quality"
 	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/redact"
+	"github.com/leocomelli/aigile/internal/report"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/telemetry"
+	"github.com/leocomelli/aigile/internal/testgen"
+	"github.com/leocomelli/aigile/internal/title"
+	"github.com/leocomelli/aigile/internal/version"
 	"github.com/spf13/cobra"
 )
 
+// Error policies supported by the --on-error flag.
+const (
+	errorPolicyFail     = "fail"
+	errorPolicyContinue = "continue"
+)
+
+// Policies supported by the --on-oversized-prompt flag.
+const (
+	promptPolicyWarn = "warn"
+	promptPolicyFail = "fail"
+)
+
 var generateCmd = &cobra.Command{
 	Use:   "generate",
 	Short: "Generate items from XLSX file",
@@ -27,9 +61,49 @@ func init() {
 	generateCmd.Flags().StringP("language", "g", "english", "Language to generate the content (e.g., english, portuguese)")
 	generateCmd.Flags().Bool("auto-tasks", false, "Automatically generate and create tasks for each user story")
 	generateCmd.Flags().String("google-credentials-file", "", "Path to Google Service Account credentials JSON file (required for Google Sheets)")
-	if err := generateCmd.MarkFlagRequired("file"); err != nil {
-		panic(fmt.Sprintf("failed to mark 'file' flag as required: %v", err))
-	}
+	generateCmd.Flags().Duration("item-timeout", 0, "Maximum time to generate and create a single item, e.g. 2m (0 disables the limit)")
+	generateCmd.Flags().Duration("run-timeout", 0, "Maximum time for the whole run, e.g. 30m (0 disables the limit)")
+	generateCmd.Flags().String("on-error", errorPolicyFail, "What to do when an item fails or times out: fail (stop the run) or continue (move on to the next item)")
+	generateCmd.Flags().String("title-template", "", "Derive the issue titles from a template instead of the LLM, e.g. \"[{{.Parent}}] {{.Summary}}\"; variables: {{.Type}}, {{.Parent}}, {{.Context}}, {{.Summary}} and {{.Row}}")
+	generateCmd.Flags().Bool("strict", false, "Fail on the first invalid row (unknown type or missing columns) instead of skipping it with a warning")
+	generateCmd.Flags().StringP("output", "o", "", "Write an XLSX file with the input rows, the generated content, the created issues and the status of each row")
+	generateCmd.Flags().String("report-html", "", "Write a standalone HTML report of the run, grouped by epic or parent, to share with stakeholders")
+	generateCmd.Flags().String("record-dir", "", "Directory where each LLM generation is saved as <row>.json, to be edited and replayed with LLM_PROVIDER=replay")
+	generateCmd.Flags().StringSlice("provider", nil, "Issue providers to create the items in, comma separated (github, gitea, asana, redmine, azure, console); defaults to github when GITHUB_* variables are set, console otherwise")
+	generateCmd.Flags().String("console-output", string(provider.ConsoleText), "Output of the console provider: text, pretty (boxed and colored), json (one record per line) or yaml")
+	generateCmd.Flags().String("criteria-format", string(prompt.CriteriaGherkin), "Acceptance criteria format: gherkin (Given/When/Then), checklist (rendered as a task list) or bullets")
+	generateCmd.Flags().Bool("task-list", false, "Render the created tasks as a task list (- [ ] #number) in the user story body, in addition to sub-issues")
+	generateCmd.Flags().Bool("qa-checklist", false, "Create a QA checklist issue for each User Story, with a checkbox per acceptance criterion referencing the story, as a sub-issue of the story")
+	generateCmd.Flags().Bool("test-skeletons", false, "Open a pull request for each User Story with a Gherkin feature file generated from its acceptance criteria, referencing the story (github only)")
+	generateCmd.Flags().String("test-dir", "features", "Directory of the repository where the feature files of --test-skeletons are added")
+	generateCmd.Flags().String("duplicates", duplicatesOff, "Compare each generated story with the open issues by embeddings: off, warn (create it linking the likely duplicate) or skip (do not create it)")
+	generateCmd.Flags().Float64("duplicate-threshold", 0.85, "Similarity, from 0 to 1, from which an open issue is a likely duplicate of a generated story")
+	generateCmd.Flags().StringSlice("docs", nil, "Directories or files of product documentation (Markdown, text, Word or PDF), e.g. docs or a clone of the wiki, whose excerpts most relevant to each item are added to its prompt")
+	generateCmd.Flags().Int("docs-excerpts", 3, "Maximum number of documentation excerpts added to the prompt of each item")
+	generateCmd.Flags().Float64("docs-min-score", 0.3, "Similarity, from 0 to 1, from which a documentation excerpt is relevant to an item")
+	generateCmd.Flags().Bool("check-grounding", false, "Flag the generated items mentioning features or systems not found in the row, the documentation excerpts or the glossary, with a warning in the issue that they need human verification")
+	generateCmd.Flags().String("glossary", "", "Text file with the names of the product features and systems, one per line, known to the grounding check (implies --check-grounding)")
+	generateCmd.Flags().Bool("drafts", false, "Create the items as draft items of the project named in Parent (GitHub Projects v2) instead of issues, except the rows with the ready Status; rows with the draft Status are drafts without it")
+	generateCmd.Flags().String("parent-strategy", parentProject, "What the Parent column links the issues to: project (board name), epic-issue (number of an existing epic, e.g. #123), milestone (title), none, or auto (#123 is an epic, milestone:<title> a milestone, anything else a project)")
+	generateCmd.Flags().Bool("create-missing-projects", false, "Create the project named in Parent (a Project v2 of the repository owner in GitHub, a project of the workspace in Asana) when it does not exist, instead of creating the issues without a project")
+	generateCmd.Flags().Bool("split", false, "Ask the LLM whether the context of each User Story row describes several independent stories and, if so, create an issue for each of them")
+	generateCmd.Flags().Int("slice-threshold", 0, "Ask the LLM for vertical slices of the User Stories estimated above this number of story points (0 disables slicing)")
+	generateCmd.Flags().String("slice-mode", slicesSection, "What to do with the slices of an oversized story: section (a Suggested Split section of its body) or stories (stories of their own, linked to it as sub-issues)")
+	generateCmd.Flags().Bool("hierarchy", false, "Link each User Story to the closest Epic row above it, as a sub-issue tracked in the epic body")
+	generateCmd.Flags().Int("candidates", 1, "Generate N variants of each item in parallel and keep the best one according to the quality checker")
+	generateCmd.Flags().Bool("interactive", false, "With --candidates, present all the variants and ask which one to keep")
+	generateCmd.Flags().Int("max-context-tokens", 8000, "Summarize Context cells estimated above this number of tokens before generating, chunk by chunk (0 disables summarization)")
+	generateCmd.Flags().String("on-oversized-prompt", promptPolicyWarn, "What to do when a prompt is estimated above the model context window (LLM_CONTEXT_WINDOW overrides the known window): warn or fail (fail the item before calling the LLM)")
+	generateCmd.Flags().Bool("redact", false, "Mask emails, tokens, keys and other sensitive data in the Context, Parent and Criteria before sending them to the LLM")
+	generateCmd.Flags().StringArray("redact-pattern", nil, "Additional redaction pattern as name=regex, can be repeated (implies --redact)")
+	generateCmd.Flags().String("experiment", "", "Prompts directory of variant B of an A/B experiment: rows alternate between the current prompts (A) and these (B), issues are labeled prompt-variant:<A|B> and the quality scores of the variants are compared at the end")
+	generateCmd.Flags().Int("refine", 0, "Ask the LLM to critique and improve each generation up to N rounds, keeping the best result of the quality checker")
+	generateCmd.Flags().Int("from-issue", 0, "Break down an existing epic issue instead of reading a file: each item of the lists in its body becomes a User Story, created as a sub-issue of the epic")
+	generateCmd.Flags().Bool("fail-on-warn", false, "Exit with an error when the run has row warnings, e.g. an issue not added to its project or not linked to its parent, a task not created or a row skipped, for CI")
+	generateCmd.Flags().Bool("atomic", false, "Roll back the run when an item fails: the issues created are deleted, or closed as not planned where they cannot be deleted, and the projects created are deleted")
+	generateCmd.Flags().Bool("skip-preflight", false, "Do not check the permissions of the provider tokens (e.g. the repo and project scopes of GitHub) before the run")
+	generateCmd.MarkFlagsOneRequired("file", "from-issue")
+	generateCmd.MarkFlagsMutuallyExclusive("file", "from-issue")
 }
 
 // runGenerate is the main handler for the 'generate' command, processing the XLSX file and creating issues.
@@ -38,160 +112,1293 @@ func runGenerate(cmd *cobra.Command, _ []string) error {
 	language, _ := cmd.Flags().GetString("language")
 	autoTasks, _ := cmd.Flags().GetBool("auto-tasks")
 	googleCredentialsFile, _ := cmd.Flags().GetString("google-credentials-file")
+	itemTimeout, _ := cmd.Flags().GetDuration("item-timeout")
+	onError, _ := cmd.Flags().GetString("on-error")
+	strict, _ := cmd.Flags().GetBool("strict")
+	failOnWarn, _ := cmd.Flags().GetBool("fail-on-warn")
+	fromIssue, _ := cmd.Flags().GetInt("from-issue")
+	atomic, _ := cmd.Flags().GetBool("atomic")
+	var titleTemplate *title.Template
+	if text, _ := cmd.Flags().GetString("title-template"); text != "" {
+		var err error
+		if titleTemplate, err = title.Parse(text); err != nil {
+			return err
+		}
+	}
+	recordDir, _ := cmd.Flags().GetString("record-dir")
+	outputFile, _ := cmd.Flags().GetString("output")
+	reportHTML, _ := cmd.Flags().GetString("report-html")
+	providerNames, _ := cmd.Flags().GetStringSlice("provider")
+	consoleOutput, _ := cmd.Flags().GetString("console-output")
+	taskList, _ := cmd.Flags().GetBool("task-list")
+	qaChecklist, _ := cmd.Flags().GetBool("qa-checklist")
+	testSkeletons, _ := cmd.Flags().GetBool("test-skeletons")
+	testDir, _ := cmd.Flags().GetString("test-dir")
+	drafts, _ := cmd.Flags().GetBool("drafts")
+	createProjects, _ := cmd.Flags().GetBool("create-missing-projects")
+	duplicatesAction, _ := cmd.Flags().GetString("duplicates")
+	duplicateThreshold, _ := cmd.Flags().GetFloat64("duplicate-threshold")
+	duplicates, err := newDuplicateChecker(duplicatesAction, duplicateThreshold)
+	if err != nil {
+		return err
+	}
+	if duplicates != nil {
+		defer duplicates.close()
+	}
+	var docs *docRetriever
+	if docPaths, _ := cmd.Flags().GetStringSlice("docs"); len(docPaths) > 0 {
+		docsExcerpts, _ := cmd.Flags().GetInt("docs-excerpts")
+		docsMinScore, _ := cmd.Flags().GetFloat64("docs-min-score")
+		if docs, err = newDocRetriever(cmd.Context(), docPaths, docsExcerpts, docsMinScore); err != nil {
+			return err
+		}
+		defer docs.close()
+	}
+	grounding, _ := cmd.Flags().GetBool("check-grounding")
+	var glossary []string
+	if glossaryFile, _ := cmd.Flags().GetString("glossary"); glossaryFile != "" || grounding {
+		grounding = true
+		if glossary, err = readGlossary(glossaryFile); err != nil {
+			return err
+		}
+	}
+	policies, err := newPolicyEngine()
+	if err != nil {
+		return err
+	}
+	preHooks, err := newHookRunner(appConfig.Hooks.PreGenerate)
+	if err != nil {
+		return err
+	}
+	hooks, err := newHookRunner(appConfig.Hooks.PostCreate)
+	if err != nil {
+		return err
+	}
+	computed, err := newComputedFields(appConfig.Computed)
+	if err != nil {
+		return err
+	}
+	checker := quality.NewChecker()
+	if grounding {
+		checker = quality.NewGroundedChecker(glossary)
+	}
+	hierarchy, _ := cmd.Flags().GetBool("hierarchy")
+	parentStrategy, _ := cmd.Flags().GetString("parent-strategy")
+	if !validParentStrategy(parentStrategy) {
+		return fmt.Errorf("invalid parent strategy: %s (expected %s, %s, %s, %s or %s)", parentStrategy, parentProject, parentEpicIssue, parentMilestone, parentNone, parentAuto)
+	}
+	criteriaFormat, _ := cmd.Flags().GetString("criteria-format")
+	refineRounds, _ := cmd.Flags().GetInt("refine")
+	if refineRounds < 0 {
+		return fmt.Errorf("invalid refine rounds: %d", refineRounds)
+	}
+	maxContextTokens, _ := cmd.Flags().GetInt("max-context-tokens")
+	if maxContextTokens < 0 {
+		return fmt.Errorf("invalid max context tokens: %d", maxContextTokens)
+	}
+	oversizedPrompt, _ := cmd.Flags().GetString("on-oversized-prompt")
+	if oversizedPrompt != promptPolicyWarn && oversizedPrompt != promptPolicyFail {
+		return fmt.Errorf("invalid on-oversized-prompt policy: %s (expected %s or %s)", oversizedPrompt, promptPolicyWarn, promptPolicyFail)
+	}
+	redactEnabled, _ := cmd.Flags().GetBool("redact")
+	redactPatterns, _ := cmd.Flags().GetStringArray("redact-pattern")
+	experimentDir, _ := cmd.Flags().GetString("experiment")
+	candidates, _ := cmd.Flags().GetInt("candidates")
+	interactive, _ := cmd.Flags().GetBool("interactive")
+	if candidates < 1 {
+		return fmt.Errorf("invalid number of candidates: %d", candidates)
+	}
+	if !prompt.CriteriaFormat(criteriaFormat).IsValid() {
+		return fmt.Errorf("invalid criteria format: %s (expected gherkin, checklist or bullets)", criteriaFormat)
+	}
+	if onError != errorPolicyFail && onError != errorPolicyContinue {
+		return fmt.Errorf("invalid on-error policy: %s (expected %s or %s)", onError, errorPolicyFail, errorPolicyContinue)
+	}
 	slog.Info("starting generate command", "file", filePath, "language", language, "autoTasks", autoTasks)
 
+	settings := runSettings{googleCredentials: googleCredentialsFile, providers: providerNames}
+	if fromIssue == 0 {
+		settings.file = filePath
+	}
+	if err := validateSettings(settings).Err(); err != nil {
+		return err
+	}
+
+	targets, err := newIssueTargets(providerNames, consoleOutput)
+	if err != nil {
+		return err
+	}
+	if skip, _ := cmd.Flags().GetBool("skip-preflight"); !skip {
+		needs := provider.Permissions{Issues: true, Projects: needsProjects(parentStrategy, createProjects, drafts, computed)}
+		if err := preflight(cmd.Context(), targets, needs); err != nil {
+			return err
+		}
+	}
+
 	var r reader.Reader
-	if strings.HasPrefix(filePath, "https://docs.google.com/spreadsheets/") {
+	var sourceEpic *epicSource
+	switch {
+	case fromIssue > 0:
+		if sourceEpic, err = fetchEpic(cmd.Context(), targets, fromIssue); err != nil {
+			return err
+		}
+		// The epic takes the place of the file as the source of the run
+		filePath = fmt.Sprintf("#%d", fromIssue)
+		r = reader.NewIssueReader(filePath, sourceEpic.issue.GetTitle(), sourceEpic.issue.GetBody())
+	case strings.HasPrefix(filePath, "https://docs.google.com/spreadsheets/"):
 		if googleCredentialsFile == "" {
 			return fmt.Errorf("google-credentials-file flag is required for Google Sheets")
 		}
 		r = reader.NewGoogleSheetsReader(extractSpreadsheetID(filePath), googleCredentialsFile)
-	} else {
+	default:
 		r = reader.NewXLSXReader(filePath)
 	}
-	items, err := r.Read()
+	items, err := reader.Stream(r)
 	if err != nil {
 		return fmt.Errorf("failed to read input: %w", err)
 	}
-	slog.Debug("items read from input source", "items", items)
+	defer func() {
+		if cerr := items.Close(); cerr != nil {
+			slog.Warn("failed to close input", "error", cerr)
+		}
+	}()
 
 	// Initialize LLM provider
-	llmConfig := llm.Config{
-		Provider: os.Getenv("LLM_PROVIDER"),
-		APIKey:   os.Getenv("LLM_API_KEY"),
-		Model:    os.Getenv("LLM_MODEL"),
-		Endpoint: os.Getenv("LLM_ENDPOINT"),
+	var redactor *redact.Redactor
+	if redactEnabled || len(redactPatterns) > 0 {
+		patterns := map[string]string{}
+		for _, p := range redactPatterns {
+			name, pattern, ok := strings.Cut(p, "=")
+			if !ok || name == "" {
+				return fmt.Errorf("invalid redaction pattern: %s (expected name=regex)", p)
+			}
+			patterns[name] = pattern
+		}
+		if redactor, err = redact.NewRedactor(patterns); err != nil {
+			return err
+		}
+	}
+	wrap := func(llmProvider llm.Provider) (llm.Provider, error) {
+		summarizer, canSummarize := llmProvider.(llm.Summarizer)
+		if refineRounds > 0 {
+			var err error
+			llmProvider, err = llm.NewRefiningProvider(llmProvider, checker, refineRounds)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if candidates > 1 || interactive {
+			var selector llm.Selector
+			if interactive {
+				selector = newInteractiveSelector(cmd.InOrStdin(), cmd.OutOrStdout())
+			}
+			llmProvider = llm.NewCandidatesProvider(llmProvider, checker, candidates, selector)
+		}
+		if maxContextTokens > 0 && canSummarize {
+			llmProvider = llm.NewChunkingProvider(llmProvider, summarizer, maxContextTokens)
+		}
+		if redactor != nil {
+			llmProvider = llm.NewRedactingProvider(llmProvider, redactor)
+		}
+		return llmProvider, nil
 	}
 
-	var llmProvider llm.Provider
-	switch llmConfig.Provider {
-	case "openai", "":
-		llmProvider = llm.NewOpenAIProvider(llmConfig)
-	default:
-		return fmt.Errorf("unsupported LLM provider: %s", llmConfig.Provider)
+	llmConfig := newLLMConfig()
+	llmConfig.FailOnOversizedPrompt = oversizedPrompt == promptPolicyFail
+	localConfig := newLocalLLMConfig()
+	if localConfig != nil {
+		localConfig.FailOnOversizedPrompt = llmConfig.FailOnOversizedPrompt
+	}
+	llmProvider, err := llm.NewProviderWithPolicy(llmConfig, localConfig, wrap)
+	if err != nil {
+		return err
+	}
+	var split *splitter
+	if enabled, _ := cmd.Flags().GetBool("split"); enabled {
+		if split, err = newSplitter(llmConfig, redactor); err != nil {
+			return err
+		}
+	}
+	sliceThreshold, _ := cmd.Flags().GetInt("slice-threshold")
+	sliceMode, _ := cmd.Flags().GetString("slice-mode")
+	slicer, err := newSlicer(llmConfig, sliceThreshold, sliceMode)
+	if err != nil {
+		return err
+	}
+	var experiment *llm.ExperimentProvider
+	if experimentDir != "" {
+		if experiment, err = newExperiment(llmProvider, llmConfig, localConfig, experimentDir, wrap); err != nil {
+			return err
+		}
+		llmProvider = experiment
+	}
+	if recordDir != "" {
+		llmProvider, err = llm.NewRecorder(llmProvider, recordDir)
+		if err != nil {
+			return err
+		}
 	}
 
-	// Initialize GitHub or Console provider
-	githubToken := os.Getenv("GITHUB_TOKEN")
-	githubOwner := os.Getenv("GITHUB_OWNER")
-	githubRepo := os.Getenv("GITHUB_REPO")
+	var state *store.Store
+	if stateDB != "" {
+		state, err = store.Open(stateDB)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := state.Close(); cerr != nil {
+				slog.Warn("failed to close state database", "error", cerr)
+			}
+		}()
+	}
 
-	var githubProvider provider.Provider
+	runID := store.NewRunID()
+	startedAt := time.Now()
+	slog.Info("run started", "run_id", runID, "version", version.Get().Short())
+	if state != nil {
+		if err := state.StartRun(cmd.Context(), store.Run{ID: runID, Source: filePath, StartedAt: startedAt, Version: version.Get().Short()}); err != nil {
+			return err
+		}
+	}
 
-	if githubToken == "" || githubOwner == "" || githubRepo == "" {
-		slog.Info("GitHub environment variables not set. Using ConsoleProvider.")
-		githubProvider = provider.NewConsoleProvider()
-	} else {
-		var err error
-		githubProvider, err = provider.NewGitHubProvider(provider.GitHubConfig{
-			Token: githubToken,
-			Owner: githubOwner,
-			Repo:  githubRepo,
-		})
+	runTimeout, _ := cmd.Flags().GetDuration("run-timeout")
+	ctx := cmd.Context()
+	if runTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, runTimeout)
+		defer cancel()
+	}
+
+	g := &generator{
+		llm:            llmProvider,
+		runID:          runID,
+		source:         filePath,
+		targets:        targets,
+		state:          state,
+		language:       language,
+		autoTasks:      autoTasks,
+		taskList:       taskList,
+		hierarchy:      hierarchy || sourceEpic != nil,
+		parentStrategy: parentStrategy,
+		epics:          make(map[string]*epicRef),
+		criteriaFormat: prompt.CriteriaFormat(criteriaFormat),
+		itemTimeout:    itemTimeout,
+		qaChecklist:    qaChecklist,
+		testSkeletons:  testSkeletons,
+		testDir:        testDir,
+		drafts:         drafts,
+		createProjects: createProjects,
+		duplicates:     duplicates,
+		splitter:       split,
+		slicer:         slicer,
+		docs:           docs,
+		grounding:      grounding,
+		glossary:       glossary,
+		policy:         policies,
+		preHooks:       preHooks,
+		hooks:          hooks,
+		computed:       computed,
+		headings:       i18n.For(language, appConfig.Headings),
+		noEmoji:        noEmoji,
+		titleTemplate:  titleTemplate,
+		collectResults: outputFile != "" || reportHTML != "" || len(appConfig.Notifications) > 0,
+	}
+
+	if sourceEpic != nil {
+		body := format.Document{Blocks: []format.Block{format.Paragraph{Text: strings.TrimRight(sourceEpic.issue.GetBody(), "\n")}}}
+		g.epics[sourceEpic.target] = &epicRef{issue: sourceEpic.issue, body: body}
+	}
+	if atomic {
+		g.atomic = &changeLog{epic: sourceEpic}
+	}
+
+	mode := reader.Lenient
+	if strict {
+		mode = reader.Strict
+	}
+	runErr := g.run(ctx, reader.Group(reader.Validate(items, mode, g.skipRow)), onError)
+	rolledBack := g.atomic != nil && (runErr != nil || g.failed > 0)
+	if rolledBack {
+		if runErr == nil {
+			// With --on-error continue, the failed items fail the run once it is rolled back
+			runErr = fmt.Errorf("the run was rolled back after %d failed items", g.failed)
+		}
+		if err := g.rollBackRun(context.WithoutCancel(ctx)); err != nil {
+			runErr = errors.Join(runErr, err)
+		}
+	}
+	for _, t := range targets {
+		usage.Providers = append(usage.Providers, t.name)
+	}
+	usage.LLM, usage.Rows = cmp.Or(llmConfig.Provider, "openai"), telemetry.SizeClass(g.processed)
+	usage.Failed, usage.Warnings, usage.Errors = g.failed, len(g.warnings), g.errorClasses
+	if runErr == nil && failOnWarn && len(g.warnings) > 0 {
+		runErr = fmt.Errorf("the run has %d row warnings and --fail-on-warn is set, see the run summary", len(g.warnings))
+	}
+	if outputFile != "" {
+		if err := report.WriteXLSX(outputFile, g.results); err != nil {
+			slog.Error("failed to write results", "file", outputFile, "error", err)
+		} else {
+			slog.Info("results written", "file", outputFile, "items", len(g.results))
+		}
+	}
+	if reportHTML != "" {
+		run := report.Run{ID: runID, Source: filePath, StartedAt: startedAt}
+		if err := report.WriteHTML(reportHTML, run, g.results); err != nil {
+			slog.Error("failed to write HTML report", "file", reportHTML, "error", err)
+		} else {
+			slog.Info("HTML report written", "file", reportHTML)
+		}
+	}
+	if experiment != nil {
+		printExperimentReport(cmd.OutOrStdout(), experiment.Report())
+	}
+	notifyRun(ctx, runID, filePath, startedAt, g.results, runErr)
+	printRunSummary(summaryWriter(cmd, targets, consoleOutput), runID, g, time.Since(startedAt))
+	if state != nil {
+		status := store.StatusCompleted
+		switch {
+		case rolledBack:
+			status = store.StatusRolledBack
+		case runErr != nil:
+			status = store.StatusFailed
+		}
+		if err := state.FinishRun(context.WithoutCancel(ctx), runID, status); err != nil {
+			slog.Warn("failed to record run status", "run_id", runID, "error", err)
+		}
+	}
+	return runErr
+}
+
+// epicSource is the existing epic issue broken down by --from-issue, and the target it was read from.
+type epicSource struct {
+	target string
+	issue  provider.Issue
+}
+
+// fetchEpic reads the epic issue with the given number from the first target that can read issues.
+func fetchEpic(ctx context.Context, targets []issueTarget, number int) (*epicSource, error) {
+	for _, t := range targets {
+		getter, ok := t.provider.(provider.IssueGetter)
+		if !ok {
+			continue
+		}
+		issue, err := getter.GetIssue(ctx, number)
 		if err != nil {
-			return fmt.Errorf("failed to initialize GitHub provider: %w", err)
+			return nil, fmt.Errorf("failed to read the epic to break down: %w", err)
 		}
+		slog.Info("breaking down epic", "provider", t.name, "number", number, "title", issue.GetTitle())
+		return &epicSource{target: t.name, issue: issue}, nil
 	}
+	return nil, fmt.Errorf("--from-issue requires an issue provider that can read issues (github)")
+}
+
+// printRunSummary prints the outcome of a run, followed by the row warnings, which is printed even
+// when logs are suppressed by --quiet.
+func printRunSummary(w io.Writer, runID string, g *generator, duration time.Duration) {
+	skipped := ""
+	if g.skipped > 0 {
+		skipped = fmt.Sprintf(", %d skipped", g.skipped)
+	}
+	_, _ = fmt.Fprintf(w, "Processed %d rows in %s: %d created, %d failed%s (run %s)\n",
+		g.processed, duration.Round(time.Millisecond), g.processed-g.failed, g.failed, skipped, runID)
+	if g.atomic != nil && g.atomic.removed > 0 {
+		_, _ = fmt.Fprintf(w, "Run rolled back: %d issues deleted or closed\n", g.atomic.removed)
+	}
+	if len(g.warnings) == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "%d row warnings:\n", len(g.warnings))
+	for _, rw := range g.warnings {
+		_, _ = fmt.Fprintf(w, "  %s: %s\n", rw.source, rw.message)
+	}
+}
 
-	// Process each item
-	for _, item := range items {
-		content, err := llmProvider.GenerateContent(
-			item.Type,
-			item.Parent,
-			item.Context,
-			item.Criteria,
-			language,
-			autoTasks,
-		)
+// summaryWriter returns where the run summary is printed: stdout, unless the console provider
+// prints machine readable records there, so they can still be piped.
+func summaryWriter(cmd *cobra.Command, targets []issueTarget, consoleOutput string) io.Writer {
+	output, _ := provider.ParseConsoleOutput(consoleOutput)
+	for _, t := range targets {
+		if t.name == providerConsole && (output == provider.ConsoleJSON || output == provider.ConsoleYAML) {
+			return cmd.ErrOrStderr()
+		}
+	}
+	return cmd.OutOrStdout()
+}
+
+// run processes the items as they are read, applying the error policy to failed items.
+func (g *generator) run(ctx context.Context, items reader.Iterator, onError string) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("run aborted after %d items: %w", g.processed, err)
+		}
+		item, err := items.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
 		if err != nil {
-			return fmt.Errorf("failed to generate content: %w", err)
+			return fmt.Errorf("failed to read input: %w", err)
+		}
+		slog.Debug("item read", "source", item.Source, "type", item.Type, "hash", item.Source.Hash)
+		for _, w := range item.Warnings {
+			g.warn(item.Source, w)
+		}
+
+		item, err = g.preGenerate(ctx, item)
+		var rejected *hook.RejectedError
+		if errors.As(err, &rejected) {
+			g.skipRow(&reader.RowError{Source: item.Source, Reason: rejected.Error()})
+			continue
+		}
+
+		stories := []reader.Item{item}
+		if err == nil {
+			stories = g.split(ctx, item)
 		}
+		for _, story := range stories {
+			g.processed++
+			storyErr := err
+			if storyErr != nil {
+				// The row is recorded as failed, as processItem would
+				g.recordItem(ctx, story, llm.Usage{}, storyErr)
+				g.addResult(story, nil, nil, storyErr)
+			} else {
+				storyErr = g.processItem(ctx, story)
+			}
+			if storyErr != nil {
+				g.failed++
+				if g.errorClasses == nil {
+					g.errorClasses = make(map[string]int)
+				}
+				g.errorClasses[errorClass(storyErr)]++
+				if onError == errorPolicyContinue && ctx.Err() == nil {
+					slog.Error("failed to process item, moving on", "source", story.Source, "type", story.Type, "parent", story.Parent, "error", storyErr)
+					continue
+				}
+				if ref := story.Source.String(); ref != "" {
+					return fmt.Errorf("%s: %w", ref, storyErr)
+				}
+				return storyErr
+			}
+		}
+	}
+	slog.Info("all items processed", "items", g.processed)
+	return nil
+}
+
+// generator holds the dependencies shared by every item processed in a run.
+type generator struct {
+	llm            llm.Provider
+	runID          string
+	source         string
+	targets        []issueTarget
+	state          *store.Store
+	language       string
+	autoTasks      bool
+	taskList       bool
+	hierarchy      bool
+	parentStrategy string              // How the Parent column is resolved, see resolveParent
+	epics          map[string]*epicRef // current epic of each target, in hierarchy mode
+	criteriaFormat prompt.CriteriaFormat
+	itemTimeout    time.Duration
+	qaChecklist    bool
+	testSkeletons  bool
+	testDir        string            // Repository directory of the feature files of the test skeletons
+	drafts         bool              // Create draft items instead of issues by default, see isDraft
+	createProjects bool              // Create the projects not found by name, when the provider can
+	atomic         *changeLog        // Changes to roll back when the run fails, nil when it is not atomic
+	duplicates     *duplicateChecker // Nil when the duplicates are not checked
+	splitter       *splitter         // Nil when the rows are not split, see split
+	slicer         *slicer           // Nil when the oversized stories are not sliced, see slice
+	docs           *docRetriever     // Nil when no documentation is given
+	grounding      bool              // Flag the content mentioning terms missing from the row, see quality.Ungrounded
+	glossary       []string          // Terms known to the grounding check
+	policy         *policy.Engine    // Nil when the configuration file has no policies
+	preHooks       *hook.Runner      // Pre-generation hooks, nil when the configuration file has none
+	hooks          *hook.Runner      // Post-create hooks, nil when the configuration file has none
+	computed       *computedFields   // Nil when the configuration file has no computed fields
+	headings       i18n.Headings
+	collectResults bool
+	results        []report.Result
+	noEmoji        bool
+	titleTemplate  *title.Template // Titles rendered from a template instead of generated, when set
+	processed      int             // Items processed, including the failed ones
+	failed         int
+	skipped        int // Invalid rows skipped in lenient mode
+	warnings       []rowWarning
+	errorClasses   map[string]int // Number of failed rows by error class, for the usage telemetry
+}
+
+// rowWarning is a problem found in a source row, listed in the run summary.
+type rowWarning struct {
+	source  reader.SourceRef
+	message string
+}
+
+// epicRef tracks an epic created in a target and the stories linked to it.
+type epicRef struct {
+	issue   provider.Issue
+	body    format.Document
+	stories []provider.Issue
+}
+
+// titlePrefixes decorates the issue titles according to the item type.
+var titlePrefixes = map[prompt.ItemType]string{
+	prompt.UserStory: "[📖 User Story]",
+	prompt.Epic:      "[🗺️ Epic]",
+}
+
+// Title prefixes of the task issues and the QA checklist issues, and label of the QA checklists.
+const (
+	taskTitlePrefix = "[🛠️ Task]"
+	qaTitlePrefix   = "[🧪 QA]"
+	qaLabel         = "QA"
+)
+
+// decorate prefixes an issue title, without the emoji of the prefix when they are disabled.
+func (g *generator) decorate(prefix, title string) string {
+	return decorate(prefix, title, g.noEmoji)
+}
+
+func decorate(prefix, title string, noEmoji bool) string {
+	if noEmoji {
+		prefix = emoji.Strip(prefix)
+	}
+	return prefix + " " + title
+}
+
+// taskRequest returns the request creating a task of a User Story, as a sub-issue of the story in
+// the providers that support them.
+func taskRequest(task string, storyNumber int, storyTitle string, caps provider.Capabilities, noEmoji bool, project *provider.ProjectInfo) provider.CreateIssueRequest {
+	title, _ := format.TruncateTitle(decorate(taskTitlePrefix, task, noEmoji), caps.MaxTitleSize)
+	req := provider.CreateIssueRequest{
+		Title:   title,
+		Body:    fmt.Sprintf("Task for User Story #%d: %s\n\n%s", storyNumber, storyTitle, task),
+		Labels:  []string{"Task"},
+		Project: project,
+	}
+	if caps.SubIssues {
+		req.Parent = storyNumber
+	}
+	return req
+}
+
+// tasks returns whether tasks are generated for an item and how many, zero for as many as the LLM
+// suggests. The Tasks column of the row overrides --auto-tasks; epics never have tasks.
+func (g *generator) tasks(item reader.Item) (bool, int) {
+	if item.Type == prompt.Epic {
+		return false, 0
+	}
+	switch item.Tasks {
+	case reader.TasksDefault:
+		return g.autoTasks, 0
+	case reader.TasksNone:
+		return false, 0
+	case reader.TasksAny:
+		return true, 0
+	default:
+		return true, item.Tasks
+	}
+}
+
+// fallbackTitle returns the title of an item whose generated content has none: its type and a
+// summary of its context.
+func fallbackTitle(item reader.Item) string {
+	return fmt.Sprintf("%s %s", item.Type, title.Summary(item.Context))
+}
+
+// titleData returns the values of a row used by the title template.
+func titleData(item reader.Item) title.Data {
+	return title.Data{Type: item.Type.String(), Parent: item.Parent, Context: item.Context, Row: item.Source.Row}
+}
+
+// processItem runs the LLM generation and issue creation pipeline for a single item,
+// bounded by the configured item timeout.
+func (g *generator) processItem(ctx context.Context, item reader.Item) (err error) {
+	var usage llm.Usage
+	var content *llm.GeneratedContent
+	var records []store.Record
+	defer func() {
+		g.recordItem(ctx, item, usage, err)
+		g.addResult(item, content, records, err)
+	}()
+
+	if g.itemTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.itemTimeout)
+		defer cancel()
+	}
+
+	parent, err := resolveParent(g.parentStrategy, item.Parent)
+	if err != nil {
+		return err
+	}
+	generateTasks, taskCount := g.tasks(item)
+	req := llm.Request{
+		ID:             item.ID,
+		ItemType:       item.Type,
+		Parent:         item.Parent,
+		Context:        item.Context,
+		Criteria:       item.Criteria,
+		Language:       g.language,
+		GenerateTasks:  generateTasks,
+		TaskCount:      taskCount,
+		CriteriaFormat: g.criteriaFormat,
+		Sensitivity:    item.Sensitivity,
+		Images:         item.Designs,
+		Documentation:  g.docs.retrieve(ctx, item),
+	}
+	content, err = g.llm.GenerateContent(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to generate content: %w", err)
+	}
+	usage = content.Usage
+	if taskCount > 0 && len(content.SuggestedTasks) > taskCount {
+		content.SuggestedTasks = content.SuggestedTasks[:taskCount]
+	}
+	if g.policy != nil {
+		violations, err := g.policy.Apply(content)
+		if err != nil {
+			return err
+		}
+		for _, v := range violations {
+			g.warn(item.Source, v.String())
+		}
+	}
+	computed, err := g.computed.eval(item, content)
+	if err != nil {
+		return err
+	}
+	item.Labels = append(slices.Clip(item.Labels), computed.labels...)
+	item.Project = computed.project
+	if item.Milestone == "" {
+		item.Milestone = parent.milestone
+	}
+
+	// Oversized stories get vertical slices, in their body or as stories of their own
+	var storySlices []llm.GeneratedContent
+	if sliced := g.slice(ctx, item, content); g.slicer != nil && g.slicer.mode == slicesStories {
+		storySlices = sliced
+	} else {
+		content.Slices = sliced
+	}
 
-		// Create issue in GitHub
-		title := content.Title
+	var title string
+	switch {
+	case g.titleTemplate != nil:
+		// The template replaces the generated title everywhere, including the reports
+		content.Title = g.titleTemplate.Render(titleData(item))
+		title = content.Title
+	case computed.title != "":
+		// Like the template, the computed title replaces the generated one everywhere
+		content.Title = computed.title
+		title = content.Title
+	default:
+		title = content.Title
 		if title == "" {
-			title = fmt.Sprintf("%s %s", item.Type, item.Context[:50])
+			title = fallbackTitle(item)
+		}
+		title = g.decorate(titlePrefixes[item.Type], title)
+	}
+	body := describeContent(content, g.criteriaFormat, nil, g.headings, item.Source)
+	if g.grounding {
+		if terms := quality.Ungrounded(req, content, g.glossary); len(terms) > 0 {
+			g.warn(item.Source, quality.UngroundedIssue(terms))
+			label := format.Label{Name: g.headings.NeedsVerification, Value: strings.Join(terms, ", ")}
+			body.Blocks = append([]format.Block{label}, body.Blocks...)
 		}
-		title = fmt.Sprintf("[📖 User Story] %s", title)
+	}
 
-		// Get project info if parent is specified
-		var project *provider.ProjectInfo
-		if item.Parent != "" {
-			slog.Debug("searching for project from parent field", "parent", item.Parent)
-			var err error
-			project, err = githubProvider.GetProjectByName(context.Background(), item.Parent)
-			if err != nil {
-				slog.Warn("failed to get project info", "parent", item.Parent, "error", err)
-			} else if project != nil {
-				slog.Debug("project found", "number", project.ProjectNumber, "owner", project.ProjectOwner)
+	// A new epic closes the previous one, even if it fails to be created
+	if g.hierarchy && item.Type == prompt.Epic {
+		clear(g.epics)
+	}
+
+	// Create the same item in every configured provider
+	draft := g.isDraft(item)
+	var publishErr error
+	for _, target := range g.targets {
+		itemBody, vector, duplicate := g.checkDuplicate(ctx, target, item, body, content)
+		if duplicate {
+			continue
+		}
+		issues, err := g.publish(ctx, target.provider, item, parent, title, itemBody, content, storySlices)
+		if err != nil {
+			publishErr = fmt.Errorf("%s: %w", target.name, err)
+			break
+		}
+		if vector != nil && !draft {
+			g.duplicates.add(ctx, target.name, issues.story, vector)
+		}
+
+		kind, epicNumber := store.KindStory, 0
+		switch {
+		case draft:
+			// Draft items have no number to be linked with
+			kind = store.KindDraft
+		case item.Type == prompt.Epic:
+			kind = store.KindEpic
+			if g.hierarchy {
+				g.epics[target.name] = &epicRef{issue: issues.story, body: body}
 			}
+		case parent.epic != 0:
+			// The epic named in Parent wins over the epic row above the item
+			epicNumber = parent.epic
+		case g.hierarchy && g.epics[target.name] != nil:
+			g.linkToEpic(ctx, item.Source, target.provider, g.epics[target.name], issues.story)
+			epicNumber = g.epics[target.name].issue.GetNumber()
+		}
+
+		storyNumber := issues.story.GetNumber()
+		records = append(records, g.created(ctx, item, target, kind, issues.story, epicNumber))
+		for _, task := range issues.tasks {
+			records = append(records, g.created(ctx, item, target, store.KindTask, task, storyNumber))
+		}
+		if issues.qa != nil {
+			records = append(records, g.created(ctx, item, target, store.KindQA, issues.qa, storyNumber))
+		}
+		if issues.tests != nil {
+			records = append(records, g.created(ctx, item, target, store.KindTests, issues.tests, storyNumber))
+		}
+		for _, story := range issues.slices {
+			records = append(records, g.created(ctx, item, target, store.KindSlice, story, storyNumber))
+		}
+	}
+
+	if g.state != nil && len(records) > 0 {
+		if err := g.state.Add(context.WithoutCancel(ctx), records...); err != nil {
+			slog.Warn("failed to record issue mapping", "row", item.ID, "error", err)
+		}
+	}
+	return publishErr
+}
+
+// checkDuplicate compares a story with the open issues of the target, when duplicates are checked.
+// A likely duplicate is reported as a row warning and either referenced in the body of the story
+// or, with the skip action, reported as true so the story is not created. It also returns the
+// embedding of the story, to compare the next items with it.
+func (g *generator) checkDuplicate(ctx context.Context, target issueTarget, item reader.Item, body format.Document, content *llm.GeneratedContent) (format.Document, []float32, bool) {
+	if g.duplicates == nil || item.Type == prompt.Epic || !g.duplicates.embeddings.allows(item) {
+		return body, nil, false
+	}
+	match, vector, err := g.duplicates.find(ctx, target, content)
+	if err != nil {
+		g.warn(item.Source, fmt.Sprintf("failed to check duplicates in %s: %v", target.name, err))
+		return body, nil, false
+	}
+	if match == nil {
+		return body, vector, false
+	}
+	message := fmt.Sprintf("likely duplicate of %s #%d %q (%.0f%% similar)", target.name, match.Number, match.Title, match.Score*100)
+	if g.duplicates.action == duplicatesSkip {
+		g.warn(item.Source, message+", not created")
+		return body, nil, true
+	}
+	g.warn(item.Source, message)
+	return body.Add(format.Label{Name: g.headings.PossibleDuplicate, Ref: match.Number}), vector, false
+}
+
+// linkToEpic links a story to its epic as a sub-issue and references it in the epic task list, so
+// GitHub shows the native "tracked by" relationship and groups them in the Projects roadmap.
+// Failures are row warnings of the story.
+func (g *generator) linkToEpic(ctx context.Context, source reader.SourceRef, issues provider.Provider, epic *epicRef, story provider.Issue) {
+	if story.GetID() != 0 && issues.Capabilities().SubIssues {
+		if err := issues.AddSubIssue(ctx, epic.issue.GetNumber(), story.GetID()); err != nil {
+			g.warn(source, fmt.Sprintf("failed to add story #%d to epic #%d: %v", story.GetNumber(), epic.issue.GetNumber(), err))
+		}
+	}
+
+	epic.stories = append(epic.stories, story)
+	body := epic.body.Add(trackedStories(epic.stories, g.headings)...)
+	description, truncated := format.TruncateBody(provider.FormatterOf(issues).Format(body), issues.Capabilities().MaxBodySize)
+	if truncated {
+		g.warn(source, fmt.Sprintf("body of epic #%d truncated to the size supported by the provider", epic.issue.GetNumber()))
+	}
+	if _, err := issues.EditIssue(ctx, epic.issue.GetNumber(), "", description); err != nil {
+		g.warn(source, fmt.Sprintf("failed to track story #%d in epic #%d: %v", story.GetNumber(), epic.issue.GetNumber(), err))
+	}
+}
+
+// recordItem stores the outcome of processing an item in the state database.
+func (g *generator) recordItem(ctx context.Context, item reader.Item, usage llm.Usage, err error) {
+	if g.state == nil {
+		return
+	}
+	record := store.ItemRecord{
+		RunID:            g.runID,
+		Row:              item.ID,
+		Type:             item.Type.String(),
+		Parent:           item.Parent,
+		Status:           store.StatusCreated,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		SourceRef:        item.Source.String(),
+		RowHash:          item.Source.Hash,
+	}
+	if err != nil {
+		record.Status = store.StatusFailed
+		record.Error = redact.Secrets(err.Error())
+	}
+	if rerr := g.state.AddItem(context.WithoutCancel(ctx), record); rerr != nil {
+		slog.Warn("failed to record item", "row", item.ID, "error", rerr)
+	}
+}
+
+// addResult keeps the outcome of an item for the results report, when one is requested.
+func (g *generator) addResult(item reader.Item, content *llm.GeneratedContent, records []store.Record, err error) {
+	if !g.collectResults {
+		return
+	}
+	result := report.Result{Item: item, Content: content, Status: report.StatusCreated}
+	// The row warnings include the ones found while processing it, not only when reading it
+	result.Item.Warnings = nil
+	for _, rw := range g.warnings {
+		if rw.source == item.Source {
+			result.Item.Warnings = append(result.Item.Warnings, rw.message)
 		}
+	}
+	if err != nil {
+		result.Status = report.StatusFailed
+		result.Error = redact.Secrets(err.Error())
+	}
+	for _, r := range records {
+		result.Issues = append(result.Issues, report.Issue{Provider: r.Provider, Kind: r.Kind, Number: r.Number, URL: r.URL})
+	}
+	g.results = append(g.results, result)
+}
 
-		fullDescription := formatDescription(content)
-		createdIssue, err := githubProvider.CreateIssue(title, fullDescription, []string{item.Type.String()}, project)
+// skipRow records an invalid row skipped in lenient mode, so it shows up in the reports.
+func (g *generator) skipRow(err *reader.RowError) {
+	g.warn(err.Source, "skipped: "+err.Reason)
+	g.skipped++
+	if g.collectResults {
+		item := reader.Item{ID: strconv.Itoa(err.Source.Row), Source: err.Source}
+		g.results = append(g.results, report.Result{Item: item, Status: report.StatusSkipped, Error: err.Reason})
+	}
+}
+
+// warn logs a problem found in a source row and keeps it for the run summary.
+func (g *generator) warn(source reader.SourceRef, message string) {
+	message = redact.Secrets(message)
+	slog.Warn("row warning", "source", source, "warning", message)
+	g.warnings = append(g.warnings, rowWarning{source: source, message: message})
+}
+
+// warnAll keeps the warnings of a provider about a source row, see warn.
+func (g *generator) warnAll(source reader.SourceRef, messages []string) {
+	for _, message := range messages {
+		g.warn(source, message)
+	}
+}
+
+// published holds the issues created for an item in a provider.
+type published struct {
+	story  provider.Issue   // The issue of the item, a story or an epic
+	tasks  []provider.Issue // Suggested tasks
+	qa     provider.Issue   // QA checklist, nil when not created
+	tests  provider.Issue   // Pull request of the test skeletons, nil when not opened
+	slices []provider.Issue // Stories created from the vertical slices of the item
+}
+
+// fit shortens the title and body of an item to the limits of the provider, with a row warning
+// for each one shortened.
+func (g *generator) fit(item reader.Item, caps provider.Capabilities, title, body string) (string, string) {
+	if shortened, ok := format.TruncateTitle(title, caps.MaxTitleSize); ok {
+		g.warn(item.Source, fmt.Sprintf("title truncated to the %d characters supported by the provider", caps.MaxTitleSize))
+		title = shortened
+	}
+	if shortened, ok := format.TruncateBody(body, caps.MaxBodySize); ok {
+		g.warn(item.Source, fmt.Sprintf("body truncated to the %d characters supported by the provider", caps.MaxBodySize))
+		body = shortened
+	}
+	return title, body
+}
+
+// isDraft reports whether an item is created as a draft item of its project rather than an issue:
+// rows with the draft status are, and so are the rows without a status when drafts are the default.
+func (g *generator) isDraft(item reader.Item) bool {
+	return item.Status == reader.StatusDraft || (g.drafts && item.Status != reader.StatusReady)
+}
+
+// publish creates the item, its tasks, its QA checklist, its test skeletons and the stories of its slices in a single
+// issue provider, rendering the body in the markup of the provider. Drafts are created as a single draft item of the
+// project.
+func (g *generator) publish(ctx context.Context, issues provider.Provider, item reader.Item, parent parentRef, title string, body format.Document, content *llm.GeneratedContent, storySlices []llm.GeneratedContent) (published, error) {
+	formatter := provider.FormatterOf(issues)
+	caps := issues.Capabilities()
+	title, description := g.fit(item, caps, title, formatter.Format(body))
+
+	// Get project info if parent (or project) is specified
+	var project *provider.ProjectInfo
+	projectName := parent.project
+	if item.Project != "" {
+		projectName = item.Project
+	}
+	if projectName != "" && caps.Projects {
+		slog.Debug("searching for project from parent field", "parent", projectName)
+		var err error
+		project, err = issues.GetProjectByName(ctx, projectName)
+		if creator, ok := issues.(provider.ProjectCreator); ok && g.createProjects && errors.Is(err, provider.ErrProjectNotFound) {
+			slog.Info("creating missing project", "parent", projectName)
+			project, err = creator.CreateProject(ctx, projectName)
+			if err == nil && g.atomic != nil {
+				g.atomic.projects = append(g.atomic.projects, createdProject{issues: issues, name: projectName, project: project})
+			}
+		}
 		if err != nil {
-			return fmt.Errorf("failed to create issue: %w", err)
+			g.warn(item.Source, fmt.Sprintf("failed to get project %q, the issues are created without it: %v", projectName, err))
+		} else if project != nil {
+			slog.Debug("project found", "number", project.ProjectNumber, "owner", project.ProjectOwner)
 		}
-		slog.Info("issue created", "type", item.Type, "title", title, "number", createdIssue.GetNumber(), "project", project)
+	}
 
-		// If there are suggested tasks, create each one as an issue and collect their IDs
-		var taskIDs []int64
-		if autoTasks && len(content.SuggestedTasks) > 0 {
-			for _, task := range content.SuggestedTasks {
-				taskTitle := fmt.Sprintf("[🛠️ Task] %s", task)
-				taskDescription := fmt.Sprintf("Task for User Story #%d: %s\n\n%s", createdIssue.GetNumber(), title, task)
+	if g.isDraft(item) {
+		drafter, ok := issues.(provider.DraftCreator)
+		if !ok {
+			return published{}, fmt.Errorf("draft items are not supported by the provider")
+		}
+		draft, err := drafter.CreateDraftItem(ctx, title, description, project)
+		if err != nil {
+			return published{}, fmt.Errorf("failed to create draft item: %w", err)
+		}
+		slog.Info("draft item created", "source", item.Source, "type", item.Type, "title", title, "project", project)
+		return published{story: draft}, nil
+	}
 
-				taskIssue, err := githubProvider.CreateIssue(taskTitle, taskDescription, []string{"Task"}, project)
-				if err != nil {
-					slog.Warn("failed to create task issue", "task", task, "error", err)
-					continue
-				}
-				slog.Info("task issue created", "task", task, "number", taskIssue.GetNumber())
-				if taskIssue.GetID() != 0 {
-					taskIDs = append(taskIDs, taskIssue.GetID())
-				}
+	labels := append([]string{item.Type.String()}, item.Labels...)
+	if content.Variant != "" {
+		labels = append(labels, variantLabelPrefix+content.Variant)
+	}
+	epic := 0
+	if parent.epic != 0 {
+		if caps.SubIssues {
+			epic = parent.epic
+		} else {
+			g.warn(item.Source, fmt.Sprintf("sub-issues are not supported by the provider, the issue is not linked to epic #%d", parent.epic))
+		}
+	}
+	created, err := issues.CreateIssue(ctx, provider.CreateIssueRequest{
+		Title:     title,
+		Body:      description,
+		Labels:    labels,
+		Assignees: item.Assignees,
+		Milestone: item.Milestone,
+		Project:   project,
+		Parent:    epic,
+		Metadata:  item.Extra,
+	})
+	if err != nil {
+		return published{}, fmt.Errorf("failed to create issue: %w", err)
+	}
+	g.warnAll(item.Source, created.Warnings)
+	createdIssue := created.Issue
+	slog.Info("issue created", "source", item.Source, "type", item.Type, "title", title, "number", createdIssue.GetNumber(), "project", project)
+
+	// Record the estimate in providers that support it
+	if estimator, ok := issues.(provider.Estimator); ok && content.Estimate > 0 {
+		if err := estimator.SetEstimate(ctx, createdIssue.GetNumber(), content.Estimate); err != nil {
+			g.warn(item.Source, fmt.Sprintf("failed to set the estimate of #%d: %v", createdIssue.GetNumber(), err))
+		}
+	}
+
+	// If there are suggested tasks, create each one as an issue and collect their IDs
+	var tasks []provider.Issue
+	if generateTasks, _ := g.tasks(item); generateTasks && len(content.SuggestedTasks) > 0 {
+		taskNumbers := make([]int, len(content.SuggestedTasks))
+		for i, task := range content.SuggestedTasks {
+			taskIssue, err := issues.CreateIssue(ctx, taskRequest(task, createdIssue.GetNumber(), title, caps, g.noEmoji, project))
+			if err != nil {
+				g.warn(item.Source, fmt.Sprintf("failed to create task %q: %v", task, err))
+				continue
 			}
-			// Add the tasks as sub-issues of the User Story
-			if len(taskIDs) > 0 {
-				for _, taskID := range taskIDs {
-					err := githubProvider.AddSubIssue(createdIssue.GetNumber(), taskID)
-					if err != nil {
-						slog.Warn("failed to add sub-issue", "error", err)
-					}
-				}
+			g.warnAll(item.Source, taskIssue.Warnings)
+			slog.Info("task issue created", "task", task, "number", taskIssue.GetNumber())
+			tasks = append(tasks, taskIssue.Issue)
+			taskNumbers[i] = taskIssue.GetNumber()
+		}
+		// Reference the created tasks in the User Story body so progress shows on the parent
+		if g.taskList && len(tasks) > 0 {
+			body = describeContent(content, g.criteriaFormat, taskNumbers, g.headings, item.Source)
+			_, description := g.fit(item, caps, "", formatter.Format(body))
+			if _, err := issues.EditIssue(ctx, createdIssue.GetNumber(), "", description); err != nil {
+				g.warn(item.Source, fmt.Sprintf("failed to render the task list in #%d: %v", createdIssue.GetNumber(), err))
 			}
 		}
 	}
 
-	return nil
+	result := published{story: createdIssue, tasks: tasks}
+	if g.qaChecklist && item.Type != prompt.Epic && len(content.AcceptanceCriteria) > 0 {
+		result.qa = g.createQAChecklist(ctx, item.Source, issues, createdIssue, content, project)
+	}
+	if proposer, ok := issues.(provider.ChangeProposer); ok && g.testSkeletons && item.Type != prompt.Epic && len(content.AcceptanceCriteria) > 0 {
+		result.tests = g.proposeTestSkeletons(ctx, item.Source, proposer, createdIssue, content)
+	}
+	if len(storySlices) > 0 {
+		result.slices = g.createSlices(ctx, item, issues, createdIssue, body, storySlices, labels, project)
+	}
+	return result, nil
+}
+
+// proposeTestSkeletons opens a pull request adding the feature file of a story, with a scenario
+// per acceptance criterion, that references the story. Failures are row warnings and return nil,
+// the story is kept.
+func (g *generator) proposeTestSkeletons(ctx context.Context, source reader.SourceRef, proposer provider.ChangeProposer, story provider.Issue, content *llm.GeneratedContent) provider.Issue {
+	feature := testgen.Story{Number: story.GetNumber(), URL: story.GetHTMLURL(), Title: content.Title, Criteria: content.AcceptanceCriteria}
+	file := path.Join(g.testDir, testgen.FileName(feature))
+	pr, err := proposer.ProposeChange(ctx, provider.Change{
+		Branch:  fmt.Sprintf("aigile/tests-%d", story.GetNumber()),
+		Message: fmt.Sprintf("Add test skeletons for #%d", story.GetNumber()),
+		Title:   fmt.Sprintf("Test skeletons for #%d: %s", story.GetNumber(), content.Title),
+		Body: fmt.Sprintf("Scenarios generated from the acceptance criteria of #%d, to be completed with the step definitions.\n\nRefs #%d",
+			story.GetNumber(), story.GetNumber()),
+		Files: map[string]string{file: testgen.Feature(feature, g.language)},
+	})
+	if err != nil {
+		g.warn(source, fmt.Sprintf("failed to propose the test skeletons of #%d: %v", story.GetNumber(), err))
+		return nil
+	}
+	slog.Info("test skeletons proposed", "story", story.GetNumber(), "pull_request", pr.GetNumber(), "file", file)
+	return pr
 }
 
-func formatDescription(content *llm.GeneratedContent) string {
-	var sb strings.Builder
+// createQAChecklist creates an issue where each acceptance criterion of a story is a checkbox, so
+// QA can tick off the verification of each one, and adds it as a sub-issue of the story. Failures
+// are row warnings and return nil, the story is kept.
+func (g *generator) createQAChecklist(ctx context.Context, source reader.SourceRef, issues provider.Provider, story provider.Issue, content *llm.GeneratedContent, project *provider.ProjectInfo) provider.Issue {
+	caps := issues.Capabilities()
+	title, _ := format.TruncateTitle(g.decorate(qaTitlePrefix, content.Title), caps.MaxTitleSize)
+	body, _ := format.TruncateBody(provider.FormatterOf(issues).Format(qaChecklist(story.GetNumber(), content.AcceptanceCriteria, g.headings)), caps.MaxBodySize)
+	req := provider.CreateIssueRequest{Title: title, Body: body, Labels: []string{qaLabel}, Project: project}
+	if caps.SubIssues {
+		req.Parent = story.GetNumber()
+	}
+	qa, err := issues.CreateIssue(ctx, req)
+	if err != nil {
+		g.warn(source, fmt.Sprintf("failed to create the QA checklist of #%d: %v", story.GetNumber(), err))
+		return nil
+	}
+	g.warnAll(source, qa.Warnings)
+	slog.Info("QA checklist created", "story", story.GetNumber(), "number", qa.GetNumber())
+	return qa.Issue
+}
+
+// preGenerate runs the pre-generation hooks on the item and returns it as transformed by them. The
+// transformed item must still be valid; a *hook.RejectedError is returned when a hook rejects it.
+func (g *generator) preGenerate(ctx context.Context, item reader.Item) (reader.Item, error) {
+	if g.preHooks == nil {
+		return item, nil
+	}
+	event := hook.ItemEvent{
+		Event:  hook.EventItemGenerating,
+		RunID:  g.runID,
+		Source: item.Source.String(),
+		Row:    item.ID,
+		Item: hook.Item{
+			Type:        item.Type.String(),
+			Parent:      item.Parent,
+			Context:     item.Context,
+			Criteria:    item.Criteria,
+			Labels:      item.Labels,
+			Assignees:   item.Assignees,
+			Milestone:   item.Milestone,
+			Priority:    item.Priority,
+			Repository:  item.Repository,
+			Sensitivity: item.Sensitivity,
+			Designs:     item.Designs,
+			Status:      item.Status,
+			Extra:       item.Extra,
+		},
+	}
+	t, err := g.preHooks.Transform(ctx, event)
+	if err != nil {
+		return item, err
+	}
+	itemType := prompt.ItemType(t.Type)
+	if !itemType.IsValid() {
+		return item, fmt.Errorf("invalid item type %q returned by the pre-generation hooks", t.Type)
+	}
+	if strings.TrimSpace(t.Parent) == "" || strings.TrimSpace(t.Context) == "" || len(t.Criteria) == 0 {
+		return item, fmt.Errorf("item returned by the pre-generation hooks has no parent, context or criteria")
+	}
+	item.Type = itemType
+	item.Parent = t.Parent
+	item.Context = t.Context
+	item.Criteria = t.Criteria
+	item.Labels = t.Labels
+	item.Assignees = t.Assignees
+	item.Milestone = t.Milestone
+	item.Priority = t.Priority
+	item.Repository = t.Repository
+	item.Sensitivity = t.Sensitivity
+	item.Designs = t.Designs
+	item.Status = t.Status
+	item.Extra = t.Extra
+	return item, nil
+}
+
+// created runs the post-create hooks for an issue created for the item and returns its mapping
+// store record. The hooks are not run for the previews of the console provider, and their failures
+// are reported as row warnings, the issue is kept.
+func (g *generator) created(ctx context.Context, item reader.Item, target issueTarget, kind string, issue provider.Issue, parentNumber int) store.Record {
+	record := g.newRecord(item, target.name, kind, issue, parentNumber)
+	if _, preview := target.provider.(*provider.ConsoleProvider); preview {
+		return record
+	}
+	if g.atomic != nil {
+		g.atomic.issues = append(g.atomic.issues, record)
+	}
+	event := hook.Event{
+		Event:        hook.EventIssueCreated,
+		RunID:        g.runID,
+		Source:       item.Source.String(),
+		Row:          item.ID,
+		Provider:     target.name,
+		Kind:         kind,
+		ParentNumber: parentNumber,
+		Issue: hook.Issue{
+			Number:     issue.GetNumber(),
+			ID:         issue.GetID(),
+			ProviderID: issue.GetProviderID(),
+			URL:        issue.GetHTMLURL(),
+			Title:      issue.GetTitle(),
+			Body:       issue.GetBody(),
+			Labels:     issue.GetLabels(),
+			State:      issue.GetState(),
+		},
+	}
+	for _, err := range g.hooks.Run(context.WithoutCancel(ctx), event) {
+		g.warn(item.Source, err.Error())
+	}
+	return record
+}
+
+// newRecord builds the mapping store record of an issue created for the item, under the issue
+// parentNumber (0 for none).
+func (g *generator) newRecord(item reader.Item, providerName, kind string, issue provider.Issue, parentNumber int) store.Record {
+	return store.Record{
+		RunID:        g.runID,
+		Source:       g.source,
+		Row:          item.ID,
+		Provider:     providerName,
+		Kind:         kind,
+		Number:       issue.GetNumber(),
+		ID:           issue.GetID(),
+		URL:          issue.GetHTMLURL(),
+		Title:        issue.GetTitle(),
+		ParentNumber: parentNumber,
+		ProviderID:   issue.GetProviderID(),
+	}
+}
 
-	// Add description
-	sb.WriteString(content.Description)
-	sb.WriteString("\n\n")
+// describeContent builds the body of the issue of an item from the generated content, with the
+// section headings of the language and a marker of the source row. When taskNumbers is given, the
+// suggested tasks are a task list referencing the created task issues (0 marks a task that could
+// not be created).
+func describeContent(content *llm.GeneratedContent, criteriaFormat prompt.CriteriaFormat, taskNumbers []int, headings i18n.Headings, source reader.SourceRef) format.Document {
+	doc := format.Document{Blocks: []format.Block{format.Paragraph{Text: content.Description}}}
 
-	// Add acceptance criteria if available
 	if len(content.AcceptanceCriteria) > 0 {
-		sb.WriteString("## Acceptance Criteria\n")
-		for i, c := range content.AcceptanceCriteria {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, c))
+		criteria := format.List{Kind: format.Ordered}
+		switch criteriaFormat {
+		case prompt.CriteriaChecklist:
+			criteria.Kind = format.Checklist
+		case prompt.CriteriaBullets:
+			criteria.Kind = format.Bullets
 		}
-		sb.WriteString("\n")
+		for _, c := range content.AcceptanceCriteria {
+			criteria.Items = append(criteria.Items, format.Item{Text: c})
+		}
+		doc = doc.Add(format.Panel{Blocks: []format.Block{format.Heading{Text: headings.AcceptanceCriteria}, criteria}})
 	}
 
-	// Add suggested tasks if available
 	if len(content.SuggestedTasks) > 0 {
-		sb.WriteString("## Suggested Tasks\n")
+		tasks := format.List{Kind: format.Ordered}
+		if taskNumbers != nil {
+			tasks.Kind = format.Checklist
+		}
 		for i, task := range content.SuggestedTasks {
-			sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, task))
+			item := format.Item{Text: task}
+			if i < len(taskNumbers) {
+				item.Ref = taskNumbers[i]
+			}
+			tasks.Items = append(tasks.Items, item)
+		}
+		doc = doc.Add(format.Heading{Text: headings.SuggestedTasks}, tasks)
+	}
+
+	if len(content.Slices) > 0 {
+		doc = doc.Add(suggestedSplit(content.Slices, nil, headings)...)
+	}
+
+	if source.Row != 0 {
+		doc = doc.Add(sourceMarker(source))
+	}
+	return doc
+}
+
+// qaChecklist builds the body of the QA checklist of a story: its acceptance criteria as a task
+// list referencing it.
+func qaChecklist(storyNumber int, criteria []string, headings i18n.Headings) format.Document {
+	checklist := format.List{Kind: format.Checklist}
+	for _, c := range criteria {
+		checklist.Items = append(checklist.Items, format.Item{Text: c, Ref: storyNumber})
+	}
+	return format.Document{}.Add(
+		format.Label{Name: headings.QAVerification, Ref: storyNumber},
+		format.Heading{Text: headings.AcceptanceCriteria},
+		checklist,
+	)
+}
+
+// sourceMarker renders the provenance of an item as a hidden comment, so an issue can be traced
+// back to the row it was generated from.
+func sourceMarker(ref reader.SourceRef) format.Comment {
+	text := fmt.Sprintf("aigile:source file=%q sheet=%q row=%d hash=%q", ref.File, ref.Sheet, ref.Row, ref.Hash)
+	if ref.Part > 0 {
+		text += fmt.Sprintf(" part=%d", ref.Part)
+	}
+	return format.Comment{Text: text}
+}
+
+// sourceMarkerPattern matches the marker rendered by sourceMarker, with its quoted values.
+var sourceMarkerPattern = regexp.MustCompile(`aigile:source file=("(?:[^"\\]|\\.)*") sheet=("(?:[^"\\]|\\.)*") row=(\d+) hash=("(?:[^"\\]|\\.)*")(?: part=(\d+))?`)
+
+// parseSourceMarker returns the provenance recorded in the source marker of an issue body.
+func parseSourceMarker(body string) (reader.SourceRef, bool) {
+	m := sourceMarkerPattern.FindStringSubmatch(body)
+	if m == nil {
+		return reader.SourceRef{}, false
+	}
+	var ref reader.SourceRef
+	var err error
+	if ref.File, err = strconv.Unquote(m[1]); err != nil {
+		return reader.SourceRef{}, false
+	}
+	if ref.Sheet, err = strconv.Unquote(m[2]); err != nil {
+		return reader.SourceRef{}, false
+	}
+	if ref.Row, err = strconv.Atoi(m[3]); err != nil {
+		return reader.SourceRef{}, false
+	}
+	if ref.Hash, err = strconv.Unquote(m[4]); err != nil {
+		return reader.SourceRef{}, false
+	}
+	if m[5] != "" {
+		if ref.Part, err = strconv.Atoi(m[5]); err != nil {
+			return reader.SourceRef{}, false
 		}
-		sb.WriteString("\n")
 	}
+	return ref, true
+}
 
-	return sb.String()
+// trackedStories lists the stories of an epic as a task list, which GitHub turns into "tracks" /
+// "tracked by" relationships.
+func trackedStories(stories []provider.Issue, headings i18n.Headings) []format.Block {
+	list := format.List{Kind: format.Checklist}
+	for _, story := range stories {
+		list.Items = append(list.Items, format.Item{Ref: story.GetNumber()})
+	}
+	return []format.Block{format.Heading{Text: headings.Stories}, list}
 }
 
 // extractSpreadsheetID extrai o ID da planilha de uma URL do Google Sheets.
@@ -204,3 +1411,48 @@ func extractSpreadsheetID(url string) string {
 	parts := strings.SplitN(idAndRest, "/", 2)
 	return parts[0]
 }
+
+// readGlossary returns the terms of the glossary file, one per line (blank lines and lines starting
+// with # are ignored), along with the glossary of the configuration file.
+func readGlossary(path string) ([]string, error) {
+	glossary := append([]string(nil), appConfig.Glossary...)
+	if path == "" {
+		return glossary, nil
+	}
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from a CLI flag
+	if err != nil {
+		return nil, fmt.Errorf("failed to read glossary: %w", err)
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+			glossary = append(glossary, line)
+		}
+	}
+	return glossary, nil
+}
+
+// newHookRunner checks the hooks of the configuration file, returning nil when there are none.
+func newHookRunner(hooks []hook.Hook) (*hook.Runner, error) {
+	if len(hooks) == 0 {
+		return nil, nil
+	}
+	runner, err := hook.New(hooks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load hooks: %w", err)
+	}
+	slog.Debug("hooks loaded", "hooks", runner.Len())
+	return runner, nil
+}
+
+// newPolicyEngine compiles the policies of the configuration file, returning nil when there are none.
+func newPolicyEngine() (*policy.Engine, error) {
+	if len(appConfig.Policies) == 0 {
+		return nil, nil
+	}
+	engine, err := policy.New(appConfig.Policies)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load policies: %w", err)
+	}
+	slog.Debug("policies loaded", "rules", engine.Len())
+	return engine, nil
+}
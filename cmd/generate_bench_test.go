@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/pkg/githubtest"
+)
+
+// benchItems is the number of rows processed by each iteration of the pipeline benchmarks.
+const benchItems = 100
+
+// benchIterator yields n generated rows.
+type benchIterator struct {
+	n, next int
+}
+
+func (it *benchIterator) Next() (reader.Item, error) {
+	if it.next == it.n {
+		return reader.Item{}, io.EOF
+	}
+	it.next++
+	return reader.Item{
+		ID:       fmt.Sprint(it.next + 1),
+		Type:     prompt.UserStory,
+		Context:  fmt.Sprintf("As a customer, I want feature %d so that I can complete my purchase faster", it.next),
+		Criteria: []string{"The feature is available to signed in customers", "Errors are reported with a clear message"},
+		Source:   reader.SourceRef{File: "bench.xlsx", Sheet: "Sheet1", Row: it.next + 1},
+	}, nil
+}
+
+func (it *benchIterator) Close() error { return nil }
+
+// benchmarkPipeline runs the generation pipeline over benchItems rows with the mock LLM and the
+// given issue provider, reporting the time per row.
+func benchmarkPipeline(b *testing.B, issues provider.Provider) {
+	logger := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { slog.SetDefault(logger) })
+
+	b.ResetTimer()
+	start := time.Now()
+	for range b.N {
+		g := &generator{
+			llm:            llm.NewMockProvider(),
+			targets:        []issueTarget{{name: "bench", provider: issues}},
+			language:       "english",
+			autoTasks:      true,
+			taskList:       true,
+			epics:          make(map[string]*epicRef),
+			criteriaFormat: prompt.CriteriaGherkin,
+			headings:       i18n.For("english", nil),
+		}
+		if err := g.run(context.Background(), &benchIterator{n: benchItems}, errorPolicyFail); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(time.Since(start).Microseconds())/float64(b.N*benchItems), "µs/row")
+}
+
+// BenchmarkPipeline_Console measures the overhead of the pipeline itself, without network.
+func BenchmarkPipeline_Console(b *testing.B) {
+	console, err := provider.NewConsoleProviderWithConfig(provider.ConsoleConfig{Output: provider.ConsoleJSON, Writer: io.Discard})
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkPipeline(b, console)
+}
+
+// BenchmarkPipeline_FakeGitHub measures the pipeline against a local fake GitHub API: each row
+// creates a story and three tasks, links them as sub-issues and renders the task list.
+func BenchmarkPipeline_FakeGitHub(b *testing.B) {
+	server := githubtest.NewServer("owner", "repo")
+	b.Cleanup(server.Close)
+	github, err := provider.NewGitHubProvider(provider.GitHubConfig{Token: "token", Owner: "owner", Repo: "repo", BaseURL: server.BaseURL()})
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkPipeline(b, github)
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/leocomelli/aigile/internal/graph"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "Export the epic, story and task graph of a run as DOT or Mermaid",
+	Long: `Export the relationship graph of the epics, stories, tasks and QA checklists created by a run,
+recorded in the local state, as Graphviz DOT or a Mermaid flowchart. Structural problems, such as
+stories outside any epic, epics without stories or failed rows, are reported as warnings.`,
+	RunE: runGraph,
+}
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+	graphCmd.Flags().String("run", "", "Run ID to export (defaults to the most recent run)")
+	graphCmd.Flags().String("format", graph.FormatMermaid, "Output format: mermaid or dot")
+	graphCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+}
+
+// runGraph writes the relationship graph of a run and logs its structural problems.
+func runGraph(cmd *cobra.Command, _ []string) error {
+	runID, _ := cmd.Flags().GetString("run")
+	format, _ := cmd.Flags().GetString("format")
+	output, _ := cmd.Flags().GetString("output")
+	if format != graph.FormatMermaid && format != graph.FormatDOT {
+		return fmt.Errorf("unsupported graph format: %s (expected %s or %s)", format, graph.FormatMermaid, graph.FormatDOT)
+	}
+
+	s, err := openState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	if runID == "" {
+		runs, err := s.Runs(cmd.Context())
+		if err != nil {
+			return err
+		}
+		if len(runs) == 0 {
+			return fmt.Errorf("no runs recorded in %s", stateDB)
+		}
+		runID = runs[0].ID
+	}
+	items, err := s.Items(cmd.Context(), runID)
+	if err != nil {
+		return err
+	}
+	issues, err := s.Issues(cmd.Context(), runID)
+	if err != nil {
+		return err
+	}
+	if len(items) == 0 && len(issues) == 0 {
+		return fmt.Errorf("run not found or empty: %s", runID)
+	}
+
+	g := graph.Build(items, issues)
+	for _, problem := range g.Problems {
+		slog.Warn("graph problem", "run_id", runID, "problem", problem)
+	}
+
+	out := cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output) // #nosec G304 -- path comes from a CLI flag
+		if err != nil {
+			return fmt.Errorf("failed to create graph file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	if err := graph.Write(out, g, format); err != nil {
+		return fmt.Errorf("failed to write graph: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/diff"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/quality"
+	"github.com/spf13/cobra"
+)
+
+// Modes of the groom command.
+const (
+	groomComment = "comment"
+	groomEdit    = "edit"
+)
+
+var groomCmd = &cobra.Command{
+	Use:   "groom",
+	Short: "Flag backlog issues missing acceptance criteria or with vague descriptions and propose fixes",
+	Long: `Scan the open issues of the backlog, flag the ones without acceptance criteria or with a short or
+vague description, and ask the LLM for an improved version of each. The proposal is posted as a
+comment with the diff of the body, or, with --mode edit, written to the issue once confirmed.`,
+	RunE: runGroom,
+}
+
+func init() {
+	rootCmd.AddCommand(groomCmd)
+	groomCmd.Flags().StringSlice("label", nil, "Labels of the issues to scan, comma separated (the issues must have all of them); all open issues by default")
+	groomCmd.Flags().String("mode", groomComment, "How the proposals are delivered: comment (a comment with the diff) or edit (replaces the issue after confirmation)")
+	groomCmd.Flags().Int("limit", 10, "Maximum number of flagged issues to propose improvements for (0 for all)")
+	groomCmd.Flags().Bool("check-only", false, "Only list the flagged issues and their problems, without asking the LLM")
+	groomCmd.Flags().BoolP("yes", "y", false, "Apply the proposals of --mode edit without asking for confirmation")
+	groomCmd.Flags().StringP("language", "g", "english", "Language of the issues (e.g., english, portuguese)")
+	groomCmd.Flags().String("criteria-format", string(prompt.CriteriaGherkin), "Format of the acceptance criteria added to the issues: gherkin, checklist or bullets")
+	groomCmd.Flags().String("provider", providerGitHub, "Issue provider where the issues are (github)")
+}
+
+// runGroom flags the issues with problems and proposes an improved version of each.
+func runGroom(cmd *cobra.Command, _ []string) error {
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	mode, _ := cmd.Flags().GetString("mode")
+	limit, _ := cmd.Flags().GetInt("limit")
+	checkOnly, _ := cmd.Flags().GetBool("check-only")
+	approved, _ := cmd.Flags().GetBool("yes")
+	language, _ := cmd.Flags().GetString("language")
+	criteriaFormat, _ := cmd.Flags().GetString("criteria-format")
+	providerName, _ := cmd.Flags().GetString("provider")
+	if mode != groomComment && mode != groomEdit {
+		return fmt.Errorf("invalid mode: %s (expected comment or edit)", mode)
+	}
+	if !prompt.CriteriaFormat(criteriaFormat).IsValid() {
+		return fmt.Errorf("invalid criteria format: %s (expected gherkin, checklist or bullets)", criteriaFormat)
+	}
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.IssueLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list issues (supported by github)", providerName)
+	}
+	commenter, canComment := issues.(provider.Commenter)
+	if mode == groomComment && !canComment && !checkOnly {
+		return fmt.Errorf("the %s provider cannot comment on issues, use --mode edit", providerName)
+	}
+	var chatter llm.Chatter
+	if !checkOnly {
+		llmProvider, err := llm.NewProvider(newLLMConfig())
+		if err != nil {
+			return err
+		}
+		if chatter, ok = llmProvider.(llm.Chatter); !ok {
+			return fmt.Errorf("the LLM provider cannot propose improvements (supported by openai, bedrock and mock), use --check-only")
+		}
+	}
+
+	backlog, err := lister.ListIssues(cmd.Context(), provider.IssueFilter{Labels: labels})
+	if err != nil {
+		return err
+	}
+	headings := i18n.For(language, appConfig.Headings)
+	out := cmd.OutOrStdout()
+	var flagged []provider.Issue
+	problems := map[int][]string{}
+	for _, issue := range backlog {
+		found := quality.CheckIssue(issue.GetTitle(), issue.GetBody(), []string{headings.AcceptanceCriteria})
+		if len(found) == 0 {
+			continue
+		}
+		flagged = append(flagged, issue)
+		problems[issue.GetNumber()] = found
+		_, _ = fmt.Fprintf(out, "#%d %s\n", issue.GetNumber(), issue.GetTitle())
+		for _, p := range found {
+			_, _ = fmt.Fprintf(out, "  - %s\n", p)
+		}
+	}
+	_, _ = fmt.Fprintf(out, "%d of %d issues need grooming.\n", len(flagged), len(backlog))
+	if checkOnly || len(flagged) == 0 {
+		return nil
+	}
+	if limit > 0 && len(flagged) > limit {
+		slog.Info("proposing improvements for the first flagged issues", "limit", limit, "flagged", len(flagged))
+		flagged = flagged[:limit]
+	}
+
+	in := bufio.NewScanner(cmd.InOrStdin())
+	var usage llm.Usage
+	proposed, failed := 0, 0
+	for _, issue := range flagged {
+		number := issue.GetNumber()
+		chat := llm.NewIssueChat(chatter, issue.GetTitle(), issue.GetBody(), language)
+		_, err := chat.Send(cmd.Context(), llm.GroomMessage(problems[number], prompt.CriteriaFormat(criteriaFormat)))
+		usage.PromptTokens += chat.Usage.PromptTokens
+		usage.CompletionTokens += chat.Usage.CompletionTokens
+		if err == nil && chat.Title == issue.GetTitle() && chat.Body == issue.GetBody() {
+			slog.Warn("no improvement proposed", "number", number)
+			continue
+		}
+		if err == nil {
+			if mode == groomEdit {
+				err = applyGrooming(cmd, in, out, issues, issue, chat, approved)
+			} else {
+				err = commenter.AddComment(cmd.Context(), number, groomingComment(issue, chat, problems[number]))
+			}
+		}
+		if err != nil {
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			slog.Error("failed to groom issue", "number", number, "error", err)
+			failed++
+			continue
+		}
+		proposed++
+	}
+
+	_, _ = fmt.Fprintf(out, "Proposed improvements for %d issues (%s, %d prompt and %d completion tokens).\n",
+		proposed, mode, usage.PromptTokens, usage.CompletionTokens)
+	if failed > 0 {
+		return fmt.Errorf("failed to groom %d issues", failed)
+	}
+	return nil
+}
+
+// applyGrooming shows the diff of the proposal and writes it to the issue once confirmed.
+func applyGrooming(cmd *cobra.Command, in *bufio.Scanner, out io.Writer, issues provider.Provider, issue provider.Issue, chat *llm.IssueChat, approved bool) error {
+	_, _ = fmt.Fprintf(out, "\nProposal for #%d:\n", issue.GetNumber())
+	if chat.Title != issue.GetTitle() {
+		_, _ = fmt.Fprintf(out, "Title: %s\n", chat.Title)
+	}
+	_, _ = fmt.Fprint(out, diff.Lines(issue.GetBody(), chat.Body))
+	if !approved {
+		ok, err := confirm(in, out, fmt.Sprintf("Apply to #%d?", issue.GetNumber()))
+		if err != nil || !ok {
+			return err
+		}
+	}
+	title := ""
+	if chat.Title != issue.GetTitle() {
+		title = chat.Title
+	}
+	_, err := issues.EditIssue(cmd.Context(), issue.GetNumber(), title, chat.Body)
+	return err
+}
+
+// groomingComment returns the comment with the problems of an issue and the diff of the proposed
+// version, to be reviewed as a suggested change.
+func groomingComment(issue provider.Issue, chat *llm.IssueChat, problems []string) string {
+	var sb strings.Builder
+	sb.WriteString("**Grooming suggestions**\n\nProblems found:\n")
+	for _, p := range problems {
+		sb.WriteString("- " + p + "\n")
+	}
+	if chat.Title != issue.GetTitle() {
+		fmt.Fprintf(&sb, "\nProposed title: %s\n", chat.Title)
+	}
+	if changes := diff.Lines(issue.GetBody(), chat.Body); changes != "" {
+		sb.WriteString("\nProposed body:\n\n```diff\n" + changes + "```\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/index"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var indexCmd = &cobra.Command{
+	Use:   "index",
+	Short: "Build and inspect the local index of issue embeddings",
+	Long: `Build, inspect and clear the local SQLite database with the embeddings of the issues, used by the
+duplicate checks of generate and by search. The index is refreshed incrementally: only the issues
+that are new or changed since they were stored, or stored by another embedding model, are embedded.`,
+}
+
+var indexRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Embed the issues that are new or changed since the last refresh",
+	RunE:  runIndexRefresh,
+}
+
+var indexListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the indexed repositories and their number of issues",
+	RunE:  runIndexList,
+}
+
+var indexClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Delete the stored embeddings",
+	RunE:  runIndexClear,
+}
+
+func init() {
+	rootCmd.AddCommand(indexCmd)
+	indexCmd.AddCommand(indexRefreshCmd, indexListCmd, indexClearCmd)
+	indexRefreshCmd.Flags().String("state", "open", "State of the issues indexed: open, closed or all")
+	indexRefreshCmd.Flags().Bool("prune", false, "Delete the embeddings of the issues that are no longer listed, such as closed issues")
+	indexRefreshCmd.Flags().String("provider", providerGitHub, "Issue provider of the issues (github)")
+	indexClearCmd.Flags().String("scope", "", "Indexed repository to clear, as shown by list (defaults to all)")
+}
+
+// openIndex opens the index database configured by the --index-db flag.
+func openIndex() (*index.Index, error) {
+	if indexDB == "" {
+		return nil, fmt.Errorf("index-db flag is required")
+	}
+	return index.Open(indexDB)
+}
+
+// runIndexRefresh embeds the issues of the provider missing from the index or changed since.
+func runIndexRefresh(cmd *cobra.Command, _ []string) error {
+	state, _ := cmd.Flags().GetString("state")
+	prune, _ := cmd.Flags().GetBool("prune")
+	providerName, _ := cmd.Flags().GetString("provider")
+	providerName = strings.ToLower(providerName)
+	if state != "open" && state != "closed" && state != "all" {
+		return fmt.Errorf("invalid state: %s (expected open, closed or all)", state)
+	}
+	if indexDB == "" {
+		return fmt.Errorf("index-db flag is required")
+	}
+
+	issues, err := newIssueProvider(providerName, string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.IssueLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list issues (supported by github)", providerName)
+	}
+	embeddings, err := openIssueEmbeddings()
+	if err != nil {
+		return err
+	}
+	defer embeddings.Close()
+
+	listed, err := lister.ListIssues(cmd.Context(), provider.IssueFilter{State: state})
+	if err != nil {
+		return err
+	}
+	scope := issueScope(providerName)
+	_, stats, err := embeddings.store.Refresh(cmd.Context(), scope, indexIssues(listed), embeddings.embedder, embeddings.model)
+	if err != nil {
+		return err
+	}
+	pruned := 0
+	if prune {
+		numbers := make([]int, len(listed))
+		for i, issue := range listed {
+			numbers[i] = issue.GetNumber()
+		}
+		if pruned, err = embeddings.store.Prune(cmd.Context(), scope, numbers); err != nil {
+			return err
+		}
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Indexed %d issues of %s: %d embedded (%d tokens), %d unchanged, %d pruned.\n",
+		len(listed), scope, stats.Embedded, stats.Tokens, stats.Unchanged, pruned)
+	return nil
+}
+
+// runIndexList prints the indexed scopes.
+func runIndexList(cmd *cobra.Command, _ []string) error {
+	ix, err := openIndex()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ix.Close() }()
+	scopes, err := ix.Scopes(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if len(scopes) == 0 {
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), "No issues indexed.")
+		return nil
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SCOPE\tMODEL\tISSUES\tUPDATED")
+	for _, s := range scopes {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", s.Name, s.Model, s.Issues, s.UpdatedAt.Format(time.DateTime))
+	}
+	return w.Flush()
+}
+
+// runIndexClear deletes the embeddings of a scope, or all of them.
+func runIndexClear(cmd *cobra.Command, _ []string) error {
+	scope, _ := cmd.Flags().GetString("scope")
+	ix, err := openIndex()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = ix.Close() }()
+	deleted, err := ix.Clear(cmd.Context(), scope)
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "Deleted %d embeddings.\n", deleted)
+	return nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+)
+
+// newInteractiveSelector returns a selector that presents every candidate and asks which one to
+// keep. An empty answer keeps the best-scoring candidate.
+func newInteractiveSelector(in io.Reader, out io.Writer) llm.Selector {
+	scanner := bufio.NewScanner(in)
+	return func(req llm.Request, candidates []llm.Candidate) (int, error) {
+		best, _ := llm.BestCandidate(req, candidates)
+
+		fmt.Fprintf(out, "\n%d candidates for row %s (%s):\n", len(candidates), req.ID, req.ItemType)
+		for i, c := range candidates {
+			fmt.Fprintf(out, "\n[%d] score %d: %s\n", i+1, c.Score, c.Content.Title)
+			fmt.Fprintf(out, "    %s\n", c.Content.Description)
+			for _, criterion := range c.Content.AcceptanceCriteria {
+				fmt.Fprintf(out, "    - %s\n", criterion)
+			}
+			for _, issue := range c.Issues {
+				fmt.Fprintf(out, "    ! %s\n", issue)
+			}
+		}
+
+		for {
+			fmt.Fprintf(out, "\nSelect a candidate [1-%d] (default %d): ", len(candidates), best+1)
+			if !scanner.Scan() {
+				if err := scanner.Err(); err != nil {
+					return 0, err
+				}
+				return 0, io.ErrUnexpectedEOF
+			}
+			answer := strings.TrimSpace(scanner.Text())
+			if answer == "" {
+				return best, nil
+			}
+			n, err := strconv.Atoi(answer)
+			if err == nil && n >= 1 && n <= len(candidates) {
+				return n - 1, nil
+			}
+			fmt.Fprintf(out, "Invalid choice: %s\n", answer)
+		}
+	}
+}
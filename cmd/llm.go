@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var llmCmd = &cobra.Command{
+	Use:   "llm",
+	Short: "Check the configured LLM provider",
+	Long:  `Inspect the LLM provider configured through the LLM_* environment variables before starting a large run.`,
+}
+
+var llmModelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models available to the configured provider",
+	RunE:  runLLMModels,
+}
+
+var llmPingCmd = &cobra.Command{
+	Use:   "ping",
+	Short: "Send a minimal completion to verify the API key and model",
+	RunE:  runLLMPing,
+}
+
+func init() {
+	rootCmd.AddCommand(llmCmd)
+	llmCmd.AddCommand(llmModelsCmd, llmPingCmd)
+}
+
+// newLLMConfig builds the LLM provider configuration from the environment.
+func newLLMConfig() llm.Config {
+	var contextWindow int
+	if value := os.Getenv("LLM_CONTEXT_WINDOW"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 0 {
+			slog.Warn("ignoring invalid LLM_CONTEXT_WINDOW", "value", value)
+		} else {
+			contextWindow = n
+		}
+	}
+
+	var vision *bool
+	if value := os.Getenv("LLM_VISION"); value != "" {
+		v, err := strconv.ParseBool(value)
+		if err != nil {
+			slog.Warn("ignoring invalid LLM_VISION", "value", value)
+		} else {
+			vision = &v
+		}
+	}
+
+	return llm.Config{
+		Provider:      os.Getenv("LLM_PROVIDER"),
+		APIKey:        os.Getenv("LLM_API_KEY"),
+		Model:         os.Getenv("LLM_MODEL"),
+		Endpoint:      os.Getenv("LLM_ENDPOINT"),
+		Headers:       parseMapping(os.Getenv("LLM_HEADERS")),
+		Dir:           os.Getenv("LLM_REPLAY_DIR"),
+		PromptsDir:    promptsDir,
+		ContextWindow: contextWindow,
+		Local:         os.Getenv("LLM_ON_PREM") == "true",
+		Vision:        vision,
+		FigmaToken:    os.Getenv("FIGMA_TOKEN"),
+	}
+}
+
+// newEmbeddingConfig builds the configuration of the provider of the embeddings: the LLM provider,
+// with the LLM_EMBEDDING_* environment variables overriding its settings, e.g. to use a local
+// embedding model along with a hosted chat model.
+func newEmbeddingConfig() llm.Config {
+	config := newLLMConfig()
+	config.EmbeddingModel = os.Getenv("LLM_EMBEDDING_MODEL")
+	if value := os.Getenv("LLM_EMBEDDING_PROVIDER"); value != "" {
+		config.Provider = value
+	}
+	if value := os.Getenv("LLM_EMBEDDING_ENDPOINT"); value != "" {
+		config.Endpoint = value
+	}
+	if value := os.Getenv("LLM_EMBEDDING_API_KEY"); value != "" {
+		config.APIKey = value
+	}
+	return config
+}
+
+// newLocalLLMConfig builds the configuration of the local provider used for internal-only rows
+// from the LLM_LOCAL_* environment variables, or returns nil when none is configured.
+func newLocalLLMConfig() *llm.Config {
+	if os.Getenv("LLM_LOCAL_PROVIDER") == "" && os.Getenv("LLM_LOCAL_ENDPOINT") == "" {
+		return nil
+	}
+	return &llm.Config{
+		Provider:   os.Getenv("LLM_LOCAL_PROVIDER"),
+		APIKey:     os.Getenv("LLM_LOCAL_API_KEY"),
+		Model:      os.Getenv("LLM_LOCAL_MODEL"),
+		Endpoint:   os.Getenv("LLM_LOCAL_ENDPOINT"),
+		PromptsDir: promptsDir,
+		Local:      os.Getenv("LLM_LOCAL_ON_PREM") == "true",
+	}
+}
+
+// runLLMModels prints the models available to the configured provider, one per line.
+func runLLMModels(cmd *cobra.Command, _ []string) error {
+	config := newLLMConfig()
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		return err
+	}
+	lister, ok := p.(llm.ModelLister)
+	if !ok {
+		return fmt.Errorf("LLM provider %q does not support listing models", config.Provider)
+	}
+
+	models, err := lister.ListModels(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, m := range models {
+		fmt.Fprintln(cmd.OutOrStdout(), m)
+	}
+	return nil
+}
+
+// runLLMPing verifies that the configured provider answers with the configured model.
+func runLLMPing(cmd *cobra.Command, _ []string) error {
+	config := newLLMConfig()
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		return err
+	}
+	pinger, ok := p.(llm.Pinger)
+	if !ok {
+		return fmt.Errorf("LLM provider %q does not support ping", config.Provider)
+	}
+
+	start := time.Now()
+	if err := pinger.Ping(cmd.Context()); err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "OK: model %q answered in %s\n", config.Model, time.Since(start).Round(time.Millisecond))
+	return nil
+}
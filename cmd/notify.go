@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/notify"
+	"github.com/leocomelli/aigile/internal/report"
+	"github.com/leocomelli/aigile/internal/store"
+)
+
+// notifyTimeout bounds the time spent sending the run summary to each webhook or email.
+const notifyTimeout = 10 * time.Second
+
+// notifyRun sends the summary of a run to the webhooks and emails of the configuration file. Failures are
+// logged, a notification never fails the run.
+func notifyRun(ctx context.Context, runID, source string, startedAt time.Time, results []report.Result, runErr error) {
+	if len(appConfig.Notifications) == 0 {
+		return
+	}
+
+	summary := notify.Summary{RunID: runID, Source: source, Duration: time.Since(startedAt), Total: len(results)}
+	for _, r := range results {
+		summary.Warnings += len(r.Item.Warnings)
+		if r.Status == report.StatusFailed {
+			summary.Failed++
+			summary.Failures = append(summary.Failures, notify.Failure{Source: r.Item.Source.String(), Error: r.Error})
+			continue
+		}
+		if r.Status == report.StatusSkipped {
+			summary.Skipped++
+			continue
+		}
+		summary.Created++
+		title := r.Item.Context
+		if r.Content != nil && r.Content.Title != "" {
+			title = r.Content.Title
+		}
+		for _, issue := range r.Issues {
+			if issue.Kind == store.KindStory || issue.Kind == store.KindEpic {
+				summary.Issues = append(summary.Issues, notify.Link{Title: title, URL: issue.URL})
+			}
+		}
+	}
+	failed := runErr != nil || summary.Failed > 0
+
+	for _, n := range appConfig.Notifications {
+		if n.Type == config.NotifyEmail {
+			var buf bytes.Buffer
+			run := report.Run{ID: runID, Source: source, StartedAt: startedAt}
+			if err := report.RenderHTML(&buf, run, results); err != nil {
+				slog.Warn("failed to render the report for the email notification", "error", err)
+			} else {
+				summary.Report = buf.Bytes()
+			}
+			break
+		}
+	}
+
+	for _, n := range appConfig.Notifications {
+		if !notify.ShouldNotify(n, failed) {
+			continue
+		}
+		notifier, err := notify.New(n, appConfig.SMTP)
+		if err != nil {
+			slog.Warn("failed to create notifier", "type", n.Type, "error", err)
+			continue
+		}
+		nctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), notifyTimeout)
+		if err := notifier.Notify(nctx, summary); err != nil {
+			slog.Warn("failed to send run notification", "type", n.Type, "error", err)
+		} else {
+			slog.Info("run notification sent", "type", n.Type)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Strategies of the --parent-strategy flag, how the Parent column of the rows is resolved.
+const (
+	parentProject   = "project"    // Name of the project (board) the issues are added to
+	parentEpicIssue = "epic-issue" // Number of an existing epic issue the issues are sub-issues of, e.g. #123
+	parentMilestone = "milestone"  // Title of the milestone of the issues
+	parentNone      = "none"       // Sent to the LLM only, not linked to anything
+	parentAuto      = "auto"       // Detected from the value, see resolveParent
+)
+
+// Prefixes of the Parent values with the auto strategy.
+const (
+	epicPrefix      = "#"
+	milestonePrefix = "milestone:"
+	projectPrefix   = "project:"
+)
+
+// parentRef is what the Parent value of a row links its issues to. At most one field is set.
+type parentRef struct {
+	project   string
+	epic      int
+	milestone string
+}
+
+// validParentStrategy reports whether the strategy is supported by resolveParent.
+func validParentStrategy(strategy string) bool {
+	switch strategy {
+	case parentProject, parentEpicIssue, parentMilestone, parentNone, parentAuto:
+		return true
+	}
+	return false
+}
+
+// resolveParent resolves the Parent value of a row with a strategy. With the auto strategy, #123 is
+// an epic issue, milestone:<title> a milestone, and project:<name> or any other value a project.
+func resolveParent(strategy, value string) (parentRef, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return parentRef{}, nil
+	}
+	switch strategy {
+	case "", parentProject:
+		return parentRef{project: value}, nil
+	case parentEpicIssue:
+		number, err := strconv.Atoi(strings.TrimPrefix(value, epicPrefix))
+		if err != nil || number <= 0 {
+			return parentRef{}, fmt.Errorf("invalid parent %q: expected the number of an epic issue, e.g. #123", value)
+		}
+		return parentRef{epic: number}, nil
+	case parentMilestone:
+		return parentRef{milestone: value}, nil
+	case parentNone:
+		return parentRef{}, nil
+	}
+
+	lower := strings.ToLower(value)
+	switch {
+	case strings.HasPrefix(value, epicPrefix):
+		return resolveParent(parentEpicIssue, value)
+	case strings.HasPrefix(lower, milestonePrefix):
+		return resolveParent(parentMilestone, value[len(milestonePrefix):])
+	case strings.HasPrefix(lower, projectPrefix):
+		return resolveParent(parentProject, value[len(projectPrefix):])
+	default:
+		return parentRef{project: value}, nil
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+var projectsCmd = &cobra.Command{
+	Use:   "projects",
+	Short: "Inspect the project boards of the issue provider",
+}
+
+var projectsFieldsCmd = &cobra.Command{
+	Use:   "fields",
+	Short: "List the fields of a project board with their types and option IDs",
+	Long: `List the fields of a GitHub project board with their data type and ID, the options of the
+single select fields (e.g. Status) and the iterations of the iteration fields, with their IDs, which
+are needed to configure the status, iteration and estimate mappings.
+
+  aigile projects fields --project Board`,
+	RunE: runProjectsFields,
+}
+
+func init() {
+	rootCmd.AddCommand(projectsCmd)
+	projectsCmd.AddCommand(projectsFieldsCmd)
+	projectsFieldsCmd.Flags().String("project", "", "Title of the GitHub project board")
+	projectsFieldsCmd.Flags().String("provider", providerGitHub, "Issue provider where the project is (github)")
+	if err := projectsFieldsCmd.MarkFlagRequired("project"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'project' flag as required: %v", err))
+	}
+}
+
+// runProjectsFields prints the fields of a project board, each followed by its options.
+func runProjectsFields(cmd *cobra.Command, _ []string) error {
+	projectName, _ := cmd.Flags().GetString("project")
+	providerName, _ := cmd.Flags().GetString("provider")
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.ProjectFieldLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list project fields (supported by github)", providerName)
+	}
+	project, err := issues.GetProjectByName(cmd.Context(), projectName)
+	if err != nil {
+		return err
+	}
+	fields, err := lister.ListProjectFields(cmd.Context(), project)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FIELD\tTYPE\tID")
+	for _, field := range fields {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", field.Name, field.DataType, field.ID)
+		for _, option := range field.Options {
+			name := option.Name
+			if option.StartDate != "" {
+				name = fmt.Sprintf("%s (%s, %d days)", option.Name, option.StartDate, option.Duration)
+			}
+			fmt.Fprintf(w, "  %s\t\t%s\n", name, option.ID)
+		}
+	}
+	return w.Flush()
+}
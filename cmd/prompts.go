@@ -0,0 +1,193 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/promptlib"
+	"github.com/spf13/cobra"
+)
+
+// skipPromptsResolution is the annotation of the commands that do not load the prompt library
+// before running.
+const skipPromptsResolution = "skip-prompts-resolution"
+
+var promptsCmd = &cobra.Command{
+	Use:   "prompts",
+	Short: "Manage the prompt templates",
+	Long:  `List, render and validate the prompt templates, and fetch the prompt library configured in the configuration file.`,
+}
+
+var promptsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the prompt templates and where they are loaded from",
+	RunE:  runPromptsList,
+}
+
+var promptsShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the rendered prompts for a sample item",
+	Long:  `Show the system prompt and the prompt sent to the LLM for a sample item, with the built-in prompts overridden by --prompts-dir or the prompt library.`,
+	RunE:  runPromptsShow,
+}
+
+var promptsValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check the prompt templates for unknown or missing variables",
+	Long:  `Check the prompt templates for unknown variables, which would be sent verbatim to the LLM, and for prompts missing the variables with the content of the rows. Fails when errors are found.`,
+	RunE:  runPromptsValidate,
+}
+
+var promptsPullCmd = &cobra.Command{
+	Use:         "pull",
+	Short:       "Fetch the prompt library into the local cache",
+	Long:        `Fetch the ref of the prompt library configured in the prompts section of the configuration file, or given by the flags, into the local cache used by the next runs.`,
+	Annotations: map[string]string{skipPromptsResolution: "true"},
+	RunE:        runPromptsPull,
+}
+
+func init() {
+	rootCmd.AddCommand(promptsCmd)
+	promptsCmd.AddCommand(promptsListCmd, promptsShowCmd, promptsValidateCmd, promptsPullCmd)
+	promptsShowCmd.Flags().String("type", string(prompt.UserStory), "Item type of the sample item (User Story or Epic)")
+	promptsShowCmd.Flags().String("parent", "Online store checkout", "Parent of the sample item")
+	promptsShowCmd.Flags().String("context", "Customers can pay with a saved credit card", "Context of the sample item")
+	promptsShowCmd.Flags().StringSlice("criteria", nil, "Acceptance criteria of the sample item, comma separated")
+	promptsShowCmd.Flags().StringP("language", "g", "english", "Language of the generated content")
+	promptsShowCmd.Flags().Bool("auto-tasks", false, "Ask for suggested tasks")
+	promptsShowCmd.Flags().String("criteria-format", string(prompt.CriteriaGherkin), "Acceptance criteria format: gherkin, checklist or bullets")
+	promptsPullCmd.Flags().String("repository", "", "Git repository of the prompt library (overrides the configuration file)")
+	promptsPullCmd.Flags().String("ref", "", "Branch, tag or commit to fetch (overrides the configuration file)")
+	promptsPullCmd.Flags().String("path", "", "Directory of the prompt files inside the repository (overrides the configuration file)")
+}
+
+// promptSource returns the prompt library of the configuration file.
+func promptSource() promptlib.Source {
+	return promptlib.Source{
+		Repository: appConfig.Prompts.Repository,
+		Ref:        appConfig.Prompts.Ref,
+		Path:       appConfig.Prompts.Path,
+	}
+}
+
+// resolvePromptsDir points --prompts-dir to the cached prompt library when the configuration file
+// has one and the flag is not set, pulling the library the first time.
+func resolvePromptsDir(cmd *cobra.Command) error {
+	if promptsDir != "" || appConfig.Prompts.Repository == "" || cmd.Annotations[skipPromptsResolution] != "" {
+		return nil
+	}
+	lib, err := promptlib.New(appConfig.Prompts.CacheDir)
+	if err != nil {
+		return err
+	}
+	dir, err := lib.Dir(cmd.Context(), promptSource())
+	if err != nil {
+		return fmt.Errorf("failed to load prompt library: %w", err)
+	}
+	slog.Debug("using prompt library", "dir", dir)
+	promptsDir = dir
+	return nil
+}
+
+// runPromptsList prints the templates of the prompt manager and their sources.
+func runPromptsList(cmd *cobra.Command, _ []string) error {
+	m, err := prompt.NewManagerFromDir(promptsDir)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tITEM TYPE\tKIND\tSOURCE")
+	for _, t := range m.Templates() {
+		itemType, kind := t.ItemType.String(), "prompt"
+		if itemType == "" {
+			itemType = "all"
+		}
+		if t.System {
+			kind = "system"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", t.Name, itemType, kind, t.Source)
+	}
+	return w.Flush()
+}
+
+// runPromptsShow prints the system prompt and the prompt rendered for the sample item of the flags.
+func runPromptsShow(cmd *cobra.Command, _ []string) error {
+	itemType, _ := cmd.Flags().GetString("type")
+	criteriaFormat, _ := cmd.Flags().GetString("criteria-format")
+	if !prompt.CriteriaFormat(criteriaFormat).IsValid() {
+		return fmt.Errorf("invalid criteria format: %s (expected gherkin, checklist or bullets)", criteriaFormat)
+	}
+	data := prompt.Data{CriteriaFormat: prompt.CriteriaFormat(criteriaFormat)}
+	data.Parent, _ = cmd.Flags().GetString("parent")
+	data.Context, _ = cmd.Flags().GetString("context")
+	data.Criteria, _ = cmd.Flags().GetStringSlice("criteria")
+	data.Language, _ = cmd.Flags().GetString("language")
+	data.GenerateTasks, _ = cmd.Flags().GetBool("auto-tasks")
+
+	m, err := prompt.NewManagerFromDir(promptsDir)
+	if err != nil {
+		return err
+	}
+	system, err := m.GetSystemPrompt(prompt.ItemType(itemType), data)
+	if err != nil {
+		return err
+	}
+	user, err := m.GetPrompt(prompt.ItemType(itemType), data)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "=== system ===\n%s\n\n=== prompt ===\n%s\n", strings.TrimSpace(system), strings.TrimSpace(user))
+	return nil
+}
+
+// runPromptsValidate prints the problems of the templates and fails when any is an error.
+func runPromptsValidate(cmd *cobra.Command, _ []string) error {
+	m, err := prompt.NewManagerFromDir(promptsDir)
+	if err != nil {
+		return err
+	}
+	errs := 0
+	for _, p := range m.Validate() {
+		fmt.Fprintln(cmd.OutOrStdout(), p.String())
+		if p.Severity == prompt.SeverityError {
+			errs++
+		}
+	}
+	if errs > 0 {
+		return fmt.Errorf("found %d errors in the prompt templates", errs)
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), "prompt templates are valid")
+	return nil
+}
+
+// runPromptsPull fetches the configured prompt library into the cache.
+func runPromptsPull(cmd *cobra.Command, _ []string) error {
+	source := promptSource()
+	if v, _ := cmd.Flags().GetString("repository"); v != "" {
+		source = promptlib.Source{Repository: v}
+	}
+	if v, _ := cmd.Flags().GetString("ref"); v != "" {
+		source.Ref = v
+	}
+	if v, _ := cmd.Flags().GetString("path"); v != "" {
+		source.Path = v
+	}
+	if source.Repository == "" {
+		return fmt.Errorf("no prompt library: set prompts.repository in the configuration file or the --repository flag")
+	}
+
+	lib, err := promptlib.New(appConfig.Prompts.CacheDir)
+	if err != nil {
+		return err
+	}
+	commit, err := lib.Pull(cmd.Context(), source)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.OutOrStdout(), commit)
+	return nil
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/emoji"
+	"github.com/leocomelli/aigile/internal/provider"
+)
+
+// Issue provider names accepted by the --provider flag.
+const (
+	providerGitHub      = "github"
+	providerConsole     = "console"
+	providerAsana       = "asana"
+	providerRedmine     = "redmine"
+	providerGitea       = "gitea"
+	providerForgejo     = "forgejo" // Alias of gitea, Forgejo exposes the same API
+	providerAzure       = "azure"
+	providerAzureDevOps = "azure-devops" // Alias of azure
+)
+
+// issueTarget is an issue provider configured for a run, identified by its name.
+type issueTarget struct {
+	name     string
+	provider provider.Provider
+}
+
+// newIssueTargets creates the issue providers selected by name. When no name is given, the GitHub
+// provider is used if its environment variables are set, falling back to the console provider,
+// which prints the issues in consoleOutput.
+func newIssueTargets(names []string, consoleOutput string) ([]issueTarget, error) {
+	if len(names) == 0 {
+		if os.Getenv("GITHUB_TOKEN") == "" || os.Getenv("GITHUB_OWNER") == "" || os.Getenv("GITHUB_REPO") == "" {
+			slog.Info("GitHub environment variables not set. Using ConsoleProvider.")
+			names = []string{providerConsole}
+		} else {
+			names = []string{providerGitHub}
+		}
+	}
+
+	var targets []issueTarget
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if seen[name] {
+			return nil, fmt.Errorf("issue provider configured more than once: %s", name)
+		}
+		seen[name] = true
+
+		p, err := newIssueProvider(name, consoleOutput)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, issueTarget{name: name, provider: p})
+	}
+	return targets, nil
+}
+
+// preflight checks that the credentials of the providers grant the needed permissions before the
+// run, rather than failing on the first item. Providers that cannot check them are skipped.
+func preflight(ctx context.Context, targets []issueTarget, needs provider.Permissions) error {
+	for _, t := range targets {
+		checker, ok := t.provider.(provider.PermissionChecker)
+		if !ok {
+			continue
+		}
+		if err := checker.CheckPermissions(ctx, needs); err != nil {
+			return fmt.Errorf("preflight check of the %s provider failed: %w", t.name, err)
+		}
+		slog.Debug("preflight check passed", "provider", t.name)
+	}
+	return nil
+}
+
+// needsProjects returns whether a run adds its issues to projects, so the preflight checks the
+// access to them: the Parent column names projects, or the projects are created, hold the drafts or
+// are computed. Runs resolving the Parent column to epics or milestones, or ignoring it, do not.
+func needsProjects(parentStrategy string, createProjects, drafts bool, computed *computedFields) bool {
+	switch {
+	case parentStrategy == parentProject, parentStrategy == parentAuto:
+		return true
+	case createProjects, drafts:
+		return true
+	default:
+		return computed != nil && computed.project != nil
+	}
+}
+
+// newIssueProvider creates a single issue provider from its environment configuration.
+func newIssueProvider(name, consoleOutput string) (provider.Provider, error) {
+	switch name {
+	case providerConsole:
+		return provider.NewConsoleProviderWithConfig(provider.ConsoleConfig{
+			Output: provider.ConsoleOutput(consoleOutput),
+			Color:  isTerminal(os.Stdout) && os.Getenv("NO_COLOR") == "",
+			ASCII:  noEmoji || !emoji.Supported(os.Getenv),
+		})
+	case providerGitHub:
+		config := provider.GitHubConfig{
+			Token:      os.Getenv("GITHUB_TOKEN"),
+			Owner:      os.Getenv("GITHUB_OWNER"),
+			Repo:       os.Getenv("GITHUB_REPO"),
+			BaseURL:    os.Getenv("GITHUB_API_URL"),
+			GraphQLURL: os.Getenv("GITHUB_GRAPHQL_URL"),
+		}
+		if config.Token == "" || config.Owner == "" || config.Repo == "" {
+			return nil, fmt.Errorf("GITHUB_TOKEN, GITHUB_OWNER and GITHUB_REPO are required for the github provider")
+		}
+		p, err := provider.NewGitHubProvider(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize GitHub provider: %w", err)
+		}
+		return p, nil
+	case providerAsana:
+		config := provider.AsanaConfig{
+			Token:         os.Getenv("ASANA_TOKEN"),
+			Workspace:     os.Getenv("ASANA_WORKSPACE"),
+			Project:       os.Getenv("ASANA_PROJECT"),
+			BaseURL:       os.Getenv("ASANA_API_URL"),
+			Sections:      parseMapping(os.Getenv("ASANA_SECTIONS")),
+			EstimateField: os.Getenv("ASANA_ESTIMATE_FIELD"),
+		}
+		if config.Token == "" || (config.Workspace == "" && config.Project == "") {
+			return nil, fmt.Errorf("ASANA_TOKEN and ASANA_WORKSPACE or ASANA_PROJECT are required for the asana provider")
+		}
+		p, err := provider.NewAsanaProvider(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Asana provider: %w", err)
+		}
+		return p, nil
+	case providerRedmine:
+		config := provider.RedmineConfig{
+			URL:        os.Getenv("REDMINE_URL"),
+			APIKey:     os.Getenv("REDMINE_API_KEY"),
+			Project:    os.Getenv("REDMINE_PROJECT"),
+			Trackers:   parseMapping(os.Getenv("REDMINE_TRACKERS")),
+			Priorities: parseMapping(os.Getenv("REDMINE_PRIORITIES")),
+			Markdown:   isMarkdownFormatting(os.Getenv("REDMINE_TEXT_FORMATTING")),
+		}
+		if config.URL == "" || config.APIKey == "" {
+			return nil, fmt.Errorf("REDMINE_URL and REDMINE_API_KEY are required for the redmine provider")
+		}
+		p, err := provider.NewRedmineProvider(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Redmine provider: %w", err)
+		}
+		return p, nil
+	case providerGitea, providerForgejo:
+		config := provider.GiteaConfig{
+			URL:   os.Getenv("GITEA_URL"),
+			Token: os.Getenv("GITEA_TOKEN"),
+			Owner: os.Getenv("GITEA_OWNER"),
+			Repo:  os.Getenv("GITEA_REPO"),
+		}
+		if config.URL == "" || config.Token == "" || config.Owner == "" || config.Repo == "" {
+			return nil, fmt.Errorf("GITEA_URL, GITEA_TOKEN, GITEA_OWNER and GITEA_REPO are required for the %s provider", name)
+		}
+		p, err := provider.NewGiteaProvider(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Gitea provider: %w", err)
+		}
+		return p, nil
+	case providerAzure, providerAzureDevOps:
+		config := provider.AzureDevOpsConfig{
+			URL:           azureDevOpsURL(),
+			Token:         os.Getenv("AZURE_DEVOPS_TOKEN"),
+			Project:       os.Getenv("AZURE_DEVOPS_PROJECT"),
+			WorkItemTypes: parseMapping(os.Getenv("AZURE_DEVOPS_WORK_ITEM_TYPES")),
+			EstimateField: os.Getenv("AZURE_DEVOPS_ESTIMATE_FIELD"),
+			States:        parseMapping(os.Getenv("AZURE_DEVOPS_STATES")),
+		}
+		if config.URL == "" || config.Token == "" || config.Project == "" {
+			return nil, fmt.Errorf("AZURE_DEVOPS_TOKEN, AZURE_DEVOPS_ORG (or AZURE_DEVOPS_URL) and AZURE_DEVOPS_PROJECT are required for the %s provider", name)
+		}
+		p, err := provider.NewAzureDevOpsProvider(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Azure DevOps provider: %w", err)
+		}
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unsupported issue provider: %s", name)
+	}
+}
+
+// azureDevOpsURL returns the URL of the Azure DevOps organization: AZURE_DEVOPS_URL, for Azure
+// DevOps Server collections, or the Azure DevOps Services URL of AZURE_DEVOPS_ORG.
+func azureDevOpsURL() string {
+	if url := os.Getenv("AZURE_DEVOPS_URL"); url != "" {
+		return url
+	}
+	if org := os.Getenv("AZURE_DEVOPS_ORG"); org != "" {
+		return "https://dev.azure.com/" + org
+	}
+	return ""
+}
+
+// parseMapping parses a comma-separated list of key=value pairs, such as "Epic=Roadmap,Task=To do".
+func parseMapping(value string) map[string]string {
+	mapping := make(map[string]string)
+	for _, pair := range strings.Split(value, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		mapping[strings.TrimSpace(key)] = strings.TrimSpace(val)
+	}
+	return mapping
+}
+
+// isMarkdownFormatting reports whether a Redmine text formatting setting is one of its Markdown
+// variants ("markdown", "common_mark").
+func isMarkdownFormatting(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "markdown", "common_mark", "commonmark":
+		return true
+	}
+	return false
+}
+
+// isTerminal reports whether the file is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,354 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/similar"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/webhook"
+)
+
+// commandUsage lists the commands of the serve mode, in the replies to unknown commands.
+const commandUsage = "`/aigile regenerate tasks`: generate the tasks of this story again from its source row and create the missing ones"
+
+// taskRegenerator answers the /aigile regenerate tasks command of the serve mode: it generates the
+// tasks of a story again from the source row of its marker, creates the ones the story does not
+// have yet, adds them to the task list of the story and comments the outcome on the story.
+type taskRegenerator struct {
+	issues         provider.Provider
+	llm            llm.Provider
+	state          *store.Store
+	matcher        taskMatcher
+	language       string
+	headings       i18n.Headings
+	parentStrategy string // How the Parent column of the rows is resolved, see resolveParent
+	sourceDir      string // Directory of the XLSX sources, whose markers only have the file name
+	credentials    string // Google Service Account credentials file, for the Google Sheets sources
+	noEmoji        bool
+
+	mu   sync.Mutex     // Regenerations run one at a time, so a repeated command does not create the tasks twice
+	jobs sync.WaitGroup // Commands in progress, see wait
+}
+
+// command runs a command in the background, as the generation outlasts the delivery timeout of
+// GitHub, and replies to unknown commands with the usage.
+func (r *taskRegenerator) command(ctx context.Context, story webhook.Issue, args []string) {
+	r.jobs.Add(1)
+	go func() {
+		defer r.jobs.Done()
+		if strings.Join(args, " ") != "regenerate tasks" {
+			r.reply(ctx, story.Number, fmt.Sprintf("Unknown command `/aigile %s`. Available commands:\n\n- %s", strings.Join(args, " "), commandUsage))
+			return
+		}
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		reply, err := r.regenerate(ctx, story)
+		if err != nil {
+			slog.Error("failed to regenerate tasks", "number", story.Number, "error", err)
+			reply = fmt.Sprintf("Failed to regenerate the tasks: %v", err)
+		}
+		r.reply(ctx, story.Number, reply)
+	}()
+}
+
+// wait waits for the commands in progress, until ctx is done.
+func (r *taskRegenerator) wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.jobs.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to finish the commands in progress: %w", ctx.Err())
+	}
+}
+
+// regenerate generates the tasks of a story again and creates the missing ones, returning the
+// reply to the command.
+func (r *taskRegenerator) regenerate(ctx context.Context, story webhook.Issue) (string, error) {
+	ref, ok := parseSourceMarker(story.Body)
+	if !ok {
+		return "", fmt.Errorf("the issue has no aigile:source marker, it was not generated from a source row")
+	}
+	if ref.Part > 0 {
+		return "", fmt.Errorf("%s was split into stories, the tasks of a part cannot be generated again from the row", ref)
+	}
+	item, err := r.readRow(ref)
+	if err != nil {
+		return "", err
+	}
+	if item.Type == prompt.Epic {
+		return "", fmt.Errorf("%s is an epic, which has no tasks", ref)
+	}
+	parentRef, err := resolveParent(r.parentStrategy, item.Parent)
+	if err != nil {
+		return "", err
+	}
+
+	req := llm.Request{
+		ID:            item.ID,
+		ItemType:      item.Type,
+		Parent:        item.Parent,
+		Context:       item.Context,
+		Criteria:      item.Criteria,
+		Language:      r.language,
+		GenerateTasks: true,
+		Sensitivity:   item.Sensitivity,
+		Images:        item.Designs,
+	}
+	if item.Tasks > 0 {
+		req.TaskCount = item.Tasks
+	}
+	content, err := r.llm.GenerateContent(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate content: %w", err)
+	}
+	if req.TaskCount > 0 && len(content.SuggestedTasks) > req.TaskCount {
+		content.SuggestedTasks = content.SuggestedTasks[:req.TaskCount]
+	}
+	slog.Info("tasks regenerated", "number", story.Number, "source", ref, "tasks", len(content.SuggestedTasks), "prompt_tokens", content.Usage.PromptTokens, "completion_tokens", content.Usage.CompletionTokens)
+
+	// The tasks already created for the story, by the run or a previous command, are not created
+	// again, even when the LLM words them differently
+	children, err := r.state.FindChildren(ctx, providerGitHub, story.URL)
+	if err != nil {
+		return "", err
+	}
+	prefix := decorate(taskTitlePrefix, "", r.noEmoji)
+	var existing []string
+	for _, c := range children {
+		if c.Kind == store.KindTask {
+			existing = append(existing, strings.TrimPrefix(c.Title, prefix))
+		}
+	}
+	found, err := r.matcher.match(ctx, item, existing, content.SuggestedTasks)
+	if err != nil {
+		return "", err
+	}
+	var parent *store.Record
+	if records, err := r.state.FindByURL(ctx, providerGitHub, story.URL); err == nil && len(records) > 0 {
+		parent = &records[0]
+	}
+	project := r.project(ctx, parentRef)
+
+	caps := r.issues.Capabilities()
+	newTasks := format.List{Kind: format.Checklist}
+	var created []string
+	var failed []string
+	kept := 0
+	for i, task := range content.SuggestedTasks {
+		if found[i] {
+			kept++
+			continue
+		}
+		taskReq := taskRequest(task, story.Number, story.Title, caps, r.noEmoji, project)
+		issue, err := r.issues.CreateIssue(ctx, taskReq)
+		if err != nil {
+			slog.Warn("failed to create task", "number", story.Number, "task", task, "error", err)
+			failed = append(failed, task)
+			continue
+		}
+		slog.Info("task issue created", "story", story.Number, "task", task, "number", issue.GetNumber())
+		created = append(created, fmt.Sprintf("#%d", issue.GetNumber()))
+		newTasks.Items = append(newTasks.Items, format.Item{Text: task, Ref: issue.GetNumber()})
+		if parent != nil {
+			record := store.Record{
+				RunID: parent.RunID, Source: parent.Source, Row: parent.Row, Provider: providerGitHub, Kind: store.KindTask,
+				Number: issue.GetNumber(), ID: issue.GetID(), URL: issue.GetHTMLURL(), Title: taskReq.Title,
+				ParentNumber: story.Number, ProviderID: issue.GetProviderID(),
+			}
+			if err := r.state.Add(ctx, record); err != nil {
+				slog.Warn("failed to record task", "number", issue.GetNumber(), "error", err)
+			}
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Regenerated the tasks of this story from `%s`: ", ref)
+	if len(created) == 0 {
+		b.WriteString("no new tasks")
+	} else {
+		fmt.Fprintf(&b, "created %s", strings.Join(created, ", "))
+	}
+	if kept > 0 {
+		fmt.Fprintf(&b, ", %d already existed", kept)
+	}
+	b.WriteString(".")
+	if len(failed) > 0 {
+		fmt.Fprintf(&b, "\n\nFailed to create: %s.", strings.Join(failed, "; "))
+	}
+	if len(newTasks.Items) > 0 {
+		body, _ := format.TruncateBody(addToTaskList(story.Body, r.headings.SuggestedTasks, newTasks), caps.MaxBodySize)
+		if _, err := r.issues.EditIssue(ctx, story.Number, "", body); err != nil {
+			slog.Warn("failed to render the task list", "number", story.Number, "error", err)
+			fmt.Fprintf(&b, "\n\nFailed to add the new tasks to the task list of this story: %v", err)
+		}
+	}
+	if ref.Hash != "" && item.Source.Hash != ref.Hash {
+		b.WriteString("\n\nThe row changed since the story was generated, the tasks follow its current content.")
+	}
+	return b.String(), nil
+}
+
+// project returns the project the tasks are added to, the one of the story resolved from the Parent
+// column of its row, nil when the row has none or it cannot be found.
+func (r *taskRegenerator) project(ctx context.Context, parent parentRef) *provider.ProjectInfo {
+	if parent.project == "" || !r.issues.Capabilities().Projects {
+		return nil
+	}
+	project, err := r.issues.GetProjectByName(ctx, parent.project)
+	if err != nil {
+		slog.Warn("failed to get project, the tasks are created without it", "parent", parent.project, "error", err)
+		return nil
+	}
+	return project
+}
+
+// readRow reads the row of a source marker again, merged with the other rows of its group: from the
+// XLSX file of the source directory, or from the Google Sheet when credentials are given.
+func (r *taskRegenerator) readRow(ref reader.SourceRef) (reader.Item, error) {
+	var source reader.Reader
+	path := filepath.Join(r.sourceDir, filepath.Base(ref.File))
+	switch {
+	case strings.HasPrefix(ref.File, "#"):
+		return reader.Item{}, fmt.Errorf("%s was broken down from an issue, its rows cannot be read again", ref)
+	case fileExists(path):
+		source = reader.NewXLSXReader(path)
+	case r.credentials != "":
+		source = reader.NewGoogleSheetsReader(ref.File, r.credentials)
+	default:
+		return reader.Item{}, fmt.Errorf("source %s not found in %s", ref.File, r.sourceDir)
+	}
+	stream, err := reader.Stream(source)
+	if err != nil {
+		return reader.Item{}, fmt.Errorf("failed to read %s: %w", ref.File, err)
+	}
+	defer func() { _ = stream.Close() }()
+	// The rows of a group are generated together, the story of a group has the source of its first
+	// row and the hash of the whole group
+	items := reader.Group(reader.Validate(stream, reader.Lenient, nil))
+	for {
+		item, err := items.Next()
+		if errors.Is(err, io.EOF) {
+			return reader.Item{}, fmt.Errorf("row %s not found", ref)
+		}
+		if err != nil {
+			return reader.Item{}, fmt.Errorf("failed to read %s: %w", ref.File, err)
+		}
+		if item.Source.Row == ref.Row && item.Source.Sheet == ref.Sheet {
+			return item, nil
+		}
+	}
+}
+
+// reply comments on an issue, logging the failures.
+func (r *taskRegenerator) reply(ctx context.Context, number int, body string) {
+	commenter, ok := r.issues.(provider.Commenter)
+	if !ok {
+		return
+	}
+	if err := commenter.AddComment(ctx, number, body); err != nil {
+		slog.Warn("failed to reply to the command", "number", number, "error", err)
+	}
+}
+
+// listItemPattern matches the items of the Markdown lists, e.g. "- [ ] #12 Task" or "1. Task".
+var listItemPattern = regexp.MustCompile(`^\s*(?:[-*+]|\d+\.)\s`)
+
+// addToTaskList adds tasks to the list under the suggested tasks heading of a story body, or to a
+// new section before the source marker when the body has none.
+func addToTaskList(body, heading string, tasks format.List) string {
+	rendered := strings.TrimSuffix(format.Markdown{}.Format(format.Document{Blocks: []format.Block{tasks}}), "\n")
+	lines := strings.Split(body, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) != "## "+heading {
+			continue
+		}
+		end := i + 1
+		for end < len(lines) && listItemPattern.MatchString(lines[end]) {
+			end++
+		}
+		return strings.Join(slices.Insert(lines, end, rendered), "\n")
+	}
+	section := "## " + heading + "\n" + rendered + "\n\n"
+	if at := strings.Index(body, "<!-- aigile:source "); at >= 0 {
+		return body[:at] + section + body[at:]
+	}
+	return strings.TrimRight(body, "\n") + "\n\n" + strings.TrimSuffix(section, "\n")
+}
+
+// taskMatcher finds the generated tasks a story already has, by the similarity of the embeddings of
+// their titles, so the tasks the LLM words differently are not created again. Without embeddings,
+// or for the internal-only rows when the embeddings are not local, only the same titles match.
+type taskMatcher struct {
+	embedder  llm.Embedder
+	local     bool    // The provider of the embeddings keeps the data on premises
+	threshold float64 // Similarity from which a task is one the story has
+}
+
+// newTaskMatcher creates the matcher of the regenerated tasks with the provider of the embeddings
+// configured by newEmbeddingConfig, matching the titles only when it cannot compute them.
+func newTaskMatcher(threshold float64) taskMatcher {
+	config := newEmbeddingConfig()
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		slog.Warn("failed to initialize the embeddings, the regenerated tasks are matched by title", "error", err)
+		return taskMatcher{threshold: threshold}
+	}
+	embedder, ok := p.(llm.Embedder)
+	if !ok {
+		slog.Warn("the LLM provider cannot compute embeddings, the regenerated tasks are matched by title (set LLM_EMBEDDING_PROVIDER)")
+		return taskMatcher{threshold: threshold}
+	}
+	return taskMatcher{embedder: embedder, local: llm.IsLocal(config), threshold: threshold}
+}
+
+// match returns whether each task matches one of the existing ones.
+func (m taskMatcher) match(ctx context.Context, item reader.Item, existing, tasks []string) ([]bool, error) {
+	found := make([]bool, len(tasks))
+	for i, task := range tasks {
+		found[i] = slices.ContainsFunc(existing, func(e string) bool { return strings.EqualFold(strings.TrimSpace(e), strings.TrimSpace(task)) })
+	}
+	internal := strings.EqualFold(strings.TrimSpace(item.Sensitivity), llm.SensitivityInternalOnly)
+	if m.embedder == nil || len(existing) == 0 || len(tasks) == 0 || (internal && !m.local) {
+		return found, nil
+	}
+	vectors, _, err := m.embedder.Embed(ctx, append(slices.Clip(existing), tasks...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed the tasks: %w", err)
+	}
+	for i := range tasks {
+		for _, e := range vectors[:len(existing)] {
+			if similar.Cosine(vectors[len(existing)+i], e) >= m.threshold {
+				found[i] = true
+				break
+			}
+		}
+	}
+	return found, nil
+}
+
+// fileExists returns whether path is an existing regular file.
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular()
+}
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/webhook"
+	"github.com/leocomelli/aigile/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestSourceMarker(t *testing.T) {
+	for _, ref := range []reader.SourceRef{
+		{File: "backlog.xlsx", Sheet: "Sheet1", Row: 12, Hash: "a1b2c3d4e5f6"},
+		{File: `Q3 "payments".xlsx`, Sheet: "Stories", Row: 3, Hash: "ff00", Part: 2},
+	} {
+		body := format.Markdown{}.Format(format.Document{Blocks: []format.Block{format.Paragraph{Text: "Description"}, sourceMarker(ref)}})
+		parsed, ok := parseSourceMarker(body)
+		require.True(t, ok, body)
+		assert.Equal(t, ref, parsed)
+	}
+
+	_, ok := parseSourceMarker("Description without marker")
+	assert.False(t, ok)
+}
+
+func TestAddToTaskList(t *testing.T) {
+	tasks := format.List{Kind: format.Checklist, Items: []format.Item{{Text: "Implement it", Ref: 13}}}
+	marker := `<!-- aigile:source file="backlog.xlsx" sheet="Sheet1" row=2 hash="ab" -->`
+
+	body := "Description\n\n## Suggested Tasks\n- [ ] #11 Design it\n- [x] #12 Review it\n\n" + marker + "\n"
+	assert.Equal(t, "Description\n\n## Suggested Tasks\n- [ ] #11 Design it\n- [x] #12 Review it\n- [ ] #13 Implement it\n\n"+marker+"\n",
+		addToTaskList(body, "Suggested Tasks", tasks))
+
+	// Stories generated without tasks get the section before the marker
+	assert.Equal(t, "Description\n\n## Suggested Tasks\n- [ ] #13 Implement it\n\n"+marker+"\n",
+		addToTaskList("Description\n\n"+marker+"\n", "Suggested Tasks", tasks))
+	assert.Equal(t, "Description\n\n## Suggested Tasks\n- [ ] #13 Implement it\n", addToTaskList("Description\n", "Suggested Tasks", tasks))
+}
+
+// writeBacklog writes the rows to the first sheet of an XLSX file in dir.
+func writeBacklog(t *testing.T, dir string, rows [][]string) string {
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	for i, row := range rows {
+		require.NoError(t, f.SetSheetRow("Sheet1", fmt.Sprintf("A%d", i+1), &row))
+	}
+	path := filepath.Join(dir, "backlog.xlsx")
+	require.NoError(t, f.SaveAs(path))
+	return path
+}
+
+// TestTaskRegenerator_Regenerate tests that the tasks of a grouped story are generated again with
+// the mock LLM, that only the missing ones are created in its project and task list, and that a
+// task worded differently by the previous run is not created again.
+func TestTaskRegenerator_Regenerate(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := writeBacklog(t, dir, [][]string{
+		{"Type", "Parent", "Context", "Criteria", "GroupID"},
+		{"User Story", "Payments", "As a customer, I want to pay by card", "The payment is confirmed", "cards"},
+		{"User Story", "Payments", "As a customer, I want to see my orders", "The orders are listed", ""},
+		{"User Story", "", "As a customer, I want to save my card", "", "cards"},
+	})
+	server := githubtest.NewServer("acme", "backlog")
+	t.Cleanup(server.Close)
+	server.AddProject("Payments")
+	github, err := provider.NewGitHubProvider(provider.GitHubConfig{Token: "token", Owner: "acme", Repo: "backlog", BaseURL: server.BaseURL()})
+	require.NoError(t, err)
+	state, err := store.Open(filepath.Join(dir, "state.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = state.Close() })
+
+	// The story of the group, generated with one of its tasks
+	stream, err := reader.Stream(reader.NewXLSXReader(path))
+	require.NoError(t, err)
+	item, err := reader.Group(stream).Next()
+	require.NoError(t, err)
+	require.NoError(t, stream.Close())
+	require.Len(t, item.Merged, 1)
+	designed := "Design the whole solution for as a customer, I want to pay by card"
+	body := format.Markdown{}.Format(format.Document{Blocks: []format.Block{
+		format.Paragraph{Text: "Pay by card."},
+		format.Heading{Text: "Suggested Tasks"},
+		format.List{Kind: format.Checklist, Items: []format.Item{{Text: designed, Ref: 2}}},
+		sourceMarker(item.Source),
+	}})
+	created, err := github.CreateIssue(ctx, provider.CreateIssueRequest{Title: "Pay by card", Body: body, Labels: []string{"User Story"}})
+	require.NoError(t, err)
+	story := created.Issue
+	caps := github.Capabilities()
+	task, err := github.CreateIssue(ctx, taskRequest(designed, story.GetNumber(), story.GetTitle(), caps, false, nil))
+	require.NoError(t, err)
+	require.NoError(t, state.StartRun(ctx, store.Run{ID: "r1", Source: path}))
+	require.NoError(t, state.Add(ctx,
+		store.Record{RunID: "r1", Source: path, Row: item.ID, Provider: providerGitHub, Kind: store.KindStory, Number: story.GetNumber(), URL: story.GetHTMLURL(), Title: story.GetTitle()},
+		store.Record{RunID: "r1", Source: path, Row: item.ID, Provider: providerGitHub, Kind: store.KindTask, Number: task.GetNumber(), URL: task.GetHTMLURL(), Title: task.GetTitle(), ParentNumber: story.GetNumber()}))
+
+	r := &taskRegenerator{
+		issues:         github,
+		llm:            llm.NewMockProvider(),
+		state:          state,
+		matcher:        taskMatcher{embedder: llm.NewMockProvider(), local: true, threshold: 0.9},
+		language:       "english",
+		headings:       i18n.For("english", nil),
+		parentStrategy: parentProject,
+		sourceDir:      dir,
+	}
+	reply, err := r.regenerate(ctx, webhook.Issue{Number: story.GetNumber(), Title: story.GetTitle(), URL: story.GetHTMLURL(), Body: body})
+	require.NoError(t, err)
+	assert.Equal(t, "Regenerated the tasks of this story from `backlog.xlsx:Sheet1!2`: created #3, #4, 1 already existed.", strings.ReplaceAll(reply, path, "backlog.xlsx"))
+
+	issues := server.Issues()
+	require.Len(t, issues, 4)
+	assert.Equal(t, decorate(taskTitlePrefix, "Implement as a customer, I want to pay by card", false), issues[2].Title)
+	assert.Equal(t, decorate(taskTitlePrefix, "Write automated tests for as a customer, I want to pay by card", false), issues[3].Title)
+	assert.Len(t, server.SubIssues(story.GetNumber()), 3)
+	project, ok := server.Project("Payments")
+	require.True(t, ok)
+	assert.True(t, slices.Contains(project.Items, issues[2].NodeID))
+	assert.True(t, slices.Contains(project.Items, issues[3].NodeID))
+	assert.Contains(t, issues[0].Body, "- [ ] #2 "+designed+"\n- [ ] #3 Implement as a customer, I want to pay by card\n- [ ] #4 Write automated tests")
+	children, err := state.FindChildren(ctx, providerGitHub, story.GetHTMLURL())
+	require.NoError(t, err)
+	assert.Len(t, children, 3)
+
+	// Nothing is missing the second time
+	reply, err = r.regenerate(ctx, webhook.Issue{Number: story.GetNumber(), Title: story.GetTitle(), URL: story.GetHTMLURL(), Body: issues[0].Body})
+	require.NoError(t, err)
+	assert.Contains(t, reply, "no new tasks, 3 already existed.")
+	assert.NotContains(t, reply, "The row changed")
+	assert.Len(t, server.Issues(), 4)
+}
+
+func TestTaskRegenerator_RegenerateErrors(t *testing.T) {
+	r := &taskRegenerator{sourceDir: t.TempDir()}
+	for body, want := range map[string]string{
+		"No marker": "has no aigile:source marker",
+		`<!-- aigile:source file="backlog.xlsx" sheet="Sheet1" row=2 hash="ab" part=1 -->`: "was split into stories",
+		`<!-- aigile:source file="backlog.xlsx" sheet="Sheet1" row=2 hash="ab" -->`:        "source backlog.xlsx not found",
+		`<!-- aigile:source file="#12" sheet="" row=1 hash="" -->`:                         "broken down from an issue",
+	} {
+		_, err := r.regenerate(context.Background(), webhook.Issue{Number: 1, Body: body})
+		assert.ErrorContains(t, err, want, body)
+	}
+}
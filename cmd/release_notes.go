@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/release"
+	"github.com/spf13/cobra"
+)
+
+var releaseNotesCmd = &cobra.Command{
+	Use:   "release-notes",
+	Short: "Write the release notes of a milestone from its closed issues",
+	Long: `Read the closed issues of a milestone, group them in sections by their type label (epics, stories,
+bugs...) and ask the LLM to write polished release notes in Markdown. Tasks and QA checklists are
+left out by default, as the stories they belong to already describe the change.`,
+	RunE: runReleaseNotes,
+}
+
+func init() {
+	rootCmd.AddCommand(releaseNotesCmd)
+	releaseNotesCmd.Flags().String("milestone", "", "Title of the milestone of the release, e.g. v1.2")
+	releaseNotesCmd.Flags().StringSlice("section", release.DefaultSections, "Labels the issues are grouped by, in the order of the sections; issues without any of them go to Other")
+	releaseNotesCmd.Flags().StringSlice("exclude-label", []string{"Task", "QA"}, "Labels of the issues left out of the notes")
+	releaseNotesCmd.Flags().StringP("language", "g", "english", "Language to write the release notes (e.g., english, portuguese)")
+	releaseNotesCmd.Flags().StringP("output", "o", "", "Markdown file to write the release notes to, stdout by default")
+	releaseNotesCmd.Flags().Bool("raw", false, "Write the grouped list of issues without asking the LLM to polish it")
+	releaseNotesCmd.Flags().String("provider", providerGitHub, "Issue provider where the issues are (github)")
+	if err := releaseNotesCmd.MarkFlagRequired("milestone"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'milestone' flag as required: %v", err))
+	}
+}
+
+// runReleaseNotes writes the release notes of the closed issues of a milestone.
+func runReleaseNotes(cmd *cobra.Command, _ []string) error {
+	milestone, _ := cmd.Flags().GetString("milestone")
+	sections, _ := cmd.Flags().GetStringSlice("section")
+	exclude, _ := cmd.Flags().GetStringSlice("exclude-label")
+	language, _ := cmd.Flags().GetString("language")
+	output, _ := cmd.Flags().GetString("output")
+	raw, _ := cmd.Flags().GetBool("raw")
+	providerName, _ := cmd.Flags().GetString("provider")
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.IssueLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list issues (supported by github)", providerName)
+	}
+	if !issues.Capabilities().Milestones {
+		return fmt.Errorf("the %s provider does not support milestones (supported by github)", providerName)
+	}
+	var writer llm.ReleaseNoteWriter
+	if !raw {
+		llmProvider, err := llm.NewProvider(newLLMConfig())
+		if err != nil {
+			return err
+		}
+		if writer, ok = llmProvider.(llm.ReleaseNoteWriter); !ok {
+			return fmt.Errorf("the LLM provider cannot write release notes (supported by openai, bedrock and mock), use --raw")
+		}
+	}
+
+	closed, err := lister.ListIssues(cmd.Context(), provider.IssueFilter{State: "closed", Milestone: milestone})
+	if err != nil {
+		return err
+	}
+	completed := make([]release.Issue, 0, len(closed))
+	for _, issue := range closed {
+		completed = append(completed, release.Issue{
+			Number: issue.GetNumber(),
+			Title:  issue.GetTitle(),
+			Body:   issue.GetBody(),
+			Labels: issue.GetLabels(),
+		})
+	}
+	notes := release.Group(milestone, completed, sections, exclude)
+	if notes.Count() == 0 {
+		return fmt.Errorf("no closed issues in milestone %s", milestone)
+	}
+	slog.Info("issues grouped", "milestone", milestone, "issues", notes.Count(), "sections", len(notes.Sections))
+
+	text := release.Markdown(notes)
+	if writer != nil {
+		var usage llm.Usage
+		if text, usage, err = writer.WriteReleaseNotes(cmd.Context(), notes, language); err != nil {
+			return err
+		}
+		slog.Info("release notes written", "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+	}
+
+	out := cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output) // #nosec G304 -- path comes from a CLI flag
+		if err != nil {
+			return fmt.Errorf("failed to create release notes file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	if _, err := io.WriteString(out, text); err != nil {
+		return fmt.Errorf("failed to write release notes: %w", err)
+	}
+	return nil
+}
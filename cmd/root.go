@@ -1,38 +1,104 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"log"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/redact"
+	"github.com/leocomelli/aigile/internal/telemetry"
 	"github.com/lmittmann/tint"
 	"github.com/spf13/cobra"
 )
 
+// Default locations of the local state and embeddings index databases.
+const (
+	defaultStateDB = ".aigile/state.db"
+	defaultIndexDB = ".aigile/index.db"
+)
+
+// deferConfigValidation is the annotation of the commands that load an invalid configuration
+// file, to report its problems themselves.
+const deferConfigValidation = "defer-config-validation"
+
 // rootCmd is the base command for the aigile CLI application.
 var (
-	logLevel string
-	rootCmd  = &cobra.Command{
+	logLevel      string
+	quiet         bool
+	verbose       bool
+	noEmoji       bool
+	stateDB       string
+	indexDB       string
+	promptsDir    string
+	configFile    string
+	telemetryFlag string
+	debugHTTP     string
+	appConfig     = &config.Config{}
+	rootCmd       = &cobra.Command{
 		Use:   "aigile",
 		Short: "A tool to generate User Stories and Tasks",
 		Long:  `Aigile is a CLI tool that helps you generate User Stories and Tasks using LLMs (OpenAI, Gemini, Azure OpenAI) and integrates with GitHub Projects or Azure DevOps.`,
-		PersistentPreRun: func(_ *cobra.Command, _ []string) {
-			handler := tint.NewHandler(os.Stdout, &tint.Options{
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			// Logs go to stderr, so the output of the commands can be piped
+			// Logs go through the redaction of the secrets, as errors can echo requests and responses
+			handler := tint.NewHandler(redact.NewWriter(os.Stderr), &tint.Options{
 				Level:      GetLogLevel(),
 				TimeFormat: "15:04:05",
+				NoColor:    !isTerminal(os.Stderr) || os.Getenv("NO_COLOR") != "",
 			})
 			logger := slog.New(handler)
 			slog.SetDefault(logger)
 			slog.Info("starting aigile", "log_level", logLevel)
+
+			cfg, err := config.Load(configFile)
+			if err != nil && (cfg == nil || cmd.Annotations[deferConfigValidation] == "") {
+				return err
+			}
+			appConfig = cfg
+			redact.RegisterSecrets(cfg.Secrets()...)
+			if telemetryFlag != "" && !telemetry.ValidMode(telemetryFlag) {
+				return fmt.Errorf("invalid telemetry mode: %s (expected %s, %s or %s)", telemetryFlag, telemetry.ModeOff, telemetry.ModeLocal, telemetry.ModeOn)
+			}
+			if debugHTTP != "" {
+				if err := httpclient.EnableDump(debugHTTP); err != nil {
+					return err
+				}
+				slog.Warn("HTTP requests and responses are written to the debug directory, with the credentials masked", "dir", debugHTTP)
+			}
+			return resolvePromptsDir(cmd)
 		},
 	}
 )
 
 func init() {
 	rootCmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Set log level (debug, info, warn, error)")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Only log warnings and errors, the commands still print their results")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Log debug messages, same as --log-level debug")
+	rootCmd.MarkFlagsMutuallyExclusive("quiet", "verbose")
+	rootCmd.PersistentFlags().BoolVar(&noEmoji, "no-emoji", false, "Remove the emoji of the issue titles and print ASCII only in the console output, which is automatic in terminals without emoji support")
+	rootCmd.PersistentFlags().StringVar(&stateDB, "state-db", defaultStateDB, "Path to the local SQLite state database (empty disables state tracking)")
+	rootCmd.PersistentFlags().StringVar(&indexDB, "index-db", defaultIndexDB, "Path to the local SQLite database of the issue embeddings (empty embeds the issues again on every run)")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "Path to the configuration file (defaults to "+config.DefaultPath+" when it exists)")
+	rootCmd.PersistentFlags().StringVar(&telemetryFlag, "telemetry", "", "Anonymous usage telemetry: off, local (only written to "+defaultTelemetryFile+") or on; overrides the configuration file, off by default")
+	rootCmd.PersistentFlags().StringVar(&debugHTTP, "debug-http", "", "Write the HTTP requests and responses to the issue trackers and LLM APIs, with the credentials masked, to files of this directory, to diagnose proxy or permission problems")
+	rootCmd.PersistentFlags().StringVar(&promptsDir, "prompts-dir", "", "Directory with prompt files (user-story.txt, epic.txt, system.txt, <type>.system.txt) overriding the default prompts")
 }
 
-// GetLogLevel returns the slog.Level based on the command line flag
+// GetLogLevel returns the slog.Level based on the command line flags. --quiet and --verbose take
+// precedence over --log-level.
 func GetLogLevel() slog.Level {
+	switch {
+	case quiet:
+		return slog.LevelWarn
+	case verbose:
+		return slog.LevelDebug
+	}
 	switch logLevel {
 	case "debug":
 		return slog.LevelDebug
@@ -45,7 +111,29 @@ func GetLogLevel() slog.Level {
 	}
 }
 
-// Execute runs the root command for the CLI application.
+// secretVariables are the environment variables holding tokens and keys, whose values are masked
+// in the logs and errors whatever their format.
+var secretVariables = []string{
+	"GITHUB_TOKEN", "GITHUB_WEBHOOK_SECRET", "GITEA_TOKEN", "ASANA_TOKEN", "REDMINE_API_KEY", "AZURE_DEVOPS_TOKEN", "FIGMA_TOKEN",
+	"LLM_API_KEY", "LLM_EMBEDDING_API_KEY", "LLM_LOCAL_API_KEY",
+}
+
+// Execute runs the root command for the CLI application. SIGINT and SIGTERM cancel the context of
+// the commands, so a run stops after the item in progress and records its state before exiting.
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for _, name := range secretVariables {
+		redact.RegisterSecrets(os.Getenv(name))
+	}
+	for _, value := range parseMapping(os.Getenv("LLM_HEADERS")) {
+		redact.RegisterSecrets(value)
+	}
+	// The errors printed by cobra and by main are masked like the logs
+	rootCmd.SetErr(redact.NewWriter(os.Stderr))
+	log.SetOutput(redact.NewWriter(os.Stderr))
+	cmd, err := rootCmd.ExecuteContextC(ctx)
+	recordUsage(ctx, cmd, err)
+	return err
 }
@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/health"
+	"github.com/leocomelli/aigile/internal/promptlib"
+	"github.com/leocomelli/aigile/internal/schedule"
+	"github.com/spf13/cobra"
+)
+
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule --cron <expression> -- <command> [flags]",
+	Short: "Run a command on a cron schedule",
+	Long: `Run an aigile command, usually generate against a Google Sheets source, on a cron schedule
+without an external cron. Each run is a separate process with the given arguments, so a failed
+run is logged and the next one runs as scheduled.
+
+With --health-addr, /healthz and /readyz are served for Kubernetes probes. On SIGTERM the
+scheduler stops and sends SIGTERM to a run in progress, which aborts its current item and records
+the run in the local state; a run still going after --shutdown-timeout is killed. On Windows, which
+cannot signal a process, the run is killed right away.
+
+  aigile schedule --cron "0 9 * * 1" -- generate --file https://docs.google.com/spreadsheets/d/<id> --google-credentials-file creds.json`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSchedule,
+}
+
+func init() {
+	rootCmd.AddCommand(scheduleCmd)
+	scheduleCmd.Flags().String("cron", "", "Cron expression (minute hour day-of-month month day-of-week, or @hourly, @daily, @weekly) in the local time zone")
+	scheduleCmd.Flags().Bool("run-now", false, "Also run the command immediately, before the first scheduled time")
+	scheduleCmd.Flags().String("health-addr", "", "Address to serve the /healthz and /readyz endpoints on, e.g. :8080 (empty disables them)")
+	scheduleCmd.Flags().Duration("shutdown-timeout", 30*time.Second, "Time given to a run in progress to exit after SIGTERM before it is killed")
+	if err := scheduleCmd.MarkFlagRequired("cron"); err != nil {
+		panic(fmt.Sprintf("failed to mark 'cron' flag as required: %v", err))
+	}
+}
+
+// runSchedule runs the command given after the flags at each activation of the cron expression.
+func runSchedule(cmd *cobra.Command, args []string) error {
+	expr, _ := cmd.Flags().GetString("cron")
+	runNow, _ := cmd.Flags().GetBool("run-now")
+	healthAddr, _ := cmd.Flags().GetString("health-addr")
+	shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+	s, err := schedule.Parse(expr)
+	if err != nil {
+		return err
+	}
+	if args[0] == cmd.Name() {
+		return fmt.Errorf("the scheduled command cannot be schedule")
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find the aigile executable: %w", err)
+	}
+
+	// The persistent flags of the schedule command apply to the scheduled runs too
+	var runArgs []string
+	if configFile != "" {
+		runArgs = append(runArgs, "--config", configFile)
+	}
+	runArgs = append(runArgs, "--log-level", logLevel, "--state-db", stateDB)
+	if quiet {
+		runArgs = append(runArgs, "--quiet")
+	}
+	if verbose {
+		runArgs = append(runArgs, "--verbose")
+	}
+	if noEmoji {
+		runArgs = append(runArgs, "--no-emoji")
+	}
+	pullPrompts := appConfig.Prompts.Repository != ""
+	if cmd.Flags().Changed("prompts-dir") {
+		runArgs = append(runArgs, "--prompts-dir", promptsDir)
+		pullPrompts = false
+	}
+	runArgs = append(runArgs, args...)
+
+	if healthAddr != "" {
+		probes := health.NewServer(healthAddr)
+		if err := probes.Start(); err != nil {
+			return err
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.WithoutCancel(cmd.Context()), shutdownTimeout)
+			defer cancel()
+			if err := probes.Shutdown(ctx); err != nil {
+				slog.Warn("failed to stop health server", "error", err)
+			}
+		}()
+		probes.SetReady(true)
+	}
+
+	err = schedule.Run(cmd.Context(), s, runNow, func(ctx context.Context) error {
+		// Each run uses the latest prompt library, or the cached one when it cannot be fetched
+		if pullPrompts {
+			if lib, err := promptlib.New(appConfig.Prompts.CacheDir); err == nil {
+				if _, err := lib.Pull(ctx, promptSource()); err != nil {
+					slog.Warn("failed to update prompt library, using the cached prompts", "error", err)
+				}
+			}
+		}
+		run := exec.CommandContext(ctx, executable, runArgs...)
+		run.Stdin, run.Stdout, run.Stderr = os.Stdin, os.Stdout, os.Stderr
+		// On shutdown the run gets SIGTERM, which aborts its item in progress and records the run
+		// before it exits, and is killed after the timeout. Windows cannot send SIGTERM, the run is
+		// killed right away there.
+		run.Cancel = func() error {
+			if runtime.GOOS == "windows" {
+				return run.Process.Kill()
+			}
+			return run.Process.Signal(syscall.SIGTERM)
+		}
+		run.WaitDelay = shutdownTimeout
+		if err := run.Run(); err != nil {
+			return fmt.Errorf("failed to run %s: %w", args[0], err)
+		}
+		return nil
+	})
+	if errors.Is(err, context.Canceled) {
+		slog.Info("scheduler stopped")
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/similar"
+	"github.com/spf13/cobra"
+)
+
+var searchCmd = &cobra.Command{
+	Use:   "search <query>",
+	Short: "Find the issues of the backlog semantically similar to a query",
+	Long: `Embed the issues of the repository, or only the items of a project board, and list the ones
+most similar in meaning to the query, even when they use other words. Useful to find related work
+before writing new rows, e.g. to pick their Parent.
+
+  aigile search "payment retries" --project Checkout`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runSearch,
+}
+
+func init() {
+	rootCmd.AddCommand(searchCmd)
+	searchCmd.Flags().IntP("limit", "n", 10, "Maximum number of issues listed")
+	searchCmd.Flags().Float64("min-score", 0, "Minimum similarity of the issues listed, between 0 and 1")
+	searchCmd.Flags().StringSlice("label", nil, "Labels of the issues searched, comma separated (the issues must have all of them)")
+	searchCmd.Flags().String("state", "open", "State of the issues searched: open, closed or all")
+	searchCmd.Flags().String("project", "", "Title of a GitHub project board to search only its items")
+	searchCmd.Flags().String("provider", providerGitHub, "Issue provider where the issues are (github)")
+}
+
+// runSearch lists the issues most similar to the query by the cosine similarity of their embeddings.
+func runSearch(cmd *cobra.Command, args []string) error {
+	query := strings.Join(args, " ")
+	limit, _ := cmd.Flags().GetInt("limit")
+	minScore, _ := cmd.Flags().GetFloat64("min-score")
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	state, _ := cmd.Flags().GetString("state")
+	projectName, _ := cmd.Flags().GetString("project")
+	providerName, _ := cmd.Flags().GetString("provider")
+	if strings.TrimSpace(query) == "" {
+		return fmt.Errorf("the query cannot be empty")
+	}
+	if limit <= 0 {
+		return fmt.Errorf("invalid limit: %d (expected a positive number)", limit)
+	}
+	if minScore < 0 || minScore > 1 {
+		return fmt.Errorf("invalid minimum score: %v (expected a similarity between 0 and 1)", minScore)
+	}
+	if state != "open" && state != "closed" && state != "all" {
+		return fmt.Errorf("invalid state: %s (expected open, closed or all)", state)
+	}
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.IssueLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list issues (supported by github)", providerName)
+	}
+	embeddings, err := openIssueEmbeddings()
+	if err != nil {
+		return err
+	}
+	defer embeddings.Close()
+
+	backlog, err := lister.ListIssues(cmd.Context(), provider.IssueFilter{Labels: labels, State: state})
+	if err != nil {
+		return err
+	}
+	if projectName != "" {
+		if backlog, err = projectIssues(cmd, issues, projectName, backlog); err != nil {
+			return err
+		}
+	}
+	if len(backlog) == 0 {
+		return fmt.Errorf("no issues to search")
+	}
+
+	index, err := embeddings.index(cmd.Context(), strings.ToLower(providerName), backlog)
+	if err != nil {
+		return err
+	}
+	vector, err := embeddings.embed(cmd.Context(), similar.Text(query, ""))
+	if err != nil {
+		return err
+	}
+
+	matches := index.Nearest(vector, limit, minScore)
+	out := cmd.OutOrStdout()
+	if len(matches) == 0 {
+		_, _ = fmt.Fprintln(out, "No similar issues found.")
+		return nil
+	}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ISSUE\tSCORE\tTITLE\tURL")
+	for _, m := range matches {
+		fmt.Fprintf(w, "#%d\t%.0f%%\t%s\t%s\n", m.Number, m.Score*100, m.Title, m.URL)
+	}
+	return w.Flush()
+}
+
+// projectIssues keeps the issues that are items of the project board.
+func projectIssues(cmd *cobra.Command, issues provider.Provider, projectName string, backlog []provider.Issue) ([]provider.Issue, error) {
+	board, ok := issues.(provider.ProjectReader)
+	if !ok {
+		return nil, fmt.Errorf("the provider cannot read project boards (supported by github)")
+	}
+	project, err := issues.GetProjectByName(cmd.Context(), projectName)
+	if err != nil {
+		return nil, err
+	}
+	items, err := board.ListProjectItems(cmd.Context(), project, "")
+	if err != nil {
+		return nil, err
+	}
+	inProject := make(map[int]bool, len(items))
+	for _, item := range items {
+		inProject[item.Number] = true
+	}
+	var kept []provider.Issue
+	for _, issue := range backlog {
+		if inProject[issue.GetNumber()] {
+			kept = append(kept, issue)
+		}
+	}
+	return kept, nil
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/report"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/testinfra"
+	"github.com/spf13/cobra"
+)
+
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check the configuration of the issue provider with a full generate in a temporary project",
+	Long: `Run a full generate of a small backlog against the configured issue provider, in a temporary
+project created for each scenario, check the created issues and clean up: the issues are closed and
+the projects deleted. The scenarios run in parallel.
+
+With --ephemeral-repo, each scenario runs in a new private repository of GITHUB_OWNER, deleted with
+its issues afterwards, which needs a token with the delete_repo scope. The content is generated by
+the mock LLM provider unless --llm is given.
+
+  aigile selftest
+  aigile selftest --ephemeral-repo --llm`,
+	RunE: runSelftest,
+}
+
+func init() {
+	rootCmd.AddCommand(selftestCmd)
+	selftestCmd.Flags().String("provider", providerGitHub, "Issue provider to check (github, or any provider that can create projects)")
+	selftestCmd.Flags().Bool("ephemeral-repo", false, "Run each scenario in a new private repository, deleted afterwards (github only)")
+	selftestCmd.Flags().Bool("llm", false, "Generate the content with the configured LLM provider instead of the mock provider")
+	selftestCmd.Flags().Bool("keep", false, "Keep the projects, repositories and issues for inspection instead of cleaning them up")
+}
+
+// runSelftest runs the self-test scenarios against the issue provider and prints their outcome.
+func runSelftest(cmd *cobra.Command, _ []string) error {
+	providerName, _ := cmd.Flags().GetString("provider")
+	providerName = strings.ToLower(strings.TrimSpace(providerName))
+	ephemeral, _ := cmd.Flags().GetBool("ephemeral-repo")
+	useLLM, _ := cmd.Flags().GetBool("llm")
+	keep, _ := cmd.Flags().GetBool("keep")
+
+	var problems config.Problems
+	validateIssueProviders(&problems, []string{providerName})
+	llmConfig := llm.Config{Provider: "mock"}
+	if useLLM {
+		llmConfig = newLLMConfig()
+		validateLLM(&problems, "LLM_", llmConfig)
+	}
+	if err := problems.Err(); err != nil {
+		return err
+	}
+
+	issues, err := newIssueProvider(providerName, string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	if err := preflight(cmd.Context(), []issueTarget{{name: providerName, provider: issues}}, provider.Permissions{Issues: true, Projects: true}); err != nil {
+		return err
+	}
+	var backend testinfra.Backend = testinfra.Project{Provider: issues}
+	if ephemeral {
+		github, ok := issues.(*provider.GitHubProvider)
+		if !ok {
+			return fmt.Errorf("--ephemeral-repo is supported by the github provider only")
+		}
+		backend = testinfra.GitHubRepository{Provider: github}
+	} else if _, ok := issues.(provider.ProjectCreator); !ok {
+		return fmt.Errorf("the %s provider cannot create projects (supported by github and asana)", providerName)
+	}
+	llmProvider, err := llm.NewProvider(llmConfig)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "aigile-selftest-")
+	if err != nil {
+		return fmt.Errorf("failed to create backlog directory: %w", err)
+	}
+	if !keep {
+		defer func() { _ = os.RemoveAll(dir) }()
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Running %d scenarios against the %s provider...\n", len(testinfra.Scenarios), providerName)
+	outcomes := testinfra.Run(cmd.Context(), backend, selftestRunner(llmProvider, providerName), testinfra.Scenarios, testinfra.Options{Dir: dir, Keep: keep})
+	failed := 0
+	for _, o := range outcomes {
+		status := "PASS"
+		if o.Err != nil {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(out, "%s  %s", status, o.Scenario)
+		if o.Env != "" {
+			fmt.Fprintf(out, " (%s)", o.Env)
+		}
+		fmt.Fprintln(out)
+		if o.Err != nil {
+			fmt.Fprintf(out, "      %v\n", o.Err)
+		}
+		if o.Cleanup != nil {
+			fmt.Fprintf(out, "      cleanup failed, remove the environment manually: %v\n", o.Cleanup)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("self-test failed: %d of %d scenarios failed", failed, len(outcomes))
+	}
+	fmt.Fprintln(out, "The self-test passed.")
+	return nil
+}
+
+// selftestRunner returns a runner generating the backlog of a scenario in process, as generate does
+// with the default flags and the tasks of the Tasks column.
+func selftestRunner(llmProvider llm.Provider, providerName string) testinfra.Runner {
+	return func(ctx context.Context, env *testinfra.Env, file string) ([]report.Result, error) {
+		items, err := reader.Stream(reader.NewXLSXReader(file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read input: %w", err)
+		}
+		defer func() { _ = items.Close() }()
+
+		g := &generator{
+			llm:            llmProvider,
+			runID:          store.NewRunID(),
+			source:         file,
+			targets:        []issueTarget{{name: providerName, provider: env.Provider}},
+			language:       "english",
+			parentStrategy: parentProject,
+			epics:          make(map[string]*epicRef),
+			criteriaFormat: prompt.CriteriaGherkin,
+			headings:       i18n.For("english", appConfig.Headings),
+			collectResults: true,
+		}
+		err = g.run(ctx, reader.Validate(items, reader.Strict, g.skipRow), errorPolicyContinue)
+		return g.results, err
+	}
+}
@@ -0,0 +1,170 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/health"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/internal/webhook"
+	"github.com/spf13/cobra"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Receive GitHub webhooks to keep the local state in sync with the issues and run comment commands",
+	Long: `Serve a GitHub webhook endpoint, /webhooks/github, that keeps the local state database in sync
+with the issues aigile created in GITHUB_OWNER/GITHUB_REPO. When a story or epic is closed as
+completed, the item of its source row is marked as done.
+
+The collaborators of the repository can also comment commands on the stories:
+
+  /aigile regenerate tasks   generate the tasks of the story again from its source row, found in
+                             --source-dir or in Google Sheets, and create the missing ones
+
+The deliveries must be signed with the secret of GITHUB_WEBHOOK_SECRET. /healthz and /readyz are
+served on the same address for Kubernetes probes.
+
+  aigile serve --addr :8080`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().String("addr", ":8080", "Address to serve the webhook and health endpoints on")
+	serveCmd.Flags().Duration("shutdown-timeout", 30*time.Second, "Time given to the deliveries and commands in progress to finish after SIGTERM")
+	serveCmd.Flags().StringP("language", "g", "english", "Language to generate the content of the commands (e.g., english, portuguese)")
+	serveCmd.Flags().String("source-dir", ".", "Directory of the XLSX files the stories were generated from, read by the commands")
+	serveCmd.Flags().String("google-credentials-file", "", "Path to Google Service Account credentials JSON file, to read the Google Sheets the stories were generated from")
+	serveCmd.Flags().String("parent-strategy", parentProject, "What the Parent column links the issues to, as in generate: the project of a story is the one of its regenerated tasks")
+	serveCmd.Flags().Float64("task-threshold", 0.85, "Similarity, from 0 to 1, from which a regenerated task is one the story already has")
+}
+
+// runServe serves the webhook endpoint until the command is interrupted.
+func runServe(cmd *cobra.Command, _ []string) error {
+	addr, _ := cmd.Flags().GetString("addr")
+	shutdownTimeout, _ := cmd.Flags().GetDuration("shutdown-timeout")
+	language, _ := cmd.Flags().GetString("language")
+	sourceDir, _ := cmd.Flags().GetString("source-dir")
+	googleCredentialsFile, _ := cmd.Flags().GetString("google-credentials-file")
+	parentStrategy, _ := cmd.Flags().GetString("parent-strategy")
+	if !validParentStrategy(parentStrategy) {
+		return fmt.Errorf("invalid parent strategy: %s (expected %s, %s, %s, %s or %s)", parentStrategy, parentProject, parentEpicIssue, parentMilestone, parentNone, parentAuto)
+	}
+	taskThreshold, _ := cmd.Flags().GetFloat64("task-threshold")
+	if taskThreshold <= 0 || taskThreshold > 1 {
+		return fmt.Errorf("invalid task threshold: %v (expected a similarity between 0 and 1)", taskThreshold)
+	}
+	secret := os.Getenv("GITHUB_WEBHOOK_SECRET")
+	if secret == "" {
+		return fmt.Errorf("GITHUB_WEBHOOK_SECRET is required to verify the webhook deliveries")
+	}
+	issues, err := newIssueProvider(providerGitHub, "")
+	if err != nil {
+		return err
+	}
+	llmProvider, err := llm.NewProviderWithPolicy(newLLMConfig(), newLocalLLMConfig(), func(p llm.Provider) (llm.Provider, error) { return p, nil })
+	if err != nil {
+		return fmt.Errorf("failed to initialize LLM provider: %w", err)
+	}
+	state, err := openState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = state.Close() }()
+
+	regenerator := &taskRegenerator{
+		issues:         issues,
+		llm:            llmProvider,
+		state:          state,
+		matcher:        newTaskMatcher(taskThreshold),
+		language:       language,
+		headings:       i18n.For(language, appConfig.Headings),
+		parentStrategy: parentStrategy,
+		sourceDir:      sourceDir,
+		credentials:    googleCredentialsFile,
+		noEmoji:        noEmoji,
+	}
+	repository := os.Getenv("GITHUB_OWNER") + "/" + os.Getenv("GITHUB_REPO")
+	hooks := webhook.NewHandler(secret, repository)
+	hooks.OnIssueClosed(func(ctx context.Context, issue webhook.Issue) error {
+		return markDone(ctx, state, issue)
+	})
+	// The commands outlive the delivery, and finish on shutdown within the timeout
+	commands := context.WithoutCancel(cmd.Context())
+	hooks.OnCommand(func(_ context.Context, issue webhook.Issue, _ webhook.Comment, args []string) error {
+		regenerator.command(commands, issue, args)
+		return nil
+	})
+	probes := health.NewServer(addr)
+	mux := http.NewServeMux()
+	mux.Handle("POST /webhooks/github", hooks)
+	mux.Handle("/", probes.Handler())
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	slog.Info("serving webhooks", "addr", ln.Addr().String(), "repository", repository)
+	errs := make(chan error, 1)
+	go func() { errs <- server.Serve(ln) }()
+	probes.SetReady(true)
+
+	select {
+	case err := <-errs:
+		return fmt.Errorf("failed to serve webhooks: %w", err)
+	case <-cmd.Context().Done():
+	}
+	probes.SetReady(false)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(cmd.Context()), shutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to stop the server: %w", err)
+	}
+	if err := regenerator.wait(ctx); err != nil {
+		return err
+	}
+	slog.Info("server stopped")
+	return nil
+}
+
+// markDone marks the source rows of a story or epic closed as completed as done in the state
+// database. Issues closed as not planned, and the tasks and slices of a row, leave it unchanged.
+func markDone(ctx context.Context, state *store.Store, issue webhook.Issue) error {
+	if issue.StateReason == "not_planned" {
+		slog.Debug("issue closed as not planned, row left unchanged", "number", issue.Number)
+		return nil
+	}
+	// The store may be shared with other repositories, whose issues have the same numbers
+	records, err := state.FindByURL(ctx, providerGitHub, issue.URL)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		slog.Debug("closed issue not created by aigile", "number", issue.Number)
+		return nil
+	}
+	for _, r := range records {
+		if r.Kind != store.KindStory && r.Kind != store.KindEpic {
+			continue
+		}
+		marked, err := state.MarkDone(ctx, r.RunID, r.Row)
+		if err != nil {
+			return err
+		}
+		if marked {
+			slog.Info("row marked as done", "number", issue.Number, "source", r.Source, "row", r.Row, "run", r.RunID)
+		}
+	}
+	return nil
+}
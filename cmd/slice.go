@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+)
+
+// Where the vertical slices of an oversized story go.
+const (
+	slicesSection = "section" // A Suggested Split section of the story body
+	slicesStories = "stories" // Stories of their own, linked to the story as sub-issues
+)
+
+// slicer asks the LLM for vertical slices of the User Stories estimated above a threshold, so that
+// oversized stories come with a proposal to split them.
+type slicer struct {
+	llm       llm.Slicer
+	local     bool // Whether the LLM provider keeps the data on premises, so internal-only rows can be sliced
+	threshold int  // Story points above which a story is sliced
+	mode      string
+}
+
+// newSlicer creates the slicer of the LLM provider of config, nil when threshold is 0. The provider
+// must be able to slice stories.
+func newSlicer(config llm.Config, threshold int, mode string) (*slicer, error) {
+	if threshold < 0 {
+		return nil, fmt.Errorf("invalid slice threshold: %d", threshold)
+	}
+	if mode != slicesSection && mode != slicesStories {
+		return nil, fmt.Errorf("invalid slice mode: %s (expected %s or %s)", mode, slicesSection, slicesStories)
+	}
+	if threshold == 0 {
+		return nil, nil
+	}
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := p.(llm.Slicer)
+	if !ok {
+		return nil, fmt.Errorf("the %s LLM provider cannot slice stories (supported by openai, bedrock and mock)", cmp.Or(config.Provider, "openai"))
+	}
+	return &slicer{llm: s, local: llm.IsLocal(config), threshold: threshold, mode: mode}, nil
+}
+
+// slice returns the vertical slices of a User Story estimated above the threshold, nil when it is
+// not sliced. A story that fails to be sliced is created as it is, with a warning.
+func (g *generator) slice(ctx context.Context, item reader.Item, content *llm.GeneratedContent) []llm.GeneratedContent {
+	if g.slicer == nil || item.Type != prompt.UserStory || content.Estimate <= g.slicer.threshold {
+		return nil
+	}
+	if !g.slicer.local && strings.EqualFold(strings.TrimSpace(item.Sensitivity), llm.SensitivityInternalOnly) {
+		slog.Debug("internal-only story not sliced, the LLM provider is not local", "source", item.Source)
+		return nil
+	}
+	slices, usage, err := g.slicer.llm.SliceStory(ctx, content, g.language)
+	if err != nil {
+		g.warn(item.Source, fmt.Sprintf("failed to slice the story estimated at %d points: %v", content.Estimate, err))
+		return nil
+	}
+	slog.Info("oversized story sliced", "source", item.Source, "estimate", content.Estimate, "slices", len(slices), "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+	if len(slices) < 2 {
+		// A single slice is the story itself
+		return nil
+	}
+	return slices
+}
+
+// suggestedSplit renders the slices of a story as a section of its body, referencing the stories
+// created for them when numbers is given (0 marks a slice that could not be created).
+func suggestedSplit(slices []llm.GeneratedContent, numbers []int, headings i18n.Headings) []format.Block {
+	list := format.List{Kind: format.Ordered}
+	if numbers != nil {
+		list.Kind = format.Checklist
+	}
+	for i, s := range slices {
+		text := s.Title
+		if s.Estimate > 0 {
+			text = fmt.Sprintf("%s (%d points)", text, s.Estimate)
+		}
+		item := format.Item{Text: text}
+		if numbers == nil && s.Description != "" {
+			item.Text += ": " + s.Description
+		}
+		if i < len(numbers) {
+			item.Ref = numbers[i]
+		}
+		list.Items = append(list.Items, item)
+	}
+	return []format.Block{format.Heading{Text: headings.SuggestedSplit}, list}
+}
+
+// createSlices creates the slices of a story as stories of their own, referencing the story and
+// linked to it as sub-issues, and lists them in the story body. Failures are row warnings.
+func (g *generator) createSlices(ctx context.Context, item reader.Item, issues provider.Provider, story provider.Issue, body format.Document, slices []llm.GeneratedContent, labels []string, project *provider.ProjectInfo) []provider.Issue {
+	caps := issues.Capabilities()
+	formatter := provider.FormatterOf(issues)
+	parent := 0
+	if caps.SubIssues {
+		parent = story.GetNumber()
+	}
+
+	var created []provider.Issue
+	numbers := make([]int, len(slices))
+	for i, s := range slices {
+		sliceBody := describeContent(&s, g.criteriaFormat, nil, g.headings, item.Source)
+		sliceBody.Blocks = append([]format.Block{format.Label{Name: g.headings.SliceOf, Ref: story.GetNumber()}}, sliceBody.Blocks...)
+		title, description := g.fit(item, caps, g.decorate(titlePrefixes[prompt.UserStory], s.Title), formatter.Format(sliceBody))
+		issue, err := issues.CreateIssue(ctx, provider.CreateIssueRequest{
+			Title:     title,
+			Body:      description,
+			Labels:    labels,
+			Assignees: item.Assignees,
+			Milestone: item.Milestone,
+			Project:   project,
+			Parent:    parent,
+			Metadata:  item.Extra,
+		})
+		if err != nil {
+			g.warn(item.Source, fmt.Sprintf("failed to create slice %q of #%d: %v", s.Title, story.GetNumber(), err))
+			continue
+		}
+		g.warnAll(item.Source, issue.Warnings)
+		if estimator, ok := issues.(provider.Estimator); ok && s.Estimate > 0 {
+			if err := estimator.SetEstimate(ctx, issue.GetNumber(), s.Estimate); err != nil {
+				g.warn(item.Source, fmt.Sprintf("failed to set the estimate of #%d: %v", issue.GetNumber(), err))
+			}
+		}
+		slog.Info("slice issue created", "story", story.GetNumber(), "title", title, "number", issue.GetNumber())
+		created = append(created, issue.Issue)
+		numbers[i] = issue.GetNumber()
+	}
+
+	if len(created) > 0 {
+		_, description := g.fit(item, caps, "", formatter.Format(body.Add(suggestedSplit(slices, numbers, g.headings)...)))
+		if _, err := issues.EditIssue(ctx, story.GetNumber(), "", description); err != nil {
+			g.warn(item.Source, fmt.Sprintf("failed to list the slices in #%d: %v", story.GetNumber(), err))
+		}
+	}
+	return created
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/redact"
+)
+
+// splitter asks the LLM whether the context of a User Story row describes several independent
+// stories, so that each of them is generated and created as its own issue.
+type splitter struct {
+	llm   llm.Splitter
+	local bool // Whether the LLM provider keeps the data on premises, so internal-only rows can be split
+}
+
+// newSplitter creates the splitter of the LLM provider of config, redacting the rows first when
+// redactor is set. The provider must be able to split stories.
+func newSplitter(config llm.Config, redactor *redact.Redactor) (*splitter, error) {
+	p, err := llm.NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := p.(llm.Splitter); !ok {
+		return nil, fmt.Errorf("the %s LLM provider cannot split rows (supported by openai, bedrock, mock and replay)", cmp.Or(config.Provider, "openai"))
+	}
+	if redactor != nil {
+		p = llm.NewRedactingProvider(p, redactor)
+	}
+	return &splitter{llm: p.(llm.Splitter), local: llm.IsLocal(config)}, nil
+}
+
+// split returns the stories described by a User Story row, each with the context and criteria of
+// the row that belong to it, or the row itself when it describes a single story or is not split.
+// A row that fails to be split is generated as it is, with a warning.
+func (g *generator) split(ctx context.Context, item reader.Item) []reader.Item {
+	if g.splitter == nil || item.Type != prompt.UserStory {
+		return []reader.Item{item}
+	}
+	if !g.splitter.local && strings.EqualFold(strings.TrimSpace(item.Sensitivity), llm.SensitivityInternalOnly) {
+		slog.Debug("internal-only row not split, the LLM provider is not local", "source", item.Source)
+		return []reader.Item{item}
+	}
+	parts, usage, err := g.splitter.llm.SplitStory(ctx, llm.Request{
+		ID:          item.ID,
+		ItemType:    item.Type,
+		Parent:      item.Parent,
+		Context:     item.Context,
+		Criteria:    item.Criteria,
+		Language:    g.language,
+		Sensitivity: item.Sensitivity,
+	})
+	if err != nil {
+		g.warn(item.Source, fmt.Sprintf("failed to split the row, generated as a single story: %v", err))
+		return []reader.Item{item}
+	}
+	slog.Debug("row split checked", "source", item.Source, "stories", len(parts), "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+	if len(parts) < 2 {
+		return []reader.Item{item}
+	}
+	slog.Info("row split into stories", "source", item.Source, "stories", len(parts))
+	stories := make([]reader.Item, len(parts))
+	for i, part := range parts {
+		// Each story has an ID of its own, e.g. 12-2, so its generation and state do not overwrite
+		// the ones of the other stories of the row
+		stories[i] = item
+		stories[i].ID = fmt.Sprintf("%s-%d", item.ID, i+1)
+		stories[i].Source.Part = i + 1
+		stories[i].Context = part.Context
+		stories[i].Criteria = part.Criteria
+	}
+	return stories
+}
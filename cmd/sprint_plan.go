@@ -0,0 +1,131 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/sprint"
+	"github.com/spf13/cobra"
+)
+
+// sprintPlanLabel is the label of the issues with the sprint plans.
+const sprintPlanLabel = "Sprint Plan"
+
+var sprintPlanCmd = &cobra.Command{
+	Use:   "sprint-plan",
+	Short: "Plan a sprint from the items of a project board and the team capacity",
+	Long: `Read the open items of a GitHub project board with their estimates, commit them to the sprint in
+the order of the board until the capacity is reached, and ask the LLM to write the sprint goal and a
+summary of the plan. The plan is posted as an issue, or printed with --dry-run.`,
+	RunE: runSprintPlan,
+}
+
+func init() {
+	rootCmd.AddCommand(sprintPlanCmd)
+	sprintPlanCmd.Flags().String("project", "", "Title of the GitHub project board with the items of the sprint")
+	sprintPlanCmd.Flags().Float64("capacity", 0, "Capacity of the team for the sprint, in points")
+	sprintPlanCmd.Flags().String("estimate-field", "Estimate", "Name of the number field of the project with the estimates")
+	sprintPlanCmd.Flags().StringSlice("status", nil, "Statuses of the items considered for the sprint, comma separated (e.g. Todo,Ready); all open items by default")
+	sprintPlanCmd.Flags().String("name", "", "Name of the sprint, defaults to the date it starts")
+	sprintPlanCmd.Flags().StringP("language", "g", "english", "Language to write the sprint goal and summary (e.g., english, portuguese)")
+	sprintPlanCmd.Flags().String("provider", providerGitHub, "Issue provider where the project is (github)")
+	sprintPlanCmd.Flags().Bool("dry-run", false, "Print the sprint plan without posting it")
+	for _, name := range []string{"project", "capacity"} {
+		if err := sprintPlanCmd.MarkFlagRequired(name); err != nil {
+			panic(fmt.Sprintf("failed to mark '%s' flag as required: %v", name, err))
+		}
+	}
+}
+
+// runSprintPlan plans the sprint of a project board and posts the plan as an issue.
+func runSprintPlan(cmd *cobra.Command, _ []string) error {
+	projectName, _ := cmd.Flags().GetString("project")
+	capacity, _ := cmd.Flags().GetFloat64("capacity")
+	estimateField, _ := cmd.Flags().GetString("estimate-field")
+	statuses, _ := cmd.Flags().GetStringSlice("status")
+	name, _ := cmd.Flags().GetString("name")
+	language, _ := cmd.Flags().GetString("language")
+	providerName, _ := cmd.Flags().GetString("provider")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if capacity <= 0 {
+		return fmt.Errorf("invalid capacity: %v (expected a positive number of points)", capacity)
+	}
+	if name == "" {
+		name = "Sprint starting " + time.Now().Format(time.DateOnly)
+	}
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	board, ok := issues.(provider.ProjectReader)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot read project boards (supported by github)", providerName)
+	}
+	llmProvider, err := llm.NewProvider(newLLMConfig())
+	if err != nil {
+		return err
+	}
+	summarizer, ok := llmProvider.(llm.SprintSummarizer)
+	if !ok {
+		return fmt.Errorf("the LLM provider cannot summarize sprints (supported by openai, bedrock and mock)")
+	}
+
+	project, err := issues.GetProjectByName(cmd.Context(), projectName)
+	if err != nil {
+		return err
+	}
+	projectItems, err := board.ListProjectItems(cmd.Context(), project, estimateField)
+	if err != nil {
+		return err
+	}
+	var items []sprint.Item
+	for _, item := range projectItems {
+		if len(statuses) == 0 || containsFold(statuses, item.Status) {
+			items = append(items, sprint.Item{Number: item.Number, Title: item.Title, Status: item.Status, Points: item.Estimate})
+		}
+	}
+	if len(items) == 0 {
+		return fmt.Errorf("no open items to plan in project %s", projectName)
+	}
+
+	plan := sprint.New(name, items, capacity)
+	slog.Info("sprint planned", "project", projectName, "committed", len(plan.Committed), "points", plan.Points(),
+		"capacity", capacity, "overflow", len(plan.Overflow), "unestimated", len(plan.Unestimated))
+	summary, usage, err := summarizer.SummarizeSprint(cmd.Context(), plan, language)
+	if err != nil {
+		return err
+	}
+	slog.Info("sprint summarized", "prompt_tokens", usage.PromptTokens, "completion_tokens", usage.CompletionTokens)
+
+	title := "Sprint plan: " + name
+	body := sprint.Markdown(plan, summary.Goal, summary.Summary)
+	out := cmd.OutOrStdout()
+	if dryRun {
+		_, _ = fmt.Fprintf(out, "# %s\n\n%s", title, body)
+		return nil
+	}
+	issue, err := issues.CreateIssue(cmd.Context(), provider.CreateIssueRequest{Title: title, Body: body, Labels: []string{sprintPlanLabel}})
+	if err != nil {
+		return fmt.Errorf("failed to post sprint plan: %w", err)
+	}
+	for _, warning := range issue.Warnings {
+		slog.Warn("sprint plan posted with a warning", "warning", warning)
+	}
+	_, _ = fmt.Fprintf(out, "Sprint plan posted as #%d: %s\n", issue.GetNumber(), issue.GetHTMLURL())
+	return nil
+}
+
+// containsFold reports whether values contains value, ignoring case.
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(strings.TrimSpace(v), value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "Inspect and clean up the local state database",
+	Long:  `Inspect, export and prune the local SQLite database that records runs, processed items, token usage and created issues.`,
+}
+
+var stateShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show recorded runs, or the items and issues of a run",
+	RunE:  runStateShow,
+}
+
+var stateExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the recorded state as JSON",
+	RunE:  runStateExport,
+}
+
+var statePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete runs older than the given age",
+	RunE:  runStatePrune,
+}
+
+func init() {
+	rootCmd.AddCommand(stateCmd)
+	stateCmd.AddCommand(stateShowCmd, stateExportCmd, statePruneCmd)
+	stateShowCmd.Flags().String("run", "", "Run ID to show in detail")
+	stateExportCmd.Flags().String("run", "", "Run ID to export (defaults to all runs)")
+	stateExportCmd.Flags().StringP("output", "o", "", "Output file (defaults to stdout)")
+	statePruneCmd.Flags().Duration("older-than", 30*24*time.Hour, "Delete runs started before this age, e.g. 720h")
+}
+
+// openState opens the state database configured by the --state-db flag.
+func openState() (*store.Store, error) {
+	if stateDB == "" {
+		return nil, fmt.Errorf("state-db flag is required")
+	}
+	return store.Open(stateDB)
+}
+
+// runStateShow prints the recorded runs, or the items and issues of a single run.
+func runStateShow(cmd *cobra.Command, _ []string) error {
+	runID, _ := cmd.Flags().GetString("run")
+	s, err := openState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+	if runID == "" {
+		runs, err := s.Runs(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, "RUN\tSTATUS\tSTARTED\tVERSION\tSOURCE")
+		for _, r := range runs {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.ID, r.Status, r.StartedAt.Format(time.RFC3339), r.Version, r.Source)
+		}
+		return w.Flush()
+	}
+
+	items, err := s.Items(cmd.Context(), runID)
+	if err != nil {
+		return err
+	}
+	issues, err := s.Issues(cmd.Context(), runID)
+	if err != nil {
+		return err
+	}
+	var promptTokens, completionTokens int
+	fmt.Fprintln(w, "ROW\tTYPE\tSTATUS\tTOKENS\tSOURCE\tERROR")
+	for _, i := range items {
+		promptTokens += i.PromptTokens
+		completionTokens += i.CompletionTokens
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\t%s\n", i.Row, i.Type, i.Status, i.PromptTokens+i.CompletionTokens, i.SourceRef, i.Error)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "ROW\tPROVIDER\tKIND\tNUMBER\tURL")
+	for _, i := range issues {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", i.Row, i.Provider, i.Kind, i.Number, i.URL)
+	}
+	fmt.Fprintf(w, "\nTokens: %d prompt, %d completion\n", promptTokens, completionTokens)
+	return w.Flush()
+}
+
+// stateExport is the JSON document written by the state export command.
+type stateExport struct {
+	Runs   []store.Run        `json:"runs"`
+	Items  []store.ItemRecord `json:"items"`
+	Issues []store.Record     `json:"issues"`
+}
+
+// runStateExport writes the recorded state as JSON.
+func runStateExport(cmd *cobra.Command, _ []string) error {
+	runID, _ := cmd.Flags().GetString("run")
+	output, _ := cmd.Flags().GetString("output")
+	s, err := openState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	var export stateExport
+	runs, err := s.Runs(cmd.Context())
+	if err != nil {
+		return err
+	}
+	for _, r := range runs {
+		if runID == "" || r.ID == runID {
+			export.Runs = append(export.Runs, r)
+		}
+	}
+	if export.Items, err = s.Items(cmd.Context(), runID); err != nil {
+		return err
+	}
+	if export.Issues, err = s.Issues(cmd.Context(), runID); err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if output != "" {
+		f, err := os.Create(output) // #nosec G304 -- path comes from a CLI flag
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(export)
+}
+
+// runStatePrune deletes old runs from the state database.
+func runStatePrune(cmd *cobra.Command, _ []string) error {
+	olderThan, _ := cmd.Flags().GetDuration("older-than")
+	s, err := openState()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = s.Close() }()
+
+	removed, err := s.Prune(cmd.Context(), time.Now().Add(-olderThan))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Pruned %d run(s)\n", removed)
+	return nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/hook"
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/policy"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/telemetry"
+	"github.com/leocomelli/aigile/internal/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// defaultTelemetryFile is the local file of the usage telemetry events.
+const defaultTelemetryFile = ".aigile/telemetry.jsonl"
+
+// telemetryTimeout bounds sending an event, which must not delay the exit of the command.
+const telemetryTimeout = 3 * time.Second
+
+// usage is the usage of the command being run, completed by the commands with the details of their
+// run and recorded on exit when the telemetry is opted in.
+var usage telemetry.Event
+
+// telemetryMode returns the mode of the telemetry: the --telemetry flag, then off when DO_NOT_TRACK
+// is set, then the configuration file, off by default.
+func telemetryMode() string {
+	switch {
+	case telemetryFlag != "":
+		return telemetryFlag
+	case os.Getenv("DO_NOT_TRACK") != "" && os.Getenv("DO_NOT_TRACK") != "0":
+		return telemetry.ModeOff
+	case appConfig.Telemetry.Mode != "":
+		return appConfig.Telemetry.Mode
+	default:
+		return telemetry.ModeOff
+	}
+}
+
+// recordUsage records the usage of the command that ran, with the class of the error it failed
+// with. Telemetry failures are logged at debug level only.
+func recordUsage(ctx context.Context, cmd *cobra.Command, err error) {
+	mode := telemetryMode()
+	if mode == telemetry.ModeOff || cmd == nil {
+		return
+	}
+	file := appConfig.Telemetry.File
+	if file == "" {
+		file = defaultTelemetryFile
+	}
+	recorder, rerr := telemetry.New(mode, file, appConfig.Telemetry.Endpoint, httpclient.New(telemetryTimeout))
+	if rerr != nil {
+		slog.Debug("telemetry disabled", "error", rerr)
+		return
+	}
+
+	event := usage
+	event.Time = time.Now().UTC()
+	event.Command = cmd.CommandPath()
+	event.Version = version.Get().Version
+	event.Platform = runtime.GOOS + "/" + runtime.GOARCH
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		event.Flags = append(event.Flags, f.Name)
+	})
+	if err != nil {
+		countError(&event, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), telemetryTimeout)
+	defer cancel()
+	if rerr := recorder.Record(ctx, event); rerr != nil {
+		slog.Debug("failed to record usage", "error", rerr)
+	}
+}
+
+// countError counts the class of an error in the usage event.
+func countError(event *telemetry.Event, err error) {
+	if event.Errors == nil {
+		event.Errors = make(map[string]int)
+	}
+	event.Errors[errorClass(err)]++
+}
+
+// errorClass returns the class of an error of aigile, falling back to telemetry.Classify.
+func errorClass(err error) string {
+	var problems config.Problems
+	var sso *provider.SSORequiredError
+	var graphQL provider.GraphQLErrors
+	var blocked *policy.BlockedError
+	var rejected *hook.RejectedError
+	switch {
+	case errors.As(err, &problems):
+		return "config"
+	case errors.As(err, &sso):
+		return "sso"
+	case errors.Is(err, provider.ErrProjectNotFound):
+		return "project_not_found"
+	case errors.As(err, &graphQL):
+		return "graphql"
+	case errors.Is(err, llm.ErrPromptTooLarge):
+		return "prompt_too_large"
+	case errors.Is(err, llm.ErrSensitiveContent):
+		return "sensitive_content"
+	case errors.As(err, &blocked):
+		return "policy"
+	case errors.As(err, &rejected):
+		return "hook"
+	default:
+		return telemetry.Classify(err)
+	}
+}
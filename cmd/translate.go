@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// aigileMarker matches the hidden comments aigile adds to the issue bodies, such as the source row.
+var aigileMarker = regexp.MustCompile(`<!--\s*aigile:[^>]*-->\n?`)
+
+// Modes of the translate command.
+const (
+	translateComment = "comment"
+	translateEdit    = "edit"
+)
+
+var translateCmd = &cobra.Command{
+	Use:   "translate",
+	Short: "Translate existing issues selected by label",
+	Long: `Translate the title and body of the issues with the given labels to another language with the LLM,
+keeping the structure of the body (headings, lists, task lists, code and hidden comments). The
+translation is added as a comment, or replaces the issue content with --mode edit.`,
+	RunE: runTranslate,
+}
+
+func init() {
+	rootCmd.AddCommand(translateCmd)
+	translateCmd.Flags().StringSlice("label", nil, "Labels of the issues to translate, comma separated (the issues must have all of them), e.g. lang:es")
+	translateCmd.Flags().String("to", "", "Language to translate the issues to (e.g., english, portuguese)")
+	translateCmd.Flags().String("mode", translateComment, "How the translation is written: comment (added as a comment) or edit (replaces the title and body)")
+	translateCmd.Flags().String("state", "open", "State of the issues to translate: open, closed or all")
+	translateCmd.Flags().String("provider", providerGitHub, "Issue provider where the issues are (github)")
+	translateCmd.Flags().Bool("dry-run", false, "Print the translations without changing the issues")
+	for _, name := range []string{"label", "to"} {
+		if err := translateCmd.MarkFlagRequired(name); err != nil {
+			panic(fmt.Sprintf("failed to mark '%s' flag as required: %v", name, err))
+		}
+	}
+}
+
+// runTranslate translates the selected issues one by one, moving on when one of them fails.
+func runTranslate(cmd *cobra.Command, _ []string) error {
+	labels, _ := cmd.Flags().GetStringSlice("label")
+	language, _ := cmd.Flags().GetString("to")
+	mode, _ := cmd.Flags().GetString("mode")
+	state, _ := cmd.Flags().GetString("state")
+	providerName, _ := cmd.Flags().GetString("provider")
+	dryRun, _ := cmd.Flags().GetBool("dry-run")
+	if mode != translateComment && mode != translateEdit {
+		return fmt.Errorf("invalid mode: %s (expected comment or edit)", mode)
+	}
+	if state != "open" && state != "closed" && state != "all" {
+		return fmt.Errorf("invalid state: %s (expected open, closed or all)", state)
+	}
+
+	issues, err := newIssueProvider(strings.ToLower(providerName), string(provider.ConsoleText))
+	if err != nil {
+		return err
+	}
+	lister, ok := issues.(provider.IssueLister)
+	if !ok {
+		return fmt.Errorf("the %s provider cannot list issues (supported by github)", providerName)
+	}
+	commenter, canComment := issues.(provider.Commenter)
+	if mode == translateComment && !canComment {
+		return fmt.Errorf("the %s provider cannot comment on issues, use --mode edit", providerName)
+	}
+	llmProvider, err := llm.NewProvider(newLLMConfig())
+	if err != nil {
+		return err
+	}
+	translator, ok := llmProvider.(llm.Translator)
+	if !ok {
+		return fmt.Errorf("the LLM provider cannot translate issues (supported by openai, bedrock and mock)")
+	}
+
+	selected, err := lister.ListIssues(cmd.Context(), provider.IssueFilter{Labels: labels, State: state})
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	if len(selected) == 0 {
+		_, _ = fmt.Fprintf(out, "No %s issues with the labels %s.\n", state, strings.Join(labels, ", "))
+		return nil
+	}
+
+	var usage llm.Usage
+	failed := 0
+	for _, issue := range selected {
+		number := issue.GetNumber()
+		translation, u, err := translator.TranslateIssue(cmd.Context(), issue.GetTitle(), issue.GetBody(), language)
+		usage.PromptTokens += u.PromptTokens
+		usage.CompletionTokens += u.CompletionTokens
+		if err == nil {
+			switch {
+			case dryRun:
+				_, _ = fmt.Fprintf(out, "#%d %s\n\n%s\n\n", number, translation.Title, translation.Body)
+				continue
+			case mode == translateEdit:
+				_, err = issues.EditIssue(cmd.Context(), number, translation.Title, translation.Body)
+			default:
+				err = commenter.AddComment(cmd.Context(), number, translationComment(translation, language))
+			}
+		}
+		if err != nil {
+			if cmd.Context().Err() != nil {
+				return err
+			}
+			slog.Error("failed to translate issue", "number", number, "error", err)
+			failed++
+			continue
+		}
+		_, _ = fmt.Fprintf(out, "#%d translated (%s): %s\n", number, mode, issue.GetHTMLURL())
+	}
+
+	_, _ = fmt.Fprintf(out, "Translated %d of %d issues to %s (%d prompt and %d completion tokens).\n",
+		len(selected)-failed, len(selected), language, usage.PromptTokens, usage.CompletionTokens)
+	if failed > 0 {
+		return fmt.Errorf("failed to translate %d issues", failed)
+	}
+	return nil
+}
+
+// translationComment returns the comment with the translation of an issue. The aigile markers of
+// the body are left out, so they stay unique to the issue body.
+func translationComment(t *llm.Translation, language string) string {
+	comment := fmt.Sprintf("**Translation (%s)**\n\n### %s", language, t.Title)
+	if body := strings.TrimSpace(aigileMarker.ReplaceAllString(t.Body, "")); body != "" {
+		comment += "\n\n" + body
+	}
+	return comment
+}
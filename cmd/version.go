@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// updateCheckTimeout bounds the request of the latest release, which must not slow down the command.
+const updateCheckTimeout = 5 * time.Second
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version, commit and build date of aigile and check for a newer release",
+	Long: `Print the version, commit and build date of aigile, with the Go version and platform it was built
+for, to identify the build in bug reports, and check for a newer release on GitHub. The runs recorded
+in the state database keep the version that ran them.
+
+The check is skipped with --no-update-check or when AIGILE_NO_UPDATE_CHECK is set.`,
+	Annotations: map[string]string{deferConfigValidation: "true", skipPromptsResolution: "true"},
+	RunE:        runVersion,
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	versionCmd.Flags().Bool("no-update-check", false, "Do not check GitHub for a newer release")
+}
+
+// runVersion prints the build metadata followed by the newer release, when there is one.
+func runVersion(cmd *cobra.Command, _ []string) error {
+	info := version.Get()
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "aigile %s\n", info.Version)
+	if info.Commit != "" {
+		modified := ""
+		if info.Modified {
+			modified = " (modified)"
+		}
+		fmt.Fprintf(out, "commit: %s%s\n", info.Commit, modified)
+	}
+	if info.Date != "" {
+		fmt.Fprintf(out, "built:  %s\n", info.Date)
+	}
+	fmt.Fprintf(out, "go:     %s %s\n", info.GoVersion, info.Platform)
+
+	if skip, _ := cmd.Flags().GetBool("no-update-check"); skip || os.Getenv("AIGILE_NO_UPDATE_CHECK") != "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), updateCheckTimeout)
+	defer cancel()
+	release, err := version.Latest(ctx, httpclient.New(updateCheckTimeout))
+	if err != nil {
+		// Offline or rate limited, the version is printed anyway
+		slog.Debug("update check failed", "error", err)
+		return nil
+	}
+	if version.Newer(release.Version, info.Version) {
+		fmt.Fprintf(out, "\nA newer version is available: %s (%s)\n", release.Version, release.URL)
+	}
+	return nil
+}
@@ -0,0 +1,151 @@
+// Package config loads the aigile configuration file, which holds the settings that do not fit
+// command line flags, such as notification webhooks, the prompt library or the issue headings.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/leocomelli/aigile/internal/hook"
+	"github.com/leocomelli/aigile/internal/i18n"
+	"github.com/leocomelli/aigile/internal/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultPath is the configuration file loaded when no path is given, if it exists.
+const DefaultPath = ".aigile.yaml"
+
+// Config is the content of the configuration file.
+type Config struct {
+	Notifications []Notification `yaml:"notifications"`
+	SMTP          SMTP           `yaml:"smtp"`
+	Prompts       Prompts        `yaml:"prompts"`
+
+	// Headings override the section headings of the issue bodies by language, e.g. portuguese or pt-BR
+	Headings map[string]i18n.Headings `yaml:"headings"`
+
+	// Glossary lists the names of the product features and systems, which the grounding check of
+	// generate does not flag when the generated content mentions them
+	Glossary []string `yaml:"glossary"`
+
+	// Policies are the rules of the organization the generated content is checked against before
+	// the issues are created, e.g. not naming customers or including credentials
+	Policies []policy.Rule `yaml:"policies"`
+
+	// Hooks run the automation of the team around the generation, e.g. notifying a channel when an
+	// issue is created
+	Hooks Hooks `yaml:"hooks"`
+
+	// Computed derives fields of the issues from expressions over the row and the generated
+	// content, for mappings too simple to need a hook
+	Computed Computed `yaml:"computed"`
+
+	// Telemetry opts in to the anonymous usage telemetry, see the telemetry package
+	Telemetry Telemetry `yaml:"telemetry"`
+}
+
+// Telemetry configures the anonymous usage telemetry. The --telemetry flag takes precedence over
+// its mode.
+type Telemetry struct {
+	Mode     string `yaml:"mode"`     // off (default), local or on
+	Endpoint string `yaml:"endpoint"` // URL the events are posted to in the on mode
+	File     string `yaml:"file"`     // Local file of the events, defaults to .aigile/telemetry.jsonl
+}
+
+// Computed holds the expressions of the computed fields, see the expr package.
+type Computed struct {
+	Title   string   `yaml:"title"`   // Replaces the title of the issues
+	Labels  []string `yaml:"labels"`  // Each adds the label, or the list of labels, it returns
+	Project string   `yaml:"project"` // Name of the project the issues are added to, instead of the Parent
+}
+
+// Hooks are the hooks of each stage of the generation.
+type Hooks struct {
+	PreGenerate []hook.Hook `yaml:"pre_generate"` // Run before the content of each row is generated, to transform or reject it
+	PostCreate  []hook.Hook `yaml:"post_create"`  // Run after each issue is created
+}
+
+// Prompts configures a Git repository the prompt files are loaded from, e.g. a prompt library
+// shared across teams. The --prompts-dir flag takes precedence over it.
+type Prompts struct {
+	Repository string `yaml:"repository"` // Clone URL
+	Ref        string `yaml:"ref"`        // Branch, tag or commit, defaults to the default branch
+	Path       string `yaml:"path"`       // Directory of the prompt files inside the repository
+	CacheDir   string `yaml:"cache_dir"`  // Local cache of the repository, defaults to the user cache directory
+}
+
+// Notification configures a webhook or email notified at the end of each run.
+type Notification struct {
+	Type string   `yaml:"type"` // slack, teams or email
+	URL  string   `yaml:"url"`  // Incoming webhook URL, ${VAR} references are expanded from the environment
+	To   []string `yaml:"to"`   // Email recipients
+	On   string   `yaml:"on"`   // always (default) or failure
+}
+
+// SMTP configures the server used by the email notifications.
+type SMTP struct {
+	Host     string `yaml:"host"`
+	Port     int    `yaml:"port"` // Defaults to DefaultSMTPPort
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	From     string `yaml:"from"`
+}
+
+// DefaultSMTPPort is the SMTP submission port, used when the port is not set.
+const DefaultSMTPPort = 587
+
+// Notification types and triggers.
+const (
+	NotifySlack = "slack"
+	NotifyTeams = "teams"
+	NotifyEmail = "email"
+
+	NotifyAlways    = "always"
+	NotifyOnFailure = "failure"
+)
+
+// Load reads the configuration file at path. An empty path loads DefaultPath, and returns an
+// empty configuration when it does not exist. An invalid configuration is returned along with its
+// Problems.
+func Load(path string) (*Config, error) {
+	explicit := path != ""
+	if !explicit {
+		path = DefaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal([]byte(os.ExpandEnv(string(data))), &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return &cfg, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Secrets returns the secrets of the configuration, masked in the logs and errors: the SMTP
+// password, the notification webhook URLs, which embed their credentials, and the header values of
+// the webhook hooks.
+func (c *Config) Secrets() []string {
+	secrets := []string{c.SMTP.Password}
+	for _, n := range c.Notifications {
+		secrets = append(secrets, n.URL)
+	}
+	for _, h := range append(slices.Clone(c.Hooks.PreGenerate), c.Hooks.PostCreate...) {
+		for _, value := range h.Headers {
+			secrets = append(secrets, value)
+		}
+	}
+	return secrets
+}
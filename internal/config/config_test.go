@@ -0,0 +1,135 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/hook"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "aigile.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	t.Setenv("SLACK_WEBHOOK_URL", "https://hooks.slack.com/services/T/B/X")
+	path := writeConfig(t, `
+notifications:
+  - type: slack
+    url: ${SLACK_WEBHOOK_URL}
+  - type: teams
+    url: https://example.webhook.office.com/webhookb2/abc
+    on: failure
+  - type: email
+    to: [team@example.com]
+smtp:
+  host: smtp.example.com
+  from: aigile@example.com
+prompts:
+  repository: https://github.com/acme/prompt-library.git
+  ref: v1.2.0
+  path: aigile
+headings:
+  pt-BR:
+    acceptance_criteria: Critérios de Aceite
+glossary: [Adyen, Order Service]
+hooks:
+  post_create:
+    - name: roadmap
+      url: https://roadmap.example.com/hooks/aigile
+      headers: {Authorization: Bearer abc}
+      kinds: [epic]
+      timeout: 5s
+computed:
+  title: 'Parent + ": " + summary(Context)'
+  labels: ['"team:" + lower(Extra.team)']
+  project: Extra.board
+`)
+
+	cfg, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Notification{
+		{Type: NotifySlack, URL: "https://hooks.slack.com/services/T/B/X"},
+		{Type: NotifyTeams, URL: "https://example.webhook.office.com/webhookb2/abc", On: NotifyOnFailure},
+		{Type: NotifyEmail, To: []string{"team@example.com"}},
+	}, cfg.Notifications)
+	assert.Equal(t, "smtp.example.com", cfg.SMTP.Host)
+	assert.Equal(t, "Critérios de Aceite", cfg.Headings["pt-BR"].AcceptanceCriteria)
+	assert.Equal(t, []string{"Adyen", "Order Service"}, cfg.Glossary)
+	assert.Equal(t, Prompts{Repository: "https://github.com/acme/prompt-library.git", Ref: "v1.2.0", Path: "aigile"}, cfg.Prompts)
+	assert.Equal(t, []hook.Hook{{
+		Name:    "roadmap",
+		URL:     "https://roadmap.example.com/hooks/aigile",
+		Headers: map[string]string{"Authorization": "Bearer abc"},
+		Kinds:   []string{"epic"},
+		Timeout: 5 * time.Second,
+	}}, cfg.Hooks.PostCreate)
+	assert.Equal(t, Computed{
+		Title:   `Parent + ": " + summary(Context)`,
+		Labels:  []string{`"team:" + lower(Extra.team)`},
+		Project: "Extra.board",
+	}, cfg.Computed)
+}
+
+func TestLoad_Default(t *testing.T) {
+	t.Chdir(t.TempDir())
+	cfg, err := Load("")
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Notifications)
+
+	_, err = Load("missing.yaml")
+	assert.ErrorContains(t, err, "failed to read config file")
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"notifications: [":                                           "failed to parse config file",
+		"notifications:\n  - type: sms\n    url: x":                  `unsupported type "sms"`,
+		"notifications:\n  - type: email":                            "to is required",
+		"notifications:\n  - type: email\n    to: [a@example.com]":   "smtp host and from are required",
+		"notifications:\n  - type: slack":                            "url is required",
+		"notifications:\n  - type: slack\n    url: x\n    on: never": `unsupported trigger "never"`,
+		"telemetry:\n  mode: always":                                 `unsupported mode "always"`,
+		"telemetry:\n  mode: on":                                     "endpoint is required to send the events",
+	}
+	for content, expected := range tests {
+		_, err := Load(writeConfig(t, content))
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestLoad_Problems(t *testing.T) {
+	path := writeConfig(t, `
+notifications:
+  - type: slack
+  - type: sms
+    on: never
+prompts:
+  ref: v1
+hooks:
+  pre_generate:
+    - command: [normalize]
+      url: https://example.com/hook
+`)
+	cfg, err := Load(path)
+	require.NotNil(t, cfg)
+	var problems Problems
+	require.ErrorAs(t, err, &problems)
+	assert.Equal(t, Problems{
+		{Setting: "notifications[0]", Message: "url is required", Fix: "set url to the incoming webhook URL of the channel, e.g. url: ${SLACK_WEBHOOK_URL}"},
+		{Setting: "notifications[1]", Message: `unsupported type "sms"`, Fix: "use slack, teams or email"},
+		{Setting: "notifications[1]", Message: `unsupported trigger "never"`, Fix: "use always or failure"},
+		{Setting: "prompts", Message: "ref and path need a repository", Fix: "set prompts.repository to the clone URL of the prompt library"},
+		{Setting: "hooks.pre_generate[0]", Message: "exactly one of command and url is required"},
+	}, problems)
+	assert.Contains(t, err.Error(), "invalid config file "+path+": 5 problems found:\n  - notifications[0]: url is required (fix: set url")
+
+	assert.EqualError(t, Problems{{Setting: "LLM_MODEL", Message: "is required"}}, "LLM_MODEL: is required")
+	assert.NoError(t, Problems(nil).Err())
+}
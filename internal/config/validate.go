@@ -0,0 +1,107 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/hook"
+	"github.com/leocomelli/aigile/internal/policy"
+	"github.com/leocomelli/aigile/internal/telemetry"
+)
+
+// Problem is an invalid or missing setting, with the way to fix it.
+type Problem struct {
+	Setting string // e.g. notifications[0].url, LLM_API_KEY or --google-credentials-file
+	Message string
+	Fix     string // Suggested fix, empty when the message says it all
+}
+
+func (p Problem) String() string {
+	if p.Fix == "" {
+		return fmt.Sprintf("%s: %s", p.Setting, p.Message)
+	}
+	return fmt.Sprintf("%s: %s (fix: %s)", p.Setting, p.Message, p.Fix)
+}
+
+// Problems are the problems of a configuration, reported all at once rather than one per run.
+type Problems []Problem
+
+// Add adds a problem of the setting.
+func (p *Problems) Add(setting, message, fix string) {
+	*p = append(*p, Problem{Setting: setting, Message: message, Fix: fix})
+}
+
+// Err returns the problems as an error, nil when there are none.
+func (p Problems) Err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return p
+}
+
+func (p Problems) Error() string {
+	if len(p) == 1 {
+		return p[0].String()
+	}
+	lines := make([]string, len(p))
+	for i, problem := range p {
+		lines[i] = "\n  - " + problem.String()
+	}
+	return fmt.Sprintf("%d problems found:%s", len(p), strings.Join(lines, ""))
+}
+
+// Validate checks the values of the configuration, returning all its Problems.
+func (c *Config) Validate() error {
+	var problems Problems
+	for i, n := range c.Notifications {
+		setting := fmt.Sprintf("notifications[%d]", i)
+		switch n.Type {
+		case NotifySlack, NotifyTeams:
+			if n.URL == "" {
+				problems.Add(setting, "url is required", "set url to the incoming webhook URL of the channel, e.g. url: ${SLACK_WEBHOOK_URL}")
+			}
+		case NotifyEmail:
+			if len(n.To) == 0 {
+				problems.Add(setting, "to is required", "list the recipients, e.g. to: [team@example.com]")
+			}
+			if c.SMTP.Host == "" || c.SMTP.From == "" {
+				problems.Add(setting, "smtp host and from are required for email notifications", "set smtp.host and smtp.from")
+			}
+		default:
+			problems.Add(setting, fmt.Sprintf("unsupported type %q", n.Type), fmt.Sprintf("use %s, %s or %s", NotifySlack, NotifyTeams, NotifyEmail))
+		}
+		switch n.On {
+		case "", NotifyAlways, NotifyOnFailure:
+		default:
+			problems.Add(setting, fmt.Sprintf("unsupported trigger %q", n.On), fmt.Sprintf("use %s or %s", NotifyAlways, NotifyOnFailure))
+		}
+	}
+
+	if c.Prompts.Repository == "" && (c.Prompts.Ref != "" || c.Prompts.Path != "") {
+		problems.Add("prompts", "ref and path need a repository", "set prompts.repository to the clone URL of the prompt library")
+	}
+	if _, err := policy.New(c.Policies); err != nil {
+		problems.Add("policies", err.Error(), "")
+	}
+	for _, stage := range []struct {
+		name  string
+		hooks []hook.Hook
+	}{{"pre_generate", c.Hooks.PreGenerate}, {"post_create", c.Hooks.PostCreate}} {
+		for i, h := range stage.hooks {
+			if _, err := hook.New([]hook.Hook{h}); err != nil {
+				_, message, _ := strings.Cut(err.Error(), ": ")
+				problems.Add(fmt.Sprintf("hooks.%s[%d]", stage.name, i), message, "")
+			}
+		}
+	}
+	switch c.Telemetry.Mode {
+	case "", telemetry.ModeOff, telemetry.ModeLocal:
+	case telemetry.ModeOn:
+		if c.Telemetry.Endpoint == "" {
+			problems.Add("telemetry", "endpoint is required to send the events", "set telemetry.endpoint, or use mode: local to only record them locally")
+		}
+	default:
+		problems.Add("telemetry", fmt.Sprintf("unsupported mode %q", c.Telemetry.Mode), fmt.Sprintf("use %s, %s or %s", telemetry.ModeOff, telemetry.ModeLocal, telemetry.ModeOn))
+	}
+	return problems.Err()
+}
@@ -0,0 +1,59 @@
+// Package diff compares texts line by line, to review the changes proposed to an issue.
+package diff
+
+import "strings"
+
+// Lines returns the lines of b compared to a, as in a unified diff without hunk headers: removed
+// lines are prefixed with "-", added lines with "+" and kept lines with a space. It returns an empty
+// string when the texts have the same lines.
+func Lines(a, b string) string {
+	before, after := split(a), split(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of before[i:] and after[j:]
+	lcs := make([][]int, len(before)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var sb strings.Builder
+	changed := false
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			sb.WriteString(" " + before[i] + "\n")
+			i++
+			j++
+		case i < len(before) && (j == len(after) || lcs[i+1][j] >= lcs[i][j+1]):
+			sb.WriteString("-" + before[i] + "\n")
+			changed = true
+			i++
+		default:
+			sb.WriteString("+" + after[j] + "\n")
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return sb.String()
+}
+
+// split returns the lines of a text, without the trailing empty line.
+func split(text string) []string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
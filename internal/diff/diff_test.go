@@ -0,0 +1,19 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLines(t *testing.T) {
+	before := "Pay by card.\n\n## Notes\nTBD\n"
+	after := "As a buyer, I want to pay by card.\n\n## Notes\n\n## Acceptance Criteria\n- Visa is accepted\n"
+	expected := "-Pay by card.\n+As a buyer, I want to pay by card.\n \n ## Notes\n-TBD\n+\n+## Acceptance Criteria\n+- Visa is accepted\n"
+	assert.Equal(t, expected, Lines(before, after))
+}
+
+func TestLines_Same(t *testing.T) {
+	assert.Empty(t, Lines("a\r\nb\n", "a\nb"))
+	assert.Equal(t, "+a\n", Lines("", "a"))
+}
@@ -0,0 +1,52 @@
+package document
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// voicePattern matches the voice span of a WebVTT cue, which names the speaker.
+	voicePattern = regexp.MustCompile(`^<v(?:\.[^ >]*)? ([^>]+)>`)
+	// tagPattern matches the other cue tags, such as <b>, <i> and the closing tags.
+	tagPattern = regexp.MustCompile(`</?[^>]*>`)
+)
+
+// captionText returns the spoken text of WebVTT or SubRip captions: one line per cue, prefixed
+// with the speaker when the cue names one, without the header, notes, cue numbers and timings.
+func captionText(data string) string {
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	var sb strings.Builder
+	for _, block := range strings.Split(data, "\n\n") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if lines[0] == "" || strings.HasPrefix(lines[0], "WEBVTT") || strings.HasPrefix(lines[0], "NOTE") ||
+			strings.HasPrefix(lines[0], "STYLE") || strings.HasPrefix(lines[0], "REGION") {
+			continue
+		}
+
+		var cue []string
+		speaker := ""
+		timed := false
+		for _, line := range lines {
+			if !timed {
+				// Cue identifiers come before the timing line
+				timed = strings.Contains(line, "-->")
+				continue
+			}
+			if m := voicePattern.FindStringSubmatch(line); m != nil && speaker == "" {
+				speaker = strings.TrimSpace(m[1])
+			}
+			if line = strings.TrimSpace(tagPattern.ReplaceAllString(line, "")); line != "" {
+				cue = append(cue, line)
+			}
+		}
+		if len(cue) == 0 {
+			continue
+		}
+		if speaker != "" {
+			sb.WriteString(speaker + ": ")
+		}
+		sb.WriteString(strings.Join(cue, " ") + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,154 @@
+package document
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChunkSize is the maximum length of a chunk, in characters.
+const ChunkSize = 1500
+
+// docExtensions are the extensions of the files read by Load; captions are left out, as they are
+// meeting transcripts rather than product documentation.
+var docExtensions = map[string]bool{".md": true, ".markdown": true, ".txt": true, ".docx": true, ".pdf": true}
+
+// Chunk is a part of a document small enough to be retrieved and sent to the LLM on its own.
+type Chunk struct {
+	Source  string // Path of the document
+	Heading string // Closest Markdown heading above the chunk, if any
+	Text    string
+}
+
+// Load reads the documents at paths, walking directories for Markdown, text, Word and PDF files
+// (hidden directories, such as .git, are skipped), and splits them into chunks by document.
+func Load(paths []string) (map[string][]Chunk, error) {
+	chunks := make(map[string][]Chunk)
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documentation: %w", err)
+		}
+		if !info.IsDir() {
+			text, err := Extract(path)
+			if err != nil {
+				return nil, err
+			}
+			chunks[path] = Split(path, text, ChunkSize)
+			continue
+		}
+		err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if file != path && strings.HasPrefix(d.Name(), ".") {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !docExtensions[strings.ToLower(filepath.Ext(file))] {
+				return nil
+			}
+			text, err := Extract(file)
+			if err != nil {
+				// Files without text, such as empty wiki pages, or that cannot be extracted are skipped
+				return nil
+			}
+			chunks[file] = Split(file, text, ChunkSize)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to read documentation: %w", err)
+		}
+	}
+	return chunks, nil
+}
+
+// Split splits the text of a document into chunks of up to size characters. Markdown headings
+// start a new chunk, and paragraphs are kept together unless they are longer than size.
+func Split(source, text string, size int) []Chunk {
+	var chunks []Chunk
+	var current strings.Builder
+	heading := ""
+	flush := func() {
+		if t := strings.TrimSpace(current.String()); t != "" {
+			chunks = append(chunks, Chunk{Source: source, Heading: heading, Text: t})
+		}
+		current.Reset()
+	}
+
+	for _, paragraph := range paragraphs(text) {
+		if title, ok := markdownHeading(paragraph); ok {
+			flush()
+			heading = title
+			continue
+		}
+		for _, part := range cut(paragraph, size) {
+			if current.Len() > 0 && current.Len()+len(part)+2 > size {
+				flush()
+			}
+			if current.Len() > 0 {
+				current.WriteString("\n\n")
+			}
+			current.WriteString(part)
+		}
+	}
+	flush()
+	return chunks
+}
+
+// paragraphs splits a text on blank lines, with the Markdown headings as paragraphs of their own.
+func paragraphs(text string) []string {
+	var result []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			result = append(result, strings.Join(current, "\n"))
+			current = nil
+		}
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		switch _, isHeading := markdownHeading(line); {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case isHeading:
+			flush()
+			result = append(result, line)
+		default:
+			current = append(current, line)
+		}
+	}
+	flush()
+	return result
+}
+
+// markdownHeading returns the title of an ATX Markdown heading line, e.g. "## Retries".
+func markdownHeading(line string) (string, bool) {
+	trimmed := strings.TrimLeft(line, "#")
+	level := len(line) - len(trimmed)
+	if level == 0 || level > 6 || !strings.HasPrefix(trimmed, " ") {
+		return "", false
+	}
+	return strings.TrimSpace(strings.TrimRight(trimmed, "# ")), true
+}
+
+// cut splits a paragraph longer than size on the last space before each limit.
+func cut(paragraph string, size int) []string {
+	var parts []string
+	runes := []rune(paragraph)
+	for len(runes) > size {
+		end := size
+		for i := size; i > size/2; i-- {
+			if runes[i] == ' ' || runes[i] == '\n' {
+				end = i
+				break
+			}
+		}
+		parts = append(parts, strings.TrimSpace(string(runes[:end])))
+		runes = runes[end:]
+	}
+	return append(parts, strings.TrimSpace(string(runes)))
+}
@@ -0,0 +1,57 @@
+package document
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	text := "Intro paragraph.\n\n# Payments\n\nCards are charged\non checkout.\n\n## Retries ##\n\nFailed payments are retried.\n\nUp to three times.\n"
+	chunks := Split("docs/payments.md", text, 100)
+	assert.Equal(t, []Chunk{
+		{Source: "docs/payments.md", Text: "Intro paragraph."},
+		{Source: "docs/payments.md", Heading: "Payments", Text: "Cards are charged\non checkout."},
+		{Source: "docs/payments.md", Heading: "Retries", Text: "Failed payments are retried.\n\nUp to three times."},
+	}, chunks)
+
+	chunks = Split("a.md", "First paragraph.\n\nSecond paragraph.", 20)
+	require.Len(t, chunks, 2)
+	assert.Equal(t, "Second paragraph.", chunks[1].Text)
+
+	chunks = Split("a.md", strings.Repeat("word ", 100), 60)
+	assert.Greater(t, len(chunks), 1)
+	for _, c := range chunks {
+		assert.LessOrEqual(t, len(c.Text), 60)
+	}
+	assert.Empty(t, Split("a.md", "# Only a heading\n", 100))
+	assert.Len(t, Split("a.md", "#hashtag is not a heading", 100), 1)
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "guides"), 0o750))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0o750))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Home.md"), []byte("# Home\n\nWelcome."), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "guides", "refunds.txt"), []byte("Refunds take 5 days."), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "guides", "logo.png"), []byte("png"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "empty.md"), nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "HEAD.md"), []byte("ignored"), 0o600))
+
+	chunks, err := Load([]string{dir})
+	require.NoError(t, err)
+	assert.Len(t, chunks, 2)
+	assert.Equal(t, "Welcome.", chunks[filepath.Join(dir, "Home.md")][0].Text)
+	assert.Equal(t, "Refunds take 5 days.", chunks[filepath.Join(dir, "guides", "refunds.txt")][0].Text)
+
+	chunks, err = Load([]string{filepath.Join(dir, "Home.md")})
+	require.NoError(t, err)
+	assert.Len(t, chunks, 1)
+
+	_, err = Load([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,96 @@
+// Package document extracts the text of product documents (Markdown, plain text, Word and PDF)
+// and meeting transcripts (WebVTT and SubRip captions) so they can be sent to the LLM.
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Extract returns the text of the document at path, chosen by the file extension: .md, .markdown
+// and .txt are read as is, .docx and .pdf have their text extracted and the .vtt and .srt captions
+// have their cue numbers and timings removed.
+func Extract(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read document: %w", err)
+	}
+
+	var text string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".md", ".markdown", ".txt":
+		text = string(data)
+	case ".docx":
+		if text, err = docxText(data); err != nil {
+			return "", fmt.Errorf("failed to extract the text of %s: %w", filepath.Base(path), err)
+		}
+	case ".pdf":
+		if text, err = pdfText(data); err != nil {
+			return "", fmt.Errorf("failed to extract the text of %s: %w", filepath.Base(path), err)
+		}
+	case ".vtt", ".srt":
+		text = captionText(string(data))
+	default:
+		return "", fmt.Errorf("unsupported document format: %s (expected .md, .txt, .docx, .pdf, .vtt or .srt)", ext)
+	}
+
+	if strings.TrimSpace(text) == "" {
+		return "", fmt.Errorf("document %s has no text", filepath.Base(path))
+	}
+	return text, nil
+}
+
+// docxText returns the text of the paragraphs of a Word document, one per line.
+func docxText(data []byte) (string, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+	f, err := archive.Open("word/document.xml")
+	if err != nil {
+		return "", fmt.Errorf("invalid docx file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var sb strings.Builder
+	decoder := xml.NewDecoder(f)
+	inText := false
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("invalid docx document: %w", err)
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "t":
+				inText = true
+			case "tab":
+				sb.WriteString("\t")
+			case "br":
+				sb.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				sb.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+}
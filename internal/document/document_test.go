@@ -0,0 +1,86 @@
+package document
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/zlib"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, name string, data []byte) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	return path
+}
+
+func TestExtract_Markdown(t *testing.T) {
+	text, err := Extract(writeFile(t, "prd.md", []byte("# Checkout\n\n- Pay by card\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "# Checkout\n\n- Pay by card\n", text)
+}
+
+func TestExtract_Docx(t *testing.T) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	w, err := archive.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>
+<w:p><w:r><w:t>Checkout</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Pay by </w:t></w:r><w:r><w:t>card &amp; wallet</w:t></w:r></w:p>
+</w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+
+	text, err := Extract(writeFile(t, "prd.docx", buf.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout\nPay by card & wallet\n", text)
+}
+
+func TestExtract_PDF(t *testing.T) {
+	var stream bytes.Buffer
+	zw := zlib.NewWriter(&stream)
+	_, err := zw.Write([]byte("BT /F1 12 Tf 72 720 Td (Checkout) Tj 0 -14 Td [(Pay by ) -20 (card \\(Visa\\))] TJ T* (Refunds\\04112) Tj ET"))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	pdf := []byte("%PDF-1.4\n1 0 obj\n<< /Length 10 /Filter /FlateDecode >>\nstream\n")
+	pdf = append(pdf, stream.Bytes()...)
+	pdf = append(pdf, []byte("\nendstream\nendobj\n%%EOF\n")...)
+
+	text, err := Extract(writeFile(t, "prd.pdf", pdf))
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout\nPay by card (Visa)\nRefunds!12\n", text)
+}
+
+func TestExtract_Captions(t *testing.T) {
+	vtt := "WEBVTT\n\nNOTE recorded by the meeting app\n\n1\n00:00:01.000 --> 00:00:04.000\n<v Ana>We need refunds</v>\n<v Ana>in the checkout.</v>\n\n" +
+		"2\n00:00:05.000 --> 00:00:07.000 align:start\n<v Bruno>Agreed, <b>by card</b>.</v>\n"
+	text, err := Extract(writeFile(t, "meeting.vtt", []byte(vtt)))
+	require.NoError(t, err)
+	assert.Equal(t, "Ana: We need refunds in the checkout.\nBruno: Agreed, by card.\n", text)
+
+	srt := "1\r\n00:00:01,000 --> 00:00:04,000\r\nAna: Monthly reports\r\n\r\n2\r\n00:00:05,000 --> 00:00:07,000\r\nin PDF.\r\n"
+	text, err = Extract(writeFile(t, "meeting.srt", []byte(srt)))
+	require.NoError(t, err)
+	assert.Equal(t, "Ana: Monthly reports\nin PDF.\n", text)
+}
+
+func TestExtract_Errors(t *testing.T) {
+	_, err := Extract(writeFile(t, "prd.odt", []byte("x")))
+	assert.EqualError(t, err, "unsupported document format: .odt (expected .md, .txt, .docx, .pdf, .vtt or .srt)")
+
+	_, err = Extract(writeFile(t, "prd.md", []byte(" \n")))
+	assert.EqualError(t, err, "document prd.md has no text")
+
+	_, err = Extract(writeFile(t, "prd.pdf", []byte("not a pdf")))
+	assert.ErrorContains(t, err, "failed to extract the text of prd.pdf: invalid pdf file")
+
+	_, err = Extract(filepath.Join(t.TempDir(), "missing.md"))
+	assert.ErrorContains(t, err, "failed to read document")
+}
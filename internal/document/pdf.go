@@ -0,0 +1,136 @@
+package document
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// streamPattern matches the content streams of a PDF file with their dictionary.
+var streamPattern = regexp.MustCompile(`(?s)<<(.*?)>>\s*stream\r?\n(.*?)\r?\nendstream`)
+
+// pdfText returns the text shown by the content streams of a PDF file. It covers text-based PDFs
+// with uncompressed or Flate compressed streams and literal strings, as exported by most word
+// processors; scanned documents and fonts with custom encodings yield no text.
+func pdfText(data []byte) (string, error) {
+	if !bytes.HasPrefix(data, []byte("%PDF-")) {
+		return "", errors.New("invalid pdf file")
+	}
+	var sb strings.Builder
+	for _, m := range streamPattern.FindAllSubmatch(data, -1) {
+		dict, stream := m[1], m[2]
+		if bytes.Contains(dict, []byte("/FlateDecode")) {
+			r, err := zlib.NewReader(bytes.NewReader(stream))
+			if err != nil {
+				continue
+			}
+			// Truncated streams still yield their readable part
+			stream, _ = io.ReadAll(r)
+		} else if bytes.Contains(dict, []byte("/Filter")) {
+			continue
+		}
+		contentText(&sb, stream)
+	}
+	return sb.String(), nil
+}
+
+// contentText writes the strings shown by the text operators of a content stream, breaking lines
+// on the operators that move to the next line and at the end of each text object.
+func contentText(sb *strings.Builder, stream []byte) {
+	var line strings.Builder
+	flush := func() {
+		if text := strings.TrimSpace(line.String()); text != "" {
+			sb.WriteString(text + "\n")
+		}
+		line.Reset()
+	}
+
+	for i := 0; i < len(stream); i++ {
+		switch c := stream[i]; {
+		case c == '(':
+			var s string
+			s, i = literalString(stream, i)
+			line.WriteString(s)
+		case c == '%':
+			for i < len(stream) && stream[i] != '\n' && stream[i] != '\r' {
+				i++
+			}
+		case isOperatorStart(stream, i, "T*"), isOperatorStart(stream, i, "Td"), isOperatorStart(stream, i, "TD"), isOperatorStart(stream, i, "ET"):
+			flush()
+			i++
+		case c == '\'' || c == '"':
+			flush()
+		}
+	}
+	flush()
+}
+
+// isOperatorStart reports whether the operator op starts at position i of a content stream, as
+// a token of its own.
+func isOperatorStart(stream []byte, i int, op string) bool {
+	if !bytes.HasPrefix(stream[i:], []byte(op)) {
+		return false
+	}
+	if i > 0 && !isDelimiter(stream[i-1]) {
+		return false
+	}
+	end := i + len(op)
+	return end == len(stream) || isDelimiter(stream[end])
+}
+
+// isDelimiter reports whether c separates the tokens of a content stream.
+func isDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// literalString decodes the literal string starting at the opening parenthesis at position
+// start, returning it and the position of its closing parenthesis.
+func literalString(stream []byte, start int) (string, int) {
+	var sb strings.Builder
+	depth := 0
+	for i := start; i < len(stream); i++ {
+		c := stream[i]
+		switch {
+		case c == '\\' && i+1 < len(stream):
+			i++
+			switch e := stream[i]; e {
+			case 'n':
+				sb.WriteByte('\n')
+			case 'r', 'f', 'b':
+			case 't':
+				sb.WriteByte('\t')
+			case '\r', '\n':
+				// Line continuation
+			default:
+				if e >= '0' && e <= '7' {
+					n := 0
+					for j := 0; j < 3 && i < len(stream) && stream[i] >= '0' && stream[i] <= '7'; j++ {
+						n = n*8 + int(stream[i]-'0')
+						i++
+					}
+					i--
+					sb.WriteByte(byte(n))
+				} else {
+					sb.WriteByte(e)
+				}
+			}
+		case c == '(':
+			if depth > 0 {
+				sb.WriteByte(c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return sb.String(), i
+			}
+			sb.WriteByte(c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String(), len(stream)
+}
@@ -0,0 +1,90 @@
+// Package emoji degrades emoji and other symbols to ASCII, for terminals that cannot display
+// them, such as the legacy Windows console.
+package emoji
+
+import (
+	"runtime"
+	"strings"
+)
+
+// replacements are the ASCII forms of the symbols that carry meaning; other emoji are removed.
+var replacements = strings.NewReplacer(
+	"↳", "->",
+	"→", "->",
+	"•", "*",
+	"☐", "[ ]",
+	"✅", "[x]",
+	"❌", "[!]",
+	"─", "-",
+	"│", "|",
+	"╭", "+",
+	"╮", "+",
+	"╰", "+",
+	"╯", "+",
+)
+
+// isEmoji reports whether r is an emoji or a part of an emoji sequence (variation selector, zero
+// width joiner, skin tone or regional indicator).
+func isEmoji(r rune) bool {
+	switch {
+	case r >= 0x1F000 && r <= 0x1FAFF: // Pictographs, emoticons, transport, flags, skin tones...
+		return true
+	case r >= 0x2600 && r <= 0x27BF: // Miscellaneous symbols and dingbats
+		return true
+	case r >= 0x2B00 && r <= 0x2BFF: // Miscellaneous symbols and arrows
+		return true
+	case r == 0xFE0F || r == 0x200D || r == 0x20E3:
+		return true
+	}
+	return false
+}
+
+// Strip removes the emoji of s, with the space following them, so "[📖 User Story]" becomes
+// "[User Story]".
+func Strip(s string) string {
+	var sb strings.Builder
+	removed := false
+	for _, r := range s {
+		if isEmoji(r) {
+			removed = true
+			continue
+		}
+		if removed && r == ' ' {
+			removed = false
+			continue
+		}
+		removed = false
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// ASCII replaces the symbols of s with ASCII equivalents and removes its emoji.
+func ASCII(s string) string {
+	return Strip(replacements.Replace(s))
+}
+
+// Supported reports whether the terminal can display emoji, according to the environment: the
+// legacy Windows console and terminals without a UTF-8 locale cannot.
+func Supported(getenv func(string) string) bool {
+	return supported(getenv, runtime.GOOS)
+}
+
+func supported(getenv func(string) string, goos string) bool {
+	if goos == "windows" {
+		// Windows Terminal, VS Code and ConEmu render emoji, the legacy console does not
+		return getenv("WT_SESSION") != "" || getenv("TERM_PROGRAM") == "vscode" || getenv("ConEmuANSI") == "ON"
+	}
+	if getenv("TERM") == "linux" {
+		// The Linux virtual console has no emoji glyphs
+		return false
+	}
+	for _, name := range []string{"LC_ALL", "LC_CTYPE", "LANG"} {
+		if locale := getenv(name); locale != "" {
+			locale = strings.ToLower(locale)
+			return strings.Contains(locale, "utf-8") || strings.Contains(locale, "utf8")
+		}
+	}
+	// Without a locale, assume a modern terminal
+	return true
+}
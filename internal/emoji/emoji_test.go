@@ -0,0 +1,33 @@
+package emoji
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrip(t *testing.T) {
+	assert.Equal(t, "[User Story] Pay by card", Strip("[📖 User Story] Pay by card"))
+	assert.Equal(t, "[Epic] Payments", Strip("[🗺️ Epic] Payments"))
+	assert.Equal(t, "[Task] Build form", Strip("[🛠️ Task] Build form"))
+	assert.Equal(t, "Team ok", Strip("Team 👩‍💻 ok"))
+	assert.Equal(t, "Critérios de Aceite", Strip("Critérios de Aceite"))
+}
+
+func TestASCII(t *testing.T) {
+	assert.Equal(t, "+- [QA] Pay -+", ASCII("╭─ [🧪 QA] Pay ─╮"))
+	assert.Equal(t, "-> sub-issue 2 linked to #1", ASCII("↳ sub-issue 2 linked to #1"))
+}
+
+func TestSupported(t *testing.T) {
+	env := func(vars map[string]string) func(string) string {
+		return func(name string) string { return vars[name] }
+	}
+	assert.False(t, supported(env(nil), "windows"))
+	assert.True(t, supported(env(map[string]string{"WT_SESSION": "1"}), "windows"))
+	assert.True(t, supported(env(map[string]string{"LANG": "en_US.UTF-8"}), "linux"))
+	assert.False(t, supported(env(map[string]string{"LANG": "C"}), "linux"))
+	assert.True(t, supported(env(map[string]string{"LC_ALL": "pt_BR.utf8", "LANG": "C"}), "darwin"))
+	assert.False(t, supported(env(map[string]string{"TERM": "linux", "LANG": "en_US.UTF-8"}), "linux"))
+	assert.True(t, supported(env(nil), "linux"))
+}
@@ -0,0 +1,365 @@
+package expr
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/title"
+)
+
+// node is a node of the syntax tree of an expression.
+type node interface {
+	eval(env Env) (Value, error)
+}
+
+type literalNode struct {
+	value Value
+}
+
+func (n *literalNode) eval(Env) (Value, error) {
+	return n.value, nil
+}
+
+type variableNode struct {
+	name string
+}
+
+func (n *variableNode) eval(env Env) (Value, error) {
+	v, ok := env[n.name]
+	if !ok {
+		return nil, fmt.Errorf("variable %s is not set", n.name)
+	}
+	return v, nil
+}
+
+type listNode struct {
+	items []node
+}
+
+func (n *listNode) eval(env Env) (Value, error) {
+	list := make([]string, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case string:
+			list = append(list, v)
+		case []string:
+			list = append(list, v...)
+		default:
+			return nil, fmt.Errorf("list items must be strings, got %s", typeName(v))
+		}
+	}
+	return list, nil
+}
+
+// indexNode reads a value of a map by key, e.g. Extra["team"] or Extra.team, which is empty when
+// the key is missing, or an item of a list by position.
+type indexNode struct {
+	target node
+	index  node
+}
+
+func (n *indexNode) eval(env Env) (Value, error) {
+	target, err := n.target.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	index, err := n.index.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch target := target.(type) {
+	case map[string]string:
+		key, ok := index.(string)
+		if !ok {
+			return nil, fmt.Errorf("map keys are strings, got %s", typeName(index))
+		}
+		return target[key], nil
+	case []string:
+		i, ok := index.(int)
+		if !ok {
+			return nil, fmt.Errorf("list indexes are numbers, got %s", typeName(index))
+		}
+		if i < 0 || i >= len(target) {
+			return "", nil
+		}
+		return target[i], nil
+	default:
+		return nil, fmt.Errorf("cannot index %s", typeName(target))
+	}
+}
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+func (n *unaryNode) eval(env Env) (Value, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	switch v := v.(type) {
+	case bool:
+		if n.op == "!" {
+			return !v, nil
+		}
+	case int:
+		if n.op == "-" {
+			return -v, nil
+		}
+	}
+	return nil, fmt.Errorf("operator %s does not apply to %s", n.op, typeName(v))
+}
+
+// logicalNode is a && or || operation, which only evaluates its right operand when needed.
+type logicalNode struct {
+	op          string
+	left, right node
+}
+
+func (n *logicalNode) eval(env Env) (Value, error) {
+	left, err := boolean(n.left, env, n.op)
+	if err != nil {
+		return nil, err
+	}
+	if (n.op == "&&") != left {
+		return left, nil
+	}
+	return boolean(n.right, env, n.op)
+}
+
+type conditionalNode struct {
+	cond, then, otherwise node
+}
+
+func (n *conditionalNode) eval(env Env) (Value, error) {
+	cond, err := boolean(n.cond, env, "?")
+	if err != nil {
+		return nil, err
+	}
+	if cond {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+// boolean evaluates an operand of op that must be a boolean.
+func boolean(n node, env Env, op string) (bool, error) {
+	v, err := n.eval(env)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("operator %s expects a boolean, got %s", op, typeName(v))
+	}
+	return b, nil
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+func (n *binaryNode) eval(env Env) (Value, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==", "!=":
+		equal, err := equals(left, right)
+		if err != nil {
+			return nil, err
+		}
+		return equal == (n.op == "=="), nil
+	case "in":
+		return contains(right, left)
+	}
+
+	switch l := left.(type) {
+	case int:
+		if r, ok := right.(int); ok {
+			switch n.op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "<":
+				return l < r, nil
+			case "<=":
+				return l <= r, nil
+			case ">":
+				return l > r, nil
+			case ">=":
+				return l >= r, nil
+			}
+		}
+	case string:
+		if r, ok := right.(string); ok && n.op == "+" {
+			return l + r, nil
+		}
+		if r, ok := right.(int); ok && n.op == "+" {
+			return fmt.Sprintf("%s%d", l, r), nil
+		}
+	case []string:
+		if r, ok := right.([]string); ok && n.op == "+" {
+			return append(slices.Clip(l), r...), nil
+		}
+	}
+	return nil, fmt.Errorf("operator %s does not apply to %s and %s", n.op, typeName(left), typeName(right))
+}
+
+// equals compares two values of the same type.
+func equals(left, right Value) (bool, error) {
+	switch l := left.(type) {
+	case string:
+		if r, ok := right.(string); ok {
+			return l == r, nil
+		}
+	case int:
+		if r, ok := right.(int); ok {
+			return l == r, nil
+		}
+	case bool:
+		if r, ok := right.(bool); ok {
+			return l == r, nil
+		}
+	case []string:
+		if r, ok := right.([]string); ok {
+			return slices.Equal(l, r), nil
+		}
+	}
+	return false, fmt.Errorf("cannot compare %s with %s", typeName(left), typeName(right))
+}
+
+// contains reports whether a list has an item, a map has a key or a string has a substring.
+func contains(collection, value Value) (Value, error) {
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot look for %s", typeName(value))
+	}
+	switch c := collection.(type) {
+	case []string:
+		return slices.Contains(c, s), nil
+	case map[string]string:
+		_, found := c[s]
+		return found, nil
+	case string:
+		return strings.Contains(c, s), nil
+	default:
+		return nil, fmt.Errorf("cannot look into %s", typeName(collection))
+	}
+}
+
+// function is a function of the expressions.
+type function struct {
+	arity int
+	call  func(args []Value) (Value, error)
+}
+
+type callNode struct {
+	name string
+	fn   function
+	args []node
+}
+
+func (n *callNode) eval(env Env) (Value, error) {
+	args := make([]Value, len(n.args))
+	for i, arg := range n.args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v
+	}
+	v, err := n.fn.call(args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", n.name, err)
+	}
+	return v, nil
+}
+
+// functions are the functions of the expressions by name.
+var functions = map[string]function{
+	"lower":      stringFunction(strings.ToLower),
+	"upper":      stringFunction(strings.ToUpper),
+	"trim":       stringFunction(strings.TrimSpace),
+	"summary":    stringFunction(title.Summary),
+	"startsWith": predicate(strings.HasPrefix),
+	"endsWith":   predicate(strings.HasSuffix),
+	"contains": {arity: 2, call: func(args []Value) (Value, error) {
+		return contains(args[0], args[1])
+	}},
+	"replace": {arity: 3, call: func(args []Value) (Value, error) {
+		s, err := strs(args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s[0], s[1], s[2]), nil
+	}},
+	"join": {arity: 2, call: func(args []Value) (Value, error) {
+		list, ok := args[0].([]string)
+		sep, ok2 := args[1].(string)
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("expected a list and a string, got %s and %s", typeName(args[0]), typeName(args[1]))
+		}
+		return strings.Join(list, sep), nil
+	}},
+	"len": {arity: 1, call: func(args []Value) (Value, error) {
+		switch v := args[0].(type) {
+		case string:
+			return len([]rune(v)), nil
+		case []string:
+			return len(v), nil
+		case map[string]string:
+			return len(v), nil
+		}
+		return nil, fmt.Errorf("expected a string, list or map, got %s", typeName(args[0]))
+	}},
+}
+
+// stringFunction adapts a function of a string.
+func stringFunction(f func(string) string) function {
+	return function{arity: 1, call: func(args []Value) (Value, error) {
+		s, err := strs(args)
+		if err != nil {
+			return nil, err
+		}
+		return f(s[0]), nil
+	}}
+}
+
+// predicate adapts a function of two strings returning a boolean.
+func predicate(f func(string, string) bool) function {
+	return function{arity: 2, call: func(args []Value) (Value, error) {
+		s, err := strs(args)
+		if err != nil {
+			return nil, err
+		}
+		return f(s[0], s[1]), nil
+	}}
+}
+
+// strs returns the arguments of a function that only takes strings.
+func strs(args []Value) ([]string, error) {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		s, ok := arg.(string)
+		if !ok {
+			return nil, fmt.Errorf("argument %d must be a string, got %s", i+1, typeName(arg))
+		}
+		result[i] = s
+	}
+	return result, nil
+}
@@ -0,0 +1,128 @@
+// Package expr evaluates the small expression language of the computed fields of the
+// configuration file, such as the labels derived from the columns of a row, e.g.
+//
+//	Priority == "High" ? "urgent" : ""
+//	"team:" + lower(Extra["team"])
+//
+// Values are strings, integers, booleans, lists of strings and maps of strings. The operators are
+// + (concatenation and addition), -, ==, !=, <, <=, >, >=, in, &&, ||, ! and the conditional
+// a ? b : c, with parentheses, list literals ["a", "b"], indexing and the functions of Functions.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Value is the value of an expression: string, int, bool, []string or map[string]string.
+type Value = any
+
+// Env holds the values of the variables of an expression by name.
+type Env map[string]Value
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression, checking that it only uses the given variables and the known
+// functions.
+func Compile(source string, variables []string) (*Program, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	p := &parser{tokens: tokens, variables: make(map[string]bool, len(variables))}
+	for _, v := range variables {
+		p.variables[v] = true
+	}
+	root, err := p.parse()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	return &Program{source: source, root: root}, nil
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
+
+// Eval evaluates the expression with the values of env.
+func (p *Program) Eval(env Env) (Value, error) {
+	v, err := p.root.eval(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", p.source, err)
+	}
+	return v, nil
+}
+
+// Strings evaluates an expression that returns a string or a list of strings, returning the
+// non-blank strings, e.g. the labels of an item.
+func (p *Program) Strings(env Env) ([]string, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return nil, err
+	}
+	var values []string
+	switch v := v.(type) {
+	case string:
+		values = []string{v}
+	case []string:
+		values = v
+	default:
+		return nil, fmt.Errorf("expression %q returned %s, expected a string or a list", p.source, typeName(v))
+	}
+	result := make([]string, 0, len(values))
+	for _, s := range values {
+		if s = strings.TrimSpace(s); s != "" {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// StringValue evaluates an expression that returns a string, without its surrounding spaces.
+func (p *Program) StringValue(env Env) (string, error) {
+	v, err := p.Eval(env)
+	if err != nil {
+		return "", err
+	}
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("expression %q returned %s, expected a string", p.source, typeName(v))
+	}
+	return strings.TrimSpace(s), nil
+}
+
+// Functions lists the functions of the expressions.
+var Functions = sortedNames(functions)
+
+func sortedNames(m map[string]function) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// typeName names the type of a value in the error messages.
+func typeName(v Value) string {
+	switch v.(type) {
+	case string:
+		return "a string"
+	case int:
+		return "a number"
+	case bool:
+		return "a boolean"
+	case []string:
+		return "a list"
+	case map[string]string:
+		return "a map"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
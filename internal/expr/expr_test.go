@@ -0,0 +1,112 @@
+package expr
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEnv = Env{
+	"Type":     "User Story",
+	"Parent":   "Checkout",
+	"Priority": "High",
+	"Estimate": 5,
+	"Labels":   []string{"payments", "web"},
+	"Extra":    map[string]string{"team": "Payments"},
+	"Draft":    false,
+}
+
+var testVariables = []string{"Type", "Parent", "Priority", "Estimate", "Labels", "Extra", "Draft", "Missing"}
+
+func TestProgram_Eval(t *testing.T) {
+	for source, expected := range map[string]Value{
+		`"team:" + lower(Extra["team"])`:                   "team:payments",
+		`Extra.team + "/" + Extra.squad`:                   "Payments/",
+		`Priority == "High" ? "urgent" : ""`:               "urgent",
+		`Priority != "High" || Estimate >= 8`:              false,
+		`!Draft && Estimate > 3 && Estimate - 3 < 3`:       true,
+		`"web" in Labels && "team" in Extra`:               true,
+		`'Pay' in "Payments"`:                              true,
+		`Labels + ["sprint-" + 12]`:                        []string{"payments", "web", "sprint-12"},
+		`Labels[1] + Labels[5]`:                            "web",
+		`len(Labels) == 2 ? join(Labels, ", ") : "none"`:   "payments, web",
+		`replace(upper(Parent), "OUT", "-OUT")`:            "CHECK-OUT",
+		`startsWith(Type, "User") && !endsWith(Type, "x")`: true,
+		`contains(Labels, "mobile") ? 1 : -1`:              -1,
+		`summary("Pay by card. And more.")`:                "Pay by card",
+		`trim("  x ")`:                                     "x",
+		`(Estimate + 1) * 2 == 12`:                         nil,
+	} {
+		p, err := Compile(source, testVariables)
+		if expected == nil {
+			assert.Error(t, err, source)
+			continue
+		}
+		require.NoError(t, err, source)
+		v, err := p.Eval(testEnv)
+		require.NoError(t, err, source)
+		assert.Equal(t, expected, v, source)
+	}
+}
+
+func TestProgram_Strings(t *testing.T) {
+	p, err := Compile(`[Priority == "High" ? "urgent" : "", " team:" + lower(Extra.team)]`, testVariables)
+	require.NoError(t, err)
+	labels, err := p.Strings(testEnv)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"urgent", "team:payments"}, labels)
+
+	p, err = Compile(`Estimate`, testVariables)
+	require.NoError(t, err)
+	_, err = p.Strings(testEnv)
+	assert.EqualError(t, err, `expression "Estimate" returned a number, expected a string or a list`)
+
+	p, err = Compile(` Parent + " " `, testVariables)
+	require.NoError(t, err)
+	s, err := p.StringValue(testEnv)
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout", s)
+}
+
+func TestCompile_Errors(t *testing.T) {
+	for source, message := range map[string]string{
+		``:                    "the expression is empty",
+		`Unknown == "x"`:      "unknown variable Unknown at 1",
+		`shout(Parent)`:       "unknown function shout at 1",
+		`lower(Parent, Type)`: "function lower takes 1 arguments, got 2",
+		`"open`:               "unterminated string at 1",
+		`Parent ==`:           "unexpected end of expression at 10",
+		`Parent Type`:         `unexpected "Type" at 8`,
+		`Draft ? "a"`:         `expected ":", found end of expression at 12`,
+		`Parent # x`:          "unexpected character '#' at 8",
+	} {
+		_, err := Compile(source, testVariables)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), message, source)
+	}
+}
+
+func TestProgram_EvalErrors(t *testing.T) {
+	for source, message := range map[string]string{
+		`Parent == 1`:       "cannot compare a string with a number",
+		`Parent ? "a" : ""`: "operator ? expects a boolean, got a string",
+		`Estimate + Draft`:  "operator + does not apply to a number and a boolean",
+		`lower(Estimate)`:   "lower: argument 1 must be a string, got a number",
+		`Missing`:           "variable Missing is not set",
+		`-Parent`:           "operator - does not apply to a string",
+	} {
+		p, err := Compile(source, testVariables)
+		require.NoError(t, err, source)
+		_, err = p.Eval(testEnv)
+		require.Error(t, err, source)
+		assert.Contains(t, err.Error(), message, source)
+	}
+
+	// && and || do not evaluate the right operand when the left one decides
+	p, err := Compile(`Draft && Missing`, testVariables)
+	require.NoError(t, err)
+	v, err := p.Eval(testEnv)
+	require.NoError(t, err)
+	assert.Equal(t, false, v)
+}
@@ -0,0 +1,344 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Kinds of tokens.
+const (
+	tokenEOF = iota
+	tokenString
+	tokenNumber
+	tokenIdent
+	tokenOperator
+)
+
+// token is a token of an expression, with its position for the error messages.
+type token struct {
+	kind  int
+	text  string // Operator or identifier, or the unquoted value of a string
+	value int    // Value of a number
+	pos   int
+}
+
+// operators are the operators and punctuation, longest first so "==" is not read as "=".
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "+", "-", "<", ">", "!", "?", ":", "(", ")", "[", "]", ",", "."}
+
+// lex splits an expression into tokens.
+func lex(source string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(source); {
+		c := rune(source[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"' || c == '\'':
+			end := i + 1
+			for end < len(source) && source[end] != byte(c) {
+				if source[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(source) {
+				return nil, fmt.Errorf("unterminated string at %d", i+1)
+			}
+			text := source[i : end+1]
+			if c == '\'' {
+				text = `"` + strings.ReplaceAll(strings.ReplaceAll(text[1:len(text)-1], `"`, `\"`), `\'`, `'`) + `"`
+			}
+			value, err := strconv.Unquote(text)
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at %d: %w", i+1, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end + 1
+		case unicode.IsDigit(c):
+			end := i
+			for end < len(source) && unicode.IsDigit(rune(source[end])) {
+				end++
+			}
+			n, err := strconv.Atoi(source[i:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid number at %d: %w", i+1, err)
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: n, pos: i})
+			i = end
+		case unicode.IsLetter(c) || c == '_':
+			end := i
+			for end < len(source) && (unicode.IsLetter(rune(source[end])) || unicode.IsDigit(rune(source[end])) || source[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[i:end], pos: i})
+			i = end
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				r, _ := utf8.DecodeRuneInString(source[i:])
+				return nil, fmt.Errorf("unexpected character %q at %d", r, i+1)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(source)}), nil
+}
+
+// parser builds the syntax tree of an expression by recursive descent, from the lowest precedence
+// (the conditional) to the highest (the literals, variables and calls).
+type parser struct {
+	tokens    []token
+	pos       int
+	variables map[string]bool
+}
+
+func (p *parser) parse() (node, error) {
+	if p.peek().kind == tokenEOF {
+		return nil, fmt.Errorf("the expression is empty")
+	}
+	n, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at %d", describe(t), t.pos+1)
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// accept consumes the next token when it is the given operator or keyword.
+func (p *parser) accept(text string) bool {
+	t := p.peek()
+	if (t.kind == tokenOperator || t.kind == tokenIdent) && t.text == text {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		t := p.peek()
+		return fmt.Errorf("expected %q, found %s at %d", op, describe(t), t.pos+1)
+	}
+	return nil
+}
+
+func (p *parser) conditional() (node, error) {
+	cond, err := p.or()
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	then, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.conditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{cond: cond, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) or() (node, error) {
+	left, err := p.and()
+	for err == nil && p.accept("||") {
+		var right node
+		if right, err = p.and(); err == nil {
+			left = &logicalNode{op: "||", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) and() (node, error) {
+	left, err := p.comparison()
+	for err == nil && p.accept("&&") {
+		var right node
+		if right, err = p.comparison(); err == nil {
+			left = &logicalNode{op: "&&", left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) comparison() (node, error) {
+	left, err := p.additive()
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range []string{"==", "!=", "<=", ">=", "<", ">", "in"} {
+		if p.accept(op) {
+			right, err := p.additive()
+			if err != nil {
+				return nil, err
+			}
+			return &binaryNode{op: op, left: left, right: right}, nil
+		}
+	}
+	return left, nil
+}
+
+func (p *parser) additive() (node, error) {
+	left, err := p.unary()
+	for err == nil {
+		op := p.peek().text
+		if p.peek().kind != tokenOperator || (op != "+" && op != "-") {
+			break
+		}
+		p.next()
+		var right node
+		if right, err = p.unary(); err == nil {
+			left = &binaryNode{op: op, left: left, right: right}
+		}
+	}
+	return left, err
+}
+
+func (p *parser) unary() (node, error) {
+	for _, op := range []string{"!", "-"} {
+		if p.accept(op) {
+			operand, err := p.unary()
+			if err != nil {
+				return nil, err
+			}
+			return &unaryNode{op: op, operand: operand}, nil
+		}
+	}
+	return p.postfix()
+}
+
+func (p *parser) postfix() (node, error) {
+	n, err := p.primary()
+	for err == nil {
+		switch {
+		case p.accept("["):
+			var index node
+			if index, err = p.conditional(); err == nil {
+				if err = p.expect("]"); err == nil {
+					n = &indexNode{target: n, index: index}
+				}
+			}
+		case p.accept("."):
+			t := p.next()
+			if t.kind != tokenIdent {
+				return nil, fmt.Errorf("expected a field name, found %s at %d", describe(t), t.pos+1)
+			}
+			n = &indexNode{target: n, index: &literalNode{value: t.text}}
+		default:
+			return n, nil
+		}
+	}
+	return nil, err
+}
+
+func (p *parser) primary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenNumber:
+		return &literalNode{value: t.value}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true", "false":
+			return &literalNode{value: t.text == "true"}, nil
+		}
+		if p.accept("(") {
+			return p.call(t)
+		}
+		if !p.variables[t.text] {
+			return nil, fmt.Errorf("unknown variable %s at %d", t.text, t.pos+1)
+		}
+		return &variableNode{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			n, err := p.conditional()
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(")")
+		case "[":
+			list := &listNode{}
+			for !p.accept("]") {
+				if len(list.items) > 0 {
+					if err := p.expect(","); err != nil {
+						return nil, err
+					}
+				}
+				item, err := p.conditional()
+				if err != nil {
+					return nil, err
+				}
+				list.items = append(list.items, item)
+			}
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at %d", describe(t), t.pos+1)
+}
+
+// call parses the arguments of a call to the function named by t, whose "(" is consumed.
+func (p *parser) call(t token) (node, error) {
+	fn, ok := functions[t.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %s at %d (expected one of %s)", t.text, t.pos+1, strings.Join(Functions, ", "))
+	}
+	call := &callNode{name: t.text, fn: fn}
+	for !p.accept(")") {
+		if len(call.args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	if len(call.args) != fn.arity {
+		return nil, fmt.Errorf("function %s takes %d arguments, got %d", t.text, fn.arity, len(call.args))
+	}
+	return call, nil
+}
+
+// describe names a token in the error messages.
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.text)
+	case tokenNumber:
+		return strconv.Itoa(t.value)
+	default:
+		return fmt.Sprintf("%q", t.text)
+	}
+}
@@ -0,0 +1,111 @@
+package format
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ADF renders documents in the Atlassian Document Format, the JSON document model of the rich text
+// fields of Jira Cloud. Checklists become native task lists, panels become info panels and
+// comments are dropped. The result is the JSON document, to be sent as the field value.
+type ADF struct{}
+
+// adfNode is a node of an ADF document.
+type adfNode struct {
+	Type    string         `json:"type"`
+	Version int            `json:"version,omitempty"`
+	Attrs   map[string]any `json:"attrs,omitempty"`
+	Content []adfNode      `json:"content,omitempty"`
+	Text    string         `json:"text,omitempty"`
+	Marks   []adfMark      `json:"marks,omitempty"`
+}
+
+// adfMark is the formatting of a text node.
+type adfMark struct {
+	Type string `json:"type"`
+}
+
+// Format renders the document as an ADF JSON document.
+func (ADF) Format(doc Document) string {
+	b := adfBuilder{}
+	root := adfNode{Type: "doc", Version: 1, Content: b.nodes(doc.Blocks)}
+	if root.Content == nil {
+		// A document must have content, an empty paragraph is the empty document
+		root.Content = []adfNode{{Type: "paragraph"}}
+	}
+	// Nodes only hold strings, ints and maps of them, which always marshal
+	data, _ := json.Marshal(root)
+	return string(data)
+}
+
+// adfBuilder converts blocks to ADF nodes, numbering the task lists, whose nodes require a local
+// ID unique in the document.
+type adfBuilder struct {
+	taskLists int
+}
+
+// nodes converts the blocks to ADF nodes.
+func (b *adfBuilder) nodes(blocks []Block) []adfNode {
+	var nodes []adfNode
+	for _, block := range blocks {
+		switch block := block.(type) {
+		case Paragraph:
+			nodes = append(nodes, adfNode{Type: "paragraph", Content: adfText(block.Text)})
+		case Heading:
+			nodes = append(nodes, adfNode{Type: "heading", Attrs: map[string]any{"level": 2}, Content: adfText(block.Text)})
+		case Label:
+			content := []adfNode{{Type: "text", Text: block.Name, Marks: []adfMark{{Type: "strong"}}}}
+			content = append(content, adfText(": "+labelText(block))...)
+			nodes = append(nodes, adfNode{Type: "paragraph", Content: content})
+		case List:
+			nodes = append(nodes, b.list(block))
+		case Panel:
+			if content := b.nodes(block.Blocks); content != nil {
+				nodes = append(nodes, adfNode{Type: "panel", Attrs: map[string]any{"panelType": "info"}, Content: content})
+			}
+		}
+	}
+	return nodes
+}
+
+// list converts a list to an ordered, bullet or task list node.
+func (b *adfBuilder) list(list List) adfNode {
+	if list.Kind == Checklist {
+		b.taskLists++
+		node := adfNode{Type: "taskList", Attrs: map[string]any{"localId": fmt.Sprintf("tasks-%d", b.taskLists)}}
+		for i, item := range list.Items {
+			node.Content = append(node.Content, adfNode{
+				Type:    "taskItem",
+				Attrs:   map[string]any{"localId": fmt.Sprintf("tasks-%d-%d", b.taskLists, i+1), "state": "TODO"},
+				Content: adfText(itemText(item)),
+			})
+		}
+		return node
+	}
+
+	node := adfNode{Type: "bulletList"}
+	if list.Kind == Ordered {
+		node.Type = "orderedList"
+	}
+	for _, item := range list.Items {
+		paragraph := adfNode{Type: "paragraph", Content: adfText(itemText(item))}
+		node.Content = append(node.Content, adfNode{Type: "listItem", Content: []adfNode{paragraph}})
+	}
+	return node
+}
+
+// adfText converts text to inline nodes, with hard breaks for its line breaks. ADF rejects empty
+// text nodes, so empty lines are skipped.
+func adfText(text string) []adfNode {
+	var nodes []adfNode
+	for i, line := range strings.Split(text, "\n") {
+		if i > 0 {
+			nodes = append(nodes, adfNode{Type: "hardBreak"})
+		}
+		if line != "" {
+			nodes = append(nodes, adfNode{Type: "text", Text: line})
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,166 @@
+// Package format renders the bodies of the created issues in the markup of each issue provider:
+// the bodies are built once as a Document and every provider renders it with its Formatter.
+package format
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Formatter renders a document in the markup of an issue provider.
+type Formatter interface {
+	Format(doc Document) string
+}
+
+// Document is the body of an issue, as a sequence of blocks.
+type Document struct {
+	Blocks []Block
+}
+
+// Add appends blocks to the document and returns it.
+func (d Document) Add(blocks ...Block) Document {
+	d.Blocks = append(append([]Block(nil), d.Blocks...), blocks...)
+	return d
+}
+
+// Block is a part of a document: a Paragraph, Heading, List, Label, Comment or Panel.
+type Block interface {
+	block()
+}
+
+// Paragraph is a block of text.
+type Paragraph struct {
+	Text string
+}
+
+// Heading is a section heading.
+type Heading struct {
+	Text string
+}
+
+// Label is a bold label followed by a value, e.g. "Story: #12".
+type Label struct {
+	Name  string
+	Value string
+	Ref   int // Issue referenced after the value, 0 for none
+}
+
+// ListKind is the kind of a list.
+type ListKind int
+
+// List kinds.
+const (
+	Ordered   ListKind = iota // Numbered list
+	Bullets                   // Unordered list
+	Checklist                 // Task list with checkboxes
+)
+
+// List is a list of items.
+type List struct {
+	Kind  ListKind
+	Items []Item
+}
+
+// Item is an item of a list, optionally referencing an issue.
+type Item struct {
+	Text string
+	Ref  int // Issue number, 0 for none
+}
+
+// Comment is hidden text, such as machine readable markers. Formats without comments drop it.
+type Comment struct {
+	Text string
+}
+
+// Panel highlights a group of blocks, such as the acceptance criteria. Formats without panels
+// render its blocks in place.
+type Panel struct {
+	Blocks []Block
+}
+
+func (Paragraph) block() {}
+func (Heading) block()   {}
+func (Label) block()     {}
+func (List) block()      {}
+func (Comment) block()   {}
+func (Panel) block()     {}
+
+// flat returns the blocks of the document with the panels replaced by their blocks.
+func (d Document) flat() []Block {
+	var blocks []Block
+	for _, b := range d.Blocks {
+		if p, ok := b.(Panel); ok {
+			blocks = append(blocks, Document{Blocks: p.Blocks}.flat()...)
+			continue
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks
+}
+
+// ref renders an issue reference, which every supported provider links as #number.
+func ref(number int) string {
+	return fmt.Sprintf("#%d", number)
+}
+
+// itemText renders the text of a list item, preceded by its reference.
+func itemText(item Item) string {
+	switch {
+	case item.Ref == 0:
+		return item.Text
+	case item.Text == "":
+		return ref(item.Ref)
+	default:
+		return ref(item.Ref) + " " + item.Text
+	}
+}
+
+// labelText renders the value of a label followed by its reference.
+func labelText(l Label) string {
+	switch {
+	case l.Ref == 0:
+		return l.Value
+	case l.Value == "":
+		return ref(l.Ref)
+	default:
+		return l.Value + " " + ref(l.Ref)
+	}
+}
+
+// truncationMarker ends the bodies shortened by TruncateBody.
+const truncationMarker = "\n\n… (truncated)"
+
+// TruncateTitle shortens a title to at most max characters, ending it with an ellipsis, and reports
+// whether it did. A max of 0 or less leaves the title unchanged.
+func TruncateTitle(title string, max int) (string, bool) {
+	return truncate(title, max, "…", false)
+}
+
+// TruncateBody shortens a body to at most max characters, cutting at a line break when there is one
+// in the last quarter, ends it with a truncation marker and reports whether it did. A max of 0 or
+// less leaves the body unchanged.
+func TruncateBody(body string, max int) (string, bool) {
+	return truncate(body, max, truncationMarker, true)
+}
+
+// truncate shortens text to max characters including the marker, optionally at a line break.
+func truncate(text string, max int, marker string, atLine bool) (string, bool) {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text, false
+	}
+	cut := max - len([]rune(marker))
+	if cut <= 0 {
+		return string(runes[:max]), true
+	}
+	if atLine {
+		for i := cut; i > cut*3/4; i-- {
+			if runes[i] == '\n' {
+				cut = i
+				break
+			}
+		}
+	}
+	return strings.TrimRightFunc(string(runes[:cut]), unicode.IsSpace) + marker, true
+}
@@ -0,0 +1,107 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testDocument = Document{Blocks: []Block{
+	Paragraph{Text: "As a buyer, I want to pay by card."},
+	Heading{Text: "Acceptance Criteria"},
+	List{Kind: Ordered, Items: []Item{{Text: "Given a card"}, {Text: "When I pay"}}},
+	Heading{Text: "Suggested Tasks"},
+	List{Kind: Checklist, Items: []Item{{Text: "Build form", Ref: 12}, {Text: "Call gateway"}}},
+	Label{Name: "Story", Ref: 3},
+	Comment{Text: "aigile:source row=2"},
+}}
+
+func TestMarkdown(t *testing.T) {
+	assert.Equal(t, "As a buyer, I want to pay by card.\n\n"+
+		"## Acceptance Criteria\n1. Given a card\n2. When I pay\n\n"+
+		"## Suggested Tasks\n- [ ] #12 Build form\n- [ ] Call gateway\n\n"+
+		"**Story**: #3\n\n"+
+		"<!-- aigile:source row=2 -->\n", Markdown{}.Format(testDocument))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "As a buyer, I want to pay by card.\n\n"+
+		"Acceptance Criteria:\n1. Given a card\n2. When I pay\n\n"+
+		"Suggested Tasks:\n[ ] #12 Build form\n[ ] Call gateway\n\n"+
+		"Story: #3\n", Text{}.Format(testDocument))
+}
+
+func TestTextile(t *testing.T) {
+	assert.Equal(t, "As a buyer, I want to pay by card.\n\n"+
+		"h2. Acceptance Criteria\n\n# Given a card\n# When I pay\n\n"+
+		"h2. Suggested Tasks\n\n* [ ] #12 Build form\n* [ ] Call gateway\n\n"+
+		"*Story*: #3\n", Textile{}.Format(testDocument))
+}
+
+func TestHTML(t *testing.T) {
+	doc := testDocument.Add(List{Kind: Bullets, Items: []Item{{Text: "<b>&"}}})
+	assert.Equal(t, "<p>As a buyer, I want to pay by card.</p>\n"+
+		"<h2>Acceptance Criteria</h2>\n<ol>\n<li>Given a card</li>\n<li>When I pay</li>\n</ol>\n"+
+		"<h2>Suggested Tasks</h2>\n<ul>\n<li>☐ #12 Build form</li>\n<li>☐ Call gateway</li>\n</ul>\n"+
+		"<p><strong>Story</strong>: #3</p>\n"+
+		"<!-- aigile:source row=2 -->\n"+
+		"<ul>\n<li>&lt;b&gt;&amp;</li>\n</ul>\n", HTML{}.Format(doc))
+}
+
+func TestDocument_Add(t *testing.T) {
+	base := Document{}.Add(Paragraph{Text: "a"})
+	first := base.Add(Paragraph{Text: "b"})
+	second := base.Add(Paragraph{Text: "c"})
+	assert.Len(t, base.Blocks, 1)
+	assert.Equal(t, Paragraph{Text: "b"}, first.Blocks[1])
+	assert.Equal(t, Paragraph{Text: "c"}, second.Blocks[1])
+}
+
+func TestADF(t *testing.T) {
+	doc := Document{}.Add(
+		Paragraph{Text: "Pay by card.\nSecurely."},
+		Panel{Blocks: []Block{
+			Heading{Text: "Acceptance Criteria"},
+			List{Kind: Ordered, Items: []Item{{Text: "Given a card"}}},
+		}},
+		List{Kind: Checklist, Items: []Item{{Text: "Build form", Ref: 12}}},
+		List{Kind: Bullets, Items: []Item{{Text: "Note"}}},
+		Label{Name: "Story", Ref: 3},
+		Comment{Text: "aigile:source row=2"},
+	)
+	assert.JSONEq(t, `{"type": "doc", "version": 1, "content": [
+		{"type": "paragraph", "content": [{"type": "text", "text": "Pay by card."}, {"type": "hardBreak"}, {"type": "text", "text": "Securely."}]},
+		{"type": "panel", "attrs": {"panelType": "info"}, "content": [
+			{"type": "heading", "attrs": {"level": 2}, "content": [{"type": "text", "text": "Acceptance Criteria"}]},
+			{"type": "orderedList", "content": [{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Given a card"}]}]}]}
+		]},
+		{"type": "taskList", "attrs": {"localId": "tasks-1"}, "content": [
+			{"type": "taskItem", "attrs": {"localId": "tasks-1-1", "state": "TODO"}, "content": [{"type": "text", "text": "#12 Build form"}]}
+		]},
+		{"type": "bulletList", "content": [{"type": "listItem", "content": [{"type": "paragraph", "content": [{"type": "text", "text": "Note"}]}]}]},
+		{"type": "paragraph", "content": [{"type": "text", "text": "Story", "marks": [{"type": "strong"}]}, {"type": "text", "text": ": #3"}]}
+	]}`, ADF{}.Format(doc))
+
+	assert.JSONEq(t, `{"type": "doc", "version": 1, "content": [{"type": "paragraph"}]}`, ADF{}.Format(Document{}))
+}
+
+func TestPanel_Flattened(t *testing.T) {
+	doc := Document{}.Add(Paragraph{Text: "a"}, Panel{Blocks: []Block{Heading{Text: "H"}, List{Kind: Bullets, Items: []Item{{Text: "b"}}}}})
+	assert.Equal(t, "a\n\n## H\n- b\n", Markdown{}.Format(doc))
+}
+
+func TestTruncate(t *testing.T) {
+	title, truncated := TruncateTitle("Pagamento com cartão", 12)
+	assert.True(t, truncated)
+	assert.Equal(t, "Pagamento c…", title)
+	title, truncated = TruncateTitle("Short", 12)
+	assert.False(t, truncated)
+	assert.Equal(t, "Short", title)
+
+	body, truncated := TruncateBody("First paragraph.\n\nSecond paragraph is long", 36)
+	assert.True(t, truncated)
+	assert.Equal(t, "First paragraph.\n\n… (truncated)", body)
+	body, truncated = TruncateBody("unlimited", 0)
+	assert.False(t, truncated)
+	assert.Equal(t, "unlimited", body)
+}
@@ -0,0 +1,42 @@
+package format
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// HTML renders documents as HTML fragments, for providers with rich text fields such as the
+// description of Azure DevOps work items.
+type HTML struct{}
+
+// Format renders the document.
+func (HTML) Format(doc Document) string {
+	var sb strings.Builder
+	for _, b := range doc.flat() {
+		switch b := b.(type) {
+		case Paragraph:
+			sb.WriteString("<p>" + strings.ReplaceAll(html.EscapeString(b.Text), "\n", "<br>") + "</p>\n")
+		case Heading:
+			sb.WriteString("<h2>" + html.EscapeString(b.Text) + "</h2>\n")
+		case Label:
+			sb.WriteString(fmt.Sprintf("<p><strong>%s</strong>: %s</p>\n", html.EscapeString(b.Name), html.EscapeString(labelText(b))))
+		case List:
+			tag, prefix := "ul", ""
+			switch b.Kind {
+			case Ordered:
+				tag = "ol"
+			case Checklist:
+				prefix = "☐ "
+			}
+			sb.WriteString("<" + tag + ">\n")
+			for _, item := range b.Items {
+				sb.WriteString("<li>" + prefix + html.EscapeString(itemText(item)) + "</li>\n")
+			}
+			sb.WriteString("</" + tag + ">\n")
+		case Comment:
+			sb.WriteString("<!-- " + strings.ReplaceAll(b.Text, "--", "- -") + " -->\n")
+		}
+	}
+	return sb.String()
+}
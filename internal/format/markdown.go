@@ -0,0 +1,45 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown renders documents as GitHub Flavored Markdown, also used by Gitea and Forgejo.
+type Markdown struct{}
+
+// Format renders the document. Headings are followed directly by their content, and the other
+// blocks are separated by a blank line.
+func (Markdown) Format(doc Document) string {
+	var sb strings.Builder
+	blocks := doc.flat()
+	for i, b := range blocks {
+		switch b := b.(type) {
+		case Paragraph:
+			sb.WriteString(b.Text + "\n")
+		case Heading:
+			sb.WriteString("## " + b.Text + "\n")
+			continue
+		case Label:
+			sb.WriteString(fmt.Sprintf("**%s**: %s\n", b.Name, labelText(b)))
+		case List:
+			for n, item := range b.Items {
+				switch b.Kind {
+				case Ordered:
+					sb.WriteString(fmt.Sprintf("%d. %s\n", n+1, itemText(item)))
+				case Checklist:
+					sb.WriteString("- [ ] " + itemText(item) + "\n")
+				default:
+					sb.WriteString("- " + itemText(item) + "\n")
+				}
+			}
+		case Comment:
+			sb.WriteString("<!-- " + b.Text + " -->\n")
+			continue
+		}
+		if i < len(blocks)-1 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
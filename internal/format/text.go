@@ -0,0 +1,49 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Text renders documents as plain text, for providers without markup such as the notes of Asana
+// tasks. Comments are dropped.
+type Text struct{}
+
+// Format renders the document. Headings are followed directly by their content, and the other
+// blocks are separated by a blank line.
+func (Text) Format(doc Document) string {
+	var parts []string
+	heading := ""
+	for _, b := range doc.flat() {
+		var sb strings.Builder
+		sb.WriteString(heading)
+		heading = ""
+		switch b := b.(type) {
+		case Paragraph:
+			sb.WriteString(b.Text + "\n")
+		case Heading:
+			heading = b.Text + ":\n"
+			continue
+		case Label:
+			sb.WriteString(fmt.Sprintf("%s: %s\n", b.Name, labelText(b)))
+		case List:
+			for n, item := range b.Items {
+				switch b.Kind {
+				case Ordered:
+					sb.WriteString(fmt.Sprintf("%d. %s\n", n+1, itemText(item)))
+				case Checklist:
+					sb.WriteString("[ ] " + itemText(item) + "\n")
+				default:
+					sb.WriteString("• " + itemText(item) + "\n")
+				}
+			}
+		case Comment:
+			continue
+		}
+		parts = append(parts, sb.String())
+	}
+	if heading != "" {
+		parts = append(parts, heading)
+	}
+	return strings.Join(parts, "\n")
+}
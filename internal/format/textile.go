@@ -0,0 +1,41 @@
+package format
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Textile renders documents in Textile, the default text formatting of Redmine. Redmine has no
+// checkboxes, so checklists are rendered as lists of "[ ]" items, and comments are dropped.
+type Textile struct{}
+
+// Format renders the document, separating the blocks with a blank line as Textile requires.
+func (Textile) Format(doc Document) string {
+	var parts []string
+	for _, b := range doc.flat() {
+		var sb strings.Builder
+		switch b := b.(type) {
+		case Paragraph:
+			sb.WriteString(b.Text + "\n")
+		case Heading:
+			sb.WriteString("h2. " + b.Text + "\n")
+		case Label:
+			sb.WriteString(fmt.Sprintf("*%s*: %s\n", b.Name, labelText(b)))
+		case List:
+			for _, item := range b.Items {
+				switch b.Kind {
+				case Ordered:
+					sb.WriteString("# " + itemText(item) + "\n")
+				case Checklist:
+					sb.WriteString("* [ ] " + itemText(item) + "\n")
+				default:
+					sb.WriteString("* " + itemText(item) + "\n")
+				}
+			}
+		case Comment:
+			continue
+		}
+		parts = append(parts, sb.String())
+	}
+	return strings.Join(parts, "\n")
+}
@@ -0,0 +1,153 @@
+// Package graph builds the relationship graph of the epics, stories, tasks and QA checklists
+// created by a run, and renders it as Graphviz DOT or Mermaid to review the generated hierarchy.
+package graph
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/leocomelli/aigile/internal/store"
+)
+
+// KindFailed is the kind of the nodes of rows that failed, which have no issue.
+const KindFailed = "failed"
+
+// maxLabelLength is the maximum length of the titles shown in the nodes.
+const maxLabelLength = 60
+
+// Node is an issue created by the run, or a row that failed.
+type Node struct {
+	ID       string // Identifier in the rendered graph
+	Kind     string // store.KindEpic, KindStory, KindSlice, KindTask, KindQA, KindTests or KindFailed
+	Provider string
+	Number   int
+	Title    string
+	URL      string
+	Row      string
+}
+
+// Label returns the text of the node, e.g. "#12 Checkout with saved cards".
+func (n Node) Label() string {
+	title := n.Title
+	if len([]rune(title)) > maxLabelLength {
+		title = string([]rune(title)[:maxLabelLength-1]) + "…"
+	}
+	if n.Number > 0 {
+		return fmt.Sprintf("#%d %s", n.Number, title)
+	}
+	return title
+}
+
+// Edge links a parent node to a child: an epic to a story, a story to a task or QA checklist.
+type Edge struct {
+	From, To string
+}
+
+// Graph is the relationship graph of a run.
+type Graph struct {
+	Nodes    []Node
+	Edges    []Edge
+	Problems []string // Structural problems found, such as stories outside any epic
+}
+
+// Providers returns the providers of the issues, in order of appearance.
+func (g *Graph) Providers() []string {
+	var providers []string
+	for _, n := range g.Nodes {
+		if n.Provider != "" && !slices.Contains(providers, n.Provider) {
+			providers = append(providers, n.Provider)
+		}
+	}
+	return providers
+}
+
+// Build creates the graph of the items processed and the issues created by a run. Issues are
+// linked by their recorded parent; tasks recorded without one, by older versions, are linked to the
+// story of their row.
+func Build(items []store.ItemRecord, records []store.Record) *Graph {
+	g := &Graph{}
+	type key struct {
+		provider string
+		number   int
+	}
+	byNumber := map[key]string{}
+	storyOfRow := map[key]string{} // story node of a row, by provider and row index
+	rows := map[string]int{}
+	rowIndex := func(row string) int {
+		if _, ok := rows[row]; !ok {
+			rows[row] = len(rows)
+		}
+		return rows[row]
+	}
+
+	for i, r := range records {
+		n := Node{ID: fmt.Sprintf("n%d", i+1), Kind: r.Kind, Provider: r.Provider, Number: r.Number, Title: r.Title, URL: r.URL, Row: r.Row}
+		g.Nodes = append(g.Nodes, n)
+		if r.Number > 0 {
+			byNumber[key{r.Provider, r.Number}] = n.ID
+		}
+		if r.Kind == store.KindStory || r.Kind == store.KindEpic {
+			storyOfRow[key{r.Provider, rowIndex(r.Row)}] = n.ID
+		}
+	}
+
+	hierarchy := false
+	epicStories := map[string]int{}
+	for i, r := range records {
+		id := g.Nodes[i].ID
+		var parent string
+		switch {
+		case r.ParentNumber > 0:
+			p, ok := byNumber[key{r.Provider, r.ParentNumber}]
+			if !ok {
+				g.Problems = append(g.Problems, fmt.Sprintf("%s %s references the missing parent #%d", r.Kind, describe(g.Nodes[i]), r.ParentNumber))
+				continue
+			}
+			parent = p
+			if r.Kind == store.KindStory {
+				hierarchy = true
+				epicStories[p]++
+			}
+		case r.Kind == store.KindTask || r.Kind == store.KindQA || r.Kind == store.KindTests:
+			parent = storyOfRow[key{r.Provider, rowIndex(r.Row)}]
+			if parent == "" {
+				g.Problems = append(g.Problems, fmt.Sprintf("%s %s has no story", r.Kind, describe(g.Nodes[i])))
+				continue
+			}
+		default:
+			continue
+		}
+		g.Edges = append(g.Edges, Edge{From: parent, To: id})
+	}
+
+	// Stories outside an epic and empty epics are only problems when the run linked stories to epics
+	if hierarchy {
+		for i, r := range records {
+			n := g.Nodes[i]
+			switch {
+			case r.Kind == store.KindStory && r.ParentNumber == 0:
+				g.Problems = append(g.Problems, fmt.Sprintf("story %s is not linked to an epic", describe(n)))
+			case r.Kind == store.KindEpic && epicStories[n.ID] == 0:
+				g.Problems = append(g.Problems, fmt.Sprintf("epic %s has no stories", describe(n)))
+			}
+		}
+	}
+
+	for _, item := range items {
+		if item.Status != store.StatusFailed {
+			continue
+		}
+		n := Node{ID: fmt.Sprintf("f%d", len(g.Nodes)+1), Kind: KindFailed, Row: item.Row, Title: fmt.Sprintf("row %s (%s) failed", item.Row, item.Type)}
+		g.Nodes = append(g.Nodes, n)
+		g.Problems = append(g.Problems, fmt.Sprintf("row %s (%s) failed: %s", item.Row, item.Type, item.Error))
+	}
+	return g
+}
+
+// describe identifies a node in the problems.
+func describe(n Node) string {
+	if n.Number > 0 {
+		return fmt.Sprintf("#%d (row %s, %s)", n.Number, n.Row, n.Provider)
+	}
+	return fmt.Sprintf("%q (row %s, %s)", n.Title, n.Row, n.Provider)
+}
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testItems = []store.ItemRecord{
+	{Row: "2", Type: "Epic", Status: store.StatusCreated},
+	{Row: "3", Type: "User Story", Status: store.StatusCreated},
+	{Row: "4", Type: "User Story", Status: store.StatusFailed, Error: "timeout"},
+	{Row: "5", Type: "Epic", Status: store.StatusCreated},
+}
+
+var testRecords = []store.Record{
+	{Row: "2", Provider: "github", Kind: store.KindEpic, Number: 1, Title: "Checkout"},
+	{Row: "3", Provider: "github", Kind: store.KindStory, Number: 2, Title: `Pay with "saved" cards`, URL: "https://github.com/o/r/issues/2", ParentNumber: 1},
+	{Row: "3", Provider: "github", Kind: store.KindTask, Number: 3, Title: "Store the card token", ParentNumber: 2},
+	{Row: "3", Provider: "github", Kind: store.KindQA, Number: 4, Title: "QA", ParentNumber: 2},
+	{Row: "5", Provider: "github", Kind: store.KindEpic, Number: 5, Title: "Refunds"},
+}
+
+func TestBuild(t *testing.T) {
+	g := Build(testItems, testRecords)
+
+	require.Len(t, g.Nodes, 6)
+	assert.Equal(t, []Edge{{"n1", "n2"}, {"n2", "n3"}, {"n2", "n4"}}, g.Edges)
+	assert.Equal(t, KindFailed, g.Nodes[5].Kind)
+	assert.Equal(t, []string{
+		"epic #5 (row 5, github) has no stories",
+		"row 4 (User Story) failed: timeout",
+	}, g.Problems)
+	assert.Equal(t, []string{"github"}, g.Providers())
+}
+
+func TestBuild_WithoutParents(t *testing.T) {
+	// Runs recorded before the parent numbers link tasks to the story of their row, and without
+	// hierarchy stories outside epics are not problems
+	g := Build(nil, []store.Record{
+		{Row: "2", Provider: "github", Kind: store.KindStory, Number: 10},
+		{Row: "2", Provider: "github", Kind: store.KindTask, Number: 11},
+		{Row: "3", Provider: "gitea", Kind: store.KindTask, Number: 1},
+		{Row: "3", Provider: "github", Kind: store.KindTask, Number: 12, ParentNumber: 99},
+	})
+	assert.Equal(t, []Edge{{"n1", "n2"}}, g.Edges)
+	assert.Equal(t, []string{
+		"task #1 (row 3, gitea) has no story",
+		"task #12 (row 3, github) references the missing parent #99",
+	}, g.Problems)
+	assert.Equal(t, []string{"github", "gitea"}, g.Providers())
+}
+
+func TestWriteDOT(t *testing.T) {
+	var sb strings.Builder
+	require.NoError(t, Write(&sb, Build(testItems, testRecords), FormatDOT))
+	dot := sb.String()
+	assert.True(t, strings.HasPrefix(dot, "digraph aigile {\n"))
+	assert.Contains(t, dot, "\tn2 [label=\"#2 Pay with \\\"saved\\\" cards\", fillcolor=\"#bfdbfe\", URL=\"https://github.com/o/r/issues/2\"];\n")
+	assert.Contains(t, dot, "\tf6 [label=\"row 4 (User Story) failed\", fillcolor=\"#fecaca\"];\n")
+	assert.Contains(t, dot, "\tn1 -> n2;\n")
+	assert.NotContains(t, dot, "cluster")
+}
+
+func TestWriteMermaid(t *testing.T) {
+	records := append([]store.Record{}, testRecords...)
+	records = append(records, store.Record{Row: "2", Provider: "gitea", Kind: store.KindEpic, Number: 1, Title: "Checkout"})
+
+	var sb strings.Builder
+	require.NoError(t, Write(&sb, Build(nil, records), FormatMermaid))
+	mermaid := sb.String()
+	assert.True(t, strings.HasPrefix(mermaid, "flowchart LR\n  subgraph p0 [\"github\"]\n"))
+	assert.Contains(t, mermaid, "    n2[\"#2 Pay with #quot;saved#quot; cards\"]:::story\n")
+	assert.Contains(t, mermaid, "  subgraph p1 [\"gitea\"]\n    n6[\"#1 Checkout\"]:::epic\n  end\n")
+	assert.Contains(t, mermaid, "  n2 --> n3\n")
+	assert.Contains(t, mermaid, "  click n2 \"https://github.com/o/r/issues/2\"\n")
+	assert.Contains(t, mermaid, "  classDef qa fill:#bbf7d0\n")
+
+	assert.ErrorContains(t, Write(&sb, &Graph{}, "svg"), "unsupported graph format: svg")
+}
+
+func TestNode_Label(t *testing.T) {
+	n := Node{Number: 7, Title: strings.Repeat("a", 70)}
+	assert.Equal(t, "#7 "+strings.Repeat("a", 59)+"…", n.Label())
+}
@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/store"
+)
+
+// Formats supported by Write.
+const (
+	FormatDOT     = "dot"
+	FormatMermaid = "mermaid"
+)
+
+// styles are the colors of the node kinds, shared by both formats.
+var styles = map[string]string{
+	store.KindEpic:  "#d8b4fe",
+	store.KindStory: "#bfdbfe",
+	store.KindSlice: "#dbeafe",
+	store.KindTask:  "#e5e7eb",
+	store.KindQA:    "#bbf7d0",
+	store.KindTests: "#fde68a",
+	store.KindDraft: "#f5f5f4",
+	KindFailed:      "#fecaca",
+}
+
+// kinds are the node kinds in the order their styles are declared.
+var kinds = []string{store.KindEpic, store.KindStory, store.KindSlice, store.KindTask, store.KindQA, store.KindTests, store.KindDraft, KindFailed}
+
+// Write renders the graph in the given format.
+func Write(w io.Writer, g *Graph, format string) error {
+	switch format {
+	case FormatDOT:
+		return WriteDOT(w, g)
+	case FormatMermaid:
+		return WriteMermaid(w, g)
+	default:
+		return fmt.Errorf("unsupported graph format: %s (expected %s or %s)", format, FormatDOT, FormatMermaid)
+	}
+}
+
+// dotEscaper escapes the labels of DOT quoted strings.
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ")
+
+// WriteDOT renders the graph in the Graphviz DOT language, with a cluster per provider when the
+// run created issues in several providers.
+func WriteDOT(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("digraph aigile {\n\trankdir=LR;\n\tnode [shape=box, style=\"rounded,filled\", fontname=\"Helvetica\"];\n")
+	providers := g.Providers()
+	for i, provider := range providers {
+		indent := "\t"
+		if len(providers) > 1 {
+			sb.WriteString(fmt.Sprintf("\tsubgraph cluster_%d {\n\t\tlabel=\"%s\";\n", i, dotEscaper.Replace(provider)))
+			indent = "\t\t"
+		}
+		for _, n := range g.Nodes {
+			if n.Provider != provider {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("%s%s [label=\"%s\", fillcolor=\"%s\"", indent, n.ID, dotEscaper.Replace(n.Label()), styles[n.Kind]))
+			if n.URL != "" {
+				sb.WriteString(fmt.Sprintf(", URL=\"%s\"", dotEscaper.Replace(n.URL)))
+			}
+			sb.WriteString("];\n")
+		}
+		if len(providers) > 1 {
+			sb.WriteString("\t}\n")
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.Provider == "" {
+			sb.WriteString(fmt.Sprintf("\t%s [label=\"%s\", fillcolor=\"%s\"];\n", n.ID, dotEscaper.Replace(n.Label()), styles[n.Kind]))
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("\t%s -> %s;\n", e.From, e.To))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// mermaidEscaper escapes the labels of Mermaid quoted strings.
+var mermaidEscaper = strings.NewReplacer(`"`, "#quot;", "\n", " ")
+
+// WriteMermaid renders the graph as a Mermaid flowchart, which GitHub renders in Markdown.
+func WriteMermaid(w io.Writer, g *Graph) error {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	providers := g.Providers()
+	for i, provider := range providers {
+		indent := "  "
+		if len(providers) > 1 {
+			sb.WriteString(fmt.Sprintf("  subgraph p%d [\"%s\"]\n", i, mermaidEscaper.Replace(provider)))
+			indent = "    "
+		}
+		for _, n := range g.Nodes {
+			if n.Provider == provider {
+				sb.WriteString(fmt.Sprintf("%s%s[\"%s\"]:::%s\n", indent, n.ID, mermaidEscaper.Replace(n.Label()), n.Kind))
+			}
+		}
+		if len(providers) > 1 {
+			sb.WriteString("  end\n")
+		}
+	}
+	for _, n := range g.Nodes {
+		if n.Provider == "" {
+			sb.WriteString(fmt.Sprintf("  %s[\"%s\"]:::%s\n", n.ID, mermaidEscaper.Replace(n.Label()), n.Kind))
+		}
+	}
+	for _, e := range g.Edges {
+		sb.WriteString(fmt.Sprintf("  %s --> %s\n", e.From, e.To))
+	}
+	for _, n := range g.Nodes {
+		if n.URL != "" {
+			sb.WriteString(fmt.Sprintf("  click %s \"%s\"\n", n.ID, mermaidEscaper.Replace(n.URL)))
+		}
+	}
+	for _, kind := range kinds {
+		sb.WriteString(fmt.Sprintf("  classDef %s fill:%s\n", kind, styles[kind]))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
@@ -0,0 +1,76 @@
+// Package health serves the liveness and readiness endpoints used by orchestrators such as
+// Kubernetes when aigile runs as a long-lived process.
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// readHeaderTimeout bounds the time to read the headers of a probe request.
+const readHeaderTimeout = 5 * time.Second
+
+// Server serves /healthz, which succeeds while the process is running, and /readyz, which
+// succeeds once the process is ready to work and until it starts shutting down.
+type Server struct {
+	ready  atomic.Bool
+	server *http.Server
+}
+
+// NewServer creates a health server listening on addr, e.g. :8080. It starts not ready.
+func NewServer(addr string) *Server {
+	s := &Server{}
+	s.server = &http.Server{Addr: addr, Handler: s.Handler(), ReadHeaderTimeout: readHeaderTimeout}
+	return s
+}
+
+// SetReady changes the readiness reported by /readyz.
+func (s *Server) SetReady(ready bool) {
+	s.ready.Store(ready)
+}
+
+// Handler returns the handler of the health endpoints.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !s.ready.Load() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "ready")
+	})
+	return mux
+}
+
+// Start listens on the address of the server and serves the endpoints in the background.
+func (s *Server) Start() error {
+	ln, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.server.Addr, err)
+	}
+	slog.Info("serving health endpoints", "addr", ln.Addr().String())
+	go func() {
+		if err := s.server.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			slog.Error("health server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+// Shutdown reports not ready and stops the server, waiting for the probes in flight.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.SetReady(false)
+	if err := s.server.Shutdown(ctx); err != nil {
+		return fmt.Errorf("failed to stop health server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer_Handler(t *testing.T) {
+	s := NewServer(":0")
+	server := httptest.NewServer(s.Handler())
+	defer server.Close()
+
+	status := func(path string) int {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, status("/healthz"))
+	assert.Equal(t, http.StatusServiceUnavailable, status("/readyz"))
+	s.SetReady(true)
+	assert.Equal(t, http.StatusOK, status("/readyz"))
+	assert.Equal(t, http.StatusNotFound, status("/metrics"))
+}
+
+func TestServer_StartShutdown(t *testing.T) {
+	s := NewServer("127.0.0.1:0")
+	require.NoError(t, s.Start())
+	s.SetReady(true)
+	require.NoError(t, s.Shutdown(context.Background()))
+	assert.False(t, s.ready.Load())
+}
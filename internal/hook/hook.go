@@ -0,0 +1,275 @@
+// Package hook runs the automation of the teams around the generation without code changes: each
+// hook is a command run with an event as JSON on its standard input, or a webhook the event is
+// posted to, e.g. to transform the rows before they are generated, or to notify a channel or update
+// a roadmap tool when an issue is created.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/httpclient"
+)
+
+// DefaultTimeout bounds the run of a hook when it sets no timeout.
+const DefaultTimeout = 30 * time.Second
+
+// maxOutput is the maximum characters of the output of a failed command, or of the response of a
+// failed webhook, reported in its error.
+const maxOutput = 500
+
+// Events sent to the hooks.
+const (
+	EventItemGenerating = "item.generating" // Before the content of a row is generated
+	EventIssueCreated   = "issue.created"
+)
+
+// Hook is a hook of the configuration file. It runs a command or calls a webhook.
+type Hook struct {
+	Name    string            `yaml:"name"`    // Defaults to the position of the hook
+	Command []string          `yaml:"command"` // Program and arguments, run with the event on its standard input
+	URL     string            `yaml:"url"`     // Webhook the event is posted to
+	Headers map[string]string `yaml:"headers"` // Headers of the webhook requests, e.g. Authorization
+	Kinds   []string          `yaml:"kinds"`   // Kinds of issues a post-create hook runs for (epic, story, task, qa, tests, draft), all by default
+	Timeout time.Duration     `yaml:"timeout"` // Defaults to DefaultTimeout
+}
+
+// Event is the JSON document sent to the hooks when an issue is created.
+type Event struct {
+	Event        string `json:"event"`
+	RunID        string `json:"run_id"`
+	Source       string `json:"source"` // Source row, e.g. backlog.xlsx:Sheet1!12
+	Row          string `json:"row"`
+	Provider     string `json:"provider"`
+	Kind         string `json:"kind"`
+	ParentNumber int    `json:"parent_number,omitempty"` // Story of a task, epic of a story
+	Issue        Issue  `json:"issue"`
+}
+
+// Issue is the issue of an event.
+type Issue struct {
+	Number     int      `json:"number,omitempty"`
+	ID         int64    `json:"id,omitempty"`
+	ProviderID string   `json:"provider_id,omitempty"`
+	URL        string   `json:"url,omitempty"`
+	Title      string   `json:"title"`
+	Body       string   `json:"body,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	State      string   `json:"state,omitempty"`
+}
+
+// ItemEvent is the JSON document sent to the hooks before the content of a row is generated.
+type ItemEvent struct {
+	Event  string `json:"event"`
+	RunID  string `json:"run_id"`
+	Source string `json:"source"` // Source row, e.g. backlog.xlsx:Sheet1!12
+	Row    string `json:"row"`
+	Item   Item   `json:"item"`
+}
+
+// Item is the row of an item event, which the hooks can transform.
+type Item struct {
+	Type        string            `json:"type"`
+	Parent      string            `json:"parent"`
+	Context     string            `json:"context"`
+	Criteria    []string          `json:"criteria"`
+	Labels      []string          `json:"labels,omitempty"`
+	Assignees   []string          `json:"assignees,omitempty"`
+	Milestone   string            `json:"milestone,omitempty"`
+	Priority    string            `json:"priority,omitempty"`
+	Repository  string            `json:"repository,omitempty"`
+	Sensitivity string            `json:"sensitivity,omitempty"`
+	Designs     []string          `json:"designs,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	Extra       map[string]string `json:"extra,omitempty"`
+}
+
+// Response is the answer of a hook to an item event, printed by a command on its standard output
+// or returned by a webhook. An empty answer leaves the item unchanged.
+type Response struct {
+	Item   *Item  `json:"item"`   // Item replacing the row, nil to leave it unchanged
+	Reject string `json:"reject"` // Reason the row is not generated, empty to generate it
+}
+
+// RejectedError is returned when a hook rejects a row.
+type RejectedError struct {
+	Hook   string
+	Reason string
+}
+
+func (e *RejectedError) Error() string {
+	return fmt.Sprintf("rejected by hook %s: %s", e.Hook, e.Reason)
+}
+
+// Runner runs the hooks.
+type Runner struct {
+	hooks  []Hook
+	client *http.Client
+}
+
+// New checks the hooks of the configuration file.
+func New(hooks []Hook) (*Runner, error) {
+	r := &Runner{client: httpclient.New(0)}
+	for i, h := range hooks {
+		if h.Name == "" {
+			h.Name = strconv.Itoa(i + 1)
+		}
+		if (len(h.Command) == 0) == (h.URL == "") {
+			return nil, fmt.Errorf("hook %s: exactly one of command and url is required", h.Name)
+		}
+		if h.Timeout < 0 {
+			return nil, fmt.Errorf("hook %s: invalid timeout %s", h.Name, h.Timeout)
+		}
+		if h.Timeout == 0 {
+			h.Timeout = DefaultTimeout
+		}
+		r.hooks = append(r.hooks, h)
+	}
+	return r, nil
+}
+
+// Len returns the number of hooks.
+func (r *Runner) Len() int {
+	return len(r.hooks)
+}
+
+// Run runs the hooks matching the kind of the event, one after another, and returns the error of
+// each failed hook. A nil runner runs nothing.
+func (r *Runner) Run(ctx context.Context, event Event) []error {
+	if r == nil {
+		return nil
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return []error{fmt.Errorf("failed to encode hook event: %w", err)}
+	}
+
+	var errs []error
+	for _, h := range r.hooks {
+		if len(h.Kinds) > 0 && !slices.Contains(h.Kinds, event.Kind) {
+			continue
+		}
+		if _, err := r.call(ctx, h, payload, event.Event, event.Kind, event.Issue.Number, event.Issue.URL); err != nil {
+			errs = append(errs, fmt.Errorf("hook %s failed: %w", h.Name, err))
+			continue
+		}
+		slog.Debug("hook run", "hook", h.Name, "event", event.Event, "number", event.Issue.Number)
+	}
+	return errs
+}
+
+// Transform runs the hooks on the item of the event, one after another, each with the item as
+// transformed by the previous ones, and returns the transformed item. It returns a *RejectedError
+// when a hook rejects the row. A nil runner returns the item unchanged.
+func (r *Runner) Transform(ctx context.Context, event ItemEvent) (Item, error) {
+	if r == nil {
+		return event.Item, nil
+	}
+	for _, h := range r.hooks {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return event.Item, fmt.Errorf("failed to encode hook event: %w", err)
+		}
+		output, err := r.call(ctx, h, payload, event.Event, "", 0, "")
+		if err != nil {
+			return event.Item, fmt.Errorf("hook %s failed: %w", h.Name, err)
+		}
+		if len(bytes.TrimSpace(output)) == 0 {
+			continue
+		}
+		var resp Response
+		if err := json.Unmarshal(output, &resp); err != nil {
+			return event.Item, fmt.Errorf("hook %s failed: invalid response: %w", h.Name, err)
+		}
+		if resp.Reject != "" {
+			return event.Item, &RejectedError{Hook: h.Name, Reason: resp.Reject}
+		}
+		if resp.Item != nil {
+			event.Item = *resp.Item
+			slog.Debug("item transformed by hook", "hook", h.Name, "row", event.Row)
+		}
+	}
+	return event.Item, nil
+}
+
+// call runs a hook with the payload, within its timeout, and returns its output.
+func (r *Runner) call(ctx context.Context, h Hook, payload []byte, event, kind string, number int, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.Timeout)
+	defer cancel()
+	if len(h.Command) > 0 {
+		return r.exec(ctx, h, payload, event, kind, number, url)
+	}
+	return r.post(ctx, h, payload)
+}
+
+// exec runs the command of a hook with the payload on its standard input and the event in the
+// AIGILE_EVENT, AIGILE_KIND, AIGILE_ISSUE_NUMBER and AIGILE_ISSUE_URL environment variables, and
+// returns its standard output.
+func (r *Runner) exec(ctx context.Context, h Hook, payload []byte, event, kind string, number int, url string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, h.Command[0], h.Command[1:]...) // #nosec G204 -- the command comes from the configuration file
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), "AIGILE_EVENT="+event)
+	if kind != "" {
+		cmd.Env = append(cmd.Env, "AIGILE_KIND="+kind, "AIGILE_ISSUE_NUMBER="+strconv.Itoa(number), "AIGILE_ISSUE_URL="+url)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		output := strings.TrimSpace(stderr.String())
+		if output == "" {
+			output = strings.TrimSpace(stdout.String())
+		}
+		if output != "" {
+			return nil, fmt.Errorf("%w: %s", err, truncate(output))
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+// post posts the payload to the webhook of a hook and returns the response body.
+func (r *Runner) post(ctx context.Context, h Hook, payload []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range h.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, maxOutput))
+		return nil, fmt.Errorf("webhook error (status: %d): %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	return data, nil
+}
+
+// truncate shortens the output of a failed command to maxOutput characters.
+func truncate(output string) string {
+	if runes := []rune(output); len(runes) > maxOutput {
+		return string(runes[:maxOutput]) + "…"
+	}
+	return output
+}
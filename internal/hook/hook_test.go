@@ -0,0 +1,140 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testEvent = Event{
+	Event:    EventIssueCreated,
+	RunID:    "r1",
+	Source:   "backlog.xlsx:Sheet1!2",
+	Row:      "2",
+	Provider: "github",
+	Kind:     "story",
+	Issue:    Issue{Number: 7, URL: "https://github.com/o/r/issues/7", Title: "Story", Labels: []string{"User Story"}, State: "open"},
+}
+
+func TestRunner_Command(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "event.json")
+	r, err := New([]Hook{
+		{Name: "save", Command: []string{"sh", "-c", `cat > "$0" && echo "$AIGILE_ISSUE_NUMBER $AIGILE_KIND" >> "$0"`, out}},
+		{Name: "epics only", Command: []string{"false"}, Kinds: []string{"epic"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, r.Len())
+
+	assert.Empty(t, r.Run(context.Background(), testEvent))
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"event":"issue.created","run_id":"r1"`)
+	assert.Contains(t, string(data), `"labels":["User Story"]`)
+	assert.Contains(t, string(data), "7 story\n")
+}
+
+func TestRunner_CommandFailure(t *testing.T) {
+	r, err := New([]Hook{{Command: []string{"sh", "-c", "echo boom >&2; exit 3"}}})
+	require.NoError(t, err)
+
+	errs := r.Run(context.Background(), testEvent)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "hook 1 failed: exit status 3: boom", errs[0].Error())
+}
+
+func TestRunner_Webhook(t *testing.T) {
+	var received Event
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil || received.Issue.Number != 7 {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte("bad event"))
+		}
+	}))
+	defer server.Close()
+
+	r, err := New([]Hook{{Name: "roadmap", URL: server.URL, Headers: map[string]string{"Authorization": "Bearer abc"}}})
+	require.NoError(t, err)
+	assert.Empty(t, r.Run(context.Background(), testEvent))
+	assert.Equal(t, testEvent, received)
+	assert.Equal(t, "Bearer abc", auth)
+
+	other := testEvent
+	other.Issue.Number = 8
+	errs := r.Run(context.Background(), other)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "hook roadmap failed: webhook error (status: 400): bad event", errs[0].Error())
+}
+
+func TestRunner_Transform(t *testing.T) {
+	event := ItemEvent{
+		Event: EventItemGenerating,
+		Row:   "2",
+		Item:  Item{Type: "User Story", Parent: "checkout ", Context: "Pay by card", Criteria: []string{"Card is charged"}},
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var received ItemEvent
+		_ = json.NewDecoder(r.Body).Decode(&received)
+		item := received.Item
+		item.Criteria = append(item.Criteria, "An audit entry is recorded")
+		_ = json.NewEncoder(w).Encode(Response{Item: &item})
+	}))
+	defer server.Close()
+
+	r, err := New([]Hook{
+		{Name: "normalize", Command: []string{"echo", `{"item":{"type":"User Story","parent":"Checkout","context":"Pay by card","criteria":["Card is charged"]}}`}},
+		{Name: "noop", Command: []string{"true"}},
+		{Name: "defaults", URL: server.URL},
+	})
+	require.NoError(t, err)
+	item, err := r.Transform(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout", item.Parent)
+	assert.Equal(t, []string{"Card is charged", "An audit entry is recorded"}, item.Criteria)
+
+	var none *Runner
+	item, err = none.Transform(context.Background(), event)
+	require.NoError(t, err)
+	assert.Equal(t, event.Item, item)
+}
+
+func TestRunner_TransformReject(t *testing.T) {
+	r, err := New([]Hook{
+		{Name: "gate", Command: []string{"echo", `{"reject":"parent is archived"}`}},
+		{Name: "never", Command: []string{"false"}},
+	})
+	require.NoError(t, err)
+	_, err = r.Transform(context.Background(), ItemEvent{Event: EventItemGenerating})
+	var rejected *RejectedError
+	require.ErrorAs(t, err, &rejected)
+	assert.Equal(t, "rejected by hook gate: parent is archived", err.Error())
+
+	r, err = New([]Hook{{Name: "broken", Command: []string{"echo", "not json"}}})
+	require.NoError(t, err)
+	_, err = r.Transform(context.Background(), ItemEvent{Event: EventItemGenerating})
+	assert.ErrorContains(t, err, "hook broken failed: invalid response")
+	assert.False(t, errors.As(err, &rejected))
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, hooks := range [][]Hook{
+		{{Name: "none"}},
+		{{Name: "both", Command: []string{"true"}, URL: "https://example.com"}},
+		{{Name: "negative", Command: []string{"true"}, Timeout: -1}},
+	} {
+		_, err := New(hooks)
+		assert.Error(t, err, "%+v", hooks)
+	}
+
+	var r *Runner
+	assert.Empty(t, r.Run(context.Background(), testEvent))
+}
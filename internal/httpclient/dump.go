@@ -0,0 +1,113 @@
+package httpclient
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httputil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/redact"
+)
+
+// sensitiveHeaders are the headers carrying credentials, whose values are not written to the dumps.
+var sensitiveHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"Api-Key", "X-Api-Key", "X-Redmine-Api-Key", "X-Amz-Security-Token",
+}
+
+// EnableDump makes the shared transport write every request, with its response, to a file of dir,
+// to diagnose proxy and permission problems. The credentials are masked: the values of the
+// sensitive headers, and the secrets of the URLs and bodies with redact.Secrets. It must be called
+// before the clients are created.
+func EnableDump(dir string) error {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create the HTTP debug directory: %w", err)
+	}
+	Transport = &dumpTransport{base: Transport, dir: dir, run: time.Now().Format("20060102T150405")}
+	return nil
+}
+
+// dumpTransport writes the requests and responses of its base transport to files, one per request
+// named after the start of the run and the sequence number, host and method of the request, e.g.
+// 20250301T101500-0001-api.github.com-POST.http.
+type dumpTransport struct {
+	base http.RoundTripper
+	dir  string
+	run  string
+	seq  atomic.Int64
+}
+
+func (t *dumpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var b bytes.Buffer
+	if err := dumpRequest(&b, req); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	b.WriteString("\n\n")
+	if err != nil {
+		fmt.Fprintf(&b, "error: %v\n", err)
+	} else if derr := dumpResponse(&b, resp); derr != nil {
+		fmt.Fprintf(&b, "failed to dump the response: %v\n", derr)
+	}
+
+	name := fmt.Sprintf("%s-%04d-%s-%s.http", t.run, t.seq.Add(1), req.URL.Hostname(), req.Method)
+	if werr := os.WriteFile(filepath.Join(t.dir, name), []byte(redact.Secrets(b.String())), 0o600); werr != nil {
+		slog.Warn("failed to write the HTTP debug dump", "file", name, "error", werr)
+	}
+	return resp, err
+}
+
+// dumpRequest writes req, with its body and without its sensitive headers, leaving it unchanged.
+func dumpRequest(w io.Writer, req *http.Request) error {
+	clone := req.Clone(req.Context())
+	clone.Header = sanitize(req.Header)
+	if req.Body != nil && req.Body != http.NoBody {
+		body, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return fmt.Errorf("failed to read the request body: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		clone.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	dump, err := httputil.DumpRequestOut(clone, true)
+	if err != nil {
+		return fmt.Errorf("failed to dump the request: %w", err)
+	}
+	_, err = w.Write(dump)
+	return err
+}
+
+// dumpResponse writes resp, with its body and without its sensitive headers. The body is read and
+// replaced, so the caller can still read it.
+func dumpResponse(w io.Writer, resp *http.Response) error {
+	sanitized := *resp
+	sanitized.Header = sanitize(resp.Header)
+	dump, err := httputil.DumpResponse(&sanitized, true)
+	resp.Body = sanitized.Body
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(dump)
+	return err
+}
+
+// sanitize returns a copy of header with the values of the sensitive headers masked.
+func sanitize(header http.Header) http.Header {
+	header = header.Clone()
+	for name := range header {
+		for _, sensitive := range sensitiveHeaders {
+			if strings.EqualFold(name, sensitive) {
+				header[name] = []string{"[REDACTED]"}
+			}
+		}
+	}
+	return header
+}
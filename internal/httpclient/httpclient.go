@@ -0,0 +1,55 @@
+// Package httpclient provides the HTTP clients of aigile, built on a shared transport so that
+// connections to the issue trackers, the LLM APIs and Google Sheets are pooled and kept alive
+// across the items of a run.
+package httpclient
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// DefaultTimeout bounds the requests to the issue tracker, notification and spreadsheet APIs.
+// LLM requests are bounded by the item timeout instead, as generations can take minutes.
+const DefaultTimeout = 60 * time.Second
+
+// Pool sizes of the shared transport. A run sends most of its requests to a handful of hosts, so
+// the idle connections per host are raised from the net/http default of 2.
+const (
+	maxIdleConns        = 100
+	maxIdleConnsPerHost = 16
+)
+
+// Transport is the transport shared by all the clients. It honors the proxy environment variables,
+// like http.DefaultTransport.
+var Transport http.RoundTripper = newTransport()
+
+func newTransport() *http.Transport {
+	return &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          maxIdleConns,
+		MaxIdleConnsPerHost:   maxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+}
+
+// New returns a client on the shared transport with the given timeout (0 for none).
+func New(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: Transport, Timeout: timeout}
+}
+
+// OAuth2Context returns a context making the oauth2 package build its clients, and fetch its
+// tokens, on the shared transport.
+func OAuth2Context(ctx context.Context) context.Context {
+	return context.WithValue(ctx, oauth2.HTTPClient, New(DefaultTimeout))
+}
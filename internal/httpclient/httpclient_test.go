@@ -0,0 +1,27 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+func TestNew(t *testing.T) {
+	a, b := New(DefaultTimeout), New(0)
+	assert.Same(t, a.Transport, b.Transport)
+	assert.Equal(t, DefaultTimeout, a.Timeout)
+	assert.Zero(t, b.Timeout)
+
+	transport := Transport.(*http.Transport)
+	assert.Equal(t, maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.NotNil(t, transport.Proxy)
+}
+
+func TestOAuth2Context(t *testing.T) {
+	ctx := OAuth2Context(context.Background())
+	client := oauth2.NewClient(ctx, oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "token"}))
+	assert.Same(t, Transport, client.Transport.(*oauth2.Transport).Base)
+}
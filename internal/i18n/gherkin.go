@@ -0,0 +1,35 @@
+package i18n
+
+// Gherkin are the keywords of the Gherkin language in a spoken language, as defined by Cucumber.
+type Gherkin struct {
+	Code     string // Code of the "# language:" header
+	Feature  string
+	Scenario string
+	Given    string
+	When     string
+	Then     string
+	And      string
+	But      string
+}
+
+// EnglishGherkin are the default Gherkin keywords.
+var EnglishGherkin = Gherkin{Code: "en", Feature: "Feature", Scenario: "Scenario", Given: "Given", When: "When", Then: "Then", And: "And", But: "But"}
+
+// gherkinKeywords are the Gherkin keywords of the built-in languages.
+var gherkinKeywords = map[string]Gherkin{
+	"english":    EnglishGherkin,
+	"portuguese": {Code: "pt", Feature: "Funcionalidade", Scenario: "Cenário", Given: "Dado", When: "Quando", Then: "Então", And: "E", But: "Mas"},
+	"spanish":    {Code: "es", Feature: "Característica", Scenario: "Escenario", Given: "Dado", When: "Cuando", Then: "Entonces", And: "Y", But: "Pero"},
+	"french":     {Code: "fr", Feature: "Fonctionnalité", Scenario: "Scénario", Given: "Soit", When: "Quand", Then: "Alors", And: "Et", But: "Mais"},
+	"german":     {Code: "de", Feature: "Funktionalität", Scenario: "Szenario", Given: "Angenommen", When: "Wenn", Then: "Dann", And: "Und", But: "Aber"},
+	"italian":    {Code: "it", Feature: "Funzionalità", Scenario: "Scenario", Given: "Dato", When: "Quando", Then: "Allora", And: "E", But: "Ma"},
+}
+
+// GherkinFor returns the Gherkin keywords of a language, by name or code. Unknown languages use
+// English.
+func GherkinFor(language string) Gherkin {
+	if g, ok := gherkinKeywords[normalize(language)]; ok {
+		return g
+	}
+	return EnglishGherkin
+}
@@ -0,0 +1,13 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGherkinFor(t *testing.T) {
+	assert.Equal(t, "Funcionalidade", GherkinFor("pt-BR").Feature)
+	assert.Equal(t, "Entonces", GherkinFor("spanish").Then)
+	assert.Equal(t, EnglishGherkin, GherkinFor("dutch"))
+}
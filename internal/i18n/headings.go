@@ -0,0 +1,135 @@
+// Package i18n translates the fixed texts aigile adds to the generated issues, such as the
+// section headings of the issue bodies, to the language of the generated content.
+package i18n
+
+import "strings"
+
+// Headings are the section headings of the issue bodies.
+type Headings struct {
+	AcceptanceCriteria string `yaml:"acceptance_criteria"`
+	SuggestedTasks     string `yaml:"suggested_tasks"`
+	Stories            string `yaml:"stories"`            // Stories tracked by an epic
+	QAVerification     string `yaml:"qa_verification"`    // Heading of the QA checklists
+	PossibleDuplicate  string `yaml:"possible_duplicate"` // Label of the likely duplicate of an issue
+	NeedsVerification  string `yaml:"needs_verification"` // Label of the terms the LLM may have made up
+	SuggestedSplit     string `yaml:"suggested_split"`    // Vertical slices proposed for an oversized story
+	SliceOf            string `yaml:"slice_of"`           // Label of the story a slice was created from
+}
+
+// English are the default headings.
+var English = Headings{
+	AcceptanceCriteria: "Acceptance Criteria",
+	SuggestedTasks:     "Suggested Tasks",
+	Stories:            "Stories",
+	QAVerification:     "QA Verification",
+	PossibleDuplicate:  "Possible duplicate",
+	NeedsVerification:  "Needs human verification",
+	SuggestedSplit:     "Suggested Split",
+	SliceOf:            "Slice of",
+}
+
+// translations are the built-in headings by language.
+var translations = map[string]Headings{
+	"english": English,
+	"portuguese": {
+		AcceptanceCriteria: "Critérios de Aceitação",
+		SuggestedTasks:     "Tarefas Sugeridas",
+		Stories:            "Histórias",
+		QAVerification:     "Verificação de QA",
+		PossibleDuplicate:  "Possível duplicata",
+		NeedsVerification:  "Requer verificação humana",
+		SuggestedSplit:     "Divisão Sugerida",
+		SliceOf:            "Fatia de",
+	},
+	"spanish": {
+		AcceptanceCriteria: "Criterios de Aceptación",
+		SuggestedTasks:     "Tareas Sugeridas",
+		Stories:            "Historias",
+		QAVerification:     "Verificación de QA",
+		PossibleDuplicate:  "Posible duplicado",
+		NeedsVerification:  "Requiere verificación humana",
+		SuggestedSplit:     "División Sugerida",
+		SliceOf:            "Porción de",
+	},
+	"french": {
+		AcceptanceCriteria: "Critères d'Acceptation",
+		SuggestedTasks:     "Tâches Suggérées",
+		Stories:            "Récits",
+		QAVerification:     "Vérification QA",
+		PossibleDuplicate:  "Doublon possible",
+		NeedsVerification:  "Vérification humaine requise",
+		SuggestedSplit:     "Découpage Suggéré",
+		SliceOf:            "Tranche de",
+	},
+	"german": {
+		AcceptanceCriteria: "Akzeptanzkriterien",
+		SuggestedTasks:     "Vorgeschlagene Aufgaben",
+		Stories:            "Stories",
+		QAVerification:     "QA-Prüfung",
+		PossibleDuplicate:  "Mögliches Duplikat",
+		NeedsVerification:  "Menschliche Prüfung erforderlich",
+		SuggestedSplit:     "Vorgeschlagene Aufteilung",
+		SliceOf:            "Teil von",
+	},
+	"italian": {
+		AcceptanceCriteria: "Criteri di Accettazione",
+		SuggestedTasks:     "Attività Suggerite",
+		Stories:            "Storie",
+		QAVerification:     "Verifica QA",
+		PossibleDuplicate:  "Possibile duplicato",
+		NeedsVerification:  "Richiede verifica umana",
+		SuggestedSplit:     "Suddivisione Suggerita",
+		SliceOf:            "Parte di",
+	},
+}
+
+// aliases maps other names and codes of the languages to the keys of translations.
+var aliases = map[string]string{
+	"en": "english", "en-us": "english", "en-gb": "english",
+	"pt": "portuguese", "pt-br": "portuguese", "pt-pt": "portuguese", "português": "portuguese", "portugues": "portuguese",
+	"es": "spanish", "español": "spanish", "espanol": "spanish",
+	"fr": "french", "français": "french", "francais": "french",
+	"de": "german", "deutsch": "german",
+	"it": "italian", "italiano": "italian",
+}
+
+// normalize returns the key of a language name or code, e.g. "pt-BR" is "portuguese".
+func normalize(language string) string {
+	key := strings.ToLower(strings.TrimSpace(language))
+	key = strings.ReplaceAll(key, "_", "-")
+	if alias, ok := aliases[key]; ok {
+		return alias
+	}
+	return key
+}
+
+// For returns the headings of a language, with the fields set in overrides for that language (by
+// name or code) replacing the built-in ones. Unknown languages use English.
+func For(language string, overrides map[string]Headings) Headings {
+	key := normalize(language)
+	h, ok := translations[key]
+	if !ok {
+		h = English
+	}
+	for name, o := range overrides {
+		if normalize(name) != key {
+			continue
+		}
+		h.AcceptanceCriteria = override(h.AcceptanceCriteria, o.AcceptanceCriteria)
+		h.SuggestedTasks = override(h.SuggestedTasks, o.SuggestedTasks)
+		h.Stories = override(h.Stories, o.Stories)
+		h.QAVerification = override(h.QAVerification, o.QAVerification)
+		h.PossibleDuplicate = override(h.PossibleDuplicate, o.PossibleDuplicate)
+		h.NeedsVerification = override(h.NeedsVerification, o.NeedsVerification)
+		h.SuggestedSplit = override(h.SuggestedSplit, o.SuggestedSplit)
+		h.SliceOf = override(h.SliceOf, o.SliceOf)
+	}
+	return h
+}
+
+func override(value, o string) string {
+	if o != "" {
+		return o
+	}
+	return value
+}
@@ -0,0 +1,25 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFor(t *testing.T) {
+	assert.Equal(t, English, For("english", nil))
+	assert.Equal(t, English, For("klingon", nil))
+	assert.Equal(t, "Critérios de Aceitação", For("portuguese", nil).AcceptanceCriteria)
+	assert.Equal(t, "Critérios de Aceitação", For("pt_BR", nil).AcceptanceCriteria)
+	assert.Equal(t, "Tareas Sugeridas", For(" Spanish ", nil).SuggestedTasks)
+
+	overrides := map[string]Headings{
+		"pt-BR":   {AcceptanceCriteria: "Critérios de Aceite"},
+		"klingon": {AcceptanceCriteria: "ngoQ"},
+	}
+	h := For("portuguese", overrides)
+	assert.Equal(t, "Critérios de Aceite", h.AcceptanceCriteria)
+	assert.Equal(t, "Tarefas Sugeridas", h.SuggestedTasks)
+	assert.Equal(t, "ngoQ", For("Klingon", overrides).AcceptanceCriteria)
+	assert.Equal(t, "Stories", For("Klingon", overrides).Stories)
+}
@@ -0,0 +1,272 @@
+// Package index persists the embeddings of the issues of a provider, so the similarity features
+// (duplicate checks, search and retrieval) only embed the issues that are new or changed since the
+// last refresh instead of the whole backlog on every run.
+package index
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/similar"
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver
+)
+
+// BatchSize is the number of issues embedded per request.
+const BatchSize = 100
+
+// schema creates the embeddings table. The scope identifies the repository or project the issue
+// numbers belong to, and times are stored as unix milliseconds.
+const schema = `
+CREATE TABLE IF NOT EXISTS embeddings (
+	scope      TEXT NOT NULL,
+	number     INTEGER NOT NULL,
+	model      TEXT NOT NULL,
+	hash       TEXT NOT NULL,
+	title      TEXT NOT NULL DEFAULT '',
+	url        TEXT NOT NULL DEFAULT '',
+	vector     BLOB NOT NULL,
+	updated_at INTEGER NOT NULL,
+	PRIMARY KEY (scope, number)
+);
+`
+
+// Issue is an issue to index.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	URL    string
+}
+
+// Stats are the outcome of a refresh.
+type Stats struct {
+	Embedded  int // Issues new or changed since the last refresh, or embedded by another model
+	Unchanged int // Issues whose stored embedding was reused
+	Tokens    int // Tokens used to embed the issues
+}
+
+// Scope summarizes the embeddings stored for a scope.
+type Scope struct {
+	Name      string
+	Model     string
+	Issues    int
+	UpdatedAt time.Time
+}
+
+// Index is the SQLite database of the embeddings.
+type Index struct {
+	db *sql.DB
+}
+
+// Open opens (or creates) the index database at path. An empty path opens an in-memory index that
+// is discarded on Close.
+func Open(path string) (*Index, error) {
+	if path == "" {
+		path = ":memory:"
+	} else if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create index directory: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open index database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize index database: %w", err)
+	}
+	return &Index{db: db}, nil
+}
+
+// Close closes the database.
+func (ix *Index) Close() error {
+	return ix.db.Close()
+}
+
+// stored is an embedding read from the database.
+type stored struct {
+	model  string
+	hash   string
+	vector []float32
+}
+
+// Refresh embeds the issues of the scope that are not stored yet, changed since they were stored or
+// were embedded by another model, stores them, and returns the similarity index of all the issues.
+// Stored issues missing from the list are kept, as the list may be filtered; see Prune.
+func (ix *Index) Refresh(ctx context.Context, scope string, issues []Issue, embedder llm.Embedder, model string) (*similar.Index, Stats, error) {
+	var stats Stats
+	existing, err := ix.load(ctx, scope)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	vectors := make([][]float32, len(issues))
+	hashes := make([]string, len(issues))
+	var pending []int
+	for i, issue := range issues {
+		hashes[i] = Hash(similar.Text(issue.Title, issue.Body))
+		if e, ok := existing[issue.Number]; ok && e.model == model && e.hash == hashes[i] {
+			vectors[i] = e.vector
+			stats.Unchanged++
+			continue
+		}
+		pending = append(pending, i)
+	}
+
+	for start := 0; start < len(pending); start += BatchSize {
+		batch := pending[start:min(start+BatchSize, len(pending))]
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = similar.Text(issues[i].Title, issues[i].Body)
+		}
+		embedded, usage, err := embedder.Embed(ctx, texts)
+		if err != nil {
+			return nil, stats, err
+		}
+		stats.Tokens += usage.PromptTokens
+		for j, i := range batch {
+			vectors[i] = embedded[j]
+			if err := ix.put(ctx, scope, model, hashes[i], issues[i], embedded[j]); err != nil {
+				return nil, stats, err
+			}
+		}
+		stats.Embedded += len(batch)
+	}
+
+	index := &similar.Index{}
+	for i, issue := range issues {
+		index.Add(similar.Document{Number: issue.Number, Title: issue.Title, URL: issue.URL, Vector: vectors[i]})
+	}
+	return index, stats, nil
+}
+
+// Put stores the embedding of an issue computed elsewhere, such as the one of a generated story
+// compared before its issue was created.
+func (ix *Index) Put(ctx context.Context, scope, model string, issue Issue, vector []float32) error {
+	return ix.put(ctx, scope, model, Hash(similar.Text(issue.Title, issue.Body)), issue, vector)
+}
+
+// put upserts the embedding of an issue.
+func (ix *Index) put(ctx context.Context, scope, model, hash string, issue Issue, vector []float32) error {
+	_, err := ix.db.ExecContext(ctx, `INSERT INTO embeddings (scope, number, model, hash, title, url, vector, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (scope, number) DO UPDATE SET model = excluded.model, hash = excluded.hash, title = excluded.title,
+			url = excluded.url, vector = excluded.vector, updated_at = excluded.updated_at`,
+		scope, issue.Number, model, hash, issue.Title, issue.URL, encode(vector), time.Now().UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to store embedding: %w", err)
+	}
+	return nil
+}
+
+// load returns the stored embeddings of a scope by issue number.
+func (ix *Index) load(ctx context.Context, scope string) (map[int]stored, error) {
+	rows, err := ix.db.QueryContext(ctx, `SELECT number, model, hash, vector FROM embeddings WHERE scope = ?`, scope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embeddings: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	existing := make(map[int]stored)
+	for rows.Next() {
+		var number int
+		var e stored
+		var blob []byte
+		if err := rows.Scan(&number, &e.model, &e.hash, &blob); err != nil {
+			return nil, fmt.Errorf("failed to read embeddings: %w", err)
+		}
+		e.vector = decode(blob)
+		existing[number] = e
+	}
+	return existing, rows.Err()
+}
+
+// Prune deletes the embeddings of the scope whose issues are not in keep, such as closed or
+// deleted issues, and returns how many were deleted.
+func (ix *Index) Prune(ctx context.Context, scope string, keep []int) (int, error) {
+	existing, err := ix.load(ctx, scope)
+	if err != nil {
+		return 0, err
+	}
+	kept := make(map[int]bool, len(keep))
+	for _, number := range keep {
+		kept[number] = true
+	}
+	deleted := 0
+	for number := range existing {
+		if kept[number] {
+			continue
+		}
+		if _, err := ix.db.ExecContext(ctx, `DELETE FROM embeddings WHERE scope = ? AND number = ?`, scope, number); err != nil {
+			return deleted, fmt.Errorf("failed to delete embedding: %w", err)
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// Clear deletes the embeddings of a scope, or of all scopes when scope is empty, and returns how
+// many were deleted.
+func (ix *Index) Clear(ctx context.Context, scope string) (int, error) {
+	res, err := ix.db.ExecContext(ctx, `DELETE FROM embeddings WHERE ? = '' OR scope = ?`, scope, scope)
+	if err != nil {
+		return 0, fmt.Errorf("failed to clear embeddings: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	return int(n), nil
+}
+
+// Scopes summarizes the stored embeddings by scope and model.
+func (ix *Index) Scopes(ctx context.Context) ([]Scope, error) {
+	rows, err := ix.db.QueryContext(ctx, `SELECT scope, model, COUNT(*), MAX(updated_at) FROM embeddings
+		GROUP BY scope, model ORDER BY scope, model`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scopes: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+	var scopes []Scope
+	for rows.Next() {
+		var s Scope
+		var updated int64
+		if err := rows.Scan(&s.Name, &s.Model, &s.Issues, &updated); err != nil {
+			return nil, fmt.Errorf("failed to list scopes: %w", err)
+		}
+		s.UpdatedAt = time.UnixMilli(updated)
+		scopes = append(scopes, s)
+	}
+	return scopes, rows.Err()
+}
+
+// Hash returns the hash of an embedded text, used to detect the issues that changed.
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:8])
+}
+
+// encode serializes a vector as little-endian float32 values.
+func encode(vector []float32) []byte {
+	b := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(v))
+	}
+	return b
+}
+
+// decode deserializes a vector written by encode.
+func decode(b []byte) []float32 {
+	vector := make([]float32, len(b)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return vector
+}
@@ -0,0 +1,120 @@
+package index
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingEmbedder embeds texts with the mock provider and counts the texts embedded.
+type countingEmbedder struct {
+	texts int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, llm.Usage, error) {
+	e.texts += len(texts)
+	return (&llm.MockProvider{}).Embed(ctx, texts)
+}
+
+// TestIndex_Refresh tests that only new, changed or re-modeled issues are embedded across opens.
+func TestIndex_Refresh(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "index", "index.db")
+	issues := []Issue{
+		{Number: 1, Title: "Pay by card", Body: "Checkout with a credit card", URL: "https://example.com/1"},
+		{Number: 2, Title: "Refunds", Body: "Refund a payment"},
+	}
+
+	ix, err := Open(path)
+	require.NoError(t, err)
+	embedder := &countingEmbedder{}
+	index, stats, err := ix.Refresh(ctx, "github:o/r", issues, embedder, "mock")
+	require.NoError(t, err)
+	assert.Equal(t, 2, index.Len())
+	assert.Equal(t, 2, stats.Embedded)
+	assert.Zero(t, stats.Unchanged)
+	require.NoError(t, ix.Close())
+
+	ix, err = Open(path)
+	require.NoError(t, err)
+	defer ix.Close()
+	issues[1].Body = "Refund a card payment"
+	issues = append(issues, Issue{Number: 3, Title: "Receipts"})
+	embedder.texts = 0
+	index, stats, err = ix.Refresh(ctx, "github:o/r", issues, embedder, "mock")
+	require.NoError(t, err)
+	assert.Equal(t, 3, index.Len())
+	assert.Equal(t, 2, stats.Embedded)
+	assert.Equal(t, 1, stats.Unchanged)
+	assert.Equal(t, 2, embedder.texts)
+
+	matches := index.Nearest(mustEmbed(t, "Pay by card"), 1, 0)
+	require.Len(t, matches, 1)
+	assert.Equal(t, 1, matches[0].Number)
+	assert.Equal(t, "https://example.com/1", matches[0].URL)
+
+	embedder.texts = 0
+	_, stats, err = ix.Refresh(ctx, "github:o/r", issues, embedder, "other")
+	require.NoError(t, err)
+	assert.Equal(t, 3, stats.Embedded)
+
+	embedder.texts = 0
+	_, stats, err = ix.Refresh(ctx, "github:o/other", issues[:1], embedder, "other")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Embedded)
+}
+
+// TestIndex_PutPruneClear tests storing, pruning and clearing embeddings by scope.
+func TestIndex_PutPruneClear(t *testing.T) {
+	ctx := context.Background()
+	ix, err := Open("")
+	require.NoError(t, err)
+	defer ix.Close()
+
+	issue := Issue{Number: 5, Title: "Pay by card"}
+	require.NoError(t, ix.Put(ctx, "a", "mock", issue, mustEmbed(t, "Pay by card")))
+	require.NoError(t, ix.Put(ctx, "a", "mock", Issue{Number: 6, Title: "Refunds"}, mustEmbed(t, "Refunds")))
+	require.NoError(t, ix.Put(ctx, "b", "mock", issue, mustEmbed(t, "Pay by card")))
+
+	embedder := &countingEmbedder{}
+	_, stats, err := ix.Refresh(ctx, "a", []Issue{issue}, embedder, "mock")
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Unchanged)
+	assert.Zero(t, embedder.texts)
+
+	deleted, err := ix.Prune(ctx, "a", []int{5})
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+
+	scopes, err := ix.Scopes(ctx)
+	require.NoError(t, err)
+	require.Len(t, scopes, 2)
+	assert.Equal(t, "a", scopes[0].Name)
+	assert.Equal(t, "mock", scopes[0].Model)
+	assert.Equal(t, 1, scopes[0].Issues)
+	assert.False(t, scopes[0].UpdatedAt.IsZero())
+
+	deleted, err = ix.Clear(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	deleted, err = ix.Clear(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+}
+
+func TestEncode(t *testing.T) {
+	vector := []float32{0, 1.5, -2.25, 3e-8}
+	assert.Equal(t, vector, decode(encode(vector)))
+	assert.Empty(t, decode(nil))
+}
+
+// mustEmbed embeds a text with the mock provider.
+func mustEmbed(t *testing.T, text string) []float32 {
+	vectors, _, err := (&llm.MockProvider{}).Embed(context.Background(), []string{text})
+	require.NoError(t, err)
+	return vectors[0]
+}
@@ -0,0 +1,362 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	bedrocktypes "github.com/aws/aws-sdk-go-v2/service/bedrock/types"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/release"
+	"github.com/leocomelli/aigile/internal/sprint"
+)
+
+// ConverseClient is an interface for the Bedrock runtime client, allowing mocking in tests.
+type ConverseClient interface {
+	Converse(ctx context.Context, params *bedrockruntime.ConverseInput, optFns ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error)
+}
+
+// FoundationModelsClient is an interface for the Bedrock control plane client, allowing mocking in tests.
+type FoundationModelsClient interface {
+	ListFoundationModels(ctx context.Context, params *bedrock.ListFoundationModelsInput, optFns ...func(*bedrock.Options)) (*bedrock.ListFoundationModelsOutput, error)
+}
+
+// BedrockProvider implements the Provider interface for models hosted on AWS Bedrock (Claude,
+// Titan, etc.) through the Converse API.
+type BedrockProvider struct {
+	client  ConverseClient
+	models  FoundationModelsClient
+	model   string
+	prompts PromptManager
+	limit   promptLimit
+	images  *imageLoader
+}
+
+// NewBedrockProvider creates a new BedrockProvider. Credentials and region are resolved through
+// the default AWS SDK chain (environment, shared config and profiles, SSO, instance roles). The
+// endpoint, when set, overrides the Bedrock runtime endpoint, e.g. for a VPC endpoint.
+func NewBedrockProvider(config Config) (*BedrockProvider, error) {
+	if config.Model == "" {
+		return nil, fmt.Errorf("a model ID is required for the bedrock provider")
+	}
+
+	// Like the other LLM clients, Bedrock is on the shared transport, bounded by the item timeout
+	awsConfig, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithHTTPClient(httpclient.New(0)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS configuration: %w", err)
+	}
+	client := bedrockruntime.NewFromConfig(awsConfig, func(o *bedrockruntime.Options) {
+		if config.Endpoint != "" {
+			o.BaseEndpoint = aws.String(config.Endpoint)
+		}
+	})
+
+	return &BedrockProvider{
+		client:  client,
+		models:  bedrock.NewFromConfig(awsConfig),
+		model:   config.Model,
+		prompts: prompt.NewManager(),
+		limit:   newPromptLimit(config),
+		images:  newImageLoader(config),
+	}, nil
+}
+
+// GenerateContent generates content using the Bedrock Converse API.
+func (p *BedrockProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	input, err := p.input(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.converse(ctx, req, input)
+}
+
+// Refine continues the conversation of the request, asking the model to critique and improve its
+// previous answer.
+func (p *BedrockProvider) Refine(ctx context.Context, req Request, previous *GeneratedContent, feedback []string) (*GeneratedContent, error) {
+	input, err := p.input(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := json.Marshal(previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode previous answer: %w", err)
+	}
+	input.Messages = append(input.Messages,
+		types.Message{Role: types.ConversationRoleAssistant, Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: string(answer)}}},
+		types.Message{Role: types.ConversationRoleUser, Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: refineMessage(feedback)}}},
+	)
+	return p.converse(ctx, req, input)
+}
+
+// input returns the Converse input with the system prompt and the user message of a request, with
+// the design images of the item attached to the user message.
+func (p *BedrockProvider) input(ctx context.Context, req Request) (*bedrockruntime.ConverseInput, error) {
+	promptText, systemText, err := getPrompts(p.prompts, req)
+	if err != nil {
+		return nil, err
+	}
+
+	input := &bedrockruntime.ConverseInput{ModelId: aws.String(p.model)}
+	// Titan text models do not accept a system prompt, so it is sent as part of the user message
+	if strings.HasPrefix(p.model, "amazon.titan") {
+		promptText = systemText + "\n\n" + promptText
+	} else {
+		input.System = []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: systemText}}
+	}
+	images := p.images.load(ctx, req)
+	if len(images) > 0 {
+		promptText += imagePrompt
+	}
+	content := []types.ContentBlock{&types.ContentBlockMemberText{Value: promptText}}
+	for _, img := range images {
+		content = append(content, &types.ContentBlockMemberImage{Value: types.ImageBlock{
+			Format: types.ImageFormat(img.format()),
+			Source: &types.ImageSourceMemberBytes{Value: img.data},
+		}})
+	}
+	input.Messages = []types.Message{{Role: types.ConversationRoleUser, Content: content}}
+	return input, nil
+}
+
+// converse checks the size of the conversation, sends it and parses the generated content from
+// the answer.
+func (p *BedrockProvider) converse(ctx context.Context, req Request, input *bedrockruntime.ConverseInput) (*GeneratedContent, error) {
+	var texts []string
+	for _, block := range input.System {
+		if t, ok := block.(*types.SystemContentBlockMemberText); ok {
+			texts = append(texts, t.Value)
+		}
+	}
+	for _, message := range input.Messages {
+		for _, block := range message.Content {
+			if t, ok := block.(*types.ContentBlockMemberText); ok {
+				texts = append(texts, t.Value)
+			}
+		}
+	}
+	if err := p.limit.check(req, texts...); err != nil {
+		return nil, err
+	}
+
+	text, usage, err := p.send(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	result, err := parseGeneratedContent(text)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage = usage
+
+	return result, nil
+}
+
+// Summarize condenses text, used to shrink contexts that exceed the model context window.
+func (p *BedrockProvider) Summarize(ctx context.Context, text string, language string) (string, Usage, error) {
+	summary, usage, err := p.send(ctx, p.textInput(summarizeSystemPrompt, summarizeMessage(text, language)))
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to summarize: %w", err)
+	}
+	return summary, usage, nil
+}
+
+// Plan proposes the backlog of a product document.
+func (p *BedrockProvider) Plan(ctx context.Context, doc string, language string, format prompt.CriteriaFormat) (*Plan, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(planSystemPrompt, planMessage(doc, language, format)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to plan the backlog: %w", err)
+	}
+	plan, err := parsePlan(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to plan the backlog: %w", err)
+	}
+	return plan, usage, nil
+}
+
+// SplitStory splits the context of a row into the independent User Stories it describes, a single
+// one when it describes only one.
+func (p *BedrockProvider) SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(splitSystemPrompt, splitMessage(req)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to split story: %w", err)
+	}
+	parts, err := parseParts(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to split story: %w", err)
+	}
+	return parts, usage, nil
+}
+
+// SliceStory proposes vertical slices of an oversized User Story.
+func (p *BedrockProvider) SliceStory(ctx context.Context, story *GeneratedContent, language string) ([]GeneratedContent, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(sliceSystemPrompt, sliceMessage(story, language)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to slice story: %w", err)
+	}
+	slices, err := parseSlices(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to slice story: %w", err)
+	}
+	return slices, usage, nil
+}
+
+// ExtractStories lists the candidate User Stories discussed in a meeting transcript.
+func (p *BedrockProvider) ExtractStories(ctx context.Context, transcript string, language string) ([]StoryCandidate, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(extractSystemPrompt, extractMessage(transcript, language)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to extract stories: %w", err)
+	}
+	candidates, err := parseCandidates(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to extract stories: %w", err)
+	}
+	return candidates, usage, nil
+}
+
+// TranslateIssue translates the title and body of an issue, keeping the structure of the body.
+func (p *BedrockProvider) TranslateIssue(ctx context.Context, title, body, language string) (*Translation, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(translateSystemPrompt, translateMessage(title, body, language)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to translate issue: %w", err)
+	}
+	translation, err := parseTranslation(text, strings.TrimSpace(body) != "")
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to translate issue: %w", err)
+	}
+	return translation, usage, nil
+}
+
+// WriteReleaseNotes writes the release notes of the issues completed in a release.
+func (p *BedrockProvider) WriteReleaseNotes(ctx context.Context, notes release.Notes, language string) (string, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(releaseNotesSystemPrompt, releaseNotesMessage(notes, language)))
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to write release notes: %w", err)
+	}
+	return cleanMarkdownResponse(text), usage, nil
+}
+
+// SummarizeSprint writes the goal and summary of a sprint plan.
+func (p *BedrockProvider) SummarizeSprint(ctx context.Context, plan sprint.Plan, language string) (*SprintSummary, Usage, error) {
+	text, usage, err := p.send(ctx, p.textInput(sprintSystemPrompt, sprintMessage(plan, language)))
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to summarize sprint: %w", err)
+	}
+	summary, err := parseSprintSummary(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to summarize sprint: %w", err)
+	}
+	return summary, usage, nil
+}
+
+// Chat sends a free-form conversation. The system messages are sent as the system prompt, or
+// before the first message for Titan models.
+func (p *BedrockProvider) Chat(ctx context.Context, messages []ChatMessage) (string, Usage, error) {
+	input := &bedrockruntime.ConverseInput{ModelId: aws.String(p.model)}
+	var system []string
+	for _, m := range messages {
+		switch m.Role {
+		case ChatRoleSystem:
+			system = append(system, m.Content)
+		case ChatRoleAssistant:
+			input.Messages = append(input.Messages, types.Message{Role: types.ConversationRoleAssistant, Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: m.Content}}})
+		default:
+			input.Messages = append(input.Messages, types.Message{Role: types.ConversationRoleUser, Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: m.Content}}})
+		}
+	}
+	if len(system) > 0 {
+		if strings.HasPrefix(p.model, "amazon.titan") && len(input.Messages) > 0 {
+			first := input.Messages[0].Content[0].(*types.ContentBlockMemberText)
+			input.Messages[0].Content = []types.ContentBlock{&types.ContentBlockMemberText{Value: strings.Join(system, "\n\n") + "\n\n" + first.Value}}
+		} else {
+			for _, s := range system {
+				input.System = append(input.System, &types.SystemContentBlockMemberText{Value: s})
+			}
+		}
+	}
+	return p.send(ctx, input)
+}
+
+// textInput builds a single-message conversation with a system prompt, which Titan models get in
+// the user message as they do not support system prompts.
+func (p *BedrockProvider) textInput(system, message string) *bedrockruntime.ConverseInput {
+	input := &bedrockruntime.ConverseInput{ModelId: aws.String(p.model)}
+	if strings.HasPrefix(p.model, "amazon.titan") {
+		message = system + "\n\n" + message
+	} else {
+		input.System = []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: system}}
+	}
+	input.Messages = []types.Message{{
+		Role:    types.ConversationRoleUser,
+		Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: message}},
+	}}
+	return input
+}
+
+// send calls the Converse API and returns the text of the answer.
+func (p *BedrockProvider) send(ctx context.Context, input *bedrockruntime.ConverseInput) (string, Usage, error) {
+	resp, err := p.client.Converse(ctx, input)
+	if err != nil {
+		return "", Usage{}, err
+	}
+
+	var usage Usage
+	if resp.Usage != nil {
+		usage = Usage{
+			PromptTokens:     int(aws.ToInt32(resp.Usage.InputTokens)),
+			CompletionTokens: int(aws.ToInt32(resp.Usage.OutputTokens)),
+		}
+	}
+
+	message, ok := resp.Output.(*types.ConverseOutputMemberMessage)
+	if !ok {
+		return "", usage, fmt.Errorf("unexpected Bedrock response output: %T", resp.Output)
+	}
+	var text strings.Builder
+	for _, block := range message.Value.Content {
+		if t, ok := block.(*types.ContentBlockMemberText); ok {
+			text.WriteString(t.Value)
+		}
+	}
+	return text.String(), usage, nil
+}
+
+// ListModels returns the IDs of the text foundation models available in the configured region.
+func (p *BedrockProvider) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := p.models.ListFoundationModels(ctx, &bedrock.ListFoundationModelsInput{
+		ByOutputModality: bedrocktypes.ModelModalityText,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	models := make([]string, 0, len(resp.ModelSummaries))
+	for _, m := range resp.ModelSummaries {
+		models = append(models, aws.ToString(m.ModelId))
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// Ping sends a minimal Converse request to verify the credentials and model access.
+func (p *BedrockProvider) Ping(ctx context.Context) error {
+	_, err := p.client.Converse(ctx, &bedrockruntime.ConverseInput{
+		ModelId: aws.String(p.model),
+		Messages: []types.Message{{
+			Role:    types.ConversationRoleUser,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: pingPrompt}},
+		}},
+		InferenceConfig: &types.InferenceConfiguration{MaxTokens: aws.Int32(10)},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ping model %s: %w", p.model, err)
+	}
+	return nil
+}
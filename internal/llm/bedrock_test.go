@@ -0,0 +1,145 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrock"
+	bedrocktypes "github.com/aws/aws-sdk-go-v2/service/bedrock/types"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockConverseClient struct {
+	converseFunc func(ctx context.Context, input *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error)
+}
+
+func (m *mockConverseClient) Converse(ctx context.Context, input *bedrockruntime.ConverseInput, _ ...func(*bedrockruntime.Options)) (*bedrockruntime.ConverseOutput, error) {
+	return m.converseFunc(ctx, input)
+}
+
+type mockFoundationModelsClient struct {
+	models []string
+}
+
+func (m *mockFoundationModelsClient) ListFoundationModels(_ context.Context, input *bedrock.ListFoundationModelsInput, _ ...func(*bedrock.Options)) (*bedrock.ListFoundationModelsOutput, error) {
+	if input.ByOutputModality != bedrocktypes.ModelModalityText {
+		return nil, errors.New("unexpected modality")
+	}
+	out := &bedrock.ListFoundationModelsOutput{}
+	for _, m := range m.models {
+		out.ModelSummaries = append(out.ModelSummaries, bedrocktypes.FoundationModelSummary{ModelId: aws.String(m)})
+	}
+	return out, nil
+}
+
+// converseText builds a Converse response with a single text block.
+func converseText(text string) *bedrockruntime.ConverseOutput {
+	return &bedrockruntime.ConverseOutput{
+		Output: &types.ConverseOutputMemberMessage{Value: types.Message{
+			Role:    types.ConversationRoleAssistant,
+			Content: []types.ContentBlock{&types.ContentBlockMemberText{Value: text}},
+		}},
+		Usage: &types.TokenUsage{InputTokens: aws.Int32(10), OutputTokens: aws.Int32(4)},
+	}
+}
+
+func newTestBedrockProvider(model string, client ConverseClient) *BedrockProvider {
+	return &BedrockProvider{
+		client: client,
+		model:  model,
+		prompts: &mockPromptManager{getPromptFunc: func(prompt.ItemType, prompt.Data) (string, error) {
+			return "prompt", nil
+		}},
+	}
+}
+
+func TestBedrockProvider_GenerateContent_Success(t *testing.T) {
+	var input *bedrockruntime.ConverseInput
+	provider := newTestBedrockProvider("anthropic.claude-3-5-sonnet-20240620-v1:0", &mockConverseClient{
+		converseFunc: func(_ context.Context, in *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			input = in
+			return converseText("Here it is: " + `{"title":"T","description":"D","type":"User Story","acceptance_criteria":["A"]}`), nil
+		},
+	})
+
+	result, err := provider.GenerateContent(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "T", result.Title)
+	assert.Equal(t, Usage{PromptTokens: 10, CompletionTokens: 4}, result.Usage)
+
+	assert.Equal(t, "anthropic.claude-3-5-sonnet-20240620-v1:0", aws.ToString(input.ModelId))
+	assert.Equal(t, []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "system"}}, input.System)
+	assert.Equal(t, []types.ContentBlock{&types.ContentBlockMemberText{Value: "prompt"}}, input.Messages[0].Content)
+}
+
+func TestBedrockProvider_GenerateContent_Titan(t *testing.T) {
+	var input *bedrockruntime.ConverseInput
+	provider := newTestBedrockProvider("amazon.titan-text-premier-v1:0", &mockConverseClient{
+		converseFunc: func(_ context.Context, in *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			input = in
+			return converseText(`{"title":"T","description":"D","type":"User Story","acceptance_criteria":["A"]}`), nil
+		},
+	})
+
+	_, err := provider.GenerateContent(context.Background(), testRequest)
+	require.NoError(t, err)
+	assert.Empty(t, input.System)
+	assert.Equal(t, []types.ContentBlock{&types.ContentBlockMemberText{Value: "system\n\nprompt"}}, input.Messages[0].Content)
+}
+
+func TestBedrockProvider_GenerateContent_APIError(t *testing.T) {
+	provider := newTestBedrockProvider("anthropic.claude", &mockConverseClient{
+		converseFunc: func(context.Context, *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			return nil, errors.New("AccessDeniedException")
+		},
+	})
+
+	_, err := provider.GenerateContent(context.Background(), testRequest)
+	assert.ErrorContains(t, err, "failed to generate content")
+}
+
+func TestBedrockProvider_GenerateContent_InvalidJSON(t *testing.T) {
+	provider := newTestBedrockProvider("anthropic.claude", &mockConverseClient{
+		converseFunc: func(context.Context, *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			return converseText("not json"), nil
+		},
+	})
+
+	_, err := provider.GenerateContent(context.Background(), testRequest)
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+}
+
+func TestNewBedrockProvider_RequiresModel(t *testing.T) {
+	_, err := NewBedrockProvider(Config{})
+	assert.ErrorContains(t, err, "model ID is required")
+}
+
+func TestBedrockProvider_ListModels(t *testing.T) {
+	provider := &BedrockProvider{models: &mockFoundationModelsClient{models: []string{"anthropic.claude-v2", "amazon.titan-text-express-v1"}}}
+	models, err := provider.ListModels(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"amazon.titan-text-express-v1", "anthropic.claude-v2"}, models)
+}
+
+func TestBedrockProvider_Ping(t *testing.T) {
+	var input *bedrockruntime.ConverseInput
+	provider := newTestBedrockProvider("anthropic.claude", &mockConverseClient{
+		converseFunc: func(_ context.Context, in *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			input = in
+			return converseText("OK"), nil
+		},
+	})
+	require.NoError(t, provider.Ping(context.Background()))
+	assert.Equal(t, int32(10), aws.ToInt32(input.InferenceConfig.MaxTokens))
+
+	provider.client = &mockConverseClient{converseFunc: func(context.Context, *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+		return nil, errors.New("AccessDeniedException")
+	}}
+	assert.ErrorContains(t, provider.Ping(context.Background()), "failed to ping model anthropic.claude")
+}
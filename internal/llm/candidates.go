@@ -0,0 +1,94 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+)
+
+// Candidate is one of the variants generated for an item, with its quality score.
+type Candidate struct {
+	Content *GeneratedContent
+	Score   int
+	Issues  []string
+}
+
+// Selector chooses one of the candidates generated for a request and returns its index.
+type Selector func(req Request, candidates []Candidate) (int, error)
+
+// BestCandidate selects the candidate with the highest score, the first one on ties.
+func BestCandidate(_ Request, candidates []Candidate) (int, error) {
+	best := 0
+	for i, c := range candidates {
+		if c.Score > candidates[best].Score {
+			best = i
+		}
+	}
+	return best, nil
+}
+
+// CandidatesProvider wraps a provider to generate several variants of each item in parallel,
+// score them and keep the one chosen by its selector.
+type CandidatesProvider struct {
+	provider Provider
+	scorer   Scorer
+	n        int
+	selector Selector
+}
+
+// NewCandidatesProvider wraps a provider to generate n candidates per item. A nil selector picks
+// the best-scoring candidate.
+func NewCandidatesProvider(provider Provider, scorer Scorer, n int, selector Selector) *CandidatesProvider {
+	if selector == nil {
+		selector = BestCandidate
+	}
+	return &CandidatesProvider{provider: provider, scorer: scorer, n: n, selector: selector}
+}
+
+// GenerateContent generates the candidates concurrently and returns the selected one. Failed
+// candidates are logged and skipped; an error is only returned when all of them fail. The
+// returned usage is the sum of all candidates.
+func (p *CandidatesProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	results := make([]*GeneratedContent, p.n)
+	errs := make([]error, p.n)
+	var wg sync.WaitGroup
+	for i := range p.n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = p.provider.GenerateContent(ctx, req)
+		}()
+	}
+	wg.Wait()
+
+	var candidates []Candidate
+	var usage Usage
+	for i, content := range results {
+		if errs[i] != nil {
+			slog.Warn("candidate generation failed", "row", req.ID, "candidate", i+1, "error", errs[i])
+			continue
+		}
+		usage.PromptTokens += content.Usage.PromptTokens
+		usage.CompletionTokens += content.Usage.CompletionTokens
+		score, issues := p.scorer.Score(req, content)
+		slog.Debug("candidate scored", "row", req.ID, "candidate", i+1, "score", score, "issues", issues)
+		candidates = append(candidates, Candidate{Content: content, Score: score, Issues: issues})
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("all %d candidates failed: %w", p.n, errs[0])
+	}
+
+	selected, err := p.selector(req, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select candidate: %w", err)
+	}
+	if selected < 0 || selected >= len(candidates) {
+		return nil, fmt.Errorf("invalid candidate selected: %d", selected+1)
+	}
+	slog.Info("candidate selected", "row", req.ID, "candidate", selected+1, "score", candidates[selected].Score)
+
+	result := *candidates[selected].Content
+	result.Usage = usage
+	return &result, nil
+}
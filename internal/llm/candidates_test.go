@@ -0,0 +1,70 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sequenceProvider returns a title one character longer on each call, failing on the calls listed in fail.
+type sequenceProvider struct {
+	calls atomic.Int32
+	fail  map[int32]bool
+}
+
+func (s *sequenceProvider) GenerateContent(_ context.Context, _ Request) (*GeneratedContent, error) {
+	n := s.calls.Add(1)
+	if s.fail[n] {
+		return nil, errors.New("rate limited")
+	}
+	return &GeneratedContent{Title: strings.Repeat("a", int(n)), Usage: Usage{PromptTokens: 10, CompletionTokens: 1}}, nil
+}
+
+func TestCandidatesProvider_BestCandidate(t *testing.T) {
+	p := NewCandidatesProvider(&sequenceProvider{}, titleScorer{}, 3, nil)
+
+	result, err := p.GenerateContent(context.Background(), Request{ID: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "aaa", result.Title)
+	assert.Equal(t, Usage{PromptTokens: 30, CompletionTokens: 3}, result.Usage)
+}
+
+func TestCandidatesProvider_Selector(t *testing.T) {
+	var got []Candidate
+	selector := func(_ Request, candidates []Candidate) (int, error) {
+		got = candidates
+		return 0, nil
+	}
+	p := NewCandidatesProvider(&sequenceProvider{fail: map[int32]bool{2: true}}, titleScorer{}, 3, selector)
+
+	result, err := p.GenerateContent(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, got[0].Content.Title, result.Title)
+	assert.Equal(t, Usage{PromptTokens: 20, CompletionTokens: 2}, result.Usage)
+
+	p = NewCandidatesProvider(&sequenceProvider{}, titleScorer{}, 2, func(Request, []Candidate) (int, error) { return 5, nil })
+	_, err = p.GenerateContent(context.Background(), Request{})
+	assert.ErrorContains(t, err, "invalid candidate selected")
+
+	p = NewCandidatesProvider(&sequenceProvider{}, titleScorer{}, 2, func(Request, []Candidate) (int, error) { return 0, errors.New("aborted") })
+	_, err = p.GenerateContent(context.Background(), Request{})
+	assert.ErrorContains(t, err, "aborted")
+}
+
+func TestCandidatesProvider_AllFailed(t *testing.T) {
+	p := NewCandidatesProvider(&sequenceProvider{fail: map[int32]bool{1: true, 2: true}}, titleScorer{}, 2, nil)
+	_, err := p.GenerateContent(context.Background(), Request{})
+	assert.ErrorContains(t, err, "all 2 candidates failed: rate limited")
+}
+
+func TestBestCandidate(t *testing.T) {
+	best, err := BestCandidate(Request{}, []Candidate{{Score: 80}, {Score: 95}, {Score: 95}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, best)
+}
@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// Roles of the messages of a conversation.
+const (
+	ChatRoleSystem    = "system"
+	ChatRoleUser      = "user"
+	ChatRoleAssistant = "assistant"
+)
+
+// Chatter is implemented by providers that can hold a free-form conversation.
+type Chatter interface {
+	Chat(ctx context.Context, messages []ChatMessage) (string, Usage, error)
+}
+
+// ChatMessage is a message of a conversation.
+type ChatMessage struct {
+	Role    string
+	Content string
+}
+
+// issueChatPrompt instructs the model to discuss an issue with the user and propose new versions of
+// it. The current version is appended to it on every turn.
+const issueChatPrompt = `You are an Agile development expert helping a user refine an issue of their backlog.
+Answer the questions of the user about the issue and discuss the changes they ask for, keeping the structure of the body (sections, acceptance criteria, task lists and hidden comments).
+Whenever the issue should change, propose its complete new version.
+Return only the following JSON structure, without explanations:
+{
+  "message": "[your answer to the user]",
+  "title": "[new title, or empty when the title does not change]",
+  "body": "[complete new body in Markdown, or empty when the body does not change]"
+}
+Write in %s.
+
+Current issue title:
+%s
+
+Current issue body:
+%s`
+
+// ChatReply is an answer of the model in an issue chat.
+type ChatReply struct {
+	Message string `json:"message"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+}
+
+// Changed reports whether the reply proposes a new version of the issue.
+func (r *ChatReply) Changed() bool {
+	return r.Title != "" || r.Body != ""
+}
+
+// IssueChat is a conversation refining an issue. It keeps the history of the conversation and the
+// draft of the issue, updated with the versions proposed by the model.
+type IssueChat struct {
+	chatter  Chatter
+	language string
+	history  []ChatMessage
+	Title    string
+	Body     string
+	Usage    Usage // Tokens consumed by the conversation so far
+}
+
+// NewIssueChat starts a conversation about the issue with the given title and body.
+func NewIssueChat(chatter Chatter, title, body, language string) *IssueChat {
+	if language == "" {
+		language = "english"
+	}
+	return &IssueChat{chatter: chatter, language: language, Title: title, Body: body}
+}
+
+// Send sends a message of the user and returns the answer, updating the draft when the model
+// proposes a new version of the issue. Answers that are not in the expected JSON structure are
+// kept as plain messages.
+func (c *IssueChat) Send(ctx context.Context, text string) (*ChatReply, error) {
+	messages := append([]ChatMessage{{Role: ChatRoleSystem, Content: fmt.Sprintf(issueChatPrompt, c.language, c.Title, c.Body)}}, c.history...)
+	messages = append(messages, ChatMessage{Role: ChatRoleUser, Content: text})
+	answer, usage, err := c.chatter.Chat(ctx, messages)
+	c.Usage.PromptTokens += usage.PromptTokens
+	c.Usage.CompletionTokens += usage.CompletionTokens
+	if err != nil {
+		return nil, fmt.Errorf("failed to chat: %w", err)
+	}
+	c.history = append(c.history, ChatMessage{Role: ChatRoleUser, Content: text}, ChatMessage{Role: ChatRoleAssistant, Content: answer})
+
+	var reply ChatReply
+	if err := json.Unmarshal([]byte(cleanJSONResponse(answer)), &reply); err != nil || reply.Message == "" && !reply.Changed() {
+		return &ChatReply{Message: strings.TrimSpace(answer)}, nil
+	}
+	reply.Title, reply.Body = strings.TrimSpace(reply.Title), strings.TrimSpace(reply.Body)
+	if reply.Title != "" {
+		c.Title = reply.Title
+	}
+	if reply.Body != "" {
+		c.Body = reply.Body
+	}
+	return &reply, nil
+}
+
+// GroomMessage returns the message of an issue chat asking for a new version of the issue that
+// fixes the problems found when grooming the backlog.
+func GroomMessage(problems []string, format prompt.CriteriaFormat) string {
+	var sb strings.Builder
+	sb.WriteString("Rewrite this issue to fix the following problems, keeping what is already correct, the language of the issue and its hidden comments.\n")
+	sb.WriteString("Describe the need of the user and its value, and add the missing acceptance criteria in their own section, ")
+	sb.WriteString(strings.ToLower(format.Instruction()[:1]) + format.Instruction()[1:] + ".\n\nProblems:\n")
+	for _, p := range problems {
+		sb.WriteString("- " + p + "\n")
+	}
+	return sb.String()
+}
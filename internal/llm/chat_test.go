@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/leocomelli/aigile/internal/prompt"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// chatterFunc adapts a function to the Chatter interface.
+type chatterFunc func(ctx context.Context, messages []ChatMessage) (string, Usage, error)
+
+func (f chatterFunc) Chat(ctx context.Context, messages []ChatMessage) (string, Usage, error) {
+	return f(ctx, messages)
+}
+
+func TestIssueChat_Send(t *testing.T) {
+	var calls [][]ChatMessage
+	answers := []string{
+		"```json\n" + `{"message":"Added the refund criterion.","body":"## Acceptance Criteria\n- Refunds"}` + "\n```",
+		"The story is small enough.",
+	}
+	chat := NewIssueChat(chatterFunc(func(_ context.Context, messages []ChatMessage) (string, Usage, error) {
+		calls = append(calls, messages)
+		answer := answers[len(calls)-1]
+		return answer, Usage{PromptTokens: 100, CompletionTokens: 20}, nil
+	}), "Checkout", "## Acceptance Criteria\n- Pay", "")
+
+	reply, err := chat.Send(context.Background(), "add refunds")
+	require.NoError(t, err)
+	assert.Equal(t, "Added the refund criterion.", reply.Message)
+	assert.True(t, reply.Changed())
+	assert.Equal(t, "Checkout", chat.Title)
+	assert.Equal(t, "## Acceptance Criteria\n- Refunds", chat.Body)
+
+	reply, err = chat.Send(context.Background(), "should we split it?")
+	require.NoError(t, err)
+	assert.Equal(t, &ChatReply{Message: "The story is small enough."}, reply)
+	assert.Equal(t, Usage{PromptTokens: 200, CompletionTokens: 40}, chat.Usage)
+
+	// The second turn sends the updated draft and the history of the conversation
+	require.Len(t, calls[1], 4)
+	assert.Equal(t, fmt.Sprintf(issueChatPrompt, "english", "Checkout", "## Acceptance Criteria\n- Refunds"), calls[1][0].Content)
+	assert.Equal(t, []ChatMessage{
+		{Role: ChatRoleUser, Content: "add refunds"},
+		{Role: ChatRoleAssistant, Content: answers[0]},
+		{Role: ChatRoleUser, Content: "should we split it?"},
+	}, calls[1][1:])
+}
+
+func TestIssueChat_Send_Error(t *testing.T) {
+	chat := NewIssueChat(chatterFunc(func(context.Context, []ChatMessage) (string, Usage, error) {
+		return "", Usage{}, errors.New("rate limited")
+	}), "Checkout", "body", "english")
+	_, err := chat.Send(context.Background(), "hi")
+	assert.EqualError(t, err, "failed to chat: rate limited")
+	assert.Equal(t, "body", chat.Body)
+}
+
+func TestOpenAIProvider_Chat(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "ok"}}}}, nil
+		},
+	}}
+	answer, _, err := provider.Chat(context.Background(), []ChatMessage{{Role: ChatRoleSystem, Content: "s"}, {Role: ChatRoleUser, Content: "u"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", answer)
+	assert.Equal(t, []openai.ChatCompletionMessage{{Role: "system", Content: "s"}, {Role: "user", Content: "u"}}, messages)
+}
+
+func TestBedrockProvider_Chat(t *testing.T) {
+	var input *bedrockruntime.ConverseInput
+	provider := newTestBedrockProvider("anthropic.claude-3-5-sonnet-20240620-v1:0", &mockConverseClient{
+		converseFunc: func(_ context.Context, in *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			input = in
+			return converseText("ok"), nil
+		},
+	})
+	answer, _, err := provider.Chat(context.Background(), []ChatMessage{
+		{Role: ChatRoleSystem, Content: "s"},
+		{Role: ChatRoleUser, Content: "u1"},
+		{Role: ChatRoleAssistant, Content: "a1"},
+		{Role: ChatRoleUser, Content: "u2"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ok", answer)
+	assert.Equal(t, []types.SystemContentBlock{&types.SystemContentBlockMemberText{Value: "s"}}, input.System)
+	require.Len(t, input.Messages, 3)
+	assert.Equal(t, types.ConversationRoleAssistant, input.Messages[1].Role)
+}
+
+func TestMockProvider_Chat(t *testing.T) {
+	chat := NewIssueChat(NewMockProvider(), "Checkout", "## Acceptance Criteria\n- Pay", "english")
+	_, err := chat.Send(context.Background(), "Refunds")
+	require.NoError(t, err)
+	assert.Equal(t, "## Acceptance Criteria\n- Pay\n\n- Refunds", chat.Body)
+}
+
+func TestGroomMessage(t *testing.T) {
+	message := GroomMessage([]string{"there are no acceptance criteria"}, prompt.CriteriaBullets)
+	assert.Equal(t, "Rewrite this issue to fix the following problems, keeping what is already correct, the language of the issue and its hidden comments.\n"+
+		"Describe the need of the user and its value, and add the missing acceptance criteria in their own section, "+
+		"written as concise bullet points describing the expected behavior.\n\nProblems:\n- there are no acceptance criteria\n", message)
+}
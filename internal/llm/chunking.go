@@ -0,0 +1,98 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/tokens"
+)
+
+// Summarizer is implemented by providers that can condense free text, used to shrink contexts
+// that do not fit the model context window.
+type Summarizer interface {
+	Summarize(ctx context.Context, text string, language string) (string, Usage, error)
+}
+
+// maxReduceRounds bounds how many times summaries are summarized again before giving up on
+// fitting the budget.
+const maxReduceRounds = 3
+
+// summarizeSystemPrompt instructs the model to condense a piece of an item context.
+const summarizeSystemPrompt = `You condense product requirements for an agile team.
+Summarize the text you receive, keeping every requirement, business rule, constraint, actor, number and name.
+Drop repetition, greetings and unrelated discussion. Answer only with the summary, in plain text.`
+
+// summarizeMessage returns the user message asking for the summary of text.
+func summarizeMessage(text string, language string) string {
+	if language == "" {
+		return text
+	}
+	return fmt.Sprintf("Write the summary in %s.\n\n%s", language, text)
+}
+
+// ChunkingProvider wraps a provider to summarize contexts longer than a token budget before
+// generating content: the context is split into chunks, each chunk is summarized (map) and the
+// summaries are joined, and summarized again while they still exceed the budget (reduce).
+type ChunkingProvider struct {
+	provider   Provider
+	summarizer Summarizer
+	maxTokens  int
+}
+
+// NewChunkingProvider wraps a provider so that contexts estimated above maxTokens are summarized
+// with summarizer first.
+func NewChunkingProvider(provider Provider, summarizer Summarizer, maxTokens int) *ChunkingProvider {
+	return &ChunkingProvider{provider: provider, summarizer: summarizer, maxTokens: maxTokens}
+}
+
+// GenerateContent summarizes the request context when it exceeds the budget and generates the
+// content from the summary. The returned usage includes the summarization calls.
+func (p *ChunkingProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	estimate := tokens.Estimate(req.Context)
+	if estimate <= p.maxTokens {
+		return p.provider.GenerateContent(ctx, req)
+	}
+
+	slog.Info("summarizing long context", "row", req.ID, "tokens", estimate, "max", p.maxTokens)
+	summary, usage, err := p.reduce(ctx, req, req.Context)
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("context summarized", "row", req.ID, "tokens", tokens.Estimate(summary))
+
+	req.Context = summary
+	result, err := p.provider.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage.PromptTokens += usage.PromptTokens
+	result.Usage.CompletionTokens += usage.CompletionTokens
+	return result, nil
+}
+
+// reduce summarizes text chunk by chunk until it fits the budget.
+func (p *ChunkingProvider) reduce(ctx context.Context, req Request, text string) (string, Usage, error) {
+	var usage Usage
+	for round := 1; round <= maxReduceRounds; round++ {
+		chunks := tokens.Split(text, p.maxTokens)
+		summaries := make([]string, 0, len(chunks))
+		for i, chunk := range chunks {
+			summary, u, err := p.summarizer.Summarize(ctx, chunk, req.Language)
+			if err != nil {
+				return "", usage, fmt.Errorf("failed to summarize context chunk %d/%d: %w", i+1, len(chunks), err)
+			}
+			usage.PromptTokens += u.PromptTokens
+			usage.CompletionTokens += u.CompletionTokens
+			summaries = append(summaries, strings.TrimSpace(summary))
+		}
+		text = strings.Join(summaries, "\n\n")
+		if tokens.Estimate(text) <= p.maxTokens {
+			return text, usage, nil
+		}
+		slog.Debug("summary still above budget", "row", req.ID, "round", round, "tokens", tokens.Estimate(text))
+	}
+	slog.Warn("context still exceeds the token budget after summarizing", "row", req.ID, "tokens", tokens.Estimate(text), "max", p.maxTokens)
+	return text, usage, nil
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingProvider records the requests it receives.
+type recordingProvider struct {
+	requests []Request
+}
+
+func (r *recordingProvider) GenerateContent(_ context.Context, req Request) (*GeneratedContent, error) {
+	r.requests = append(r.requests, req)
+	return &GeneratedContent{Title: "title", Usage: Usage{PromptTokens: 100, CompletionTokens: 10}}, nil
+}
+
+// firstWordsSummarizer keeps the first words of every chunk it receives.
+type firstWordsSummarizer struct {
+	words  int
+	chunks []string
+	err    error
+}
+
+func (s *firstWordsSummarizer) Summarize(_ context.Context, text string, _ string) (string, Usage, error) {
+	if s.err != nil {
+		return "", Usage{}, s.err
+	}
+	s.chunks = append(s.chunks, text)
+	fields := strings.Fields(text)
+	if len(fields) > s.words {
+		fields = fields[:s.words]
+	}
+	return strings.Join(fields, " "), Usage{PromptTokens: 50, CompletionTokens: 5}, nil
+}
+
+func TestChunkingProvider_ShortContext(t *testing.T) {
+	recorder := &recordingProvider{}
+	summarizer := &firstWordsSummarizer{words: 2}
+	p := NewChunkingProvider(recorder, summarizer, 100)
+
+	result, err := p.GenerateContent(context.Background(), Request{Context: "Short context."})
+	require.NoError(t, err)
+	assert.Empty(t, summarizer.chunks)
+	assert.Equal(t, "Short context.", recorder.requests[0].Context)
+	assert.Equal(t, Usage{PromptTokens: 100, CompletionTokens: 10}, result.Usage)
+}
+
+func TestChunkingProvider_LongContext(t *testing.T) {
+	recorder := &recordingProvider{}
+	summarizer := &firstWordsSummarizer{words: 2}
+	p := NewChunkingProvider(recorder, summarizer, 30)
+
+	paragraphs := []string{
+		"Customers pay with credit cards and the payment must be authorized before shipping.",
+		"Refunds are issued to the original card within five business days of the request.",
+		"Invoices are emailed after every purchase and are available in the account page.",
+	}
+	result, err := p.GenerateContent(context.Background(), Request{ID: "2", Context: strings.Join(paragraphs, "\n\n")})
+	require.NoError(t, err)
+
+	assert.Len(t, summarizer.chunks, 3)
+	assert.Equal(t, "Customers pay\n\nRefunds are\n\nInvoices are", recorder.requests[0].Context)
+	assert.Equal(t, Usage{PromptTokens: 250, CompletionTokens: 25}, result.Usage)
+}
+
+func TestChunkingProvider_ReduceRounds(t *testing.T) {
+	recorder := &recordingProvider{}
+	// A summarizer that does not shrink the text stops after maxReduceRounds
+	summarizer := &firstWordsSummarizer{words: 1000}
+	p := NewChunkingProvider(recorder, summarizer, 5)
+
+	_, err := p.GenerateContent(context.Background(), Request{Context: strings.Repeat("word ", 20)})
+	require.NoError(t, err)
+	assert.Len(t, summarizer.chunks, 4*maxReduceRounds)
+	assert.Len(t, recorder.requests, 1)
+}
+
+func TestChunkingProvider_SummarizeError(t *testing.T) {
+	recorder := &recordingProvider{}
+	p := NewChunkingProvider(recorder, &firstWordsSummarizer{err: errors.New("rate limited")}, 5)
+
+	_, err := p.GenerateContent(context.Background(), Request{Context: strings.Repeat("word ", 20)})
+	assert.ErrorContains(t, err, "failed to summarize context chunk 1/4: rate limited")
+	assert.Empty(t, recorder.requests)
+}
+
+func TestMockProvider_Summarize(t *testing.T) {
+	summary, _, err := NewMockProvider().Summarize(context.Background(), "First sentence. Second one.\n\nOther paragraph. More.", "")
+	require.NoError(t, err)
+	assert.Equal(t, "First sentence. Other paragraph.", summary)
+}
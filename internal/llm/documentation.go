@@ -0,0 +1,15 @@
+package llm
+
+import "strings"
+
+// documentationPrompt introduces the excerpts of the product documentation added to the user
+// message when they are retrieved for an item.
+const documentationPrompt = "\n\nThe following excerpts of the product documentation are relevant to this item. Ground the description and the acceptance criteria in them, and do not contradict them:\n\n"
+
+// withDocumentation appends the documentation excerpts of a request to its user prompt.
+func withDocumentation(promptText string, documentation []string) string {
+	if len(documentation) == 0 {
+		return promptText
+	}
+	return promptText + documentationPrompt + strings.Join(documentation, "\n\n---\n\n")
+}
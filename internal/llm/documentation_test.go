@@ -0,0 +1,23 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPrompts_Documentation(t *testing.T) {
+	prompts := &mockPromptManager{getPromptFunc: func(prompt.ItemType, prompt.Data) (string, error) { return "Write the story.", nil }}
+
+	text, _, err := getPrompts(prompts, testRequest)
+	require.NoError(t, err)
+	assert.Equal(t, "Write the story.", text)
+
+	req := testRequest
+	req.Documentation = []string{"Payments (docs/payments.md):\nCards are charged on checkout.", "Refunds take 5 days."}
+	text, _, err = getPrompts(prompts, req)
+	require.NoError(t, err)
+	assert.Equal(t, "Write the story."+documentationPrompt+"Payments (docs/payments.md):\nCards are charged on checkout.\n\n---\n\nRefunds take 5 days.", text)
+}
@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// DefaultEmbeddingModel is the embedding model used when none is configured.
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// mockEmbeddingSize is the number of dimensions of the mock embeddings.
+const mockEmbeddingSize = 256
+
+// Embedder is implemented by providers that can turn texts into embedding vectors, to compare
+// them by meaning.
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float32, Usage, error)
+}
+
+// Embed returns the embeddings of the texts with the configured embedding model, in the order of
+// the texts. Any OpenAI-compatible server with an embeddings endpoint works, such as Ollama.
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, Usage, error) {
+	model := p.embeddingModel
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	resp, err := p.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{Input: texts, Model: openai.EmbeddingModel(model)})
+	if err != nil {
+		return nil, Usage{}, fmt.Errorf("failed to embed texts: %w", err)
+	}
+	usage := Usage{PromptTokens: resp.Usage.PromptTokens}
+	if len(resp.Data) != len(texts) {
+		return nil, usage, fmt.Errorf("model %s returned %d embeddings for %d texts", model, len(resp.Data), len(texts))
+	}
+	vectors := make([][]float32, len(texts))
+	for _, e := range resp.Data {
+		if e.Index < 0 || e.Index >= len(texts) {
+			return nil, usage, fmt.Errorf("model %s returned an embedding out of range: %d", model, e.Index)
+		}
+		vectors[e.Index] = e.Embedding
+	}
+	return vectors, usage, nil
+}
+
+// Embed returns bag-of-words vectors, where each word is hashed to a dimension, so texts sharing
+// words are similar and the duplicate checks can be exercised without a model.
+func (p *MockProvider) Embed(ctx context.Context, texts []string) ([][]float32, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vector := make([]float32, mockEmbeddingSize)
+		words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+		for _, w := range words {
+			h := fnv.New32a()
+			_, _ = h.Write([]byte(w))
+			vector[h.Sum32()%mockEmbeddingSize]++
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, Usage{}, nil
+}
+
+// normalize scales a vector to unit length, as the embeddings of the OpenAI models.
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
@@ -0,0 +1,43 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_Embed(t *testing.T) {
+	var request openai.EmbeddingRequestStrings
+	provider := &OpenAIProvider{embeddingModel: "nomic-embed-text", client: &mockOpenAIClient{
+		embedFunc: func(_ context.Context, req openai.EmbeddingRequestStrings) (openai.EmbeddingResponse, error) {
+			request = req
+			return openai.EmbeddingResponse{
+				Data:  []openai.Embedding{{Index: 1, Embedding: []float32{0, 1}}, {Index: 0, Embedding: []float32{1, 0}}},
+				Usage: openai.Usage{PromptTokens: 12},
+			}, nil
+		},
+	}}
+
+	vectors, usage, err := provider.Embed(context.Background(), []string{"Pay by card", "Refunds"})
+	require.NoError(t, err)
+	assert.Equal(t, [][]float32{{1, 0}, {0, 1}}, vectors)
+	assert.Equal(t, Usage{PromptTokens: 12}, usage)
+	assert.Equal(t, openai.EmbeddingModel("nomic-embed-text"), request.Model)
+	assert.Equal(t, []string{"Pay by card", "Refunds"}, request.Input)
+
+	provider.embeddingModel = ""
+	_, _, err = provider.Embed(context.Background(), []string{"Pay by card"})
+	assert.EqualError(t, err, "model text-embedding-3-small returned 2 embeddings for 1 texts")
+}
+
+func TestMockProvider_Embed(t *testing.T) {
+	vectors, _, err := NewMockProvider().Embed(context.Background(), []string{"Pay by card", "pay BY card!", "Monthly reports", ""})
+	require.NoError(t, err)
+	require.Len(t, vectors, 4)
+	assert.Equal(t, vectors[0], vectors[1])
+	assert.NotEqual(t, vectors[0], vectors[2])
+	assert.Len(t, vectors[3], mockEmbeddingSize)
+}
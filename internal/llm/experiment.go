@@ -0,0 +1,87 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Variant is a prompt variant of an experiment, with the provider chain that uses it.
+type Variant struct {
+	Name     string
+	Provider Provider
+}
+
+// VariantReport compares the generations of a variant in an experiment.
+type VariantReport struct {
+	Name      string
+	Generated int
+	Failed    int
+	MeanScore float64
+	MinScore  int
+	MaxScore  int
+}
+
+// ExperimentProvider alternates the requests between prompt variants, marks the content with the
+// variant that generated it and keeps the quality scores of each variant, so prompts can be
+// compared on the same batch.
+type ExperimentProvider struct {
+	variants []Variant
+	scorer   Scorer
+
+	mu      sync.Mutex
+	next    int
+	reports []VariantReport
+	total   []int
+}
+
+// NewExperimentProvider creates an experiment over at least two variants.
+func NewExperimentProvider(scorer Scorer, variants ...Variant) (*ExperimentProvider, error) {
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("an experiment needs at least two variants, got %d", len(variants))
+	}
+	p := &ExperimentProvider{variants: variants, scorer: scorer, reports: make([]VariantReport, len(variants)), total: make([]int, len(variants))}
+	for i, v := range variants {
+		p.reports[i].Name = v.Name
+	}
+	return p, nil
+}
+
+// GenerateContent generates the content with the next variant and sets its Variant.
+func (p *ExperimentProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	p.mu.Lock()
+	i := p.next
+	p.next = (p.next + 1) % len(p.variants)
+	p.mu.Unlock()
+
+	content, err := p.variants[i].Provider.GenerateContent(ctx, req)
+	if err != nil {
+		p.mu.Lock()
+		p.reports[i].Failed++
+		p.mu.Unlock()
+		return nil, fmt.Errorf("variant %s: %w", p.variants[i].Name, err)
+	}
+	content.Variant = p.variants[i].Name
+	score, _ := p.scorer.Score(req, content)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	r := &p.reports[i]
+	if r.Generated == 0 || score < r.MinScore {
+		r.MinScore = score
+	}
+	if score > r.MaxScore {
+		r.MaxScore = score
+	}
+	r.Generated++
+	p.total[i] += score
+	r.MeanScore = float64(p.total[i]) / float64(r.Generated)
+	return content, nil
+}
+
+// Report returns the comparison of the variants, in the order they were given.
+func (p *ExperimentProvider) Report() []VariantReport {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]VariantReport(nil), p.reports...)
+}
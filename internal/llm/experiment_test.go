@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExperimentProvider(t *testing.T) {
+	a := &sequenceProvider{}
+	b := &sequenceProvider{fail: map[int32]bool{2: true}}
+	p, err := NewExperimentProvider(titleScorer{}, Variant{Name: "A", Provider: a}, Variant{Name: "B", Provider: b})
+	require.NoError(t, err)
+
+	var variants []string
+	for range 4 {
+		content, err := p.GenerateContent(context.Background(), Request{})
+		if err != nil {
+			assert.ErrorContains(t, err, "variant B: rate limited")
+			continue
+		}
+		variants = append(variants, content.Variant)
+	}
+	assert.Equal(t, []string{"A", "B", "A"}, variants)
+	assert.Equal(t, int32(2), a.calls.Load())
+	assert.Equal(t, int32(2), b.calls.Load())
+
+	report := p.Report()
+	require.Len(t, report, 2)
+	assert.Equal(t, VariantReport{Name: "A", Generated: 2, MeanScore: 1.5, MinScore: 1, MaxScore: 2}, report[0])
+	assert.Equal(t, VariantReport{Name: "B", Generated: 1, Failed: 1, MeanScore: 1, MinScore: 1, MaxScore: 1}, report[1])
+
+	_, err = NewExperimentProvider(titleScorer{}, Variant{Name: "A", Provider: a})
+	assert.ErrorContains(t, err, "at least two variants")
+}
@@ -0,0 +1,222 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/leocomelli/aigile/internal/httpclient"
+)
+
+// maxImageBytes is the largest image attached to a prompt, the limit of the Bedrock Converse API
+// (OpenAI accepts larger ones).
+const maxImageBytes = 3_750_000
+
+// imagePrompt is added to the user message when images are attached to it.
+const imagePrompt = "\n\nThe attached images are the design mockups or screenshots of this item: the description and the acceptance criteria must reflect what they show."
+
+// figmaAPI is the base URL of the Figma REST API, used to render the frames of Figma links.
+const figmaAPI = "https://api.figma.com"
+
+// imageFormats maps the supported image media types to their format name.
+var imageFormats = map[string]string{
+	"image/png":  "png",
+	"image/jpeg": "jpeg",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+// visionModels lists whether the known model families accept images. More specific names come
+// first, since a model matches the first entry contained in its ID.
+var visionModels = []struct {
+	family string
+	vision bool
+}{
+	{"gpt-4o", true},
+	{"gpt-4.1", true},
+	{"gpt-4-turbo", true},
+	{"gpt-5", true},
+	{"o1-mini", false},
+	{"o1", true},
+	{"o3-mini", false},
+	{"o3", true},
+	{"o4-mini", true},
+	{"anthropic.claude-3", true},
+	{"anthropic.claude-sonnet-4", true},
+	{"anthropic.claude-opus-4", true},
+	{"amazon.nova-micro", false},
+	{"amazon.nova", true},
+	{"meta.llama3-2-11b", true},
+	{"meta.llama3-2-90b", true},
+	{"meta.llama4", true},
+	{"mistral.pixtral", true},
+}
+
+// SupportsImages reports whether a model accepts images in its prompts. Unknown models are
+// assumed not to.
+func SupportsImages(model string) bool {
+	model = strings.ToLower(model)
+	for _, m := range visionModels {
+		if strings.Contains(model, m.family) {
+			return m.vision
+		}
+	}
+	return false
+}
+
+// designImage is an image loaded to be attached to a prompt.
+type designImage struct {
+	mediaType string
+	data      []byte
+}
+
+// format returns the format name of the image, e.g. png.
+func (i designImage) format() string {
+	return imageFormats[i.mediaType]
+}
+
+// imageLoader loads the images of a request: URLs are downloaded, Figma links are rendered
+// through the Figma API and other references are read as local files.
+type imageLoader struct {
+	client     *http.Client
+	figmaAPI   string
+	figmaToken string
+	vision     bool   // whether the model accepts images
+	model      string // for the warning when it does not
+	ignored    sync.Once
+}
+
+// newImageLoader creates the loader of a provider, which attaches images only when the model
+// accepts them, as configured or known.
+func newImageLoader(config Config) *imageLoader {
+	vision := SupportsImages(config.Model)
+	if config.Vision != nil {
+		vision = *config.Vision
+	}
+	return &imageLoader{
+		client:     httpclient.New(httpclient.DefaultTimeout),
+		figmaAPI:   figmaAPI,
+		figmaToken: config.FigmaToken,
+		vision:     vision,
+		model:      config.Model,
+	}
+}
+
+// load returns the images referenced by a request. Images that cannot be loaded are skipped with
+// a warning, so a broken link does not fail the item.
+func (l *imageLoader) load(ctx context.Context, req Request) []designImage {
+	if l == nil || len(req.Images) == 0 {
+		return nil
+	}
+	if !l.vision {
+		l.ignored.Do(func() {
+			slog.Warn("the model does not accept images, the design references are ignored (set LLM_VISION=true to send them)", "model", l.model)
+		})
+		return nil
+	}
+	var images []designImage
+	for _, ref := range req.Images {
+		img, err := l.fetch(ctx, ref)
+		if err != nil {
+			slog.Warn("failed to load design image", "row", req.ID, "image", ref, "error", err)
+			continue
+		}
+		images = append(images, img)
+	}
+	return images
+}
+
+// fetch loads a single image.
+func (l *imageLoader) fetch(ctx context.Context, ref string) (designImage, error) {
+	var data []byte
+	var err error
+	switch u, perr := url.Parse(ref); {
+	case perr == nil && (u.Scheme == "http" || u.Scheme == "https") && strings.HasSuffix(u.Hostname(), "figma.com"):
+		var rendered string
+		if rendered, err = l.figmaImageURL(ctx, u); err == nil {
+			data, err = l.download(ctx, rendered, nil)
+		}
+	case perr == nil && (u.Scheme == "http" || u.Scheme == "https"):
+		data, err = l.download(ctx, ref, nil)
+	default:
+		data, err = readImageFile(ref)
+	}
+	if err != nil {
+		return designImage{}, err
+	}
+	if len(data) > maxImageBytes {
+		return designImage{}, fmt.Errorf("image is larger than %d bytes", maxImageBytes)
+	}
+	mediaType := http.DetectContentType(data)
+	if _, ok := imageFormats[mediaType]; !ok {
+		return designImage{}, fmt.Errorf("unsupported image type %s (expected png, jpeg, gif or webp)", mediaType)
+	}
+	return designImage{mediaType: mediaType, data: data}, nil
+}
+
+// readImageFile reads a local image, up to one byte over the size limit.
+func readImageFile(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+	return io.ReadAll(io.LimitReader(f, maxImageBytes+1))
+}
+
+// download fetches a URL, up to one byte over the image size limit.
+func (l *imageLoader) download(ctx context.Context, rawURL string, header http.Header) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download image: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download image: unexpected status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+}
+
+// figmaImageURL renders the frame of a Figma link (https://www.figma.com/design/<key>/...?node-id=1-2)
+// as a PNG and returns its URL.
+func (l *imageLoader) figmaImageURL(ctx context.Context, link *url.URL) (string, error) {
+	if l.figmaToken == "" {
+		return "", fmt.Errorf("FIGMA_TOKEN is required to render Figma links")
+	}
+	parts := strings.Split(strings.Trim(link.Path, "/"), "/")
+	nodeID := strings.ReplaceAll(link.Query().Get("node-id"), "-", ":")
+	if len(parts) < 2 || nodeID == "" {
+		return "", fmt.Errorf("the Figma link must point to a frame (file key and node-id)")
+	}
+
+	api := fmt.Sprintf("%s/v1/images/%s?format=png&ids=%s", l.figmaAPI, url.PathEscape(parts[1]), url.QueryEscape(nodeID))
+	body, err := l.download(ctx, api, http.Header{"X-Figma-Token": {l.figmaToken}})
+	if err != nil {
+		return "", fmt.Errorf("failed to render Figma frame: %w", err)
+	}
+	var result struct {
+		Err    string            `json:"err"`
+		Images map[string]string `json:"images"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", fmt.Errorf("failed to render Figma frame: %w", err)
+	}
+	if result.Err != "" || result.Images[nodeID] == "" {
+		return "", fmt.Errorf("failed to render Figma frame %s: %s", nodeID, result.Err)
+	}
+	return result.Images[nodeID], nil
+}
@@ -0,0 +1,144 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/leocomelli/aigile/internal/prompt"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG returns a 1x1 PNG image.
+func testPNG(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))))
+	return buf.Bytes()
+}
+
+// newTestImageLoader returns a loader of a vision model whose Figma API is server.
+func newTestImageLoader(server *httptest.Server) *imageLoader {
+	l := newImageLoader(Config{Model: "gpt-4o", FigmaToken: "figd_token"})
+	l.client, l.figmaAPI = server.Client(), server.URL
+	return l
+}
+
+func TestSupportsImages(t *testing.T) {
+	assert.True(t, SupportsImages("gpt-4o-mini"))
+	assert.True(t, SupportsImages("anthropic.claude-3-5-sonnet-20240620-v1:0"))
+	assert.True(t, SupportsImages("us.amazon.nova-pro-v1:0"))
+	assert.False(t, SupportsImages("amazon.nova-micro-v1:0"))
+	assert.False(t, SupportsImages("o1-mini"))
+	assert.False(t, SupportsImages("amazon.titan-text-express-v1"))
+	assert.False(t, SupportsImages("llama3"))
+
+	vision := true
+	assert.True(t, newImageLoader(Config{Model: "llava", Vision: &vision}).vision)
+}
+
+func TestImageLoader_Load(t *testing.T) {
+	pngData := testPNG(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mockup.png", "/render.png":
+			_, _ = w.Write(pngData)
+		case "/v1/images/AbC123":
+			assert.Equal(t, "figd_token", r.Header.Get("X-Figma-Token"))
+			assert.Equal(t, "12:34", r.URL.Query().Get("ids"))
+			_, _ = w.Write([]byte(`{"err":null,"images":{"12:34":"` + "http://" + r.Host + `/render.png"}}`))
+		case "/page.html":
+			_, _ = w.Write([]byte("<html></html>"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "screen.png")
+	require.NoError(t, os.WriteFile(file, pngData, 0o600))
+
+	loader := newTestImageLoader(server)
+	images := loader.load(context.Background(), Request{ID: "2", Images: []string{
+		server.URL + "/mockup.png",
+		"https://www.figma.com/design/AbC123/Checkout?node-id=12-34",
+		file,
+		server.URL + "/page.html",
+		server.URL + "/missing.png",
+		"https://www.figma.com/design/AbC123/Checkout",
+	}})
+	require.Len(t, images, 3)
+	for _, img := range images {
+		assert.Equal(t, "image/png", img.mediaType)
+		assert.Equal(t, "png", img.format())
+		assert.Equal(t, pngData, img.data)
+	}
+
+	loader.vision = false
+	assert.Empty(t, loader.load(context.Background(), Request{Images: []string{file}}))
+}
+
+func TestOpenAIProvider_GenerateContent_Images(t *testing.T) {
+	pngData := testPNG(t)
+	file := filepath.Join(t.TempDir(), "screen.png")
+	require.NoError(t, os.WriteFile(file, pngData, 0o600))
+
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{
+		model: "gpt-4o",
+		client: &mockOpenAIClient{createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{
+				Content: `{"title":"T","description":"D","type":"User Story","acceptance_criteria":["A"]}`,
+			}}}}, nil
+		}},
+		prompts: &mockPromptManager{getPromptFunc: func(prompt.ItemType, prompt.Data) (string, error) { return "prompt", nil }},
+		images:  newImageLoader(Config{Model: "gpt-4o"}),
+	}
+
+	req := testRequest
+	req.Images = []string{file}
+	_, err := provider.GenerateContent(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Empty(t, messages[1].Content)
+	assert.Equal(t, []openai.ChatMessagePart{
+		{Type: openai.ChatMessagePartTypeText, Text: "prompt" + imagePrompt},
+		{Type: openai.ChatMessagePartTypeImageURL, ImageURL: &openai.ChatMessageImageURL{URL: "data:image/png;base64," + base64.StdEncoding.EncodeToString(pngData)}},
+	}, messages[1].MultiContent)
+}
+
+func TestBedrockProvider_GenerateContent_Images(t *testing.T) {
+	pngData := testPNG(t)
+	file := filepath.Join(t.TempDir(), "screen.png")
+	require.NoError(t, os.WriteFile(file, pngData, 0o600))
+
+	var input *bedrockruntime.ConverseInput
+	model := "anthropic.claude-3-5-sonnet-20240620-v1:0"
+	provider := newTestBedrockProvider(model, &mockConverseClient{
+		converseFunc: func(_ context.Context, in *bedrockruntime.ConverseInput) (*bedrockruntime.ConverseOutput, error) {
+			input = in
+			return converseText(`{"title":"T","description":"D","type":"User Story","acceptance_criteria":["A"]}`), nil
+		},
+	})
+	provider.images = newImageLoader(Config{Model: model})
+
+	req := testRequest
+	req.Images = []string{file}
+	_, err := provider.GenerateContent(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, []types.ContentBlock{
+		&types.ContentBlockMemberText{Value: "prompt" + imagePrompt},
+		&types.ContentBlockMemberImage{Value: types.ImageBlock{Format: types.ImageFormatPng, Source: &types.ImageSourceMemberBytes{Value: pngData}}},
+	}, input.Messages[0].Content)
+}
@@ -2,12 +2,44 @@
 package llm
 
 import (
+	"context"
+	"fmt"
+
 	"github.com/leocomelli/aigile/internal/prompt"
 )
 
 // Provider defines the interface for Large Language Model providers used to generate content.
 type Provider interface {
-	GenerateContent(itemType prompt.ItemType, parent, context string, criteria []string, language string, generateTasks bool) (*GeneratedContent, error)
+	GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error)
+}
+
+// ModelLister is implemented by providers that can list the models available to the configured credentials.
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// Pinger is implemented by providers that can verify their credentials and model with a minimal request.
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// pingPrompt is the message sent by Ping implementations.
+const pingPrompt = "Reply with OK."
+
+// Request holds the input used to generate the content of a single item.
+type Request struct {
+	ID             string // Stable identifier of the source row
+	ItemType       prompt.ItemType
+	Parent         string
+	Context        string
+	Criteria       []string
+	Language       string
+	GenerateTasks  bool
+	TaskCount      int // Number of tasks to suggest, zero for as many as the LLM finds useful
+	CriteriaFormat prompt.CriteriaFormat
+	Sensitivity    string   // Data sensitivity of the row, see SensitivityInternalOnly
+	Images         []string // Design mockups or screenshots (URLs, Figma links or files), sent to vision-capable models
+	Documentation  []string // Excerpts of the product documentation relevant to the item, added to the prompt
 }
 
 // GeneratedContent represents the structured output returned by the LLM provider.
@@ -16,13 +48,67 @@ type GeneratedContent struct {
 	Description        string   `json:"description"`
 	AcceptanceCriteria []string `json:"acceptance_criteria"`
 	SuggestedTasks     []string `json:"suggested_tasks"`
+	Estimate           int      `json:"estimate,omitempty"` // Story points, zero when not estimated
 	Type               string   `json:"type"`
+	Usage              Usage    `json:"-"`
+	Variant            string   `json:"-"` // Prompt variant that generated the content, in experiments
+
+	Slices []GeneratedContent `json:"-"` // Vertical slices suggested for an oversized story, see Slicer
+}
+
+// Usage holds the number of tokens consumed by a generation.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
 }
 
 // Config holds the configuration parameters for the LLM provider.
 type Config struct {
-	Provider string
-	APIKey   string
-	Model    string
-	Endpoint string // For Azure OpenAI
+	Provider   string
+	APIKey     string
+	Model      string
+	Endpoint   string            // Base URL of an OpenAI-compatible API or an Azure OpenAI resource
+	Headers    map[string]string // Extra HTTP headers sent to the endpoint
+	Dir        string            // For the replay provider
+	PromptsDir string            // Directory with prompt files overriding the default prompts
+
+	ContextWindow         int  // Context window of the model in tokens, overriding the known value
+	FailOnOversizedPrompt bool // Return ErrPromptTooLarge instead of warning when a prompt does not fit
+	Local                 bool // The endpoint runs on premises and may receive internal-only rows
+
+	Vision     *bool  // Whether the model accepts images, overriding the known models
+	FigmaToken string // Personal access token used to render the Figma links of the Design column
+
+	EmbeddingModel string // Model of the embeddings, DefaultEmbeddingModel when empty
+}
+
+// NewProvider creates the LLM provider selected by config.Provider.
+func NewProvider(config Config) (Provider, error) {
+	switch config.Provider {
+	case "openai", "":
+		prompts, err := prompt.NewManagerFromDir(config.PromptsDir)
+		if err != nil {
+			return nil, err
+		}
+		p := NewOpenAIProvider(config)
+		p.prompts = prompts
+		return p, nil
+	case "mock":
+		return NewMockProvider(), nil
+	case "replay":
+		return NewReplayProvider(config.Dir)
+	case "bedrock":
+		prompts, err := prompt.NewManagerFromDir(config.PromptsDir)
+		if err != nil {
+			return nil, err
+		}
+		p, err := NewBedrockProvider(config)
+		if err != nil {
+			return nil, err
+		}
+		p.prompts = prompts
+		return p, nil
+	default:
+		return nil, fmt.Errorf("unsupported LLM provider: %s", config.Provider)
+	}
 }
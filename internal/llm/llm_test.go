@@ -0,0 +1,27 @@
+package llm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestNewProvider tests provider selection based on the configuration.
+func TestNewProvider(t *testing.T) {
+	p, err := NewProvider(Config{Provider: ""})
+	assert.NoError(t, err)
+	assert.IsType(t, &OpenAIProvider{}, p)
+
+	p, err = NewProvider(Config{Provider: "mock"})
+	assert.NoError(t, err)
+	assert.IsType(t, &MockProvider{}, p)
+
+	p, err = NewProvider(Config{Provider: "openai", PromptsDir: t.TempDir() + "/missing"})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+
+	p, err = NewProvider(Config{Provider: "unknown"})
+	assert.Error(t, err)
+	assert.Nil(t, p)
+	assert.Contains(t, err.Error(), "unsupported LLM provider")
+}
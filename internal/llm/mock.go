@@ -0,0 +1,341 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/release"
+	"github.com/leocomelli/aigile/internal/sprint"
+)
+
+// Templates used by the MockProvider to build deterministic content.
+const (
+	mockTitleTemplate       = "As a user, I want %s"
+	mockDescriptionTemplate = "As a user, I want %s so that the needs described for %s are met."
+	mockCriterionTemplate   = "Given the feature is available When %s Then the expected outcome is observed"
+	mockDefaultCriterion    = "the user performs the main flow"
+	mockSummaryMaxLength    = 60
+)
+
+// mockTaskTemplates are the task suggestions returned by the MockProvider when tasks are requested.
+var mockTaskTemplates = []string{
+	"Design the solution for %s",
+	"Implement %s",
+	"Write automated tests for %s",
+}
+
+// mockNeedPattern matches the sentences the MockProvider extracts from a transcript.
+var mockNeedPattern = regexp.MustCompile(`(?i)\b(need|needs|want|wants|should|must)\b`)
+
+// mockEstimates are the story points returned by the MockProvider, picked by the number of criteria.
+var mockEstimates = []int{1, 2, 3, 5, 8}
+
+// MockProvider implements the Provider interface returning canned content, so the whole
+// pipeline can be exercised without calling a real LLM.
+type MockProvider struct{}
+
+// NewMockProvider creates a new MockProvider.
+func NewMockProvider() *MockProvider {
+	return &MockProvider{}
+}
+
+// GenerateContent builds deterministic content from the item fields.
+func (p *MockProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	summary := summarizeContext(req.Context)
+	parent := req.Parent
+	if parent == "" {
+		parent = "the product"
+	}
+
+	result := &GeneratedContent{
+		Type:        req.ItemType.String(),
+		Title:       fmt.Sprintf(mockTitleTemplate, summary),
+		Description: fmt.Sprintf(mockDescriptionTemplate, summary, parent),
+	}
+
+	criteria := req.Criteria
+	if len(criteria) == 0 {
+		criteria = []string{mockDefaultCriterion}
+	}
+	for _, c := range criteria {
+		if req.CriteriaFormat == "" || req.CriteriaFormat == prompt.CriteriaGherkin {
+			c = fmt.Sprintf(mockCriterionTemplate, c)
+		}
+		result.AcceptanceCriteria = append(result.AcceptanceCriteria, c)
+	}
+
+	if req.ItemType != prompt.Epic {
+		result.Estimate = mockEstimates[min(len(criteria), len(mockEstimates))-1]
+	}
+
+	if req.GenerateTasks {
+		templates := mockTaskTemplates
+		if req.TaskCount > 0 && req.TaskCount < len(templates) {
+			templates = templates[:req.TaskCount]
+		}
+		for _, t := range templates {
+			result.SuggestedTasks = append(result.SuggestedTasks, fmt.Sprintf(t, summary))
+		}
+	}
+
+	return result, nil
+}
+
+// Refine returns a copy of the previous content, the mock has nothing to improve.
+func (p *MockProvider) Refine(ctx context.Context, _ Request, previous *GeneratedContent, _ []string) (*GeneratedContent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	refined := *previous
+	refined.Usage = Usage{}
+	return &refined, nil
+}
+
+// Summarize keeps the first sentence of every paragraph of text.
+func (p *MockProvider) Summarize(ctx context.Context, text string, _ string) (string, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return "", Usage{}, err
+	}
+	var sentences []string
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if i := strings.IndexAny(paragraph, ".\n"); i > 0 {
+			paragraph = paragraph[:i+1]
+		}
+		if paragraph != "" {
+			sentences = append(sentences, paragraph)
+		}
+	}
+	return strings.Join(sentences, " "), Usage{}, nil
+}
+
+// Plan proposes a backlog following the structure of a Markdown document: an epic per second
+// level heading (or first level, when there are none) and a story per list item below it.
+func (p *MockProvider) Plan(ctx context.Context, doc string, language string, format prompt.CriteriaFormat) (*Plan, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	level := "## "
+	if !strings.Contains("\n"+doc, "\n## ") {
+		level = "# "
+	}
+
+	plan := &Plan{}
+	for _, line := range strings.Split(doc, "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(line, level):
+			plan.Epics = append(plan.Epics, PlannedEpic{Title: strings.TrimSpace(strings.TrimPrefix(line, level))})
+		case strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* "):
+			if len(plan.Epics) == 0 {
+				plan.Epics = append(plan.Epics, PlannedEpic{Title: "the product"})
+			}
+			epic := &plan.Epics[len(plan.Epics)-1]
+			epic.Stories = append(epic.Stories, PlannedStory{Title: strings.TrimSpace(line[2:])})
+		}
+	}
+	if len(plan.Epics) == 0 {
+		plan.Epics = []PlannedEpic{{Title: summarizeContext(doc)}}
+	}
+
+	for i := range plan.Epics {
+		epic := &plan.Epics[i]
+		if len(epic.Stories) == 0 {
+			epic.Stories = []PlannedStory{{Title: epic.Title}}
+		}
+		content, _ := p.GenerateContent(ctx, Request{ItemType: prompt.Epic, Context: epic.Title, Language: language, CriteriaFormat: format})
+		epic.Title, epic.Description, epic.AcceptanceCriteria = content.Title, content.Description, content.AcceptanceCriteria
+		for j := range epic.Stories {
+			story := &epic.Stories[j]
+			content, _ := p.GenerateContent(ctx, Request{ItemType: prompt.UserStory, Parent: epic.Title, Context: story.Title, Language: language, GenerateTasks: true, CriteriaFormat: format})
+			story.Title, story.Description, story.AcceptanceCriteria = content.Title, content.Description, content.AcceptanceCriteria
+			story.Tasks, story.Estimate = content.SuggestedTasks, content.Estimate
+		}
+	}
+	return plan, Usage{}, nil
+}
+
+// ExtractStories turns every sentence of the transcript that states a need ("need", "want",
+// "should" or "must") into a candidate, under the speaker name when the line has one.
+func (p *MockProvider) ExtractStories(ctx context.Context, transcript string, _ string) ([]StoryCandidate, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	var candidates []StoryCandidate
+	for _, line := range strings.Split(transcript, "\n") {
+		speaker, text, ok := strings.Cut(strings.TrimSpace(line), ": ")
+		if !ok || strings.ContainsAny(speaker, ".!?") {
+			speaker, text = "", strings.TrimSpace(line)
+		}
+		for _, sentence := range strings.FieldsFunc(text, func(r rune) bool { return r == '.' || r == '!' || r == '?' }) {
+			sentence = strings.TrimSpace(sentence)
+			if !mockNeedPattern.MatchString(sentence) {
+				continue
+			}
+			parent := speaker
+			if parent == "" {
+				parent = "Meeting"
+			}
+			candidates = append(candidates, StoryCandidate{
+				Parent:   parent,
+				Context:  sentence,
+				Criteria: []string{mockDefaultCriterion},
+				Evidence: strings.TrimSpace(line),
+			})
+		}
+	}
+	return candidates, Usage{}, nil
+}
+
+// SplitStory splits the context into a story per paragraph, each criterion going to the paragraph
+// that shares the most words with it, the first one on ties.
+func (p *MockProvider) SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	var parts []StoryPart
+	for _, paragraph := range strings.Split(req.Context, "\n\n") {
+		if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+			parts = append(parts, StoryPart{Context: paragraph})
+		}
+	}
+	if len(parts) == 0 {
+		return []StoryPart{{Context: req.Context, Criteria: req.Criteria}}, Usage{}, nil
+	}
+	for _, criterion := range req.Criteria {
+		best, bestShared := 0, 0
+		for i, part := range parts {
+			if shared := sharedWords(part.Context, criterion); shared > bestShared {
+				best, bestShared = i, shared
+			}
+		}
+		parts[best].Criteria = append(parts[best].Criteria, criterion)
+	}
+	return parts, Usage{}, nil
+}
+
+// SliceStory proposes a slice per acceptance criterion of the story, estimated at one point.
+func (p *MockProvider) SliceStory(ctx context.Context, story *GeneratedContent, _ string) ([]GeneratedContent, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	slices := make([]GeneratedContent, len(story.AcceptanceCriteria))
+	for i, criterion := range story.AcceptanceCriteria {
+		slices[i] = GeneratedContent{
+			Title:              fmt.Sprintf("%s (slice %d)", story.Title, i+1),
+			Description:        fmt.Sprintf("Slice of %q delivering: %s", story.Title, criterion),
+			AcceptanceCriteria: []string{criterion},
+			Estimate:           mockEstimates[0],
+			Type:               story.Type,
+		}
+	}
+	return slices, Usage{}, nil
+}
+
+// sharedWords counts the words of b, of four letters or more, that are also in a.
+func sharedWords(a, b string) int {
+	words := map[string]bool{}
+	for _, w := range strings.Fields(strings.ToLower(a)) {
+		words[strings.Trim(w, ".,;:!?")] = true
+	}
+	n := 0
+	for _, w := range strings.Fields(strings.ToLower(b)) {
+		if w = strings.Trim(w, ".,;:!?"); len(w) >= 4 && words[w] {
+			n++
+		}
+	}
+	return n
+}
+
+// TranslateIssue marks the title with the target language and keeps the body, the mock does not
+// translate.
+func (p *MockProvider) TranslateIssue(ctx context.Context, title, body, language string) (*Translation, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	return &Translation{Title: fmt.Sprintf("[%s] %s", language, title), Body: body}, Usage{}, nil
+}
+
+// WriteReleaseNotes returns the plain draft of the notes, the mock does not rewrite them.
+func (p *MockProvider) WriteReleaseNotes(ctx context.Context, notes release.Notes, _ string) (string, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return "", Usage{}, err
+	}
+	return release.Markdown(notes), Usage{}, nil
+}
+
+// SummarizeSprint derives the goal from the titles of the committed items and summarizes the load.
+func (p *MockProvider) SummarizeSprint(ctx context.Context, plan sprint.Plan, _ string) (*SprintSummary, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	titles := make([]string, 0, len(plan.Committed))
+	for _, item := range plan.Committed {
+		titles = append(titles, item.Title)
+	}
+	goal := "Deliver " + strings.Join(titles, ", ")
+	if len(titles) == 0 {
+		goal = "Estimate the backlog"
+	}
+	summary := fmt.Sprintf("%d items committed for %s of %s points.", len(plan.Committed), sprint.Points(plan.Points()), sprint.Points(plan.Capacity))
+	return &SprintSummary{Goal: goal, Summary: summary}, Usage{}, nil
+}
+
+// Chat answers an issue chat by adding the last message of the user to the body of the issue, as
+// a note, so the whole refinement flow can be exercised.
+func (p *MockProvider) Chat(ctx context.Context, messages []ChatMessage) (string, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return "", Usage{}, err
+	}
+	var system, request string
+	for _, m := range messages {
+		switch m.Role {
+		case ChatRoleSystem:
+			system = m.Content
+		case ChatRoleUser:
+			request = m.Content
+		}
+	}
+	_, body, _ := strings.Cut(system, "Current issue body:\n")
+	answer, err := json.Marshal(ChatReply{
+		Message: "Added to the issue: " + request,
+		Body:    strings.TrimSpace(body + "\n\n- " + request),
+	})
+	return string(answer), Usage{}, err
+}
+
+// ListModels returns the single model served by the mock provider.
+func (p *MockProvider) ListModels(_ context.Context) ([]string, error) {
+	return []string{"mock"}, nil
+}
+
+// Ping always succeeds.
+func (p *MockProvider) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// summarizeContext returns the first sentence of the context, truncated to a readable length.
+func summarizeContext(itemContext string) string {
+	summary := strings.TrimSpace(itemContext)
+	if i := strings.IndexAny(summary, ".\n"); i > 0 {
+		summary = summary[:i]
+	}
+	if runes := []rune(summary); len(runes) > mockSummaryMaxLength {
+		summary = strings.TrimSpace(string(runes[:mockSummaryMaxLength])) + "..."
+	}
+	if summary == "" {
+		return "this feature"
+	}
+	first, size := utf8.DecodeRuneInString(summary)
+	return string(unicode.ToLower(first)) + summary[size:]
+}
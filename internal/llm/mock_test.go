@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMockProvider_GenerateContent tests that the mock provider returns valid, deterministic content.
+func TestMockProvider_GenerateContent(t *testing.T) {
+	provider := NewMockProvider()
+	result, err := provider.GenerateContent(context.Background(), Request{ItemType: prompt.UserStory, Parent: "Payments", Context: "Process credit card payments. More details.", Criteria: []string{"card is charged"}, Language: "english", GenerateTasks: true})
+	assert.NoError(t, err)
+	assert.NoError(t, validateGeneratedContent(result))
+	assert.Equal(t, "As a user, I want process credit card payments", result.Title)
+	assert.Equal(t, "User Story", result.Type)
+	assert.Contains(t, result.Description, "Payments")
+	assert.Equal(t, []string{"Given the feature is available When card is charged Then the expected outcome is observed"}, result.AcceptanceCriteria)
+	assert.Len(t, result.SuggestedTasks, 3)
+	assert.Equal(t, 1, result.Estimate)
+
+	again, err := provider.GenerateContent(context.Background(), Request{ItemType: prompt.UserStory, Parent: "Payments", Context: "Process credit card payments. More details.", Criteria: []string{"card is charged"}, Language: "english", GenerateTasks: true})
+	assert.NoError(t, err)
+	assert.Equal(t, result, again)
+}
+
+// TestMockProvider_GenerateContent_NoTasks tests that no tasks are returned when they are not requested.
+func TestMockProvider_GenerateContent_NoTasks(t *testing.T) {
+	result, err := NewMockProvider().GenerateContent(context.Background(), Request{ItemType: prompt.UserStory, Language: "english"})
+	assert.NoError(t, err)
+	assert.Equal(t, "As a user, I want this feature", result.Title)
+	assert.Len(t, result.AcceptanceCriteria, 1)
+	assert.Empty(t, result.SuggestedTasks)
+}
+
+// TestMockProvider_GenerateContent_TaskCount tests that the number of tasks requested is honored.
+func TestMockProvider_GenerateContent_TaskCount(t *testing.T) {
+	result, err := NewMockProvider().GenerateContent(context.Background(), Request{ItemType: prompt.UserStory, Context: "Checkout", GenerateTasks: true, TaskCount: 2})
+	assert.NoError(t, err)
+	assert.Len(t, result.SuggestedTasks, 2)
+}
+
+// TestMockProvider_GenerateContent_Canceled tests that a canceled context is honored.
+func TestMockProvider_GenerateContent_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := NewMockProvider().GenerateContent(ctx, Request{ItemType: prompt.UserStory, Context: "c", Language: "english"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, result)
+}
+
+// Test_summarizeContext tests the summarizeContext utility function.
+func Test_summarizeContext(t *testing.T) {
+	assert.Equal(t, "short", summarizeContext("Short"))
+	assert.Equal(t, "first sentence", summarizeContext("First sentence. Second one"))
+	assert.Equal(t, "édition de facture", summarizeContext("Édition de facture"))
+	long := summarizeContext("A very long context line that goes well beyond the sixty characters limit of the summary")
+	assert.True(t, len(long) <= mockSummaryMaxLength+3)
+}
+
+// TestMockProvider_GenerateContent_Checklist tests that non-Gherkin formats keep the criteria as given.
+func TestMockProvider_GenerateContent_Checklist(t *testing.T) {
+	result, err := NewMockProvider().GenerateContent(context.Background(), Request{ItemType: prompt.UserStory, Criteria: []string{"card is charged"}, CriteriaFormat: prompt.CriteriaChecklist})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"card is charged"}, result.AcceptanceCriteria)
+}
@@ -3,22 +3,32 @@ package llm
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
 	"strings"
 
+	"github.com/leocomelli/aigile/internal/httpclient"
 	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/release"
+	"github.com/leocomelli/aigile/internal/sprint"
 	"github.com/sashabaranov/go-openai"
 )
 
 // ChatClient is an interface for the OpenAI client, allowing mocking in tests.
 type ChatClient interface {
 	CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	ListModels(ctx context.Context) (openai.ModelsList, error)
+	CreateEmbeddings(ctx context.Context, conv openai.EmbeddingRequestConverter) (openai.EmbeddingResponse, error)
 }
 
 // PromptManager is an interface for managing prompts for LLMs.
 type PromptManager interface {
-	GetPrompt(itemType prompt.ItemType, parent, ctx string, criteria []string, language string, generateTasks bool) (string, error)
+	GetPrompt(itemType prompt.ItemType, data prompt.Data) (string, error)
+	GetSystemPrompt(itemType prompt.ItemType, data prompt.Data) (string, error)
 }
 
 // OpenAIProvider implements the Provider interface for OpenAI.
@@ -26,49 +36,335 @@ type OpenAIProvider struct {
 	client  ChatClient
 	model   string
 	prompts PromptManager
+	limit   promptLimit
+	images  *imageLoader
+
+	embeddingModel string
 }
 
-// NewOpenAIProvider creates a new OpenAIProvider with the given config.
+// NewOpenAIProvider creates a new OpenAIProvider with the given config. When an endpoint is set,
+// requests are sent to it instead of the OpenAI API, so any OpenAI-compatible server (OpenRouter,
+// vLLM, LM Studio, LiteLLM) can be used. Azure OpenAI endpoints are detected by their host.
 func NewOpenAIProvider(config Config) *OpenAIProvider {
-	client := openai.NewClient(config.APIKey)
+	clientConfig := openai.DefaultConfig(config.APIKey)
+	if config.Endpoint != "" {
+		if IsAzureEndpoint(config.Endpoint) {
+			clientConfig = openai.DefaultAzureConfig(config.APIKey, config.Endpoint)
+		} else {
+			clientConfig.BaseURL = strings.TrimSuffix(config.Endpoint, "/")
+		}
+	}
+	// Generations are bounded by the item timeout, not by the client
+	client := httpclient.New(0)
+	if len(config.Headers) > 0 {
+		client.Transport = &headerTransport{headers: config.Headers, base: client.Transport}
+	}
+	clientConfig.HTTPClient = client
+
 	return &OpenAIProvider{
-		client:  client,
+		client:  openai.NewClientWithConfig(clientConfig),
 		model:   config.Model,
 		prompts: prompt.NewManager(),
+		limit:   newPromptLimit(config),
+		images:  newImageLoader(config),
+
+		embeddingModel: config.EmbeddingModel,
 	}
 }
 
 // GenerateContent generates content using the OpenAI API based on the provided parameters.
-func (p *OpenAIProvider) GenerateContent(itemType prompt.ItemType, parent, ctx string, criteria []string, language string, generateTasks bool) (*GeneratedContent, error) {
+func (p *OpenAIProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	messages, err := p.messages(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return p.complete(ctx, req, messages)
+}
+
+// Refine continues the conversation of the request, asking the model to critique and improve its
+// previous answer.
+func (p *OpenAIProvider) Refine(ctx context.Context, req Request, previous *GeneratedContent, feedback []string) (*GeneratedContent, error) {
+	messages, err := p.messages(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	answer, err := json.Marshal(previous)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode previous answer: %w", err)
+	}
+	messages = append(messages,
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleAssistant, Content: string(answer)},
+		openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: refineMessage(feedback)},
+	)
+	return p.complete(ctx, req, messages)
+}
+
+// messages returns the system and user messages of a request, with the design images of the
+// item attached to the user message.
+func (p *OpenAIProvider) messages(ctx context.Context, req Request) ([]openai.ChatCompletionMessage, error) {
 	// Get the appropriate prompt for the item type
-	promptText, err := p.prompts.GetPrompt(itemType, parent, ctx, criteria, language, generateTasks)
+	promptText, systemText, err := getPrompts(p.prompts, req)
+	if err != nil {
+		return nil, err
+	}
+	user := openai.ChatCompletionMessage{Role: openai.ChatMessageRoleUser, Content: promptText}
+	if images := p.images.load(ctx, req); len(images) > 0 {
+		user.Content = ""
+		user.MultiContent = []openai.ChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: promptText + imagePrompt}}
+		for _, img := range images {
+			user.MultiContent = append(user.MultiContent, openai.ChatMessagePart{
+				Type:     openai.ChatMessagePartTypeImageURL,
+				ImageURL: &openai.ChatMessageImageURL{URL: "data:" + img.mediaType + ";base64," + base64.StdEncoding.EncodeToString(img.data)},
+			})
+		}
+	}
+	return []openai.ChatCompletionMessage{
+		{
+			Role:    openai.ChatMessageRoleSystem,
+			Content: systemText,
+		},
+		user,
+	}, nil
+}
+
+// complete checks the size of the conversation, sends it and parses the generated content from
+// the answer.
+func (p *OpenAIProvider) complete(ctx context.Context, req Request, messages []openai.ChatCompletionMessage) (*GeneratedContent, error) {
+	texts := make([]string, 0, len(messages))
+	for _, m := range messages {
+		texts = append(texts, m.Content)
+		for _, part := range m.MultiContent {
+			texts = append(texts, part.Text)
+		}
+	}
+	if err := p.limit.check(req, texts...); err != nil {
+		return nil, err
+	}
+
+	text, usage, err := p.chat(ctx, messages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate content: %w", err)
+	}
+
+	result, err := parseGeneratedContent(text)
+	if err != nil {
+		return nil, err
+	}
+	result.Usage = usage
+
+	return result, nil
+}
+
+// Summarize condenses text, used to shrink contexts that exceed the model context window.
+func (p *OpenAIProvider) Summarize(ctx context.Context, text string, language string) (string, Usage, error) {
+	summary, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: summarizeSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: summarizeMessage(text, language)},
+	})
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to summarize: %w", err)
+	}
+	return summary, usage, nil
+}
+
+// Plan proposes the backlog of a product document.
+func (p *OpenAIProvider) Plan(ctx context.Context, doc string, language string, format prompt.CriteriaFormat) (*Plan, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: planSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: planMessage(doc, language, format)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to plan the backlog: %w", err)
+	}
+	plan, err := parsePlan(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to plan the backlog: %w", err)
+	}
+	return plan, usage, nil
+}
+
+// SplitStory splits the context of a row into the independent User Stories it describes, a single
+// one when it describes only one.
+func (p *OpenAIProvider) SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: splitSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: splitMessage(req)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to split story: %w", err)
+	}
+	parts, err := parseParts(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to split story: %w", err)
+	}
+	return parts, usage, nil
+}
+
+// SliceStory proposes vertical slices of an oversized User Story.
+func (p *OpenAIProvider) SliceStory(ctx context.Context, story *GeneratedContent, language string) ([]GeneratedContent, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: sliceSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: sliceMessage(story, language)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to slice story: %w", err)
+	}
+	slices, err := parseSlices(text)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get prompt: %w", err)
+		return nil, usage, fmt.Errorf("failed to slice story: %w", err)
 	}
+	return slices, usage, nil
+}
+
+// ExtractStories lists the candidate User Stories discussed in a meeting transcript.
+func (p *OpenAIProvider) ExtractStories(ctx context.Context, transcript string, language string) ([]StoryCandidate, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: extractSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: extractMessage(transcript, language)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to extract stories: %w", err)
+	}
+	candidates, err := parseCandidates(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to extract stories: %w", err)
+	}
+	return candidates, usage, nil
+}
 
+// TranslateIssue translates the title and body of an issue, keeping the structure of the body.
+func (p *OpenAIProvider) TranslateIssue(ctx context.Context, title, body, language string) (*Translation, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: translateSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: translateMessage(title, body, language)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to translate issue: %w", err)
+	}
+	translation, err := parseTranslation(text, strings.TrimSpace(body) != "")
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to translate issue: %w", err)
+	}
+	return translation, usage, nil
+}
+
+// WriteReleaseNotes writes the release notes of the issues completed in a release.
+func (p *OpenAIProvider) WriteReleaseNotes(ctx context.Context, notes release.Notes, language string) (string, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: releaseNotesSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: releaseNotesMessage(notes, language)},
+	})
+	if err != nil {
+		return "", usage, fmt.Errorf("failed to write release notes: %w", err)
+	}
+	return cleanMarkdownResponse(text), usage, nil
+}
+
+// SummarizeSprint writes the goal and summary of a sprint plan.
+func (p *OpenAIProvider) SummarizeSprint(ctx context.Context, plan sprint.Plan, language string) (*SprintSummary, Usage, error) {
+	text, usage, err := p.chat(ctx, []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: sprintSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, Content: sprintMessage(plan, language)},
+	})
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to summarize sprint: %w", err)
+	}
+	summary, err := parseSprintSummary(text)
+	if err != nil {
+		return nil, usage, fmt.Errorf("failed to summarize sprint: %w", err)
+	}
+	return summary, usage, nil
+}
+
+// Chat sends a free-form conversation.
+func (p *OpenAIProvider) Chat(ctx context.Context, messages []ChatMessage) (string, Usage, error) {
+	conversation := make([]openai.ChatCompletionMessage, 0, len(messages))
+	for _, m := range messages {
+		conversation = append(conversation, openai.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+	}
+	return p.chat(ctx, conversation)
+}
+
+// chat sends the conversation and returns the text of the first choice.
+func (p *OpenAIProvider) chat(ctx context.Context, messages []openai.ChatCompletionMessage) (string, Usage, error) {
 	resp, err := p.client.CreateChatCompletion(
-		context.Background(),
+		ctx,
 		openai.ChatCompletionRequest{
-			Model: p.model,
-			Messages: []openai.ChatCompletionMessage{
-				{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: "You are an expert in agile methodologies and software development. Your task is to generate high-quality agile artifacts in JSON format.",
-				},
-				{
-					Role:    openai.ChatMessageRoleUser,
-					Content: promptText,
-				},
-			},
+			Model:    p.model,
+			Messages: messages,
 		},
 	)
+	if err != nil {
+		return "", Usage{}, err
+	}
 
+	usage := Usage{PromptTokens: resp.Usage.PromptTokens, CompletionTokens: resp.Usage.CompletionTokens}
+	if len(resp.Choices) == 0 {
+		return "", usage, fmt.Errorf("model %s returned no choices", p.model)
+	}
+	return resp.Choices[0].Message.Content, usage, nil
+}
+
+// ListModels returns the IDs of the models available at the endpoint.
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]string, error) {
+	list, err := p.client.ListModels(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate content: %w", err)
+		return nil, fmt.Errorf("failed to list models: %w", err)
+	}
+	models := make([]string, 0, len(list.Models))
+	for _, m := range list.Models {
+		models = append(models, m.ID)
+	}
+	sort.Strings(models)
+	return models, nil
+}
+
+// Ping sends a minimal completion to verify the API key and model.
+func (p *OpenAIProvider) Ping(ctx context.Context) error {
+	resp, err := p.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
+		Model:    p.model,
+		Messages: []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: pingPrompt}},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to ping model %s: %w", p.model, err)
+	}
+	if len(resp.Choices) == 0 {
+		return fmt.Errorf("model %s returned no choices", p.model)
+	}
+	return nil
+}
+
+// getPrompts returns the user and system prompts of a request.
+func getPrompts(prompts PromptManager, req Request) (string, string, error) {
+	data := promptData(req)
+	promptText, err := prompts.GetPrompt(req.ItemType, data)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get prompt: %w", err)
 	}
+	systemText, err := prompts.GetSystemPrompt(req.ItemType, data)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get system prompt: %w", err)
+	}
+	return withDocumentation(promptText, req.Documentation), systemText, nil
+}
 
+// promptData builds the prompt template data of a request.
+func promptData(req Request) prompt.Data {
+	return prompt.Data{
+		Parent:         req.Parent,
+		Context:        req.Context,
+		Criteria:       req.Criteria,
+		Language:       req.Language,
+		GenerateTasks:  req.GenerateTasks,
+		TaskCount:      req.TaskCount,
+		CriteriaFormat: req.CriteriaFormat,
+	}
+}
+
+// parseGeneratedContent extracts the JSON object from a model response and validates it.
+func parseGeneratedContent(text string) (*GeneratedContent, error) {
 	// Clean up the response to ensure it's valid JSON
-	content := cleanJSONResponse(resp.Choices[0].Message.Content)
+	content := cleanJSONResponse(text)
 
 	// Parse the JSON response
 	var result GeneratedContent
@@ -80,7 +376,6 @@ func (p *OpenAIProvider) GenerateContent(itemType prompt.ItemType, parent, ctx s
 	if err := validateGeneratedContent(&result); err != nil {
 		return nil, err
 	}
-
 	return &result, nil
 }
 
@@ -114,3 +409,24 @@ func validateGeneratedContent(content *GeneratedContent) error {
 	}
 	return nil
 }
+
+// IsAzureEndpoint reports whether the endpoint is an Azure OpenAI resource.
+func IsAzureEndpoint(endpoint string) bool {
+	u, err := url.Parse(endpoint)
+	return err == nil && strings.HasSuffix(u.Hostname(), ".openai.azure.com")
+}
+
+// headerTransport adds extra headers to every request.
+type headerTransport struct {
+	headers map[string]string
+	base    http.RoundTripper
+}
+
+// RoundTrip sets the extra headers on a copy of the request and sends it with the base transport.
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	return t.base.RoundTrip(req)
+}
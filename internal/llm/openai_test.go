@@ -3,19 +3,29 @@ package llm
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/leocomelli/aigile/internal/prompt"
 	"github.com/sashabaranov/go-openai"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
+// testRequest is the generation request shared by the OpenAI provider tests.
+var testRequest = Request{ID: "2", ItemType: prompt.UserStory, Parent: "p", Context: "c", Criteria: []string{"a"}, Language: "en", GenerateTasks: true}
+
 type mockPromptManager struct {
-	getPromptFunc func(prompt.ItemType, string, string, []string, string, bool) (string, error)
+	getPromptFunc func(prompt.ItemType, prompt.Data) (string, error)
+}
+
+func (m *mockPromptManager) GetPrompt(itemType prompt.ItemType, data prompt.Data) (string, error) {
+	return m.getPromptFunc(itemType, data)
 }
 
-func (m *mockPromptManager) GetPrompt(itemType prompt.ItemType, parent, ctx string, criteria []string, language string, generateTasks bool) (string, error) {
-	return m.getPromptFunc(itemType, parent, ctx, criteria, language, generateTasks)
+func (m *mockPromptManager) GetSystemPrompt(_ prompt.ItemType, _ prompt.Data) (string, error) {
+	return "system", nil
 }
 
 // TestNewOpenAIProvider tests the creation of a new OpenAIProvider instance.
@@ -25,50 +35,88 @@ func TestNewOpenAIProvider(t *testing.T) {
 	assert.Equal(t, "gpt", provider.model)
 }
 
+// TestNewOpenAIProvider_CompatibleEndpoint tests that requests are sent to a custom endpoint with the extra headers.
+func TestNewOpenAIProvider_CompatibleEndpoint(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		assert.Equal(t, "Bearer key", r.Header.Get("Authorization"))
+		assert.Equal(t, "https://example.com", r.Header.Get("HTTP-Referer"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"title\":\"T\",\"description\":\"D\",\"type\":\"User Story\",\"acceptance_criteria\":[\"A\"]}"}}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`))
+	}))
+	defer server.Close()
+
+	provider := NewOpenAIProvider(Config{APIKey: "key", Model: "local", Endpoint: server.URL + "/v1/", Headers: map[string]string{"HTTP-Referer": "https://example.com"}})
+	result, err := provider.GenerateContent(context.Background(), testRequest)
+	assert.NoError(t, err)
+	assert.Equal(t, "T", result.Title)
+	assert.Equal(t, Usage{PromptTokens: 3, CompletionTokens: 2}, result.Usage)
+}
+
+func TestIsAzureEndpoint(t *testing.T) {
+	assert.True(t, IsAzureEndpoint("https://my-resource.openai.azure.com/"))
+	assert.False(t, IsAzureEndpoint("https://openrouter.ai/api/v1"))
+	assert.False(t, IsAzureEndpoint("http://localhost:1234/v1"))
+}
+
 type mockOpenAIClient struct {
-	createFunc func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	createFunc     func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error)
+	listModelsFunc func(ctx context.Context) (openai.ModelsList, error)
+	embedFunc      func(ctx context.Context, req openai.EmbeddingRequestStrings) (openai.EmbeddingResponse, error)
 }
 
 func (m *mockOpenAIClient) CreateChatCompletion(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
 	return m.createFunc(ctx, req)
 }
 
+func (m *mockOpenAIClient) ListModels(ctx context.Context) (openai.ModelsList, error) {
+	return m.listModelsFunc(ctx)
+}
+
+func (m *mockOpenAIClient) CreateEmbeddings(ctx context.Context, conv openai.EmbeddingRequestConverter) (openai.EmbeddingResponse, error) {
+	return m.embedFunc(ctx, conv.(openai.EmbeddingRequestStrings))
+}
+
 func TestOpenAIProvider_GenerateContent_Success(t *testing.T) {
 	provider := &OpenAIProvider{
 		client: &mockOpenAIClient{
 			createFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+				assert.Equal(t, "system", req.Messages[0].Content)
+				assert.Equal(t, "prompt", req.Messages[1].Content)
 				return openai.ChatCompletionResponse{
 					Choices: []openai.ChatCompletionChoice{{
 						Message: openai.ChatCompletionMessage{
 							Content: `{"title":"T","description":"D","type":"User Story","acceptance_criteria":["A"],"suggested_tasks":["T1"]}`,
 						},
 					}},
+					Usage: openai.Usage{PromptTokens: 120, CompletionTokens: 80},
 				}, nil
 			},
 		},
 		model: "gpt",
-		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ string, _ string, _ []string, _ string, _ bool) (string, error) {
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
 			return "prompt", nil
 		}},
 	}
-	result, err := provider.GenerateContent(prompt.UserStory, "p", "c", []string{"a"}, "en", true)
+	result, err := provider.GenerateContent(context.Background(), testRequest)
 	assert.NoError(t, err)
 	assert.Equal(t, "T", result.Title)
 	assert.Equal(t, "D", result.Description)
 	assert.Equal(t, "User Story", result.Type)
 	assert.Equal(t, []string{"A"}, result.AcceptanceCriteria)
 	assert.Equal(t, []string{"T1"}, result.SuggestedTasks)
+	assert.Equal(t, Usage{PromptTokens: 120, CompletionTokens: 80}, result.Usage)
 }
 
 func TestOpenAIProvider_GenerateContent_PromptError(t *testing.T) {
 	provider := &OpenAIProvider{
 		client: &mockOpenAIClient{},
 		model:  "gpt",
-		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ string, _ string, _ []string, _ string, _ bool) (string, error) {
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
 			return "", errors.New("prompt error")
 		}},
 	}
-	result, err := provider.GenerateContent(prompt.UserStory, "p", "c", []string{"a"}, "en", true)
+	result, err := provider.GenerateContent(context.Background(), testRequest)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to get prompt")
@@ -83,11 +131,11 @@ func TestOpenAIProvider_GenerateContent_APIError(t *testing.T) {
 			},
 		},
 		model: "gpt",
-		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ string, _ string, _ []string, _ string, _ bool) (string, error) {
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
 			return "prompt", nil
 		}},
 	}
-	result, err := provider.GenerateContent(prompt.UserStory, "p", "c", []string{"a"}, "en", true)
+	result, err := provider.GenerateContent(context.Background(), testRequest)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to generate content")
@@ -106,11 +154,11 @@ func TestOpenAIProvider_GenerateContent_InvalidJSON(t *testing.T) {
 			},
 		},
 		model: "gpt",
-		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ string, _ string, _ []string, _ string, _ bool) (string, error) {
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
 			return "prompt", nil
 		}},
 	}
-	result, err := provider.GenerateContent(prompt.UserStory, "p", "c", []string{"a"}, "en", true)
+	result, err := provider.GenerateContent(context.Background(), testRequest)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "failed to parse JSON response")
@@ -129,11 +177,11 @@ func TestOpenAIProvider_GenerateContent_ValidationError(t *testing.T) {
 			},
 		},
 		model: "gpt",
-		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ string, _ string, _ []string, _ string, _ bool) (string, error) {
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
 			return "prompt", nil
 		}},
 	}
-	result, err := provider.GenerateContent(prompt.UserStory, "p", "c", []string{"a"}, "en", true)
+	result, err := provider.GenerateContent(context.Background(), testRequest)
 	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.Contains(t, err.Error(), "title is required")
@@ -167,3 +215,79 @@ func Test_validateGeneratedContent(t *testing.T) {
 	c.AcceptanceCriteria = nil
 	assert.Error(t, validateGeneratedContent(c))
 }
+
+// TestOpenAIProvider_GenerateContent_ContextCanceled tests that the request context is propagated to the API client.
+func TestOpenAIProvider_GenerateContent_ContextCanceled(t *testing.T) {
+	provider := &OpenAIProvider{
+		client: &mockOpenAIClient{
+			createFunc: func(ctx context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+				return openai.ChatCompletionResponse{}, ctx.Err()
+			},
+		},
+		model: "gpt",
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) {
+			return "prompt", nil
+		}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := provider.GenerateContent(ctx, testRequest)
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestOpenAIProvider_ListModels(t *testing.T) {
+	provider := &OpenAIProvider{client: &mockOpenAIClient{
+		listModelsFunc: func(context.Context) (openai.ModelsList, error) {
+			return openai.ModelsList{Models: []openai.Model{{ID: "gpt-4o"}, {ID: "gpt-4o-mini"}, {ID: "chatgpt-4o-latest"}}}, nil
+		},
+	}}
+	models, err := provider.ListModels(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"chatgpt-4o-latest", "gpt-4o", "gpt-4o-mini"}, models)
+
+	provider.client = &mockOpenAIClient{listModelsFunc: func(context.Context) (openai.ModelsList, error) {
+		return openai.ModelsList{}, errors.New("unauthorized")
+	}}
+	_, err = provider.ListModels(context.Background())
+	assert.ErrorContains(t, err, "failed to list models")
+}
+
+func TestOpenAIProvider_Ping(t *testing.T) {
+	var model string
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			model = req.Model
+			return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "OK"}}}}, nil
+		},
+	}}
+	assert.NoError(t, provider.Ping(context.Background()))
+	assert.Equal(t, "gpt-4o", model)
+
+	provider.client = &mockOpenAIClient{createFunc: func(context.Context, openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+		return openai.ChatCompletionResponse{}, errors.New("model not found")
+	}}
+	assert.ErrorContains(t, provider.Ping(context.Background()), "failed to ping model gpt-4o")
+}
+
+func TestOpenAIProvider_Summarize(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "Card payments with refunds."}}},
+				Usage:   openai.Usage{PromptTokens: 40, CompletionTokens: 6},
+			}, nil
+		},
+	}}
+
+	summary, usage, err := provider.Summarize(context.Background(), "A long context.", "portuguese")
+	require.NoError(t, err)
+	assert.Equal(t, "Card payments with refunds.", summary)
+	assert.Equal(t, Usage{PromptTokens: 40, CompletionTokens: 6}, usage)
+	require.Len(t, messages, 2)
+	assert.Equal(t, summarizeSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Write the summary in portuguese.\n\nA long context.", messages[1].Content)
+}
@@ -0,0 +1,129 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// Planner is implemented by providers that can propose a backlog for a product document.
+type Planner interface {
+	Plan(ctx context.Context, doc string, language string, format prompt.CriteriaFormat) (*Plan, Usage, error)
+}
+
+// Plan is the backlog proposed for a document: epics, their stories and the tasks of each story.
+type Plan struct {
+	Epics []PlannedEpic `json:"epics"`
+}
+
+// PlannedEpic is an epic of a plan.
+type PlannedEpic struct {
+	Title              string         `json:"title"`
+	Description        string         `json:"description"`
+	AcceptanceCriteria []string       `json:"acceptance_criteria"`
+	Stories            []PlannedStory `json:"stories"`
+}
+
+// PlannedStory is a User Story of a plan.
+type PlannedStory struct {
+	Title              string   `json:"title"`
+	Description        string   `json:"description"`
+	AcceptanceCriteria []string `json:"acceptance_criteria"`
+	Tasks              []string `json:"tasks"`
+	Estimate           int      `json:"estimate,omitempty"`
+}
+
+// Counts returns the number of epics, stories and tasks of the plan.
+func (p *Plan) Counts() (epics, stories, tasks int) {
+	for _, e := range p.Epics {
+		stories += len(e.Stories)
+		for _, s := range e.Stories {
+			tasks += len(s.Tasks)
+		}
+	}
+	return len(p.Epics), stories, tasks
+}
+
+// planSystemPrompt instructs the model to break a product document down into a backlog.
+const planSystemPrompt = `You are an Agile development expert who turns product requirements documents into backlogs.
+Read the document you receive and propose a tree of Epics, User Stories and implementation tasks that covers every requirement of the document, without inventing scope.
+Epics group the stories of a business capability. Stories are titled "As a [role], I want [goal]", described as "As a [persona], I want [feature] so that [benefit]" and estimated in story points (1, 2, 3, 5, 8, 13).
+Return only the following JSON structure, without explanations:
+{
+  "epics": [
+    {
+      "title": "[business capability]",
+      "description": "[problem, goal and business value]",
+      "acceptance_criteria": ["[criterion]"],
+      "stories": [
+        {
+          "title": "As a [role], I want [goal]",
+          "description": "As a [persona], I want [feature] so that [benefit]",
+          "acceptance_criteria": ["[criterion]"],
+          "tasks": ["[implementation task]"],
+          "estimate": 3
+        }
+      ]
+    }
+  ]
+}`
+
+// planMessage returns the user message asking for the plan of a document.
+func planMessage(doc string, language string, format prompt.CriteriaFormat) string {
+	if format == "" {
+		format = prompt.CriteriaGherkin
+	}
+	if language == "" {
+		language = "english"
+	}
+	return fmt.Sprintf("Output language: %s\nAcceptance criteria: %s\n\nDocument:\n%s", language, format.Instruction(), doc)
+}
+
+// parsePlan extracts the plan from a model response and validates it.
+func parsePlan(text string) (*Plan, error) {
+	var plan Plan
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if len(plan.Epics) == 0 {
+		return nil, fmt.Errorf("the plan has no epics")
+	}
+	for i, e := range plan.Epics {
+		if strings.TrimSpace(e.Title) == "" {
+			return nil, fmt.Errorf("epic %d has no title", i+1)
+		}
+		for j, s := range e.Stories {
+			if strings.TrimSpace(s.Title) == "" {
+				return nil, fmt.Errorf("story %d of epic %q has no title", j+1, e.Title)
+			}
+		}
+	}
+	return &plan, nil
+}
+
+// StaticProvider implements the Provider interface returning content generated beforehand, by
+// row ID, such as the items of a plan.
+type StaticProvider struct {
+	contents map[string]*GeneratedContent
+}
+
+// NewStaticProvider creates a provider returning the given contents by row ID.
+func NewStaticProvider(contents map[string]*GeneratedContent) *StaticProvider {
+	return &StaticProvider{contents: contents}
+}
+
+// GenerateContent returns a copy of the content of the request row ID.
+func (p *StaticProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	content, ok := p.contents[req.ID]
+	if !ok {
+		return nil, fmt.Errorf("no content for row %s", req.ID)
+	}
+	result := *content
+	return &result, nil
+}
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_Plan(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "```json\n" +
+					`{"epics":[{"title":"Checkout","description":"Faster checkout","acceptance_criteria":["Done"],"stories":[{"title":"As a buyer, I want to pay by card","tasks":["Integrate the gateway"],"estimate":3}]}]}` +
+					"\n```"}}},
+				Usage: openai.Usage{PromptTokens: 900, CompletionTokens: 200},
+			}, nil
+		},
+	}}
+
+	plan, usage, err := provider.Plan(context.Background(), "# Checkout", "portuguese", prompt.CriteriaChecklist)
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 900, CompletionTokens: 200}, usage)
+	require.Len(t, plan.Epics, 1)
+	assert.Equal(t, "Checkout", plan.Epics[0].Title)
+	assert.Equal(t, []PlannedStory{{Title: "As a buyer, I want to pay by card", Tasks: []string{"Integrate the gateway"}, Estimate: 3}}, plan.Epics[0].Stories)
+	require.Len(t, messages, 2)
+	assert.Equal(t, planSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: portuguese\nAcceptance criteria: "+prompt.CriteriaChecklist.Instruction()+"\n\nDocument:\n# Checkout", messages[1].Content)
+}
+
+func TestParsePlan_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"not json":                  "failed to parse JSON response",
+		`{"epics":[]}`:              "the plan has no epics",
+		`{"epics":[{"title":" "}]}`: "epic 1 has no title",
+		`{"epics":[{"title":"A","stories":[{}]}]}`: `story 1 of epic "A" has no title`,
+	}
+	for text, expected := range tests {
+		_, err := parsePlan(text)
+		assert.ErrorContains(t, err, expected)
+	}
+}
+
+func TestMockProvider_Plan(t *testing.T) {
+	doc := "# Shop\n\n## Checkout\n- Pay by card\n- Refunds\n\n## Reports\nMonthly sales.\n"
+	plan, _, err := NewMockProvider().Plan(context.Background(), doc, "english", prompt.CriteriaBullets)
+	require.NoError(t, err)
+	require.Len(t, plan.Epics, 2)
+	assert.Equal(t, "As a user, I want checkout", plan.Epics[0].Title)
+	require.Len(t, plan.Epics[0].Stories, 2)
+	assert.Equal(t, "As a user, I want refunds", plan.Epics[0].Stories[1].Title)
+	assert.Len(t, plan.Epics[0].Stories[1].Tasks, 3)
+	assert.Equal(t, "As a user, I want reports", plan.Epics[1].Stories[0].Title)
+
+	epics, stories, tasks := plan.Counts()
+	assert.Equal(t, []int{2, 3, 9}, []int{epics, stories, tasks})
+}
+
+func TestStaticProvider(t *testing.T) {
+	p := NewStaticProvider(map[string]*GeneratedContent{"1": {Title: "Checkout"}})
+	content, err := p.GenerateContent(context.Background(), Request{ID: "1"})
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout", content.Title)
+
+	_, err = p.GenerateContent(context.Background(), Request{ID: "2"})
+	assert.EqualError(t, err, "no content for row 2")
+}
@@ -0,0 +1,100 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/url"
+	"strings"
+)
+
+// SensitivityInternalOnly marks rows whose content must not leave the organization, so they
+// can only be sent to a local or on-prem LLM provider.
+const SensitivityInternalOnly = "internal-only"
+
+// ErrSensitiveContent is returned when an internal-only row would be sent to a cloud provider.
+var ErrSensitiveContent = errors.New("internal-only content cannot be sent to a cloud LLM provider")
+
+// IsLocal reports whether a provider configuration keeps the data on premises: the mock and
+// replay providers, configurations declared local, and OpenAI-compatible endpoints on loopback,
+// private network addresses or .local/.internal hosts.
+func IsLocal(config Config) bool {
+	switch config.Provider {
+	case "mock", "replay":
+		return true
+	}
+	if config.Local {
+		return true
+	}
+	if (config.Provider != "openai" && config.Provider != "") || config.Endpoint == "" {
+		return false
+	}
+
+	u, err := url.Parse(config.Endpoint)
+	if err != nil {
+		return false
+	}
+	host := u.Hostname()
+	if host == "localhost" || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && (ip.IsLoopback() || ip.IsPrivate())
+}
+
+// PolicyProvider routes requests by the sensitivity of their rows: internal-only rows go to the
+// local provider, or fail with ErrSensitiveContent when only a cloud provider is available.
+type PolicyProvider struct {
+	provider Provider
+	local    Provider
+}
+
+// NewPolicyProvider enforces the sensitivity policy over provider. When provider is local it
+// handles every row; otherwise internal-only rows are sent to local, which may be nil.
+func NewPolicyProvider(provider Provider, providerIsLocal bool, local Provider) *PolicyProvider {
+	if providerIsLocal {
+		local = provider
+	}
+	return &PolicyProvider{provider: provider, local: local}
+}
+
+// GenerateContent generates the content with the provider allowed for the request sensitivity.
+func (p *PolicyProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	if !strings.EqualFold(strings.TrimSpace(req.Sensitivity), SensitivityInternalOnly) {
+		return p.provider.GenerateContent(ctx, req)
+	}
+	if p.local == nil {
+		return nil, fmt.Errorf("row %s: %w", req.ID, ErrSensitiveContent)
+	}
+	slog.Debug("routing internal-only row to the local LLM provider", "row", req.ID)
+	return p.local.GenerateContent(ctx, req)
+}
+
+// NewProviderWithPolicy is the factory of the generation chain: it creates the provider of config
+// and, when localConfig is set, the local provider used for internal-only rows, applies wrap to
+// each of them (refinement, candidates, etc.) and enforces the sensitivity policy over both.
+func NewProviderWithPolicy(config Config, localConfig *Config, wrap func(Provider) (Provider, error)) (Provider, error) {
+	provider, err := NewProvider(config)
+	if err != nil {
+		return nil, err
+	}
+	if provider, err = wrap(provider); err != nil {
+		return nil, err
+	}
+
+	var local Provider
+	if localConfig != nil && !IsLocal(config) {
+		if !IsLocal(*localConfig) {
+			return nil, fmt.Errorf("the local LLM provider %s at %s is not on premises", localConfig.Provider, localConfig.Endpoint)
+		}
+		if local, err = NewProvider(*localConfig); err != nil {
+			return nil, fmt.Errorf("failed to create local LLM provider: %w", err)
+		}
+		if local, err = wrap(local); err != nil {
+			return nil, err
+		}
+	}
+	return NewPolicyProvider(provider, IsLocal(config), local), nil
+}
@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsLocal(t *testing.T) {
+	tests := []struct {
+		config   Config
+		expected bool
+	}{
+		{Config{Provider: "mock"}, true},
+		{Config{Provider: "replay"}, true},
+		{Config{Provider: "openai"}, false},
+		{Config{Provider: "openai", Endpoint: "https://openrouter.ai/api/v1"}, false},
+		{Config{Endpoint: "http://localhost:11434/v1"}, true},
+		{Config{Provider: "openai", Endpoint: "http://127.0.0.1:8000/v1"}, true},
+		{Config{Provider: "openai", Endpoint: "http://10.1.2.3/v1"}, true},
+		{Config{Provider: "openai", Endpoint: "https://llm.corp.internal/v1"}, true},
+		{Config{Provider: "bedrock", Endpoint: "http://localhost:4566"}, false},
+		{Config{Provider: "openai", Endpoint: "https://llm.example.com/v1", Local: true}, true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, IsLocal(tt.config), "%+v", tt.config)
+	}
+}
+
+func TestPolicyProvider(t *testing.T) {
+	cloud := &recordingProvider{}
+	local := &recordingProvider{}
+	p := NewPolicyProvider(cloud, false, local)
+
+	_, err := p.GenerateContent(context.Background(), Request{ID: "2"})
+	require.NoError(t, err)
+	_, err = p.GenerateContent(context.Background(), Request{ID: "3", Sensitivity: "Internal-Only"})
+	require.NoError(t, err)
+	assert.Len(t, cloud.requests, 1)
+	assert.Len(t, local.requests, 1)
+	assert.Equal(t, "3", local.requests[0].ID)
+
+	// Without a local provider internal-only rows fail
+	p = NewPolicyProvider(cloud, false, nil)
+	_, err = p.GenerateContent(context.Background(), Request{ID: "4", Sensitivity: SensitivityInternalOnly})
+	assert.ErrorIs(t, err, ErrSensitiveContent)
+	assert.ErrorContains(t, err, "row 4")
+
+	// A local provider handles every row
+	p = NewPolicyProvider(local, true, nil)
+	_, err = p.GenerateContent(context.Background(), Request{ID: "5", Sensitivity: SensitivityInternalOnly})
+	require.NoError(t, err)
+	assert.Len(t, local.requests, 2)
+}
+
+func TestNewProviderWithPolicy(t *testing.T) {
+	wrapped := 0
+	wrap := func(p Provider) (Provider, error) {
+		wrapped++
+		return p, nil
+	}
+
+	p, err := NewProviderWithPolicy(Config{Provider: "mock"}, nil, wrap)
+	require.NoError(t, err)
+	_, err = p.GenerateContent(context.Background(), Request{ID: "2", ItemType: "User Story", Sensitivity: SensitivityInternalOnly})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, wrapped)
+
+	_, err = NewProviderWithPolicy(Config{Provider: "openai", APIKey: "key"}, &Config{Provider: "openai", Endpoint: "https://api.example.com/v1"}, wrap)
+	assert.ErrorContains(t, err, "is not on premises")
+
+	wrapped = 0
+	p, err = NewProviderWithPolicy(Config{Provider: "openai", APIKey: "key"}, &Config{Provider: "mock"}, wrap)
+	require.NoError(t, err)
+	assert.Equal(t, 2, wrapped)
+	_, err = p.GenerateContent(context.Background(), Request{ID: "2", ItemType: "User Story", Sensitivity: SensitivityInternalOnly})
+	assert.NoError(t, err)
+}
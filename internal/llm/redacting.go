@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+)
+
+// Redactor masks sensitive data in a text and returns the number of matches per detector.
+type Redactor interface {
+	Redact(text string) (string, map[string]int)
+}
+
+// RedactingProvider wraps a provider to mask personal data and secrets in the request fields
+// before they are sent to the LLM.
+type RedactingProvider struct {
+	provider Provider
+	redactor Redactor
+}
+
+// NewRedactingProvider wraps a provider so that every request is redacted first.
+func NewRedactingProvider(provider Provider, redactor Redactor) *RedactingProvider {
+	return &RedactingProvider{provider: provider, redactor: redactor}
+}
+
+// GenerateContent redacts the request and generates the content.
+func (p *RedactingProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	return p.provider.GenerateContent(ctx, p.redactRequest(req))
+}
+
+// SplitStory redacts the request and splits it with the wrapped provider, which must be a Splitter.
+func (p *RedactingProvider) SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error) {
+	splitter, ok := p.provider.(Splitter)
+	if !ok {
+		return nil, Usage{}, fmt.Errorf("the LLM provider cannot split stories")
+	}
+	return splitter.SplitStory(ctx, p.redactRequest(req))
+}
+
+// redactRequest redacts the context, parent, criteria and documentation of the request and logs
+// what was found (detector names and counts, never the values).
+func (p *RedactingProvider) redactRequest(req Request) Request {
+	found := map[string]int{}
+	redact := func(text string) string {
+		redacted, matches := p.redactor.Redact(text)
+		for name, n := range matches {
+			found[name] += n
+		}
+		return redacted
+	}
+
+	req.Context = redact(req.Context)
+	req.Parent = redact(req.Parent)
+	if len(req.Criteria) > 0 {
+		criteria := make([]string, len(req.Criteria))
+		for i, criterion := range req.Criteria {
+			criteria[i] = redact(criterion)
+		}
+		req.Criteria = criteria
+	}
+	if len(req.Documentation) > 0 {
+		documentation := make([]string, len(req.Documentation))
+		for i, excerpt := range req.Documentation {
+			documentation[i] = redact(excerpt)
+		}
+		req.Documentation = documentation
+	}
+
+	if len(found) > 0 {
+		names := make([]string, 0, len(found))
+		for name := range found {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		attrs := []any{"row", req.ID}
+		for _, name := range names {
+			attrs = append(attrs, name, found[name])
+		}
+		slog.Info("sensitive data redacted from prompt", attrs...)
+	}
+	return req
+}
@@ -0,0 +1,41 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wordRedactor masks the word "secret".
+type wordRedactor struct{}
+
+func (wordRedactor) Redact(text string) (string, map[string]int) {
+	n := strings.Count(text, "secret")
+	if n == 0 {
+		return text, nil
+	}
+	return strings.ReplaceAll(text, "secret", "[REDACTED:word]"), map[string]int{"word": n}
+}
+
+func TestRedactingProvider(t *testing.T) {
+	recorder := &recordingProvider{}
+	p := NewRedactingProvider(recorder, wordRedactor{})
+
+	criteria := []string{"keep the secret", "plain"}
+	_, err := p.GenerateContent(context.Background(), Request{
+		ID:       "3",
+		Context:  "a secret and another secret",
+		Parent:   "parent secret",
+		Criteria: criteria,
+	})
+	require.NoError(t, err)
+
+	req := recorder.requests[0]
+	assert.Equal(t, "a [REDACTED:word] and another [REDACTED:word]", req.Context)
+	assert.Equal(t, "parent [REDACTED:word]", req.Parent)
+	assert.Equal(t, []string{"keep the [REDACTED:word]", "plain"}, req.Criteria)
+	assert.Equal(t, "keep the secret", criteria[0], "the caller's criteria are not modified")
+}
@@ -0,0 +1,89 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+)
+
+// Refiner is implemented by providers that can continue the conversation of a generation, asking
+// the model to critique and improve its previous answer.
+type Refiner interface {
+	Refine(ctx context.Context, req Request, previous *GeneratedContent, feedback []string) (*GeneratedContent, error)
+}
+
+// Scorer evaluates generated content, returning a score (higher is better) and the problems found.
+type Scorer interface {
+	Score(req Request, content *GeneratedContent) (int, []string)
+}
+
+// RefiningProvider wraps a provider and, after the initial generation, asks the model to critique
+// and improve its own output for a number of rounds, keeping the best-scoring result.
+type RefiningProvider struct {
+	provider Provider
+	refiner  Refiner
+	scorer   Scorer
+	rounds   int
+}
+
+// NewRefiningProvider wraps a provider that implements Refiner with up to rounds refinement rounds.
+func NewRefiningProvider(provider Provider, scorer Scorer, rounds int) (*RefiningProvider, error) {
+	refiner, ok := provider.(Refiner)
+	if !ok {
+		return nil, fmt.Errorf("LLM provider %T does not support refinement", provider)
+	}
+	return &RefiningProvider{provider: provider, refiner: refiner, scorer: scorer, rounds: rounds}, nil
+}
+
+// GenerateContent generates the content and refines it until the rounds are exhausted or the
+// scorer finds no problems. A failed round is logged and the best result so far is kept. The
+// returned usage is the sum of all rounds.
+func (p *RefiningProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	best, err := p.provider.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	usage := best.Usage
+	bestScore, issues := p.scorer.Score(req, best)
+	slog.Debug("initial generation scored", "row", req.ID, "score", bestScore, "issues", issues)
+
+	current := best
+	for round := 1; round <= p.rounds && len(issues) > 0; round++ {
+		refined, err := p.refiner.Refine(ctx, req, current, issues)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			slog.Warn("refinement round failed, keeping best result", "row", req.ID, "round", round, "error", err)
+			break
+		}
+		usage.PromptTokens += refined.Usage.PromptTokens
+		usage.CompletionTokens += refined.Usage.CompletionTokens
+
+		var score int
+		score, issues = p.scorer.Score(req, refined)
+		slog.Debug("refinement round scored", "row", req.ID, "round", round, "score", score, "issues", issues)
+		if score > bestScore {
+			best, bestScore = refined, score
+		}
+		current = refined
+	}
+
+	best.Usage = usage
+	return best, nil
+}
+
+// refineMessage returns the user message asking the model to critique and improve its answer.
+func refineMessage(feedback []string) string {
+	var b strings.Builder
+	b.WriteString("Critique your previous answer as a demanding Agile coach would: check that it is clear, complete, testable and faithful to the provided context. ")
+	if len(feedback) > 0 {
+		b.WriteString("An automated review also found these problems:\n")
+		for _, f := range feedback {
+			b.WriteString("- " + f + "\n")
+		}
+	}
+	b.WriteString("Then return an improved version that fixes every problem, strictly in the same JSON structure and language, without any explanation outside the JSON.")
+	return b.String()
+}
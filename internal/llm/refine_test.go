@@ -0,0 +1,115 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refiningStub returns a sequence of refined contents, one per round.
+type refiningStub struct {
+	stubProvider
+	refined  []*GeneratedContent
+	err      error
+	feedback [][]string
+}
+
+func (s *refiningStub) Refine(_ context.Context, _ Request, _ *GeneratedContent, feedback []string) (*GeneratedContent, error) {
+	s.feedback = append(s.feedback, feedback)
+	if s.err != nil {
+		return nil, s.err
+	}
+	next := s.refined[0]
+	s.refined = s.refined[1:]
+	return next, nil
+}
+
+// titleScorer scores content by the length of its title, reporting an issue until it reaches 5.
+type titleScorer struct{}
+
+func (titleScorer) Score(_ Request, content *GeneratedContent) (int, []string) {
+	if len(content.Title) >= 5 {
+		return len(content.Title), nil
+	}
+	return len(content.Title), []string{"title is too short"}
+}
+
+func TestNewRefiningProvider_Unsupported(t *testing.T) {
+	_, err := NewRefiningProvider(&stubProvider{}, titleScorer{}, 2)
+	assert.ErrorContains(t, err, "does not support refinement")
+}
+
+func TestRefiningProvider_KeepsBest(t *testing.T) {
+	stub := &refiningStub{
+		stubProvider: stubProvider{content: &GeneratedContent{Title: "ab", Usage: Usage{PromptTokens: 10, CompletionTokens: 5}}},
+		refined: []*GeneratedContent{
+			{Title: "abc", Usage: Usage{PromptTokens: 20, CompletionTokens: 5}},
+			{Title: "a", Usage: Usage{PromptTokens: 30, CompletionTokens: 5}},
+		},
+	}
+	p, err := NewRefiningProvider(stub, titleScorer{}, 2)
+	require.NoError(t, err)
+
+	result, err := p.GenerateContent(context.Background(), Request{ID: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", result.Title)
+	assert.Equal(t, Usage{PromptTokens: 60, CompletionTokens: 15}, result.Usage)
+	assert.Equal(t, [][]string{{"title is too short"}, {"title is too short"}}, stub.feedback)
+}
+
+func TestRefiningProvider_StopsWithoutIssues(t *testing.T) {
+	stub := &refiningStub{
+		stubProvider: stubProvider{content: &GeneratedContent{Title: "a"}},
+		refined:      []*GeneratedContent{{Title: "abcdef"}, {Title: "never"}},
+	}
+	p, err := NewRefiningProvider(stub, titleScorer{}, 3)
+	require.NoError(t, err)
+
+	result, err := p.GenerateContent(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "abcdef", result.Title)
+	assert.Len(t, stub.feedback, 1)
+}
+
+func TestRefiningProvider_FailedRound(t *testing.T) {
+	stub := &refiningStub{stubProvider: stubProvider{content: &GeneratedContent{Title: "a"}}, err: errors.New("rate limited")}
+	p, err := NewRefiningProvider(stub, titleScorer{}, 2)
+	require.NoError(t, err)
+
+	result, err := p.GenerateContent(context.Background(), Request{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", result.Title)
+
+	stub.stubProvider.err = errors.New("unavailable")
+	_, err = p.GenerateContent(context.Background(), Request{})
+	assert.ErrorContains(t, err, "unavailable")
+}
+
+func TestOpenAIProvider_Refine(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{
+		client: &mockOpenAIClient{
+			createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+				messages = req.Messages
+				return openai.ChatCompletionResponse{Choices: []openai.ChatCompletionChoice{{
+					Message: openai.ChatCompletionMessage{Content: `{"title":"Better","description":"D","type":"User Story","acceptance_criteria":["A"]}`},
+				}}}, nil
+			},
+		},
+		prompts: &mockPromptManager{getPromptFunc: func(_ prompt.ItemType, _ prompt.Data) (string, error) { return "prompt", nil }},
+	}
+
+	result, err := provider.Refine(context.Background(), testRequest, &GeneratedContent{Title: "Weak"}, []string{"the title is weak"})
+	require.NoError(t, err)
+	assert.Equal(t, "Better", result.Title)
+	require.Len(t, messages, 4)
+	assert.Equal(t, openai.ChatMessageRoleAssistant, messages[2].Role)
+	assert.Contains(t, messages[2].Content, `"title":"Weak"`)
+	assert.Equal(t, openai.ChatMessageRoleUser, messages[3].Role)
+	assert.Contains(t, messages[3].Content, "- the title is weak")
+}
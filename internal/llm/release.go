@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/release"
+)
+
+// ReleaseNoteWriter is implemented by providers that can write the release notes of a milestone.
+type ReleaseNoteWriter interface {
+	WriteReleaseNotes(ctx context.Context, notes release.Notes, language string) (string, Usage, error)
+}
+
+// releaseNotesSystemPrompt instructs the model to write release notes from the grouped issues.
+const releaseNotesSystemPrompt = `You are a product manager writing the release notes of a software product for its users.
+You receive the issues completed in the release, grouped in sections by type. Write polished release notes in Markdown:
+- Start with a "# <version>" heading and a short paragraph with the highlights of the release.
+- Keep one "##" section per group, with a name meaningful to the users (e.g. "New features" for the stories, "Bug fixes" for the bugs), in the order received.
+- Write one bullet per issue, describing the change from the point of view of the users, and end it with the issue reference, e.g. (#12).
+- Do not invent changes that are not in the issues and do not mention internal details such as tasks, labels or acceptance criteria.
+Return only the Markdown, without explanations or code fences.`
+
+// releaseNotesMessage returns the user message with the grouped issues of a release.
+func releaseNotesMessage(notes release.Notes, language string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Output language: %s\nVersion: %s\n", language, notes.Version)
+	for _, s := range notes.Sections {
+		fmt.Fprintf(&sb, "\nSection: %s\n", s.Name)
+		for _, issue := range s.Issues {
+			fmt.Fprintf(&sb, "- #%d %s\n", issue.Number, issue.Title)
+			if issue.Body != "" {
+				fmt.Fprintf(&sb, "  %s\n", strings.ReplaceAll(issue.Body, "\n", "\n  "))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// cleanMarkdownResponse removes the code fence a model may wrap the Markdown answer in.
+func cleanMarkdownResponse(text string) string {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "```") && strings.HasSuffix(text, "```") {
+		if i := strings.Index(text, "\n"); i >= 0 {
+			text = strings.TrimSpace(strings.TrimSuffix(text[i+1:], "```"))
+		}
+	}
+	return text + "\n"
+}
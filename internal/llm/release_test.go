@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/release"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_WriteReleaseNotes(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "```markdown\n# v1.2\n\n## New features\n\n- Pay by card (#1)\n```"}}},
+				Usage:   openai.Usage{PromptTokens: 500, CompletionTokens: 80},
+			}, nil
+		},
+	}}
+	notes := release.Notes{Version: "v1.2", Sections: []release.Section{
+		{Name: "User Story", Issues: []release.Issue{{Number: 1, Title: "As a buyer, I want to pay by card", Body: "Card payments.\nVisa only."}}},
+	}}
+
+	text, usage, err := provider.WriteReleaseNotes(context.Background(), notes, "english")
+	require.NoError(t, err)
+	assert.Equal(t, "# v1.2\n\n## New features\n\n- Pay by card (#1)\n", text)
+	assert.Equal(t, Usage{PromptTokens: 500, CompletionTokens: 80}, usage)
+	require.Len(t, messages, 2)
+	assert.Equal(t, releaseNotesSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: english\nVersion: v1.2\n\nSection: User Story\n- #1 As a buyer, I want to pay by card\n  Card payments.\n  Visa only.\n", messages[1].Content)
+}
+
+func TestMockProvider_WriteReleaseNotes(t *testing.T) {
+	notes := release.Notes{Version: "v1", Sections: []release.Section{{Name: "Bug", Issues: []release.Issue{{Number: 2, Title: "Fix totals"}}}}}
+	text, _, err := NewMockProvider().WriteReleaseNotes(context.Background(), notes, "english")
+	require.NoError(t, err)
+	assert.Equal(t, release.Markdown(notes), text)
+}
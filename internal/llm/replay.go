@@ -0,0 +1,141 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ReplayProvider implements the Provider interface reading pre-generated content from a directory.
+// Each generation is stored as <row id>.json, allowing a human to review and edit the content
+// between generation and publishing.
+type ReplayProvider struct {
+	dir string
+}
+
+// NewReplayProvider creates a new ReplayProvider reading generations from dir.
+func NewReplayProvider(dir string) (*ReplayProvider, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("replay directory is required")
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open replay directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("replay path is not a directory: %s", dir)
+	}
+	return &ReplayProvider{dir: dir}, nil
+}
+
+// GenerateContent loads the generation recorded for the request row ID.
+func (p *ReplayProvider) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	path, err := generationFile(p.dir, req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path) // #nosec G304 -- path is built from the replay dir and a validated row ID
+	if errors.Is(err, fs.ErrNotExist) {
+		if p.parts(req.ID) > 0 {
+			return nil, fmt.Errorf("row %s was recorded split into stories, replay it with --split", req.ID)
+		}
+		return nil, fmt.Errorf("no recorded generation for row %s in %s", req.ID, p.dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recorded generation: %w", err)
+	}
+
+	var result GeneratedContent
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse recorded generation %s: %w", path, err)
+	}
+	if err := validateGeneratedContent(&result); err != nil {
+		return nil, fmt.Errorf("invalid recorded generation %s: %w", path, err)
+	}
+	return &result, nil
+}
+
+// SplitStory replays the split of a row: a part per generation recorded for its stories, <row
+// id>-<n>.json, or the row itself when it was recorded as a single story. The parts keep the whole
+// context of the row, as their content is the recorded one.
+func (p *ReplayProvider) SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, Usage{}, err
+	}
+	n := p.parts(req.ID)
+	if n == 0 {
+		return []StoryPart{{Context: req.Context, Criteria: req.Criteria}}, Usage{}, nil
+	}
+	parts := make([]StoryPart, n)
+	for i := range parts {
+		parts[i] = StoryPart{Context: req.Context, Criteria: req.Criteria}
+	}
+	return parts, Usage{}, nil
+}
+
+// parts returns the number of stories recorded for a split row, numbered from 1.
+func (p *ReplayProvider) parts(id string) int {
+	n := 0
+	for {
+		path, err := generationFile(p.dir, fmt.Sprintf("%s-%d", id, n+1))
+		if err != nil {
+			return n
+		}
+		if _, err := os.Stat(path); err != nil {
+			return n
+		}
+		n++
+	}
+}
+
+// Recorder wraps a Provider and writes every generation to a directory, in the format read by
+// the ReplayProvider.
+type Recorder struct {
+	next Provider
+	dir  string
+}
+
+// NewRecorder creates a new Recorder writing the generations of next into dir.
+func NewRecorder(next Provider, dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create record directory: %w", err)
+	}
+	return &Recorder{next: next, dir: dir}, nil
+}
+
+// GenerateContent generates the content with the wrapped provider and records the result.
+func (r *Recorder) GenerateContent(ctx context.Context, req Request) (*GeneratedContent, error) {
+	result, err := r.next.GenerateContent(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	path, err := generationFile(r.dir, req.ID)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal generation: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return nil, fmt.Errorf("failed to record generation: %w", err)
+	}
+	return result, nil
+}
+
+// generationFile returns the path of the file holding the generation of the given row ID.
+func generationFile(dir, id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || id == "." || id == ".." {
+		return "", fmt.Errorf("invalid row id: %q", id)
+	}
+	return filepath.Join(dir, id+".json"), nil
+}
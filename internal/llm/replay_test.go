@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProvider struct {
+	content *GeneratedContent
+	err     error
+}
+
+func (s *stubProvider) GenerateContent(_ context.Context, _ Request) (*GeneratedContent, error) {
+	return s.content, s.err
+}
+
+// TestNewReplayProvider_InvalidDir tests error handling for missing replay directories.
+func TestNewReplayProvider_InvalidDir(t *testing.T) {
+	_, err := NewReplayProvider("")
+	assert.Error(t, err)
+
+	_, err = NewReplayProvider(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to open replay directory")
+}
+
+// TestRecorderAndReplay tests that recorded generations can be replayed.
+func TestRecorderAndReplay(t *testing.T) {
+	dir := t.TempDir()
+	content := &GeneratedContent{Title: "T", Description: "D", Type: "User Story", AcceptanceCriteria: []string{"A"}}
+
+	recorder, err := NewRecorder(&stubProvider{content: content}, dir)
+	require.NoError(t, err)
+	_, err = recorder.GenerateContent(context.Background(), Request{ID: "2", ItemType: prompt.UserStory})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "2.json"))
+
+	replay, err := NewReplayProvider(dir)
+	require.NoError(t, err)
+	got, err := replay.GenerateContent(context.Background(), Request{ID: "2"})
+	require.NoError(t, err)
+	assert.Equal(t, content, got)
+}
+
+// TestReplayProvider_Missing tests error handling when a row has no recorded generation.
+func TestReplayProvider_Missing(t *testing.T) {
+	replay, err := NewReplayProvider(t.TempDir())
+	require.NoError(t, err)
+	_, err = replay.GenerateContent(context.Background(), Request{ID: "3"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded generation for row 3")
+}
+
+// TestReplayProvider_Invalid tests validation of edited generations.
+func TestReplayProvider_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "2.json"), []byte(`{"title":""}`), 0o600))
+	replay, err := NewReplayProvider(dir)
+	require.NoError(t, err)
+	_, err = replay.GenerateContent(context.Background(), Request{ID: "2"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "title is required")
+
+	_, err = replay.GenerateContent(context.Background(), Request{ID: "../2"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid row id")
+}
+
+// TestRecorder_ProviderError tests that errors from the wrapped provider are returned unchanged.
+func TestRecorder_ProviderError(t *testing.T) {
+	recorder, err := NewRecorder(&stubProvider{err: errors.New("boom")}, t.TempDir())
+	require.NoError(t, err)
+	_, err = recorder.GenerateContent(context.Background(), Request{ID: "2"})
+	assert.EqualError(t, err, "boom")
+}
+
+// TestReplayProvider_SplitStory tests that the stories recorded for a split row are replayed.
+func TestReplayProvider_SplitStory(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(&stubProvider{content: &GeneratedContent{Title: "T", Description: "D", Type: "User Story"}}, dir)
+	require.NoError(t, err)
+	for _, id := range []string{"2-1", "2-2", "3"} {
+		_, err = recorder.GenerateContent(context.Background(), Request{ID: id, ItemType: prompt.UserStory})
+		require.NoError(t, err)
+	}
+
+	replay, err := NewReplayProvider(dir)
+	require.NoError(t, err)
+	parts, _, err := replay.SplitStory(context.Background(), Request{ID: "2", Context: "C"})
+	require.NoError(t, err)
+	assert.Equal(t, []StoryPart{{Context: "C"}, {Context: "C"}}, parts)
+	parts, _, err = replay.SplitStory(context.Background(), Request{ID: "3", Context: "C"})
+	require.NoError(t, err)
+	assert.Len(t, parts, 1)
+
+	_, err = replay.GenerateContent(context.Background(), Request{ID: "2"})
+	assert.ErrorContains(t, err, "replay it with --split")
+}
@@ -0,0 +1,60 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Slicer is implemented by providers that can propose vertical slices of an oversized User Story.
+type Slicer interface {
+	SliceStory(ctx context.Context, story *GeneratedContent, language string) ([]GeneratedContent, Usage, error)
+}
+
+// sliceSystemPrompt instructs the model to slice a story into smaller vertical stories.
+const sliceSystemPrompt = `You are an Agile development expert who helps teams split oversized User Stories.
+Read the User Story you receive and propose vertical slices of it: smaller stories that each deliver a thin, end-to-end piece of value to the user, through every layer needed, and can be released on their own.
+Do not slice by technical layer or activity (frontend, backend, tests), and together the slices must cover the whole story.
+Give each slice a title, a description, its acceptance criteria, taken from the story when they apply, and an estimate in story points using the Fibonacci scale (1, 2, 3, 5, 8).
+Return only the following JSON structure, without explanations:
+{
+  "slices": [
+    {
+      "title": "[title of the slice]",
+      "description": "[description of the slice]",
+      "acceptance_criteria": ["[acceptance criterion]"],
+      "estimate": 3
+    }
+  ]
+}`
+
+// sliceMessage returns the user message asking for the slices of a story.
+func sliceMessage(story *GeneratedContent, language string) string {
+	if language == "" {
+		language = "english"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Output language: %s\n\nTitle: %s\nEstimate: %d points\n\nDescription:\n%s\n\nAcceptance criteria:\n", language, story.Title, story.Estimate, story.Description)
+	for _, criterion := range story.AcceptanceCriteria {
+		fmt.Fprintf(&b, "- %s\n", criterion)
+	}
+	return b.String()
+}
+
+// parseSlices extracts the slices from a model response, dropping the ones without title.
+func parseSlices(text string) ([]GeneratedContent, error) {
+	var result struct {
+		Slices []GeneratedContent `json:"slices"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	slices := result.Slices[:0]
+	for _, s := range result.Slices {
+		if strings.TrimSpace(s.Title) != "" {
+			slices = append(slices, s)
+		}
+	}
+	return slices, nil
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_SliceStory(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"slices":[` +
+					`{"title":"Pay with a saved card","description":"One click payment","acceptance_criteria":["The card is charged once"],"estimate":3},` +
+					`{"title":" ","description":"No title"}]}`}}},
+				Usage: openai.Usage{PromptTokens: 400, CompletionTokens: 90},
+			}, nil
+		},
+	}}
+
+	story := &GeneratedContent{Title: "Checkout", Description: "Pay and get a receipt", AcceptanceCriteria: []string{"The card is charged once"}, Estimate: 13}
+	slices, usage, err := provider.SliceStory(context.Background(), story, "")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 400, CompletionTokens: 90}, usage)
+	assert.Equal(t, []GeneratedContent{{
+		Title:              "Pay with a saved card",
+		Description:        "One click payment",
+		AcceptanceCriteria: []string{"The card is charged once"},
+		Estimate:           3,
+	}}, slices)
+	require.Len(t, messages, 2)
+	assert.Equal(t, sliceSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: english\n\nTitle: Checkout\nEstimate: 13 points\n\nDescription:\nPay and get a receipt\n\n"+
+		"Acceptance criteria:\n- The card is charged once\n", messages[1].Content)
+}
+
+func TestParseSlices_Invalid(t *testing.T) {
+	_, err := parseSlices("no slices")
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+}
+
+func TestMockProvider_SliceStory(t *testing.T) {
+	story := &GeneratedContent{Title: "Checkout", AcceptanceCriteria: []string{"Pay", "Receipt"}, Type: "User Story"}
+	slices, _, err := NewMockProvider().SliceStory(context.Background(), story, "english")
+	require.NoError(t, err)
+	require.Len(t, slices, 2)
+	assert.Equal(t, "Checkout (slice 2)", slices[1].Title)
+	assert.Equal(t, []string{"Receipt"}, slices[1].AcceptanceCriteria)
+	assert.Equal(t, 1, slices[1].Estimate)
+}
@@ -0,0 +1,71 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Splitter is implemented by providers that can tell whether the context of a row describes
+// several independent User Stories and split it into them.
+type Splitter interface {
+	SplitStory(ctx context.Context, req Request) ([]StoryPart, Usage, error)
+}
+
+// StoryPart is one of the independent User Stories described by the context of a row, with the
+// acceptance criteria of the row that belong to it.
+type StoryPart struct {
+	Context  string   `json:"context"`
+	Criteria []string `json:"criteria"`
+}
+
+// splitSystemPrompt instructs the model to split the context of a row into independent stories.
+const splitSystemPrompt = `You are an Agile development expert who reviews backlog items before they are refined.
+Read the User Story you receive and decide whether its context describes several independent stories, each of them valuable and deliverable on its own.
+Do not split a story into technical steps, tasks or layers, and do not split stories whose parts only make sense together.
+When it describes several stories, give the context of each one, in the words of the original as much as possible, and assign each acceptance criterion to the story it belongs to, without changing it.
+When it describes a single story, return it as the only element, unchanged.
+Return only the following JSON structure, without explanations:
+{
+  "stories": [
+    {
+      "context": "[context of the story]",
+      "criteria": ["[acceptance criterion]"]
+    }
+  ]
+}`
+
+// splitMessage returns the user message asking for the stories of a row.
+func splitMessage(req Request) string {
+	language := req.Language
+	if language == "" {
+		language = "english"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Output language: %s\n\nParent: %s\n\nContext:\n%s\n\nAcceptance criteria:\n", language, req.Parent, req.Context)
+	for _, criterion := range req.Criteria {
+		fmt.Fprintf(&b, "- %s\n", criterion)
+	}
+	return b.String()
+}
+
+// parseParts extracts the stories from a model response, dropping the ones without context.
+func parseParts(text string) ([]StoryPart, error) {
+	var result struct {
+		Stories []StoryPart `json:"stories"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	parts := result.Stories[:0]
+	for _, p := range result.Stories {
+		if strings.TrimSpace(p.Context) != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("no stories in the response")
+	}
+	return parts, nil
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_SplitStory(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: "```json\n" + `{"stories":[` +
+					`{"context":"Pay with a saved card","criteria":["The card is charged once"]},` +
+					`{"context":"Email a receipt","criteria":["The receipt has the taxes"]},` +
+					`{"context":" "}]}` + "\n```"}}},
+				Usage: openai.Usage{PromptTokens: 300, CompletionTokens: 60},
+			}, nil
+		},
+	}}
+
+	parts, usage, err := provider.SplitStory(context.Background(), Request{
+		Parent:   "Checkout",
+		Context:  "Pay with a saved card and email a receipt",
+		Criteria: []string{"The card is charged once", "The receipt has the taxes"},
+		Language: "spanish",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 300, CompletionTokens: 60}, usage)
+	assert.Equal(t, []StoryPart{
+		{Context: "Pay with a saved card", Criteria: []string{"The card is charged once"}},
+		{Context: "Email a receipt", Criteria: []string{"The receipt has the taxes"}},
+	}, parts)
+	require.Len(t, messages, 2)
+	assert.Equal(t, splitSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: spanish\n\nParent: Checkout\n\nContext:\nPay with a saved card and email a receipt\n\n"+
+		"Acceptance criteria:\n- The card is charged once\n- The receipt has the taxes\n", messages[1].Content)
+}
+
+func TestParseParts_Invalid(t *testing.T) {
+	_, err := parseParts("no stories")
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+
+	_, err = parseParts(`{"stories":[]}`)
+	assert.ErrorContains(t, err, "no stories in the response")
+}
+
+func TestMockProvider_SplitStory(t *testing.T) {
+	parts, _, err := NewMockProvider().SplitStory(context.Background(), Request{
+		Context:  "Pay with a saved card\n\nEmail a receipt after the payment",
+		Criteria: []string{"The receipt has the taxes", "Nothing in common"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []StoryPart{
+		{Context: "Pay with a saved card", Criteria: []string{"Nothing in common"}},
+		{Context: "Email a receipt after the payment", Criteria: []string{"The receipt has the taxes"}},
+	}, parts)
+}
+
+func TestRedactingProvider_SplitStory(t *testing.T) {
+	p := NewRedactingProvider(NewMockProvider(), wordRedactor{})
+	parts, _, err := p.SplitStory(context.Background(), Request{Context: "a secret", Criteria: []string{"plain"}})
+	require.NoError(t, err)
+	assert.Equal(t, []StoryPart{{Context: "a [REDACTED:word]", Criteria: []string{"plain"}}}, parts)
+
+	_, _, err = NewRedactingProvider(&recordingProvider{}, wordRedactor{}).SplitStory(context.Background(), Request{})
+	assert.ErrorContains(t, err, "cannot split stories")
+}
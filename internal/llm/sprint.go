@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/sprint"
+)
+
+// SprintSummarizer is implemented by providers that can write the goal and summary of a sprint plan.
+type SprintSummarizer interface {
+	SummarizeSprint(ctx context.Context, plan sprint.Plan, language string) (*SprintSummary, Usage, error)
+}
+
+// SprintSummary is the goal and the summary of a sprint plan.
+type SprintSummary struct {
+	Goal    string `json:"goal"`
+	Summary string `json:"summary"`
+}
+
+// sprintSystemPrompt instructs the model to write the goal and summary of a sprint plan.
+const sprintSystemPrompt = `You are an experienced Scrum Master preparing the sprint planning of an agile team.
+You receive the capacity of the team and the items of the board: the ones committed to the sprint, the ones that do not fit and the ones without an estimate.
+Write:
+- goal: a single sentence with the sprint goal, the outcome the committed items deliver together, from the point of view of the users.
+- summary: one or two short paragraphs in Markdown explaining the plan: the focus of the sprint, the load against the capacity, the risks (such as a load close to the capacity or large items) and what is left for the next sprints, including the items that need an estimate.
+Only mention the items you receive, referencing them by number (#12).
+Return only the following JSON structure, without explanations:
+{
+  "goal": "[sprint goal]",
+  "summary": "[plan summary]"
+}`
+
+// sprintMessage returns the user message with the plan of a sprint.
+func sprintMessage(plan sprint.Plan, language string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Output language: %s\nSprint: %s\nCapacity: %s points\nCommitted: %s points\nEstimated backlog: %s points\n",
+		language, plan.Name, sprint.Points(plan.Capacity), sprint.Points(plan.Points()), sprint.Points(plan.TotalPoints()))
+	for _, group := range []struct {
+		name  string
+		items []sprint.Item
+	}{{"Committed", plan.Committed}, {"Not in this sprint", plan.Overflow}, {"Needs an estimate", plan.Unestimated}} {
+		if len(group.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&sb, "\n%s:\n", group.name)
+		for _, item := range group.items {
+			fmt.Fprintf(&sb, "- #%d %s", item.Number, item.Title)
+			if item.Points > 0 {
+				fmt.Fprintf(&sb, " (%s points)", sprint.Points(item.Points))
+			}
+			if item.Status != "" {
+				fmt.Fprintf(&sb, " [%s]", item.Status)
+			}
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// parseSprintSummary extracts the sprint summary from a model response.
+func parseSprintSummary(text string) (*SprintSummary, error) {
+	var s SprintSummary
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &s); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if strings.TrimSpace(s.Goal) == "" {
+		return nil, fmt.Errorf("the sprint summary has no goal")
+	}
+	return &s, nil
+}
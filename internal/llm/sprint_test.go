@@ -0,0 +1,48 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/sprint"
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_SummarizeSprint(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"goal":"Buyers pay by card","summary":"Checkout focus."}`}}},
+				Usage:   openai.Usage{PromptTokens: 400, CompletionTokens: 60},
+			}, nil
+		},
+	}}
+	plan := sprint.New("Sprint 7", []sprint.Item{{Number: 1, Title: "Pay by card", Status: "Todo", Points: 5}, {Number: 2, Title: "Refunds"}}, 10)
+
+	summary, usage, err := provider.SummarizeSprint(context.Background(), plan, "english")
+	require.NoError(t, err)
+	assert.Equal(t, &SprintSummary{Goal: "Buyers pay by card", Summary: "Checkout focus."}, summary)
+	assert.Equal(t, Usage{PromptTokens: 400, CompletionTokens: 60}, usage)
+	require.Len(t, messages, 2)
+	assert.Equal(t, sprintSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: english\nSprint: Sprint 7\nCapacity: 10 points\nCommitted: 5 points\nEstimated backlog: 5 points\n"+
+		"\nCommitted:\n- #1 Pay by card (5 points) [Todo]\n\nNeeds an estimate:\n- #2 Refunds\n", messages[1].Content)
+}
+
+func TestParseSprintSummary_Invalid(t *testing.T) {
+	_, err := parseSprintSummary("not json")
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+	_, err = parseSprintSummary(`{"goal":" ","summary":"s"}`)
+	assert.EqualError(t, err, "the sprint summary has no goal")
+}
+
+func TestMockProvider_SummarizeSprint(t *testing.T) {
+	plan := sprint.New("Sprint 7", []sprint.Item{{Number: 1, Title: "Pay by card", Points: 3}}, 10)
+	summary, _, err := NewMockProvider().SummarizeSprint(context.Background(), plan, "english")
+	require.NoError(t, err)
+	assert.Equal(t, &SprintSummary{Goal: "Deliver Pay by card", Summary: "1 items committed for 3 of 10 points."}, summary)
+}
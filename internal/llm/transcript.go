@@ -0,0 +1,66 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// StoryExtractor is implemented by providers that can extract candidate User Stories from a
+// meeting transcript or notes.
+type StoryExtractor interface {
+	ExtractStories(ctx context.Context, transcript string, language string) ([]StoryCandidate, Usage, error)
+}
+
+// StoryCandidate is a User Story discussed in a meeting, in the shape of an input row so it can be
+// reviewed in a spreadsheet before generating the issue.
+type StoryCandidate struct {
+	Parent   string   `json:"parent"`
+	Context  string   `json:"context"`
+	Criteria []string `json:"criteria"`
+	Evidence string   `json:"evidence"`
+}
+
+// extractSystemPrompt instructs the model to extract candidate stories from a transcript.
+const extractSystemPrompt = `You are an Agile development expert who turns meeting transcripts and notes into backlog candidates.
+Read the transcript you receive and list the features, changes and problems the participants agreed to work on, one candidate User Story each.
+Ignore small talk, status updates and ideas that were rejected, and do not invent requirements that were not discussed.
+For each candidate give the feature or area it belongs to, the context of what is needed and why, as discussed, the acceptance criteria mentioned in the meeting and a short quote of the transcript supporting it.
+Return only the following JSON structure, without explanations:
+{
+  "stories": [
+    {
+      "parent": "[feature or area]",
+      "context": "[what is needed and why]",
+      "criteria": ["[criterion]"],
+      "evidence": "[quote of the transcript]"
+    }
+  ]
+}`
+
+// extractMessage returns the user message asking for the candidates of a transcript.
+func extractMessage(transcript string, language string) string {
+	if language == "" {
+		language = "english"
+	}
+	return fmt.Sprintf("Output language: %s\n\nTranscript:\n%s", language, transcript)
+}
+
+// parseCandidates extracts the candidates from a model response, dropping the ones without
+// context.
+func parseCandidates(text string) ([]StoryCandidate, error) {
+	var result struct {
+		Stories []StoryCandidate `json:"stories"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	candidates := result.Stories[:0]
+	for _, c := range result.Stories {
+		if strings.TrimSpace(c.Context) != "" {
+			candidates = append(candidates, c)
+		}
+	}
+	return candidates, nil
+}
@@ -0,0 +1,53 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_ExtractStories(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"stories":[` +
+					`{"parent":"Checkout","context":"Refunds by card","criteria":["Partial refunds"],"evidence":"Ana: we need refunds"},` +
+					`{"parent":"Checkout","context":" "}]}`}}},
+				Usage: openai.Usage{PromptTokens: 500, CompletionTokens: 80},
+			}, nil
+		},
+	}}
+
+	candidates, usage, err := provider.ExtractStories(context.Background(), "Ana: we need refunds", "spanish")
+	require.NoError(t, err)
+	assert.Equal(t, Usage{PromptTokens: 500, CompletionTokens: 80}, usage)
+	assert.Equal(t, []StoryCandidate{{Parent: "Checkout", Context: "Refunds by card", Criteria: []string{"Partial refunds"}, Evidence: "Ana: we need refunds"}}, candidates)
+	require.Len(t, messages, 2)
+	assert.Equal(t, extractSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Output language: spanish\n\nTranscript:\nAna: we need refunds", messages[1].Content)
+}
+
+func TestParseCandidates_Invalid(t *testing.T) {
+	_, err := parseCandidates("no stories")
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+}
+
+func TestMockProvider_ExtractStories(t *testing.T) {
+	transcript := "Ana: Good morning. We need refunds in the checkout!\nBruno: Agreed.\nThe report must include taxes.\n"
+	candidates, _, err := NewMockProvider().ExtractStories(context.Background(), transcript, "english")
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, StoryCandidate{
+		Parent:   "Ana",
+		Context:  "We need refunds in the checkout",
+		Criteria: []string{mockDefaultCriterion},
+		Evidence: "Ana: Good morning. We need refunds in the checkout!",
+	}, candidates[0])
+	assert.Equal(t, "Meeting", candidates[1].Parent)
+	assert.Equal(t, "The report must include taxes", candidates[1].Context)
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Translator is implemented by providers that can translate existing issues.
+type Translator interface {
+	TranslateIssue(ctx context.Context, title, body, language string) (*Translation, Usage, error)
+}
+
+// Translation is the translated title and body of an issue.
+type Translation struct {
+	Title string `json:"title"`
+	Body  string `json:"body"`
+}
+
+// translateSystemPrompt instructs the model to translate an issue keeping its structure.
+const translateSystemPrompt = `You are a technical translator of software backlogs.
+Translate the title and the body of the issue you receive to the requested language, keeping the meaning and the tone.
+Keep the structure of the body exactly as it is: Markdown headings, lists, task list checkboxes, tables, links, issue references (#123), mentions (@user), code blocks and inline code, HTML comments and Gherkin keywords alignment. Do not translate code, URLs, identifiers, labels or the content of HTML comments.
+Return only the following JSON structure, without explanations:
+{
+  "title": "[translated title]",
+  "body": "[translated body]"
+}`
+
+// translateMessage returns the user message asking for the translation of an issue.
+func translateMessage(title, body, language string) string {
+	return fmt.Sprintf("Target language: %s\n\nTitle:\n%s\n\nBody:\n%s", language, title, body)
+}
+
+// parseTranslation extracts the translation from a model response. A body is required only when
+// the issue has one.
+func parseTranslation(text string, hasBody bool) (*Translation, error) {
+	var t Translation
+	if err := json.Unmarshal([]byte(cleanJSONResponse(text)), &t); err != nil {
+		return nil, fmt.Errorf("failed to parse JSON response: %w", err)
+	}
+	if strings.TrimSpace(t.Title) == "" {
+		return nil, fmt.Errorf("the translation has no title")
+	}
+	if hasBody && strings.TrimSpace(t.Body) == "" {
+		return nil, fmt.Errorf("the translation has no body")
+	}
+	return &t, nil
+}
@@ -0,0 +1,44 @@
+package llm
+
+import (
+	"context"
+	"testing"
+
+	openai "github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAIProvider_TranslateIssue(t *testing.T) {
+	var messages []openai.ChatCompletionMessage
+	provider := &OpenAIProvider{model: "gpt-4o", client: &mockOpenAIClient{
+		createFunc: func(_ context.Context, req openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			messages = req.Messages
+			return openai.ChatCompletionResponse{
+				Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: `{"title":"Pay by card","body":"## Acceptance Criteria\n- [ ] Visa"}`}}},
+				Usage:   openai.Usage{PromptTokens: 300, CompletionTokens: 40},
+			}, nil
+		},
+	}}
+
+	translation, usage, err := provider.TranslateIssue(context.Background(), "Pagar con tarjeta", "## Criterios\n- [ ] Visa", "english")
+	require.NoError(t, err)
+	assert.Equal(t, &Translation{Title: "Pay by card", Body: "## Acceptance Criteria\n- [ ] Visa"}, translation)
+	assert.Equal(t, Usage{PromptTokens: 300, CompletionTokens: 40}, usage)
+	require.Len(t, messages, 2)
+	assert.Equal(t, translateSystemPrompt, messages[0].Content)
+	assert.Equal(t, "Target language: english\n\nTitle:\nPagar con tarjeta\n\nBody:\n## Criterios\n- [ ] Visa", messages[1].Content)
+}
+
+func TestParseTranslation_Invalid(t *testing.T) {
+	_, err := parseTranslation("not json", false)
+	assert.ErrorContains(t, err, "failed to parse JSON response")
+	_, err = parseTranslation(`{"title":" ","body":"b"}`, true)
+	assert.EqualError(t, err, "the translation has no title")
+	_, err = parseTranslation(`{"title":"t"}`, true)
+	assert.EqualError(t, err, "the translation has no body")
+
+	translation, err := parseTranslation(`{"title":"t"}`, false)
+	require.NoError(t, err)
+	assert.Equal(t, "t", translation.Title)
+}
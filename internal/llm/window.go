@@ -0,0 +1,92 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/tokens"
+)
+
+// ErrPromptTooLarge is returned when a prompt is estimated above the model context window and
+// the provider is configured to fail fast.
+var ErrPromptTooLarge = errors.New("prompt exceeds the model context window")
+
+// completionReserve is the number of tokens kept free in the context window for the answer.
+const completionReserve = 1024
+
+// contextWindows lists the context window, in tokens, of known model families. More specific
+// names come first, since a model matches the first entry contained in its ID.
+var contextWindows = []struct {
+	family string
+	tokens int
+}{
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4-32k", 32768},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1-mini", 128000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4-mini", 200000},
+	{"anthropic.claude", 200000},
+	{"amazon.titan-text-lite", 4096},
+	{"amazon.titan-text-express", 8192},
+	{"amazon.titan-text-premier", 32000},
+	{"amazon.nova", 300000},
+	{"meta.llama3-1", 128000},
+	{"meta.llama3", 8192},
+	{"mistral.mistral-large", 128000},
+	{"mistral.", 32000},
+}
+
+// ContextWindow returns the context window of a model in tokens, or 0 when the model is unknown.
+func ContextWindow(model string) int {
+	model = strings.ToLower(model)
+	for _, w := range contextWindows {
+		if strings.Contains(model, w.family) {
+			return w.tokens
+		}
+	}
+	return 0
+}
+
+// promptLimit checks the estimated size of prompts against the context window of a model.
+type promptLimit struct {
+	model    string
+	window   int
+	failFast bool
+}
+
+// newPromptLimit returns the limit of the configured model, using config.ContextWindow when set.
+func newPromptLimit(config Config) promptLimit {
+	window := config.ContextWindow
+	if window == 0 {
+		window = ContextWindow(config.Model)
+	}
+	return promptLimit{model: config.Model, window: window, failFast: config.FailOnOversizedPrompt}
+}
+
+// check estimates the tokens of the prompt texts of a request. When they do not fit the context
+// window, leaving room for the answer, it logs a warning or, when failing fast, returns
+// ErrPromptTooLarge. Unknown windows are not checked.
+func (l promptLimit) check(req Request, texts ...string) error {
+	if l.window <= 0 {
+		return nil
+	}
+	estimate := 0
+	for _, text := range texts {
+		estimate += tokens.Estimate(text)
+	}
+	if estimate+completionReserve <= l.window {
+		return nil
+	}
+	if l.failFast {
+		return fmt.Errorf("%w: row %s, about %d tokens for %s (window %d)", ErrPromptTooLarge, req.ID, estimate, l.model, l.window)
+	}
+	slog.Warn("prompt may exceed the model context window", "row", req.ID, "tokens", estimate, "model", l.model, "window", l.window)
+	return nil
+}
@@ -0,0 +1,55 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/sashabaranov/go-openai"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWindow(t *testing.T) {
+	assert.Equal(t, 128000, ContextWindow("gpt-4o-mini"))
+	assert.Equal(t, 8192, ContextWindow("gpt-4"))
+	assert.Equal(t, 128000, ContextWindow("gpt-4-turbo-preview"))
+	assert.Equal(t, 200000, ContextWindow("us.anthropic.claude-3-5-sonnet-20240620-v1:0"))
+	assert.Equal(t, 4096, ContextWindow("amazon.titan-text-lite-v1"))
+	assert.Equal(t, 0, ContextWindow("llama3.2:latest"))
+}
+
+func TestPromptLimit(t *testing.T) {
+	long := strings.Repeat("word ", 200)
+
+	limit := newPromptLimit(Config{Model: "unknown"})
+	assert.NoError(t, limit.check(Request{}, long))
+
+	limit = newPromptLimit(Config{Model: "unknown", ContextWindow: 1100})
+	assert.NoError(t, limit.check(Request{}, long), "warns only")
+
+	limit.failFast = true
+	assert.NoError(t, limit.check(Request{}, "short"))
+	err := limit.check(Request{ID: "7"}, long)
+	assert.ErrorIs(t, err, ErrPromptTooLarge)
+	assert.ErrorContains(t, err, "row 7, about 200 tokens for unknown (window 1100)")
+}
+
+func TestOpenAIProvider_OversizedPrompt(t *testing.T) {
+	called := false
+	provider := &OpenAIProvider{
+		model: "gpt-4",
+		prompts: &mockPromptManager{getPromptFunc: func(prompt.ItemType, prompt.Data) (string, error) {
+			return strings.Repeat("word ", 8000), nil
+		}},
+		limit: newPromptLimit(Config{Model: "gpt-4", FailOnOversizedPrompt: true}),
+		client: &mockOpenAIClient{createFunc: func(context.Context, openai.ChatCompletionRequest) (openai.ChatCompletionResponse, error) {
+			called = true
+			return openai.ChatCompletionResponse{}, nil
+		}},
+	}
+
+	_, err := provider.GenerateContent(context.Background(), testRequest)
+	assert.ErrorIs(t, err, ErrPromptTooLarge)
+	assert.False(t, called)
+}
@@ -0,0 +1,136 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/config"
+)
+
+// sendMailFunc sends a message through an SMTP server, see smtp.SendMail.
+type sendMailFunc func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+// EmailNotifier sends run summaries by email through an SMTP server, with the HTML report of the
+// run as the message body.
+type EmailNotifier struct {
+	server   config.SMTP
+	to       []string
+	sendMail sendMailFunc
+}
+
+// Notify sends the summary to the recipients. smtp.SendMail does not take a context, so the
+// deadline is only checked before sending.
+func (n *EmailNotifier) Notify(ctx context.Context, summary Summary) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	msg, err := n.message(summary)
+	if err != nil {
+		return err
+	}
+
+	port := n.server.Port
+	if port == 0 {
+		port = config.DefaultSMTPPort
+	}
+	var auth smtp.Auth
+	if n.server.Username != "" {
+		auth = smtp.PlainAuth("", n.server.Username, n.server.Password, n.server.Host)
+	}
+	addr := net.JoinHostPort(n.server.Host, strconv.Itoa(port))
+	if err := n.sendMail(addr, auth, n.server.From, n.to, msg); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+	return nil
+}
+
+// message builds a multipart/alternative message with the plain text summary and, when the
+// summary has one, the HTML report.
+func (n *EmailNotifier) message(summary Summary) ([]byte, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	if err := writePart(w, "text/plain", emailText(summary)); err != nil {
+		return nil, err
+	}
+	if len(summary.Report) > 0 {
+		if err := writePart(w, "text/html", string(summary.Report)); err != nil {
+			return nil, err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build email: %w", err)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.server.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", summary.headline()))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: multipart/alternative; boundary=%s\r\n\r\n", w.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// writePart adds a quoted-printable part to a multipart message.
+func writePart(w *multipart.Writer, contentType, content string) error {
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType + "; charset=utf-8"},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	qp := quotedprintable.NewWriter(part)
+	if _, err := qp.Write([]byte(content)); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	if err := qp.Close(); err != nil {
+		return fmt.Errorf("failed to build email: %w", err)
+	}
+	return nil
+}
+
+// emailText returns the plain text version of the summary.
+func emailText(summary Summary) string {
+	var sb strings.Builder
+	sb.WriteString(summary.headline() + "\n")
+	if summary.Source != "" {
+		sb.WriteString("Source: " + summary.Source + "\n")
+	}
+	if len(summary.Issues) > 0 {
+		sb.WriteString("\nCreated:\n")
+		for i, link := range summary.Issues {
+			if i == maxLinks {
+				sb.WriteString(fmt.Sprintf("- and %d more\n", len(summary.Issues)-maxLinks))
+				break
+			}
+			if link.URL != "" {
+				sb.WriteString(fmt.Sprintf("- %s: %s\n", link.Title, link.URL))
+			} else {
+				sb.WriteString("- " + link.Title + "\n")
+			}
+		}
+	}
+	if len(summary.Failures) > 0 {
+		sb.WriteString("\nFailed:\n")
+		for i, failure := range summary.Failures {
+			if i == maxLinks {
+				sb.WriteString(fmt.Sprintf("- and %d more\n", len(summary.Failures)-maxLinks))
+				break
+			}
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", failure.Source, failure.Error))
+		}
+	}
+	return sb.String()
+}
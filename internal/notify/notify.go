@@ -0,0 +1,114 @@
+// Package notify sends a summary of each run to chat webhooks (Slack, Microsoft Teams) or by email.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/smtp"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/leocomelli/aigile/internal/httpclient"
+)
+
+// maxLinks is the maximum number of issue links and failures listed in a message.
+const maxLinks = 10
+
+// Summary is the outcome of a run sent to the webhooks.
+type Summary struct {
+	RunID    string
+	Source   string
+	Duration time.Duration
+	Total    int
+	Created  int
+	Failed   int
+	Skipped  int // Invalid rows skipped in lenient mode
+	Warnings int // Row warnings, e.g. an issue not linked to its parent
+	Issues   []Link
+	Failures []Failure
+	Report   []byte // HTML report of the run, sent by email
+}
+
+// Link is an issue created in the run.
+type Link struct {
+	Title string
+	URL   string
+}
+
+// Failure is a row that could not be processed.
+type Failure struct {
+	Source string
+	Error  string
+}
+
+// Notifier sends a run summary to a destination.
+type Notifier interface {
+	Notify(ctx context.Context, summary Summary) error
+}
+
+// New creates the notifier of a configured webhook or email, using server for emails.
+func New(n config.Notification, server config.SMTP) (Notifier, error) {
+	switch n.Type {
+	case config.NotifySlack:
+		return &SlackNotifier{url: n.URL, client: httpclient.New(httpclient.DefaultTimeout)}, nil
+	case config.NotifyTeams:
+		return &TeamsNotifier{url: n.URL, client: httpclient.New(httpclient.DefaultTimeout)}, nil
+	case config.NotifyEmail:
+		return &EmailNotifier{server: server, to: n.To, sendMail: smtp.SendMail}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notification type: %s", n.Type)
+	}
+}
+
+// ShouldNotify reports whether a notification is sent for a run with the given outcome.
+func ShouldNotify(n config.Notification, failed bool) bool {
+	return n.On != config.NotifyOnFailure || failed
+}
+
+// headline returns the first line of the messages.
+func (s Summary) headline() string {
+	status := "completed"
+	switch {
+	case s.Failed > 0:
+		status = "completed with failures"
+	case s.Warnings > 0:
+		status = "completed with warnings"
+	}
+	counts := ""
+	if s.Skipped > 0 {
+		counts = fmt.Sprintf(", %d skipped", s.Skipped)
+	}
+	if s.Warnings > 0 {
+		counts += fmt.Sprintf(", %d warnings", s.Warnings)
+	}
+	return fmt.Sprintf("aigile run %s %s: %d of %d rows created, %d failed%s (%s)",
+		s.RunID, status, s.Created, s.Total, s.Failed, counts, s.Duration.Round(time.Second))
+}
+
+// postJSON sends payload to a webhook URL.
+func postJSON(ctx context.Context, client *http.Client, url string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook error (status: %d): %s", resp.StatusCode, data)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"net/smtp"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWebhook starts a server recording the JSON payloads it receives.
+func newWebhook(t *testing.T, status int) (*httptest.Server, *[]map[string]any) {
+	var payloads []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		payloads = append(payloads, payload)
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &payloads
+}
+
+var testSummary = Summary{
+	RunID:    "run-1",
+	Source:   "backlog.xlsx",
+	Duration: 90 * time.Second,
+	Total:    3,
+	Created:  2,
+	Failed:   1,
+	Issues:   []Link{{Title: "Pay <by> card", URL: "https://github.com/o/r/issues/1"}, {Title: "Refunds"}},
+	Failures: []Failure{{Source: "backlog.xlsx:Sheet1!4", Error: "timeout"}},
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, payloads := newWebhook(t, http.StatusOK)
+	n, err := New(config.Notification{Type: config.NotifySlack, URL: server.URL}, config.SMTP{})
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), testSummary))
+	require.Len(t, *payloads, 1)
+	assert.Equal(t, "*aigile run run-1 completed with failures: 2 of 3 rows created, 1 failed (1m30s)*\n"+
+		"Source: backlog.xlsx\n"+
+		"• <https://github.com/o/r/issues/1|Pay &lt;by&gt; card>\n"+
+		"• Refunds\n"+
+		":x: backlog.xlsx:Sheet1!4: timeout\n", (*payloads)[0]["text"])
+}
+
+func TestTeamsNotifier(t *testing.T) {
+	server, payloads := newWebhook(t, http.StatusAccepted)
+	n, err := New(config.Notification{Type: config.NotifyTeams, URL: server.URL}, config.SMTP{})
+	require.NoError(t, err)
+
+	require.NoError(t, n.Notify(context.Background(), testSummary))
+	require.Len(t, *payloads, 1)
+	attachment := (*payloads)[0]["attachments"].([]any)[0].(map[string]any)
+	assert.Equal(t, "application/vnd.microsoft.card.adaptive", attachment["contentType"])
+	body := attachment["content"].(map[string]any)["body"].([]any)
+	require.Len(t, body, 5)
+	assert.Equal(t, "- [Pay <by> card](https://github.com/o/r/issues/1)", body[2].(map[string]any)["text"])
+	assert.Equal(t, "Attention", body[4].(map[string]any)["color"])
+}
+
+func TestNotify_TooManyLinks(t *testing.T) {
+	server, payloads := newWebhook(t, http.StatusOK)
+	n, err := New(config.Notification{Type: config.NotifySlack, URL: server.URL}, config.SMTP{})
+	require.NoError(t, err)
+
+	summary := Summary{RunID: "run-1"}
+	for i := range maxLinks + 3 {
+		summary.Issues = append(summary.Issues, Link{Title: fmt.Sprintf("issue %d", i)})
+	}
+	require.NoError(t, n.Notify(context.Background(), summary))
+	assert.Contains(t, (*payloads)[0]["text"], "• and 3 more\n")
+}
+
+func TestNotify_WebhookError(t *testing.T) {
+	server, _ := newWebhook(t, http.StatusNotFound)
+	n, err := New(config.Notification{Type: config.NotifySlack, URL: server.URL}, config.SMTP{})
+	require.NoError(t, err)
+	assert.ErrorContains(t, n.Notify(context.Background(), testSummary), "webhook error (status: 404)")
+
+	_, err = New(config.Notification{Type: "sms"}, config.SMTP{})
+	assert.ErrorContains(t, err, "unsupported notification type")
+}
+
+func TestSummary_Skipped(t *testing.T) {
+	summary := testSummary
+	summary.Total, summary.Skipped = 4, 1
+	assert.Equal(t, "aigile run run-1 completed with failures: 2 of 4 rows created, 1 failed, 1 skipped (1m30s)", summary.headline())
+}
+
+func TestSummary_Warnings(t *testing.T) {
+	summary := testSummary
+	summary.Warnings = 2
+	assert.Equal(t, "aigile run run-1 completed with failures: 2 of 3 rows created, 1 failed, 2 warnings (1m30s)", summary.headline())
+
+	summary.Failed, summary.Created = 0, 3
+	assert.Equal(t, "aigile run run-1 completed with warnings: 3 of 3 rows created, 0 failed, 2 warnings (1m30s)", summary.headline())
+}
+
+func TestShouldNotify(t *testing.T) {
+	assert.True(t, ShouldNotify(config.Notification{}, false))
+	assert.True(t, ShouldNotify(config.Notification{On: config.NotifyAlways}, false))
+	assert.False(t, ShouldNotify(config.Notification{On: config.NotifyOnFailure}, false))
+	assert.True(t, ShouldNotify(config.Notification{On: config.NotifyOnFailure}, true))
+}
+
+func TestEmailNotifier(t *testing.T) {
+	var addr, from string
+	var to []string
+	var msg []byte
+	n := &EmailNotifier{
+		server: config.SMTP{Host: "smtp.example.com", Username: "bot", Password: "secret", From: "aigile@example.com"},
+		to:     []string{"po@example.com", "team@example.com"},
+		sendMail: func(a string, auth smtp.Auth, f string, t []string, m []byte) error {
+			addr, from, to, msg = a, f, t, m
+			return nil
+		},
+	}
+
+	summary := testSummary
+	summary.Report = []byte("<html><body>report</body></html>")
+	require.NoError(t, n.Notify(context.Background(), summary))
+	assert.Equal(t, "smtp.example.com:587", addr)
+	assert.Equal(t, "aigile@example.com", from)
+	assert.Equal(t, []string{"po@example.com", "team@example.com"}, to)
+
+	m, err := mail.ReadMessage(bytes.NewReader(msg))
+	require.NoError(t, err)
+	assert.Equal(t, "po@example.com, team@example.com", m.Header.Get("To"))
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "aigile run run-1 completed with failures: 2 of 3 rows created, 1 failed (1m30s)", subject)
+
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	require.NoError(t, err)
+	r := multipart.NewReader(m.Body, params["boundary"])
+	var parts []string
+	for {
+		part, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := io.ReadAll(part)
+		require.NoError(t, err)
+		parts = append(parts, part.Header.Get("Content-Type")+"\n"+string(data))
+	}
+	require.Len(t, parts, 2)
+	assert.Contains(t, parts[0], "text/plain")
+	assert.Contains(t, parts[0], "- Pay <by> card: https://github.com/o/r/issues/1\r\n")
+	assert.Contains(t, parts[0], "- backlog.xlsx:Sheet1!4: timeout\r\n")
+	assert.Equal(t, "text/html; charset=utf-8\n<html><body>report</body></html>", parts[1])
+}
+
+func TestEmailNotifier_Error(t *testing.T) {
+	n, err := New(config.Notification{Type: config.NotifyEmail, To: []string{"po@example.com"}}, config.SMTP{Host: "smtp.example.com", Port: 25})
+	require.NoError(t, err)
+	email := n.(*EmailNotifier)
+	email.sendMail = func(addr string, _ smtp.Auth, _ string, _ []string, _ []byte) error {
+		assert.Equal(t, "smtp.example.com:25", addr)
+		return errors.New("connection refused")
+	}
+	assert.ErrorContains(t, n.Notify(context.Background(), testSummary), "failed to send email: connection refused")
+}
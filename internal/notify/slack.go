@@ -0,0 +1,47 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// SlackNotifier posts run summaries to a Slack incoming webhook.
+type SlackNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// Notify posts the summary as a message with the created issue links and the failures.
+func (n *SlackNotifier) Notify(ctx context.Context, summary Summary) error {
+	var sb strings.Builder
+	sb.WriteString("*" + slackEscape(summary.headline()) + "*\n")
+	if summary.Source != "" {
+		sb.WriteString("Source: " + slackEscape(summary.Source) + "\n")
+	}
+	for i, link := range summary.Issues {
+		if i == maxLinks {
+			sb.WriteString(fmt.Sprintf("• and %d more\n", len(summary.Issues)-maxLinks))
+			break
+		}
+		if link.URL != "" {
+			sb.WriteString(fmt.Sprintf("• <%s|%s>\n", link.URL, slackEscape(link.Title)))
+		} else {
+			sb.WriteString("• " + slackEscape(link.Title) + "\n")
+		}
+	}
+	for i, failure := range summary.Failures {
+		if i == maxLinks {
+			sb.WriteString(fmt.Sprintf(":x: and %d more failures\n", len(summary.Failures)-maxLinks))
+			break
+		}
+		sb.WriteString(fmt.Sprintf(":x: %s: %s\n", slackEscape(failure.Source), slackEscape(failure.Error)))
+	}
+	return postJSON(ctx, n.client, n.url, map[string]string{"text": sb.String()})
+}
+
+// slackEscape escapes the characters with a special meaning in Slack messages.
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
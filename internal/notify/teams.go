@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// TeamsNotifier posts run summaries to a Microsoft Teams incoming webhook (or Workflows webhook)
+// as an Adaptive Card.
+type TeamsNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// Notify posts the summary as an Adaptive Card with the created issue links and the failures.
+func (n *TeamsNotifier) Notify(ctx context.Context, summary Summary) error {
+	body := []map[string]any{
+		{"type": "TextBlock", "text": summary.headline(), "weight": "Bolder", "wrap": true},
+	}
+	if summary.Source != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": "Source: " + summary.Source, "isSubtle": true, "wrap": true})
+	}
+	for i, link := range summary.Issues {
+		if i == maxLinks {
+			body = append(body, textBlock(fmt.Sprintf("and %d more", len(summary.Issues)-maxLinks)))
+			break
+		}
+		if link.URL != "" {
+			body = append(body, textBlock(fmt.Sprintf("- [%s](%s)", link.Title, link.URL)))
+		} else {
+			body = append(body, textBlock("- "+link.Title))
+		}
+	}
+	for i, failure := range summary.Failures {
+		if i == maxLinks {
+			body = append(body, textBlock(fmt.Sprintf("and %d more failures", len(summary.Failures)-maxLinks)))
+			break
+		}
+		block := textBlock(fmt.Sprintf("%s: %s", failure.Source, failure.Error))
+		block["color"] = "Attention"
+		body = append(body, block)
+	}
+
+	payload := map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]any{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+	return postJSON(ctx, n.client, n.url, payload)
+}
+
+// textBlock returns an Adaptive Card text block.
+func textBlock(text string) map[string]any {
+	return map[string]any{"type": "TextBlock", "text": text, "wrap": true}
+}
@@ -0,0 +1,199 @@
+// Package policy enforces the rules of the organization on the generated content before the issues
+// are created, such as not naming customers or including credentials: each rule either blocks the
+// item or rewrites the offending text.
+package policy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/redact"
+)
+
+// Actions of a rule.
+const (
+	ActionBlock   = "block"   // Fail the item, so no issue is created for it
+	ActionRewrite = "rewrite" // Replace the matches and create the issue
+)
+
+// Built-in rules.
+const (
+	BuiltinCredentials = "credentials" // Private keys, tokens, API keys and password assignments
+	BuiltinProfanity   = "profanity"   // Common English swear words
+)
+
+// credentialDetectors are the redaction detectors of the credentials built-in rule.
+var credentialDetectors = map[string]bool{
+	"private_key": true, "github_token": true, "aws_access_key": true, "slack_token": true,
+	"openai_key": true, "jwt": true, "bearer_token": true, "secret_assignment": true,
+}
+
+// profanity matches the profanity built-in rule, including the derived words, e.g. "bullshitting".
+var profanity = regexp.MustCompile(`(?i)\b(?:fuck|shit|bullshit|bitch|asshole|bastard|cunt|dickhead|motherfuck|crap|damn)\w*\b`)
+
+// Rule is a rule of the configuration file. It matches a built-in rule, a regular expression or a
+// list of terms.
+type Rule struct {
+	Name        string   `yaml:"name"`
+	Builtin     string   `yaml:"builtin"`     // credentials or profanity
+	Pattern     string   `yaml:"pattern"`     // Regular expression
+	Terms       []string `yaml:"terms"`       // Words or phrases, matched as whole words ignoring case
+	Action      string   `yaml:"action"`      // block (default) or rewrite
+	Replacement string   `yaml:"replacement"` // Text replacing the matches of a rewrite rule, defaults to [name]
+	Message     string   `yaml:"message"`     // Reason of the rule, reported when it blocks an item
+}
+
+// Violation is a rule matched by the generated content.
+type Violation struct {
+	Rule    string
+	Action  string
+	Field   string // title, description, acceptance criterion N or task N
+	Matches int
+}
+
+// String describes the violation, e.g. "rewritten by policy rule customers (2 matches in description)".
+func (v Violation) String() string {
+	verb := "blocked"
+	if v.Action == ActionRewrite {
+		verb = "rewritten"
+	}
+	matches := "matches"
+	if v.Matches == 1 {
+		matches = "match"
+	}
+	return fmt.Sprintf("%s by policy rule %s (%d %s in %s)", verb, v.Rule, v.Matches, matches, v.Field)
+}
+
+// BlockedError is returned when the generated content matches a block rule.
+type BlockedError struct {
+	Violation
+	Message string
+}
+
+func (e *BlockedError) Error() string {
+	if e.Message != "" {
+		return e.Violation.String() + ": " + e.Message
+	}
+	return e.Violation.String()
+}
+
+// rule is a rule with its compiled patterns.
+type rule struct {
+	Rule
+	patterns []*regexp.Regexp
+}
+
+// Engine applies the rules to the generated content.
+type Engine struct {
+	rules []rule
+}
+
+// New compiles the rules of the configuration file.
+func New(rules []Rule) (*Engine, error) {
+	e := &Engine{}
+	for i, r := range rules {
+		if r.Name == "" {
+			r.Name = r.Builtin
+		}
+		if r.Name == "" {
+			return nil, fmt.Errorf("policy rule %d has no name", i+1)
+		}
+		switch r.Action {
+		case "":
+			r.Action = ActionBlock
+		case ActionBlock, ActionRewrite:
+		default:
+			return nil, fmt.Errorf("invalid action of policy rule %s: %s (expected block or rewrite)", r.Name, r.Action)
+		}
+		if r.Replacement == "" {
+			r.Replacement = "[" + r.Name + "]"
+		}
+
+		compiled := rule{Rule: r}
+		switch r.Builtin {
+		case "":
+		case BuiltinCredentials:
+			for _, d := range redact.DefaultDetectors {
+				if credentialDetectors[d.Name] {
+					compiled.patterns = append(compiled.patterns, d.Pattern)
+				}
+			}
+		case BuiltinProfanity:
+			compiled.patterns = append(compiled.patterns, profanity)
+		default:
+			return nil, fmt.Errorf("unknown built-in policy rule %s: %s (expected credentials or profanity)", r.Name, r.Builtin)
+		}
+		if r.Pattern != "" {
+			pattern, err := regexp.Compile(r.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern of policy rule %s: %w", r.Name, err)
+			}
+			compiled.patterns = append(compiled.patterns, pattern)
+		}
+		if len(r.Terms) > 0 {
+			quoted := make([]string, 0, len(r.Terms))
+			for _, term := range r.Terms {
+				if term = strings.TrimSpace(term); term != "" {
+					quoted = append(quoted, regexp.QuoteMeta(term))
+				}
+			}
+			compiled.patterns = append(compiled.patterns, regexp.MustCompile(`(?i)\b(?:`+strings.Join(quoted, "|")+`)\b`))
+		}
+		if len(compiled.patterns) == 0 {
+			return nil, fmt.Errorf("policy rule %s has no builtin, pattern or terms", r.Name)
+		}
+		e.rules = append(e.rules, compiled)
+	}
+	return e, nil
+}
+
+// Len returns the number of rules.
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Apply checks the title, description, acceptance criteria and tasks of the content against the
+// rules, rewriting the matches of the rewrite rules in place. It returns the violations found and,
+// when a block rule matched, a *BlockedError for the first of them.
+func (e *Engine) Apply(content *llm.GeneratedContent) ([]Violation, error) {
+	var violations []Violation
+	var blocked *BlockedError
+	check := func(field string, text *string) {
+		for _, r := range e.rules {
+			matches := 0
+			for _, p := range r.patterns {
+				found := p.FindAllStringIndex(*text, -1)
+				if len(found) == 0 {
+					continue
+				}
+				matches += len(found)
+				if r.Action == ActionRewrite {
+					*text = p.ReplaceAllLiteralString(*text, r.Replacement)
+				}
+			}
+			if matches == 0 {
+				continue
+			}
+			v := Violation{Rule: r.Name, Action: r.Action, Field: field, Matches: matches}
+			violations = append(violations, v)
+			if r.Action == ActionBlock && blocked == nil {
+				blocked = &BlockedError{Violation: v, Message: r.Message}
+			}
+		}
+	}
+
+	check("title", &content.Title)
+	check("description", &content.Description)
+	for i := range content.AcceptanceCriteria {
+		check(fmt.Sprintf("acceptance criterion %d", i+1), &content.AcceptanceCriteria[i])
+	}
+	for i := range content.SuggestedTasks {
+		check(fmt.Sprintf("task %d", i+1), &content.SuggestedTasks[i])
+	}
+	if blocked != nil {
+		return violations, blocked
+	}
+	return violations, nil
+}
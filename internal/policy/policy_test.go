@@ -0,0 +1,75 @@
+package policy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Rewrite(t *testing.T) {
+	e, err := New([]Rule{
+		{Name: "customers", Terms: []string{"Acme Corp", "Globex"}, Action: ActionRewrite, Replacement: "a customer"},
+		{Builtin: BuiltinProfanity, Action: ActionRewrite},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, e.Len())
+
+	content := &llm.GeneratedContent{
+		Title:              "Export invoices for ACME corp",
+		Description:        "As Globex, I want the damn export so that acme corp stops calling.",
+		AcceptanceCriteria: []string{"Given Globexian data When exported Then it is a CSV"},
+		SuggestedTasks:     []string{"Add the export"},
+	}
+	violations, err := e.Apply(content)
+	require.NoError(t, err)
+	assert.Equal(t, "Export invoices for a customer", content.Title)
+	assert.Equal(t, "As a customer, I want the [profanity] export so that a customer stops calling.", content.Description)
+	assert.Equal(t, "Given Globexian data When exported Then it is a CSV", content.AcceptanceCriteria[0])
+	assert.Equal(t, []Violation{
+		{Rule: "customers", Action: ActionRewrite, Field: "title", Matches: 1},
+		{Rule: "customers", Action: ActionRewrite, Field: "description", Matches: 2},
+		{Rule: "profanity", Action: ActionRewrite, Field: "description", Matches: 1},
+	}, violations)
+	assert.Equal(t, "rewritten by policy rule customers (2 matches in description)", violations[1].String())
+}
+
+func TestEngine_Block(t *testing.T) {
+	e, err := New([]Rule{
+		{Builtin: BuiltinCredentials, Message: "never put secrets in issues"},
+		{Name: "tickets", Pattern: `INC-\d+`},
+	})
+	require.NoError(t, err)
+
+	content := &llm.GeneratedContent{
+		Title:          "Rotate keys",
+		Description:    "Use password=hunter2hunter2 to log in.",
+		SuggestedTasks: []string{"Close INC-42", "Close INC-43"},
+	}
+	violations, err := e.Apply(content)
+	var blocked *BlockedError
+	require.True(t, errors.As(err, &blocked))
+	assert.Equal(t, "credentials", blocked.Rule)
+	assert.Equal(t, "blocked by policy rule credentials (1 match in description): never put secrets in issues", err.Error())
+	assert.Len(t, violations, 3)
+	assert.Equal(t, "Use password=hunter2hunter2 to log in.", content.Description)
+
+	violations, err = e.Apply(&llm.GeneratedContent{Title: "Clean", Description: "Nothing to see."})
+	assert.NoError(t, err)
+	assert.Empty(t, violations)
+}
+
+func TestNew_Errors(t *testing.T) {
+	for _, rules := range [][]Rule{
+		{{Pattern: "x"}},
+		{{Name: "a", Pattern: "x", Action: "delete"}},
+		{{Name: "a", Builtin: "gossip"}},
+		{{Name: "a", Pattern: "("}},
+		{{Name: "a"}},
+	} {
+		_, err := New(rules)
+		assert.Error(t, err, "%+v", rules)
+	}
+}
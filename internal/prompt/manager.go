@@ -2,18 +2,41 @@
 package prompt
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 )
 
+// DefaultSystemPrompt is the system message sent with every generation unless customized.
+const DefaultSystemPrompt = "You are an expert in agile methodologies and software development. Your task is to generate high-quality agile artifacts in JSON format."
+
+// Prompt file names inside a prompts directory. Item type prompts are named after the item type
+// slug, e.g. user-story.txt, and their system prompts add the system suffix, e.g. epic.system.txt.
+const (
+	promptFileExt      = ".txt"
+	systemPromptName   = "system"
+	systemPromptSuffix = "." + systemPromptName
+)
+
 // Manager handles the prompts for different item types
 type Manager struct {
-	prompts map[ItemType]string
+	prompts      map[ItemType]string
+	system       string              // Global system prompt
+	systemByType map[ItemType]string // System prompts overriding the global one for an item type
+	sources      map[string]string   // Files the prompts were loaded from, by template name
 }
 
 // NewManager creates a new prompt manager with default prompts
 func NewManager() *Manager {
 	return &Manager{
+		system:       DefaultSystemPrompt,
+		systemByType: make(map[ItemType]string),
+		sources:      make(map[string]string),
 		prompts: map[ItemType]string{
 			UserStory: `
 You are an Agile development expert specialized in writing well-structured and detailed User Stories following all industry best practices.
@@ -23,33 +46,75 @@ Generate a detailed, clear, and well-written User Story, following the Agile for
 
 Title: In the format "As a [role], I want [goal]"
 Description: In the format "As a [persona], I want [feature] so that [benefit]"
-Acceptance Criteria: Written using the Gherkin format (Given / When / Then)
+Acceptance Criteria: {{.CriteriaFormat}}
 (Optional) Suggested tasks: A list of implementation tasks written in clear and actionable language
+Estimate: The effort in story points, using the Fibonacci scale (1, 2, 3, 5, 8, 13)
 
 Input parameters:
 Parent: {{.Parent}}
 Context provided by the user: {{.Context}}
+Acceptance criteria provided by the user:
+{{.Criteria}}
 Output language: {{.Language}}
 Generate task suggestions?: {{.GenerateTasks}}
+Number of suggested tasks: {{.TaskCount}}
 Output format: Return the User Story strictly in the following JSON structure:
 {
   "type": "User Story",
   "title": "As a [role], I want [goal]",
   "description": "As a [persona], I want [feature] so that [benefit]",
   "acceptance_criteria": [
-    "Given [initial context] When [action] Then [outcome]",
-    "Given [initial context] When [action] Then [outcome]"
+    "{{.CriteriaExample}}",
+    "{{.CriteriaExample}}"
   ],
   "suggested_tasks": [
     "Task 1",
     "Task 2"
-  ]
+  ],
+  "estimate": 3
 }
 Mandatory rules:
 The content must follow the language defined in the {language} parameter.
 If the {generate_tasks} parameter is false, the "suggested_tasks" array must be empty.
+If the {task_count} parameter is a number, the "suggested_tasks" array must have exactly that many tasks.
 Be highly descriptive and detailed, especially in the description and acceptance_criteria fields.
 Always use the provided context as the main source for generating the User Story.
+Every acceptance criterion provided by the user is a mandatory constraint: the "acceptance_criteria" array must cover all of them, rewritten in the requested format, without dropping or contradicting any.
+Do not include any explanations, comments, or instructional text in the output. Only return the pure JSON result.
+`,
+			Epic: `
+You are an Agile development expert specialized in writing well-structured Epics that group related User Stories.
+
+Objective:
+Generate a clear and well-written Epic, following the format below:
+
+Title: A short name for the business capability delivered by the Epic
+Description: The problem, the goal and the expected business value of the Epic
+Acceptance Criteria: {{.CriteriaFormat}}, describing when the Epic can be considered done
+
+Input parameters:
+Parent: {{.Parent}}
+Context provided by the user: {{.Context}}
+Acceptance criteria provided by the user:
+{{.Criteria}}
+Output language: {{.Language}}
+
+Output format: Return the Epic strictly in the following JSON structure:
+{
+  "type": "Epic",
+  "title": "[business capability]",
+  "description": "[problem, goal and business value]",
+  "acceptance_criteria": [
+    "{{.CriteriaExample}}"
+  ],
+  "suggested_tasks": []
+}
+
+Mandatory rules:
+The content must follow the language defined in the {language} parameter.
+The "suggested_tasks" array must always be empty.
+Always use the provided context as the main source for generating the Epic.
+Every acceptance criterion provided by the user is a mandatory constraint: the "acceptance_criteria" array must cover all of them, rewritten in the requested format, without dropping or contradicting any.
 Do not include any explanations, comments, or instructional text in the output. Only return the pure JSON result.
 `,
 		},
@@ -57,18 +122,13 @@ Do not include any explanations, comments, or instructional text in the output.
 }
 
 // GetPrompt returns the prompt string for the given item type and context, filling in template variables.
-func (m *Manager) GetPrompt(itemType ItemType, parent, context string, criteria []string, language string, generateTasks bool) (string, error) {
+func (m *Manager) GetPrompt(itemType ItemType, data Data) (string, error) {
 	promptTemplate, ok := m.prompts[itemType]
 	if !ok {
 		return "", fmt.Errorf("invalid item type: %s", itemType)
 	}
 
-	// Replace template variables
-	prompt := strings.ReplaceAll(promptTemplate, "{{.Parent}}", parent)
-	prompt = strings.ReplaceAll(prompt, "{{.Context}}", context)
-	prompt = strings.ReplaceAll(prompt, "{{.Criteria}}", strings.Join(criteria, ", "))
-	prompt = strings.ReplaceAll(prompt, "{{.Language}}", language)
-	prompt = strings.ReplaceAll(prompt, "{{.GenerateTasks}}", fmt.Sprintf("%v", generateTasks))
+	prompt := render(promptTemplate, data)
 
 	// Add common instructions for JSON output
 	prompt += "\n\nIMPORTANT:\n" +
@@ -80,6 +140,137 @@ func (m *Manager) GetPrompt(itemType ItemType, parent, context string, criteria
 	return prompt, nil
 }
 
+// GetSystemPrompt returns the system prompt for the given item type, filling in template variables.
+// The item type system prompt is used when set, otherwise the global one.
+func (m *Manager) GetSystemPrompt(itemType ItemType, data Data) (string, error) {
+	if !itemType.IsValid() {
+		return "", fmt.Errorf("invalid item type: %s", itemType)
+	}
+	system, ok := m.systemByType[itemType]
+	if !ok {
+		system = m.system
+	}
+	return render(system, data), nil
+}
+
+// SetSystemPrompt customizes the system prompt of an item type, or the global system prompt when
+// the item type is empty.
+func (m *Manager) SetSystemPrompt(itemType ItemType, prompt string) error {
+	if itemType == "" {
+		m.system = prompt
+		return nil
+	}
+	if !itemType.IsValid() {
+		return fmt.Errorf("invalid item type: %s", itemType)
+	}
+	m.systemByType[itemType] = prompt
+	return nil
+}
+
+// LoadDir overrides the prompts with the files found in dir: <type>.txt for the prompt of an item
+// type, <type>.system.txt for its system prompt and system.txt for the global system prompt,
+// where <type> is the item type slug (user-story, epic). Missing files keep the current prompts.
+func (m *Manager) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to read prompts directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != promptFileExt {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read prompt file %s: %w", name, err)
+		}
+
+		base := strings.TrimSuffix(name, promptFileExt)
+		system := false
+		if base == systemPromptName {
+			m.system = string(content)
+			m.sources[name] = path
+			slog.Debug("loaded prompt file", "file", name)
+			continue
+		}
+		if trimmed, ok := strings.CutSuffix(base, systemPromptSuffix); ok {
+			base, system = trimmed, true
+		}
+
+		itemType, ok := ItemTypeFromSlug(base)
+		if !ok {
+			slog.Warn("ignoring prompt file for unknown item type", "file", name)
+			continue
+		}
+		if system {
+			m.systemByType[itemType] = string(content)
+		} else {
+			m.prompts[itemType] = string(content)
+		}
+		m.sources[name] = path
+		slog.Debug("loaded prompt file", "file", name)
+	}
+	return nil
+}
+
+// NewManagerFromDir creates a prompt manager with the default prompts overridden by the files in
+// dir, see LoadDir. An empty dir returns the default prompts.
+func NewManagerFromDir(dir string) (*Manager, error) {
+	m := NewManager()
+	if dir == "" {
+		return m, nil
+	}
+	if err := m.LoadDir(dir); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("prompts directory not found: %s", dir)
+		}
+		return nil, err
+	}
+	return m, nil
+}
+
+// render fills in the template variables of a prompt.
+func render(template string, data Data) string {
+	criteriaFormat := data.CriteriaFormat
+	if criteriaFormat == "" {
+		criteriaFormat = CriteriaGherkin
+	}
+
+	prompt := strings.ReplaceAll(template, "{{.Parent}}", data.Parent)
+	prompt = strings.ReplaceAll(prompt, "{{.Context}}", data.Context)
+	prompt = strings.ReplaceAll(prompt, "{{.Criteria}}", criteriaList(data.Criteria))
+	prompt = strings.ReplaceAll(prompt, "{{.Language}}", data.Language)
+	prompt = strings.ReplaceAll(prompt, "{{.GenerateTasks}}", fmt.Sprintf("%v", data.GenerateTasks))
+	prompt = strings.ReplaceAll(prompt, "{{.TaskCount}}", taskCount(data.TaskCount))
+	prompt = strings.ReplaceAll(prompt, "{{.CriteriaFormat}}", criteriaFormat.Instruction())
+	prompt = strings.ReplaceAll(prompt, "{{.CriteriaExample}}", criteriaFormat.Example())
+	return prompt
+}
+
+// taskCount renders the number of tasks to suggest, "as needed" when it is not set.
+func taskCount(n int) string {
+	if n <= 0 {
+		return "as needed"
+	}
+	return strconv.Itoa(n)
+}
+
+// criteriaList renders the criteria of a row as a bulleted list, one criterion per line.
+func criteriaList(criteria []string) string {
+	var lines []string
+	for _, c := range criteria {
+		if c = strings.TrimSpace(c); c != "" {
+			lines = append(lines, "- "+c)
+		}
+	}
+	if len(lines) == 0 {
+		return "- None"
+	}
+	return strings.Join(lines, "\n")
+}
+
 // SetPrompt allows customizing the prompt template for a specific item type.
 func (m *Manager) SetPrompt(itemType ItemType, prompt string) error {
 	if !itemType.IsValid() {
@@ -1,6 +1,9 @@
 package prompt
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -45,7 +48,7 @@ func TestManager_GetPrompt(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := manager.GetPrompt(tt.itemType, tt.parent, tt.context, nil, tt.language, tt.generateTasks)
+			got, err := manager.GetPrompt(tt.itemType, Data{Parent: tt.parent, Context: tt.context, Language: tt.language, GenerateTasks: tt.generateTasks})
 			if tt.wantErr {
 				assert.Error(t, err)
 				return
@@ -68,10 +71,67 @@ func TestManager_GetPrompt(t *testing.T) {
 			assert.Contains(t, got, "\"description\": \"As a [persona], I want [feature] so that [benefit]\"")
 			assert.Contains(t, got, "\"acceptance_criteria\": [")
 			assert.Contains(t, got, "\"suggested_tasks\": [")
+			assert.Contains(t, got, "Given [initial context] When [action] Then [outcome]")
 		})
 	}
 }
 
+func TestManager_GetPrompt_CriteriaFormat(t *testing.T) {
+	manager := NewManager()
+
+	got, err := manager.GetPrompt(UserStory, Data{Language: "english", CriteriaFormat: CriteriaChecklist})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Acceptance Criteria: "+CriteriaChecklist.Instruction())
+	assert.Contains(t, got, "\"[verifiable condition]\"")
+	assert.NotContains(t, got, "Gherkin")
+
+	got, err = manager.GetPrompt(UserStory, Data{Language: "english", CriteriaFormat: CriteriaBullets})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Acceptance Criteria: "+CriteriaBullets.Instruction())
+}
+
+func TestManager_GetPrompt_Criteria(t *testing.T) {
+	manager := NewManager()
+
+	got, err := manager.GetPrompt(UserStory, Data{Context: "Card payments", Criteria: []string{"Visa and Mastercard", " ", "Refunds within 7 days"}})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Acceptance criteria provided by the user:\n- Visa and Mastercard\n- Refunds within 7 days\n")
+	assert.Contains(t, got, "Every acceptance criterion provided by the user is a mandatory constraint")
+
+	got, err = manager.GetPrompt(Epic, Data{Context: "Checkout revamp"})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Acceptance criteria provided by the user:\n- None\n")
+}
+
+func TestManager_GetPrompt_TaskCount(t *testing.T) {
+	manager := NewManager()
+
+	got, err := manager.GetPrompt(UserStory, Data{GenerateTasks: true, TaskCount: 2})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Number of suggested tasks: 2\n")
+
+	got, err = manager.GetPrompt(UserStory, Data{GenerateTasks: true})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "Number of suggested tasks: as needed\n")
+}
+
+func TestCriteriaFormat_IsValid(t *testing.T) {
+	assert.True(t, CriteriaGherkin.IsValid())
+	assert.True(t, CriteriaChecklist.IsValid())
+	assert.True(t, CriteriaBullets.IsValid())
+	assert.False(t, CriteriaFormat("table").IsValid())
+}
+
+func TestManager_GetPrompt_Epic(t *testing.T) {
+	manager := NewManager()
+	got, err := manager.GetPrompt(Epic, Data{Parent: "Board", Context: "Checkout revamp", Language: "english"})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "well-structured Epics")
+	assert.Contains(t, got, "Context provided by the user: Checkout revamp")
+	assert.Contains(t, got, "\"type\": \"Epic\"")
+	assert.Contains(t, got, "Acceptance Criteria: "+CriteriaGherkin.Instruction())
+}
+
 func TestManager_SetPrompt(t *testing.T) {
 	manager := NewManager()
 
@@ -81,7 +141,7 @@ func TestManager_SetPrompt(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Verify the prompt was set
-	got, err := manager.GetPrompt(UserStory, "", "", nil, "english", false)
+	got, err := manager.GetPrompt(UserStory, Data{Language: "english"})
 	assert.NoError(t, err)
 	assert.Contains(t, got, newPrompt)
 
@@ -90,6 +150,79 @@ func TestManager_SetPrompt(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestManager_SystemPrompt(t *testing.T) {
+	manager := NewManager()
+
+	got, err := manager.GetSystemPrompt(UserStory, Data{})
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSystemPrompt, got)
+
+	// The global system prompt applies to every type unless overridden
+	assert.NoError(t, manager.SetSystemPrompt("", "Write in {{.Language}}."))
+	assert.NoError(t, manager.SetSystemPrompt(Epic, "You write epics."))
+	got, err = manager.GetSystemPrompt(UserStory, Data{Language: "pt-BR"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Write in pt-BR.", got)
+	got, err = manager.GetSystemPrompt(Epic, Data{})
+	assert.NoError(t, err)
+	assert.Equal(t, "You write epics.", got)
+
+	assert.Error(t, manager.SetSystemPrompt("Invalid", "x"))
+	_, err = manager.GetSystemPrompt("Invalid", Data{})
+	assert.Error(t, err)
+}
+
+func TestNewManagerFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"system.txt":            "Global system",
+		"epic.system.txt":       "Epic system",
+		"user-story.txt":        "Story for {{.Parent}}",
+		"notes.md":              "ignored",
+		"unknown-type.txt":      "ignored",
+		"epic.system.backup":    "ignored",
+		"user-story.system.txt": "Story system",
+	}
+	for name, content := range files {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	manager, err := NewManagerFromDir(dir)
+	assert.NoError(t, err)
+
+	got, err := manager.GetPrompt(UserStory, Data{Parent: "Payments"})
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(got, "Story for Payments"))
+	got, err = manager.GetPrompt(Epic, Data{})
+	assert.NoError(t, err)
+	assert.Contains(t, got, "well-structured Epics")
+
+	got, _ = manager.GetSystemPrompt(UserStory, Data{})
+	assert.Equal(t, "Story system", got)
+	got, _ = manager.GetSystemPrompt(Epic, Data{})
+	assert.Equal(t, "Epic system", got)
+	manager.systemByType = map[ItemType]string{}
+	got, _ = manager.GetSystemPrompt(Epic, Data{})
+	assert.Equal(t, "Global system", got)
+
+	_, err = NewManagerFromDir(filepath.Join(dir, "missing"))
+	assert.ErrorContains(t, err, "prompts directory not found")
+
+	manager, err = NewManagerFromDir("")
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultSystemPrompt, manager.system)
+}
+
+func TestItemType_Slug(t *testing.T) {
+	assert.Equal(t, "user-story", UserStory.Slug())
+	assert.Equal(t, "epic", Epic.Slug())
+	itemType, ok := ItemTypeFromSlug("user-story")
+	assert.True(t, ok)
+	assert.Equal(t, UserStory, itemType)
+	_, ok = ItemTypeFromSlug("task")
+	assert.False(t, ok)
+}
+
 // boolToString converts a boolean value to its string representation ("true" or "false").
 func boolToString(b bool) string {
 	if b {
@@ -0,0 +1,96 @@
+package prompt
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// SourceBuiltin is the source of the templates that are not overridden by a file.
+const SourceBuiltin = "builtin"
+
+// Variables are the template variables filled in by render.
+var Variables = []string{"Parent", "Context", "Criteria", "Language", "GenerateTasks", "TaskCount", "CriteriaFormat", "CriteriaExample"}
+
+// variablePattern matches the template variables, e.g. {{.Context}} or {{ .Context }}.
+var variablePattern = regexp.MustCompile(`\{\{\s*\.?([^{}]*?)\s*\}\}`)
+
+// Template is a prompt of the manager, as it would be read from a prompts directory.
+type Template struct {
+	Name     string   // File name in a prompts directory, e.g. user-story.system.txt
+	ItemType ItemType // Item type of the prompt, empty for the global system prompt
+	System   bool     // Whether it is a system prompt
+	Source   string   // File it was loaded from, or SourceBuiltin
+	Text     string
+}
+
+// Templates returns the prompts used for generation: the prompt and the system prompt of each
+// item type, and the global system prompt. System prompts of an item type are listed only when set.
+func (m *Manager) Templates() []Template {
+	templates := []Template{{Name: systemPromptName + promptFileExt, System: true, Text: m.system}}
+	for _, itemType := range []ItemType{UserStory, Epic} {
+		templates = append(templates, Template{Name: itemType.Slug() + promptFileExt, ItemType: itemType, Text: m.prompts[itemType]})
+		if system, ok := m.systemByType[itemType]; ok {
+			templates = append(templates, Template{Name: itemType.Slug() + systemPromptSuffix + promptFileExt, ItemType: itemType, System: true, Text: system})
+		}
+	}
+	for i := range templates {
+		templates[i].Source = SourceBuiltin
+		if source, ok := m.sources[templates[i].Name]; ok {
+			templates[i].Source = source
+		}
+	}
+	return templates
+}
+
+// Severity of a template problem.
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found in a template by Validate.
+type Problem struct {
+	Template string
+	Severity string
+	Message  string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%s: %s: %s", p.Template, p.Severity, p.Message)
+}
+
+// Validate checks the templates for variables that would be sent verbatim to the LLM because they
+// are unknown, and for item type prompts missing the variables with the content of the row.
+func (m *Manager) Validate() []Problem {
+	var problems []Problem
+	for _, t := range m.Templates() {
+		used := map[string]bool{}
+		for _, match := range variablePattern.FindAllStringSubmatch(t.Text, -1) {
+			name := match[1]
+			used[name] = true
+			if !slices.Contains(Variables, name) {
+				problems = append(problems, Problem{t.Name, SeverityError, fmt.Sprintf("unknown variable %s (expected one of %s)", match[0], strings.Join(Variables, ", "))})
+			} else if !strings.HasPrefix(match[0], "{{.") || !strings.HasSuffix(match[0], "}}") || strings.ContainsAny(match[0], " \t") {
+				problems = append(problems, Problem{t.Name, SeverityError, fmt.Sprintf("variable %s is not filled in, write it as {{.%s}}", match[0], name)})
+			}
+		}
+		if t.System {
+			continue
+		}
+		if strings.TrimSpace(t.Text) == "" {
+			problems = append(problems, Problem{t.Name, SeverityError, "the prompt is empty"})
+			continue
+		}
+		if !used["Context"] {
+			problems = append(problems, Problem{t.Name, SeverityError, "missing {{.Context}}: the content of the rows is not sent to the LLM"})
+		}
+		for _, name := range []string{"Parent", "Criteria", "Language", "CriteriaFormat"} {
+			if !used[name] {
+				problems = append(problems, Problem{t.Name, SeverityWarning, fmt.Sprintf("missing {{.%s}}", name)})
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,51 @@
+package prompt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManager_Templates(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "epic.txt"), []byte("Epic {{.Context}}"), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "epic.system.txt"), []byte("Epic system"), 0o600))
+
+	m, err := NewManagerFromDir(dir)
+	require.NoError(t, err)
+	templates := m.Templates()
+	require.Len(t, templates, 4)
+
+	assert.Equal(t, "system.txt", templates[0].Name)
+	assert.Equal(t, SourceBuiltin, templates[0].Source)
+	assert.Equal(t, "user-story.txt", templates[1].Name)
+	assert.Equal(t, UserStory, templates[1].ItemType)
+	assert.Equal(t, Template{Name: "epic.txt", ItemType: Epic, Source: filepath.Join(dir, "epic.txt"), Text: "Epic {{.Context}}"}, templates[2])
+	assert.Equal(t, Template{Name: "epic.system.txt", ItemType: Epic, System: true, Source: filepath.Join(dir, "epic.system.txt"), Text: "Epic system"}, templates[3])
+}
+
+func TestManager_Validate(t *testing.T) {
+	assert.Empty(t, NewManager().Validate())
+
+	m := NewManager()
+	require.NoError(t, m.SetPrompt(UserStory, "Story about {{.Contxt}} in {{ .Language }}, parent {{.Parent}}"))
+	require.NoError(t, m.SetPrompt(Epic, " "))
+	require.NoError(t, m.SetSystemPrompt("", "System {{.Team}}"))
+
+	var got []string
+	for _, p := range m.Validate() {
+		got = append(got, p.String())
+	}
+	assert.Equal(t, []string{
+		"system.txt: error: unknown variable {{.Team}} (expected one of Parent, Context, Criteria, Language, GenerateTasks, TaskCount, CriteriaFormat, CriteriaExample)",
+		"user-story.txt: error: unknown variable {{.Contxt}} (expected one of Parent, Context, Criteria, Language, GenerateTasks, TaskCount, CriteriaFormat, CriteriaExample)",
+		"user-story.txt: error: variable {{ .Language }} is not filled in, write it as {{.Language}}",
+		"user-story.txt: error: missing {{.Context}}: the content of the rows is not sent to the LLM",
+		"user-story.txt: warning: missing {{.Criteria}}",
+		"user-story.txt: warning: missing {{.CriteriaFormat}}",
+		"epic.txt: error: the prompt is empty",
+	}, got)
+}
@@ -1,17 +1,20 @@
 package prompt
 
+import "strings"
+
 // ItemType represents the type of agile item
 type ItemType string
 
-// UserStory represents the 'User Story' agile item type.
+// Supported agile item types.
 const (
-	UserStory ItemType = "User Story"
+	UserStory ItemType = "User Story" // UserStory represents the 'User Story' agile item type.
+	Epic      ItemType = "Epic"       // Epic represents the 'Epic' agile item type, grouping user stories.
 )
 
 // IsValid checks if the item type is valid
 func (t ItemType) IsValid() bool {
 	switch t {
-	case UserStory:
+	case UserStory, Epic:
 		return true
 	default:
 		return false
@@ -22,3 +25,73 @@ func (t ItemType) IsValid() bool {
 func (t ItemType) String() string {
 	return string(t)
 }
+
+// Slug returns the item type as a lowercase, hyphenated name, e.g. "user-story", used in file names.
+func (t ItemType) Slug() string {
+	return strings.ReplaceAll(strings.ToLower(string(t)), " ", "-")
+}
+
+// ItemTypeFromSlug returns the item type with the given slug.
+func ItemTypeFromSlug(slug string) (ItemType, bool) {
+	for _, t := range []ItemType{UserStory, Epic} {
+		if t.Slug() == slug {
+			return t, true
+		}
+	}
+	return "", false
+}
+
+// CriteriaFormat represents how acceptance criteria are written and rendered.
+type CriteriaFormat string
+
+// Supported acceptance criteria formats.
+const (
+	CriteriaGherkin   CriteriaFormat = "gherkin"
+	CriteriaChecklist CriteriaFormat = "checklist"
+	CriteriaBullets   CriteriaFormat = "bullets"
+)
+
+// IsValid checks if the criteria format is supported
+func (f CriteriaFormat) IsValid() bool {
+	switch f {
+	case CriteriaGherkin, CriteriaChecklist, CriteriaBullets:
+		return true
+	default:
+		return false
+	}
+}
+
+// Instruction returns the prompt instruction describing how criteria must be written.
+func (f CriteriaFormat) Instruction() string {
+	switch f {
+	case CriteriaChecklist:
+		return "Written as a checklist of short, verifiable conditions that can be ticked off"
+	case CriteriaBullets:
+		return "Written as concise bullet points describing the expected behavior"
+	default:
+		return "Written using the Gherkin format (Given / When / Then)"
+	}
+}
+
+// Example returns a sample criterion in this format, used in the JSON structure of the prompt.
+func (f CriteriaFormat) Example() string {
+	switch f {
+	case CriteriaChecklist:
+		return "[verifiable condition]"
+	case CriteriaBullets:
+		return "[expected behavior]"
+	default:
+		return "Given [initial context] When [action] Then [outcome]"
+	}
+}
+
+// Data holds the values used to fill a prompt template.
+type Data struct {
+	Parent         string
+	Context        string
+	Criteria       []string
+	Language       string
+	GenerateTasks  bool
+	TaskCount      int // Number of tasks to suggest, zero for as many as needed
+	CriteriaFormat CriteriaFormat
+}
@@ -0,0 +1,146 @@
+// Package promptlib fetches prompt templates from a Git repository, such as a prompt library
+// shared by several teams, and caches them locally so runs do not need the network.
+package promptlib
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultRef is the ref fetched when none is configured.
+const DefaultRef = "HEAD"
+
+// Source is a directory of prompt files in a Git repository.
+type Source struct {
+	Repository string // Clone URL
+	Ref        string // Branch, tag or commit, defaults to DefaultRef
+	Path       string // Directory of the prompt files inside the repository
+}
+
+// Library is the local cache of the prompt sources.
+type Library struct {
+	cacheDir string
+}
+
+// New creates a library caching the repositories under cacheDir. An empty cacheDir uses the
+// aigile directory of the user cache, e.g. ~/.cache/aigile/prompts.
+func New(cacheDir string) (*Library, error) {
+	if cacheDir == "" {
+		dir, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the cache directory: %w", err)
+		}
+		cacheDir = filepath.Join(dir, "aigile", "prompts")
+	}
+	return &Library{cacheDir: cacheDir}, nil
+}
+
+// Dir returns the local directory with the prompt files of source, pulling the repository when it
+// is not cached yet.
+func (l *Library) Dir(ctx context.Context, source Source) (string, error) {
+	checkout := l.checkout(source)
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); errors.Is(err, fs.ErrNotExist) {
+		if _, err := l.Pull(ctx, source); err != nil {
+			return "", err
+		}
+	}
+	return promptsDir(checkout, source)
+}
+
+// Pull fetches the ref of source into the cache, cloning the repository the first time, and
+// returns the commit of the prompts.
+func (l *Library) Pull(ctx context.Context, source Source) (string, error) {
+	if source.Repository == "" {
+		return "", fmt.Errorf("prompts repository is required")
+	}
+	ref := source.Ref
+	if ref == "" {
+		ref = DefaultRef
+	}
+
+	checkout := l.checkout(source)
+	if _, err := os.Stat(filepath.Join(checkout, ".git")); errors.Is(err, fs.ErrNotExist) {
+		if err := os.MkdirAll(checkout, 0o755); err != nil {
+			return "", fmt.Errorf("failed to create prompts cache: %w", err)
+		}
+		if _, err := git(ctx, checkout, "init", "--quiet"); err != nil {
+			return "", err
+		}
+	}
+	// Only the ref is fetched, without history, and the checkout discards local changes
+	if _, err := git(ctx, checkout, "fetch", "--quiet", "--depth", "1", source.Repository, ref); err != nil {
+		return "", err
+	}
+	if _, err := git(ctx, checkout, "checkout", "--quiet", "--force", "FETCH_HEAD"); err != nil {
+		return "", err
+	}
+	commit, err := git(ctx, checkout, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	if _, err := promptsDir(checkout, source); err != nil {
+		return "", err
+	}
+	slog.Info("pulled prompts", "repository", redactURL(source.Repository), "ref", ref, "commit", commit)
+	return commit, nil
+}
+
+// checkout returns the cache directory of a repository and ref.
+func (l *Library) checkout(source Source) string {
+	sum := sha256.Sum256([]byte(source.Repository + "@" + source.Ref))
+	return filepath.Join(l.cacheDir, hex.EncodeToString(sum[:8]))
+}
+
+// promptsDir returns the directory of the prompt files inside a checkout.
+func promptsDir(checkout string, source Source) (string, error) {
+	dir := filepath.Join(checkout, filepath.FromSlash(source.Path))
+	if rel, err := filepath.Rel(checkout, dir); err != nil || strings.HasPrefix(rel, "..") {
+		return "", fmt.Errorf("invalid prompts path: %s", source.Path)
+	}
+	info, err := os.Stat(dir)
+	if err != nil || !info.IsDir() {
+		return "", fmt.Errorf("prompts path %q not found in %s", source.Path, redactURL(source.Repository))
+	}
+	return dir, nil
+}
+
+// git runs a git command in dir and returns its trimmed output.
+func git(ctx context.Context, dir string, args ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	// Never prompt for credentials, the URL or a credential helper must provide them
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("failed to run git %s: %w: %s", args[0], err, strings.TrimSpace(redactURL(stderr.String())))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// redactURL masks the credentials of the URLs in s, e.g. https://token@github.com.
+func redactURL(s string) string {
+	fields := strings.Fields(s)
+	for _, f := range fields {
+		scheme := strings.Index(f, "://")
+		at := strings.Index(f, "@")
+		if scheme < 0 || at < scheme {
+			continue
+		}
+		if slash := strings.Index(f[scheme+3:], "/"); slash >= 0 && scheme+3+slash < at {
+			continue
+		}
+		s = strings.ReplaceAll(s, f[scheme+3:at], "***")
+	}
+	return s
+}
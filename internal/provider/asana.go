@@ -0,0 +1,349 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leocomelli/aigile/internal/format"
+)
+
+// defaultAsanaBaseURL is the Asana REST API endpoint used when no base URL is configured.
+const defaultAsanaBaseURL = "https://app.asana.com/api/1.0/"
+
+// AsanaConfig holds the configuration for the Asana provider.
+type AsanaConfig struct {
+	Token         string
+	Workspace     string            // Workspace GID, used to look up projects by name
+	Project       string            // Default project GID, used when the item has no parent project
+	BaseURL       string            // Optional API base URL, defaults to https://app.asana.com/api/1.0/
+	Sections      map[string]string // Section name per label (item type), defaults to a section named after the label
+	EstimateField string            // Optional GID of the number custom field that receives the estimate
+}
+
+// AsanaProvider creates items as Asana tasks. Item types are mapped to project sections and
+// sub-issues are created as subtasks.
+type AsanaProvider struct {
+	api           *restClient
+	workspace     string
+	project       string
+	sections      map[string]string
+	estimateField string
+
+	mu           sync.Mutex
+	sectionCache map[string]map[string]string // project GID -> section name -> section GID
+}
+
+// NewAsanaProvider creates a new AsanaProvider with the given configuration.
+func NewAsanaProvider(config AsanaConfig) (*AsanaProvider, error) {
+	baseURL := config.BaseURL
+	if baseURL == "" {
+		baseURL = defaultAsanaBaseURL
+	}
+	header := http.Header{"Authorization": {"Bearer " + config.Token}}
+	api, err := newRestClient("Asana", baseURL, header, asanaErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &AsanaProvider{
+		api:           api,
+		workspace:     config.Workspace,
+		project:       config.Project,
+		sections:      config.Sections,
+		estimateField: config.EstimateField,
+		sectionCache:  make(map[string]map[string]string),
+	}, nil
+}
+
+// asanaTaskFields are the fields of asanaTask requested from the API.
+const asanaTaskFields = "name,notes,permalink_url,completed"
+
+// asanaTask is the subset of the Asana task resource used by the provider.
+type asanaTask struct {
+	GID          string `json:"gid"`
+	Name         string `json:"name"`
+	Notes        string `json:"notes"`
+	PermalinkURL string `json:"permalink_url"`
+	Completed    bool   `json:"completed"`
+}
+
+// asanaIssue wraps an Asana task to implement the Issue interface. The task GID is used as
+// both the issue number and ID.
+type asanaIssue struct {
+	task   asanaTask
+	labels []string
+}
+
+func (i *asanaIssue) GetNumber() int { return int(i.GetID()) }
+func (i *asanaIssue) GetID() int64 {
+	id, err := strconv.ParseInt(i.task.GID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+func (i *asanaIssue) GetHTMLURL() string    { return i.task.PermalinkURL }
+func (i *asanaIssue) GetTitle() string      { return i.task.Name }
+func (i *asanaIssue) GetBody() string       { return i.task.Notes }
+func (i *asanaIssue) GetLabels() []string   { return i.labels }
+func (i *asanaIssue) GetProviderID() string { return i.task.GID }
+func (i *asanaIssue) GetState() string {
+	if i.task.Completed {
+		return StateClosed
+	}
+	return StateOpen
+}
+
+// CreateIssue creates a task in the item's project, or in the default project, placing it in the
+// section mapped from its first label, assigned to the first assignee and, with a parent, as its
+// subtask. Milestones are not supported and ignored.
+func (p *AsanaProvider) CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	projectGID := p.project
+	if req.Project != nil && req.Project.ProjectID != "" {
+		projectGID = req.Project.ProjectID
+	}
+	labels := req.Labels
+
+	data := map[string]interface{}{
+		"name":  req.Title,
+		"notes": req.Body,
+	}
+	if len(req.Assignees) > 0 {
+		data["assignee"] = req.Assignees[0]
+	}
+	if req.Parent != 0 {
+		data["parent"] = strconv.Itoa(req.Parent)
+	}
+	var warnings []string
+	if projectGID != "" {
+		data["projects"] = []string{projectGID}
+		if len(labels) > 0 {
+			section, err := p.findSection(ctx, projectGID, labels[0])
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf("failed to look up the Asana section of label %q: %v", labels[0], err))
+			} else if section != "" {
+				data["memberships"] = []map[string]string{{"project": projectGID, "section": section}}
+			}
+		}
+	} else {
+		data["workspace"] = p.workspace
+	}
+
+	var task asanaTask
+	if err := p.do(ctx, http.MethodPost, "tasks?opt_fields="+asanaTaskFields, data, &task); err != nil {
+		return nil, fmt.Errorf("failed to create task: %w", err)
+	}
+	slog.Info("task created", "gid", task.GID, "url", task.PermalinkURL)
+
+	return &CreateIssueResult{Issue: &asanaIssue{task: task, labels: labels}, Warnings: warnings}, nil
+}
+
+// AddSubIssue makes the child task a subtask of the parent task.
+func (p *AsanaProvider) AddSubIssue(ctx context.Context, parentNumber int, childID int64) error {
+	data := map[string]interface{}{"parent": strconv.Itoa(parentNumber)}
+	path := fmt.Sprintf("tasks/%d/setParent", childID)
+	if err := p.do(ctx, http.MethodPost, path, data, nil); err != nil {
+		return fmt.Errorf("failed to set parent of task %d: %w", childID, err)
+	}
+	return nil
+}
+
+// BodyFormat returns the plain text formatter, as the notes of Asana tasks have no markup.
+func (p *AsanaProvider) BodyFormat() format.Formatter {
+	return format.Text{}
+}
+
+// EditIssue updates the name and/or notes of an existing task. Empty fields are left unchanged.
+func (p *AsanaProvider) EditIssue(ctx context.Context, number int, title, description string) (Issue, error) {
+	data := map[string]interface{}{}
+	if title != "" {
+		data["name"] = title
+	}
+	if description != "" {
+		data["notes"] = description
+	}
+
+	var task asanaTask
+	path := fmt.Sprintf("tasks/%d?opt_fields="+asanaTaskFields, number)
+	if err := p.do(ctx, http.MethodPut, path, data, &task); err != nil {
+		return nil, fmt.Errorf("failed to edit task %d: %w", number, err)
+	}
+	slog.Debug("task edited", "gid", task.GID)
+	return &asanaIssue{task: task}, nil
+}
+
+// DeleteIssue deletes an existing task, which Asana keeps in the trash of the workspace for 30
+// days.
+func (p *AsanaProvider) DeleteIssue(ctx context.Context, number int) error {
+	if err := p.do(ctx, http.MethodDelete, fmt.Sprintf("tasks/%d", number), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete task %d: %w", number, err)
+	}
+	slog.Debug("task deleted", "gid", number)
+	return nil
+}
+
+// SetEstimate stores the estimate in the configured number custom field. It is a no-op when no
+// estimate field is configured.
+func (p *AsanaProvider) SetEstimate(ctx context.Context, number int, points int) error {
+	if p.estimateField == "" {
+		return nil
+	}
+	data := map[string]interface{}{
+		"custom_fields": map[string]int{p.estimateField: points},
+	}
+	if err := p.do(ctx, http.MethodPut, fmt.Sprintf("tasks/%d", number), data, nil); err != nil {
+		return fmt.Errorf("failed to set estimate of task %d: %w", number, err)
+	}
+	return nil
+}
+
+// Capabilities returns the capabilities of Asana, where the sub-issues are subtasks and the labels
+// are mapped to sections. Projects are looked up by name only when a workspace is configured.
+func (p *AsanaProvider) Capabilities() Capabilities {
+	return Capabilities{Projects: p.workspace != "", SubIssues: true}
+}
+
+// GetProjectByName searches the configured workspace for a project with the given name.
+func (p *AsanaProvider) GetProjectByName(ctx context.Context, projectName string) (*ProjectInfo, error) {
+	if p.workspace == "" {
+		return nil, fmt.Errorf("an Asana workspace is required to look up projects by name")
+	}
+
+	path := fmt.Sprintf("workspaces/%s/projects?archived=false&limit=100", url.PathEscape(p.workspace))
+	for path != "" {
+		var projects []struct {
+			GID  string `json:"gid"`
+			Name string `json:"name"`
+		}
+		next, err := p.doPage(ctx, path, &projects)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		for _, project := range projects {
+			if project.Name == projectName {
+				slog.Info("found project", "name", project.Name, "gid", project.GID)
+				return &ProjectInfo{ProjectID: project.GID}, nil
+			}
+		}
+		path = next
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectName)
+}
+
+// CreateProject creates a project in the configured workspace.
+func (p *AsanaProvider) CreateProject(ctx context.Context, name string) (*ProjectInfo, error) {
+	if p.workspace == "" {
+		return nil, fmt.Errorf("an Asana workspace is required to create projects")
+	}
+	var project struct {
+		GID string `json:"gid"`
+	}
+	data := map[string]interface{}{"name": name, "workspace": p.workspace}
+	if err := p.do(ctx, http.MethodPost, "projects", data, &project); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	slog.Info("project created", "name", name, "gid", project.GID)
+	return &ProjectInfo{ProjectID: project.GID}, nil
+}
+
+// findSection returns the GID of the section mapped from the label, or an empty string when the
+// project has no such section. Sections are fetched once per project.
+func (p *AsanaProvider) findSection(ctx context.Context, projectGID, label string) (string, error) {
+	name := label
+	if mapped, ok := p.sections[label]; ok {
+		name = mapped
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sections, ok := p.sectionCache[projectGID]
+	if !ok {
+		var result []struct {
+			GID  string `json:"gid"`
+			Name string `json:"name"`
+		}
+		if err := p.do(ctx, http.MethodGet, fmt.Sprintf("projects/%s/sections", url.PathEscape(projectGID)), nil, &result); err != nil {
+			return "", err
+		}
+		sections = make(map[string]string, len(result))
+		for _, s := range result {
+			sections[s.Name] = s.GID
+		}
+		p.sectionCache[projectGID] = sections
+	}
+
+	gid, ok := sections[name]
+	if !ok {
+		slog.Debug("section not found in Asana project", "section", name, "project", projectGID)
+	}
+	return gid, nil
+}
+
+// doPage performs a GET request on a paginated collection and returns the path of the next page,
+// or an empty string on the last page.
+func (p *AsanaProvider) doPage(ctx context.Context, path string, out interface{}) (string, error) {
+	var envelope struct {
+		Data     json.RawMessage `json:"data"`
+		NextPage *struct {
+			Path string `json:"path"`
+		} `json:"next_page"`
+	}
+	if err := p.api.request(ctx, http.MethodGet, path, nil, &envelope); err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return "", fmt.Errorf("failed to decode response: %w", err)
+	}
+	if envelope.NextPage == nil {
+		return "", nil
+	}
+	return strings.TrimPrefix(envelope.NextPage.Path, "/"), nil
+}
+
+// do sends data wrapped in the Asana "data" envelope and decodes the "data" field of the response
+// into out, when out is not nil.
+func (p *AsanaProvider) do(ctx context.Context, method, path string, data, out interface{}) error {
+	var body interface{}
+	if data != nil {
+		body = map[string]interface{}{"data": data}
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := p.api.request(ctx, method, path, body, &envelope); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(envelope.Data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// asanaErrorMessage joins the messages of an Asana error response.
+func asanaErrorMessage(body []byte) string {
+	var apiErr struct {
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	messages := make([]string, len(apiErr.Errors))
+	for i, e := range apiErr.Errors {
+		messages[i] = e.Message
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,190 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// asanaRequest is a request received by the fake Asana server.
+type asanaRequest struct {
+	method string
+	path   string
+	data   map[string]interface{}
+}
+
+// newFakeAsanaProvider starts a fake Asana API that answers with the handler's response for each
+// request and records the requests it receives.
+func newFakeAsanaProvider(t *testing.T, config AsanaConfig, handler func(r *http.Request) (int, string)) (*AsanaProvider, *[]asanaRequest) {
+	t.Helper()
+	var requests []asanaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		var body struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, asanaRequest{method: r.Method, path: r.URL.Path, data: body.Data})
+
+		status, response := handler(r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	config.Token = "token"
+	config.BaseURL = server.URL + "/api/1.0"
+	p, err := NewAsanaProvider(config)
+	require.NoError(t, err)
+	return p, &requests
+}
+
+func TestAsanaProvider_CreateIssue_Section(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100", Sections: map[string]string{"User Story": "Backlog"}}, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/api/1.0/projects/100/sections" {
+			return http.StatusOK, `{"data":[{"gid":"7","name":"Backlog"},{"gid":"8","name":"Task"}]}`
+		}
+		return http.StatusCreated, `{"data":{"gid":"1201","name":"Story","notes":"Body","permalink_url":"https://app.asana.com/0/100/1201","completed":false}}`
+	})
+
+	issue, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1201, issue.GetNumber())
+	assert.Equal(t, int64(1201), issue.GetID())
+	assert.Equal(t, "https://app.asana.com/0/100/1201", issue.GetHTMLURL())
+	assert.Equal(t, "Body", issue.GetBody())
+	assert.Equal(t, StateOpen, issue.GetState())
+	assert.Equal(t, "1201", issue.GetProviderID())
+
+	_, err = p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Task", Body: "Body", Labels: []string{"Task"}})
+	require.NoError(t, err)
+
+	// Sections are fetched once and the task is placed in the mapped section
+	require.Len(t, *requests, 3)
+	created := (*requests)[1]
+	assert.Equal(t, "/api/1.0/tasks", created.path)
+	assert.Equal(t, []interface{}{"100"}, created.data["projects"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"project": "100", "section": "7"}}, created.data["memberships"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"project": "100", "section": "8"}}, (*requests)[2].data["memberships"])
+}
+
+func TestAsanaProvider_CreateIssue_ParentProject(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100"}, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/api/1.0/projects/200/sections" {
+			return http.StatusOK, `{"data":[]}`
+		}
+		return http.StatusCreated, `{"data":{"gid":"1202","name":"Story"}}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story"}, Project: &ProjectInfo{ProjectID: "200"}})
+	require.NoError(t, err)
+	created := (*requests)[1]
+	assert.Equal(t, []interface{}{"200"}, created.data["projects"])
+	assert.NotContains(t, created.data, "memberships")
+}
+
+func TestAsanaProvider_CreateIssue_Error(t *testing.T) {
+	p, _ := newFakeAsanaProvider(t, AsanaConfig{Workspace: "1"}, func(r *http.Request) (int, string) {
+		return http.StatusForbidden, `{"errors":[{"message":"Not authorized"}]}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status: 403")
+	assert.Contains(t, err.Error(), "Not authorized")
+}
+
+func TestAsanaProvider_AddSubIssue(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100"}, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"data":{}}`
+	})
+
+	require.NoError(t, p.AddSubIssue(context.Background(), 1201, 1300))
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "/api/1.0/tasks/1300/setParent", (*requests)[0].path)
+	assert.Equal(t, "1201", (*requests)[0].data["parent"])
+}
+
+func TestAsanaProvider_EditIssue(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100"}, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"data":{"gid":"1201","name":"Story","notes":"New body"}}`
+	})
+
+	issue, err := p.EditIssue(context.Background(), 1201, "", "New body")
+	require.NoError(t, err)
+	assert.Equal(t, "New body", issue.GetBody())
+	assert.Equal(t, http.MethodPut, (*requests)[0].method)
+	assert.Equal(t, map[string]interface{}{"notes": "New body"}, (*requests)[0].data)
+}
+
+func TestAsanaProvider_DeleteIssue(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100"}, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"data":{}}`
+	})
+
+	require.NoError(t, p.DeleteIssue(context.Background(), 1201))
+	assert.Equal(t, http.MethodDelete, (*requests)[0].method)
+	assert.Equal(t, "/api/1.0/tasks/1201", (*requests)[0].path)
+}
+
+func TestAsanaProvider_SetEstimate(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Project: "100", EstimateField: "55"}, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"data":{"gid":"1201"}}`
+	})
+
+	require.NoError(t, p.SetEstimate(context.Background(), 1201, 5))
+	require.Len(t, *requests, 1)
+	assert.Equal(t, "/api/1.0/tasks/1201", (*requests)[0].path)
+	assert.Equal(t, map[string]interface{}{"55": float64(5)}, (*requests)[0].data["custom_fields"])
+
+	// Without an estimate field nothing is sent
+	p.estimateField = ""
+	require.NoError(t, p.SetEstimate(context.Background(), 1201, 5))
+	assert.Len(t, *requests, 1)
+}
+
+func TestAsanaProvider_GetProjectByName(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Workspace: "1"}, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("offset") == "" {
+			return http.StatusOK, `{"data":[{"gid":"100","name":"Other"}],"next_page":{"offset":"abc","path":"/workspaces/1/projects?archived=false&limit=100&offset=abc"}}`
+		}
+		return http.StatusOK, `{"data":[{"gid":"200","name":"Payments"}],"next_page":null}`
+	})
+
+	project, err := p.GetProjectByName(context.Background(), "Payments")
+	require.NoError(t, err)
+	assert.Equal(t, "200", project.ProjectID)
+	assert.Len(t, *requests, 2)
+
+	_, err = p.GetProjectByName(context.Background(), "Missing")
+	assert.ErrorContains(t, err, "project not found: Missing")
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+}
+
+func TestAsanaProvider_CreateProject(t *testing.T) {
+	p, requests := newFakeAsanaProvider(t, AsanaConfig{Workspace: "1"}, func(r *http.Request) (int, string) {
+		return http.StatusCreated, `{"data":{"gid":"300","name":"Roadmap"}}`
+	})
+
+	project, err := p.CreateProject(context.Background(), "Roadmap")
+	require.NoError(t, err)
+	assert.Equal(t, "300", project.ProjectID)
+	assert.Equal(t, asanaRequest{method: http.MethodPost, path: "/api/1.0/projects", data: map[string]interface{}{"name": "Roadmap", "workspace": "1"}}, (*requests)[0])
+
+	p, _ = newFakeAsanaProvider(t, AsanaConfig{}, func(*http.Request) (int, string) { return http.StatusOK, "{}" })
+	_, err = p.CreateProject(context.Background(), "Roadmap")
+	assert.ErrorContains(t, err, "an Asana workspace is required to create projects")
+}
+
+// TestAsanaProvider_Capabilities tests that projects are only looked up with a workspace.
+func TestAsanaProvider_Capabilities(t *testing.T) {
+	p, _ := newFakeAsanaProvider(t, AsanaConfig{}, func(*http.Request) (int, string) { return http.StatusOK, "{}" })
+	assert.Equal(t, Capabilities{SubIssues: true}, p.Capabilities())
+	p, _ = newFakeAsanaProvider(t, AsanaConfig{Workspace: "ws"}, func(*http.Request) (int, string) { return http.StatusOK, "{}" })
+	assert.True(t, p.Capabilities().Projects)
+}
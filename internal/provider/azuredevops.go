@@ -0,0 +1,291 @@
+package provider
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/format"
+)
+
+// AzureDevOpsConfig holds the configuration for the Azure DevOps provider.
+type AzureDevOpsConfig struct {
+	URL           string // URL of the organization, e.g. https://dev.azure.com/acme, or of an Azure DevOps Server collection
+	Token         string // Personal access token with the Work Items read and write scope
+	Project       string
+	WorkItemTypes map[string]string // Work item type per label (item type), see workItemType
+	EstimateField string            // Reference name of the estimate field, Microsoft.VSTS.Scheduling.StoryPoints by default
+	States        map[string]string // Initial state (board column) per label (item type), the default state of the work item type when missing
+}
+
+// azureDevOpsAPIVersion is the version of the Azure DevOps REST API used by the provider.
+const azureDevOpsAPIVersion = "7.1"
+
+// defaultEstimateField is the story points field of the Agile process; the Scrum process estimates
+// its backlog items in Microsoft.VSTS.Scheduling.Effort instead.
+const defaultEstimateField = "Microsoft.VSTS.Scheduling.StoryPoints"
+
+// azureDevOpsWorkItemTypes are the work item types of the labels of the items, in the Agile process.
+var azureDevOpsWorkItemTypes = map[string]string{
+	"User Story": "User Story",
+	"Epic":       "Epic",
+	"Task":       "Task",
+}
+
+// AzureDevOpsProvider creates items as work items of an Azure Boards project, through the work item
+// tracking REST API. The Parent column selects the area path of the work items, and the sub-issues
+// are parent-child links.
+type AzureDevOpsProvider struct {
+	api           *restClient
+	project       string
+	workItemTypes map[string]string
+	estimateField string
+	states        map[string]string
+}
+
+// NewAzureDevOpsProvider creates a new AzureDevOpsProvider with the given configuration.
+func NewAzureDevOpsProvider(config AzureDevOpsConfig) (*AzureDevOpsProvider, error) {
+	if config.URL == "" || config.Project == "" {
+		return nil, fmt.Errorf("an Azure DevOps organization URL and project are required")
+	}
+	// Personal access tokens are sent as the password of a basic authentication without user
+	credentials := base64.StdEncoding.EncodeToString([]byte(":" + config.Token))
+	header := http.Header{"Authorization": {"Basic " + credentials}}
+	api, err := newRestClient("Azure DevOps", config.URL, header, azureDevOpsErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+	// The work items are created and updated with JSON Patch documents
+	api.contentType = "application/json-patch+json"
+
+	estimateField := config.EstimateField
+	if estimateField == "" {
+		estimateField = defaultEstimateField
+	}
+	return &AzureDevOpsProvider{
+		api:           api,
+		project:       config.Project,
+		workItemTypes: config.WorkItemTypes,
+		estimateField: estimateField,
+		states:        config.States,
+	}, nil
+}
+
+// BodyFormat returns the HTML formatter, as the descriptions of the work items are HTML.
+func (p *AzureDevOpsProvider) BodyFormat() format.Formatter {
+	return format.HTML{}
+}
+
+// azureWorkItem is the subset of the work item resource used by the provider.
+type azureWorkItem struct {
+	ID     int `json:"id"`
+	Fields struct {
+		Title       string `json:"System.Title"`
+		Description string `json:"System.Description"`
+		State       string `json:"System.State"`
+		Tags        string `json:"System.Tags"`
+	} `json:"fields"`
+	Links struct {
+		HTML struct {
+			Href string `json:"href"`
+		} `json:"html"`
+	} `json:"_links"`
+}
+
+func (i *azureWorkItem) GetNumber() int        { return i.ID }
+func (i *azureWorkItem) GetID() int64          { return int64(i.ID) }
+func (i *azureWorkItem) GetHTMLURL() string    { return i.Links.HTML.Href }
+func (i *azureWorkItem) GetTitle() string      { return i.Fields.Title }
+func (i *azureWorkItem) GetBody() string       { return i.Fields.Description }
+func (i *azureWorkItem) GetProviderID() string { return strconv.Itoa(i.ID) }
+func (i *azureWorkItem) GetLabels() []string {
+	var labels []string
+	for _, tag := range strings.Split(i.Fields.Tags, ";") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			labels = append(labels, tag)
+		}
+	}
+	return labels
+}
+func (i *azureWorkItem) GetState() string {
+	switch i.Fields.State {
+	case "":
+		return ""
+	case "Closed", "Done", "Removed", "Resolved":
+		return StateClosed
+	default:
+		return StateOpen
+	}
+}
+
+// patchOperation is an operation of the JSON Patch documents updating the work items.
+type patchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value any    `json:"value"`
+}
+
+// CreateIssue creates a work item of the type mapped from the first label, tagged with the labels,
+// in the state mapped from the first label and the area path of its project, assigned to its first
+// assignee and linked to its parent. Milestones are not supported and ignored.
+func (p *AzureDevOpsProvider) CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	workItemType := p.workItemType(req.Labels)
+	ops := []patchOperation{
+		{Op: "add", Path: "/fields/System.Title", Value: req.Title},
+		{Op: "add", Path: "/fields/System.Description", Value: req.Body},
+	}
+	if len(req.Labels) > 0 {
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.Tags", Value: strings.Join(req.Labels, "; ")})
+	}
+	if len(req.Labels) > 0 && p.states[req.Labels[0]] != "" {
+		// The state places the work item in the board column it is mapped to
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.State", Value: p.states[req.Labels[0]]})
+	}
+	if req.Project != nil && req.Project.ProjectID != "" {
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.AreaPath", Value: req.Project.ProjectID})
+	}
+	var warnings []string
+	if len(req.Assignees) > 0 {
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.AssignedTo", Value: req.Assignees[0]})
+		if len(req.Assignees) > 1 {
+			warnings = append(warnings, fmt.Sprintf("work items have a single assignee, %s is not assigned", strings.Join(req.Assignees[1:], ", ")))
+		}
+	}
+	if req.Parent != 0 {
+		ops = append(ops, p.parentLink(req.Parent))
+	}
+
+	var item azureWorkItem
+	path := p.path("workitems/" + url.PathEscape("$"+workItemType))
+	if err := p.api.request(ctx, http.MethodPost, path, ops, &item); err != nil {
+		return nil, fmt.Errorf("failed to create %s work item: %w", workItemType, err)
+	}
+	slog.Info("issue created", "number", item.ID, "type", workItemType, "url", item.GetHTMLURL())
+	return &CreateIssueResult{Issue: &item, Warnings: warnings}, nil
+}
+
+// AddSubIssue links the child work item to its parent.
+func (p *AzureDevOpsProvider) AddSubIssue(ctx context.Context, parentNumber int, childID int64) error {
+	ops := []patchOperation{p.parentLink(parentNumber)}
+	if err := p.api.request(ctx, http.MethodPatch, p.path(fmt.Sprintf("workitems/%d", childID)), ops, nil); err != nil {
+		return fmt.Errorf("failed to link work item #%d to its parent #%d: %w", childID, parentNumber, err)
+	}
+	return nil
+}
+
+// EditIssue updates the title and/or description of an existing work item. Empty fields are left
+// unchanged.
+func (p *AzureDevOpsProvider) EditIssue(ctx context.Context, number int, title, description string) (Issue, error) {
+	var ops []patchOperation
+	if title != "" {
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.Title", Value: title})
+	}
+	if description != "" {
+		ops = append(ops, patchOperation{Op: "add", Path: "/fields/System.Description", Value: description})
+	}
+	var item azureWorkItem
+	if err := p.api.request(ctx, http.MethodPatch, p.path(fmt.Sprintf("workitems/%d", number)), ops, &item); err != nil {
+		return nil, fmt.Errorf("failed to edit work item #%d: %w", number, err)
+	}
+	slog.Debug("issue edited", "number", number)
+	return &item, nil
+}
+
+// GetIssue reads an existing work item.
+func (p *AzureDevOpsProvider) GetIssue(ctx context.Context, number int) (Issue, error) {
+	var item azureWorkItem
+	if err := p.api.request(ctx, http.MethodGet, p.path(fmt.Sprintf("workitems/%d", number)), nil, &item); err != nil {
+		return nil, fmt.Errorf("failed to get work item #%d: %w", number, err)
+	}
+	return &item, nil
+}
+
+// DeleteIssue moves an existing work item to the recycle bin of the project.
+func (p *AzureDevOpsProvider) DeleteIssue(ctx context.Context, number int) error {
+	if err := p.api.request(ctx, http.MethodDelete, p.path(fmt.Sprintf("workitems/%d", number)), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete work item #%d: %w", number, err)
+	}
+	slog.Debug("issue deleted", "number", number)
+	return nil
+}
+
+// SetEstimate records the estimate of a work item in the estimate field.
+func (p *AzureDevOpsProvider) SetEstimate(ctx context.Context, number int, points int) error {
+	ops := []patchOperation{{Op: "add", Path: "/fields/" + p.estimateField, Value: points}}
+	if err := p.api.request(ctx, http.MethodPatch, p.path(fmt.Sprintf("workitems/%d", number)), ops, nil); err != nil {
+		return fmt.Errorf("failed to set the estimate of work item #%d: %w", number, err)
+	}
+	return nil
+}
+
+// GetProjectByName finds the area of the project with the given name, directly under the root
+// area, whose path the work items are created in.
+func (p *AzureDevOpsProvider) GetProjectByName(ctx context.Context, projectName string) (*ProjectInfo, error) {
+	var area struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+	path := p.path("classificationnodes/Areas/" + url.PathEscape(projectName))
+	if err := p.api.request(ctx, http.MethodGet, path, nil, &area); err != nil {
+		if isNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectName)
+		}
+		return nil, fmt.Errorf("failed to get area %q: %w", projectName, err)
+	}
+	slog.Info("found area", "name", area.Name, "id", area.ID)
+	return &ProjectInfo{ProjectID: p.project + `\` + area.Name, ProjectNumber: area.ID}, nil
+}
+
+// azureDevOpsMaxTitleSize is the maximum characters of the title of a work item.
+const azureDevOpsMaxTitleSize = 255
+
+// Capabilities returns the capabilities of Azure DevOps, whose areas group the work items and whose
+// labels are tags, the first one also mapped to the work item type.
+func (p *AzureDevOpsProvider) Capabilities() Capabilities {
+	return Capabilities{Projects: true, SubIssues: true, Labels: true, MaxTitleSize: azureDevOpsMaxTitleSize}
+}
+
+// workItemType returns the work item type of the first label: the configured one, the type of the
+// same name for the built-in item types, or Task.
+func (p *AzureDevOpsProvider) workItemType(labels []string) string {
+	if len(labels) == 0 {
+		return "Task"
+	}
+	if t := p.workItemTypes[labels[0]]; t != "" {
+		return t
+	}
+	if t := azureDevOpsWorkItemTypes[labels[0]]; t != "" {
+		return t
+	}
+	return "Task"
+}
+
+// parentLink returns the operation linking a work item to its parent.
+func (p *AzureDevOpsProvider) parentLink(parent int) patchOperation {
+	return patchOperation{Op: "add", Path: "/relations/-", Value: map[string]any{
+		"rel": "System.LinkTypes.Hierarchy-Reverse",
+		"url": p.api.baseURL.JoinPath("_apis/wit/workItems", strconv.Itoa(parent)).String(),
+	}}
+}
+
+// path returns the path of a work item tracking resource of the project, with the API version.
+func (p *AzureDevOpsProvider) path(resource string) string {
+	return url.PathEscape(p.project) + "/_apis/wit/" + resource + "?api-version=" + azureDevOpsAPIVersion
+}
+
+// azureDevOpsErrorMessage extracts the message of an Azure DevOps error response.
+func azureDevOpsErrorMessage(body []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return apiErr.Message
+}
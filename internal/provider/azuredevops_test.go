@@ -0,0 +1,161 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// azureRequest is a request received by the fake Azure DevOps server.
+type azureRequest struct {
+	method string
+	path   string
+	body   []patchOperation
+}
+
+// newFakeAzureDevOpsProvider starts a fake Azure DevOps API that answers with the handler's
+// response for each request and records the requests it receives.
+func newFakeAzureDevOpsProvider(t *testing.T, handler func(r *http.Request) (int, string)) (*AzureDevOpsProvider, *[]azureRequest) {
+	t.Helper()
+	var requests []azureRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Basic OnNlY3JldA==", r.Header.Get("Authorization"))
+		assert.Equal(t, azureDevOpsAPIVersion, r.URL.Query().Get("api-version"))
+		var body []patchOperation
+		if r.Body != nil && r.ContentLength > 0 {
+			assert.Equal(t, "application/json-patch+json", r.Header.Get("Content-Type"))
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		requests = append(requests, azureRequest{method: r.Method, path: r.URL.Path, body: body})
+
+		status, response := handler(r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := NewAzureDevOpsProvider(AzureDevOpsConfig{
+		URL:           server.URL + "/acme/",
+		Token:         "secret",
+		Project:       "Shop App",
+		WorkItemTypes: map[string]string{"User Story": "Product Backlog Item"},
+		States:        map[string]string{"User Story": "Approved"},
+	})
+	require.NoError(t, err)
+	return p, &requests
+}
+
+// operation returns the value of the operation of a JSON Patch document on path.
+func operation(ops []patchOperation, path string) any {
+	for _, op := range ops {
+		if op.Path == path {
+			return op.Value
+		}
+	}
+	return nil
+}
+
+func TestAzureDevOpsProvider_CreateIssue(t *testing.T) {
+	p, requests := newFakeAzureDevOpsProvider(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{"id":42,"fields":{"System.Title":"Story","System.Description":"<p>Body</p>","System.State":"New","System.Tags":"User Story; checkout"},"_links":{"html":{"href":"https://dev.azure.com/acme/Shop%20App/_workitems/edit/42"}}}`
+	})
+
+	issue, err := p.CreateIssue(context.Background(), CreateIssueRequest{
+		Title:     "Story",
+		Body:      "<p>Body</p>",
+		Labels:    []string{"User Story", "checkout"},
+		Assignees: []string{"ana@example.com", "bob@example.com"},
+		Project:   &ProjectInfo{ProjectID: `Shop App\Checkout`},
+		Parent:    7,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 42, issue.GetNumber())
+	assert.Equal(t, int64(42), issue.GetID())
+	assert.Equal(t, "https://dev.azure.com/acme/Shop%20App/_workitems/edit/42", issue.GetHTMLURL())
+	assert.Equal(t, []string{"User Story", "checkout"}, issue.GetLabels())
+	assert.Equal(t, StateOpen, issue.GetState())
+	assert.Equal(t, []string{"work items have a single assignee, bob@example.com is not assigned"}, issue.Warnings)
+
+	require.Len(t, *requests, 1)
+	req := (*requests)[0]
+	assert.Equal(t, http.MethodPost, req.method)
+	assert.Equal(t, "/acme/Shop App/_apis/wit/workitems/$Product Backlog Item", req.path)
+	assert.Equal(t, "Story", operation(req.body, "/fields/System.Title"))
+	assert.Equal(t, "User Story; checkout", operation(req.body, "/fields/System.Tags"))
+	assert.Equal(t, "Approved", operation(req.body, "/fields/System.State"))
+	assert.Equal(t, `Shop App\Checkout`, operation(req.body, "/fields/System.AreaPath"))
+	assert.Equal(t, "ana@example.com", operation(req.body, "/fields/System.AssignedTo"))
+	link := operation(req.body, "/relations/-").(map[string]any)
+	assert.Equal(t, "System.LinkTypes.Hierarchy-Reverse", link["rel"])
+	assert.Contains(t, link["url"], "/acme/_apis/wit/workItems/7")
+}
+
+func TestAzureDevOpsProvider_WorkItemType(t *testing.T) {
+	p, _ := newFakeAzureDevOpsProvider(t, func(*http.Request) (int, string) { return http.StatusOK, `{}` })
+	assert.Equal(t, "Product Backlog Item", p.workItemType([]string{"User Story"}))
+	assert.Equal(t, "Epic", p.workItemType([]string{"Epic"}))
+	assert.Equal(t, "Task", p.workItemType([]string{"QA"}))
+	assert.Equal(t, "Task", p.workItemType(nil))
+	assert.Equal(t, format.HTML{}, p.BodyFormat())
+}
+
+func TestAzureDevOpsProvider_CreateIssue_Error(t *testing.T) {
+	p, _ := newFakeAzureDevOpsProvider(t, func(*http.Request) (int, string) {
+		return http.StatusBadRequest, `{"message":"TF401326: Invalid field status 'InvalidListValue' for field 'System.AreaPath'."}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Task", Labels: []string{"Task"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to create Task work item")
+	assert.Contains(t, err.Error(), "status: 400")
+	assert.Contains(t, err.Error(), "TF401326")
+}
+
+func TestAzureDevOpsProvider_UpdateWorkItems(t *testing.T) {
+	p, requests := newFakeAzureDevOpsProvider(t, func(*http.Request) (int, string) {
+		return http.StatusOK, `{"id":42,"fields":{"System.Title":"Renamed","System.State":"Closed"}}`
+	})
+	ctx := context.Background()
+
+	require.NoError(t, p.AddSubIssue(ctx, 7, 42))
+	issue, err := p.EditIssue(ctx, 42, "Renamed", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", issue.GetTitle())
+	assert.Equal(t, StateClosed, issue.GetState())
+	require.NoError(t, p.SetEstimate(ctx, 42, 5))
+	require.NoError(t, p.DeleteIssue(ctx, 42))
+
+	require.Len(t, *requests, 4)
+	for _, req := range *requests {
+		assert.Equal(t, "/acme/Shop App/_apis/wit/workitems/42", req.path)
+	}
+	assert.Equal(t, http.MethodPatch, (*requests)[0].method)
+	assert.NotNil(t, operation((*requests)[0].body, "/relations/-"))
+	assert.Equal(t, []patchOperation{{Op: "add", Path: "/fields/System.Title", Value: "Renamed"}}, (*requests)[1].body)
+	assert.Equal(t, float64(5), operation((*requests)[2].body, "/fields/Microsoft.VSTS.Scheduling.StoryPoints"))
+	assert.Equal(t, http.MethodDelete, (*requests)[3].method)
+}
+
+func TestAzureDevOpsProvider_GetProjectByName(t *testing.T) {
+	p, requests := newFakeAzureDevOpsProvider(t, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/acme/Shop App/_apis/wit/classificationnodes/Areas/Checkout" {
+			return http.StatusOK, `{"id":310,"name":"Checkout","structureType":"area"}`
+		}
+		return http.StatusNotFound, `{"message":"VS402485: The node name is not valid."}`
+	})
+
+	project, err := p.GetProjectByName(context.Background(), "Checkout")
+	require.NoError(t, err)
+	assert.Equal(t, `Shop App\Checkout`, project.ProjectID)
+	assert.Equal(t, 310, project.ProjectNumber)
+
+	_, err = p.GetProjectByName(context.Background(), "Missing")
+	assert.ErrorIs(t, err, ErrProjectNotFound)
+	assert.Len(t, *requests, 2)
+}
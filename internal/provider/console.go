@@ -2,16 +2,226 @@ package provider
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"io"
+	"sync"
+
+	"github.com/leocomelli/aigile/internal/format"
 )
 
 // Provider is the interface for issue providers (GitHub, Console, etc).
 type Provider interface {
-	CreateIssue(title, description string, labels []string, project *ProjectInfo) (Issue, error)
-	AddSubIssue(parentNumber int, childID int64) error
+	CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error)
+	AddSubIssue(ctx context.Context, parentNumber int, childID int64) error
+	EditIssue(ctx context.Context, number int, title, description string) (Issue, error)
 	GetProjectByName(ctx context.Context, projectName string) (*ProjectInfo, error)
+	Capabilities() Capabilities
+}
+
+// CreateIssueRequest is an issue to create. Providers ignore the fields they do not support.
+type CreateIssueRequest struct {
+	Title     string
+	Body      string
+	Labels    []string
+	Assignees []string          // Logins (or emails) of the assignees
+	Milestone string            // Title of the milestone
+	Project   *ProjectInfo      // Project the issue is added to, nil for none
+	Parent    int               // Number of the parent issue the new issue is linked to, 0 for none
+	Metadata  map[string]string // Custom values of the item, e.g. its X-<name> columns
+}
+
+// CreateIssueResult is an issue created by a provider.
+type CreateIssueResult struct {
+	Issue
+	ProjectItemID string   // Item of the issue in the project, when the provider reports it
+	Warnings      []string // Problems that did not prevent creating the issue, e.g. a failed link to its project or parent
+}
+
+// Capabilities describes what an issue provider supports, so the pipeline skips the operations a
+// provider cannot perform and fits the issues in its limits, rather than trying and warning.
+type Capabilities struct {
+	Projects     bool // GetProjectByName finds the project (or board) the issues are added to
+	SubIssues    bool // AddSubIssue links a child issue to its parent
+	Milestones   bool // The issues can be listed by milestone
+	Labels       bool // The labels are set on the issues, rather than mapped to other fields
+	MaxTitleSize int  // Maximum characters of an issue title, 0 when unlimited
+	MaxBodySize  int  // Maximum characters of an issue body, 0 when unlimited
+}
+
+// Permissions are the permissions a run needs from the credentials of an issue provider.
+type Permissions struct {
+	Issues   bool // Create and edit issues, with their labels, assignees and milestones
+	Projects bool // Add the issues to the projects of the owner
+}
+
+// PermissionChecker is implemented by providers that can check the permissions of their
+// credentials before a run, so a missing one fails the run before the first item rather than on it.
+type PermissionChecker interface {
+	CheckPermissions(ctx context.Context, needs Permissions) error
+}
+
+// Estimator is implemented by providers that can record the estimate of an issue.
+type Estimator interface {
+	SetEstimate(ctx context.Context, number int, points int) error
+}
+
+// IssueGetter is implemented by providers that can read existing issues.
+type IssueGetter interface {
+	GetIssue(ctx context.Context, number int) (Issue, error)
+}
+
+// IssueFilter selects the issues listed by an IssueLister.
+type IssueFilter struct {
+	Labels    []string // Labels the issues must all have
+	State     string   // open (default), closed or all
+	Milestone string   // Title of the milestone the issues belong to, any when empty
+}
+
+// IssueLister is implemented by providers that can list existing issues.
+type IssueLister interface {
+	ListIssues(ctx context.Context, filter IssueFilter) ([]Issue, error)
+}
+
+// Commenter is implemented by providers that can comment on existing issues.
+type Commenter interface {
+	AddComment(ctx context.Context, number int, body string) error
+}
+
+// ProjectItem is an open issue of a project board with the values of its fields.
+type ProjectItem struct {
+	Number   int
+	Title    string
+	URL      string
+	Labels   []string
+	Status   string  // Value of the Status field, empty when unset
+	Estimate float64 // Value of the estimate field, 0 when unset
+}
+
+// ProjectReader is implemented by providers that can list the items of a project board, in the
+// order of the board.
+type ProjectReader interface {
+	ListProjectItems(ctx context.Context, project *ProjectInfo, estimateField string) ([]ProjectItem, error)
+}
+
+// ProjectField is a field of a project board, with the options of the single select fields and the
+// iterations of the iteration fields.
+type ProjectField struct {
+	ID       string
+	Name     string
+	DataType string // e.g. TEXT, NUMBER, DATE, SINGLE_SELECT or ITERATION
+	Options  []FieldOption
+}
+
+// FieldOption is an option of a single select field or an iteration of an iteration field.
+type FieldOption struct {
+	ID        string
+	Name      string
+	StartDate string // Start of the iteration, e.g. 2024-05-06, empty for other fields
+	Duration  int    // Days of the iteration, 0 for other fields
+}
+
+// ProjectFieldLister is implemented by providers that can list the fields of a project board.
+type ProjectFieldLister interface {
+	ListProjectFields(ctx context.Context, project *ProjectInfo) ([]ProjectField, error)
+}
+
+// ErrProjectNotFound is returned by GetProjectByName when no project has the name.
+var ErrProjectNotFound = errors.New("project not found")
+
+// ProjectCreator is implemented by providers that can create the project (or board) named in the
+// Parent of a row when GetProjectByName does not find it.
+type ProjectCreator interface {
+	CreateProject(ctx context.Context, name string) (*ProjectInfo, error)
+}
+
+// ProjectDeleter is implemented by providers that can delete a project (or board), e.g. the
+// temporary ones of a self-test.
+type ProjectDeleter interface {
+	DeleteProject(ctx context.Context, project *ProjectInfo) error
+}
+
+// IssueCloser is implemented by providers that can close existing issues.
+type IssueCloser interface {
+	CloseIssue(ctx context.Context, number int) error
+}
+
+// IssueDeleter is implemented by providers that can delete existing issues, e.g. to roll back the
+// issues of a failed run.
+type IssueDeleter interface {
+	DeleteIssue(ctx context.Context, number int) error
 }
 
+// DraftCreator is implemented by providers that can create draft items in a project board, for the
+// ideas not ready to be issues.
+type DraftCreator interface {
+	CreateDraftItem(ctx context.Context, title, description string, project *ProjectInfo) (Issue, error)
+}
+
+// DraftItem is a draft item of a project board. It has no number, ID nor URL, as it is not an
+// issue of the repository.
+type DraftItem struct {
+	ItemID      string // Node ID of the project item, empty in the console
+	title       string
+	description string
+}
+
+// GetNumber returns 0, draft items have no number.
+func (d *DraftItem) GetNumber() int { return 0 }
+
+// GetID returns 0, draft items have no issue ID.
+func (d *DraftItem) GetID() int64 { return 0 }
+
+// GetHTMLURL returns an empty string, draft items have no URL.
+func (d *DraftItem) GetHTMLURL() string { return "" }
+
+// GetTitle returns the title of the draft item.
+func (d *DraftItem) GetTitle() string { return d.title }
+
+// GetBody returns the description of the draft item.
+func (d *DraftItem) GetBody() string { return d.description }
+
+// GetLabels returns nil, draft items have no labels.
+func (d *DraftItem) GetLabels() []string { return nil }
+
+// GetState returns StateOpen, draft items cannot be closed.
+func (d *DraftItem) GetState() string { return StateOpen }
+
+// GetProviderID returns the node ID of the project item.
+func (d *DraftItem) GetProviderID() string { return d.ItemID }
+
+// Change is a set of files proposed to the repository in a new branch, for review in a pull request.
+type Change struct {
+	Branch  string            // Name of the branch created from the default branch
+	Message string            // Commit message of the files
+	Title   string            // Title of the pull request
+	Body    string            // Description of the pull request
+	Files   map[string]string // Content of the files by path
+}
+
+// ChangeProposer is implemented by providers that can open pull requests, returned as issues.
+type ChangeProposer interface {
+	ProposeChange(ctx context.Context, change Change) (Issue, error)
+}
+
+// BodyFormatter is implemented by providers whose issue bodies use a markup other than Markdown.
+type BodyFormatter interface {
+	BodyFormat() format.Formatter
+}
+
+// FormatterOf returns the formatter of the issue bodies of a provider, Markdown by default.
+func FormatterOf(p Provider) format.Formatter {
+	if f, ok := p.(BodyFormatter); ok {
+		return f.BodyFormat()
+	}
+	return format.Markdown{}
+}
+
+// States of the issues returned by providers.
+const (
+	StateOpen   = "open"
+	StateClosed = "closed"
+)
+
 // Issue is the interface for issue objects returned by providers.
 type Issue interface {
 	GetNumber() int
@@ -20,16 +230,34 @@ type Issue interface {
 	GetTitle() string
 	GetBody() string
 	GetLabels() []string
+	GetState() string      // StateOpen or StateClosed, empty when the provider does not report it
+	GetProviderID() string // Identifier of the issue in the provider API, e.g. the GraphQL node ID of GitHub
 }
 
 // ConsoleProvider implements a provider that prints issues to the console instead of creating them externally.
-type ConsoleProvider struct{}
+type ConsoleProvider struct {
+	output    ConsoleOutput
+	color     bool
+	asciiOnly bool
+	w         io.Writer // Defaults to os.Stdout
 
-// NewConsoleProvider creates a new ConsoleProvider.
+	mu sync.Mutex // Keeps the records of concurrent calls apart
+}
+
+// NewConsoleProvider creates a new ConsoleProvider printing plain text previews.
 func NewConsoleProvider() *ConsoleProvider {
 	return &ConsoleProvider{}
 }
 
+// NewConsoleProviderWithConfig creates a new ConsoleProvider with the given configuration.
+func NewConsoleProviderWithConfig(config ConsoleConfig) (*ConsoleProvider, error) {
+	output, err := ParseConsoleOutput(string(config.Output))
+	if err != nil {
+		return nil, err
+	}
+	return &ConsoleProvider{output: output, color: config.Color, asciiOnly: config.ASCII, w: config.Writer}, nil
+}
+
 // ConsoleIssue is a struct to mimic the GitHub Issue for compatibility.
 type ConsoleIssue struct {
 	title       string
@@ -55,22 +283,56 @@ func (i *ConsoleIssue) GetBody() string { return i.description }
 // GetLabels returns the issue labels.
 func (i *ConsoleIssue) GetLabels() []string { return i.labels }
 
+// GetState returns the issue state (always empty for ConsoleIssue).
+func (i *ConsoleIssue) GetState() string { return "" }
+
+// GetProviderID returns the issue provider ID (always empty for ConsoleIssue).
+func (i *ConsoleIssue) GetProviderID() string { return "" }
+
 // CreateIssue prints the issue data to the console and returns a ConsoleIssue.
-func (p *ConsoleProvider) CreateIssue(title, description string, labels []string, project *ProjectInfo) (Issue, error) {
-	fmt.Println("\n[CONSOLE PROVIDER] Issue Preview:")
-	fmt.Println("Title:", title)
-	fmt.Println("Labels:", labels)
-	fmt.Println("Description:\n" + description)
-	if project != nil {
-		fmt.Printf("Project: %v\n", project)
+func (p *ConsoleProvider) CreateIssue(_ context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	err := p.print(consoleRecord{
+		Action:      consoleCreate,
+		Title:       req.Title,
+		Labels:      req.Labels,
+		Assignees:   req.Assignees,
+		Milestone:   req.Milestone,
+		Description: req.Body,
+		Metadata:    req.Metadata,
+		Project:     req.Project,
+		Parent:      req.Parent,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CreateIssueResult{Issue: &ConsoleIssue{title: req.Title, description: req.Body, labels: req.Labels}}, nil
+}
+
+// CreateDraftItem prints the draft item data to the console and returns a DraftItem.
+func (p *ConsoleProvider) CreateDraftItem(_ context.Context, title, description string, project *ProjectInfo) (Issue, error) {
+	if err := p.print(consoleRecord{Action: consoleDraft, Title: title, Description: description, Project: project}); err != nil {
+		return nil, err
+	}
+	return &DraftItem{title: title, description: description}, nil
+}
+
+// AddSubIssue prints the link of a sub-issue to its parent to the console.
+func (p *ConsoleProvider) AddSubIssue(_ context.Context, parentNumber int, childID int64) error {
+	return p.print(consoleRecord{Action: consoleLink, Parent: parentNumber, Child: childID})
+}
+
+// EditIssue prints the updated issue data to the console. Empty fields are left unchanged.
+func (p *ConsoleProvider) EditIssue(_ context.Context, number int, title, description string) (Issue, error) {
+	if err := p.print(consoleRecord{Action: consoleEdit, Number: number, Title: title, Description: description}); err != nil {
+		return nil, err
 	}
-	return &ConsoleIssue{title: title, description: description, labels: labels}, nil
+	return &ConsoleIssue{title: title, description: description}, nil
 }
 
-// AddSubIssue is a no-op for the console provider.
-func (p *ConsoleProvider) AddSubIssue(parentNumber int, childID int64) error {
-	fmt.Printf("[CONSOLE PROVIDER] Would link sub-issue %d to parent %d\n", childID, parentNumber)
-	return nil
+// Capabilities returns the capabilities of the console provider, which previews the links to the
+// sub-issues but resolves no projects.
+func (p *ConsoleProvider) Capabilities() Capabilities {
+	return Capabilities{SubIssues: true, Labels: true}
 }
 
 // GetProjectByName is a no-op for the console provider.
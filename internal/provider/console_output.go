@@ -0,0 +1,277 @@
+package provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/leocomelli/aigile/internal/emoji"
+	"gopkg.in/yaml.v3"
+)
+
+// ConsoleOutput is the rendering of the issues printed by the console provider.
+type ConsoleOutput string
+
+// Console outputs.
+const (
+	ConsoleText   ConsoleOutput = "text"   // Plain text previews (default)
+	ConsolePretty ConsoleOutput = "pretty" // Boxed previews, colored when enabled
+	ConsoleJSON   ConsoleOutput = "json"   // One JSON record per line, for jq and other tools
+	ConsoleYAML   ConsoleOutput = "yaml"   // One YAML document per record
+)
+
+// ConsoleConfig holds the configuration for the console provider.
+type ConsoleConfig struct {
+	Output ConsoleOutput
+	Color  bool      // Whether the pretty output uses ANSI colors
+	ASCII  bool      // Whether the text and pretty outputs replace emoji and symbols with ASCII
+	Writer io.Writer // Destination of the output, defaults to os.Stdout
+}
+
+// ParseConsoleOutput parses the name of a console output, empty for the default.
+func ParseConsoleOutput(name string) (ConsoleOutput, error) {
+	switch output := ConsoleOutput(strings.ToLower(strings.TrimSpace(name))); output {
+	case "":
+		return ConsoleText, nil
+	case ConsoleText, ConsolePretty, ConsoleJSON, ConsoleYAML:
+		return output, nil
+	default:
+		return "", fmt.Errorf("unsupported console output %q (supported: text, pretty, json, yaml)", name)
+	}
+}
+
+// Actions of the console records.
+const (
+	consoleCreate = "create"
+	consoleEdit   = "edit"
+	consoleLink   = "link"
+	consoleDraft  = "draft"
+)
+
+// consoleRecord is an operation performed by the console provider, as printed in the machine
+// readable outputs.
+type consoleRecord struct {
+	Action      string            `json:"action" yaml:"action"`
+	Number      int               `json:"number,omitempty" yaml:"number,omitempty"`
+	Parent      int               `json:"parent,omitempty" yaml:"parent,omitempty"`
+	Child       int64             `json:"child,omitempty" yaml:"child,omitempty"`
+	Title       string            `json:"title,omitempty" yaml:"title,omitempty"`
+	Labels      []string          `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Assignees   []string          `json:"assignees,omitempty" yaml:"assignees,omitempty"`
+	Milestone   string            `json:"milestone,omitempty" yaml:"milestone,omitempty"`
+	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty" yaml:"metadata,omitempty"`
+	Project     *ProjectInfo      `json:"-" yaml:"-"`
+	ProjectInfo *consoleProject   `json:"project,omitempty" yaml:"project,omitempty"`
+}
+
+// consoleProject is the project of a console record.
+type consoleProject struct {
+	Number int    `json:"number" yaml:"number"`
+	Owner  string `json:"owner" yaml:"owner"`
+	ID     string `json:"id,omitempty" yaml:"id,omitempty"`
+}
+
+// print writes a record in the configured output.
+func (p *ConsoleProvider) print(r consoleRecord) error {
+	w := p.w
+	if w == nil {
+		w = os.Stdout
+	}
+	if r.Project != nil {
+		r.ProjectInfo = &consoleProject{Number: r.Project.ProjectNumber, Owner: r.Project.ProjectOwner, ID: r.Project.ProjectID}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var err error
+	switch p.output {
+	case ConsoleJSON:
+		enc := json.NewEncoder(w)
+		enc.SetEscapeHTML(false)
+		err = enc.Encode(r)
+	case ConsoleYAML:
+		var data []byte
+		if data, err = yaml.Marshal(r); err == nil {
+			_, err = fmt.Fprintf(w, "---\n%s", data)
+		}
+	case ConsolePretty:
+		_, err = io.WriteString(w, p.ascii(p.pretty(p.asciiRecord(r))))
+	default:
+		_, err = io.WriteString(w, p.ascii(plainRecord(p.asciiRecord(r))))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to print issue: %w", err)
+	}
+	return nil
+}
+
+// asciiRecord replaces the emoji of the texts of a record in ASCII mode, before they are laid out.
+func (p *ConsoleProvider) asciiRecord(r consoleRecord) consoleRecord {
+	if !p.asciiOnly {
+		return r
+	}
+	r.Title = emoji.ASCII(r.Title)
+	r.Description = emoji.ASCII(r.Description)
+	labels := make([]string, len(r.Labels))
+	for i, label := range r.Labels {
+		labels[i] = emoji.ASCII(label)
+	}
+	r.Labels = labels
+	return r
+}
+
+// ascii replaces the symbols of the rendered output, such as the box borders, in ASCII mode.
+func (p *ConsoleProvider) ascii(text string) string {
+	if !p.asciiOnly {
+		return text
+	}
+	return emoji.ASCII(text)
+}
+
+// plainRecord renders a record as a plain text preview.
+func plainRecord(r consoleRecord) string {
+	var sb strings.Builder
+	switch r.Action {
+	case consoleLink:
+		fmt.Fprintf(&sb, "[CONSOLE PROVIDER] Would link sub-issue %d to parent %d\n", r.Child, r.Parent)
+	case consoleEdit:
+		fmt.Fprintf(&sb, "\n[CONSOLE PROVIDER] Issue #%d Update:\n", r.Number)
+		if r.Title != "" {
+			sb.WriteString("Title: " + r.Title + "\n")
+		}
+		if r.Description != "" {
+			sb.WriteString("Description:\n" + r.Description + "\n")
+		}
+	case consoleDraft:
+		sb.WriteString("\n[CONSOLE PROVIDER] Draft Item Preview:\n")
+		sb.WriteString("Title: " + r.Title + "\n")
+		sb.WriteString("Description:\n" + r.Description + "\n")
+		if r.Project != nil {
+			fmt.Fprintf(&sb, "Project: %v\n", r.Project)
+		}
+	default:
+		sb.WriteString("\n[CONSOLE PROVIDER] Issue Preview:\n")
+		sb.WriteString("Title: " + r.Title + "\n")
+		fmt.Fprintf(&sb, "Labels: %v\n", r.Labels)
+		if len(r.Assignees) > 0 {
+			fmt.Fprintf(&sb, "Assignees: %v\n", r.Assignees)
+		}
+		if r.Milestone != "" {
+			sb.WriteString("Milestone: " + r.Milestone + "\n")
+		}
+		sb.WriteString("Description:\n" + r.Description + "\n")
+		if r.Project != nil {
+			fmt.Fprintf(&sb, "Project: %v\n", r.Project)
+		}
+	}
+	return sb.String()
+}
+
+// ANSI styles of the pretty output.
+const (
+	styleBold   = "\x1b[1m"
+	styleDim    = "\x1b[2m"
+	styleCyan   = "\x1b[36m"
+	styleYellow = "\x1b[33m"
+	styleReset  = "\x1b[0m"
+)
+
+// prettyWidth is the width the text of the pretty boxes is wrapped at.
+const prettyWidth = 80
+
+// boxLine is a line of a pretty box, with the style it is printed in.
+type boxLine struct {
+	text  string
+	style string
+}
+
+// pretty renders a record as a box, or as a single line for links.
+func (p *ConsoleProvider) pretty(r consoleRecord) string {
+	if r.Action == consoleLink {
+		return p.styled(fmt.Sprintf("↳ sub-issue %d linked to #%d\n", r.Child, r.Parent), styleDim)
+	}
+
+	header := "New issue"
+	switch r.Action {
+	case consoleEdit:
+		header = fmt.Sprintf("Issue #%d updated", r.Number)
+	case consoleDraft:
+		header = "New draft item"
+	}
+	var lines []boxLine
+	for _, line := range wrapText(r.Title, prettyWidth) {
+		lines = append(lines, boxLine{line, styleBold + styleCyan})
+	}
+	if len(r.Labels) > 0 {
+		lines = append(lines, boxLine{"Labels: " + strings.Join(r.Labels, ", "), styleYellow})
+	}
+	if len(r.Assignees) > 0 {
+		lines = append(lines, boxLine{"Assignees: " + strings.Join(r.Assignees, ", "), styleYellow})
+	}
+	if r.Milestone != "" {
+		lines = append(lines, boxLine{"Milestone: " + r.Milestone, styleYellow})
+	}
+	if r.Project != nil {
+		lines = append(lines, boxLine{fmt.Sprintf("Project: %s #%d", r.Project.ProjectOwner, r.Project.ProjectNumber), styleYellow})
+	}
+	if r.Description != "" {
+		if len(lines) > 0 {
+			lines = append(lines, boxLine{})
+		}
+		for _, line := range wrapText(r.Description, prettyWidth) {
+			lines = append(lines, boxLine{text: line})
+		}
+	}
+	return p.box(header, lines)
+}
+
+// box draws the lines in a box titled header.
+func (p *ConsoleProvider) box(header string, lines []boxLine) string {
+	width := utf8.RuneCountInString(header) + 2
+	for _, line := range lines {
+		width = max(width, utf8.RuneCountInString(line.text))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n" + p.styled("╭─ ", styleDim) + p.styled(header, styleBold) + " " +
+		p.styled(strings.Repeat("─", width-utf8.RuneCountInString(header)-1)+"╮", styleDim) + "\n")
+	for _, line := range lines {
+		padding := strings.Repeat(" ", width-utf8.RuneCountInString(line.text))
+		sb.WriteString(p.styled("│ ", styleDim) + p.styled(line.text, line.style) + padding + p.styled(" │", styleDim) + "\n")
+	}
+	sb.WriteString(p.styled("╰"+strings.Repeat("─", width+2)+"╯", styleDim) + "\n")
+	return sb.String()
+}
+
+// styled applies an ANSI style to the text when colors are enabled.
+func (p *ConsoleProvider) styled(text, style string) string {
+	if !p.color || style == "" || text == "" {
+		return text
+	}
+	return style + text + styleReset
+}
+
+// wrapText wraps the lines of the text at width runes, breaking at spaces.
+func wrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) > width:
+				lines = append(lines, line)
+				line = word
+			default:
+				line += " " + word
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
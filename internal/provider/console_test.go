@@ -24,7 +24,7 @@ func captureStdout(f func()) string {
 func TestConsoleProvider_CreateIssue(t *testing.T) {
 	provider := NewConsoleProvider()
 	output := captureStdout(func() {
-		issue, err := provider.CreateIssue("Test Title", "Test Description", []string{"bug", "feature"}, nil)
+		issue, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Test Title", Body: "Test Description", Labels: []string{"bug", "feature"}})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -47,7 +47,7 @@ func TestConsoleProvider_CreateIssue_WithProject(t *testing.T) {
 	provider := NewConsoleProvider()
 	project := &ProjectInfo{ProjectNumber: 1, ProjectOwner: "owner", ProjectID: "id"}
 	output := captureStdout(func() {
-		_, err := provider.CreateIssue("Title", "Desc", []string{"label"}, project)
+		_, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Title", Body: "Desc", Labels: []string{"label"}, Project: project})
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -57,10 +57,28 @@ func TestConsoleProvider_CreateIssue_WithProject(t *testing.T) {
 	}
 }
 
+func TestConsoleProvider_CreateDraftItem(t *testing.T) {
+	var buf bytes.Buffer
+	provider, err := NewConsoleProviderWithConfig(ConsoleConfig{Writer: &buf})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	draft, err := provider.CreateDraftItem(context.Background(), "Idea", "Desc", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if draft.GetTitle() != "Idea" || draft.GetNumber() != 0 || draft.GetHTMLURL() != "" {
+		t.Errorf("unexpected draft item %+v", draft)
+	}
+	if !strings.Contains(buf.String(), "[CONSOLE PROVIDER] Draft Item Preview:\nTitle: Idea\n") {
+		t.Errorf("expected output to contain the draft item preview, got %s", buf.String())
+	}
+}
+
 func TestConsoleProvider_AddSubIssue(t *testing.T) {
 	provider := NewConsoleProvider()
 	output := captureStdout(func() {
-		err := provider.AddSubIssue(1, 2)
+		err := provider.AddSubIssue(context.Background(), 1, 2)
 		if err != nil {
 			t.Fatalf("unexpected error: %v", err)
 		}
@@ -102,3 +120,98 @@ func TestConsoleIssue_Methods(t *testing.T) {
 		t.Errorf("expected labels ['a'], got %v", issue.GetLabels())
 	}
 }
+
+func TestConsoleProvider_EditIssue(t *testing.T) {
+	provider := NewConsoleProvider()
+	output := captureStdout(func() {
+		issue, err := provider.EditIssue(context.Background(), 3, "", "New body")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if issue.GetBody() != "New body" {
+			t.Errorf("expected body 'New body', got '%s'", issue.GetBody())
+		}
+	})
+	if !strings.Contains(output, "Issue #3 Update:") || strings.Contains(output, "Title:") {
+		t.Errorf("expected output to contain only the body update, got %s", output)
+	}
+}
+
+func TestConsoleProvider_JSONOutput(t *testing.T) {
+	var buf bytes.Buffer
+	provider, err := NewConsoleProviderWithConfig(ConsoleConfig{Output: ConsoleJSON, Writer: &buf})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	project := &ProjectInfo{ProjectNumber: 1, ProjectOwner: "owner"}
+	_, _ = provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Title", Body: "<b>Desc</b>", Labels: []string{"label"}, Project: project})
+	_ = provider.AddSubIssue(context.Background(), 1, 2)
+	_, _ = provider.EditIssue(context.Background(), 3, "", "New body")
+
+	expected := `{"action":"create","title":"Title","labels":["label"],"description":"<b>Desc</b>","project":{"number":1,"owner":"owner"}}` + "\n" +
+		`{"action":"link","parent":1,"child":2}` + "\n" +
+		`{"action":"edit","number":3,"description":"New body"}` + "\n"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
+
+func TestConsoleProvider_YAMLOutput(t *testing.T) {
+	var buf bytes.Buffer
+	provider, _ := NewConsoleProviderWithConfig(ConsoleConfig{Output: ConsoleYAML, Writer: &buf})
+	_, _ = provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Title", Body: "Desc", Labels: []string{"label"}})
+	_ = provider.AddSubIssue(context.Background(), 1, 2)
+
+	expected := "---\naction: create\ntitle: Title\nlabels:\n    - label\ndescription: Desc\n---\naction: link\nparent: 1\nchild: 2\n"
+	if buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestConsoleProvider_PrettyOutput(t *testing.T) {
+	var buf bytes.Buffer
+	provider, _ := NewConsoleProviderWithConfig(ConsoleConfig{Output: ConsolePretty, Writer: &buf})
+	_, _ = provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Title", Body: "Desc", Labels: []string{"a", "b"}})
+
+	expected := "\n╭─ New issue ──╮\n" +
+		"│ Title        │\n" +
+		"│ Labels: a, b │\n" +
+		"│              │\n" +
+		"│ Desc         │\n" +
+		"╰──────────────╯\n"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+	buf.Reset()
+
+	colored, _ := NewConsoleProviderWithConfig(ConsoleConfig{Output: ConsolePretty, Color: true, Writer: &buf})
+	_, _ = colored.CreateIssue(context.Background(), CreateIssueRequest{Title: "Title"})
+	if !strings.Contains(buf.String(), styleBold+styleCyan+"Title"+styleReset) {
+		t.Errorf("expected a colored title, got %q", buf.String())
+	}
+}
+
+func TestParseConsoleOutput(t *testing.T) {
+	if output, err := ParseConsoleOutput(""); err != nil || output != ConsoleText {
+		t.Errorf("expected the text output by default, got %q (%v)", output, err)
+	}
+	if output, err := ParseConsoleOutput("JSON"); err != nil || output != ConsoleJSON {
+		t.Errorf("expected the json output, got %q (%v)", output, err)
+	}
+	if _, err := NewConsoleProviderWithConfig(ConsoleConfig{Output: "xml"}); err == nil {
+		t.Error("expected an error for an unsupported output")
+	}
+}
+
+func TestConsoleProvider_ASCII(t *testing.T) {
+	var buf bytes.Buffer
+	provider, _ := NewConsoleProviderWithConfig(ConsoleConfig{Output: ConsolePretty, ASCII: true, Writer: &buf})
+	_, _ = provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "[📖 User Story] Pay"})
+
+	expected := "\n+- New issue ------+\n" +
+		"| [User Story] Pay |\n" +
+		"+------------------+\n"
+	if buf.String() != expected {
+		t.Errorf("expected %s, got %s", expected, buf.String())
+	}
+}
@@ -0,0 +1,250 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GiteaConfig holds the configuration for the Gitea/Forgejo provider.
+type GiteaConfig struct {
+	URL   string // Base URL of the instance, e.g. https://gitea.example.com
+	Token string
+	Owner string
+	Repo  string
+}
+
+// GiteaProvider creates items as issues in a Gitea or Forgejo repository. Both expose the same
+// REST API, which follows the GitHub issue model but has no Projects v2 nor sub-issues, so
+// sub-issues are tracked as issue dependencies: the parent depends on each of its children.
+type GiteaProvider struct {
+	api   *restClient
+	owner string
+	repo  string
+
+	mu       sync.Mutex
+	labelIDs map[string]int64 // label name -> ID, loaded on first use
+	numbers  map[int64]int    // issue ID -> number of the issues created by the provider
+}
+
+// NewGiteaProvider creates a new GiteaProvider with the given configuration.
+func NewGiteaProvider(config GiteaConfig) (*GiteaProvider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("a Gitea URL is required")
+	}
+	header := http.Header{"Authorization": {"token " + config.Token}}
+	api, err := newRestClient("Gitea", strings.TrimSuffix(config.URL, "/")+"/api/v1", header, giteaErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GiteaProvider{
+		api:     api,
+		owner:   config.Owner,
+		repo:    config.Repo,
+		numbers: make(map[int64]int),
+	}, nil
+}
+
+// giteaIssue is the subset of the Gitea issue resource used by the provider.
+type giteaIssue struct {
+	ID      int64  `json:"id"`
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	State   string `json:"state"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+}
+
+func (i *giteaIssue) GetNumber() int     { return i.Number }
+func (i *giteaIssue) GetID() int64       { return i.ID }
+func (i *giteaIssue) GetHTMLURL() string { return i.HTMLURL }
+func (i *giteaIssue) GetTitle() string   { return i.Title }
+func (i *giteaIssue) GetBody() string    { return i.Body }
+func (i *giteaIssue) GetLabels() []string {
+	var result []string
+	for _, l := range i.Labels {
+		result = append(result, l.Name)
+	}
+	return result
+}
+func (i *giteaIssue) GetState() string      { return i.State }
+func (i *giteaIssue) GetProviderID() string { return strconv.FormatInt(i.ID, 10) }
+
+// CreateIssue creates a new issue in the configured repository, linked to its parent as a
+// dependency. Labels that do not exist in the repository are skipped. Projects and milestones are
+// not supported and ignored.
+func (p *GiteaProvider) CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	if req.Project != nil {
+		slog.Debug("projects are not supported by Gitea, ignoring project", "project", req.Project)
+	}
+
+	var warnings []string
+	labelIDs, err := p.resolveLabels(ctx, req.Labels)
+	if err != nil {
+		warnings = append(warnings, fmt.Sprintf("failed to look up Gitea labels: %v", err))
+	}
+
+	body := map[string]interface{}{
+		"title":  req.Title,
+		"body":   req.Body,
+		"labels": labelIDs,
+	}
+	if len(req.Assignees) > 0 {
+		body["assignees"] = req.Assignees
+	}
+	var issue giteaIssue
+	if err := p.api.request(ctx, http.MethodPost, p.repoPath("issues"), body, &issue); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	slog.Info("issue created", "number", issue.Number, "url", issue.HTMLURL)
+
+	p.mu.Lock()
+	p.numbers[issue.ID] = issue.Number
+	p.mu.Unlock()
+
+	if req.Parent != 0 {
+		if err := p.AddSubIssue(ctx, req.Parent, issue.ID); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to add issue #%d as a sub-issue of #%d: %v", issue.Number, req.Parent, err))
+		}
+	}
+	return &CreateIssueResult{Issue: &issue, Warnings: warnings}, nil
+}
+
+// AddSubIssue records the child as a dependency of the parent issue, so the parent cannot be
+// closed before its children. Only issues created by this provider can be linked.
+func (p *GiteaProvider) AddSubIssue(ctx context.Context, parentNumber int, childID int64) error {
+	p.mu.Lock()
+	childNumber, ok := p.numbers[childID]
+	p.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("unknown issue ID %d", childID)
+	}
+
+	body := map[string]interface{}{"owner": p.owner, "repo": p.repo, "index": childNumber}
+	path := p.repoPath(fmt.Sprintf("issues/%d/dependencies", parentNumber))
+	if err := p.api.request(ctx, http.MethodPost, path, body, nil); err != nil {
+		return fmt.Errorf("failed to add dependency #%d to issue #%d: %w", childNumber, parentNumber, err)
+	}
+	return nil
+}
+
+// EditIssue updates the title and/or description of an existing issue. Empty fields are left unchanged.
+func (p *GiteaProvider) EditIssue(ctx context.Context, number int, title, description string) (Issue, error) {
+	body := map[string]interface{}{}
+	if title != "" {
+		body["title"] = title
+	}
+	if description != "" {
+		body["body"] = description
+	}
+
+	var issue giteaIssue
+	if err := p.api.request(ctx, http.MethodPatch, p.repoPath(fmt.Sprintf("issues/%d", number)), body, &issue); err != nil {
+		return nil, fmt.Errorf("failed to edit issue #%d: %w", number, err)
+	}
+	slog.Debug("issue edited", "number", issue.Number)
+	return &issue, nil
+}
+
+// CloseIssue closes an existing issue of the repository.
+func (p *GiteaProvider) CloseIssue(ctx context.Context, number int) error {
+	body := map[string]interface{}{"state": StateClosed}
+	if err := p.api.request(ctx, http.MethodPatch, p.repoPath(fmt.Sprintf("issues/%d", number)), body, nil); err != nil {
+		return fmt.Errorf("failed to close issue #%d: %w", number, err)
+	}
+	slog.Debug("issue closed", "number", number)
+	return nil
+}
+
+// DeleteIssue deletes an existing issue of the repository, which needs a token of an admin of the
+// repository.
+func (p *GiteaProvider) DeleteIssue(ctx context.Context, number int) error {
+	if err := p.api.request(ctx, http.MethodDelete, p.repoPath(fmt.Sprintf("issues/%d", number)), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete issue #%d: %w", number, err)
+	}
+	slog.Debug("issue deleted", "number", number)
+	return nil
+}
+
+// giteaMaxTitleSize is the maximum characters of the title of a Gitea issue.
+const giteaMaxTitleSize = 255
+
+// Capabilities returns the capabilities of Gitea, where the sub-issues are dependencies and there
+// are no projects.
+func (p *GiteaProvider) Capabilities() Capabilities {
+	return Capabilities{SubIssues: true, Labels: true, MaxTitleSize: giteaMaxTitleSize}
+}
+
+// GetProjectByName is a no-op, Gitea has no equivalent of GitHub Projects v2.
+func (p *GiteaProvider) GetProjectByName(_ context.Context, projectName string) (*ProjectInfo, error) {
+	slog.Debug("projects are not supported by Gitea", "project", projectName)
+	return nil, nil
+}
+
+// resolveLabels maps label names to the IDs of the repository labels. The labels are loaded once.
+func (p *GiteaProvider) resolveLabels(ctx context.Context, labels []string) ([]int64, error) {
+	if len(labels) == 0 {
+		return []int64{}, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.labelIDs == nil {
+		ids := make(map[string]int64)
+		for page := 1; ; page++ {
+			var result []struct {
+				ID   int64  `json:"id"`
+				Name string `json:"name"`
+			}
+			path := p.repoPath(fmt.Sprintf("labels?limit=50&page=%d", page))
+			if err := p.api.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+				return []int64{}, err
+			}
+			for _, l := range result {
+				ids[l.Name] = l.ID
+			}
+			if len(result) < 50 {
+				break
+			}
+		}
+		p.labelIDs = ids
+	}
+
+	result := []int64{}
+	for _, name := range labels {
+		id, ok := p.labelIDs[name]
+		if !ok {
+			slog.Debug("label not found in Gitea repository, skipping", "label", name)
+			continue
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// repoPath returns the API path of a resource in the configured repository.
+func (p *GiteaProvider) repoPath(resource string) string {
+	return fmt.Sprintf("repos/%s/%s/%s", url.PathEscape(p.owner), url.PathEscape(p.repo), resource)
+}
+
+// giteaErrorMessage returns the message of a Gitea error response.
+func giteaErrorMessage(body []byte) string {
+	var apiErr struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return apiErr.Message
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// giteaRequest is a request received by the fake Gitea server.
+type giteaRequest struct {
+	method string
+	path   string
+	body   map[string]interface{}
+}
+
+// newFakeGiteaProvider starts a fake Gitea API that answers with the handler's response for each
+// request and records the requests it receives.
+func newFakeGiteaProvider(t *testing.T, handler func(r *http.Request) (int, string)) (*GiteaProvider, *[]giteaRequest) {
+	t.Helper()
+	var requests []giteaRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, giteaRequest{method: r.Method, path: r.URL.Path, body: body})
+
+		status, response := handler(r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	p, err := NewGiteaProvider(GiteaConfig{URL: server.URL + "/", Token: "secret", Owner: "acme", Repo: "shop"})
+	require.NoError(t, err)
+	return p, &requests
+}
+
+func TestGiteaProvider_CreateIssue(t *testing.T) {
+	p, requests := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/api/v1/repos/acme/shop/labels" {
+			return http.StatusOK, `[{"id":5,"name":"User Story"},{"id":6,"name":"Task"}]`
+		}
+		return http.StatusCreated, `{"id":900,"number":12,"title":"Story","body":"Body","html_url":"https://gitea.example.com/acme/shop/issues/12","labels":[{"name":"User Story"}]}`
+	})
+
+	issue, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story", "Missing"}, Project: &ProjectInfo{ProjectID: "ignored"}})
+	require.NoError(t, err)
+	assert.Equal(t, 12, issue.GetNumber())
+	assert.Equal(t, int64(900), issue.GetID())
+	assert.Equal(t, "https://gitea.example.com/acme/shop/issues/12", issue.GetHTMLURL())
+	assert.Equal(t, []string{"User Story"}, issue.GetLabels())
+
+	_, err = p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Task", Body: "Body", Labels: []string{"Task"}})
+	require.NoError(t, err)
+
+	// Labels are loaded once and unknown labels are skipped
+	require.Len(t, *requests, 3)
+	assert.Equal(t, "/api/v1/repos/acme/shop/issues", (*requests)[1].path)
+	assert.Equal(t, []interface{}{float64(5)}, (*requests)[1].body["labels"])
+	assert.Equal(t, []interface{}{float64(6)}, (*requests)[2].body["labels"])
+}
+
+func TestGiteaProvider_CreateIssue_Error(t *testing.T) {
+	p, _ := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		return http.StatusNotFound, `{"message":"repository does not exist"}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status: 404")
+	assert.Contains(t, err.Error(), "repository does not exist")
+}
+
+func TestGiteaProvider_AddSubIssue(t *testing.T) {
+	p, requests := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/api/v1/repos/acme/shop/issues" {
+			return http.StatusCreated, `{"id":901,"number":13}`
+		}
+		return http.StatusCreated, `{}`
+	})
+
+	task, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Task", Body: "Body"})
+	require.NoError(t, err)
+	require.NoError(t, p.AddSubIssue(context.Background(), 12, task.GetID()))
+
+	dependency := (*requests)[1]
+	assert.Equal(t, "/api/v1/repos/acme/shop/issues/12/dependencies", dependency.path)
+	assert.Equal(t, map[string]interface{}{"owner": "acme", "repo": "shop", "index": float64(13)}, dependency.body)
+
+	assert.ErrorContains(t, p.AddSubIssue(context.Background(), 12, 999), "unknown issue ID 999")
+}
+
+func TestGiteaProvider_EditIssue(t *testing.T) {
+	p, requests := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		return http.StatusCreated, `{"id":900,"number":12,"title":"Story","body":"New body"}`
+	})
+
+	issue, err := p.EditIssue(context.Background(), 12, "", "New body")
+	require.NoError(t, err)
+	assert.Equal(t, "New body", issue.GetBody())
+	assert.Equal(t, http.MethodPatch, (*requests)[0].method)
+	assert.Equal(t, "/api/v1/repos/acme/shop/issues/12", (*requests)[0].path)
+	assert.Equal(t, map[string]interface{}{"body": "New body"}, (*requests)[0].body)
+}
+
+func TestGiteaProvider_CloseAndDeleteIssue(t *testing.T) {
+	p, requests := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		if r.Method == http.MethodDelete {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusCreated, `{"id":900,"number":12,"state":"closed"}`
+	})
+
+	require.NoError(t, p.CloseIssue(context.Background(), 12))
+	require.NoError(t, p.DeleteIssue(context.Background(), 12))
+	require.Len(t, *requests, 2)
+	assert.Equal(t, map[string]interface{}{"state": "closed"}, (*requests)[0].body)
+	assert.Equal(t, http.MethodDelete, (*requests)[1].method)
+	assert.Equal(t, "/api/v1/repos/acme/shop/issues/12", (*requests)[1].path)
+}
+
+func TestGiteaProvider_GetProjectByName(t *testing.T) {
+	p, requests := newFakeGiteaProvider(t, func(r *http.Request) (int, string) {
+		return http.StatusOK, `{}`
+	})
+
+	project, err := p.GetProjectByName(context.Background(), "Payments")
+	assert.NoError(t, err)
+	assert.Nil(t, project)
+	assert.Empty(t, *requests)
+}
@@ -4,14 +4,19 @@ package provider
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
-	"os"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/redact"
 	"golang.org/x/oauth2"
 )
 
@@ -19,27 +24,49 @@ import (
 type IssuesService interface {
 	Create(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
 	Edit(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+	ListByRepo(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error)
+	ListMilestones(ctx context.Context, owner string, repo string, opts *github.MilestoneListOptions) ([]*github.Milestone, *github.Response, error)
 }
 
 // RepositoriesService interface for GitHub Repositories API.
 type RepositoriesService interface {
 	Get(ctx context.Context, owner string, repo string) (*github.Repository, *github.Response, error)
+	CreateFile(ctx context.Context, owner string, repo string, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error)
+}
+
+// GitService interface for GitHub Git Database API.
+type GitService interface {
+	GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error)
+	CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error)
+}
+
+// PullRequestsService interface for GitHub Pull Requests API.
+type PullRequestsService interface {
+	Create(ctx context.Context, owner string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error)
 }
 
 // GitHubProvider provides methods to interact with GitHub Issues and Projects.
 type GitHubProvider struct {
-	issues IssuesService
-	repos  RepositoriesService
-	owner  string
-	repo   string
-	client *github.Client
+	issues      IssuesService
+	repos       RepositoriesService
+	git         GitService
+	pulls       PullRequestsService
+	owner       string
+	repo        string
+	client      *github.Client
+	fineGrained bool   // Whether the token is a fine-grained personal access token
+	graphQLURL  string // GraphQL endpoint, see graphQLURL; relative to the REST base URL when empty
 }
 
 // GitHubConfig holds the configuration for the GitHub provider.
 type GitHubConfig struct {
-	Token string
-	Owner string
-	Repo  string
+	Token      string
+	Owner      string
+	Repo       string
+	BaseURL    string // Optional API base URL (GitHub Enterprise or a fake server), defaults to https://api.github.com/
+	GraphQLURL string // Optional GraphQL endpoint, derived from BaseURL by default
 }
 
 // ProjectInfo holds information about a GitHub Project v2.
@@ -74,6 +101,62 @@ const (
 		}
 	}`
 
+	queryProjectV2Items = `query($projectId: ID!, $cursor: String) {
+		node(id: $projectId) {
+			... on ProjectV2 {
+				items(first: 100, after: $cursor) {
+					nodes {
+						fieldValues(first: 50) {
+							nodes {
+								... on ProjectV2ItemFieldSingleSelectValue { name field { ... on ProjectV2FieldCommon { name } } }
+								... on ProjectV2ItemFieldNumberValue { number field { ... on ProjectV2FieldCommon { name } } }
+							}
+						}
+						content { ... on Issue { number title url state labels(first: 20) { nodes { name } } } }
+					}
+					pageInfo { hasNextPage endCursor }
+				}
+			}
+		}
+	}`
+
+	queryProjectV2Fields = `query($projectId: ID!) {
+		node(id: $projectId) {
+			... on ProjectV2 {
+				fields(first: 100) {
+					nodes {
+						... on ProjectV2FieldCommon { id name dataType }
+						... on ProjectV2SingleSelectField { options { id name } }
+						... on ProjectV2IterationField {
+							configuration {
+								iterations { id title startDate duration }
+								completedIterations { id title startDate duration }
+							}
+						}
+					}
+				}
+			}
+		}
+	}`
+
+	mutationAddProjectV2DraftIssue = `mutation($projectId: ID!, $title: String!, $body: String) {
+		addProjectV2DraftIssue(input: {projectId: $projectId, title: $title, body: $body}) {
+			projectItem { id content { ... on DraftIssue { id title } } }
+		}
+	}`
+
+	mutationCreateProjectV2 = `mutation($ownerId: ID!, $repositoryId: ID!, $title: String!) {
+		createProjectV2(input: {ownerId: $ownerId, repositoryId: $repositoryId, title: $title}) {
+			projectV2 { id number title }
+		}
+	}`
+
+	mutationDeleteProjectV2 = `mutation($projectId: ID!) {
+		deleteProjectV2(input: {projectId: $projectId}) {
+			projectV2 { id }
+		}
+	}`
+
 	mutationAddProjectV2ItemByID = `mutation($projectId: ID!, $contentId: ID!) {
 		addProjectV2ItemById(input: {projectId: $projectId, contentId: $contentId}) {
 			item { id content { ... on Issue { number title } } }
@@ -83,24 +166,108 @@ const (
 
 // NewGitHubProvider creates a new GitHubProvider with the given configuration.
 func NewGitHubProvider(config GitHubConfig) (*GitHubProvider, error) {
-	ctx := context.Background()
+	ctx := httpclient.OAuth2Context(context.Background())
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: config.Token},
 	)
 	tc := oauth2.NewClient(ctx, ts)
+	tc.Timeout = httpclient.DefaultTimeout
+	tc.Transport = &ssoTransport{base: tc.Transport}
 	client := github.NewClient(tc)
+	if config.BaseURL != "" {
+		baseURL, err := url.Parse(strings.TrimSuffix(config.BaseURL, "/") + "/")
+		if err != nil {
+			return nil, fmt.Errorf("invalid GitHub API base URL: %w", err)
+		}
+		client.BaseURL = baseURL
+	}
 
 	provider := &GitHubProvider{
-		issues: client.Issues,
-		repos:  client.Repositories,
-		owner:  config.Owner,
-		repo:   config.Repo,
-		client: client,
+		issues:      client.Issues,
+		repos:       client.Repositories,
+		git:         client.Git,
+		pulls:       client.PullRequests,
+		owner:       config.Owner,
+		repo:        config.Repo,
+		client:      client,
+		fineGrained: strings.HasPrefix(config.Token, fineGrainedTokenPrefix),
+		graphQLURL:  config.GraphQLURL,
+	}
+	if provider.graphQLURL == "" {
+		provider.graphQLURL = graphQLURL(client.BaseURL)
 	}
 
 	return provider, nil
 }
 
+// graphQLURL returns the GraphQL endpoint of a REST API base URL. GitHub Enterprise Server serves
+// the REST API under /api/v3/ and GraphQL at /api/graphql, while api.github.com (and the fake
+// servers) serve GraphQL next to the REST resources.
+func graphQLURL(base *url.URL) string {
+	u := *base
+	if prefix, ok := strings.CutSuffix(u.Path, "/api/v3/"); ok {
+		u.Path = prefix + "/api/graphql"
+	} else {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/graphql"
+	}
+	return u.String()
+}
+
+// graphQLEndpoint returns the URL of the GraphQL requests.
+func (p *GitHubProvider) graphQLEndpoint() string {
+	if p.graphQLURL == "" {
+		return "graphql"
+	}
+	return p.graphQLURL
+}
+
+// SSORequiredError is returned when the organization of the repository enforces SAML single sign-on
+// and the token is not authorized for it.
+type SSORequiredError struct {
+	URL string // Page authorizing the token for the organization, when GitHub reports it
+}
+
+func (e *SSORequiredError) Error() string {
+	message := "the organization requires SAML single sign-on and the GitHub token is not authorized for it: "
+	if e.URL != "" {
+		return message + "authorize it at " + e.URL
+	}
+	return message + "authorize it for the organization (Configure SSO) at https://github.com/settings/tokens"
+}
+
+// ssoTransport turns the 403 responses of the resources protected by SAML single sign-on, flagged
+// by the X-GitHub-SSO header or the message of the body, into a SSORequiredError.
+type ssoTransport struct {
+	base http.RoundTripper
+}
+
+func (t *ssoTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusForbidden {
+		return resp, err
+	}
+
+	header := resp.Header.Get("X-GitHub-SSO")
+	if !strings.HasPrefix(header, "required") {
+		data, err := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if !strings.Contains(string(data), "SAML enforcement") {
+			resp.Body = io.NopCloser(bytes.NewReader(data))
+			return resp, nil
+		}
+	} else {
+		_ = resp.Body.Close()
+	}
+	ssoErr := &SSORequiredError{}
+	if _, authorizeURL, ok := strings.Cut(header, "url="); ok {
+		ssoErr.URL = strings.TrimSpace(authorizeURL)
+	}
+	return nil, ssoErr
+}
+
 // githubIssueWrapper wraps *github.Issue to implement the Issue interface.
 type githubIssueWrapper struct {
 	issue *github.Issue
@@ -146,37 +313,402 @@ func (w *githubIssueWrapper) GetLabels() []string {
 	}
 	return result
 }
+func (w *githubIssueWrapper) GetState() string      { return w.issue.GetState() }
+func (w *githubIssueWrapper) GetProviderID() string { return w.issue.GetNodeID() }
 
-// CreateIssue creates a new issue in the configured GitHub repository and optionally adds it to a project.
-func (p *GitHubProvider) CreateIssue(title, description string, labels []string, project *ProjectInfo) (Issue, error) {
-	ctx := context.Background()
-
+// CreateIssue creates a new issue in the configured GitHub repository and optionally adds it to a
+// project and links it to its parent as a sub-issue. A milestone that cannot be found, a failure to
+// add the issue to the project or to link it are returned as warnings, the issue is kept.
+func (p *GitHubProvider) CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	labels := req.Labels
 	issue := &github.IssueRequest{
-		Title:  &title,
-		Body:   &description,
+		Title:  &req.Title,
+		Body:   &req.Body,
 		Labels: &labels,
 	}
+	if len(req.Assignees) > 0 {
+		issue.Assignees = &req.Assignees
+	}
+	var warnings []string
+	if req.Milestone != "" {
+		number, err := p.milestoneNumber(ctx, req.Milestone)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to set milestone %q: %v", req.Milestone, err))
+		} else {
+			issue.Milestone = &number
+		}
+	}
 
 	createdIssue, resp, err := p.issues.Create(ctx, p.owner, p.repo, issue)
 	if err != nil {
+		if resp == nil {
+			return nil, fmt.Errorf("failed to create issue: %w", err)
+		}
 		bodyBytes, _ := io.ReadAll(resp.Body)
 		if cerr := resp.Body.Close(); cerr != nil {
 			slog.Warn("failed to close response body", "error", cerr)
 		}
-		bodyStr := string(bodyBytes)
+		bodyStr := redact.Secrets(string(bodyBytes))
 		return nil, fmt.Errorf("failed to create issue (status: %s, body: %s): %w", resp.Status, bodyStr, err)
 	}
 
 	slog.Info("issue created", "number", createdIssue.GetNumber(), "url", createdIssue.GetHTMLURL())
 
-	// If project info is provided, add the issue to the project
-	if project != nil {
-		if err := p.addIssueToProject(ctx, createdIssue, project); err != nil {
-			slog.Warn("failed to add issue to project", "error", err)
+	result := &CreateIssueResult{Issue: &githubIssueWrapper{issue: createdIssue}, Warnings: warnings}
+	if req.Project != nil {
+		if result.ProjectItemID, err = p.addIssueToProject(ctx, createdIssue, req.Project); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to add issue #%d to project: %v", createdIssue.GetNumber(), err))
+		}
+	}
+	if req.Parent != 0 {
+		if err := p.AddSubIssue(ctx, req.Parent, createdIssue.GetID()); err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("failed to add issue #%d as a sub-issue of #%d: %v", createdIssue.GetNumber(), req.Parent, err))
+		}
+	}
+	return result, nil
+}
+
+// EditIssue updates the title and/or description of an existing issue. Empty fields are left unchanged.
+func (p *GitHubProvider) EditIssue(ctx context.Context, number int, title, description string) (Issue, error) {
+	req := &github.IssueRequest{}
+	if title != "" {
+		req.Title = &title
+	}
+	if description != "" {
+		req.Body = &description
+	}
+
+	edited, resp, err := p.issues.Edit(ctx, p.owner, p.repo, number, req)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to edit issue #%d (status: %s): %w", number, resp.Status, err)
+		}
+		return nil, fmt.Errorf("failed to edit issue #%d: %w", number, err)
+	}
+	slog.Debug("issue edited", "number", edited.GetNumber())
+	return &githubIssueWrapper{issue: edited}, nil
+}
+
+// CloseIssue closes an existing issue of the repository as not planned. GitHub issues can only be
+// deleted by the admins of the repository, so closing them is how aigile discards its issues.
+func (p *GitHubProvider) CloseIssue(ctx context.Context, number int) error {
+	req := &github.IssueRequest{State: github.String(StateClosed), StateReason: github.String("not_planned")}
+	if _, resp, err := p.issues.Edit(ctx, p.owner, p.repo, number, req); err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to close issue #%d (status: %s): %w", number, resp.Status, err)
+		}
+		return fmt.Errorf("failed to close issue #%d: %w", number, err)
+	}
+	slog.Debug("issue closed", "number", number)
+	return nil
+}
+
+// GetIssue fetches an existing issue of the repository by number.
+func (p *GitHubProvider) GetIssue(ctx context.Context, number int) (Issue, error) {
+	issue, resp, err := p.issues.Get(ctx, p.owner, p.repo, number)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to get issue #%d (status: %s): %w", number, resp.Status, err)
+		}
+		return nil, fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+	return &githubIssueWrapper{issue: issue}, nil
+}
+
+// ListIssues returns the issues of the repository matching the filter, following the pages of the
+// results. Pull requests, which the API lists with the issues, are left out.
+func (p *GitHubProvider) ListIssues(ctx context.Context, filter IssueFilter) ([]Issue, error) {
+	opts := &github.IssueListByRepoOptions{
+		State:       filter.State,
+		Labels:      filter.Labels,
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	if opts.State == "" {
+		opts.State = "open"
+	}
+	if filter.Milestone != "" {
+		number, err := p.milestoneNumber(ctx, filter.Milestone)
+		if err != nil {
+			return nil, err
+		}
+		opts.Milestone = strconv.Itoa(number)
+	}
+
+	var result []Issue
+	for {
+		issues, resp, err := p.issues.ListByRepo(ctx, p.owner, p.repo, opts)
+		if err != nil {
+			if resp != nil {
+				return nil, fmt.Errorf("failed to list issues (status: %s): %w", resp.Status, err)
+			}
+			return nil, fmt.Errorf("failed to list issues: %w", err)
+		}
+		for _, issue := range issues {
+			if !issue.IsPullRequest() {
+				result = append(result, &githubIssueWrapper{issue: issue})
+			}
 		}
+		if resp == nil || resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
 	}
+}
 
-	return &githubIssueWrapper{issue: createdIssue}, nil
+// milestoneNumber returns the number of the milestone with the given title, open or closed.
+func (p *GitHubProvider) milestoneNumber(ctx context.Context, title string) (int, error) {
+	opts := &github.MilestoneListOptions{State: "all", ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		milestones, resp, err := p.issues.ListMilestones(ctx, p.owner, p.repo, opts)
+		if err != nil {
+			return 0, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		for _, m := range milestones {
+			if m.GetTitle() == title {
+				return m.GetNumber(), nil
+			}
+		}
+		if resp == nil || resp.NextPage == 0 {
+			return 0, fmt.Errorf("milestone not found: %s", title)
+		}
+		opts.Page = resp.NextPage
+	}
+}
+
+// AddComment adds a comment to an existing issue.
+func (p *GitHubProvider) AddComment(ctx context.Context, number int, body string) error {
+	_, resp, err := p.issues.CreateComment(ctx, p.owner, p.repo, number, &github.IssueComment{Body: &body})
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to comment on issue #%d (status: %s): %w", number, resp.Status, err)
+		}
+		return fmt.Errorf("failed to comment on issue #%d: %w", number, err)
+	}
+	slog.Debug("issue commented", "number", number)
+	return nil
+}
+
+// ProposeChange creates the branch of the change from the default branch, commits each file to it
+// and opens a pull request against the default branch.
+func (p *GitHubProvider) ProposeChange(ctx context.Context, change Change) (Issue, error) {
+	repo, _, err := p.repos.Get(ctx, p.owner, p.repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %w", err)
+	}
+	base := repo.GetDefaultBranch()
+	ref, _, err := p.git.GetRef(ctx, p.owner, p.repo, "heads/"+base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get branch %s: %w", base, err)
+	}
+	_, _, err = p.git.CreateRef(ctx, p.owner, p.repo, &github.Reference{
+		Ref:    github.String("refs/heads/" + change.Branch),
+		Object: &github.GitObject{SHA: github.String(ref.GetObject().GetSHA())},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create branch %s: %w", change.Branch, err)
+	}
+
+	paths := make([]string, 0, len(change.Files))
+	for path := range change.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		_, _, err := p.repos.CreateFile(ctx, p.owner, p.repo, path, &github.RepositoryContentFileOptions{
+			Message: github.String(change.Message),
+			Content: []byte(change.Files[path]),
+			Branch:  github.String(change.Branch),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to commit %s: %w", path, err)
+		}
+	}
+
+	pr, _, err := p.pulls.Create(ctx, p.owner, p.repo, &github.NewPullRequest{
+		Title: github.String(change.Title),
+		Head:  github.String(change.Branch),
+		Base:  github.String(base),
+		Body:  github.String(change.Body),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open pull request: %w", err)
+	}
+	slog.Info("pull request opened", "number", pr.GetNumber(), "url", pr.GetHTMLURL())
+	return &githubIssueWrapper{issue: &github.Issue{
+		Number: pr.Number, ID: pr.ID, NodeID: pr.NodeID, HTMLURL: pr.HTMLURL, Title: pr.Title, Body: pr.Body, State: pr.State,
+	}}, nil
+}
+
+// GitHub limits of the issue titles and bodies, in characters.
+const (
+	githubMaxTitleSize = 256
+	githubMaxBodySize  = 65536
+)
+
+// Capabilities returns the capabilities of GitHub, which supports Projects v2, sub-issues and
+// milestones.
+func (p *GitHubProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Projects:     true,
+		SubIssues:    true,
+		Milestones:   true,
+		Labels:       true,
+		MaxTitleSize: githubMaxTitleSize,
+		MaxBodySize:  githubMaxBodySize,
+	}
+}
+
+// fineGrainedTokenPrefix is the prefix of the fine-grained personal access tokens, which have
+// permissions on selected repositories of a single owner instead of scopes.
+const fineGrainedTokenPrefix = "github_pat_"
+
+// tokenExpiryWarning is how long before the expiration of the token the preflight check warns about
+// it, so that it does not expire in the middle of a long run.
+const tokenExpiryWarning = 7 * 24 * time.Hour
+
+// queryProjectsAccess reads the first project of the owner, to check the token can access them.
+const queryProjectsAccess = `query($owner: String!) {
+	repositoryOwner(login: $owner) {
+		__typename
+		... on ProjectV2Owner { projectsV2(first: 1) { totalCount } }
+	}
+}`
+
+// CheckPermissions checks that the token can create the issues of the repository and, from the
+// X-OAuth-Scopes header of classic tokens, that it has the scopes of the needed permissions. The
+// permissions of fine-grained tokens and GitHub Apps are not reported, so their read access to the
+// issues and the projects is checked by reading them instead. It also warns when the token expires
+// within a week.
+func (p *GitHubProvider) CheckPermissions(ctx context.Context, needs Permissions) error {
+	repo, resp, err := p.repos.Get(ctx, p.owner, p.repo)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			if p.fineGrained {
+				return fmt.Errorf("repository %s/%s not found: check it exists, the token is owned by %s and the repository is selected in its repository access", p.owner, p.repo, p.owner)
+			}
+			return fmt.Errorf("repository %s/%s not found: check it exists and the token has access to it", p.owner, p.repo)
+		}
+		return fmt.Errorf("failed to check the token permissions: %w", err)
+	}
+	if warning := tokenExpiry(resp.Header, time.Now()); warning != "" {
+		slog.Warn(warning)
+	}
+
+	var missing []string
+	if header, ok := resp.Header[http.CanonicalHeaderKey("X-OAuth-Scopes")]; ok {
+		scopes := map[string]bool{}
+		for _, scope := range strings.Split(strings.Join(header, ","), ",") {
+			scopes[strings.TrimSpace(scope)] = true
+		}
+		slog.Debug("token scopes", "scopes", strings.Join(header, ","))
+		if needs.Issues && !scopes["repo"] && (repo.GetPrivate() || !scopes["public_repo"]) {
+			missing = append(missing, "the repo scope")
+		}
+		if needs.Projects && !scopes["project"] {
+			missing = append(missing, "the project scope")
+		}
+	} else {
+		slog.Debug("token scopes not reported, checking the access to the issues and projects", "fine_grained", p.fineGrained)
+		if missing, err = p.checkAccess(ctx, needs); err != nil {
+			return err
+		}
+	}
+	if needs.Issues {
+		if perms := repo.GetPermissions(); len(perms) > 0 && !perms["admin"] && !perms["maintain"] && !perms["push"] && !perms["triage"] {
+			missing = append(missing, fmt.Sprintf("triage or write access to %s/%s", p.owner, p.repo))
+		}
+		if repo.HasIssues != nil && !repo.GetHasIssues() {
+			return fmt.Errorf("the issues of %s/%s are disabled: enable them in the settings of the repository", p.owner, p.repo)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("the GitHub token is missing %s: update the token, or run with --skip-preflight to try anyway", strings.Join(missing, " and "))
+	}
+	return nil
+}
+
+// checkAccess checks the access of a token without scopes, a fine-grained token or a GitHub App,
+// to the issues of the repository and the projects of the owner, and returns the permissions it is
+// missing. Only the read access can be checked without writing, the write access of a token that
+// can read them fails on the first item.
+func (p *GitHubProvider) checkAccess(ctx context.Context, needs Permissions) ([]string, error) {
+	var missing []string
+	if needs.Issues {
+		_, resp, err := p.issues.ListByRepo(ctx, p.owner, p.repo, &github.IssueListByRepoOptions{ListOptions: github.ListOptions{PerPage: 1}})
+		switch {
+		case resp != nil && resp.StatusCode == http.StatusForbidden:
+			missing = append(missing, "the Issues repository permission (read and write)")
+		case err != nil:
+			return nil, fmt.Errorf("failed to check the access to the issues: %w", err)
+		}
+	}
+	if !needs.Projects {
+		return missing, nil
+	}
+
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]any{
+		"query":     queryProjectsAccess,
+		"variables": map[string]any{"owner": p.owner},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request: %w", err)
+	}
+	var result struct {
+		Data struct {
+			RepositoryOwner struct {
+				Typename string `json:"__typename"`
+			} `json:"repositoryOwner"`
+		} `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}
+	if _, err := p.client.Do(ctx, req, &result); err != nil {
+		return nil, fmt.Errorf("failed to check the access to the projects: %w", err)
+	}
+	for _, e := range result.Errors {
+		if e.Type == "FORBIDDEN" || e.Type == "INSUFFICIENT_SCOPES" || strings.Contains(strings.ToLower(e.Message), "not accessible by") {
+			return append(missing, "the Projects organization permission (read and write)"), nil
+		}
+	}
+	if len(result.Errors) > 0 {
+		return nil, graphQLError("checking the access to the projects", result.Errors)
+	}
+	if p.fineGrained && result.Data.RepositoryOwner.Typename == "User" {
+		slog.Warn("fine-grained tokens cannot access the projects of a user account, the issues may not be added to them: use a classic token with the project scope", "owner", p.owner)
+	}
+	return missing, nil
+}
+
+// tokenExpiry returns a warning when the expiration of the token, reported by GitHub in the
+// GitHub-Authentication-Token-Expiration header for the tokens that expire, is closer than
+// tokenExpiryWarning, or an empty string.
+func tokenExpiry(header http.Header, now time.Time) string {
+	value := header.Get("GitHub-Authentication-Token-Expiration")
+	if value == "" {
+		return ""
+	}
+	var expiration time.Time
+	var err error
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700"} {
+		if expiration, err = time.Parse(layout, value); err == nil {
+			break
+		}
+	}
+	if err != nil {
+		slog.Debug("unknown token expiration format", "expiration", value)
+		return ""
+	}
+	left := expiration.Sub(now)
+	if left > tokenExpiryWarning {
+		return ""
+	}
+	if left <= 0 {
+		return fmt.Sprintf("the GitHub token expired on %s: regenerate it", expiration.Format(time.DateTime))
+	}
+	in := fmt.Sprintf("%d hours", int(left.Hours()))
+	if left >= 48*time.Hour {
+		in = fmt.Sprintf("%d days", int(left.Hours()/24))
+	}
+	return fmt.Sprintf("the GitHub token expires in %s (%s): regenerate it before a long run", in, expiration.Format(time.DateTime))
 }
 
 // GetProjectByName fetches project information using the project name.
@@ -184,7 +716,7 @@ func (p *GitHubProvider) GetProjectByName(ctx context.Context, projectName strin
 	slog.Debug("searching for project", "name", projectName, "owner", p.owner)
 
 	vars := map[string]interface{}{"owner": p.owner}
-	req, err := p.client.NewRequest("POST", "graphql", map[string]interface{}{
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
 		"query":     queryProjectV2ByName,
 		"variables": vars,
 	})
@@ -205,9 +737,7 @@ func (p *GitHubProvider) GetProjectByName(ctx context.Context, projectName strin
 				} `json:"projectsV2"`
 			} `json:"repositoryOwner"`
 		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+		Errors GraphQLErrors `json:"errors"`
 	}
 
 	resp, err := p.client.Do(ctx, req, &result)
@@ -220,7 +750,7 @@ func (p *GitHubProvider) GetProjectByName(ctx context.Context, projectName strin
 			}()
 			if resp.StatusCode != 200 {
 				bodyBytes, _ := io.ReadAll(resp.Body)
-				return nil, fmt.Errorf("failed to get projects (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+				return nil, fmt.Errorf("failed to get projects (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
 			}
 		}
 		return nil, fmt.Errorf("failed to execute GraphQL request: %w", err)
@@ -233,14 +763,11 @@ func (p *GitHubProvider) GetProjectByName(ctx context.Context, projectName strin
 
 	if resp.StatusCode != 200 {
 		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get projects (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("failed to get projects (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
 	}
 
 	if len(result.Errors) > 0 {
-		for _, err := range result.Errors {
-			slog.Error("graphql error", "message", err.Message)
-		}
-		return nil, fmt.Errorf("graphql errors occurred")
+		return nil, graphQLError("getting projects", result.Errors)
 	}
 
 	slog.Debug("found projects", "total_count", result.Data.RepositoryOwner.ProjectsV2.TotalCount)
@@ -256,91 +783,331 @@ func (p *GitHubProvider) GetProjectByName(ctx context.Context, projectName strin
 		}
 	}
 
-	return nil, fmt.Errorf("project not found: %s", projectName)
+	return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectName)
 }
 
-// addIssueToProject adds an existing issue to a GitHub Project v2 using addProjectV2ItemById.
-func (p *GitHubProvider) addIssueToProject(ctx context.Context, issue *github.Issue, project *ProjectInfo) error {
-	slog.Debug("adding issue to project",
-		"issue_number", issue.GetNumber(),
-		"project_number", project.ProjectNumber,
-		"project_id", project.ProjectID,
-		"owner", p.owner,
-		"repo", p.repo)
-
-	// 1. Buscar node_id da issue
-	vars := map[string]interface{}{"owner": p.owner, "repo": p.repo, "number": issue.GetNumber()}
-	req, err := p.client.NewRequest("POST", "graphql", map[string]interface{}{
-		"query":     queryIssueNodeID,
-		"variables": vars,
+// CreateProject creates a Project v2 owned by the owner of the repository and linked to it, using
+// createProjectV2.
+func (p *GitHubProvider) CreateProject(ctx context.Context, name string) (*ProjectInfo, error) {
+	repo, _, err := p.repos.Get(ctx, p.owner, p.repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository %s/%s: %w", p.owner, p.repo, err)
+	}
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+		"query":     mutationCreateProjectV2,
+		"variables": map[string]interface{}{"ownerId": repo.GetOwner().GetNodeID(), "repositoryId": repo.GetNodeID(), "title": name},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create GraphQL request for issue: %w", err)
+		return nil, fmt.Errorf("failed to create GraphQL request for project: %w", err)
 	}
 
-	var issueResult struct {
+	var result struct {
 		Data struct {
-			Repository struct {
-				Issue struct {
+			CreateProjectV2 struct {
+				ProjectV2 struct {
 					ID     string `json:"id"`
 					Number int    `json:"number"`
-					Title  string `json:"title"`
-				} `json:"issue"`
-			} `json:"repository"`
+				} `json:"projectV2"`
+			} `json:"createProjectV2"`
 		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+		Errors GraphQLErrors `json:"errors"`
 	}
 
-	resp, err := p.client.Do(ctx, req, &issueResult)
+	resp, err := p.client.Do(ctx, req, &result)
 	if err != nil {
-		if resp != nil && resp.Body != nil {
-			if resp.StatusCode != 200 {
-				bodyBytes, _ := io.ReadAll(resp.Body)
-				if cerr := resp.Body.Close(); cerr != nil {
-					slog.Warn("failed to close response body", "error", cerr)
-				}
-				return fmt.Errorf("failed to get issue (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+		if resp != nil {
+			return nil, fmt.Errorf("failed to create project (status: %d): %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("failed to execute GraphQL request for project: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, graphQLError("creating project", result.Errors)
+	}
+
+	project := result.Data.CreateProjectV2.ProjectV2
+	slog.Info("project created", "title", name, "number", project.Number)
+	return &ProjectInfo{ProjectID: project.ID, ProjectNumber: project.Number, ProjectOwner: p.owner}, nil
+}
+
+// DeleteProject deletes a Project v2. The issues of the project are kept.
+func (p *GitHubProvider) DeleteProject(ctx context.Context, project *ProjectInfo) error {
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+		"query":     mutationDeleteProjectV2,
+		"variables": map[string]interface{}{"projectId": project.ProjectID},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create GraphQL request for project: %w", err)
+	}
+
+	var result struct {
+		Errors GraphQLErrors `json:"errors"`
+	}
+	resp, err := p.client.Do(ctx, req, &result)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to delete project (status: %d): %w", resp.StatusCode, err)
+		}
+		return fmt.Errorf("failed to execute GraphQL request for project: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return graphQLError("deleting project", result.Errors)
+	}
+	slog.Info("project deleted", "number", project.ProjectNumber)
+	return nil
+}
+
+// CreateRepository creates a private repository of the owner, as an organization repository when
+// the owner is not the authenticated user, and returns a provider for it with the same token.
+func (p *GitHubProvider) CreateRepository(ctx context.Context, name string) (*GitHubProvider, error) {
+	user, _, err := p.client.Users.Get(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authenticated user: %w", err)
+	}
+	org := p.owner
+	if strings.EqualFold(user.GetLogin(), p.owner) {
+		org = ""
+	}
+	repo, _, err := p.client.Repositories.Create(ctx, org, &github.Repository{Name: github.String(name), Private: github.Bool(true), HasIssues: github.Bool(true)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create repository %s/%s: %w", p.owner, name, err)
+	}
+	slog.Info("repository created", "repository", repo.GetFullName())
+	// A copy, so the provider of the repository takes the same paths as p, e.g. for fine-grained tokens
+	np := *p
+	np.repo = repo.GetName()
+	return &np, nil
+}
+
+// DeleteRepository deletes the repository of the provider along with its issues.
+func (p *GitHubProvider) DeleteRepository(ctx context.Context) error {
+	if _, err := p.client.Repositories.Delete(ctx, p.owner, p.repo); err != nil {
+		return fmt.Errorf("failed to delete repository %s/%s: %w", p.owner, p.repo, err)
+	}
+	slog.Info("repository deleted", "repository", p.owner+"/"+p.repo)
+	return nil
+}
+
+// ListProjectItems returns the open issues of a Project v2 in the order of the board, with their
+// Status and the number field named estimateField. Draft issues, pull requests and closed issues
+// are left out.
+func (p *GitHubProvider) ListProjectItems(ctx context.Context, project *ProjectInfo, estimateField string) ([]ProjectItem, error) {
+	var items []ProjectItem
+	var cursor *string
+	for {
+		req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+			"query":     queryProjectV2Items,
+			"variables": map[string]interface{}{"projectId": project.ProjectID, "cursor": cursor},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GraphQL request for project items: %w", err)
+		}
+
+		var result struct {
+			Data struct {
+				Node struct {
+					Items struct {
+						Nodes []struct {
+							FieldValues struct {
+								Nodes []struct {
+									Name   string   `json:"name"`
+									Number *float64 `json:"number"`
+									Field  struct {
+										Name string `json:"name"`
+									} `json:"field"`
+								} `json:"nodes"`
+							} `json:"fieldValues"`
+							Content struct {
+								Number int    `json:"number"`
+								Title  string `json:"title"`
+								URL    string `json:"url"`
+								State  string `json:"state"`
+								Labels struct {
+									Nodes []struct {
+										Name string `json:"name"`
+									} `json:"nodes"`
+								} `json:"labels"`
+							} `json:"content"`
+						} `json:"nodes"`
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+					} `json:"items"`
+				} `json:"node"`
+			} `json:"data"`
+			Errors GraphQLErrors `json:"errors"`
+		}
+
+		resp, err := p.client.Do(ctx, req, &result)
+		if err != nil {
+			if resp != nil {
+				return nil, fmt.Errorf("failed to list project items (status: %d): %w", resp.StatusCode, err)
 			}
-			if cerr := resp.Body.Close(); cerr != nil {
-				slog.Warn("failed to close response body", "error", cerr)
+			return nil, fmt.Errorf("failed to execute GraphQL request for project items: %w", err)
+		}
+		if len(result.Errors) > 0 {
+			return nil, graphQLError("listing project items", result.Errors)
+		}
+
+		for _, node := range result.Data.Node.Items.Nodes {
+			content := node.Content
+			if content.Number == 0 || content.State != "OPEN" {
+				continue
+			}
+			item := ProjectItem{Number: content.Number, Title: content.Title, URL: content.URL}
+			for _, l := range content.Labels.Nodes {
+				item.Labels = append(item.Labels, l.Name)
+			}
+			for _, value := range node.FieldValues.Nodes {
+				switch {
+				case value.Field.Name == "Status":
+					item.Status = value.Name
+				case value.Number != nil && strings.EqualFold(value.Field.Name, estimateField):
+					item.Estimate = *value.Number
+				}
 			}
+			items = append(items, item)
+		}
+		if !result.Data.Node.Items.PageInfo.HasNextPage {
+			return items, nil
 		}
-		return fmt.Errorf("failed to execute GraphQL request for issue: %w", err)
+		cursor = &result.Data.Node.Items.PageInfo.EndCursor
 	}
+}
 
-	if resp.StatusCode != 200 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		if cerr := resp.Body.Close(); cerr != nil {
-			slog.Warn("failed to close response body", "error", cerr)
+// ListProjectFields returns the fields of a Project v2 with their data type, the options of the
+// single select fields and the iterations of the iteration fields, completed ones included, with
+// their node IDs.
+func (p *GitHubProvider) ListProjectFields(ctx context.Context, project *ProjectInfo) ([]ProjectField, error) {
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+		"query":     queryProjectV2Fields,
+		"variables": map[string]interface{}{"projectId": project.ProjectID},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request for project fields: %w", err)
+	}
+
+	type iteration struct {
+		ID        string `json:"id"`
+		Title     string `json:"title"`
+		StartDate string `json:"startDate"`
+		Duration  int    `json:"duration"`
+	}
+	var result struct {
+		Data struct {
+			Node struct {
+				Fields struct {
+					Nodes []struct {
+						ID       string `json:"id"`
+						Name     string `json:"name"`
+						DataType string `json:"dataType"`
+						Options  []struct {
+							ID   string `json:"id"`
+							Name string `json:"name"`
+						} `json:"options"`
+						Configuration struct {
+							Iterations          []iteration `json:"iterations"`
+							CompletedIterations []iteration `json:"completedIterations"`
+						} `json:"configuration"`
+					} `json:"nodes"`
+				} `json:"fields"`
+			} `json:"node"`
+		} `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}
+
+	resp, err := p.client.Do(ctx, req, &result)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to list project fields (status: %d): %w", resp.StatusCode, err)
 		}
-		return fmt.Errorf("failed to get issue (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+		return nil, fmt.Errorf("failed to execute GraphQL request for project fields: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, graphQLError("listing project fields", result.Errors)
 	}
 
-	if len(issueResult.Errors) > 0 {
-		for _, err := range issueResult.Errors {
-			slog.Error("graphql error", "message", err.Message)
+	fields := make([]ProjectField, 0, len(result.Data.Node.Fields.Nodes))
+	for _, node := range result.Data.Node.Fields.Nodes {
+		field := ProjectField{ID: node.ID, Name: node.Name, DataType: node.DataType}
+		for _, o := range node.Options {
+			field.Options = append(field.Options, FieldOption{ID: o.ID, Name: o.Name})
 		}
-		if cerr := resp.Body.Close(); cerr != nil {
-			slog.Warn("failed to close response body", "error", cerr)
+		for _, it := range append(node.Configuration.Iterations, node.Configuration.CompletedIterations...) {
+			field.Options = append(field.Options, FieldOption{ID: it.ID, Name: it.Title, StartDate: it.StartDate, Duration: it.Duration})
 		}
-		return fmt.Errorf("graphql errors occurred while getting issue")
+		fields = append(fields, field)
 	}
+	return fields, nil
+}
 
-	slog.Debug("got issue details",
-		"issue_id", issueResult.Data.Repository.Issue.ID,
-		"issue_number", issueResult.Data.Repository.Issue.Number,
-		"issue_title", issueResult.Data.Repository.Issue.Title)
+// CreateDraftItem creates a draft issue in a GitHub Project v2 using addProjectV2DraftIssue. Draft
+// items have no number nor URL and belong to the project only, not to the repository.
+func (p *GitHubProvider) CreateDraftItem(ctx context.Context, title, description string, project *ProjectInfo) (Issue, error) {
+	if project == nil {
+		return nil, fmt.Errorf("draft items belong to a project: set the Parent of the row to the name of a project")
+	}
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+		"query":     mutationAddProjectV2DraftIssue,
+		"variables": map[string]interface{}{"projectId": project.ProjectID, "title": title, "body": description},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GraphQL request for draft item: %w", err)
+	}
+
+	var result struct {
+		Data struct {
+			AddProjectV2DraftIssue struct {
+				ProjectItem struct {
+					ID string `json:"id"`
+				} `json:"projectItem"`
+			} `json:"addProjectV2DraftIssue"`
+		} `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}
+
+	resp, err := p.client.Do(ctx, req, &result)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to create draft item (status: %d): %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("failed to execute GraphQL request for draft item: %w", err)
+	}
+	if len(result.Errors) > 0 {
+		return nil, graphQLError("creating draft item", result.Errors)
+	}
+
+	item := result.Data.AddProjectV2DraftIssue.ProjectItem
+	slog.Info("draft item created", "project_number", project.ProjectNumber, "project_item_id", item.ID, "title", title)
+	return &DraftItem{ItemID: item.ID, title: title, description: description}, nil
+}
+
+// addIssueToProject adds an existing issue to a GitHub Project v2 using addProjectV2ItemById and
+// returns the node ID of the project item.
+func (p *GitHubProvider) addIssueToProject(ctx context.Context, issue *github.Issue, project *ProjectInfo) (string, error) {
+	slog.Debug("adding issue to project",
+		"issue_number", issue.GetNumber(),
+		"project_number", project.ProjectNumber,
+		"project_id", project.ProjectID,
+		"owner", p.owner,
+		"repo", p.repo)
+
+	// The REST response carries the node ID, the GraphQL lookup is only needed when it is missing
+	contentID := issue.GetNodeID()
+	if contentID == "" {
+		var err error
+		if contentID, err = p.issueNodeID(ctx, issue.GetNumber()); err != nil {
+			return "", err
+		}
+	}
 
-	// 2. Adicionar ao projeto
-	varsMutation := map[string]interface{}{"projectId": project.ProjectID, "contentId": issueResult.Data.Repository.Issue.ID}
-	req, err = p.client.NewRequest("POST", "graphql", map[string]interface{}{
+	varsMutation := map[string]interface{}{"projectId": project.ProjectID, "contentId": contentID}
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
 		"query":     mutationAddProjectV2ItemByID,
 		"variables": varsMutation,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to create GraphQL request for adding to project: %w", err)
+		return "", fmt.Errorf("failed to create GraphQL request for adding to project: %w", err)
 	}
 
 	var mutationResult struct {
@@ -355,30 +1122,28 @@ func (p *GitHubProvider) addIssueToProject(ctx context.Context, issue *github.Is
 				} `json:"item"`
 			} `json:"addProjectV2ItemById"`
 		} `json:"data"`
-		Errors []struct {
-			Message string `json:"message"`
-		} `json:"errors"`
+		Errors GraphQLErrors `json:"errors"`
 	}
 
-	resp, err = p.client.Do(ctx, req, &mutationResult)
+	resp, err := p.client.Do(ctx, req, &mutationResult)
 	if err != nil {
 		if resp == nil || resp.Body == nil {
-			return fmt.Errorf("failed to execute GraphQL request for adding to project: %w", err)
+			return "", fmt.Errorf("failed to execute GraphQL request for adding to project: %w", err)
 		}
 		if resp.StatusCode != 200 {
 			bodyBytes, _ := io.ReadAll(resp.Body)
 			if cerr := resp.Body.Close(); cerr != nil {
 				slog.Warn("failed to close response body", "error", cerr)
 			}
-			return fmt.Errorf("failed to add issue to project (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+			return "", fmt.Errorf("failed to add issue to project (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
 		}
 		if cerr := resp.Body.Close(); cerr != nil {
 			slog.Warn("failed to close response body", "error", cerr)
 		}
-		return fmt.Errorf("failed to execute GraphQL request for adding to project: %w", err)
+		return "", fmt.Errorf("failed to execute GraphQL request for adding to project: %w", err)
 	}
 	if resp == nil || resp.Body == nil {
-		return fmt.Errorf("response or response body is nil after GraphQL request for adding to project")
+		return "", fmt.Errorf("response or response body is nil after GraphQL request for adding to project")
 	}
 	defer func() {
 		if cerr := resp.Body.Close(); cerr != nil {
@@ -391,57 +1156,163 @@ func (p *GitHubProvider) addIssueToProject(ctx context.Context, issue *github.Is
 		if cerr := resp.Body.Close(); cerr != nil {
 			slog.Warn("failed to close response body", "error", cerr)
 		}
-		return fmt.Errorf("failed to add issue to project (status: %d, body: %s)", resp.StatusCode, string(bodyBytes))
+		return "", fmt.Errorf("failed to add issue to project (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
 	}
 
 	if len(mutationResult.Errors) > 0 {
-		for _, err := range mutationResult.Errors {
-			slog.Error("graphql error", "message", err.Message)
-		}
-		return fmt.Errorf("graphql errors occurred while adding to project")
+		return "", graphQLError("adding to project", mutationResult.Errors)
 	}
 
 	slog.Info("issue added to project",
-		"issue_number", issueResult.Data.Repository.Issue.Number,
+		"issue_number", issue.GetNumber(),
 		"project_number", project.ProjectNumber,
 		"project_item_id", mutationResult.Data.AddProjectV2ItemByID.Item.ID,
 		"issue_title", mutationResult.Data.AddProjectV2ItemByID.Item.Content.Title)
-	return nil
+	return mutationResult.Data.AddProjectV2ItemByID.Item.ID, nil
+}
+
+// issueNodeID fetches the GraphQL node ID of an issue by its number.
+func (p *GitHubProvider) issueNodeID(ctx context.Context, number int) (string, error) {
+	vars := map[string]interface{}{"owner": p.owner, "repo": p.repo, "number": number}
+	req, err := p.client.NewRequest("POST", p.graphQLEndpoint(), map[string]interface{}{
+		"query":     queryIssueNodeID,
+		"variables": vars,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create GraphQL request for issue: %w", err)
+	}
+
+	var issueResult struct {
+		Data struct {
+			Repository struct {
+				Issue struct {
+					ID     string `json:"id"`
+					Number int    `json:"number"`
+					Title  string `json:"title"`
+				} `json:"issue"`
+			} `json:"repository"`
+		} `json:"data"`
+		Errors GraphQLErrors `json:"errors"`
+	}
+
+	resp, err := p.client.Do(ctx, req, &issueResult)
+	if err != nil {
+		if resp != nil && resp.Body != nil {
+			if resp.StatusCode != 200 {
+				bodyBytes, _ := io.ReadAll(resp.Body)
+				if cerr := resp.Body.Close(); cerr != nil {
+					slog.Warn("failed to close response body", "error", cerr)
+				}
+				return "", fmt.Errorf("failed to get issue (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
+			}
+			if cerr := resp.Body.Close(); cerr != nil {
+				slog.Warn("failed to close response body", "error", cerr)
+			}
+		}
+		return "", fmt.Errorf("failed to execute GraphQL request for issue: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Warn("failed to close response body", "error", cerr)
+		}
+		return "", fmt.Errorf("failed to get issue (status: %d, body: %s)", resp.StatusCode, redact.Secrets(string(bodyBytes)))
+	}
+
+	if len(issueResult.Errors) > 0 {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Warn("failed to close response body", "error", cerr)
+		}
+		return "", graphQLError("getting issue", issueResult.Errors)
+	}
+
+	slog.Debug("got issue node ID",
+		"issue_id", issueResult.Data.Repository.Issue.ID,
+		"issue_number", issueResult.Data.Repository.Issue.Number,
+		"issue_title", issueResult.Data.Repository.Issue.Title)
+	if cerr := resp.Body.Close(); cerr != nil {
+		slog.Warn("failed to close response body", "error", cerr)
+	}
+	return issueResult.Data.Repository.Issue.ID, nil
 }
 
 // AddSubIssue adds sub-issue to a parent issue using the GitHub REST API.
-func (p *GitHubProvider) AddSubIssue(parentNumber int, childID int64) error {
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/issues/%d/sub_issues", p.owner, p.repo, parentNumber)
-	slog.Debug("adding sub-issues", "url", url, "parent_number", parentNumber, "child_id", childID)
+func (p *GitHubProvider) AddSubIssue(ctx context.Context, parentNumber int, childID int64) error {
+	path := fmt.Sprintf("repos/%s/%s/issues/%d/sub_issues", p.owner, p.repo, parentNumber)
+	slog.Debug("adding sub-issues", "path", path, "parent_number", parentNumber, "child_id", childID)
 	body := map[string]interface{}{
 		"sub_issue_id": childID,
 	}
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		return fmt.Errorf("failed to marshal sub-issues body: %w", err)
-	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonBody))
+	req, err := p.client.NewRequest("POST", path, body)
 	if err != nil {
 		return fmt.Errorf("failed to create sub-issues request: %w", err)
 	}
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", os.Getenv("GITHUB_TOKEN")))
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := p.client.Do(ctx, req, nil)
 	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("failed to add sub-issues (status: %d): %w", resp.StatusCode, err)
+		}
 		return fmt.Errorf("failed to execute sub-issues request: %w", err)
 	}
-	defer func() {
-		if cerr := resp.Body.Close(); cerr != nil {
-			slog.Warn("failed to close response body", "error", cerr)
+	return nil
+}
+
+// GraphQLError is an error of a GraphQL response, which GitHub returns with a 200 status, e.g. when
+// the token is missing a scope.
+type GraphQLError struct {
+	Type    string `json:"type"` // e.g. FORBIDDEN, INSUFFICIENT_SCOPES or NOT_FOUND
+	Message string `json:"message"`
+}
+
+// GraphQLErrors are the errors of a GraphQL response.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+		if err.Type != "" {
+			messages[i] += " (" + err.Type + ")"
 		}
-	}()
+	}
+	return strings.Join(messages, "; ")
+}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		respBody, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("failed to add sub-issues (status: %d, body: %s)", resp.StatusCode, string(respBody))
+// Hint returns the fix of the most common errors, such as a missing scope or an organization that
+// requires SAML single sign-on, or an empty string.
+func (e GraphQLErrors) Hint() string {
+	for _, err := range e {
+		message := strings.ToLower(err.Message)
+		switch {
+		case err.Type == "INSUFFICIENT_SCOPES" || strings.Contains(message, "required scopes"):
+			return "the token is missing a scope: Projects need the project scope (read:project to read them), " +
+				"e.g. gh auth refresh -s project, or the Projects read and write permission of a fine-grained token"
+		case strings.Contains(message, "saml") || strings.Contains(message, "single sign-on"):
+			return "the organization requires SAML single sign-on: authorize the token for it in the token settings " +
+				"(Configure SSO) at https://github.com/settings/tokens"
+		case err.Type == "FORBIDDEN" || strings.Contains(message, "not accessible by"):
+			return "the token cannot access the resource: check it has write access to the issues of the repository " +
+				"and to the projects of the owner"
+		case err.Type == "NOT_FOUND":
+			return "check the owner, repository and project exist and the token can see them"
+		case err.Type == "RATE_LIMITED":
+			return "the GraphQL rate limit is exceeded: wait for it to reset and run again"
+		}
 	}
-	return nil
+	return ""
+}
+
+// graphQLError returns the error of a GraphQL response while doing action, with its messages and
+// the hint of the errors. The returned error wraps the GraphQLErrors.
+func graphQLError(action string, errs GraphQLErrors) error {
+	for _, err := range errs {
+		slog.Debug("graphql error", "action", action, "type", err.Type, "message", err.Message)
+	}
+	if hint := errs.Hint(); hint != "" {
+		return fmt.Errorf("graphql errors occurred while %s: %w (hint: %s)", action, errs, hint)
+	}
+	return fmt.Errorf("graphql errors occurred while %s: %w", action, errs)
 }
@@ -4,6 +4,7 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
@@ -54,7 +55,7 @@ func TestGitHubProvider_Integration_CreateIssue(t *testing.T) {
 	}
 
 	t.Logf("Creating issue: title=%s, owner=%s, repo=%s, project=%v", title, owner, repo, project)
-	createdIssue, err := provider.CreateIssue(title, description, labels, project)
+	createdIssue, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: title, Body: description, Labels: labels, Project: project})
 	if err != nil {
 		t.Fatalf("Failed to create issue: %v\nPlease verify:\n1. The token has 'repo' scope\n2. The repository exists and is accessible\n3. The owner/repo combination is correct", err)
 	}
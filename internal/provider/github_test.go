@@ -6,11 +6,15 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"net/url"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v60/github"
+	"github.com/leocomelli/aigile/pkg/githubtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // mockIssuesService is a mock implementation of the IssuesService interface for testing.
@@ -28,6 +32,66 @@ func (m *mockIssuesService) Edit(ctx context.Context, owner string, repo string,
 	return args.Get(0).(*github.Issue), args.Get(1).(*github.Response), args.Error(2)
 }
 
+func (m *mockIssuesService) Get(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, number)
+	return args.Get(0).(*github.Issue), args.Get(1).(*github.Response), args.Error(2)
+}
+
+func (m *mockIssuesService) ListByRepo(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, opts)
+	return args.Get(0).([]*github.Issue), args.Get(1).(*github.Response), args.Error(2)
+}
+
+func (m *mockIssuesService) CreateComment(ctx context.Context, owner string, repo string, number int, comment *github.IssueComment) (*github.IssueComment, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, number, comment)
+	return args.Get(0).(*github.IssueComment), args.Get(1).(*github.Response), args.Error(2)
+}
+
+func (m *mockIssuesService) ListMilestones(ctx context.Context, owner string, repo string, opts *github.MilestoneListOptions) ([]*github.Milestone, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, opts)
+	return args.Get(0).([]*github.Milestone), args.Get(1).(*github.Response), args.Error(2)
+}
+
+// mockRepositoriesService is a mock implementation of the RepositoriesService interface for testing.
+type mockRepositoriesService struct {
+	mock.Mock
+}
+
+func (m *mockRepositoriesService) Get(ctx context.Context, owner string, repo string) (*github.Repository, *github.Response, error) {
+	args := m.Called(ctx, owner, repo)
+	return args.Get(0).(*github.Repository), args.Get(1).(*github.Response), args.Error(2)
+}
+
+func (m *mockRepositoriesService) CreateFile(ctx context.Context, owner string, repo string, path string, opts *github.RepositoryContentFileOptions) (*github.RepositoryContentResponse, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, path, opts)
+	return args.Get(0).(*github.RepositoryContentResponse), args.Get(1).(*github.Response), args.Error(2)
+}
+
+// mockGitService is a mock implementation of the GitService interface for testing.
+type mockGitService struct {
+	mock.Mock
+}
+
+func (m *mockGitService) GetRef(ctx context.Context, owner string, repo string, ref string) (*github.Reference, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, ref)
+	return args.Get(0).(*github.Reference), args.Get(1).(*github.Response), args.Error(2)
+}
+
+func (m *mockGitService) CreateRef(ctx context.Context, owner string, repo string, ref *github.Reference) (*github.Reference, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, ref)
+	return args.Get(0).(*github.Reference), args.Get(1).(*github.Response), args.Error(2)
+}
+
+// mockPullRequestsService is a mock implementation of the PullRequestsService interface for testing.
+type mockPullRequestsService struct {
+	mock.Mock
+}
+
+func (m *mockPullRequestsService) Create(ctx context.Context, owner string, repo string, pull *github.NewPullRequest) (*github.PullRequest, *github.Response, error) {
+	args := m.Called(ctx, owner, repo, pull)
+	return args.Get(0).(*github.PullRequest), args.Get(1).(*github.Response), args.Error(2)
+}
+
 // mockHTTPClient is a mock implementation of the HTTP client for testing GraphQL requests.
 type mockHTTPClient struct {
 	mock.Mock
@@ -78,7 +142,7 @@ func TestGitHubProvider_CreateIssue_Success(t *testing.T) {
 	).Return(expectedIssue, mockResponse, nil)
 
 	// Act
-	createdIssue, err := provider.CreateIssue("Test Issue", "Test Description", []string{"bug"}, nil)
+	createdIssue, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Test Issue", Body: "Test Description", Labels: []string{"bug"}})
 
 	// Assert
 	assert.NoError(t, err)
@@ -128,7 +192,7 @@ func TestGitHubProvider_CreateIssue_WithProject(t *testing.T) {
 	}
 
 	// Act
-	createdIssue, err := provider.CreateIssue("Test Issue", "Test Description", []string{"bug"}, project)
+	createdIssue, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "Test Issue", Body: "Test Description", Labels: []string{"bug"}, Project: project})
 
 	// Assert
 	assert.NoError(t, err)
@@ -167,7 +231,7 @@ func TestGitHubProvider_CreateIssue_Error(t *testing.T) {
 	).Return(&github.Issue{}, mockResponse, errors.New("validation failed"))
 
 	// Act
-	createdIssue, err := provider.CreateIssue("", "Test Description", []string{"bug"}, nil)
+	createdIssue, err := provider.CreateIssue(context.Background(), CreateIssueRequest{Title: "", Body: "Test Description", Labels: []string{"bug"}})
 
 	// Assert
 	assert.Error(t, err)
@@ -382,8 +446,34 @@ func TestGitHubProvider_addIssueToProject_Success(t *testing.T) {
 	issue := &github.Issue{Number: github.Int(1)}
 	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
 
-	err := provider.addIssueToProject(context.Background(), issue, project)
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
+	assert.NoError(t, err)
+}
+
+// TestGitHubProvider_addIssueToProject_NodeIDFromREST tests that the node ID of the REST response
+// is used without looking it up.
+func TestGitHubProvider_addIssueToProject_NodeIDFromREST(t *testing.T) {
+	mockClient := new(mockHTTPClient)
+	client := github.NewClient(&http.Client{Transport: &mockTransport{mock: mockClient}})
+	provider := &GitHubProvider{
+		owner:  "testowner",
+		repo:   "testrepo",
+		client: client,
+	}
+
+	addProjectResponse := `{"data":{"addProjectV2ItemById":{"item":{"id":"item-id","content":{"number":1,"title":"Test Issue"}}}}}`
+	resp := &http.Response{
+		StatusCode: 200,
+		Body:       io.NopCloser(bytes.NewBufferString(addProjectResponse)),
+	}
+	mockClient.On("Do", mock.Anything).Return(resp, nil).Once()
+
+	issue := &github.Issue{Number: github.Int(1), NodeID: github.String("issue-node-id")}
+	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
+
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
 	assert.NoError(t, err)
+	mockClient.AssertNumberOfCalls(t, "Do", 1)
 }
 
 // TestGitHubProvider_addIssueToProject_NodeIDError tests error handling when fetching the issue node ID fails.
@@ -405,7 +495,7 @@ func TestGitHubProvider_addIssueToProject_NodeIDError(t *testing.T) {
 	issue := &github.Issue{Number: github.Int(1)}
 	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
 
-	err := provider.addIssueToProject(context.Background(), issue, project)
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to execute GraphQL request for issue")
 }
@@ -437,7 +527,7 @@ func TestGitHubProvider_addIssueToProject_AddProjectError(t *testing.T) {
 	issue := &github.Issue{Number: github.Int(1)}
 	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
 
-	err := provider.addIssueToProject(context.Background(), issue, project)
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to execute GraphQL request for adding to project")
 }
@@ -463,7 +553,7 @@ func TestGitHubProvider_addIssueToProject_GraphQLError(t *testing.T) {
 	issue := &github.Issue{Number: github.Int(1)}
 	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
 
-	err := provider.addIssueToProject(context.Background(), issue, project)
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "graphql errors occurred while getting issue")
 }
@@ -495,7 +585,640 @@ func TestGitHubProvider_addIssueToProject_StatusCodeNot200(t *testing.T) {
 	issue := &github.Issue{Number: github.Int(1)}
 	project := &ProjectInfo{ProjectID: "project-id", ProjectNumber: 1}
 
-	err := provider.addIssueToProject(context.Background(), issue, project)
+	_, err := provider.addIssueToProject(context.Background(), issue, project)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to add issue to project (status: 403, body: forbidden)")
 }
+
+// newFakeGitHubProvider creates a GitHubProvider backed by a fake GitHub server.
+func newFakeGitHubProvider(t *testing.T) (*GitHubProvider, *githubtest.Server) {
+	server := githubtest.NewServer("testowner", "testrepo")
+	t.Cleanup(server.Close)
+	p, err := NewGitHubProvider(GitHubConfig{Token: "token", Owner: "testowner", Repo: "testrepo", BaseURL: server.BaseURL()})
+	require.NoError(t, err)
+	return p, server
+}
+
+// TestGitHubProvider_FakeServer_CreateIssueWithProjectAndSubIssue tests the full creation flow against the fake server.
+func TestGitHubProvider_FakeServer_CreateIssueWithProjectAndSubIssue(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+
+	story, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story"}, Project: project})
+	require.NoError(t, err)
+	task, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Task", Body: "Body", Labels: []string{"Task"}, Project: project})
+	require.NoError(t, err)
+	require.NoError(t, p.AddSubIssue(ctx, story.GetNumber(), task.GetID()))
+
+	assert.Len(t, server.Issues(), 2)
+	assert.Equal(t, []int64{task.GetID()}, server.SubIssues(story.GetNumber()))
+	board, _ := server.Project("Board")
+	assert.Equal(t, []string{"I_1", "I_2"}, board.Items)
+	// Project lookup, then creation and project item of each issue, then the sub-issue
+	assert.Equal(t, 6, server.Requests())
+}
+
+// TestGitHubProvider_FakeServer_CreateIssueRequest tests that the assignees, milestone, project and
+// parent of the request are set on the created issue against the fake server.
+func TestGitHubProvider_FakeServer_CreateIssueRequest(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	milestone := server.AddMilestone("Sprint 1")
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+
+	story, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Body: "Body"})
+	require.NoError(t, err)
+	task, err := p.CreateIssue(ctx, CreateIssueRequest{
+		Title:     "Task",
+		Body:      "Body",
+		Labels:    []string{"Task"},
+		Assignees: []string{"octocat"},
+		Milestone: "Sprint 1",
+		Project:   project,
+		Parent:    story.GetNumber(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "PVTI_1", task.ProjectItemID)
+	assert.Equal(t, "I_2", task.GetProviderID())
+	assert.Equal(t, StateOpen, task.GetState())
+	assert.Equal(t, []string{"Task"}, task.GetLabels())
+	assert.Equal(t, []int64{task.GetID()}, server.SubIssues(story.GetNumber()))
+	created := server.Issues()[1]
+	assert.Equal(t, []string{"octocat"}, created.Assignees)
+	assert.Equal(t, milestone, created.Milestone)
+
+	assert.Empty(t, task.Warnings)
+
+	other, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Other", Milestone: "Sprint 9"})
+	require.NoError(t, err, "an unknown milestone is a warning, the issue is created")
+	assert.Zero(t, server.Issues()[2].Milestone)
+	assert.Len(t, other.Warnings, 1)
+	assert.Contains(t, other.Warnings[0], `failed to set milestone "Sprint 9"`)
+}
+
+// TestGitHubProvider_Capabilities tests the capabilities and limits of GitHub.
+func TestGitHubProvider_Capabilities(t *testing.T) {
+	p, _ := newFakeGitHubProvider(t)
+	assert.Equal(t, Capabilities{Projects: true, SubIssues: true, Milestones: true, Labels: true, MaxTitleSize: 256, MaxBodySize: 65536}, p.Capabilities())
+}
+
+// TestGitHubProvider_FakeServer_CreateDraftItem tests creating a draft item of a project, which is
+// not an issue of the repository, against the fake server.
+func TestGitHubProvider_FakeServer_CreateDraftItem(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+
+	draft, err := p.CreateDraftItem(ctx, "Idea", "Body", project)
+	require.NoError(t, err)
+	assert.Equal(t, "Idea", draft.GetTitle())
+	assert.Zero(t, draft.GetNumber())
+	assert.Equal(t, "PVTI_draft_1", draft.(*DraftItem).ItemID)
+	assert.Equal(t, "PVTI_draft_1", draft.GetProviderID())
+	assert.Empty(t, server.Issues())
+	board, _ := server.Project("Board")
+	assert.Equal(t, []githubtest.DraftIssue{{ID: "PVTI_draft_1", Title: "Idea", Body: "Body"}}, board.Drafts)
+
+	_, err = p.CreateDraftItem(ctx, "Idea", "Body", nil)
+	assert.ErrorContains(t, err, "draft items belong to a project")
+	_, err = p.CreateDraftItem(ctx, "Idea", "Body", &ProjectInfo{ProjectID: "missing"})
+	assert.ErrorContains(t, err, "graphql errors occurred while creating draft item: Could not resolve to a node with the global id of 'missing' (NOT_FOUND)")
+}
+
+// TestGitHubProvider_FakeServer_CreateProject tests creating the project of a name that is not
+// found, which is then found by name, against the fake server.
+func TestGitHubProvider_FakeServer_CreateProject(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+
+	_, err := p.GetProjectByName(ctx, "Roadmap")
+	require.ErrorIs(t, err, ErrProjectNotFound)
+
+	created, err := p.CreateProject(ctx, "Roadmap")
+	require.NoError(t, err)
+	assert.Equal(t, &ProjectInfo{ProjectID: "PVT_2", ProjectNumber: 2, ProjectOwner: "testowner"}, created)
+	found, err := p.GetProjectByName(ctx, "Roadmap")
+	require.NoError(t, err)
+	assert.Equal(t, "PVT_2", found.ProjectID)
+}
+
+// TestGitHubProvider_FakeServer_Cleanup tests creating and deleting a repository and a project and
+// closing an issue, as a self-test does, against the fake server.
+func TestGitHubProvider_FakeServer_Cleanup(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+
+	scratch, err := p.CreateRepository(ctx, "scratch")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scratch"}, server.Repositories())
+	project, err := scratch.CreateProject(ctx, "Scratch")
+	require.NoError(t, err)
+	result, err := scratch.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Project: project})
+	require.NoError(t, err)
+
+	require.NoError(t, scratch.CloseIssue(ctx, result.Issue.GetNumber()))
+	assert.Equal(t, StateClosed, server.Issues()[0].State)
+	assert.Equal(t, "not_planned", server.Issues()[0].StateReason)
+	require.NoError(t, scratch.DeleteProject(ctx, project))
+	_, ok := server.Project("Scratch")
+	assert.False(t, ok)
+	assert.ErrorContains(t, scratch.DeleteProject(ctx, project), "NOT_FOUND")
+	require.NoError(t, scratch.DeleteRepository(ctx))
+	assert.Empty(t, server.Repositories())
+	assert.Error(t, p.DeleteRepository(ctx), "the fake repository cannot be deleted")
+}
+
+// TestGitHubProvider_FakeServer_ListProjectFields tests listing the fields of a board with their
+// options and iterations against the fake server.
+func TestGitHubProvider_FakeServer_ListProjectFields(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	server.AddField("Board", githubtest.Field{ID: "PVTF_1", Name: "Title", DataType: "TITLE"})
+	server.AddField("Board", githubtest.Field{ID: "PVTSSF_1", Name: "Status", DataType: "SINGLE_SELECT", Options: []githubtest.FieldOption{
+		{ID: "f75ad846", Name: "Todo"}, {ID: "98236657", Name: "Done"},
+	}})
+	server.AddField("Board", githubtest.Field{ID: "PVTIF_1", Name: "Sprint", DataType: "ITERATION", Options: []githubtest.FieldOption{
+		{ID: "c1c2", Name: "Sprint 1", StartDate: "2024-05-06", Duration: 14},
+	}})
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+
+	fields, err := p.ListProjectFields(ctx, project)
+	require.NoError(t, err)
+	assert.Equal(t, []ProjectField{
+		{ID: "PVTF_1", Name: "Title", DataType: "TITLE"},
+		{ID: "PVTSSF_1", Name: "Status", DataType: "SINGLE_SELECT", Options: []FieldOption{{ID: "f75ad846", Name: "Todo"}, {ID: "98236657", Name: "Done"}}},
+		{ID: "PVTIF_1", Name: "Sprint", DataType: "ITERATION", Options: []FieldOption{{ID: "c1c2", Name: "Sprint 1", StartDate: "2024-05-06", Duration: 14}}},
+	}, fields)
+
+	_, err = p.ListProjectFields(ctx, &ProjectInfo{ProjectID: "missing"})
+	assert.ErrorContains(t, err, "graphql errors occurred while listing project fields: Could not resolve to a node with the global id of 'missing' (NOT_FOUND)")
+}
+
+// TestGitHubProvider_FakeServer_ListProjectItems tests reading the open items of a board with their
+// status and estimate against the fake server.
+func TestGitHubProvider_FakeServer_ListProjectItems(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+	for _, title := range []string{"Pay by card", "Refunds", "Reports"} {
+		_, err := p.CreateIssue(ctx, CreateIssueRequest{Title: title, Labels: []string{"User Story"}, Project: project})
+		require.NoError(t, err)
+	}
+	server.SetItemField("Board", 1, "Status", "Todo")
+	server.SetItemField("Board", 1, "Story Points", 5)
+	server.SetItemField("Board", 2, "Status", "In Progress")
+	_, _, err = p.issues.Edit(ctx, "testowner", "testrepo", 3, &github.IssueRequest{State: github.String("closed")})
+	require.NoError(t, err)
+
+	items, err := p.ListProjectItems(ctx, project, "story points")
+	require.NoError(t, err)
+	assert.Equal(t, []ProjectItem{
+		{Number: 1, Title: "Pay by card", URL: "https://github.com/testowner/testrepo/issues/1", Labels: []string{"User Story"}, Status: "Todo", Estimate: 5},
+		{Number: 2, Title: "Refunds", URL: "https://github.com/testowner/testrepo/issues/2", Labels: []string{"User Story"}, Status: "In Progress"},
+	}, items)
+
+	_, err = p.ListProjectItems(ctx, &ProjectInfo{ProjectID: "missing"}, "Estimate")
+	assert.ErrorContains(t, err, "graphql errors occurred while listing project items: Could not resolve to a node with the global id of 'missing' (NOT_FOUND)")
+}
+
+// TestGitHubProvider_FakeServer_RateLimited tests that rate limit responses surface as errors.
+func TestGitHubProvider_FakeServer_RateLimited(t *testing.T) {
+	p, server := newFakeGitHubProvider(t)
+	server.RateLimit(1)
+
+	issue, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body"})
+	assert.Error(t, err)
+	assert.Nil(t, issue)
+	assert.Contains(t, err.Error(), "403")
+}
+
+// TestGitHubProvider_FakeServer_ServerError tests that 5xx responses surface as errors.
+func TestGitHubProvider_FakeServer_ServerError(t *testing.T) {
+	p, server := newFakeGitHubProvider(t)
+	server.FailWithStatus(1, http.StatusBadGateway)
+
+	err := p.AddSubIssue(context.Background(), 1, 2)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "status: 502")
+}
+
+// TestGitHubProvider_FakeServer_GraphQLError tests that GraphQL errors fail the project lookup.
+func TestGitHubProvider_FakeServer_GraphQLError(t *testing.T) {
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	server.GraphQLErrors(1, "FORBIDDEN", "Resource not accessible by integration")
+
+	project, err := p.GetProjectByName(context.Background(), "Board")
+	assert.Error(t, err)
+	assert.Nil(t, project)
+	assert.ErrorContains(t, err, "graphql errors occurred while getting projects: Resource not accessible by integration (FORBIDDEN) (hint: the token cannot access")
+	var errs GraphQLErrors
+	require.ErrorAs(t, err, &errs)
+	assert.Equal(t, GraphQLErrors{{Type: "FORBIDDEN", Message: "Resource not accessible by integration"}}, errs)
+}
+
+// TestGraphQLErrors_Hint tests the hints of the common GraphQL errors.
+func TestGraphQLErrors_Hint(t *testing.T) {
+	tests := map[string]struct {
+		errs     GraphQLErrors
+		expected string
+	}{
+		"insufficient scopes": {
+			errs: GraphQLErrors{{Type: "INSUFFICIENT_SCOPES", Message: "Your token has not been granted the required scopes to execute this query. " +
+				"The 'id' field requires one of the following scopes: ['read:project'], but your token has only been granted the: ['repo'] scopes."}},
+			expected: "the token is missing a scope",
+		},
+		"sso": {
+			errs:     GraphQLErrors{{Type: "FORBIDDEN", Message: "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization."}},
+			expected: "requires SAML single sign-on",
+		},
+		"forbidden":    {errs: GraphQLErrors{{Type: "FORBIDDEN", Message: "Resource not accessible by integration"}}, expected: "the token cannot access the resource"},
+		"not found":    {errs: GraphQLErrors{{Type: "NOT_FOUND", Message: "Could not resolve to a Repository"}}, expected: "check the owner"},
+		"rate limited": {errs: GraphQLErrors{{Type: "RATE_LIMITED", Message: "API rate limit exceeded"}}, expected: "rate limit"},
+		"unknown":      {errs: GraphQLErrors{{Message: "Something went wrong"}}},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if tt.expected == "" {
+				assert.Empty(t, tt.errs.Hint())
+				return
+			}
+			assert.Contains(t, tt.errs.Hint(), tt.expected)
+		})
+	}
+
+	errs := GraphQLErrors{{Type: "NOT_FOUND", Message: "a"}, {Message: "b"}}
+	assert.Equal(t, "a (NOT_FOUND); b", errs.Error())
+}
+
+// TestGitHubProvider_EditIssue tests updating only the body of an existing issue.
+func TestGitHubProvider_EditIssue(t *testing.T) {
+	mockIssues := new(mockIssuesService)
+	provider := &GitHubProvider{issues: mockIssues, owner: "testowner", repo: "testrepo", client: github.NewClient(nil)}
+
+	mockIssues.On("Edit", mock.Anything, "testowner", "testrepo", 7,
+		mock.MatchedBy(func(req *github.IssueRequest) bool {
+			return req.Title == nil && req.Body != nil && *req.Body == "new body"
+		}),
+	).Return(&github.Issue{Number: github.Int(7), Body: github.String("new body")}, &github.Response{Response: &http.Response{StatusCode: 200}}, nil)
+
+	issue, err := provider.EditIssue(context.Background(), 7, "", "new body")
+	assert.NoError(t, err)
+	assert.Equal(t, "new body", issue.GetBody())
+	mockIssues.AssertExpectations(t)
+}
+
+// TestGitHubProvider_FakeServer_EditIssue tests editing an issue against the fake server.
+func TestGitHubProvider_FakeServer_EditIssue(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	created, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Body: "Body"})
+	require.NoError(t, err)
+
+	_, err = p.EditIssue(ctx, created.GetNumber(), "", "Body\n- [ ] #2")
+	require.NoError(t, err)
+	assert.Equal(t, "Body\n- [ ] #2", server.Issues()[0].Body)
+	assert.Equal(t, "Story", server.Issues()[0].Title)
+
+	_, err = p.EditIssue(ctx, 99, "", "x")
+	assert.Error(t, err)
+}
+
+// TestGitHubProvider_FakeServer_GetIssue tests reading an existing issue against the fake server.
+func TestGitHubProvider_FakeServer_GetIssue(t *testing.T) {
+	ctx := context.Background()
+	p, _ := newFakeGitHubProvider(t)
+	created, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Checkout revamp", Body: "- Pay by card\n- Refunds", Labels: []string{"Epic"}})
+	require.NoError(t, err)
+
+	issue, err := p.GetIssue(ctx, created.GetNumber())
+	require.NoError(t, err)
+	assert.Equal(t, "Checkout revamp", issue.GetTitle())
+	assert.Equal(t, "- Pay by card\n- Refunds", issue.GetBody())
+	assert.Equal(t, created.GetID(), issue.GetID())
+
+	_, err = p.GetIssue(ctx, 99)
+	assert.ErrorContains(t, err, "failed to get issue #99 (status: 404")
+}
+
+// TestGitHubProvider_FakeServer_ListIssuesAndComment tests listing issues by label and commenting
+// on them against the fake server.
+func TestGitHubProvider_FakeServer_ListIssuesAndComment(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	_, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Historia", Body: "Cuerpo", Labels: []string{"lang:es"}})
+	require.NoError(t, err)
+	_, err = p.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story"}})
+	require.NoError(t, err)
+
+	issues, err := p.ListIssues(ctx, IssueFilter{Labels: []string{"lang:es"}})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Historia", issues[0].GetTitle())
+	assert.Equal(t, []string{"lang:es"}, issues[0].GetLabels())
+
+	require.NoError(t, p.AddComment(ctx, 1, "Translation"))
+	assert.Equal(t, []string{"Translation"}, server.Issues()[0].Comments)
+	assert.ErrorContains(t, p.AddComment(ctx, 99, "x"), "failed to comment on issue #99 (status: 404")
+}
+
+// TestGitHubProvider_FakeServer_ListIssuesByMilestone tests listing the closed issues of a milestone
+// against the fake server.
+func TestGitHubProvider_FakeServer_ListIssuesByMilestone(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddMilestone("v1.1")
+	v12 := server.AddMilestone("v1.2")
+	for _, title := range []string{"Pay by card", "Refunds", "Reports"} {
+		_, err := p.CreateIssue(ctx, CreateIssueRequest{Title: title, Labels: []string{"User Story"}})
+		require.NoError(t, err)
+	}
+	for _, number := range []int{1, 2} {
+		_, _, err := p.issues.Edit(ctx, "testowner", "testrepo", number, &github.IssueRequest{State: github.String("closed"), Milestone: &v12})
+		require.NoError(t, err)
+	}
+	_, _, err := p.issues.Edit(ctx, "testowner", "testrepo", 3, &github.IssueRequest{Milestone: &v12})
+	require.NoError(t, err)
+
+	issues, err := p.ListIssues(ctx, IssueFilter{State: "closed", Milestone: "v1.2"})
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	assert.Equal(t, "Pay by card", issues[0].GetTitle())
+	assert.Equal(t, "Refunds", issues[1].GetTitle())
+
+	issues, err = p.ListIssues(ctx, IssueFilter{State: "closed", Milestone: "v1.1"})
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+
+	_, err = p.ListIssues(ctx, IssueFilter{Milestone: "v2.0"})
+	assert.EqualError(t, err, "milestone not found: v2.0")
+}
+
+// TestGitHubProvider_FakeServer_ProposeChange tests opening a pull request with new files against
+// the fake server.
+func TestGitHubProvider_FakeServer_ProposeChange(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	change := Change{
+		Branch:  "aigile/tests-1",
+		Message: "Add test skeletons for #1",
+		Title:   "Test skeletons for #1",
+		Body:    "Refs #1",
+		Files:   map[string]string{"features/1-pay.feature": "Feature: Pay\n"},
+	}
+
+	pr, err := p.ProposeChange(ctx, change)
+	require.NoError(t, err)
+	assert.Equal(t, 1, pr.GetNumber())
+	assert.Equal(t, "https://github.com/testowner/testrepo/pull/1", pr.GetHTMLURL())
+	assert.Equal(t, []githubtest.PullRequest{{
+		Number: 1, Title: "Test skeletons for #1", Body: "Refs #1", Head: "aigile/tests-1", Base: "main",
+		Files: map[string]string{"features/1-pay.feature": "Feature: Pay\n"},
+	}}, server.PullRequests())
+
+	_, err = p.ProposeChange(ctx, change)
+	assert.ErrorContains(t, err, "failed to create branch aigile/tests-1")
+}
+
+// TestGitHubProvider_ProposeChange tests that the branch of a change is created from the default
+// branch, that its files are committed in order and that the pull request targets the default branch.
+func TestGitHubProvider_ProposeChange(t *testing.T) {
+	repos := new(mockRepositoriesService)
+	git := new(mockGitService)
+	pulls := new(mockPullRequestsService)
+	p := &GitHubProvider{repos: repos, git: git, pulls: pulls, owner: "testowner", repo: "testrepo"}
+	resp := &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}
+
+	repos.On("Get", mock.Anything, "testowner", "testrepo").Return(&github.Repository{DefaultBranch: github.String("trunk")}, resp, nil)
+	git.On("GetRef", mock.Anything, "testowner", "testrepo", "heads/trunk").
+		Return(&github.Reference{Object: &github.GitObject{SHA: github.String("abc123")}}, resp, nil)
+	git.On("CreateRef", mock.Anything, "testowner", "testrepo", mock.MatchedBy(func(ref *github.Reference) bool {
+		return ref.GetRef() == "refs/heads/aigile/tests-7" && ref.GetObject().GetSHA() == "abc123"
+	})).Return(&github.Reference{}, resp, nil)
+	var committed []string
+	repos.On("CreateFile", mock.Anything, "testowner", "testrepo", mock.Anything, mock.MatchedBy(func(opts *github.RepositoryContentFileOptions) bool {
+		return opts.GetBranch() == "aigile/tests-7" && opts.GetMessage() == "Add test skeletons for #7"
+	})).Run(func(args mock.Arguments) {
+		committed = append(committed, args.String(3))
+	}).Return(&github.RepositoryContentResponse{}, resp, nil)
+	pulls.On("Create", mock.Anything, "testowner", "testrepo", mock.MatchedBy(func(pull *github.NewPullRequest) bool {
+		return pull.GetHead() == "aigile/tests-7" && pull.GetBase() == "trunk" && pull.GetTitle() == "Test skeletons for #7"
+	})).Return(&github.PullRequest{Number: github.Int(8), HTMLURL: github.String("https://github.com/testowner/testrepo/pull/8")}, resp, nil)
+
+	pr, err := p.ProposeChange(context.Background(), Change{
+		Branch:  "aigile/tests-7",
+		Message: "Add test skeletons for #7",
+		Title:   "Test skeletons for #7",
+		Body:    "Refs #7",
+		Files:   map[string]string{"features/7-refund.feature": "Feature: Refund\n", "features/7-pay.feature": "Feature: Pay\n"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 8, pr.GetNumber())
+	assert.Equal(t, "https://github.com/testowner/testrepo/pull/8", pr.GetHTMLURL())
+	assert.Equal(t, []string{"features/7-pay.feature", "features/7-refund.feature"}, committed)
+	repos.AssertExpectations(t)
+	git.AssertExpectations(t)
+	pulls.AssertExpectations(t)
+}
+
+// TestGitHubProvider_ProposeChange_CommitFailure tests that no pull request is opened when a file
+// cannot be committed.
+func TestGitHubProvider_ProposeChange_CommitFailure(t *testing.T) {
+	repos := new(mockRepositoriesService)
+	git := new(mockGitService)
+	pulls := new(mockPullRequestsService)
+	p := &GitHubProvider{repos: repos, git: git, pulls: pulls, owner: "testowner", repo: "testrepo"}
+	resp := &github.Response{Response: &http.Response{StatusCode: http.StatusOK}}
+
+	repos.On("Get", mock.Anything, "testowner", "testrepo").Return(&github.Repository{DefaultBranch: github.String("main")}, resp, nil)
+	git.On("GetRef", mock.Anything, "testowner", "testrepo", "heads/main").
+		Return(&github.Reference{Object: &github.GitObject{SHA: github.String("abc123")}}, resp, nil)
+	git.On("CreateRef", mock.Anything, "testowner", "testrepo", mock.Anything).Return(&github.Reference{}, resp, nil)
+	repos.On("CreateFile", mock.Anything, "testowner", "testrepo", "features/7-pay.feature", mock.Anything).
+		Return((*github.RepositoryContentResponse)(nil), resp, errors.New("409 conflict"))
+
+	_, err := p.ProposeChange(context.Background(), Change{Branch: "aigile/tests-7", Files: map[string]string{"features/7-pay.feature": "Feature: Pay\n"}})
+	assert.EqualError(t, err, "failed to commit features/7-pay.feature: 409 conflict")
+	pulls.AssertNotCalled(t, "Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// TestGitHubProvider_FakeServer_CheckPermissions tests the preflight check of the token scopes and
+// repository access against the fake server.
+func TestGitHubProvider_FakeServer_CheckPermissions(t *testing.T) {
+	needs := Permissions{Issues: true, Projects: true}
+	tests := map[string]struct {
+		setup    func(s *githubtest.Server)
+		expected string
+	}{
+		"fine-grained token": {setup: func(*githubtest.Server) {}},
+		"classic token":      {setup: func(s *githubtest.Server) { s.SetScopes("repo", "project", "read:org") }},
+		"public repo scope":  {setup: func(s *githubtest.Server) { s.SetScopes("public_repo", "project") }},
+		"missing project":    {setup: func(s *githubtest.Server) { s.SetScopes("repo", "read:project") }, expected: "the GitHub token is missing the project scope"},
+		"missing all":        {setup: func(s *githubtest.Server) { s.SetScopes("gist") }, expected: "missing the repo scope and the project scope"},
+		"read-only access":   {setup: func(s *githubtest.Server) { s.SetPermissions(map[string]bool{"pull": true}) }, expected: "missing triage or write access to testowner/testrepo"},
+		"write access": {setup: func(s *githubtest.Server) {
+			s.SetPermissions(map[string]bool{"pull": true, "triage": true, "push": true})
+		}},
+		"fine-grained without issues": {setup: func(s *githubtest.Server) {
+			s.SetFineGrainedPermissions(map[string]string{"organization_projects": "write"})
+		}, expected: "missing the Issues repository permission (read and write)"},
+		"fine-grained without projects": {setup: func(s *githubtest.Server) {
+			s.SetFineGrainedPermissions(map[string]string{"issues": "write"})
+		}, expected: "missing the Projects organization permission (read and write)"},
+		"fine-grained permissions": {setup: func(s *githubtest.Server) {
+			s.SetFineGrainedPermissions(map[string]string{"issues": "write", "organization_projects": "write"})
+		}},
+		"token expiring":       {setup: func(s *githubtest.Server) { s.SetTokenExpiration(time.Now().Add(24 * time.Hour)) }},
+		"issues disabled":      {setup: func(s *githubtest.Server) { s.DisableIssues() }, expected: "the issues of testowner/testrepo are disabled"},
+		"repository not found": {setup: func(s *githubtest.Server) { s.FailWithStatus(1, http.StatusNotFound) }, expected: "repository testowner/testrepo not found"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			p, server := newFakeGitHubProvider(t)
+			tt.setup(server)
+			err := p.CheckPermissions(context.Background(), needs)
+			if tt.expected == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+
+	p, server := newFakeGitHubProvider(t)
+	server.SetScopes("repo")
+	assert.NoError(t, p.CheckPermissions(context.Background(), Permissions{Issues: true}))
+}
+
+// TestGitHubProvider_FakeServer_FineGrainedToken tests the hints of the preflight check for the
+// repositories a fine-grained token cannot see.
+func TestGitHubProvider_FakeServer_FineGrainedToken(t *testing.T) {
+	server := githubtest.NewServer("testowner", "testrepo")
+	t.Cleanup(server.Close)
+	p, err := NewGitHubProvider(GitHubConfig{Token: "github_pat_11ABCDEFG", Owner: "testowner", Repo: "testrepo", BaseURL: server.BaseURL()})
+	require.NoError(t, err)
+	assert.True(t, p.fineGrained)
+	scratch, err := p.CreateRepository(context.Background(), "scratch")
+	require.NoError(t, err)
+	assert.Equal(t, "scratch", scratch.repo)
+	assert.True(t, scratch.fineGrained, "the provider of a created repository keeps the kind of token")
+
+	server.FailWithStatus(1, http.StatusNotFound)
+	err = p.CheckPermissions(context.Background(), Permissions{Issues: true})
+	assert.ErrorContains(t, err, "the token is owned by testowner and the repository is selected in its repository access")
+}
+
+func TestTokenExpiry(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	tests := map[string]struct {
+		header   string
+		expected string
+	}{
+		"no expiration":  {},
+		"far":            {header: "2025-06-01 10:00:00 UTC"},
+		"within a week":  {header: "2025-03-04 10:00:00 UTC", expected: "the GitHub token expires in 3 days (2025-03-04 10:00:00): regenerate it before a long run"},
+		"within hours":   {header: "2025-03-01 22:30:00 UTC", expected: "the GitHub token expires in 12 hours"},
+		"offset":         {header: "2025-03-02 10:00:00 +0000", expected: "the GitHub token expires in 24 hours"},
+		"expired":        {header: "2025-02-28 10:00:00 UTC", expected: "the GitHub token expired on 2025-02-28 10:00:00"},
+		"unknown format": {header: "tomorrow"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.header != "" {
+				header.Set("GitHub-Authentication-Token-Expiration", tt.header)
+			}
+			warning := tokenExpiry(header, now)
+			if tt.expected == "" {
+				assert.Empty(t, warning)
+				return
+			}
+			assert.Contains(t, warning, tt.expected)
+		})
+	}
+}
+
+// TestGitHubProvider_FakeServer_SSORequired tests that the responses of an organization enforcing
+// SAML single sign-on surface as a SSORequiredError with the authorization page.
+func TestGitHubProvider_FakeServer_SSORequired(t *testing.T) {
+	ctx := context.Background()
+	p, server := newFakeGitHubProvider(t)
+	server.AddProject("Board")
+	server.RequireSSO(2)
+
+	_, err := p.CreateIssue(ctx, CreateIssueRequest{Title: "Story", Body: "Body"})
+	var ssoErr *SSORequiredError
+	require.ErrorAs(t, err, &ssoErr)
+	assert.Equal(t, "https://github.com/orgs/testowner/sso?authorization_request=abc", ssoErr.URL)
+	assert.ErrorContains(t, err, "requires SAML single sign-on and the GitHub token is not authorized for it: authorize it at https://github.com/orgs/testowner/sso")
+
+	_, err = p.GetProjectByName(ctx, "Board")
+	assert.ErrorAs(t, err, &ssoErr)
+
+	project, err := p.GetProjectByName(ctx, "Board")
+	require.NoError(t, err)
+	assert.NotNil(t, project)
+}
+
+// TestSSOTransport tests detecting SAML single sign-on from the message of a 403 response without
+// the X-GitHub-SSO header, and leaving the other 403 responses readable.
+func TestSSOTransport(t *testing.T) {
+	mockClient := new(mockHTTPClient)
+	transport := &ssoTransport{base: &mockTransport{mock: mockClient}}
+	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	require.NoError(t, err)
+
+	mockClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"message":"Resource protected by organization SAML enforcement."}`)),
+	}, nil).Once()
+	_, err = transport.RoundTrip(req)
+	assert.EqualError(t, err, "the organization requires SAML single sign-on and the GitHub token is not authorized for it: "+
+		"authorize it for the organization (Configure SSO) at https://github.com/settings/tokens")
+
+	mockClient.On("Do", mock.Anything).Return(&http.Response{
+		StatusCode: http.StatusForbidden,
+		Header:     http.Header{},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"message":"Must have admin rights to Repository."}`)),
+	}, nil).Once()
+	resp, err := transport.RoundTrip(req)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"message":"Must have admin rights to Repository."}`, string(body))
+}
+
+func TestGraphQLURL(t *testing.T) {
+	for base, want := range map[string]string{
+		"https://api.github.com/":            "https://api.github.com/graphql",
+		"https://ghes.example.com/api/v3/":   "https://ghes.example.com/api/graphql",
+		"https://example.com/github/api/v3/": "https://example.com/github/api/graphql",
+		"http://127.0.0.1:8080/":             "http://127.0.0.1:8080/graphql",
+		"http://127.0.0.1:8080/fake/":        "http://127.0.0.1:8080/fake/graphql",
+	} {
+		u, err := url.Parse(base)
+		require.NoError(t, err)
+		assert.Equal(t, want, graphQLURL(u), base)
+	}
+
+	p, err := NewGitHubProvider(GitHubConfig{Token: "t", Owner: "acme", Repo: "app", BaseURL: "https://ghes.example.com/api/v3"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://ghes.example.com/api/graphql", p.graphQLEndpoint())
+	p, err = NewGitHubProvider(GitHubConfig{Token: "t", Owner: "acme", Repo: "app", BaseURL: "https://ghes.example.com/api/v3", GraphQLURL: "https://ghes.example.com/custom/graphql"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://ghes.example.com/custom/graphql", p.graphQLEndpoint())
+}
@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/leocomelli/aigile/internal/format"
+)
+
+// RedmineConfig holds the configuration for the Redmine provider.
+type RedmineConfig struct {
+	URL        string // Base URL of the Redmine instance, e.g. https://redmine.example.com
+	APIKey     string
+	Project    string            // Default project identifier, used when the item has no parent project
+	Trackers   map[string]string // Tracker name per label (item type), the project default is used when unset
+	Priorities map[string]string // Priority name per label (item type), the Redmine default is used when unset
+	Markdown   bool              // Whether the instance text formatting is Markdown instead of the default Textile
+}
+
+// RedmineProvider creates items as issues in a Redmine instance through its REST API.
+type RedmineProvider struct {
+	api        *restClient
+	project    string
+	trackers   map[string]string
+	priorities map[string]string
+	markdown   bool
+
+	mu          sync.Mutex
+	trackerIDs  map[string]int // tracker name -> ID, loaded on first use
+	priorityIDs map[string]int // priority name -> ID, loaded on first use
+}
+
+// NewRedmineProvider creates a new RedmineProvider with the given configuration.
+func NewRedmineProvider(config RedmineConfig) (*RedmineProvider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("a Redmine URL is required")
+	}
+	header := http.Header{"X-Redmine-Api-Key": {config.APIKey}}
+	api, err := newRestClient("Redmine", config.URL, header, redmineErrorMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RedmineProvider{
+		api:        api,
+		project:    config.Project,
+		trackers:   config.Trackers,
+		priorities: config.Priorities,
+		markdown:   config.Markdown,
+	}, nil
+}
+
+// BodyFormat returns the formatter of the text formatting of the instance.
+func (p *RedmineProvider) BodyFormat() format.Formatter {
+	if p.markdown {
+		return format.Markdown{}
+	}
+	return format.Textile{}
+}
+
+// redmineIssue is the subset of the Redmine issue resource used by the provider.
+type redmineIssue struct {
+	ID          int    `json:"id"`
+	Subject     string `json:"subject"`
+	Description string `json:"description"`
+	Status      struct {
+		Name     string `json:"name"`
+		IsClosed bool   `json:"is_closed"` // Reported since Redmine 5.1
+	} `json:"status"`
+	htmlURL string
+	labels  []string
+}
+
+func (i *redmineIssue) GetNumber() int        { return i.ID }
+func (i *redmineIssue) GetID() int64          { return int64(i.ID) }
+func (i *redmineIssue) GetHTMLURL() string    { return i.htmlURL }
+func (i *redmineIssue) GetTitle() string      { return i.Subject }
+func (i *redmineIssue) GetBody() string       { return i.Description }
+func (i *redmineIssue) GetLabels() []string   { return i.labels }
+func (i *redmineIssue) GetProviderID() string { return strconv.Itoa(i.ID) }
+func (i *redmineIssue) GetState() string {
+	switch {
+	case i.Status.Name == "":
+		return ""
+	case i.Status.IsClosed:
+		return StateClosed
+	default:
+		return StateOpen
+	}
+}
+
+// CreateIssue creates an issue in the item's project, or in the default project, using the
+// tracker and priority mapped from its first label, under its parent issue. Assignees and
+// milestones are not supported and ignored.
+func (p *RedmineProvider) CreateIssue(ctx context.Context, req CreateIssueRequest) (*CreateIssueResult, error) {
+	projectID := p.project
+	if req.Project != nil && req.Project.ProjectID != "" {
+		projectID = req.Project.ProjectID
+	}
+	if projectID == "" {
+		return nil, fmt.Errorf("no Redmine project configured for issue %q", req.Title)
+	}
+	labels := req.Labels
+
+	fields := map[string]interface{}{
+		"project_id":  projectID,
+		"subject":     req.Title,
+		"description": req.Body,
+	}
+	if req.Parent != 0 {
+		fields["parent_issue_id"] = req.Parent
+	}
+	var warnings []string
+	if len(labels) > 0 {
+		if trackerID, err := p.lookupID(ctx, "trackers", p.trackers[labels[0]]); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to look up the Redmine tracker of label %q: %v", labels[0], err))
+		} else if trackerID != 0 {
+			fields["tracker_id"] = trackerID
+		}
+		if priorityID, err := p.lookupID(ctx, "priorities", p.priorities[labels[0]]); err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to look up the Redmine priority of label %q: %v", labels[0], err))
+		} else if priorityID != 0 {
+			fields["priority_id"] = priorityID
+		}
+	}
+
+	var result struct {
+		Issue redmineIssue `json:"issue"`
+	}
+	if err := p.api.request(ctx, http.MethodPost, "issues.json", map[string]interface{}{"issue": fields}, &result); err != nil {
+		return nil, fmt.Errorf("failed to create issue: %w", err)
+	}
+	issue := p.wrap(result.Issue, labels)
+	slog.Info("issue created", "number", issue.ID, "url", issue.htmlURL)
+
+	return &CreateIssueResult{Issue: issue, Warnings: warnings}, nil
+}
+
+// AddSubIssue sets the parent issue of the child issue.
+func (p *RedmineProvider) AddSubIssue(ctx context.Context, parentNumber int, childID int64) error {
+	body := map[string]interface{}{"issue": map[string]interface{}{"parent_issue_id": parentNumber}}
+	if err := p.api.request(ctx, http.MethodPut, fmt.Sprintf("issues/%d.json", childID), body, nil); err != nil {
+		return fmt.Errorf("failed to set parent of issue #%d: %w", childID, err)
+	}
+	return nil
+}
+
+// EditIssue updates the subject and/or description of an existing issue. Empty fields are left unchanged.
+func (p *RedmineProvider) EditIssue(ctx context.Context, number int, title, description string) (Issue, error) {
+	fields := map[string]interface{}{}
+	if title != "" {
+		fields["subject"] = title
+	}
+	if description != "" {
+		fields["description"] = description
+	}
+
+	path := fmt.Sprintf("issues/%d.json", number)
+	if err := p.api.request(ctx, http.MethodPut, path, map[string]interface{}{"issue": fields}, nil); err != nil {
+		return nil, fmt.Errorf("failed to edit issue #%d: %w", number, err)
+	}
+
+	// Redmine answers updates with no content, so read the issue back
+	var result struct {
+		Issue redmineIssue `json:"issue"`
+	}
+	if err := p.api.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return nil, fmt.Errorf("failed to get issue #%d: %w", number, err)
+	}
+	slog.Debug("issue edited", "number", number)
+	return p.wrap(result.Issue, nil), nil
+}
+
+// DeleteIssue deletes an existing issue, with its subtasks.
+func (p *RedmineProvider) DeleteIssue(ctx context.Context, number int) error {
+	if err := p.api.request(ctx, http.MethodDelete, fmt.Sprintf("issues/%d.json", number), nil, nil); err != nil {
+		return fmt.Errorf("failed to delete issue #%d: %w", number, err)
+	}
+	slog.Debug("issue deleted", "number", number)
+	return nil
+}
+
+// GetProjectByName searches the projects visible to the API key for one with the given name or identifier.
+func (p *RedmineProvider) GetProjectByName(ctx context.Context, projectName string) (*ProjectInfo, error) {
+	for offset := 0; ; {
+		var result struct {
+			Projects []struct {
+				ID         int    `json:"id"`
+				Name       string `json:"name"`
+				Identifier string `json:"identifier"`
+			} `json:"projects"`
+			TotalCount int `json:"total_count"`
+		}
+		path := fmt.Sprintf("projects.json?limit=100&offset=%d", offset)
+		if err := p.api.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+			return nil, fmt.Errorf("failed to list projects: %w", err)
+		}
+		for _, project := range result.Projects {
+			if project.Name == projectName || project.Identifier == projectName {
+				slog.Info("found project", "name", project.Name, "id", project.ID)
+				return &ProjectInfo{ProjectID: strconv.Itoa(project.ID), ProjectNumber: project.ID}, nil
+			}
+		}
+		offset += len(result.Projects)
+		if len(result.Projects) == 0 || offset >= result.TotalCount {
+			break
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrProjectNotFound, projectName)
+}
+
+// redmineMaxTitleSize is the maximum characters of the subject of a Redmine issue.
+const redmineMaxTitleSize = 255
+
+// Capabilities returns the capabilities of Redmine, whose projects hold the issues and whose labels
+// are mapped to the tracker and priority.
+func (p *RedmineProvider) Capabilities() Capabilities {
+	return Capabilities{Projects: true, SubIssues: true, MaxTitleSize: redmineMaxTitleSize}
+}
+
+// wrap sets the browser URL of an issue returned by the API.
+func (p *RedmineProvider) wrap(issue redmineIssue, labels []string) *redmineIssue {
+	issue.htmlURL = p.api.baseURL.JoinPath("issues", strconv.Itoa(issue.ID)).String()
+	issue.labels = labels
+	return &issue
+}
+
+// lookupID resolves a tracker or priority name to its ID. Numeric names are used as IDs and an
+// empty name resolves to zero. The names are loaded once from the API.
+func (p *RedmineProvider) lookupID(ctx context.Context, kind, name string) (int, error) {
+	if name == "" {
+		return 0, nil
+	}
+	if id, err := strconv.Atoi(name); err == nil {
+		return id, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	ids := &p.trackerIDs
+	path, key := "trackers.json", "trackers"
+	if kind == "priorities" {
+		ids = &p.priorityIDs
+		path, key = "enumerations/issue_priorities.json", "issue_priorities"
+	}
+
+	if *ids == nil {
+		var result map[string][]struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}
+		if err := p.api.request(ctx, http.MethodGet, path, nil, &result); err != nil {
+			return 0, err
+		}
+		*ids = make(map[string]int)
+		for _, v := range result[key] {
+			(*ids)[strings.ToLower(v.Name)] = v.ID
+		}
+	}
+
+	id, ok := (*ids)[strings.ToLower(name)]
+	if !ok {
+		return 0, fmt.Errorf("unknown Redmine %s: %s", kind, name)
+	}
+	return id, nil
+}
+
+// redmineErrorMessage joins the messages of a Redmine validation error response.
+func redmineErrorMessage(body []byte) string {
+	var apiErr struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &apiErr) != nil {
+		return ""
+	}
+	return strings.Join(apiErr.Errors, "; ")
+}
@@ -0,0 +1,183 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/format"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// redmineRequest is a request received by the fake Redmine server.
+type redmineRequest struct {
+	method string
+	path   string
+	issue  map[string]interface{}
+}
+
+// newFakeRedmineProvider starts a fake Redmine API that answers with the handler's response for
+// each request and records the requests it receives.
+func newFakeRedmineProvider(t *testing.T, config RedmineConfig, handler func(r *http.Request) (int, string)) (*RedmineProvider, *[]redmineRequest) {
+	t.Helper()
+	var requests []redmineRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "key", r.Header.Get("X-Redmine-API-Key"))
+		var body struct {
+			Issue map[string]interface{} `json:"issue"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, redmineRequest{method: r.Method, path: r.URL.Path, issue: body.Issue})
+
+		status, response := handler(r)
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+
+	config.URL = server.URL + "/redmine"
+	config.APIKey = "key"
+	p, err := NewRedmineProvider(config)
+	require.NoError(t, err)
+	return p, &requests
+}
+
+func TestNewRedmineProvider_RequiresURL(t *testing.T) {
+	_, err := NewRedmineProvider(RedmineConfig{APIKey: "key"})
+	assert.Error(t, err)
+}
+
+func TestRedmineProvider_BodyFormat(t *testing.T) {
+	p, err := NewRedmineProvider(RedmineConfig{URL: "https://redmine.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, format.Textile{}, FormatterOf(p))
+
+	p, err = NewRedmineProvider(RedmineConfig{URL: "https://redmine.example.com", Markdown: true})
+	require.NoError(t, err)
+	assert.Equal(t, format.Markdown{}, FormatterOf(p))
+	assert.Equal(t, format.Markdown{}, FormatterOf(&ConsoleProvider{}))
+}
+
+func TestRedmineProvider_CreateIssue_Mapping(t *testing.T) {
+	config := RedmineConfig{
+		Project:    "backlog",
+		Trackers:   map[string]string{"User Story": "Feature", "Task": "4"},
+		Priorities: map[string]string{"User Story": "high"},
+	}
+	p, requests := newFakeRedmineProvider(t, config, func(r *http.Request) (int, string) {
+		switch r.URL.Path {
+		case "/redmine/trackers.json":
+			return http.StatusOK, `{"trackers":[{"id":1,"name":"Bug"},{"id":2,"name":"Feature"}]}`
+		case "/redmine/enumerations/issue_priorities.json":
+			return http.StatusOK, `{"issue_priorities":[{"id":2,"name":"Normal"},{"id":3,"name":"High"}]}`
+		}
+		return http.StatusCreated, `{"issue":{"id":42,"subject":"Story","description":"Body","status":{"id":1,"name":"New","is_closed":false}}}`
+	})
+
+	issue, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body", Labels: []string{"User Story"}})
+	require.NoError(t, err)
+	assert.Equal(t, 42, issue.GetNumber())
+	assert.Equal(t, int64(42), issue.GetID())
+	assert.Equal(t, p.api.baseURL.String()+"issues/42", issue.GetHTMLURL())
+	assert.Equal(t, []string{"User Story"}, issue.GetLabels())
+	assert.Equal(t, StateOpen, issue.GetState())
+	assert.Equal(t, "42", issue.GetProviderID())
+
+	_, err = p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Task", Body: "Body", Labels: []string{"Task"}})
+	require.NoError(t, err)
+
+	// Names are loaded once and numeric tracker IDs are used as is
+	require.Len(t, *requests, 4)
+	story := (*requests)[2].issue
+	assert.Equal(t, "backlog", story["project_id"])
+	assert.Equal(t, float64(2), story["tracker_id"])
+	assert.Equal(t, float64(3), story["priority_id"])
+	task := (*requests)[3].issue
+	assert.Equal(t, float64(4), task["tracker_id"])
+	assert.NotContains(t, task, "priority_id")
+}
+
+func TestRedmineProvider_CreateIssue_ParentProject(t *testing.T) {
+	p, requests := newFakeRedmineProvider(t, RedmineConfig{}, func(r *http.Request) (int, string) {
+		return http.StatusCreated, `{"issue":{"id":1}}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body"})
+	assert.ErrorContains(t, err, "no Redmine project configured")
+
+	_, err = p.CreateIssue(context.Background(), CreateIssueRequest{Title: "Story", Body: "Body", Project: &ProjectInfo{ProjectID: "7"}})
+	require.NoError(t, err)
+	assert.Equal(t, "7", (*requests)[0].issue["project_id"])
+}
+
+func TestRedmineProvider_CreateIssue_Error(t *testing.T) {
+	p, _ := newFakeRedmineProvider(t, RedmineConfig{Project: "backlog"}, func(r *http.Request) (int, string) {
+		return http.StatusUnprocessableEntity, `{"errors":["Subject cannot be blank"]}`
+	})
+
+	_, err := p.CreateIssue(context.Background(), CreateIssueRequest{Title: "", Body: "Body"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status: 422")
+	assert.Contains(t, err.Error(), "Subject cannot be blank")
+}
+
+func TestRedmineProvider_AddSubIssue(t *testing.T) {
+	p, requests := newFakeRedmineProvider(t, RedmineConfig{}, func(r *http.Request) (int, string) {
+		return http.StatusNoContent, ""
+	})
+
+	require.NoError(t, p.AddSubIssue(context.Background(), 42, 43))
+	require.Len(t, *requests, 1)
+	assert.Equal(t, http.MethodPut, (*requests)[0].method)
+	assert.Equal(t, "/redmine/issues/43.json", (*requests)[0].path)
+	assert.Equal(t, float64(42), (*requests)[0].issue["parent_issue_id"])
+}
+
+func TestRedmineProvider_EditIssue(t *testing.T) {
+	p, requests := newFakeRedmineProvider(t, RedmineConfig{}, func(r *http.Request) (int, string) {
+		if r.Method == http.MethodPut {
+			return http.StatusNoContent, ""
+		}
+		return http.StatusOK, `{"issue":{"id":42,"subject":"Story","description":"New body"}}`
+	})
+
+	issue, err := p.EditIssue(context.Background(), 42, "", "New body")
+	require.NoError(t, err)
+	assert.Equal(t, "Story", issue.GetTitle())
+	assert.Equal(t, "New body", issue.GetBody())
+	assert.Equal(t, map[string]interface{}{"description": "New body"}, (*requests)[0].issue)
+}
+
+func TestRedmineProvider_DeleteIssue(t *testing.T) {
+	p, requests := newFakeRedmineProvider(t, RedmineConfig{}, func(r *http.Request) (int, string) {
+		if r.URL.Path == "/redmine/issues/43.json" {
+			return http.StatusNotFound, ""
+		}
+		return http.StatusNoContent, ""
+	})
+
+	require.NoError(t, p.DeleteIssue(context.Background(), 42))
+	assert.Equal(t, http.MethodDelete, (*requests)[0].method)
+	assert.Equal(t, "/redmine/issues/42.json", (*requests)[0].path)
+	assert.ErrorContains(t, p.DeleteIssue(context.Background(), 43), "failed to delete issue #43")
+}
+
+func TestRedmineProvider_GetProjectByName(t *testing.T) {
+	p, requests := newFakeRedmineProvider(t, RedmineConfig{}, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("offset") == "0" {
+			return http.StatusOK, `{"projects":[{"id":1,"name":"Other","identifier":"other"}],"total_count":2}`
+		}
+		return http.StatusOK, `{"projects":[{"id":7,"name":"Payments","identifier":"payments"}],"total_count":2}`
+	})
+
+	project, err := p.GetProjectByName(context.Background(), "payments")
+	require.NoError(t, err)
+	assert.Equal(t, "7", project.ProjectID)
+	assert.Len(t, *requests, 2)
+
+	_, err = p.GetProjectByName(context.Background(), "Missing")
+	assert.ErrorContains(t, err, "project not found: Missing")
+}
@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/httpclient"
+	"github.com/leocomelli/aigile/internal/redact"
+)
+
+// restClient performs authenticated JSON requests against the REST API of an issue tracker.
+type restClient struct {
+	name    string // Tracker name used in error messages
+	client  *http.Client
+	baseURL *url.URL
+	header  http.Header // Authentication and other headers sent with every request
+	// contentType is the media type of the request bodies, application/json when empty.
+	contentType string
+	// errorMessage extracts a readable message from an error response, returning an empty string
+	// when the body has no known error structure.
+	errorMessage func(body []byte) string
+}
+
+// newRestClient creates a restClient for the API rooted at rawURL.
+func newRestClient(name, rawURL string, header http.Header, errorMessage func([]byte) string) (*restClient, error) {
+	baseURL, err := url.Parse(strings.TrimSuffix(rawURL, "/") + "/")
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s API base URL: %w", name, err)
+	}
+	return &restClient{
+		name:         name,
+		client:       httpclient.New(httpclient.DefaultTimeout),
+		baseURL:      baseURL,
+		header:       header,
+		errorMessage: errorMessage,
+	}, nil
+}
+
+// request sends body as JSON, when not nil, to the path relative to the base URL and decodes the
+// JSON response into out, when not nil.
+func (c *restClient) request(ctx context.Context, method, path string, body, out interface{}) error {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return fmt.Errorf("invalid request path %q: %w", path, err)
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	for key, values := range c.header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", cmp.Or(c.contentType, "application/json"))
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		if cerr := resp.Body.Close(); cerr != nil {
+			slog.Warn("failed to close response body", "error", cerr)
+		}
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &restError{tracker: c.name, status: resp.StatusCode, body: redact.Secrets(string(respBody))}
+		if c.errorMessage != nil {
+			apiErr.message = redact.Secrets(c.errorMessage(respBody))
+		}
+		return apiErr
+	}
+
+	if out == nil || len(respBody) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// restError is an error response of the API of an issue tracker.
+type restError struct {
+	tracker string
+	status  int
+	message string // Message extracted from the body, empty when it has no known error structure
+	body    string
+}
+
+func (e *restError) Error() string {
+	if e.message != "" {
+		return fmt.Sprintf("%s API error (status: %d): %s", e.tracker, e.status, e.message)
+	}
+	return fmt.Sprintf("%s API error (status: %d, body: %s)", e.tracker, e.status, e.body)
+}
+
+// isNotFound reports whether err is a 404 response of the API of an issue tracker.
+func isNotFound(err error) bool {
+	var apiErr *restError
+	return errors.As(err, &apiErr) && apiErr.status == http.StatusNotFound
+}
@@ -0,0 +1,144 @@
+package quality
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/leocomelli/aigile/internal/llm"
+)
+
+// penaltyUngrounded is applied by a grounded checker to content mentioning unsupported terms.
+const penaltyUngrounded = 15
+
+// termPattern matches the words of a text, including the ones with inner dots, dashes or digits,
+// such as S3, OAuth2 or Node.js.
+var termPattern = regexp.MustCompile(`[\p{L}\p{N}](?:[\p{L}\p{N}.\-]*[\p{L}\p{N}])?`)
+
+// commonTerms are capitalized words and acronyms that name no particular feature or system, so
+// they never need verification.
+var commonTerms = map[string]bool{
+	"i": true, "a": true, "an": true, "the": true, "as": true, "so": true, "given": true, "when": true,
+	"then": true, "and": true, "but": true, "or": true, "if": true, "not": true, "ok": true,
+	"api": true, "apis": true, "ui": true, "ux": true, "url": true, "urls": true, "id": true, "ids": true,
+	"http": true, "https": true, "json": true, "xml": true, "csv": true, "pdf": true, "html": true,
+	"sms": true, "otp": true, "faq": true, "qa": true, "sla": true, "kpi": true, "mvp": true,
+	"crud": true, "rest": true, "pii": true, "utc": true, "etc": true, "e.g": true, "i.e": true,
+	"user story": true, "epic": true, "task": true, "feature": true, "bug": true,
+}
+
+// Ungrounded returns the terms of the generated content that look like the names of features,
+// products or systems (acronyms, CamelCase words and capitalized words within a sentence) and are
+// not found in what was supplied for the item (parent, context, criteria and documentation) nor in
+// the glossary, in the order they first appear. They are likely made up by the model and need
+// human verification. Capitalized words are not considered in German, which capitalizes all nouns.
+func Ungrounded(req llm.Request, content *llm.GeneratedContent, glossary []string) []string {
+	supplied := strings.ToLower(strings.Join(append(append([]string{req.Parent, req.Context}, req.Criteria...), req.Documentation...), "\n"))
+	known := make(map[string]bool, len(glossary))
+	for _, term := range glossary {
+		known[strings.ToLower(strings.TrimSpace(term))] = true
+	}
+	capitalized := !isGerman(req.Language)
+
+	texts := append(append([]string{content.Title, content.Description}, content.AcceptanceCriteria...), content.SuggestedTasks...)
+	var terms []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, term := range namedTerms(text, capitalized) {
+			key := strings.ToLower(term)
+			if seen[key] || commonTerms[key] || known[key] || strings.Contains(supplied, key) {
+				continue
+			}
+			seen[key] = true
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// UngroundedIssue describes the ungrounded terms of a content as a quality problem.
+func UngroundedIssue(terms []string) string {
+	return fmt.Sprintf("mentions %s, not found in the row nor in the glossary, which needs human verification", strings.Join(terms, ", "))
+}
+
+// namedTerms returns the acronyms, CamelCase words and, when capitalized is set, the capitalized
+// words that do not start a sentence, joining consecutive capitalized words, e.g. "Google Pay".
+func namedTerms(text string, capitalized bool) []string {
+	var terms []string
+	var current []string
+	flush := func() {
+		if len(current) > 0 {
+			terms = append(terms, strings.Join(current, " "))
+			current = nil
+		}
+	}
+
+	previous := 0
+	for _, loc := range termPattern.FindAllStringIndex(text, -1) {
+		word := text[loc[0]:loc[1]]
+		gap := text[previous:loc[0]]
+		sentenceStart := previous == 0 || strings.ContainsAny(gap, ".!?:;\n\"(")
+		previous = loc[1]
+		if gap != " " {
+			flush()
+		}
+
+		switch {
+		case isAcronym(word) || isCamelCase(word):
+			if commonTerms[strings.ToLower(word)] {
+				flush()
+				continue
+			}
+			current = append(current, word)
+		case capitalized && !sentenceStart && isCapitalized(word) && !commonTerms[strings.ToLower(word)]:
+			current = append(current, word)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+// isAcronym reports whether a word of two or more characters has letters, all of them uppercase,
+// e.g. SAP or S3.
+func isAcronym(word string) bool {
+	letters, length := 0, 0
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			letters++
+		}
+		length++
+	}
+	return letters > 0 && length >= 2
+}
+
+// isCamelCase reports whether a word has an uppercase letter after a lowercase one, e.g. PayPal.
+func isCamelCase(word string) bool {
+	lower := false
+	for _, r := range word {
+		if unicode.IsUpper(r) && lower {
+			return true
+		}
+		lower = unicode.IsLower(r)
+	}
+	return false
+}
+
+// isCapitalized reports whether a word starts with an uppercase letter.
+func isCapitalized(word string) bool {
+	for _, r := range word {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// isGerman reports whether the output language is German.
+func isGerman(language string) bool {
+	language = strings.ToLower(strings.TrimSpace(language))
+	return language == "german" || language == "deutsch" || strings.HasPrefix(language, "de")
+}
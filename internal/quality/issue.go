@@ -0,0 +1,91 @@
+package quality
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	// hiddenComment matches the HTML comments of an issue body, such as the aigile markers.
+	hiddenComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+	// heading matches a Markdown heading, or a bold line used as one.
+	heading = regexp.MustCompile(`^\s*(#{1,6}\s+.*|\*\*[^*]+\*\*:?\s*)$`)
+	// vagueTerms matches placeholders and wording that does not say what is expected.
+	vagueTerms = regexp.MustCompile(`(?i)\b(tbd|tbc|todo|etc|somehow|something|stuff|and so on|as needed|user[- ]friendly|asap)\b`)
+)
+
+// CheckIssue lists the problems of an existing issue of the backlog: a missing or short title, a
+// description that is too short or vague, and the lack of acceptance criteria, found under one of
+// the criteriaHeadings or written as Given / When / Then.
+func CheckIssue(title, body string, criteriaHeadings []string) []string {
+	var problems []string
+	title = strings.TrimSpace(title)
+	switch {
+	case title == "":
+		problems = append(problems, "the title is empty")
+	case len([]rune(title)) > maxTitleLength:
+		problems = append(problems, fmt.Sprintf("the title is longer than %d characters", maxTitleLength))
+	}
+
+	body = hiddenComment.ReplaceAllString(body, "")
+	description := issueDescription(body)
+	if len([]rune(description)) < minDescriptionLength {
+		problems = append(problems, "the description is too short to explain the need and its value")
+	}
+	if terms := vagueTerms.FindAllString(description, -1); len(terms) > 0 {
+		problems = append(problems, fmt.Sprintf("the description is vague (%s)", strings.Join(unique(terms), ", ")))
+	}
+	if !hasCriteria(body, criteriaHeadings) {
+		problems = append(problems, "there are no acceptance criteria")
+	}
+	return problems
+}
+
+// issueDescription returns the text of a body before its first heading.
+func issueDescription(body string) string {
+	var lines []string
+	for _, line := range strings.Split(body, "\n") {
+		if heading.MatchString(line) {
+			break
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// hasCriteria reports whether a body has a heading of acceptance criteria or criteria written as
+// Given / When / Then.
+func hasCriteria(body string, criteriaHeadings []string) bool {
+	if gherkinSteps.MatchString(body) {
+		return true
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if !heading.MatchString(line) {
+			continue
+		}
+		line = strings.ToLower(line)
+		for _, h := range append([]string{"acceptance criteria"}, criteriaHeadings...) {
+			if h != "" && strings.Contains(line, strings.ToLower(h)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// unique returns the distinct values in lower case, sorted.
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, v := range values {
+		v = strings.ToLower(v)
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,148 @@
+// Package quality scores generated agile items with simple heuristics, so weak generations can be
+// detected, refined or compared.
+package quality
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// MaxScore is the score of an item without any problem.
+const MaxScore = 100
+
+// Limits and penalties applied by the checker.
+const (
+	maxTitleLength       = 120
+	minDescriptionLength = 60
+	minStoryCriteria     = 2
+
+	penaltyMissingTitle       = 20
+	penaltyLongTitle          = 10
+	penaltyShortDescription   = 15
+	penaltyStoryFormat        = 15
+	penaltyNoCriteria         = 30
+	penaltyFewCriteria        = 10
+	penaltyGherkinCriterion   = 5
+	penaltyDuplicateCriterion = 5
+	penaltyMissingTasks       = 15
+	penaltyUnexpectedTasks    = 5
+	penaltyTypeMismatch       = 10
+)
+
+// storyFormat matches the "As a [persona], I want [feature] so that [benefit]" format.
+var storyFormat = regexp.MustCompile(`(?is)\bas an?\b.+\bi want\b.+\bso that\b`)
+
+// gherkinSteps matches a criterion written with the Given / When / Then steps.
+var gherkinSteps = regexp.MustCompile(`(?is)\bgiven\b.+\bwhen\b.+\bthen\b`)
+
+// Report is the result of a quality check.
+type Report struct {
+	Score  int      // From 0 to MaxScore
+	Issues []string // Problems found, empty when the item has none
+}
+
+// Checker scores generated content. It implements llm.Scorer.
+type Checker struct {
+	grounded bool     // Also penalize the terms not found in the request nor the glossary, see Ungrounded
+	glossary []string // Terms known to exist, such as the names of the product features and systems
+}
+
+// NewChecker creates a new Checker.
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// NewGroundedChecker creates a Checker that also penalizes the content mentioning features or
+// systems not found in the request nor in the glossary.
+func NewGroundedChecker(glossary []string) *Checker {
+	return &Checker{grounded: true, glossary: glossary}
+}
+
+// Score returns the score and the problems of the content generated for the request.
+func (c *Checker) Score(req llm.Request, content *llm.GeneratedContent) (int, []string) {
+	report := Check(req, content)
+	if c.grounded {
+		if terms := Ungrounded(req, content, c.glossary); len(terms) > 0 {
+			report.penalize(penaltyUngrounded, UngroundedIssue(terms))
+		}
+	}
+	return report.Score, report.Issues
+}
+
+// Check evaluates the content generated for the request. Wording rules (the User Story format and
+// Gherkin keywords) are only applied to English content.
+func Check(req llm.Request, content *llm.GeneratedContent) Report {
+	r := &Report{Score: MaxScore}
+	english := isEnglish(req.Language)
+
+	title := strings.TrimSpace(content.Title)
+	switch {
+	case title == "":
+		r.penalize(penaltyMissingTitle, "the title is empty")
+	case len([]rune(title)) > maxTitleLength:
+		r.penalize(penaltyLongTitle, fmt.Sprintf("the title is longer than %d characters", maxTitleLength))
+	}
+
+	description := strings.TrimSpace(content.Description)
+	if len([]rune(description)) < minDescriptionLength {
+		r.penalize(penaltyShortDescription, "the description is too short to explain the need and its value")
+	}
+	if req.ItemType == prompt.UserStory && english && !storyFormat.MatchString(description) {
+		r.penalize(penaltyStoryFormat, `the description does not follow the "As a [persona], I want [feature] so that [benefit]" format`)
+	}
+
+	if content.Type != "" && req.ItemType != "" && !strings.EqualFold(content.Type, req.ItemType.String()) {
+		r.penalize(penaltyTypeMismatch, fmt.Sprintf("the type is %q instead of %q", content.Type, req.ItemType))
+	}
+
+	checkCriteria(r, req, content.AcceptanceCriteria, english)
+
+	if req.GenerateTasks && req.ItemType != prompt.Epic && len(content.SuggestedTasks) == 0 {
+		r.penalize(penaltyMissingTasks, "tasks were requested but none were suggested")
+	}
+	if !req.GenerateTasks && len(content.SuggestedTasks) > 0 {
+		r.penalize(penaltyUnexpectedTasks, "tasks were suggested but not requested")
+	}
+
+	return *r
+}
+
+// checkCriteria evaluates the acceptance criteria.
+func checkCriteria(r *Report, req llm.Request, criteria []string, english bool) {
+	if len(criteria) == 0 {
+		r.penalize(penaltyNoCriteria, "there are no acceptance criteria")
+		return
+	}
+	if req.ItemType == prompt.UserStory && len(criteria) < minStoryCriteria {
+		r.penalize(penaltyFewCriteria, fmt.Sprintf("there are fewer than %d acceptance criteria", minStoryCriteria))
+	}
+
+	gherkin := req.CriteriaFormat == "" || req.CriteriaFormat == prompt.CriteriaGherkin
+	seen := make(map[string]bool)
+	for i, c := range criteria {
+		key := strings.ToLower(strings.TrimSpace(c))
+		if seen[key] {
+			r.penalize(penaltyDuplicateCriterion, fmt.Sprintf("acceptance criterion %d is duplicated", i+1))
+		}
+		seen[key] = true
+		if gherkin && english && !gherkinSteps.MatchString(c) {
+			r.penalize(penaltyGherkinCriterion, fmt.Sprintf("acceptance criterion %d is not written as Given / When / Then", i+1))
+		}
+	}
+}
+
+// penalize records an issue and lowers the score, which never goes below zero.
+func (r *Report) penalize(points int, issue string) {
+	r.Score = max(0, r.Score-points)
+	r.Issues = append(r.Issues, issue)
+}
+
+// isEnglish reports whether the output language is English, which is the default.
+func isEnglish(language string) bool {
+	language = strings.ToLower(strings.TrimSpace(language))
+	return language == "" || strings.HasPrefix(language, "en")
+}
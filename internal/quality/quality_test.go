@@ -0,0 +1,132 @@
+package quality
+
+import (
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+)
+
+// goodStory is a User Story without any problem.
+func goodStory() *llm.GeneratedContent {
+	return &llm.GeneratedContent{
+		Type:        "User Story",
+		Title:       "As a shopper, I want to pay with a credit card",
+		Description: "As a shopper, I want to pay with my credit card so that I can complete purchases without leaving the checkout.",
+		AcceptanceCriteria: []string{
+			"Given a valid card When the shopper pays Then the order is confirmed",
+			"Given a declined card When the shopper pays Then an error is shown",
+		},
+		SuggestedTasks: []string{"Integrate the payment gateway"},
+	}
+}
+
+func TestCheck_NoIssues(t *testing.T) {
+	req := llm.Request{ItemType: prompt.UserStory, Language: "english", GenerateTasks: true}
+	report := Check(req, goodStory())
+	assert.Equal(t, MaxScore, report.Score)
+	assert.Empty(t, report.Issues)
+}
+
+func TestCheck_Issues(t *testing.T) {
+	req := llm.Request{ItemType: prompt.UserStory, Language: "en", GenerateTasks: true}
+	content := &llm.GeneratedContent{
+		Type:               "Epic",
+		Title:              "Payments",
+		Description:        "Card payments.",
+		AcceptanceCriteria: []string{"It works", "it works"},
+	}
+
+	report := Check(req, content)
+	assert.Less(t, report.Score, MaxScore)
+	assert.Equal(t, MaxScore-penaltyShortDescription-penaltyStoryFormat-penaltyTypeMismatch-2*penaltyGherkinCriterion-penaltyDuplicateCriterion-penaltyMissingTasks, report.Score)
+	assert.Contains(t, report.Issues, "the description is too short to explain the need and its value")
+	assert.Contains(t, report.Issues, "acceptance criterion 2 is duplicated")
+	assert.Contains(t, report.Issues, "tasks were requested but none were suggested")
+}
+
+func TestCheck_CriteriaFormatAndLanguage(t *testing.T) {
+	content := goodStory()
+	content.AcceptanceCriteria = []string{"O pedido é confirmado", "Um erro é exibido"}
+	content.Description = "Como comprador, quero pagar com cartão de crédito para concluir compras sem sair do checkout."
+
+	// Wording rules are not applied to other languages
+	report := Check(llm.Request{ItemType: prompt.UserStory, Language: "portuguese", GenerateTasks: true}, content)
+	assert.Equal(t, MaxScore, report.Score)
+
+	// Non Gherkin formats do not require Given / When / Then
+	content = goodStory()
+	content.AcceptanceCriteria = []string{"The order is confirmed", "An error is shown"}
+	report = Check(llm.Request{ItemType: prompt.UserStory, CriteriaFormat: prompt.CriteriaChecklist, GenerateTasks: true}, content)
+	assert.Equal(t, MaxScore, report.Score)
+}
+
+func TestCheck_EmptyContent(t *testing.T) {
+	report := Check(llm.Request{ItemType: prompt.Epic}, &llm.GeneratedContent{})
+	assert.Equal(t, MaxScore-penaltyMissingTitle-penaltyShortDescription-penaltyNoCriteria, report.Score)
+	assert.Len(t, report.Issues, 3)
+}
+
+func TestChecker_Score(t *testing.T) {
+	score, issues := NewChecker().Score(llm.Request{ItemType: prompt.UserStory, GenerateTasks: true}, goodStory())
+	assert.Equal(t, MaxScore, score)
+	assert.Empty(t, issues)
+}
+
+func TestCheckIssue(t *testing.T) {
+	body := "As a shopper, I want to pay with my credit card so that I can complete purchases quickly.\n\n" +
+		"## Critérios de Aceitação\n\n1. O pedido é confirmado\n\n<!-- aigile:source row=2 -->"
+	assert.Empty(t, CheckIssue("Pay with a credit card", body, []string{"Critérios de Aceitação"}))
+
+	gherkin := "As a shopper, I want to pay with my credit card so that I can complete purchases quickly.\n\n" +
+		"Given a valid card When the shopper pays Then the order is confirmed"
+	assert.Empty(t, CheckIssue("Pay with a credit card", gherkin, nil))
+
+	problems := CheckIssue("", "Improve checkout, TBD etc.\n\n## Notes\n- TODO", nil)
+	assert.Equal(t, []string{
+		"the title is empty",
+		"the description is too short to explain the need and its value",
+		"the description is vague (etc, tbd)",
+		"there are no acceptance criteria",
+	}, problems)
+}
+
+func TestUngrounded(t *testing.T) {
+	req := llm.Request{
+		ItemType:      prompt.UserStory,
+		Language:      "english",
+		Parent:        "Checkout",
+		Context:       "Shoppers pay with a credit card through the payment gateway",
+		Documentation: []string{"From docs/payments.md:\nCards are authorized by Adyen."},
+	}
+	content := goodStory()
+	assert.Empty(t, Ungrounded(req, content, nil))
+
+	content.Description = "As a shopper, I want to pay with Google Pay through Adyen so that the Checkout is faster. Orders are queued in Kafka."
+	content.AcceptanceCriteria = append(content.AcceptanceCriteria, "Given a PayPal account When the shopper pays Then the SAP ledger and the S3 archive are updated through the API")
+	assert.Equal(t, []string{"Google Pay", "Kafka", "PayPal", "SAP", "S3"}, Ungrounded(req, content, nil))
+	assert.Equal(t, []string{"Google Pay", "PayPal", "S3"}, Ungrounded(req, content, []string{"kafka", " SAP "}))
+
+	req.Language = "german"
+	content = goodStory()
+	content.Description = "Als Kunde möchte ich mit der Kreditkarte im Warenkorb bezahlen, über PayPal."
+	assert.Equal(t, []string{"PayPal"}, Ungrounded(req, content, nil))
+}
+
+func TestChecker_Grounded(t *testing.T) {
+	req := llm.Request{ItemType: prompt.UserStory, Language: "english", GenerateTasks: true, Context: "Pay with a credit card"}
+	content := goodStory()
+	content.SuggestedTasks = []string{"Integrate the payment gateway with Stripe"}
+
+	score, issues := NewChecker().Score(req, content)
+	assert.Equal(t, MaxScore, score)
+	assert.Empty(t, issues)
+
+	score, issues = NewGroundedChecker(nil).Score(req, content)
+	assert.Equal(t, MaxScore-penaltyUngrounded, score)
+	assert.Equal(t, []string{UngroundedIssue([]string{"Stripe"})}, issues)
+
+	score, _ = NewGroundedChecker([]string{"Stripe"}).Score(req, content)
+	assert.Equal(t, MaxScore, score)
+}
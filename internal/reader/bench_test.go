@@ -0,0 +1,88 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+// benchRows is the size of the large backlogs read by the benchmarks.
+const benchRows = 10000
+
+// benchBacklog returns the header and benchRows rows of a backlog, alternating epics and stories.
+func benchBacklog() [][]string {
+	rows := [][]string{{"Type", "Parent", "Context", "Criteria1", "Criteria2"}}
+	for i := range benchRows {
+		itemType := "User Story"
+		if i%20 == 0 {
+			itemType = "Epic"
+		}
+		rows = append(rows, []string{
+			itemType,
+			fmt.Sprintf("Project %d", i%10),
+			fmt.Sprintf("As a customer, I want feature %d so that I can complete my purchase faster", i),
+			"The feature is available to signed in customers",
+			"Errors are reported with a clear message",
+		})
+	}
+	return rows
+}
+
+// drain reads all the items of an iterator.
+func drain(b *testing.B, it Iterator) int {
+	n := 0
+	for {
+		_, err := it.Next()
+		if errors.Is(err, io.EOF) {
+			return n
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+		n++
+	}
+}
+
+// BenchmarkXLSXReader_Stream_10k measures streaming a 10k-row XLSX file.
+func BenchmarkXLSXReader_Stream_10k(b *testing.B) {
+	file := createTestXLSX(b, benchBacklog())
+	b.Cleanup(func() { _ = os.Remove(file) })
+
+	b.ResetTimer()
+	for range b.N {
+		it, err := NewXLSXReader(file).Stream()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if n := drain(b, it); n != benchRows {
+			b.Fatalf("expected %d items, got %d", benchRows, n)
+		}
+		_ = it.Close()
+	}
+}
+
+// BenchmarkGoogleSheetsReader_Stream_10k measures converting 10k fetched Google Sheets rows, without
+// the network.
+func BenchmarkGoogleSheetsReader_Stream_10k(b *testing.B) {
+	var values [][]interface{}
+	for _, row := range benchBacklog() {
+		cells := make([]interface{}, len(row))
+		for i, cell := range row {
+			cells[i] = cell
+		}
+		values = append(values, cells)
+	}
+
+	b.ResetTimer()
+	for range b.N {
+		it, err := NewGoogleSheetsReaderWithService("id", "creds", &windowedSheetsService{values: values}).Stream()
+		if err != nil {
+			b.Fatal(err)
+		}
+		if n := drain(b, it); n != benchRows {
+			b.Fatalf("expected %d items, got %d", benchRows, n)
+		}
+	}
+}
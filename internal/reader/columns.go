@@ -0,0 +1,188 @@
+package reader
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// Headers of the optional columns, matched case-insensitively. They can be placed anywhere after
+// the Context column and are not read as criteria. List columns accept comma-separated values.
+const (
+	LabelsHeader      = "Labels"
+	AssigneesHeader   = "Assignees"
+	MilestoneHeader   = "Milestone"
+	PriorityHeader    = "Priority"
+	RepositoryHeader  = "Repository"
+	SensitivityHeader = "Sensitivity"
+	DesignHeader      = "Design"
+	StatusHeader      = "Status"
+	TasksHeader       = "Tasks"
+	GroupHeader       = "GroupID"
+
+	// ExtraHeaderPrefix marks custom columns, read into Item.Extra without the prefix.
+	ExtraHeaderPrefix = "X-"
+)
+
+// firstOptionalColumn is the index of the first column after Type, Parent and Context.
+const firstOptionalColumn = 3
+
+// columnSetters fill an item field from the value of an optional column.
+var columnSetters = map[string]func(item *Item, value string){
+	strings.ToLower(LabelsHeader):      func(item *Item, value string) { item.Labels = splitList(value) },
+	strings.ToLower(AssigneesHeader):   func(item *Item, value string) { item.Assignees = splitList(value) },
+	strings.ToLower(MilestoneHeader):   func(item *Item, value string) { item.Milestone = value },
+	strings.ToLower(PriorityHeader):    func(item *Item, value string) { item.Priority = value },
+	strings.ToLower(RepositoryHeader):  func(item *Item, value string) { item.Repository = value },
+	strings.ToLower(SensitivityHeader): func(item *Item, value string) { item.Sensitivity = value },
+	strings.ToLower(DesignHeader):      func(item *Item, value string) { item.Designs = splitList(value) },
+	strings.ToLower(StatusHeader):      setStatus,
+	strings.ToLower(TasksHeader):       setTasks,
+	strings.ToLower(GroupHeader):       func(item *Item, value string) { item.Group = value },
+}
+
+// Statuses of the Status column.
+const (
+	StatusDraft = "draft" // Created as a draft item of the project, not ready to be an issue
+	StatusReady = "ready" // Created as an issue, even when drafts are the default
+)
+
+// setStatus sets the status of an item, warning about unknown statuses, which are ignored.
+func setStatus(item *Item, value string) {
+	switch status := strings.ToLower(value); status {
+	case StatusDraft, StatusReady:
+		item.Status = status
+	default:
+		item.Warnings = append(item.Warnings, fmt.Sprintf("unknown status %q ignored (expected %s or %s)", value, StatusDraft, StatusReady))
+	}
+}
+
+// Values of Item.Tasks other than a number of tasks.
+const (
+	TasksDefault = 0  // The --auto-tasks flag of the run decides
+	TasksNone    = -1 // No tasks, even with --auto-tasks
+	TasksAny     = -2 // Tasks, as many as the LLM suggests, even without --auto-tasks
+)
+
+// setTasks sets whether tasks are generated for an item from yes, no or a number of tasks, warning
+// about other values, which are ignored.
+func setTasks(item *Item, value string) {
+	if n, err := strconv.Atoi(value); err == nil && n >= 0 {
+		item.Tasks = n
+		if n == 0 {
+			item.Tasks = TasksNone
+		}
+		return
+	}
+	switch strings.ToLower(value) {
+	case "yes", "y", "true":
+		item.Tasks = TasksAny
+	case "no", "n", "false":
+		item.Tasks = TasksNone
+	default:
+		item.Warnings = append(item.Warnings, fmt.Sprintf("invalid tasks %q ignored (expected yes, no or a number of tasks)", value))
+	}
+}
+
+// rowParser converts the rows of a sheet into items. The first row is the header.
+type rowParser struct {
+	file    string
+	sheet   string
+	setters map[int]func(item *Item, value string) // optional columns by index
+}
+
+// newRowParser creates a parser for the columns described by header, in the given file and sheet.
+func newRowParser(file, sheet string, header []string) (*rowParser, error) {
+	p := &rowParser{file: file, sheet: sheet, setters: map[int]func(*Item, string){}}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		setter, ok := columnSetters[strings.ToLower(name)]
+		if !ok && len(name) > len(ExtraHeaderPrefix) && strings.EqualFold(name[:len(ExtraHeaderPrefix)], ExtraHeaderPrefix) {
+			key := name[len(ExtraHeaderPrefix):]
+			setter, ok = func(item *Item, value string) {
+				if item.Extra == nil {
+					item.Extra = map[string]string{}
+				}
+				item.Extra[key] = value
+			}, true
+		}
+		if !ok {
+			continue
+		}
+		if i < firstOptionalColumn {
+			return nil, fmt.Errorf("invalid header: the %s column must come after the Context column", name)
+		}
+		p.setters[i] = setter
+	}
+	return p, nil
+}
+
+// parse converts the row at the given 1-based row number. It returns false for blank rows, which
+// are skipped, and a *RowError for rows with an unknown type or without the required columns.
+func (p *rowParser) parse(rowNumber int, row []string) (Item, bool, error) {
+	if isBlank(row) {
+		return Item{}, false, nil
+	}
+	ref := SourceRef{File: p.file, Sheet: p.sheet, Row: rowNumber, Hash: rowHash(row)}
+	if len(row) < 4 {
+		return Item{}, false, &RowError{Source: ref, Reason: "missing required columns: Type, Parent, Context and at least one criterion"}
+	}
+
+	// Convert string type to ItemType
+	itemType := prompt.ItemType(row[0])
+	if !itemType.IsValid() {
+		return Item{}, false, &RowError{Source: ref, Reason: fmt.Sprintf("invalid item type %q (%s)", row[0], ref.Cell(0))}
+	}
+
+	item := Item{
+		ID:      strconv.Itoa(rowNumber),
+		Source:  ref,
+		Type:    itemType,
+		Parent:  row[1],
+		Context: row[2],
+	}
+
+	// The remaining columns are criteria, unless the header names them
+	for i := firstOptionalColumn; i < len(row); i++ {
+		if setter, ok := p.setters[i]; ok {
+			if value := strings.TrimSpace(row[i]); value != "" {
+				setter(&item, value)
+			}
+			continue
+		}
+		if strings.TrimSpace(row[i]) == "" {
+			continue
+		}
+		item.Criteria = append(item.Criteria, row[i])
+	}
+	if len(item.Criteria) == 0 {
+		item.Warnings = append(item.Warnings, noCriteriaWarning)
+	}
+	return item, true, nil
+}
+
+// noCriteriaWarning is the warning of the rows without acceptance criteria.
+const noCriteriaWarning = "no acceptance criteria: all the criteria cells are empty"
+
+// isBlank reports whether all the cells of a row are empty.
+func isBlank(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitList splits a comma-separated cell value, dropping empty entries.
+func splitList(value string) []string {
+	var values []string
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
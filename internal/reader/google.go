@@ -3,9 +3,10 @@ package reader
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 
-	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/httpclient"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/option"
 	"google.golang.org/api/sheets/v4"
@@ -29,15 +30,41 @@ func (r *realSheetsService) GetValues(spreadsheetID, readRange string) ([][]inte
 	return resp.Values, nil
 }
 
+// RowCount returns the number of rows of the sheet grid, including the empty ones.
+func (r *realSheetsService) RowCount(spreadsheetID, sheet string) (int, error) {
+	resp, err := r.srv.Spreadsheets.Get(spreadsheetID).Fields("sheets.properties").Do()
+	if err != nil {
+		return 0, err
+	}
+	for _, s := range resp.Sheets {
+		if s.Properties != nil && s.Properties.Title == sheet && s.Properties.GridProperties != nil {
+			return int(s.Properties.GridProperties.RowCount), nil
+		}
+	}
+	return 0, fmt.Errorf("sheet not found: %s", sheet)
+}
+
+// SheetsRowCounter is implemented by services that know the number of rows of a sheet, so the
+// reader fetches windows up to the last row instead of stopping at the first partial window.
+type SheetsRowCounter interface {
+	RowCount(spreadsheetID, sheet string) (int, error)
+}
+
 // GoogleSheetsReader reads items from a Google Sheets spreadsheet.
 type GoogleSheetsReader struct {
 	SpreadsheetID   string
 	CredentialsFile string        // Caminho para o arquivo de credenciais JSON
 	SheetsAPI       SheetsService // opcional, para testes
+	PageSize        int           // Rows fetched per request, DefaultGoogleSheetPageSize when 0
 }
 
-// DefaultGoogleSheetRange is the default range read from Google Sheets.
-const DefaultGoogleSheetRange = "Sheet1!A:D"
+// DefaultGoogleSheet is the sheet read from Google Sheets. Its rows are read whole, so every
+// column with a value is read, like in XLSX files.
+const DefaultGoogleSheet = "Sheet1"
+
+// DefaultGoogleSheetPageSize is the number of rows fetched per request, so only a window of a
+// large sheet is held in memory at a time.
+const DefaultGoogleSheetPageSize = 1000
 
 // NewGoogleSheetsReader creates a new reader for Google Sheets.
 func NewGoogleSheetsReader(spreadsheetID, credentialsFile string) *GoogleSheetsReader {
@@ -56,12 +83,23 @@ func NewGoogleSheetsReaderWithService(spreadsheetID, credentialsFile string, ser
 	}
 }
 
+// Read reads the sheet and returns all its items.
 func (r *GoogleSheetsReader) Read() ([]Item, error) {
+	it, err := r.Stream()
+	if err != nil {
+		return nil, err
+	}
+	return collect(it)
+}
+
+// Stream fetches the sheet values and returns an iterator that converts the rows to items one
+// at a time.
+func (r *GoogleSheetsReader) Stream() (Iterator, error) {
 	var service SheetsService
 	if r.SheetsAPI != nil {
 		service = r.SheetsAPI
 	} else {
-		ctx := context.Background()
+		ctx := httpclient.OAuth2Context(context.Background())
 		b, err := os.ReadFile(r.CredentialsFile)
 		if err != nil {
 			return nil, fmt.Errorf("unable to read credentials file: %w", err)
@@ -78,31 +116,108 @@ func (r *GoogleSheetsReader) Read() ([]Item, error) {
 		service = &realSheetsService{srv: srv}
 	}
 
-	respValues, err := service.GetValues(r.SpreadsheetID, DefaultGoogleSheetRange)
-	if err != nil {
-		return nil, fmt.Errorf("unable to retrieve data from sheet: %w", err)
+	sheet := DefaultGoogleSheet
+	it := &sheetsIterator{
+		service:       service,
+		spreadsheetID: r.SpreadsheetID,
+		sheet:         sheet,
+		pageSize:      r.PageSize,
+		parser:        &rowParser{},
+	}
+	if it.pageSize <= 0 {
+		it.pageSize = DefaultGoogleSheetPageSize
+	}
+	if counter, ok := service.(SheetsRowCounter); ok {
+		rowCount, err := counter.RowCount(r.SpreadsheetID, sheet)
+		if err != nil {
+			return nil, fmt.Errorf("unable to retrieve the size of the sheet: %w", err)
+		}
+		it.rowCount = rowCount
 	}
 
-	var items []Item
-	for i, row := range respValues {
-		if i == 0 { // Skip header
-			continue
+	if err := it.fetch(1); err != nil {
+		return nil, err
+	}
+	if len(it.values) > 0 {
+		var err error
+		if it.parser, err = newRowParser(r.SpreadsheetID, sheet, cellStrings(it.values[0])); err != nil {
+			return nil, err
 		}
-		if len(row) < 4 {
+		it.next = 1 // Skip header
+	}
+	return it, nil
+}
+
+// sheetsIterator converts the rows of a sheet to items one at a time, fetching them in windows of
+// pageSize rows.
+type sheetsIterator struct {
+	service       SheetsService
+	spreadsheetID string
+	sheet         string
+	pageSize      int
+	rowCount      int // Rows of the sheet, 0 when unknown
+	parser        *rowParser
+
+	values [][]interface{} // Current window
+	start  int             // Row number of the first row of the window
+	next   int             // Index of the next row in the window
+	done   bool            // Whether the window is the last one
+}
+
+// fetch replaces the current window with the one starting at row start.
+func (it *sheetsIterator) fetch(start int) error {
+	end := start + it.pageSize - 1
+	readRange := fmt.Sprintf("%s!%d:%d", it.sheet, start, end)
+	values, err := it.service.GetValues(it.spreadsheetID, readRange)
+	if err != nil {
+		return fmt.Errorf("unable to retrieve data from sheet: %w", err)
+	}
+	it.values, it.start, it.next = values, start, 0
+	if it.rowCount > 0 {
+		it.done = end >= it.rowCount
+	} else {
+		// Trailing empty rows are omitted, a partial window is the end of the data
+		it.done = len(values) < it.pageSize
+	}
+	return nil
+}
+
+// Next returns the item of the next valid row.
+func (it *sheetsIterator) Next() (Item, error) {
+	for {
+		if it.next >= len(it.values) {
+			if it.done {
+				return Item{}, io.EOF
+			}
+			if err := it.fetch(it.start + it.pageSize); err != nil {
+				return Item{}, err
+			}
 			continue
 		}
-		itemType := prompt.ItemType(fmt.Sprintf("%v", row[0]))
-		item := Item{
-			Type:    itemType,
-			Parent:  fmt.Sprintf("%v", row[1]),
-			Context: fmt.Sprintf("%v", row[2]),
+		i := it.next
+		it.next++
+		item, ok, err := it.parser.parse(it.start+i, cellStrings(it.values[i]))
+		if err != nil {
+			return Item{}, err
 		}
-		if len(row) > 3 {
-			for _, c := range row[3:] {
-				item.Criteria = append(item.Criteria, fmt.Sprintf("%v", c))
-			}
+		if ok {
+			return item, nil
 		}
-		items = append(items, item)
 	}
-	return items, nil
+}
+
+// Close releases the fetched rows.
+func (it *sheetsIterator) Close() error {
+	it.values = nil
+	it.done = true
+	return nil
+}
+
+// cellStrings converts the cells of a Sheets row to strings.
+func cellStrings(row []interface{}) []string {
+	cells := make([]string, len(row))
+	for i, c := range row {
+		cells[i] = fmt.Sprintf("%v", c)
+	}
+	return cells
 }
@@ -2,6 +2,7 @@ package reader
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"testing"
 
@@ -20,6 +21,49 @@ func (m *mockSheetsService) GetValues(spreadsheetID, readRange string) ([][]inte
 	return m.values, m.err
 }
 
+// windowedSheetsService serves the rows of the requested window, like the Sheets API, and records
+// the requested ranges.
+type windowedSheetsService struct {
+	values [][]interface{}
+	ranges []string
+}
+
+func (m *windowedSheetsService) GetValues(_, readRange string) ([][]interface{}, error) {
+	m.ranges = append(m.ranges, readRange)
+	var start, end int
+	if _, err := fmt.Sscanf(readRange, "Sheet1!%d:%d", &start, &end); err != nil {
+		return nil, err
+	}
+	if start > len(m.values) {
+		return nil, nil
+	}
+	window := m.values[start-1 : min(end, len(m.values))]
+	// Trailing empty rows are omitted
+	for len(window) > 0 && len(window[len(window)-1]) == 0 {
+		window = window[:len(window)-1]
+	}
+	return window, nil
+}
+
+// countedSheetsService is a windowedSheetsService that knows the number of rows of the sheet.
+type countedSheetsService struct {
+	windowedSheetsService
+	rowCount int
+}
+
+func (m *countedSheetsService) RowCount(_, _ string) (int, error) {
+	return m.rowCount, nil
+}
+
+// sheetRows returns a header followed by n story rows.
+func sheetRows(n int) [][]interface{} {
+	values := [][]interface{}{{"Type", "Parent", "Context", "Criteria"}}
+	for i := range n {
+		values = append(values, []interface{}{"User Story", "FEAT-1", fmt.Sprintf("Context%d", i+1), "Crit"})
+	}
+	return values
+}
+
 // --- Unit tests ---
 
 func TestGoogleSheetsReader_Read_InvalidCredentialsFile(t *testing.T) {
@@ -81,8 +125,15 @@ func TestGoogleSheetsReader_Read_InvalidType(t *testing.T) {
 	r := NewGoogleSheetsReaderWithService("id", "creds", &mockSheetsService{values: values})
 	items, err := r.Read()
 	assert.NoError(t, err)
-	assert.Len(t, items, 1)
-	assert.Equal(t, prompt.ItemType("InvalidType"), items[0].Type)
+	assert.Empty(t, items)
+
+	it, err := r.Stream()
+	assert.NoError(t, err)
+	defer it.Close()
+	_, err = Validate(it, Strict, nil).Next()
+	var rowErr *RowError
+	assert.ErrorAs(t, err, &rowErr)
+	assert.EqualError(t, err, `invalid row id:Sheet1!2: invalid item type "InvalidType" (Sheet1!A2)`)
 }
 
 func TestGoogleSheetsReader_Read_ValidRow(t *testing.T) {
@@ -95,6 +146,7 @@ func TestGoogleSheetsReader_Read_ValidRow(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, items, 1)
 	assert.Equal(t, prompt.UserStory, items[0].Type)
+	assert.Equal(t, "2", items[0].ID)
 	assert.Equal(t, "FEAT-1", items[0].Parent)
 	assert.Equal(t, "Context1", items[0].Context)
 	assert.Equal(t, []string{"Crit1", "Crit2"}, items[0].Criteria)
@@ -107,3 +159,59 @@ func TestGoogleSheetsReader_Read_ServiceError(t *testing.T) {
 	assert.Nil(t, items)
 	assert.Contains(t, err.Error(), "unable to retrieve data from sheet")
 }
+
+func TestGoogleSheetsReader_Read_Sensitivity(t *testing.T) {
+	values := [][]interface{}{
+		{"Type", "Parent", "Context", "Sensitivity", "Criteria"},
+		{"User Story", "FEAT-1", "Context1", "internal-only", "Crit1"},
+	}
+	r := NewGoogleSheetsReaderWithService("id", "creds", &mockSheetsService{values: values})
+	items, err := r.Read()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, []string{"Crit1"}, items[0].Criteria)
+	assert.Equal(t, "internal-only", items[0].Sensitivity)
+}
+
+func TestGoogleSheetsReader_Stream_Windows(t *testing.T) {
+	service := &windowedSheetsService{values: sheetRows(7)}
+	r := NewGoogleSheetsReaderWithService("id", "creds", service)
+	r.PageSize = 3
+	items, err := r.Read()
+	require.NoError(t, err)
+	require.Len(t, items, 7)
+	assert.Equal(t, "2", items[0].ID)
+	assert.Equal(t, "Context7", items[6].Context)
+	assert.Equal(t, "8", items[6].ID)
+	assert.Equal(t, []string{"Sheet1!1:3", "Sheet1!4:6", "Sheet1!7:9"}, service.ranges)
+}
+
+func TestGoogleSheetsReader_Stream_RowCount(t *testing.T) {
+	// An empty window in the middle of the sheet does not end the reading when the size is known
+	values := sheetRows(2)
+	values = append(values, []interface{}{}, []interface{}{}, []interface{}{})
+	values = append(values, []interface{}{"User Story", "FEAT-1", "After the gap", "Crit"})
+	service := &countedSheetsService{windowedSheetsService: windowedSheetsService{values: values}, rowCount: 10}
+	r := NewGoogleSheetsReaderWithService("id", "creds", service)
+	r.PageSize = 3
+	items, err := r.Read()
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+	assert.Equal(t, "After the gap", items[2].Context)
+	assert.Equal(t, "7", items[2].ID)
+	assert.Equal(t, []string{"Sheet1!1:3", "Sheet1!4:6", "Sheet1!7:9", "Sheet1!10:12"}, service.ranges)
+}
+
+func TestGoogleSheetsReader_Stream_AllColumns(t *testing.T) {
+	values := [][]interface{}{
+		{"Type", "Parent", "Context", "Criteria1", "Criteria2", "Criteria3", "Labels", "X-Team"},
+		{"User Story", "FEAT-1", "Context1", "Crit1", "Crit2", "Crit3", "ui, web", "Payments"},
+	}
+	r := NewGoogleSheetsReaderWithService("id", "creds", &windowedSheetsService{values: values})
+	items, err := r.Read()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, []string{"Crit1", "Crit2", "Crit3"}, items[0].Criteria)
+	assert.Equal(t, []string{"ui", "web"}, items[0].Labels)
+	assert.Equal(t, map[string]string{"Team": "Payments"}, items[0].Extra)
+}
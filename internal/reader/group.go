@@ -0,0 +1,149 @@
+package reader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// Group merges the rows of it with the same GroupID into a single item, at the position of the
+// first row of the group, so that fragments of context collected from different stakeholders are
+// generated together. Rows without a GroupID are passed as they are. As the rows of a group can be
+// anywhere in the source, the rest of the source is read once the first grouped row is found.
+func Group(it Iterator) Iterator {
+	return &groupingIterator{Iterator: it}
+}
+
+// groupingIterator merges the rows of the wrapped iterator by GroupID.
+type groupingIterator struct {
+	Iterator
+	buffered bool   // Whether the rest of the source has been read into pending
+	pending  []Item // Items left to return once buffered, groups merged
+}
+
+func (g *groupingIterator) Next() (Item, error) {
+	if g.buffered {
+		if len(g.pending) == 0 {
+			return Item{}, io.EOF
+		}
+		item := g.pending[0]
+		g.pending = g.pending[1:]
+		return item, nil
+	}
+
+	item, err := g.Iterator.Next()
+	if err != nil || item.Group == "" {
+		return item, err
+	}
+	if err := g.buffer(item); err != nil {
+		return Item{}, err
+	}
+	return g.Next()
+}
+
+// buffer reads the rest of the source after the first grouped item, merging the groups.
+func (g *groupingIterator) buffer(first Item) error {
+	items := []Item{first}
+	for {
+		item, err := g.Iterator.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		items = append(items, item)
+	}
+
+	groups := map[string]int{} // Index in pending of the item of each group
+	for _, item := range items {
+		i, ok := groups[item.Group]
+		if item.Group == "" || !ok {
+			if item.Group != "" {
+				groups[item.Group] = len(g.pending)
+			}
+			g.pending = append(g.pending, item)
+			continue
+		}
+		g.pending[i] = merge(g.pending[i], item)
+	}
+	for i, item := range g.pending {
+		if len(item.Merged) > 0 {
+			g.pending[i] = finishGroup(item)
+		}
+	}
+	g.buffered = true
+	return nil
+}
+
+// merge merges a row into the item of its group: the contexts are concatenated and the criteria,
+// labels, assignees and designs added, without duplicates. The type and Parent of the first row
+// win; the other columns are taken from the first row that has them.
+func merge(item, row Item) Item {
+	if row.Type != item.Type {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("row %d of group %q has type %q, merged as %q", row.Source.Row, item.Group, row.Type, item.Type))
+	}
+	if row.Parent != "" && row.Parent != item.Parent {
+		item.Warnings = append(item.Warnings, fmt.Sprintf("row %d of group %q has Parent %q, merged with Parent %q", row.Source.Row, item.Group, row.Parent, item.Parent))
+	}
+	if row.Context != "" {
+		item.Context = strings.TrimSpace(item.Context + "\n\n" + row.Context)
+	}
+	item.Criteria = appendUnique(item.Criteria, row.Criteria...)
+	item.Labels = appendUnique(item.Labels, row.Labels...)
+	item.Assignees = appendUnique(item.Assignees, row.Assignees...)
+	item.Designs = appendUnique(item.Designs, row.Designs...)
+	for _, field := range []struct{ dst, src *string }{
+		{&item.Milestone, &row.Milestone}, {&item.Priority, &row.Priority}, {&item.Repository, &row.Repository},
+		{&item.Project, &row.Project}, {&item.Sensitivity, &row.Sensitivity}, {&item.Status, &row.Status},
+	} {
+		if *field.dst == "" {
+			*field.dst = *field.src
+		}
+	}
+	if item.Tasks == TasksDefault {
+		item.Tasks = row.Tasks
+	}
+	for key, value := range row.Extra {
+		if _, ok := item.Extra[key]; !ok {
+			if item.Extra == nil {
+				item.Extra = map[string]string{}
+			}
+			item.Extra[key] = value
+		}
+	}
+	item.Warnings = append(item.Warnings, row.Warnings...)
+	item.Merged = append(item.Merged, row.Source)
+	return item
+}
+
+// finishGroup drops the warnings of the rows without criteria when the group has some, and hashes
+// the rows of the group together, so that a change in any of them is detected.
+func finishGroup(item Item) Item {
+	if len(item.Criteria) > 0 {
+		item.Warnings = slices.DeleteFunc(item.Warnings, func(w string) bool { return w == noCriteriaWarning })
+	} else {
+		item.Warnings = slices.Compact(item.Warnings)
+	}
+	hashes := []string{item.Source.Hash}
+	for _, ref := range item.Merged {
+		hashes = append(hashes, ref.Hash)
+	}
+	sum := sha256.Sum256([]byte(strings.Join(hashes, "\x1f")))
+	item.Source.Hash = hex.EncodeToString(sum[:6])
+	return item
+}
+
+// appendUnique appends the values missing from list.
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		if !slices.Contains(list, v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
@@ -0,0 +1,67 @@
+package reader
+
+import (
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// collectAll returns the items of an iterator until io.EOF.
+func collectAll(t *testing.T, it Iterator) []Item {
+	var items []Item
+	for {
+		item, err := it.Next()
+		if errors.Is(err, io.EOF) {
+			return items
+		}
+		require.NoError(t, err)
+		items = append(items, item)
+	}
+}
+
+// TestGroup tests that the rows of a group are merged at the position of the first one, wherever
+// the others are.
+func TestGroup(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "GroupID", "Labels", "Criteria"},
+		{"User Story", "FEAT-1", "Standalone", "", "", "Crit0"},
+		{"User Story", "FEAT-1", "From sales: export as CSV", "export", "sales", "Crit1"},
+		{"User Story", "FEAT-2", "Other", "", "", "Crit2"},
+		{"User Story", "", "From support: include the last login", "export", "support", ""},
+		{"Epic", "FEAT-3", "From security: mask the emails", "export", "sales", "Crit1"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+	it, err := Stream(NewXLSXReader(file))
+	require.NoError(t, err)
+
+	items := collectAll(t, Group(it))
+	require.Len(t, items, 3)
+	assert.Equal(t, "Standalone", items[0].Context)
+	merged := items[1]
+	assert.Equal(t, "3", merged.ID)
+	assert.Equal(t, prompt.UserStory, merged.Type)
+	assert.Equal(t, "FEAT-1", merged.Parent)
+	assert.Equal(t, "From sales: export as CSV\n\nFrom support: include the last login\n\nFrom security: mask the emails", merged.Context)
+	assert.Equal(t, []string{"Crit1"}, merged.Criteria)
+	assert.Equal(t, []string{"sales", "support"}, merged.Labels)
+	assert.Equal(t, []string{
+		`row 6 of group "export" has type "Epic", merged as "User Story"`,
+		`row 6 of group "export" has Parent "FEAT-3", merged with Parent "FEAT-1"`,
+	}, merged.Warnings)
+	require.Len(t, merged.Merged, 2)
+	assert.Equal(t, 5, merged.Merged[0].Row)
+	assert.NotEqual(t, rowHash(rows[2]), merged.Source.Hash)
+	assert.Equal(t, "Other", items[2].Context)
+}
+
+// TestGroup_Ungrouped tests that a source without groups is passed as it is.
+func TestGroup_Ungrouped(t *testing.T) {
+	items := []Item{{ID: "2", Context: "A"}, {ID: "3", Context: "B"}}
+	assert.Equal(t, items, collectAll(t, Group(&sliceIterator{items: items})))
+}
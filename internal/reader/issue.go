@@ -0,0 +1,76 @@
+package reader
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// listItemPattern matches the items of Markdown lists, with an optional task list checkbox.
+var listItemPattern = regexp.MustCompile(`^(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?(.+)$`)
+
+// issueRefPattern matches list items that only reference another issue, e.g. the stories
+// already tracked by an epic.
+var issueRefPattern = regexp.MustCompile(`^#\d+\b`)
+
+// IssueReader reads the items of an epic filed as an issue, to break it down into User Stories:
+// each item of the lists in the body becomes a story, or the whole body when it has no lists.
+// The title and body of the epic are sent as the context of every story. Parent is left empty, as
+// it names the project of the items and the epic is not one.
+type IssueReader struct {
+	Ref   string // Reference of the issue, e.g. #12, used as the source of the items
+	Title string
+	Body  string
+}
+
+// NewIssueReader creates a reader of the stories of the epic issue with the given reference,
+// title and body.
+func NewIssueReader(ref, title, body string) *IssueReader {
+	return &IssueReader{Ref: ref, Title: title, Body: body}
+}
+
+// Read returns a User Story for each list item of the epic body.
+func (r *IssueReader) Read() ([]Item, error) {
+	entries := r.listItems()
+	if len(entries) == 0 && strings.TrimSpace(r.Body) != "" {
+		entries = []string{r.Title}
+	}
+
+	epic := "Epic: " + r.Title
+	if body := strings.TrimSpace(r.Body); body != "" {
+		epic += "\n\n" + body
+	}
+	var items []Item
+	for i, entry := range entries {
+		ref := SourceRef{File: r.Ref, Row: i + 1, Hash: rowHash([]string{r.Title, entry})}
+		items = append(items, Item{
+			ID:      strconv.Itoa(ref.Row),
+			Source:  ref,
+			Type:    prompt.UserStory,
+			Context: entry + "\n\n" + epic,
+		})
+	}
+	return items, nil
+}
+
+// listItems returns the text of the list items of the body, skipping the references to other
+// issues and the content of code blocks.
+func (r *IssueReader) listItems() []string {
+	var entries []string
+	inCode := false
+	for _, line := range strings.Split(r.Body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "```") {
+			inCode = !inCode
+			continue
+		}
+		m := listItemPattern.FindStringSubmatch(line)
+		if inCode || m == nil || issueRefPattern.MatchString(m[1]) {
+			continue
+		}
+		entries = append(entries, strings.TrimSpace(m[1]))
+	}
+	return entries
+}
@@ -0,0 +1,35 @@
+package reader
+
+import (
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIssueReader_Read(t *testing.T) {
+	body := "Customers check out faster.\n\n## Acceptance Criteria\n- Pay by card\n* [ ] Refunds within 7 days\n1. Receipts by email\n\n```\n- not an item\n```\n\n## Stories\n- [ ] #14\n"
+	items, err := NewIssueReader("#7", "Checkout revamp", body).Read()
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	assert.Equal(t, "1", items[0].ID)
+	assert.Equal(t, prompt.UserStory, items[0].Type)
+	assert.Empty(t, items[0].Parent)
+	assert.Equal(t, "Pay by card\n\nEpic: Checkout revamp\n\n"+body[:len(body)-1], items[0].Context)
+	assert.Equal(t, "#7:1", items[0].Source.String())
+	assert.Contains(t, items[1].Context, "Refunds within 7 days\n\n")
+	assert.Contains(t, items[2].Context, "Receipts by email\n\n")
+}
+
+func TestIssueReader_Read_NoLists(t *testing.T) {
+	items, err := NewIssueReader("#7", "Checkout revamp", "Customers check out faster.").Read()
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "Checkout revamp\n\nEpic: Checkout revamp\n\nCustomers check out faster.", items[0].Context)
+
+	items, err = NewIssueReader("#7", "Checkout revamp", " ").Read()
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
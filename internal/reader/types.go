@@ -1,6 +1,153 @@
 package reader
 
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// Item represents a row read from a source (XLSX, Google Sheets, etc). Type, Parent, Context and
+// Criteria come from the fixed columns; the other fields are read from optional columns named in
+// the header and are empty when the source does not have them.
+type Item struct {
+	ID       string    // Stable row identifier (the row number in the source sheet)
+	Source   SourceRef // Provenance of the row
+	Type     prompt.ItemType
+	Parent   string
+	Context  string
+	Criteria []string
+
+	Labels      []string          // Extra labels for the created issues
+	Assignees   []string          // Logins of the issue assignees
+	Milestone   string            // Milestone (or iteration) of the issue
+	Priority    string            // Priority name, e.g. High
+	Repository  string            // Repository (or project) where the issue is created, overriding the default
+	Project     string            // Project (board) the issues are added to, overriding the Parent, e.g. from a computed field
+	Sensitivity string            // Data sensitivity of the row, e.g. internal-only
+	Designs     []string          // Mockups or screenshots of the item: image URLs, Figma links or files
+	Status      string            // StatusDraft, StatusReady or empty for the default of the run
+	Tasks       int               // Number of tasks to generate, or TasksDefault, TasksNone or TasksAny
+	Extra       map[string]string // Values of the X-<name> columns, by name
+	Group       string            // Rows with the same GroupID are merged into a single item, see Group
+	Merged      []SourceRef       // Other rows of the group merged into the item
+
+	Warnings []string // Problems found in the row that do not prevent processing it
+}
+
 // Reader is the interface for reading items from a source (XLSX, Google Sheets, etc).
 type Reader interface {
 	Read() ([]Item, error)
 }
+
+// Iterator yields the items of a source one at a time.
+type Iterator interface {
+	// Next returns the next item, or io.EOF when there are no more items.
+	Next() (Item, error)
+	Close() error
+}
+
+// StreamReader is implemented by readers that can stream their items instead of loading them
+// all in memory.
+type StreamReader interface {
+	Reader
+	Stream() (Iterator, error)
+}
+
+// Stream returns an iterator over the items of r, streaming them when r supports it.
+func Stream(r Reader) (Iterator, error) {
+	if s, ok := r.(StreamReader); ok {
+		return s.Stream()
+	}
+	items, err := r.Read()
+	if err != nil {
+		return nil, err
+	}
+	return &sliceIterator{items: items}, nil
+}
+
+// collect reads all the items of an iterator and closes it. Invalid rows are skipped with a
+// warning.
+func collect(it Iterator) ([]Item, error) {
+	it = Validate(it, Lenient, logSkipped)
+	defer func() {
+		if err := it.Close(); err != nil {
+			slog.Warn("failed to close reader", "error", err)
+		}
+	}()
+	var items []Item
+	for {
+		item, err := it.Next()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+}
+
+// sliceIterator iterates over items already in memory.
+type sliceIterator struct {
+	items []Item
+}
+
+func (s *sliceIterator) Next() (Item, error) {
+	if len(s.items) == 0 {
+		return Item{}, io.EOF
+	}
+	item := s.items[0]
+	s.items = s.items[1:]
+	return item, nil
+}
+
+func (s *sliceIterator) Close() error {
+	return nil
+}
+
+// SourceRef identifies the row an item was read from, so failures and created issues can be
+// traced back to the exact cells.
+type SourceRef struct {
+	File  string `json:"file"`           // File name, or spreadsheet ID for Google Sheets
+	Sheet string `json:"sheet"`          // Sheet name, empty for sources without sheets
+	Row   int    `json:"row"`            // 1-based row number
+	Hash  string `json:"hash"`           // Hash of the row cells, to detect changes in the source
+	Part  int    `json:"part,omitempty"` // 1-based story of a row split into several stories, 0 when not split
+}
+
+// String returns the reference in spreadsheet notation, e.g. backlog.xlsx:Sheet1!12, or the
+// source and the item number for sources without sheets, e.g. #7:2. The stories of a split row are
+// suffixed with their part, e.g. backlog.xlsx:Sheet1!12 (part 2).
+func (r SourceRef) String() string {
+	if r.Row == 0 {
+		return ""
+	}
+	ref := fmt.Sprintf("%s:%s!%d", r.File, r.Sheet, r.Row)
+	if r.Sheet == "" {
+		ref = fmt.Sprintf("%s:%d", r.File, r.Row)
+	}
+	if r.Part > 0 {
+		ref += fmt.Sprintf(" (part %d)", r.Part)
+	}
+	return ref
+}
+
+// Cell returns the reference of a cell of the row by its 0-based column index, e.g. Sheet1!C12.
+func (r SourceRef) Cell(column int) string {
+	name := ""
+	for n := column + 1; n > 0; n = (n - 1) / 26 {
+		name = string(rune('A'+(n-1)%26)) + name
+	}
+	return fmt.Sprintf("%s!%s%d", r.Sheet, name, r.Row)
+}
+
+// rowHash returns a short hash of the cells of a row.
+func rowHash(row []string) string {
+	sum := sha256.Sum256([]byte(strings.Join(row, "\x1f")))
+	return hex.EncodeToString(sum[:6])
+}
@@ -0,0 +1,61 @@
+package reader
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+// Mode tells how invalid rows are handled.
+type Mode string
+
+// Validation modes.
+const (
+	// Lenient skips invalid rows, reporting them to the caller.
+	Lenient Mode = "lenient"
+	// Strict fails on the first invalid row.
+	Strict Mode = "strict"
+)
+
+// RowError is returned for a row that has content but cannot be converted into an item, such as
+// a row with an unknown type or without the required columns.
+type RowError struct {
+	Source SourceRef
+	Reason string
+}
+
+func (e *RowError) Error() string {
+	return fmt.Sprintf("invalid row %s: %s", e.Source, e.Reason)
+}
+
+// Validate applies the validation mode to the rows of it. In strict mode the iterator fails with
+// the RowError of the first invalid row; in lenient mode invalid rows are passed to skipped, when
+// not nil, and the iteration continues. Errors reading the source always stop the iteration.
+func Validate(it Iterator, mode Mode, skipped func(*RowError)) Iterator {
+	return &validatingIterator{Iterator: it, mode: mode, skipped: skipped}
+}
+
+// validatingIterator applies a validation mode to the rows of the wrapped iterator.
+type validatingIterator struct {
+	Iterator
+	mode    Mode
+	skipped func(*RowError)
+}
+
+func (v *validatingIterator) Next() (Item, error) {
+	for {
+		item, err := v.Iterator.Next()
+		var rowErr *RowError
+		if v.mode == Strict || !errors.As(err, &rowErr) {
+			return item, err
+		}
+		if v.skipped != nil {
+			v.skipped(rowErr)
+		}
+	}
+}
+
+// logSkipped logs the rows skipped in lenient mode.
+func logSkipped(err *RowError) {
+	slog.Warn("skipping invalid row", "source", err.Source.String(), "reason", err.Reason)
+}
@@ -0,0 +1,50 @@
+package reader
+
+import (
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// rowsIterator yields a row per call, as an item or an error.
+type rowsIterator struct {
+	rows []any
+}
+
+func (r *rowsIterator) Next() (Item, error) {
+	if len(r.rows) == 0 {
+		return Item{}, io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	if err, ok := row.(error); ok {
+		return Item{}, err
+	}
+	return row.(Item), nil
+}
+
+func (r *rowsIterator) Close() error {
+	return nil
+}
+
+func TestValidate(t *testing.T) {
+	invalid := &RowError{Source: SourceRef{File: "backlog.xlsx", Sheet: "Sheet1", Row: 3}, Reason: "invalid item type"}
+	rows := func() []any { return []any{Item{ID: "2"}, invalid, Item{ID: "4"}} }
+
+	var skipped []*RowError
+	items, err := collect(Validate(&rowsIterator{rows: rows()}, Lenient, func(err *RowError) { skipped = append(skipped, err) }))
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{ID: "2"}, {ID: "4"}}, items)
+	assert.Equal(t, []*RowError{invalid}, skipped)
+
+	it := Validate(&rowsIterator{rows: rows()}, Strict, nil)
+	_, err = it.Next()
+	assert.NoError(t, err)
+	_, err = it.Next()
+	assert.EqualError(t, err, "invalid row backlog.xlsx:Sheet1!3: invalid item type")
+
+	_, err = collect(Validate(&rowsIterator{rows: []any{errors.New("failed to get rows")}}, Lenient, nil))
+	assert.EqualError(t, err, "failed to get rows")
+}
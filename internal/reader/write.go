@@ -0,0 +1,98 @@
+package reader
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// backlogSheet is the name of the sheet written by WriteXLSX, the one read from Google Sheets
+// when the file is imported there.
+const backlogSheet = "Sheet1"
+
+// optionalColumns lists the optional columns written by WriteXLSX, with the value of each item.
+var optionalColumns = []struct {
+	header string
+	value  func(item Item) string
+}{
+	{LabelsHeader, func(item Item) string { return strings.Join(item.Labels, ", ") }},
+	{AssigneesHeader, func(item Item) string { return strings.Join(item.Assignees, ", ") }},
+	{MilestoneHeader, func(item Item) string { return item.Milestone }},
+	{PriorityHeader, func(item Item) string { return item.Priority }},
+	{RepositoryHeader, func(item Item) string { return item.Repository }},
+	{SensitivityHeader, func(item Item) string { return item.Sensitivity }},
+	{DesignHeader, func(item Item) string { return strings.Join(item.Designs, ", ") }},
+	{StatusHeader, func(item Item) string { return item.Status }},
+}
+
+// WriteXLSX writes items to an XLSX file in the input format, so the file can be reviewed and
+// then passed to generate. The optional and X-<name> columns are written only when an item has
+// a value for them.
+func WriteXLSX(path string, items []Item) error {
+	criteria := 1
+	extras := map[string]bool{}
+	for _, item := range items {
+		criteria = max(criteria, len(item.Criteria))
+		for key := range item.Extra {
+			extras[key] = true
+		}
+	}
+
+	header := []string{"Type", "Parent", "Context"}
+	for i := range criteria {
+		header = append(header, fmt.Sprintf("Criteria %d", i+1))
+	}
+	var columns []func(item Item) string
+	for _, c := range optionalColumns {
+		for _, item := range items {
+			if c.value(item) != "" {
+				header = append(header, c.header)
+				columns = append(columns, c.value)
+				break
+			}
+		}
+	}
+	keys := make([]string, 0, len(extras))
+	for key := range extras {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		header = append(header, ExtraHeaderPrefix+key)
+		columns = append(columns, func(item Item) string { return item.Extra[key] })
+	}
+
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	if err := f.SetSheetRow(backlogSheet, "A1", &header); err != nil {
+		return fmt.Errorf("failed to write backlog header: %w", err)
+	}
+	for i, item := range items {
+		row := make([]string, 0, len(header))
+		row = append(row, item.Type.String(), item.Parent, item.Context)
+		for j := range criteria {
+			value := ""
+			if j < len(item.Criteria) {
+				value = item.Criteria[j]
+			}
+			row = append(row, value)
+		}
+		for _, value := range columns {
+			row = append(row, value(item))
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := f.SetSheetRow(backlogSheet, cell, &row); err != nil {
+			return fmt.Errorf("failed to write backlog row %d: %w", i+2, err)
+		}
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("failed to write backlog file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package reader
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteXLSX_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backlog.xlsx")
+	items := []Item{
+		{Type: prompt.UserStory, Parent: "Checkout", Context: "Refunds by card", Criteria: []string{"Full refunds", "Partial refunds"}, Extra: map[string]string{"Evidence": "Ana: we need refunds"}},
+		{Type: prompt.UserStory, Parent: "Reports", Context: "Monthly sales report", Criteria: []string{"PDF export"}, Labels: []string{"reports", "finance"}},
+	}
+	require.NoError(t, WriteXLSX(path, items))
+
+	read, err := NewXLSXReader(path).Read()
+	require.NoError(t, err)
+	require.Len(t, read, 2)
+	assert.Equal(t, "Checkout", read[0].Parent)
+	assert.Equal(t, []string{"Full refunds", "Partial refunds"}, read[0].Criteria)
+	assert.Equal(t, map[string]string{"Evidence": "Ana: we need refunds"}, read[0].Extra)
+	assert.Nil(t, read[0].Labels)
+	assert.Equal(t, "Monthly sales report", read[1].Context)
+	assert.Equal(t, []string{"PDF export"}, read[1].Criteria)
+	assert.Equal(t, []string{"reports", "finance"}, read[1].Labels)
+	assert.Equal(t, SourceRef{File: "backlog.xlsx", Sheet: "Sheet1", Row: 3, Hash: read[1].Source.Hash}, read[1].Source)
+}
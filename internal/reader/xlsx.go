@@ -3,21 +3,12 @@ package reader
 
 import (
 	"fmt"
-
-	"log/slog"
-
-	"github.com/leocomelli/aigile/internal/prompt"
 	"github.com/xuri/excelize/v2"
+	"io"
+	"log/slog"
+	"path/filepath"
 )
 
-// Item represents a row read from a source (XLSX, Google Sheets, etc).
-type Item = struct {
-	Type     prompt.ItemType
-	Parent   string
-	Context  string
-	Criteria []string
-}
-
 // XLSXReader reads items from an XLSX file.
 type XLSXReader struct {
 	filePath string
@@ -32,60 +23,90 @@ func NewXLSXReader(filePath string) *XLSXReader {
 
 // Read reads the XLSX file and returns a slice of Items or an error.
 func (r *XLSXReader) Read() ([]Item, error) {
+	it, err := r.Stream()
+	if err != nil {
+		return nil, err
+	}
+	return collect(it)
+}
+
+// Stream opens the XLSX file and returns an iterator that reads its first sheet row by row,
+// without loading the whole sheet in memory.
+func (r *XLSXReader) Stream() (Iterator, error) {
 	f, err := excelize.OpenFile(r.filePath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
-	defer func() {
-		err := f.Close()
-		if err != nil {
-			slog.Warn("failed to close xlsx file", "error", err)
-		}
-	}()
+	it := &xlsxIterator{file: f}
 
 	sheets := f.GetSheetList()
 	if len(sheets) == 0 {
+		_ = it.Close()
 		return nil, fmt.Errorf("failed to get rows: no sheets found")
 	}
-
 	sheetName := sheets[0]
 
-	rows, err := f.GetRows(sheetName)
+	it.rows, err = f.Rows(sheetName)
 	if err != nil {
+		_ = it.Close()
 		return nil, fmt.Errorf("failed to get rows: %w", err)
 	}
-	if len(rows) == 0 {
+	if !it.rows.Next() {
+		_ = it.Close()
 		return nil, fmt.Errorf("failed to get rows: sheet '%s' is empty or invalid", sheetName)
 	}
+	header, err := it.rows.Columns()
+	if err != nil {
+		_ = it.Close()
+		return nil, fmt.Errorf("failed to get rows: %w", err)
+	}
+	it.rowNumber = 1
+	if it.parser, err = newRowParser(filepath.Base(r.filePath), sheetName, header); err != nil {
+		_ = it.Close()
+		return nil, err
+	}
+	return it, nil
+}
 
-	var items []Item
-	for i, row := range rows {
-		if i == 0 { // Skip header
-			continue
-		}
-		if len(row) < 4 {
-			continue
-		}
+// xlsxIterator reads the rows of a sheet one at a time.
+type xlsxIterator struct {
+	file      *excelize.File
+	rows      *excelize.Rows
+	parser    *rowParser
+	rowNumber int
+}
 
-		// Convert string type to ItemType
-		itemType := prompt.ItemType(row[0])
-		if !itemType.IsValid() {
-			return nil, fmt.Errorf("invalid item type at row %d: %s", i+1, row[0])
+// Next returns the item of the next valid row.
+func (it *xlsxIterator) Next() (Item, error) {
+	for it.rows.Next() {
+		it.rowNumber++
+		row, err := it.rows.Columns()
+		if err != nil {
+			return Item{}, fmt.Errorf("failed to read row %d: %w", it.rowNumber, err)
 		}
-
-		item := Item{
-			Type:    itemType,
-			Parent:  row[1],
-			Context: row[2],
+		item, ok, err := it.parser.parse(it.rowNumber, row)
+		if err != nil {
+			return Item{}, err
 		}
-
-		// Add criteria if available
-		if len(row) > 3 {
-			item.Criteria = row[3:]
+		if ok {
+			return item, nil
 		}
-
-		items = append(items, item)
 	}
+	if err := it.rows.Error(); err != nil {
+		return Item{}, fmt.Errorf("failed to get rows: %w", err)
+	}
+	return Item{}, io.EOF
+}
 
-	return items, nil
+// Close releases the sheet and the file.
+func (it *xlsxIterator) Close() error {
+	if it.rows != nil {
+		if err := it.rows.Close(); err != nil {
+			slog.Warn("failed to close xlsx rows", "error", err)
+		}
+	}
+	if err := it.file.Close(); err != nil {
+		return fmt.Errorf("failed to close xlsx file: %w", err)
+	}
+	return nil
 }
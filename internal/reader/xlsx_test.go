@@ -2,7 +2,9 @@ package reader
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/leocomelli/aigile/internal/prompt"
@@ -11,7 +13,7 @@ import (
 )
 
 // createTestXLSX creates a temporary XLSX file with the provided rows for testing.
-func createTestXLSX(t *testing.T, rows [][]string) string {
+func createTestXLSX(t testing.TB, rows [][]string) string {
 	f := excelize.NewFile()
 	// Rename default sheet to 'Sheet1' if needed
 	defaultSheet := f.GetSheetName(f.GetActiveSheetIndex())
@@ -70,11 +72,206 @@ func TestXLSXReader_Read_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, items, 1)
 	assert.Equal(t, prompt.UserStory, items[0].Type)
+	assert.Equal(t, "2", items[0].ID)
 	assert.Equal(t, "FEAT-1", items[0].Parent)
 	assert.Equal(t, "Context1", items[0].Context)
 	assert.Equal(t, []string{"Crit1", "Crit2"}, items[0].Criteria)
 }
 
+// TestXLSXReader_Read_Sensitivity tests that the Sensitivity column is read apart from the criteria.
+func TestXLSXReader_Read_Sensitivity(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Criteria1", "sensitivity", "Criteria2"},
+		{"User Story", "FEAT-1", "Context1", "Crit1", "internal-only", "Crit2"},
+		{"User Story", "FEAT-1", "Context2", "Crit1"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, []string{"Crit1", "Crit2"}, items[0].Criteria)
+	assert.Equal(t, "internal-only", items[0].Sensitivity)
+	assert.Equal(t, []string{"Crit1"}, items[1].Criteria)
+	assert.Empty(t, items[1].Sensitivity)
+
+	file = createTestXLSX(t, [][]string{{"Type", "Sensitivity", "Context", "Criteria"}})
+	defer os.Remove(file)
+	_, err = NewXLSXReader(file).Read()
+	assert.ErrorContains(t, err, "the Sensitivity column must come after the Context column")
+}
+
+// TestXLSXReader_Read_Design tests that the Design column is read as a list of image references.
+func TestXLSXReader_Read_Design(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Criteria", "Design"},
+		{"User Story", "FEAT-1", "Context1", "Crit1", "https://example.com/checkout.png, designs/cart.png"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	assert.Equal(t, []string{"Crit1"}, items[0].Criteria)
+	assert.Equal(t, []string{"https://example.com/checkout.png", "designs/cart.png"}, items[0].Designs)
+}
+
+// TestXLSXReader_Read_Status tests that the Status column is read apart from the criteria, with a
+// warning for unknown statuses.
+func TestXLSXReader_Read_Status(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Status", "Criteria"},
+		{"User Story", "Board", "Context1", "Draft", "Crit1"},
+		{"User Story", "Board", "Context2", "ready", "Crit2"},
+		{"User Story", "Board", "Context3", "done", "Crit3"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 3)
+	assert.Equal(t, StatusDraft, items[0].Status)
+	assert.Equal(t, []string{"Crit1"}, items[0].Criteria)
+	assert.Equal(t, StatusReady, items[1].Status)
+	assert.Empty(t, items[2].Status)
+	assert.Equal(t, []string{`unknown status "done" ignored (expected draft or ready)`}, items[2].Warnings)
+}
+
+// TestXLSXReader_Read_Tasks tests that the Tasks column is read apart from the criteria, with a
+// warning for invalid values.
+func TestXLSXReader_Read_Tasks(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Tasks", "Criteria"},
+		{"User Story", "FEAT-1", "Context1", "Yes", "Crit1"},
+		{"User Story", "FEAT-1", "Context2", "no", "Crit2"},
+		{"User Story", "FEAT-1", "Context3", "4", "Crit3"},
+		{"User Story", "FEAT-1", "Context4", "0", "Crit4"},
+		{"User Story", "FEAT-1", "Context5", "", "Crit5"},
+		{"User Story", "FEAT-1", "Context6", "some", "Crit6"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 6)
+	assert.Equal(t, TasksAny, items[0].Tasks)
+	assert.Equal(t, []string{"Crit1"}, items[0].Criteria)
+	assert.Equal(t, TasksNone, items[1].Tasks)
+	assert.Equal(t, 4, items[2].Tasks)
+	assert.Equal(t, TasksNone, items[3].Tasks)
+	assert.Equal(t, TasksDefault, items[4].Tasks)
+	assert.Equal(t, TasksDefault, items[5].Tasks)
+	assert.Equal(t, []string{`invalid tasks "some" ignored (expected yes, no or a number of tasks)`}, items[5].Warnings)
+}
+
+// TestXLSXReader_Read_EmptyCriteria tests that empty criteria cells are dropped, with a warning
+// for rows left without criteria.
+func TestXLSXReader_Read_EmptyCriteria(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Criteria", "Criteria", "Labels"},
+		{"User Story", "FEAT-1", "Context1", "", "Crit2"},
+		{"User Story", "FEAT-1", "Context2", " ", "", "backend"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+	assert.Equal(t, []string{"Crit2"}, items[0].Criteria)
+	assert.Empty(t, items[0].Warnings)
+	assert.Empty(t, items[1].Criteria)
+	assert.Equal(t, []string{"no acceptance criteria: all the criteria cells are empty"}, items[1].Warnings)
+}
+
+// TestXLSXReader_Read_OptionalColumns tests reading the optional columns named in the header.
+func TestXLSXReader_Read_OptionalColumns(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Criteria", "LABELS", "Assignees", "Milestone", "Priority", "Repository", "X-Team", "Criteria"},
+		{"User Story", "FEAT-1", "Context1", "Crit1", "backend, api", "alice,bob", "v1.0", "High", "org/payments", "Core", "Crit2"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	items, err := NewXLSXReader(file).Read()
+	assert.NoError(t, err)
+	assert.Len(t, items, 1)
+	item := items[0]
+	assert.Equal(t, []string{"Crit1", "Crit2"}, item.Criteria)
+	assert.Equal(t, []string{"backend", "api"}, item.Labels)
+	assert.Equal(t, []string{"alice", "bob"}, item.Assignees)
+	assert.Equal(t, "v1.0", item.Milestone)
+	assert.Equal(t, "High", item.Priority)
+	assert.Equal(t, "org/payments", item.Repository)
+	assert.Equal(t, map[string]string{"Team": "Core"}, item.Extra)
+}
+
+// TestXLSXReader_Stream tests iterating over the rows one at a time, keeping the row numbers.
+func TestXLSXReader_Stream(t *testing.T) {
+	rows := [][]string{
+		{"Type", "Parent", "Context", "Criteria"},
+		{"User Story", "FEAT-1", "Context1", "Crit1"},
+		{},
+		{"Epic", "", "Context2", "Crit2"},
+	}
+	file := createTestXLSX(t, rows)
+	defer os.Remove(file)
+
+	it, err := NewXLSXReader(file).Stream()
+	assert.NoError(t, err)
+	defer it.Close()
+
+	item, err := it.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "2", item.ID)
+	assert.Equal(t, filepath.Base(file), item.Source.File)
+	assert.Equal(t, "Sheet1", item.Source.Sheet)
+	assert.Equal(t, 2, item.Source.Row)
+	assert.Len(t, item.Source.Hash, 12)
+	assert.Equal(t, filepath.Base(file)+":Sheet1!2", item.Source.String())
+	item, err = it.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "4", item.ID)
+	assert.Equal(t, prompt.Epic, item.Type)
+	_, err = it.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+// TestSourceRef tests the cell references and hashes of source rows.
+func TestSourceRef(t *testing.T) {
+	ref := SourceRef{File: "backlog.xlsx", Sheet: "Sheet1", Row: 12}
+	assert.Equal(t, "Sheet1!A12", ref.Cell(0))
+	assert.Equal(t, "Sheet1!Z12", ref.Cell(25))
+	assert.Equal(t, "Sheet1!AB12", ref.Cell(27))
+	assert.Empty(t, SourceRef{}.String())
+	assert.Equal(t, "backlog.xlsx:Sheet1!12", ref.String())
+	ref.Part = 2
+	assert.Equal(t, "backlog.xlsx:Sheet1!12 (part 2)", ref.String())
+
+	assert.Equal(t, rowHash([]string{"a", "b"}), rowHash([]string{"a", "b"}))
+	assert.NotEqual(t, rowHash([]string{"a", "b"}), rowHash([]string{"ab"}))
+}
+
+// staticReader is a Reader without streaming support.
+type staticReader []Item
+
+func (s staticReader) Read() ([]Item, error) {
+	return s, nil
+}
+
+// TestStream_Fallback tests that readers without streaming support are iterated from memory.
+func TestStream_Fallback(t *testing.T) {
+	it, err := Stream(staticReader{{ID: "2"}, {ID: "3"}})
+	assert.NoError(t, err)
+	items, err := collect(it)
+	assert.NoError(t, err)
+	assert.Equal(t, []Item{{ID: "2"}, {ID: "3"}}, items)
+}
+
 // TestXLSXReader_Read_OpenFileError tests error handling when the XLSX file does not exist.
 func TestXLSXReader_Read_OpenFileError(t *testing.T) {
 	r := NewXLSXReader("nonexistent.xlsx")
@@ -107,7 +304,8 @@ func TestXLSXReader_Read_GetRowsError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get rows")
 }
 
-// TestXLSXReader_Read_InvalidType tests error handling for invalid item types in the XLSX file.
+// TestXLSXReader_Read_InvalidType tests that rows with invalid item types are skipped, or fail in
+// strict mode.
 func TestXLSXReader_Read_InvalidType(t *testing.T) {
 	rows := [][]string{
 		{"Type", "Parent", "Context", "Criteria1"},
@@ -122,9 +320,14 @@ func TestXLSXReader_Read_InvalidType(t *testing.T) {
 
 	r := NewXLSXReader(file)
 	items, err := r.Read()
-	assert.Error(t, err)
-	assert.Nil(t, items)
-	assert.Contains(t, err.Error(), "invalid item type")
+	assert.NoError(t, err)
+	assert.Empty(t, items)
+
+	it, err := r.Stream()
+	assert.NoError(t, err)
+	defer it.Close()
+	_, err = Validate(it, Strict, nil).Next()
+	assert.ErrorContains(t, err, `invalid item type "InvalidType"`)
 }
 
 // TestXLSXReader_Read_SkipHeaderAndShortRows tests skipping header and short/incomplete rows.
@@ -0,0 +1,93 @@
+// Package redact masks personal data and secrets in texts before they are sent to a cloud LLM, and
+// the secrets of the logs and errors.
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// Detector finds one kind of sensitive data.
+type Detector struct {
+	Name    string
+	Pattern *regexp.Regexp
+	Valid   func(match string) bool // Tells the matches that are the data apart from lookalikes, nil for all of them
+}
+
+// DefaultDetectors are the detectors enabled by NewRedactor. More specific patterns come first,
+// so a token is reported by its kind rather than as a generic secret.
+var DefaultDetectors = []Detector{
+	{Name: "private_key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`)},
+	{Name: "github_token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`)},
+	{Name: "aws_access_key", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{Name: "slack_token", Pattern: regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}\b`)},
+	{Name: "openai_key", Pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}\b`)},
+	{Name: "jwt", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`)},
+	{Name: "bearer_token", Pattern: regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]{16,}=*`)},
+	{Name: "secret_assignment", Pattern: regexp.MustCompile(`(?i)\b(?:password|passwd|secret|api[_-]?key|token)\s*[:=]\s*["']?[^\s"']{6,}`)},
+	{Name: "email", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	{Name: "credit_card", Pattern: regexp.MustCompile(`\b(?:\d[ -]?){12,15}\d\b`), Valid: luhn},
+	{Name: "ip_address", Pattern: regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`)},
+}
+
+// Redactor replaces the matches of its detectors with a [REDACTED:<name>] placeholder.
+type Redactor struct {
+	detectors []Detector
+}
+
+// NewRedactor creates a redactor with the default detectors and the extra patterns, given as
+// detector name to regular expression.
+func NewRedactor(patterns map[string]string) (*Redactor, error) {
+	detectors := append([]Detector(nil), DefaultDetectors...)
+
+	names := make([]string, 0, len(patterns))
+	for name := range patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pattern, err := regexp.Compile(patterns[name])
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %s: %w", name, err)
+		}
+		detectors = append(detectors, Detector{Name: name, Pattern: pattern})
+	}
+	return &Redactor{detectors: detectors}, nil
+}
+
+// Redact returns text with the sensitive data masked and the number of matches per detector.
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	found := map[string]int{}
+	for _, d := range r.detectors {
+		text = d.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if d.Valid != nil && !d.Valid(match) {
+				return match
+			}
+			found[d.Name]++
+			return "[REDACTED:" + d.Name + "]"
+		})
+	}
+	return text, found
+}
+
+// luhn reports whether the digits of a match pass the Luhn checksum of the card numbers, so order
+// numbers, timestamps and other IDs of the same length are not masked as cards.
+func luhn(match string) bool {
+	sum, double := 0, false
+	for i := len(match) - 1; i >= 0; i-- {
+		c := match[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
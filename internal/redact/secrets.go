@@ -0,0 +1,102 @@
+package redact
+
+import (
+	"io"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// secretDetectorNames are the detectors of DefaultDetectors masked in the logs and errors. The
+// personal data, such as emails, is left readable there, as it is part of the issues.
+var secretDetectorNames = []string{
+	"private_key", "github_token", "aws_access_key", "slack_token", "openai_key", "jwt", "bearer_token", "secret_assignment",
+}
+
+// secretDetectors mask the keys of the Google APIs and the credentials in the query of URLs, e.g.
+// ?key=... of Gemini or ?sig=... of Azure, which the default detectors do not cover.
+var secretDetectors = []Detector{
+	{Name: "google_api_key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{Name: "url_key", Pattern: regexp.MustCompile(`(?i)\b(?:key|access_token|sig)=[^&\s"']{8,}`)},
+}
+
+// minSecretValue is the minimum length of a registered secret value, so a placeholder such as a
+// token "t" in a test does not mask every "t" of the logs.
+const minSecretValue = 8
+
+var (
+	secretsMu sync.RWMutex
+	secrets   = newSecretsRedactor(nil)
+)
+
+// RegisterSecrets adds values masked by Secrets wherever they appear, e.g. the tokens and API keys
+// read from the environment, whatever their format. Values shorter than 8 characters are ignored.
+func RegisterSecrets(values ...string) {
+	secretsMu.Lock()
+	defer secretsMu.Unlock()
+	known := slices.Clone(secrets.values)
+	for _, v := range values {
+		if v = strings.TrimSpace(v); len(v) >= minSecretValue && !slices.Contains(known, v) {
+			known = append(known, v)
+		}
+	}
+	secrets = newSecretsRedactor(known)
+}
+
+// Secrets masks the tokens, keys and passwords of a text, such as an error message or a response
+// body, with a [REDACTED:<name>] placeholder, along with the values of RegisterSecrets.
+func Secrets(text string) string {
+	secretsMu.RLock()
+	r := secrets
+	secretsMu.RUnlock()
+	text, _ = r.Redact(text)
+	return text
+}
+
+// secretsRedactor is a Redactor of the secrets, with the registered values.
+type secretsRedactor struct {
+	*Redactor
+	values []string
+}
+
+func newSecretsRedactor(values []string) *secretsRedactor {
+	var detectors []Detector
+	if len(values) > 0 {
+		// Longest first, so a value containing another one is masked whole
+		sorted := slices.Clone(values)
+		sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+		quoted := make([]string, len(sorted))
+		for i, v := range sorted {
+			quoted[i] = regexp.QuoteMeta(v)
+		}
+		detectors = append(detectors, Detector{Name: "secret", Pattern: regexp.MustCompile(strings.Join(quoted, "|"))})
+	}
+	for _, d := range DefaultDetectors {
+		if slices.Contains(secretDetectorNames, d.Name) {
+			detectors = append(detectors, d)
+		}
+	}
+	detectors = append(detectors, secretDetectors...)
+	return &secretsRedactor{Redactor: &Redactor{detectors: detectors}, values: values}
+}
+
+// writer masks the secrets of what is written to an underlying writer.
+type writer struct {
+	w io.Writer
+}
+
+// NewWriter returns a writer masking with Secrets what is written to w, for the logs and the errors
+// printed on the terminal. Each write is masked on its own, as the loggers write a line at a time.
+func NewWriter(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+// Write writes p masked, returning len(p) on success as the masked text can be shorter or longer.
+func (w *writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(w.w, Secrets(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
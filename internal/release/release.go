@@ -0,0 +1,122 @@
+// Package release groups the issues completed in a release into the sections of its notes.
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// maxBodyLength bounds the part of each issue body kept as context for the notes.
+const maxBodyLength = 600
+
+// OtherSection is the section of the issues without any of the section labels.
+const OtherSection = "Other"
+
+// DefaultSections are the labels the notes are grouped by when none are given, in order.
+var DefaultSections = []string{"Epic", "User Story", "Feature", "Enhancement", "Bug", "Documentation"}
+
+var (
+	// titlePrefix matches the decoration aigile adds before the titles, e.g. "[📖 User Story] ".
+	titlePrefix = regexp.MustCompile(`^\[[^\]]*\]\s*`)
+	// hiddenComment matches the HTML comments of the bodies, such as the aigile markers.
+	hiddenComment = regexp.MustCompile(`(?s)<!--.*?-->`)
+)
+
+// Issue is a completed issue of the release.
+type Issue struct {
+	Number int
+	Title  string
+	Body   string
+	Labels []string
+}
+
+// Section is a group of issues sharing a label.
+type Section struct {
+	Name   string
+	Issues []Issue
+}
+
+// Notes are the issues of a release grouped in sections.
+type Notes struct {
+	Version  string
+	Sections []Section
+}
+
+// Group builds the notes of a release. Each issue goes to the section of the first of the section
+// labels it has, or to OtherSection; issues with any of the excluded labels are left out. Labels
+// are compared ignoring case, and empty sections are dropped.
+func Group(version string, issues []Issue, sections, exclude []string) Notes {
+	grouped := make([][]Issue, len(sections)+1)
+	for _, issue := range issues {
+		if hasAny(issue.Labels, exclude) {
+			continue
+		}
+		issue.Title = titlePrefix.ReplaceAllString(strings.TrimSpace(issue.Title), "")
+		issue.Body = summarize(issue.Body)
+		i := len(sections)
+		for j, name := range sections {
+			if hasAny(issue.Labels, []string{name}) {
+				i = j
+				break
+			}
+		}
+		grouped[i] = append(grouped[i], issue)
+	}
+
+	notes := Notes{Version: version}
+	for i, issues := range grouped {
+		if len(issues) == 0 {
+			continue
+		}
+		name := OtherSection
+		if i < len(sections) {
+			name = sections[i]
+		}
+		notes.Sections = append(notes.Sections, Section{Name: name, Issues: issues})
+	}
+	return notes
+}
+
+// Count returns the number of issues of the notes.
+func (n Notes) Count() int {
+	count := 0
+	for _, s := range n.Sections {
+		count += len(s.Issues)
+	}
+	return count
+}
+
+// Markdown renders the notes as a plain list per section, the draft the LLM polishes.
+func Markdown(notes Notes) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Release %s\n", notes.Version)
+	for _, s := range notes.Sections {
+		fmt.Fprintf(&sb, "\n## %s\n\n", s.Name)
+		for _, issue := range s.Issues {
+			fmt.Fprintf(&sb, "- %s (#%d)\n", issue.Title, issue.Number)
+		}
+	}
+	return sb.String()
+}
+
+// summarize removes the hidden comments of a body and cuts it to maxBodyLength characters.
+func summarize(body string) string {
+	body = strings.TrimSpace(hiddenComment.ReplaceAllString(body, ""))
+	if runes := []rune(body); len(runes) > maxBodyLength {
+		body = strings.TrimSpace(string(runes[:maxBodyLength])) + "…"
+	}
+	return body
+}
+
+// hasAny reports whether labels has any of names, ignoring case.
+func hasAny(labels, names []string) bool {
+	for _, l := range labels {
+		for _, n := range names {
+			if strings.EqualFold(l, n) {
+				return true
+			}
+		}
+	}
+	return false
+}
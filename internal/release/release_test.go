@@ -0,0 +1,35 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroup(t *testing.T) {
+	issues := []Issue{
+		{Number: 3, Title: "[🐛 Bug] Totals are rounded twice", Labels: []string{"bug"}},
+		{Number: 1, Title: "[📖 User Story] As a buyer, I want to pay by card", Body: "Card payments.\n<!-- aigile:source row=2 -->", Labels: []string{"User Story", "checkout"}},
+		{Number: 2, Title: "[✅ Task] Integrate the gateway", Labels: []string{"Task"}},
+		{Number: 4, Title: "Bump dependencies", Labels: []string{"chore"}},
+		{Number: 5, Title: "[🧪 QA] As a buyer, I want to pay by card", Labels: []string{"QA", "User Story"}},
+	}
+
+	notes := Group("v1.2", issues, DefaultSections, []string{"task", "qa"})
+	assert.Equal(t, Notes{Version: "v1.2", Sections: []Section{
+		{Name: "User Story", Issues: []Issue{{Number: 1, Title: "As a buyer, I want to pay by card", Body: "Card payments.", Labels: []string{"User Story", "checkout"}}}},
+		{Name: "Bug", Issues: []Issue{{Number: 3, Title: "Totals are rounded twice", Labels: []string{"bug"}}}},
+		{Name: OtherSection, Issues: []Issue{{Number: 4, Title: "Bump dependencies", Labels: []string{"chore"}}}},
+	}}, notes)
+	assert.Equal(t, 3, notes.Count())
+
+	assert.Equal(t, "# Release v1.2\n\n## User Story\n\n- As a buyer, I want to pay by card (#1)\n\n## Bug\n\n- Totals are rounded twice (#3)\n\n## Other\n\n- Bump dependencies (#4)\n", Markdown(notes))
+}
+
+func TestGroup_LongBody(t *testing.T) {
+	notes := Group("v1", []Issue{{Number: 1, Title: "A", Body: strings.Repeat("a", maxBodyLength+10)}}, nil, nil)
+	body := notes.Sections[0].Issues[0].Body
+	assert.Equal(t, maxBodyLength+1, len([]rune(body)))
+	assert.True(t, strings.HasSuffix(body, "…"))
+}
@@ -0,0 +1,179 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/prompt"
+)
+
+// Run describes the run a report belongs to.
+type Run struct {
+	ID        string
+	Source    string
+	StartedAt time.Time
+}
+
+// Group is a set of results shown together: an epic and the rows below it, or the rows sharing
+// a parent.
+type Group struct {
+	Name    string
+	Epic    *Result
+	Results []Result
+}
+
+// GroupResults groups the results by epic: every row belongs to the closest epic above it. Rows
+// before the first epic are grouped by their Parent column.
+func GroupResults(results []Result) []Group {
+	var groups []Group
+	byParent := map[string]int{}
+	current := -1
+	for _, r := range results {
+		if r.Item.Type == prompt.Epic {
+			epic := r
+			name := r.Item.Context
+			if r.Content != nil && r.Content.Title != "" {
+				name = r.Content.Title
+			}
+			groups = append(groups, Group{Name: name, Epic: &epic})
+			current = len(groups) - 1
+			continue
+		}
+		if current >= 0 {
+			groups[current].Results = append(groups[current].Results, r)
+			continue
+		}
+		i, ok := byParent[r.Item.Parent]
+		if !ok {
+			name := r.Item.Parent
+			if name == "" {
+				name = "No parent"
+			}
+			groups = append(groups, Group{Name: name})
+			i = len(groups) - 1
+			byParent[r.Item.Parent] = i
+		}
+		groups[i].Results = append(groups[i].Results, r)
+	}
+	return groups
+}
+
+// htmlData is the data rendered by the HTML template.
+type htmlData struct {
+	Run         Run
+	GeneratedAt time.Time
+	Total       int
+	Created     int
+	Failed      int
+	Skipped     int
+	Warnings    int
+	Issues      int
+	Groups      []Group
+}
+
+// WriteHTML writes a standalone HTML report of the results, grouped by epic or parent.
+func WriteHTML(path string, run Run, results []Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+	if err := RenderHTML(f, run, results); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// RenderHTML renders the HTML report of the results to w.
+func RenderHTML(w io.Writer, run Run, results []Result) error {
+	data := htmlData{Run: run, GeneratedAt: time.Now(), Total: len(results), Groups: GroupResults(results)}
+	for _, r := range results {
+		switch r.Status {
+		case StatusFailed:
+			data.Failed++
+		case StatusSkipped:
+			data.Skipped++
+		default:
+			data.Created++
+		}
+		data.Issues += len(r.Issues)
+		data.Warnings += len(r.Item.Warnings)
+	}
+	if err := htmlTemplate.Execute(w, data); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// htmlTemplate renders the report as a single page with inline styles, so it can be shared as is.
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>aigile report{{with .Run.Source}} - {{.}}{{end}}</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Roboto, Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 60rem; padding: 0 1rem; color: #1f2328; }
+header p { color: #59636e; margin: .25rem 0; }
+.summary { display: flex; gap: 1rem; margin: 1.5rem 0; }
+.summary div { border: 1px solid #d1d9e0; border-radius: 6px; padding: .75rem 1rem; flex: 1; }
+.summary strong { display: block; font-size: 1.5rem; }
+section { margin-top: 2rem; }
+section > h2 { border-bottom: 1px solid #d1d9e0; padding-bottom: .25rem; }
+article { border: 1px solid #d1d9e0; border-radius: 6px; padding: .75rem 1rem; margin: .75rem 0; }
+article.failed { border-color: #cf222e; background: #fff5f5; }
+article.skipped { border-style: dashed; color: #59636e; }
+article h3 { margin: 0 0 .5rem; font-size: 1.05rem; }
+.meta { color: #59636e; font-size: .85rem; }
+.badge { display: inline-block; border-radius: 1rem; padding: 0 .5rem; font-size: .75rem; background: #ddf4ff; color: #0969da; margin-right: .25rem; }
+.badge.failed { background: #ffebe9; color: #cf222e; }
+.error { color: #cf222e; }
+.warnings { color: #9a6700; }
+</style>
+</head>
+<body>
+<header>
+<h1>aigile report</h1>
+{{with .Run.Source}}<p>Source: {{.}}</p>{{end}}
+{{with .Run.ID}}<p>Run: {{.}}</p>{{end}}
+{{if not .Run.StartedAt.IsZero}}<p>Started {{.Run.StartedAt.Format "2006-01-02 15:04 MST"}}</p>{{end}}
+<p>Generated {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</p>
+</header>
+<div class="summary">
+<div><strong>{{.Total}}</strong>rows</div>
+<div><strong>{{.Created}}</strong>created</div>
+<div><strong>{{.Failed}}</strong>failed</div>
+{{if .Skipped}}<div><strong>{{.Skipped}}</strong>skipped</div>{{end}}
+{{if .Warnings}}<div><strong>{{.Warnings}}</strong>warnings</div>{{end}}
+<div><strong>{{.Issues}}</strong>issues</div>
+</div>
+{{range .Groups}}
+<section>
+<h2>{{.Name}}</h2>
+{{with .Epic}}{{template "item" .}}{{end}}
+{{range .Results}}{{template "item" .}}{{end}}
+</section>
+{{end}}
+</body>
+</html>
+{{define "item"}}
+<article{{if eq .Status "failed" "skipped"}} class="{{.Status}}"{{end}}>
+<h3>{{with .Item.Type}}<span class="badge{{if eq $.Status "failed"}} failed{{end}}">{{.}}</span>{{end}}{{if .Content}}{{.Content.Title}}{{else if .Item.Context}}{{.Item.Context}}{{else}}Row {{.Item.ID}}{{end}}</h3>
+<p class="meta">{{with .Item.Source.String}}{{.}} · {{end}}{{.Status}}{{with .Content}}{{if .Estimate}} · {{.Estimate}} points{{end}}{{end}}</p>
+{{with .Content}}
+<p>{{.Description}}</p>
+{{with .AcceptanceCriteria}}<h4>Acceptance Criteria</h4><ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{with .SuggestedTasks}}<h4>Suggested Tasks</h4><ul>{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+{{end}}
+{{with .Issues}}<h4>Issues</h4><ul>{{range .}}<li>{{.Provider}} {{.Kind}}: {{if .URL}}<a href="{{.URL}}">{{if .Number}}#{{.Number}}{{else}}{{.URL}}{{end}}</a>{{else}}#{{.Number}}{{end}}</li>{{end}}</ul>{{end}}
+{{with .Error}}<p class="error">{{.}}</p>{{end}}
+{{with .Item.Warnings}}<ul class="warnings">{{range .}}<li>{{.}}</li>{{end}}</ul>{{end}}
+</article>
+{{end}}
+`))
@@ -0,0 +1,73 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupResults(t *testing.T) {
+	results := []Result{
+		{Item: reader.Item{ID: "2", Type: prompt.UserStory, Parent: "Payments"}},
+		{Item: reader.Item{ID: "3", Type: prompt.UserStory}},
+		{Item: reader.Item{ID: "4", Type: prompt.UserStory, Parent: "Payments"}},
+		{Item: reader.Item{ID: "5", Type: prompt.Epic, Context: "Checkout"}, Content: &llm.GeneratedContent{Title: "Checkout revamp"}},
+		{Item: reader.Item{ID: "6", Type: prompt.UserStory, Parent: "Payments"}},
+		{Item: reader.Item{ID: "7", Type: prompt.Epic, Context: "Reporting"}},
+	}
+
+	groups := GroupResults(results)
+	require.Len(t, groups, 4)
+	assert.Equal(t, "Payments", groups[0].Name)
+	assert.Len(t, groups[0].Results, 2)
+	assert.Equal(t, "No parent", groups[1].Name)
+	assert.Equal(t, "Checkout revamp", groups[2].Name)
+	assert.Equal(t, "5", groups[2].Epic.Item.ID)
+	assert.Equal(t, "6", groups[2].Results[0].Item.ID)
+	assert.Equal(t, "Reporting", groups[3].Name)
+	assert.Empty(t, groups[3].Results)
+}
+
+func TestWriteHTML(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "report.html")
+	results := []Result{
+		{
+			Item:    reader.Item{ID: "2", Type: prompt.Epic, Context: "Checkout"},
+			Content: &llm.GeneratedContent{Title: "Checkout <revamp>", Description: "Epic description"},
+			Issues:  []Issue{{Provider: "github", Kind: "epic", Number: 1, URL: "https://github.com/o/r/issues/1"}},
+			Status:  StatusCreated,
+		},
+		{
+			Item:   reader.Item{ID: "3", Type: prompt.UserStory, Context: "Pay by card", Warnings: []string{"no acceptance criteria"}, Source: reader.SourceRef{File: "b.xlsx", Sheet: "Sheet1", Row: 3}},
+			Status: StatusFailed,
+			Error:  "failed to generate content: timeout",
+		},
+		{
+			Item:   reader.Item{ID: "4", Source: reader.SourceRef{File: "b.xlsx", Sheet: "Sheet1", Row: 4}},
+			Status: StatusSkipped,
+			Error:  `invalid item type "Storyy" (Sheet1!A4)`,
+		},
+	}
+	require.NoError(t, WriteHTML(path, Run{ID: "run-1", Source: "b.xlsx", StartedAt: time.Now()}, results))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	html := string(data)
+	assert.Contains(t, html, "<h2>Checkout &lt;revamp&gt;</h2>")
+	assert.Contains(t, html, `<a href="https://github.com/o/r/issues/1">#1</a>`)
+	assert.Contains(t, html, `<article class="failed">`)
+	assert.Contains(t, html, "b.xlsx:Sheet1!3")
+	assert.Contains(t, html, "failed to generate content: timeout")
+	assert.Contains(t, html, "<strong>1</strong>failed")
+	assert.Contains(t, html, "<strong>1</strong>skipped")
+	assert.Contains(t, html, "<strong>1</strong>warnings")
+	assert.Contains(t, html, `<ul class="warnings"><li>no acceptance criteria</li></ul>`)
+	assert.Contains(t, html, `<article class="skipped">`+"\n<h3>Row 4</h3>")
+}
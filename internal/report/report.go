@@ -0,0 +1,32 @@
+// Package report writes the outcome of a generate run for stakeholders: the source rows, the
+// generated content and the issues created for them.
+package report
+
+import (
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/reader"
+)
+
+// Result statuses.
+const (
+	StatusCreated = "created"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped" // Invalid rows skipped in lenient mode
+)
+
+// Issue is an issue created for an item in a provider.
+type Issue struct {
+	Provider string
+	Kind     string
+	Number   int
+	URL      string
+}
+
+// Result is the outcome of processing a source row.
+type Result struct {
+	Item    reader.Item
+	Content *llm.GeneratedContent // nil when the generation failed
+	Issues  []Issue
+	Status  string
+	Error   string
+}
@@ -0,0 +1,82 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// xlsxSheet is the name of the results sheet.
+const xlsxSheet = "Results"
+
+// xlsxHeader lists the columns of the results sheet: the input columns followed by the outcome.
+var xlsxHeader = []string{
+	"Type", "Parent", "Context", "Criteria",
+	"Title", "Description", "Acceptance Criteria", "Suggested Tasks", "Estimate",
+	"Issues", "Status", "Error", "Warnings", "Source",
+}
+
+// WriteXLSX writes the results to an XLSX file, one row per source row in the input order.
+func WriteXLSX(path string, results []Result) error {
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+
+	if err := f.SetSheetName(f.GetSheetName(0), xlsxSheet); err != nil {
+		return fmt.Errorf("failed to create results sheet: %w", err)
+	}
+	if err := f.SetSheetRow(xlsxSheet, "A1", &xlsxHeader); err != nil {
+		return fmt.Errorf("failed to write results header: %w", err)
+	}
+	for i, r := range results {
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		row := xlsxRow(r)
+		if err := f.SetSheetRow(xlsxSheet, cell, &row); err != nil {
+			return fmt.Errorf("failed to write result of row %s: %w", r.Item.ID, err)
+		}
+	}
+
+	style, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err == nil {
+		_ = f.SetRowStyle(xlsxSheet, 1, 1, style)
+	}
+	if err := f.SetPanes(xlsxSheet, &excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+		return fmt.Errorf("failed to freeze results header: %w", err)
+	}
+
+	if err := f.SaveAs(path); err != nil {
+		return fmt.Errorf("failed to write results file: %w", err)
+	}
+	return nil
+}
+
+// xlsxRow returns the cells of a result row.
+func xlsxRow(r Result) []any {
+	var urls []string
+	for _, issue := range r.Issues {
+		if issue.URL != "" {
+			urls = append(urls, issue.URL)
+		} else {
+			urls = append(urls, fmt.Sprintf("%s #%d", issue.Provider, issue.Number))
+		}
+	}
+
+	row := []any{
+		r.Item.Type.String(), r.Item.Parent, r.Item.Context, strings.Join(r.Item.Criteria, "\n"),
+		"", "", "", "", "",
+		strings.Join(urls, "\n"), r.Status, r.Error, strings.Join(r.Item.Warnings, "\n"), r.Item.Source.String(),
+	}
+	if c := r.Content; c != nil {
+		row[4] = c.Title
+		row[5] = c.Description
+		row[6] = strings.Join(c.AcceptanceCriteria, "\n")
+		row[7] = strings.Join(c.SuggestedTasks, "\n")
+		if c.Estimate > 0 {
+			row[8] = c.Estimate
+		}
+	}
+	return row
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/llm"
+	"github.com/leocomelli/aigile/internal/prompt"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xuri/excelize/v2"
+)
+
+func TestWriteXLSX(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "results.xlsx")
+	results := []Result{
+		{
+			Item: reader.Item{
+				ID: "2", Type: prompt.UserStory, Parent: "Payments", Context: "Card payments", Criteria: []string{"charge", "refund"},
+				Source: reader.SourceRef{File: "backlog.xlsx", Sheet: "Sheet1", Row: 2},
+			},
+			Content: &llm.GeneratedContent{Title: "Pay by card", Description: "As a buyer...", AcceptanceCriteria: []string{"Given", "When"}, Estimate: 3},
+			Issues:  []Issue{{Provider: "github", Kind: "story", Number: 7, URL: "https://github.com/o/r/issues/7"}, {Provider: "console", Kind: "story"}},
+			Status:  StatusCreated,
+		},
+		{
+			Item:   reader.Item{ID: "4", Type: prompt.UserStory, Context: "Refunds", Warnings: []string{"no acceptance criteria"}},
+			Status: StatusCreated,
+		},
+		{
+			Item:   reader.Item{ID: "3", Type: prompt.Epic, Context: "Reporting"},
+			Status: StatusFailed,
+			Error:  "failed to generate content: timeout",
+		},
+	}
+	require.NoError(t, WriteXLSX(path, results))
+
+	f, err := excelize.OpenFile(path)
+	require.NoError(t, err)
+	defer f.Close()
+	rows, err := f.GetRows(xlsxSheet)
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, xlsxHeader, rows[0])
+	assert.Equal(t, []string{
+		"User Story", "Payments", "Card payments", "charge\nrefund",
+		"Pay by card", "As a buyer...", "Given\nWhen", "", "3",
+		"https://github.com/o/r/issues/7\nconsole #0", "created", "", "", "backlog.xlsx:Sheet1!2",
+	}, rows[1])
+	assert.Equal(t, "no acceptance criteria", rows[2][12])
+	assert.Equal(t, "Epic", rows[3][0])
+	assert.Equal(t, "failed", rows[3][10])
+	assert.Equal(t, "failed to generate content: timeout", rows[3][11])
+}
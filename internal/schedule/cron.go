@@ -0,0 +1,189 @@
+// Package schedule runs jobs on cron schedules, so aigile can take in a backlog periodically
+// without an external cron.
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSearch bounds the search of the next activation of a schedule, for expressions that never
+// match, such as February 30th.
+const maxSearch = 5 * 366 * 24 * time.Hour
+
+// descriptors are the predefined schedules accepted in place of the five fields.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field describes the range and the names of a cron field.
+type field struct {
+	name     string
+	min, max int
+	names    []string // names of the values from min, e.g. jan for month 1
+}
+
+var fields = []field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31},
+	{name: "month", min: 1, max: 12, names: []string{"jan", "feb", "mar", "apr", "may", "jun", "jul", "aug", "sep", "oct", "nov", "dec"}},
+	{name: "day of week", min: 0, max: 7, names: []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}},
+}
+
+// Schedule is a parsed cron expression.
+type Schedule struct {
+	expr                          string
+	minute, hour, dom, month, dow uint64 // bit sets of the allowed values
+	anyDOM, anyDOW                bool
+}
+
+// Parse parses a standard five-field cron expression (minute, hour, day of month, month and day
+// of week) with lists, ranges, steps and names, or one of the @hourly, @daily, @weekly, @monthly
+// and @yearly descriptors. Times are evaluated in the local time zone.
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[strings.ToLower(spec)]; ok {
+		spec = d
+	}
+	parts := strings.Fields(spec)
+	if len(parts) != len(fields) {
+		return nil, fmt.Errorf("invalid cron expression %q: expected 5 fields, got %d", expr, len(parts))
+	}
+
+	sets := make([]uint64, len(fields))
+	for i, part := range parts {
+		set, err := parseField(part, fields[i])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron expression %q: %w", expr, err)
+		}
+		sets[i] = set
+	}
+	// Sunday is both 0 and 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return &Schedule{
+		expr:   expr,
+		minute: sets[0], hour: sets[1], dom: sets[2], month: sets[3], dow: sets[4],
+		anyDOM: parts[2] == "*" || parts[2] == "?",
+		anyDOW: parts[4] == "*" || parts[4] == "?",
+	}, nil
+}
+
+// parseField parses a comma separated list of values, ranges and steps into a bit set.
+func parseField(part string, f field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(part, ",") {
+		rng, step := item, 1
+		if i := strings.Index(item, "/"); i >= 0 {
+			s, err := strconv.Atoi(item[i+1:])
+			if err != nil || s <= 0 {
+				return 0, fmt.Errorf("invalid step in %s field: %s", f.name, item)
+			}
+			rng, step = item[:i], s
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*" || rng == "?":
+			lo, hi = f.min, f.max
+		case strings.Contains(rng, "-"):
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if lo, err = f.value(bounds[0]); err != nil {
+				return 0, err
+			}
+			if hi, err = f.value(bounds[1]); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("invalid range in %s field: %s", f.name, rng)
+			}
+		default:
+			v, err := f.value(rng)
+			if err != nil {
+				return 0, err
+			}
+			lo, hi = v, v
+			if step > 1 {
+				hi = f.max
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << v
+		}
+	}
+	return set, nil
+}
+
+// value parses a number or a name of the field.
+func (f field) value(s string) (int, error) {
+	for i, name := range f.names {
+		if strings.EqualFold(s, name) {
+			return f.min + i, nil
+		}
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, fmt.Errorf("invalid value in %s field: %s (expected %d-%d)", f.name, s, f.min, f.max)
+	}
+	return v, nil
+}
+
+// String returns the expression the schedule was parsed from.
+func (s *Schedule) String() string {
+	return s.expr
+}
+
+// Next returns the first activation strictly after t, or the zero time when the schedule never
+// activates.
+func (s *Schedule) Next(t time.Time) time.Time {
+	t = t.Truncate(time.Minute).Add(time.Minute)
+	limit := t.Add(maxSearch)
+	for t.Before(limit) {
+		if s.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if s.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay reports whether the day of t matches. As in cron, when both the day of month and the
+// day of week are restricted, a day matching either of them matches.
+func (s *Schedule) matchDay(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDOM && s.anyDOW:
+		return true
+	case s.anyDOM:
+		return dow
+	case s.anyDOW:
+		return dom
+	default:
+		return dom || dow
+	}
+}
@@ -0,0 +1,76 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func date(s string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", s, time.UTC)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestSchedule_Next(t *testing.T) {
+	tests := []struct {
+		expr, from, expected string
+	}{
+		{"0 9 * * 1", "2025-06-04 10:00", "2025-06-09 09:00"}, // Wednesday to Monday
+		{"0 9 * * mon", "2025-06-09 08:59", "2025-06-09 09:00"},
+		{"0 9 * * 1", "2025-06-09 09:00", "2025-06-16 09:00"},
+		{"*/15 * * * *", "2025-06-04 10:07", "2025-06-04 10:15"},
+		{"30 8-18/2 * * *", "2025-06-04 18:31", "2025-06-05 08:30"},
+		{"0 0 1,15 * *", "2025-06-02 00:00", "2025-06-15 00:00"},
+		{"0 0 1 jan *", "2025-06-02 00:00", "2026-01-01 00:00"},
+		{"0 0 29 2 *", "2025-03-01 00:00", "2028-02-29 00:00"},
+		{"0 12 13 * 5", "2025-06-01 00:00", "2025-06-06 12:00"}, // day of month or Friday
+		{"0 0 * * 7", "2025-06-04 00:00", "2025-06-08 00:00"},   // 7 is Sunday
+		{"@daily", "2025-06-04 10:00", "2025-06-05 00:00"},
+		{"@weekly", "2025-06-04 10:00", "2025-06-08 00:00"},
+	}
+	for _, tt := range tests {
+		s, err := Parse(tt.expr)
+		require.NoError(t, err, tt.expr)
+		assert.Equal(t, date(tt.expected), s.Next(date(tt.from)), tt.expr)
+	}
+
+	s, err := Parse("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, s.Next(date("2025-01-01 00:00")).IsZero())
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"0 9 * *":     "expected 5 fields",
+		"60 9 * * 1":  "invalid value in minute field: 60",
+		"0 9 * * fun": "invalid value in day of week field: fun",
+		"0 9-5 * * *": "invalid range in hour field",
+		"*/0 * * * *": "invalid step in minute field",
+	}
+	for expr, expected := range tests {
+		_, err := Parse(expr)
+		assert.ErrorContains(t, err, expected, expr)
+	}
+}
+
+func TestRun(t *testing.T) {
+	s, err := Parse("@yearly")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runs := 0
+	err = Run(ctx, s, true, func(context.Context) error {
+		runs++
+		cancel()
+		return errors.New("failed")
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, runs)
+}
@@ -0,0 +1,47 @@
+package schedule
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Job is the work run at each activation of a schedule.
+type Job func(ctx context.Context) error
+
+// Run runs job at each activation of s until ctx is done. Activations are not run concurrently:
+// the next one is computed when a job finishes, so activations missed by a long job are skipped.
+// Job errors are logged and do not stop the schedule. With runNow the job also runs immediately.
+func Run(ctx context.Context, s *Schedule, runNow bool, job Job) error {
+	if runNow {
+		runJob(ctx, job)
+	}
+	for {
+		next := s.Next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("cron expression %q never activates", s)
+		}
+		slog.Info("waiting for the next scheduled run", "cron", s.String(), "next", next.Format(time.RFC3339))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+		runJob(ctx, job)
+	}
+}
+
+// runJob runs a scheduled job and logs its outcome.
+func runJob(ctx context.Context, job Job) {
+	started := time.Now()
+	slog.Info("starting scheduled run")
+	if err := job(ctx); err != nil {
+		slog.Error("scheduled run failed", "duration", time.Since(started).Round(time.Second), "error", err)
+		return
+	}
+	slog.Info("scheduled run finished", "duration", time.Since(started).Round(time.Second))
+}
@@ -0,0 +1,90 @@
+// Package similar finds the issues closest in meaning to a text by comparing their embeddings.
+package similar
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+// maxTextLength bounds the text embedded for an issue, to stay within the input limits of the
+// embedding models.
+const maxTextLength = 4000
+
+// Document is an embedded issue.
+type Document struct {
+	Number int
+	Title  string
+	URL    string
+	Vector []float32
+}
+
+// Match is a document similar to the searched vector, with the cosine similarity between them.
+type Match struct {
+	Document
+	Score float64
+}
+
+// Index holds embedded documents to search by similarity.
+type Index struct {
+	docs []Document
+}
+
+// Add adds a document to the index.
+func (ix *Index) Add(doc Document) {
+	ix.docs = append(ix.docs, doc)
+}
+
+// Len returns the number of documents of the index.
+func (ix *Index) Len() int {
+	return len(ix.docs)
+}
+
+// Merge adds the documents of another index.
+func (ix *Index) Merge(other *Index) {
+	ix.docs = append(ix.docs, other.docs...)
+}
+
+// Nearest returns up to k documents whose similarity to the vector is at least threshold, the most
+// similar first.
+func (ix *Index) Nearest(vector []float32, k int, threshold float64) []Match {
+	var matches []Match
+	for _, doc := range ix.docs {
+		if score := Cosine(vector, doc.Vector); score >= threshold {
+			matches = append(matches, Match{Document: doc, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// Cosine returns the cosine similarity of two vectors, from -1 to 1, or 0 when their sizes differ
+// or one of them is zero.
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// Text returns the text embedded for an issue: its title followed by its body, cut to a size the
+// embedding models accept.
+func Text(title, body string) string {
+	text := strings.TrimSpace(title + "\n\n" + strings.TrimSpace(body))
+	if runes := []rune(text); len(runes) > maxTextLength {
+		text = string(runes[:maxTextLength])
+	}
+	return text
+}
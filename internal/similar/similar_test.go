@@ -0,0 +1,44 @@
+package similar
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex_Nearest(t *testing.T) {
+	var ix Index
+	ix.Add(Document{Number: 1, Title: "Pay by card", Vector: []float32{1, 0, 0}})
+	ix.Add(Document{Number: 2, Title: "Refunds", Vector: []float32{0, 1, 0}})
+	ix.Add(Document{Number: 3, Title: "Pay by wallet", Vector: []float32{0.8, 0.6, 0}})
+	assert.Equal(t, 3, ix.Len())
+
+	matches := ix.Nearest([]float32{1, 0.1, 0}, 5, 0.7)
+	if assert.Len(t, matches, 2) {
+		assert.Equal(t, 1, matches[0].Number)
+		assert.Equal(t, 3, matches[1].Number)
+		assert.Greater(t, matches[0].Score, matches[1].Score)
+	}
+	assert.Len(t, ix.Nearest([]float32{1, 0.1, 0}, 1, 0), 1)
+	assert.Empty(t, ix.Nearest([]float32{0, 0, 1}, 5, 0.5))
+
+	var other Index
+	other.Add(Document{Number: 4, Title: "Shipping", Vector: []float32{0, 0, 1}})
+	ix.Merge(&other)
+	assert.Equal(t, 4, ix.Len())
+	assert.Equal(t, 4, ix.Nearest([]float32{0, 0, 1}, 1, 0.5)[0].Number)
+}
+
+func TestCosine(t *testing.T) {
+	assert.InDelta(t, 1, Cosine([]float32{1, 2}, []float32{2, 4}), 1e-9)
+	assert.InDelta(t, 0, Cosine([]float32{1, 0}, []float32{0, 1}), 1e-9)
+	assert.Zero(t, Cosine([]float32{1}, []float32{1, 0}))
+	assert.Zero(t, Cosine([]float32{0, 0}, []float32{1, 0}))
+}
+
+func TestText(t *testing.T) {
+	assert.Equal(t, "Pay by card\n\nBody", Text("Pay by card", " Body\n"))
+	assert.Equal(t, "Pay by card", Text("Pay by card", ""))
+	assert.Len(t, []rune(Text("T", strings.Repeat("é", maxTextLength))), maxTextLength)
+}
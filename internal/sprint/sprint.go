@@ -0,0 +1,102 @@
+// Package sprint plans a sprint from the items of a project board and the capacity of the team.
+package sprint
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Item is a candidate item of the sprint, in the order of the board.
+type Item struct {
+	Number int
+	Title  string
+	Status string
+	Points float64 // Estimate in points, 0 when not estimated
+}
+
+// Plan splits the items of the board between the sprint and the rest of the backlog.
+type Plan struct {
+	Name        string
+	Capacity    float64
+	Committed   []Item // Items that fit in the capacity
+	Overflow    []Item // Estimated items beyond the capacity
+	Unestimated []Item // Items that need an estimate before they can be planned
+}
+
+// New plans a sprint: the estimated items are committed in the order of the board until the next
+// one does not fit in the capacity, so the priority of the board is kept, and the others overflow.
+func New(name string, items []Item, capacity float64) Plan {
+	plan := Plan{Name: name, Capacity: capacity}
+	full := false
+	for _, item := range items {
+		switch {
+		case item.Points <= 0:
+			plan.Unestimated = append(plan.Unestimated, item)
+		case !full && plan.Points()+item.Points <= capacity:
+			plan.Committed = append(plan.Committed, item)
+		default:
+			full = true
+			plan.Overflow = append(plan.Overflow, item)
+		}
+	}
+	return plan
+}
+
+// Points returns the points committed to the sprint.
+func (p Plan) Points() float64 {
+	return sum(p.Committed)
+}
+
+// TotalPoints returns the points of all the estimated items of the board.
+func (p Plan) TotalPoints() float64 {
+	return sum(p.Committed) + sum(p.Overflow)
+}
+
+// Load returns the committed points as a percentage of the capacity.
+func (p Plan) Load() float64 {
+	if p.Capacity <= 0 {
+		return 0
+	}
+	return p.Points() / p.Capacity * 100
+}
+
+// Markdown renders the plan with the sprint goal and summary written for it.
+func Markdown(plan Plan, goal, summary string) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "## Sprint goal\n\n%s\n\n%s\n\n", strings.TrimSpace(goal), strings.TrimSpace(summary))
+	fmt.Fprintf(&sb, "**Capacity:** %s points · **Committed:** %s points (%.0f%%) · **Estimated backlog:** %s points\n",
+		Points(plan.Capacity), Points(plan.Points()), plan.Load(), Points(plan.TotalPoints()))
+	writeItems(&sb, fmt.Sprintf("Committed (%d)", len(plan.Committed)), "- [ ] ", plan.Committed)
+	writeItems(&sb, fmt.Sprintf("Not in this sprint (%d)", len(plan.Overflow)), "- ", plan.Overflow)
+	writeItems(&sb, fmt.Sprintf("Needs an estimate (%d)", len(plan.Unestimated)), "- ", plan.Unestimated)
+	return sb.String()
+}
+
+// Points formats a number of points without trailing zeros, e.g. 3 or 0.5.
+func Points(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// writeItems writes a section listing the items, skipped when there are none.
+func writeItems(sb *strings.Builder, heading, bullet string, items []Item) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(sb, "\n## %s\n\n", heading)
+	for _, item := range items {
+		fmt.Fprintf(sb, "%s#%d %s", bullet, item.Number, item.Title)
+		if item.Points > 0 {
+			fmt.Fprintf(sb, " (%s points)", Points(item.Points))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func sum(items []Item) float64 {
+	total := 0.0
+	for _, item := range items {
+		total += item.Points
+	}
+	return total
+}
@@ -0,0 +1,34 @@
+package sprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	items := []Item{
+		{Number: 1, Title: "Pay by card", Points: 5},
+		{Number: 2, Title: "Refunds", Points: 8},
+		{Number: 3, Title: "Invoices"},
+		{Number: 4, Title: "Reports", Points: 13},
+		{Number: 5, Title: "Export", Points: 1},
+	}
+
+	plan := New("Sprint 7", items, 20)
+	assert.Equal(t, []Item{items[0], items[1]}, plan.Committed)
+	assert.Equal(t, []Item{items[3], items[4]}, plan.Overflow, "items after the first one that does not fit keep their priority")
+	assert.Equal(t, []Item{items[2]}, plan.Unestimated)
+	assert.Equal(t, 13.0, plan.Points())
+	assert.Equal(t, 27.0, plan.TotalPoints())
+	assert.Equal(t, 65.0, plan.Load())
+}
+
+func TestMarkdown(t *testing.T) {
+	plan := New("Sprint 7", []Item{{Number: 1, Title: "Pay by card", Points: 2.5}, {Number: 2, Title: "Refunds"}}, 10)
+	expected := "## Sprint goal\n\nCard payments\n\nWe focus on checkout.\n\n" +
+		"**Capacity:** 10 points · **Committed:** 2.5 points (25%) · **Estimated backlog:** 2.5 points\n" +
+		"\n## Committed (1)\n\n- [ ] #1 Pay by card (2.5 points)\n" +
+		"\n## Needs an estimate (1)\n\n- #2 Refunds\n"
+	assert.Equal(t, expected, Markdown(plan, "Card payments\n", "We focus on checkout."))
+}
@@ -0,0 +1,379 @@
+// Package store persists the local state of aigile runs: the runs themselves, the items processed,
+// the token usage, and the mapping between source rows and the issues created for them.
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // registers the pure Go "sqlite" driver
+)
+
+// Kinds of artifacts recorded in the store.
+const (
+	KindEpic  = "epic"
+	KindStory = "story"
+	KindTask  = "task"
+	KindQA    = "qa"
+	KindTests = "tests" // Pull request of the test skeletons of a story
+	KindDraft = "draft" // Draft item of a project board, without number
+	KindSlice = "slice" // Story created from a vertical slice of an oversized story
+)
+
+// Run and item statuses recorded in the store.
+const (
+	StatusRunning    = "running"
+	StatusCompleted  = "completed"
+	StatusFailed     = "failed"
+	StatusRolledBack = "rolled_back" // Failed run whose issues were removed, see Remove
+	StatusCreated    = "created"
+	StatusDone       = "done" // Item whose issue was closed as completed, see MarkDone
+)
+
+// schema creates the state tables. Times are stored as unix milliseconds.
+const schema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          TEXT PRIMARY KEY,
+	source      TEXT NOT NULL,
+	status      TEXT NOT NULL,
+	started_at  INTEGER NOT NULL,
+	finished_at INTEGER NOT NULL DEFAULT 0,
+	version     TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS items (
+	run_id            TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	row               TEXT NOT NULL,
+	type              TEXT NOT NULL,
+	parent            TEXT NOT NULL,
+	status            TEXT NOT NULL,
+	error             TEXT NOT NULL DEFAULT '',
+	prompt_tokens     INTEGER NOT NULL DEFAULT 0,
+	completion_tokens INTEGER NOT NULL DEFAULT 0,
+	created_at        INTEGER NOT NULL,
+	source_ref        TEXT NOT NULL DEFAULT '',
+	row_hash          TEXT NOT NULL DEFAULT ''
+);
+CREATE TABLE IF NOT EXISTS issues (
+	run_id     TEXT NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+	source     TEXT NOT NULL,
+	row        TEXT NOT NULL,
+	provider   TEXT NOT NULL,
+	kind       TEXT NOT NULL,
+	number     INTEGER NOT NULL DEFAULT 0,
+	issue_id   INTEGER NOT NULL DEFAULT 0,
+	url        TEXT NOT NULL DEFAULT '',
+	title      TEXT NOT NULL DEFAULT '',
+	created_at INTEGER NOT NULL,
+	parent_number INTEGER NOT NULL DEFAULT 0,
+	provider_id   TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX IF NOT EXISTS issues_source_row ON issues(source, row);
+CREATE INDEX IF NOT EXISTS issues_run ON issues(run_id);
+CREATE INDEX IF NOT EXISTS items_run ON items(run_id);
+`
+
+// columns lists the columns added after the first schema, created in older databases on open.
+var columns = []struct{ table, name, definition string }{
+	{"items", "source_ref", "TEXT NOT NULL DEFAULT ''"},
+	{"items", "row_hash", "TEXT NOT NULL DEFAULT ''"},
+	{"issues", "parent_number", "INTEGER NOT NULL DEFAULT 0"},
+	{"issues", "provider_id", "TEXT NOT NULL DEFAULT ''"},
+	{"runs", "version", "TEXT NOT NULL DEFAULT ''"},
+}
+
+// Run is a single execution of the generate command.
+type Run struct {
+	ID         string    `json:"id"`
+	Source     string    `json:"source"`
+	Status     string    `json:"status"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Version    string    `json:"version,omitempty"` // Build of aigile that ran it, e.g. v1.2.3 (1a2b3c4)
+}
+
+// ItemRecord is the outcome of processing a source row in a run.
+type ItemRecord struct {
+	RunID            string    `json:"run_id"`
+	Row              string    `json:"row"`
+	Type             string    `json:"type"`
+	Parent           string    `json:"parent"`
+	Status           string    `json:"status"`
+	Error            string    `json:"error,omitempty"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	SourceRef        string    `json:"source_ref,omitempty"` // File, sheet and row, e.g. backlog.xlsx:Sheet1!12
+	RowHash          string    `json:"row_hash,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// Record maps a source row to an artifact created for it in a provider.
+type Record struct {
+	RunID     string    `json:"run_id"`
+	Source    string    `json:"source"`
+	Row       string    `json:"row"`
+	Provider  string    `json:"provider"`
+	Kind      string    `json:"kind"`
+	Number    int       `json:"number,omitempty"`
+	ID        int64     `json:"id,omitempty"`
+	URL       string    `json:"url,omitempty"`
+	Title     string    `json:"title,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+
+	ParentNumber int    `json:"parent_number,omitempty"` // Issue the artifact belongs to: the story of a task, the epic of a story
+	ProviderID   string `json:"provider_id,omitempty"`   // Identifier in the provider API, e.g. the node ID of a GitHub issue or draft item
+}
+
+// Store is the SQLite state database.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (or creates) the state database at path.
+func Open(path string) (*Store, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return nil, fmt.Errorf("failed to create state directory: %w", err)
+		}
+	}
+	db, err := sql.Open("sqlite", path+"?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open state database: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize state database: %w", err)
+	}
+	if err := migrate(db); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate state database: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// migrate adds the columns missing in databases created by older versions.
+func migrate(db *sql.DB) error {
+	for _, c := range columns {
+		var count int
+		err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, c.table, c.name).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			continue
+		}
+		if _, err := db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", c.table, c.name, c.definition)); err != nil { // #nosec G201 -- constant identifiers
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// StartRun records the beginning of a run.
+func (s *Store) StartRun(ctx context.Context, run Run) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO runs (id, source, status, started_at, version) VALUES (?, ?, ?, ?, ?)`,
+		run.ID, run.Source, StatusRunning, run.StartedAt.UnixMilli(), run.Version)
+	if err != nil {
+		return fmt.Errorf("failed to record run: %w", err)
+	}
+	return nil
+}
+
+// FinishRun records the final status of a run.
+func (s *Store) FinishRun(ctx context.Context, runID, status string) error {
+	_, err := s.db.ExecContext(ctx, `UPDATE runs SET status = ?, finished_at = ? WHERE id = ?`,
+		status, time.Now().UnixMilli(), runID)
+	if err != nil {
+		return fmt.Errorf("failed to finish run: %w", err)
+	}
+	return nil
+}
+
+// AddItem records the outcome of processing a source row.
+func (s *Store) AddItem(ctx context.Context, item ItemRecord) error {
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = time.Now()
+	}
+	_, err := s.db.ExecContext(ctx, `INSERT INTO items (run_id, row, type, parent, status, error, prompt_tokens, completion_tokens, source_ref, row_hash, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		item.RunID, item.Row, item.Type, item.Parent, item.Status, item.Error, item.PromptTokens, item.CompletionTokens, item.SourceRef, item.RowHash, item.CreatedAt.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("failed to record item: %w", err)
+	}
+	return nil
+}
+
+// Add records the issues created for source rows.
+func (s *Store) Add(ctx context.Context, records ...Record) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to record issues: %w", err)
+	}
+	for _, r := range records {
+		if r.CreatedAt.IsZero() {
+			r.CreatedAt = time.Now()
+		}
+		_, err := tx.ExecContext(ctx, `INSERT INTO issues (run_id, source, row, provider, kind, number, issue_id, url, title, created_at, parent_number, provider_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			r.RunID, r.Source, r.Row, r.Provider, r.Kind, r.Number, r.ID, r.URL, r.Title, r.CreatedAt.UnixMilli(), r.ParentNumber, r.ProviderID)
+		if err != nil {
+			_ = tx.Rollback()
+			return fmt.Errorf("failed to record issue: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to record issues: %w", err)
+	}
+	return nil
+}
+
+// Remove forgets issues recorded by a run, e.g. the ones deleted when the run is rolled back, by
+// provider and number.
+func (s *Store) Remove(ctx context.Context, records ...Record) error {
+	for _, r := range records {
+		_, err := s.db.ExecContext(ctx, `DELETE FROM issues WHERE run_id = ? AND provider = ? AND number = ?`, r.RunID, r.Provider, r.Number)
+		if err != nil {
+			return fmt.Errorf("failed to remove issue: %w", err)
+		}
+	}
+	return nil
+}
+
+// Find returns the issues created for a source row, optionally filtered by provider (empty matches all).
+func (s *Store) Find(ctx context.Context, source, row, provider string) ([]Record, error) {
+	return s.queryIssues(ctx, `WHERE source = ? AND row = ? AND (? = '' OR provider = ?)`, source, row, provider, provider)
+}
+
+// FindByURL returns the records of the issue with the given URL in a provider, one per run that
+// created it. Unlike the number, the URL tells apart the issues of the repositories sharing the
+// store.
+func (s *Store) FindByURL(ctx context.Context, provider, url string) ([]Record, error) {
+	return s.queryIssues(ctx, `WHERE provider = ? AND url = ?`, provider, url)
+}
+
+// FindChildren returns the issues created as children of the issue with the given URL in a
+// provider, e.g. the tasks of a story, by the runs that created it. As with FindByURL, the children
+// of the issues of other repositories with the same number are left out.
+func (s *Store) FindChildren(ctx context.Context, provider, parentURL string) ([]Record, error) {
+	return s.queryIssues(ctx, `WHERE provider = ? AND (run_id, parent_number) IN (SELECT run_id, number FROM issues WHERE provider = ? AND url = ?)`,
+		provider, provider, parentURL)
+}
+
+// MarkDone marks the item of a source row processed by a run as done, once the issue created for it
+// is completed. It returns whether an item was marked, false when the row has no item or it is
+// already done.
+func (s *Store) MarkDone(ctx context.Context, runID, row string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, `UPDATE items SET status = ? WHERE run_id = ? AND row = ? AND status = ?`,
+		StatusDone, runID, row, StatusCreated)
+	if err != nil {
+		return false, fmt.Errorf("failed to mark item as done: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark item as done: %w", err)
+	}
+	return n > 0, nil
+}
+
+// Issues returns the issues created by a run, or by every run when runID is empty.
+func (s *Store) Issues(ctx context.Context, runID string) ([]Record, error) {
+	return s.queryIssues(ctx, `WHERE ? = '' OR run_id = ?`, runID, runID)
+}
+
+func (s *Store) queryIssues(ctx context.Context, where string, args ...any) ([]Record, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT run_id, source, row, provider, kind, number, issue_id, url, title, created_at, parent_number, provider_id
+		FROM issues `+where+` ORDER BY created_at, rowid`, args...) // #nosec G202 -- where clauses are constants
+	if err != nil {
+		return nil, fmt.Errorf("failed to query issues: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []Record
+	for rows.Next() {
+		var r Record
+		var created int64
+		if err := rows.Scan(&r.RunID, &r.Source, &r.Row, &r.Provider, &r.Kind, &r.Number, &r.ID, &r.URL, &r.Title, &created, &r.ParentNumber, &r.ProviderID); err != nil {
+			return nil, fmt.Errorf("failed to read issue: %w", err)
+		}
+		r.CreatedAt = time.UnixMilli(created)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// Items returns the items processed by a run, or by every run when runID is empty.
+func (s *Store) Items(ctx context.Context, runID string) ([]ItemRecord, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT run_id, row, type, parent, status, error, prompt_tokens, completion_tokens, source_ref, row_hash, created_at
+		FROM items WHERE ? = '' OR run_id = ? ORDER BY created_at, rowid`, runID, runID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query items: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []ItemRecord
+	for rows.Next() {
+		var r ItemRecord
+		var created int64
+		if err := rows.Scan(&r.RunID, &r.Row, &r.Type, &r.Parent, &r.Status, &r.Error, &r.PromptTokens, &r.CompletionTokens, &r.SourceRef, &r.RowHash, &created); err != nil {
+			return nil, fmt.Errorf("failed to read item: %w", err)
+		}
+		r.CreatedAt = time.UnixMilli(created)
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// Runs returns all the runs, most recent first.
+func (s *Store) Runs(ctx context.Context) ([]Run, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT id, source, status, started_at, finished_at, version FROM runs ORDER BY started_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query runs: %w", err)
+	}
+	defer func() { _ = rows.Close() }()
+
+	var result []Run
+	for rows.Next() {
+		var r Run
+		var started, finished int64
+		if err := rows.Scan(&r.ID, &r.Source, &r.Status, &started, &finished, &r.Version); err != nil {
+			return nil, fmt.Errorf("failed to read run: %w", err)
+		}
+		r.StartedAt = time.UnixMilli(started)
+		if finished > 0 {
+			r.FinishedAt = time.UnixMilli(finished)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// Prune deletes the runs started before the given time, with their items and issues, and
+// returns the number of runs removed.
+func (s *Store) Prune(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM runs WHERE started_at < ?`, before.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune runs: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// NewRunID returns a unique, time-ordered identifier for a run.
+func NewRunID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
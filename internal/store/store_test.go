@@ -0,0 +1,209 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openTestStore opens a state database in a temporary directory.
+func openTestStore(t *testing.T) (*Store, string) {
+	path := filepath.Join(t.TempDir(), "state", "state.db")
+	s, err := Open(path)
+	require.NoError(t, err)
+	return s, path
+}
+
+// TestStore_IssuesAcrossRuns tests that issue mappings persist across runs and can be looked up.
+func TestStore_IssuesAcrossRuns(t *testing.T) {
+	ctx := context.Background()
+	s, path := openTestStore(t)
+
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r1", Source: "backlog.xlsx"}))
+	require.NoError(t, s.Add(ctx,
+		Record{RunID: "r1", Source: "backlog.xlsx", Row: "2", Provider: "github", Kind: KindStory, Number: 10},
+		Record{RunID: "r1", Source: "backlog.xlsx", Row: "2", Provider: "console", Kind: KindStory},
+	))
+	require.NoError(t, s.Close())
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r2", Source: "backlog.xlsx"}))
+	require.NoError(t, s.Add(ctx, Record{RunID: "r2", Source: "backlog.xlsx", Row: "3", Provider: "github", Kind: KindStory, Number: 11}))
+
+	all, err := s.Issues(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	found, err := s.Find(ctx, "backlog.xlsx", "2", "")
+	require.NoError(t, err)
+	assert.Len(t, found, 2)
+
+	found, err = s.Find(ctx, "backlog.xlsx", "2", "github")
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 10, found[0].Number)
+	assert.False(t, found[0].CreatedAt.IsZero())
+
+	run2, err := s.Issues(ctx, "r2")
+	require.NoError(t, err)
+	assert.Len(t, run2, 1)
+}
+
+func TestStore_Remove(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openTestStore(t)
+	defer s.Close()
+
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r1", Source: "backlog.xlsx"}))
+	story := Record{RunID: "r1", Source: "backlog.xlsx", Row: "2", Provider: "github", Kind: KindStory, Number: 10, URL: "https://github.com/acme/app/issues/10"}
+	task := Record{RunID: "r1", Source: "backlog.xlsx", Row: "2", Provider: "github", Kind: KindTask, Number: 11, ParentNumber: 10}
+	require.NoError(t, s.Add(ctx, story, task))
+	// A story of another repository with the same number, in another run
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r2", Source: "other.xlsx"}))
+	require.NoError(t, s.Add(ctx,
+		Record{RunID: "r2", Source: "other.xlsx", Row: "2", Provider: "github", Kind: KindStory, Number: 10, URL: "https://github.com/acme/other/issues/10"},
+		Record{RunID: "r2", Source: "other.xlsx", Row: "2", Provider: "github", Kind: KindTask, Number: 12, ParentNumber: 10}))
+
+	children, err := s.FindChildren(ctx, "github", "https://github.com/acme/app/issues/10")
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, 11, children[0].Number)
+
+	require.NoError(t, s.Remove(ctx, task))
+	issues, err := s.Issues(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 10, issues[0].Number)
+}
+
+func TestStore_MarkDone(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openTestStore(t)
+	defer s.Close()
+
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r1", Source: "backlog.xlsx"}))
+	require.NoError(t, s.AddItem(ctx, ItemRecord{RunID: "r1", Row: "2", Type: "User Story", Status: StatusCreated}))
+	require.NoError(t, s.Add(ctx,
+		Record{RunID: "r1", Source: "backlog.xlsx", Row: "2", Provider: "github", Kind: KindStory, Number: 10, URL: "https://github.com/acme/app/issues/10"},
+		Record{RunID: "r1", Source: "backlog.xlsx", Row: "3", Provider: "github", Kind: KindStory, Number: 10, URL: "https://github.com/acme/other/issues/10"}))
+
+	records, err := s.FindByURL(ctx, "github", "https://github.com/acme/app/issues/10")
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "2", records[0].Row)
+	records, err = s.FindByURL(ctx, "gitea", "https://github.com/acme/app/issues/10")
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	marked, err := s.MarkDone(ctx, "r1", "2")
+	require.NoError(t, err)
+	assert.True(t, marked)
+	items, err := s.Items(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, StatusDone, items[0].Status)
+
+	// Already done
+	marked, err = s.MarkDone(ctx, "r1", "2")
+	require.NoError(t, err)
+	assert.False(t, marked)
+}
+
+// TestStore_RunsAndItems tests recording runs, items and token usage.
+func TestStore_RunsAndItems(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openTestStore(t)
+	defer s.Close()
+
+	require.NoError(t, s.StartRun(ctx, Run{ID: "r1", Source: "backlog.xlsx", Version: "v1.2.3 (1a2b3c4)"}))
+	require.NoError(t, s.AddItem(ctx, ItemRecord{RunID: "r1", Row: "2", Type: "User Story", Status: StatusCreated, PromptTokens: 100, CompletionTokens: 50}))
+	require.NoError(t, s.AddItem(ctx, ItemRecord{RunID: "r1", Row: "3", Type: "User Story", Status: StatusFailed, Error: "boom", SourceRef: "backlog.xlsx:Sheet1!3", RowHash: "abc"}))
+	require.NoError(t, s.FinishRun(ctx, "r1", StatusCompleted))
+
+	runs, err := s.Runs(ctx)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, StatusCompleted, runs[0].Status)
+	assert.False(t, runs[0].FinishedAt.IsZero())
+	assert.Equal(t, "v1.2.3 (1a2b3c4)", runs[0].Version)
+
+	items, err := s.Items(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	assert.Equal(t, 100, items[0].PromptTokens)
+	assert.Equal(t, "boom", items[1].Error)
+	assert.Equal(t, "backlog.xlsx:Sheet1!3", items[1].SourceRef)
+	assert.Equal(t, "abc", items[1].RowHash)
+}
+
+// TestStore_Migrate tests that databases created before a column was added are upgraded on open.
+func TestStore_Migrate(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "state.db")
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE items (run_id TEXT NOT NULL, row TEXT NOT NULL, type TEXT NOT NULL, parent TEXT NOT NULL,
+		status TEXT NOT NULL, error TEXT NOT NULL DEFAULT '', prompt_tokens INTEGER NOT NULL DEFAULT 0,
+		completion_tokens INTEGER NOT NULL DEFAULT 0, created_at INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	_, err = db.Exec(`CREATE TABLE issues (run_id TEXT NOT NULL, source TEXT NOT NULL, row TEXT NOT NULL, provider TEXT NOT NULL,
+		kind TEXT NOT NULL, number INTEGER NOT NULL DEFAULT 0, issue_id INTEGER NOT NULL DEFAULT 0, url TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '', created_at INTEGER NOT NULL)`)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+	require.NoError(t, s.AddItem(ctx, ItemRecord{RunID: "r1", Row: "2", Type: "Epic", Status: StatusCreated, SourceRef: "a.xlsx:Sheet1!2"}))
+	items, err := s.Items(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	assert.Equal(t, "a.xlsx:Sheet1!2", items[0].SourceRef)
+
+	require.NoError(t, s.Add(ctx, Record{RunID: "r1", Source: "a.xlsx", Row: "2", Provider: "github", Kind: KindTask, Number: 3, ParentNumber: 2, ProviderID: "I_3"}))
+	issues, err := s.Issues(ctx, "r1")
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 2, issues[0].ParentNumber)
+	assert.Equal(t, "I_3", issues[0].ProviderID)
+}
+
+// TestStore_Prune tests that old runs are removed together with their items and issues.
+func TestStore_Prune(t *testing.T) {
+	ctx := context.Background()
+	s, _ := openTestStore(t)
+	defer s.Close()
+
+	require.NoError(t, s.StartRun(ctx, Run{ID: "old", Source: "a.xlsx", StartedAt: time.Now().Add(-48 * time.Hour)}))
+	require.NoError(t, s.AddItem(ctx, ItemRecord{RunID: "old", Row: "2", Type: "User Story", Status: StatusCreated}))
+	require.NoError(t, s.Add(ctx, Record{RunID: "old", Source: "a.xlsx", Row: "2", Provider: "github", Kind: KindStory}))
+	require.NoError(t, s.StartRun(ctx, Run{ID: "new", Source: "a.xlsx"}))
+
+	removed, err := s.Prune(ctx, time.Now().Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), removed)
+
+	runs, err := s.Runs(ctx)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "new", runs[0].ID)
+
+	issues, err := s.Issues(ctx, "old")
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+	items, err := s.Items(ctx, "old")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+// TestNewRunID tests that run IDs are unique.
+func TestNewRunID(t *testing.T) {
+	assert.NotEqual(t, NewRunID(), NewRunID())
+}
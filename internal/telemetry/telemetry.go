@@ -0,0 +1,153 @@
+// Package telemetry records anonymous usage of aigile, opt-in: the command and the names of its
+// flags, the providers used, the size of the runs and the classes of their errors. It never records
+// the content of the backlog, the values of the flags, names, URLs nor credentials.
+package telemetry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Modes of the telemetry.
+const (
+	ModeOff   = "off"   // Nothing is recorded, the default
+	ModeLocal = "local" // Events are only written to the local file, to inspect them
+	ModeOn    = "on"    // Events are written to the local file and sent to the endpoint
+)
+
+// ValidMode reports whether the mode is supported.
+func ValidMode(mode string) bool {
+	switch mode {
+	case ModeOff, ModeLocal, ModeOn:
+		return true
+	}
+	return false
+}
+
+// Event is the usage of a command.
+type Event struct {
+	Time      time.Time      `json:"time"`
+	Command   string         `json:"command"`
+	Version   string         `json:"version"`
+	Platform  string         `json:"platform"`
+	Flags     []string       `json:"flags,omitempty"`     // Names of the flags set, never their values
+	Providers []string       `json:"providers,omitempty"` // Issue providers, e.g. github
+	LLM       string         `json:"llm,omitempty"`       // LLM provider, e.g. openai, without the model nor endpoint
+	Rows      string         `json:"rows,omitempty"`      // Size class of the rows processed, see SizeClass
+	Failed    int            `json:"failed,omitempty"`
+	Warnings  int            `json:"warnings,omitempty"`
+	Errors    map[string]int `json:"errors,omitempty"` // Number of errors by class, see Classify
+}
+
+// SizeClass returns the class of a number of rows, e.g. 11-50, so that the exact size of a backlog
+// is not recorded.
+func SizeClass(n int) string {
+	for _, class := range []struct {
+		max  int
+		name string
+	}{{0, "0"}, {10, "1-10"}, {50, "11-50"}, {200, "51-200"}, {1000, "201-1000"}} {
+		if n <= class.max {
+			return class.name
+		}
+	}
+	return ">1000"
+}
+
+// Classify returns the class of an error from its type: timeout, canceled, network or other. The
+// messages of the errors are never recorded, as they can hold names or content.
+func Classify(err error) string {
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.As(err, &netErr):
+		if netErr.Timeout() {
+			return "timeout"
+		}
+		return "network"
+	default:
+		return "other"
+	}
+}
+
+// Recorder records the events in a mode.
+type Recorder struct {
+	mode     string
+	file     string // Local file of the events, one JSON object per line
+	endpoint string // URL the events are posted to in ModeOn
+	client   *http.Client
+}
+
+// New returns a recorder of the events in the mode. ModeOn needs the endpoint the events are sent to.
+func New(mode, file, endpoint string, client *http.Client) (*Recorder, error) {
+	if !ValidMode(mode) {
+		return nil, fmt.Errorf("invalid telemetry mode: %s (expected %s, %s or %s)", mode, ModeOff, ModeLocal, ModeOn)
+	}
+	if mode == ModeOn && endpoint == "" {
+		return nil, fmt.Errorf("the telemetry endpoint is required to send the events")
+	}
+	return &Recorder{mode: mode, file: file, endpoint: endpoint, client: client}, nil
+}
+
+// Record writes the event to the local file and, in ModeOn, sends it to the endpoint. It records
+// nothing in ModeOff.
+func (r *Recorder) Record(ctx context.Context, e Event) error {
+	if r == nil || r.mode == ModeOff {
+		return nil
+	}
+	sort.Strings(e.Flags)
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to encode telemetry event: %w", err)
+	}
+	if err := r.write(data); err != nil {
+		return err
+	}
+	if r.mode != ModeOn {
+		return nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create telemetry request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send telemetry event: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("failed to send telemetry event (status: %d)", resp.StatusCode)
+	}
+	return nil
+}
+
+// write appends the event to the local file.
+func (r *Recorder) write(data []byte) error {
+	if dir := filepath.Dir(r.file); dir != "." {
+		if err := os.MkdirAll(dir, 0o750); err != nil {
+			return fmt.Errorf("failed to create telemetry directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(r.file, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open telemetry file: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("failed to write telemetry event: %w", err)
+	}
+	return f.Close()
+}
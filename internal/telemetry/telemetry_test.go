@@ -0,0 +1,73 @@
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeClass(t *testing.T) {
+	assert.Equal(t, "0", SizeClass(0))
+	assert.Equal(t, "1-10", SizeClass(10))
+	assert.Equal(t, "11-50", SizeClass(11))
+	assert.Equal(t, "201-1000", SizeClass(1000))
+	assert.Equal(t, ">1000", SizeClass(10000))
+}
+
+func TestClassify(t *testing.T) {
+	assert.Equal(t, "timeout", Classify(fmt.Errorf("failed to generate content: %w", context.DeadlineExceeded)))
+	assert.Equal(t, "canceled", Classify(context.Canceled))
+	assert.Equal(t, "network", Classify(&net.OpError{Op: "dial", Err: errors.New("connection refused")}))
+	assert.Equal(t, "other", Classify(errors.New("boom")))
+}
+
+func TestNew(t *testing.T) {
+	_, err := New("sometimes", "", "", nil)
+	assert.EqualError(t, err, "invalid telemetry mode: sometimes (expected off, local or on)")
+	_, err = New(ModeOn, "", "", nil)
+	assert.EqualError(t, err, "the telemetry endpoint is required to send the events")
+}
+
+func TestRecorder_Record(t *testing.T) {
+	var received []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+	dir := t.TempDir()
+	event := Event{Command: "aigile generate", Flags: []string{"provider", "file"}, Providers: []string{"github"}, Rows: "1-10"}
+
+	off, err := New(ModeOff, filepath.Join(dir, "off.jsonl"), "", nil)
+	require.NoError(t, err)
+	require.NoError(t, off.Record(context.Background(), event))
+	assert.NoFileExists(t, filepath.Join(dir, "off.jsonl"))
+
+	local, err := New(ModeLocal, filepath.Join(dir, "local", "telemetry.jsonl"), server.URL, server.Client())
+	require.NoError(t, err)
+	require.NoError(t, local.Record(context.Background(), event))
+	require.NoError(t, local.Record(context.Background(), event))
+	data, err := os.ReadFile(filepath.Join(dir, "local", "telemetry.jsonl"))
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 2)
+	assert.Nil(t, received, "local mode must not send events")
+
+	on, err := New(ModeOn, filepath.Join(dir, "on.jsonl"), server.URL, server.Client())
+	require.NoError(t, err)
+	require.NoError(t, on.Record(context.Background(), event))
+	var sent Event
+	require.NoError(t, json.Unmarshal(received, &sent))
+	assert.Equal(t, []string{"file", "provider"}, sent.Flags)
+	assert.Equal(t, "1-10", sent.Rows)
+}
@@ -0,0 +1,136 @@
+// Package testgen generates test skeletons, Gherkin feature files, from the acceptance criteria of
+// the created stories, to be proposed to the repository next to the issues.
+package testgen
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/leocomelli/aigile/internal/i18n"
+)
+
+// maxSlugLength bounds the part of the file names derived from the story title.
+const maxSlugLength = 50
+
+// Story is a created story whose acceptance criteria become scenarios.
+type Story struct {
+	Number   int
+	URL      string
+	Title    string
+	Criteria []string
+}
+
+// FileName returns the name of the feature file of a story, e.g. 12-pay-by-card.feature.
+func FileName(story Story) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(story.Title) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			sb.WriteRune(r)
+			dash = false
+		case !dash && sb.Len() > 0:
+			sb.WriteRune('-')
+			dash = true
+		}
+	}
+	slug := strings.TrimSuffix(sb.String(), "-")
+	if runes := []rune(slug); len(runes) > maxSlugLength {
+		slug = strings.TrimSuffix(string(runes[:maxSlugLength]), "-")
+	}
+	if slug == "" {
+		return fmt.Sprintf("%d.feature", story.Number)
+	}
+	return fmt.Sprintf("%d-%s.feature", story.Number, slug)
+}
+
+// Feature returns the feature file of a story, with a scenario per acceptance criterion. Criteria
+// written as Given / When / Then (in English or in the language of the keywords) are split into
+// steps; the others become the expected outcome of a scenario whose context and action are left
+// to describe.
+func Feature(story Story, language string) string {
+	keywords := i18n.GherkinFor(language)
+	var sb strings.Builder
+	if keywords.Code != i18n.EnglishGherkin.Code {
+		fmt.Fprintf(&sb, "# language: %s\n", keywords.Code)
+	}
+	fmt.Fprintf(&sb, "# Generated by aigile from the acceptance criteria of #%d", story.Number)
+	if story.URL != "" {
+		fmt.Fprintf(&sb, ": %s", story.URL)
+	}
+	fmt.Fprintf(&sb, "\n@story-%d\n%s: %s\n", story.Number, keywords.Feature, singleLine(story.Title))
+
+	for i, criterion := range story.Criteria {
+		steps := splitSteps(criterion, keywords)
+		if len(steps) == 0 {
+			fmt.Fprintf(&sb, "\n  %s: %s\n", keywords.Scenario, singleLine(criterion))
+			fmt.Fprintf(&sb, "    # TODO: describe the context (%s) and the action (%s)\n", keywords.Given, keywords.When)
+			fmt.Fprintf(&sb, "    %s %s\n", keywords.Then, singleLine(criterion))
+			continue
+		}
+		fmt.Fprintf(&sb, "\n  %s: %s\n", keywords.Scenario, scenarioName(steps, keywords, i+1))
+		for _, step := range steps {
+			fmt.Fprintf(&sb, "    %s\n", step)
+		}
+	}
+	return sb.String()
+}
+
+// scenarioName names the scenario of a Given / When / Then criterion after its expected outcome,
+// the first Then step, or after its number when it has none.
+func scenarioName(steps []string, keywords i18n.Gherkin, number int) string {
+	for _, step := range steps {
+		if outcome, ok := strings.CutPrefix(step, keywords.Then+" "); ok {
+			return outcome
+		}
+	}
+	return fmt.Sprintf("#%d", number)
+}
+
+// splitSteps splits a criterion on its Gherkin keywords, returning the steps with the keywords of
+// the feature. It returns nil when the criterion does not start with a Given, When or Then keyword.
+func splitSteps(criterion string, keywords i18n.Gherkin) []string {
+	criterion = singleLine(strings.TrimLeft(criterion, "-*0123456789. "))
+	for _, set := range []i18n.Gherkin{keywords, i18n.EnglishGherkin} {
+		pattern := stepPattern(set)
+		loc := pattern.FindAllStringSubmatchIndex(criterion, -1)
+		if len(loc) == 0 || loc[0][2] != 0 {
+			continue
+		}
+		translate := map[string]string{
+			set.Given: keywords.Given, set.When: keywords.When, set.Then: keywords.Then,
+			set.And: keywords.And, set.But: keywords.But,
+		}
+		var steps []string
+		for i, l := range loc {
+			end := len(criterion)
+			if i+1 < len(loc) {
+				end = loc[i+1][2]
+			}
+			text := strings.TrimRight(strings.TrimSpace(criterion[l[3]:end]), ",;")
+			if text == "" {
+				continue
+			}
+			steps = append(steps, translate[criterion[l[2]:l[3]]]+" "+text)
+		}
+		return steps
+	}
+	return nil
+}
+
+// stepPattern matches the keywords of a Gherkin keyword set that start a step: at the start of the
+// criterion or after a space, followed by a space.
+func stepPattern(set i18n.Gherkin) *regexp.Regexp {
+	names := []string{set.Given, set.When, set.Then, set.And, set.But}
+	for i, n := range names {
+		names[i] = regexp.QuoteMeta(n)
+	}
+	return regexp.MustCompile(`(?:^|\s)(` + strings.Join(names, "|") + `)\s`)
+}
+
+// singleLine joins the lines of a text with spaces.
+func singleLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
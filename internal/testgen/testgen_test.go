@@ -0,0 +1,57 @@
+package testgen
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileName(t *testing.T) {
+	assert.Equal(t, "12-as-a-buyer-i-want-to-pay-by-card.feature", FileName(Story{Number: 12, Title: "As a buyer, I want to pay by card"}))
+	assert.Equal(t, "3-pagar-com-cartão.feature", FileName(Story{Number: 3, Title: "Pagar com cartão!"}))
+	assert.Equal(t, "7.feature", FileName(Story{Number: 7, Title: "🚀"}))
+	assert.Equal(t, "1-word-word-word-word-word-word-word-word-word-word.feature", FileName(Story{Number: 1, Title: "word word word word word word word word word word word word"}))
+}
+
+func TestFeature(t *testing.T) {
+	story := Story{Number: 12, URL: "https://github.com/o/r/issues/12", Title: "Pay by card", Criteria: []string{
+		"Given a cart with items When the buyer pays by card, Then the order is confirmed And a receipt is sent",
+		"Cards are validated before the payment",
+	}}
+	assert.Equal(t, `# Generated by aigile from the acceptance criteria of #12: https://github.com/o/r/issues/12
+@story-12
+Feature: Pay by card
+
+  Scenario: the order is confirmed
+    Given a cart with items
+    When the buyer pays by card
+    Then the order is confirmed
+    And a receipt is sent
+
+  Scenario: Cards are validated before the payment
+    # TODO: describe the context (Given) and the action (When)
+    Then Cards are validated before the payment
+`, Feature(story, "english"))
+}
+
+func TestFeature_Language(t *testing.T) {
+	story := Story{Number: 3, Title: "Pagar com cartão", Criteria: []string{
+		"Dado um carrinho\nQuando o comprador paga Então o pedido é confirmado",
+		"Given a cart When paying Then it works",
+	}}
+	assert.Equal(t, `# language: pt
+# Generated by aigile from the acceptance criteria of #3
+@story-3
+Funcionalidade: Pagar com cartão
+
+  Cenário: o pedido é confirmado
+    Dado um carrinho
+    Quando o comprador paga
+    Então o pedido é confirmado
+
+  Cenário: it works
+    Dado a cart
+    Quando paying
+    Então it works
+`, Feature(story, "pt-BR"))
+}
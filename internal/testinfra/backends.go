@@ -0,0 +1,96 @@
+package testinfra
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/pkg/githubtest"
+)
+
+// Project sets up environments in the configured repository (or workspace) of a provider, each
+// with a temporary project. On cleanup, the issues are closed and the project deleted.
+type Project struct {
+	Provider provider.Provider
+}
+
+// Setup creates the project of the environment.
+func (b Project) Setup(ctx context.Context, name string) (*Env, error) {
+	env := &Env{Name: name, Provider: b.Provider, closeIssues: true}
+	if err := env.addProject(ctx); err != nil {
+		return nil, err
+	}
+	return env, nil
+}
+
+// GitHubRepository sets up environments in ephemeral private repositories of the owner of a GitHub
+// provider, each with a temporary project. On cleanup, the project and the repository, along with
+// its issues, are deleted. The token needs the delete_repo scope.
+type GitHubRepository struct {
+	Provider *provider.GitHubProvider
+}
+
+// Setup creates the repository and the project of the environment.
+func (b GitHubRepository) Setup(ctx context.Context, name string) (*Env, error) {
+	repo, err := b.Provider.CreateRepository(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	env := &Env{Name: name, Provider: repo}
+	env.OnCleanup(repo.DeleteRepository)
+	if err := env.addProject(ctx); err != nil {
+		if cerr := env.Cleanup(ctx); cerr != nil {
+			slog.Warn("failed to clean up environment", "environment", name, "error", cerr)
+		}
+		return nil, err
+	}
+	return env, nil
+}
+
+// Fake sets up environments on a fake GitHub server each, to run scenarios in parallel without
+// credentials. The server is closed on cleanup.
+type Fake struct{}
+
+// Setup starts the fake server of the environment and creates its project.
+func (Fake) Setup(ctx context.Context, name string) (*Env, error) {
+	server := githubtest.NewServer("aigile", "selftest")
+	p, err := provider.NewGitHubProvider(provider.GitHubConfig{Token: "token", Owner: "aigile", Repo: "selftest", BaseURL: server.BaseURL()})
+	if err != nil {
+		server.Close()
+		return nil, err
+	}
+	env := &Env{Name: name, Provider: p}
+	env.OnCleanup(func(context.Context) error {
+		server.Close()
+		return nil
+	})
+	if err := env.addProject(ctx); err != nil {
+		server.Close()
+		return nil, err
+	}
+	return env, nil
+}
+
+// addProject creates the project of the environment, named after it, and deletes it on cleanup when
+// the provider can.
+func (e *Env) addProject(ctx context.Context) error {
+	creator, ok := e.Provider.(provider.ProjectCreator)
+	if !ok {
+		return fmt.Errorf("the provider cannot create projects")
+	}
+	project, err := creator.CreateProject(ctx, e.Name)
+	if err != nil {
+		return err
+	}
+	e.Project, e.ProjectName = project, e.Name
+	e.OnCleanup(func(ctx context.Context) error {
+		deleter, ok := e.Provider.(provider.ProjectDeleter)
+		if !ok {
+			slog.Warn("the provider cannot delete projects, delete it manually", "environment", e.Name, "project", e.ProjectName)
+			return nil
+		}
+		return deleter.DeleteProject(ctx, project)
+	})
+	return nil
+}
@@ -0,0 +1,66 @@
+package testinfra
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/xuri/excelize/v2"
+)
+
+// Row is a row of the backlog of a scenario.
+type Row struct {
+	Type     string
+	Parent   string // Defaults to the project of the environment
+	Context  string
+	Criteria []string
+	Tasks    string // Value of the Tasks column, empty for the default of the run
+}
+
+// WriteBacklog writes the rows to an XLSX file in the directory, or in the temporary directory when
+// empty, and returns its path.
+func WriteBacklog(dir string, env *Env, rows []Row) (string, error) {
+	criteria := 0
+	for _, r := range rows {
+		criteria = max(criteria, len(r.Criteria))
+	}
+	header := []any{"Type", "Parent", "Context", reader.TasksHeader}
+	for i := range criteria {
+		header = append(header, fmt.Sprintf("Criteria %d", i+1))
+	}
+
+	f := excelize.NewFile()
+	defer func() { _ = f.Close() }()
+	sheet := f.GetSheetName(0)
+	if err := f.SetSheetRow(sheet, "A1", &header); err != nil {
+		return "", fmt.Errorf("failed to write backlog header: %w", err)
+	}
+	for i, r := range rows {
+		parent := r.Parent
+		if parent == "" {
+			parent = env.ProjectName
+		}
+		row := []any{r.Type, parent, r.Context, r.Tasks}
+		for _, c := range r.Criteria {
+			row = append(row, c)
+		}
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return "", err
+		}
+		if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+			return "", fmt.Errorf("failed to write backlog row %d: %w", i+1, err)
+		}
+	}
+
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	path := filepath.Join(dir, strings.ReplaceAll(env.Name, string(filepath.Separator), "-")+".xlsx")
+	if err := f.SaveAs(path); err != nil {
+		return "", fmt.Errorf("failed to write backlog file: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,37 @@
+//go:build integration || integration_test
+// +build integration integration_test
+
+package testinfra
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHub_Integration_Run(t *testing.T) {
+	config := provider.GitHubConfig{
+		Token:   os.Getenv("GITHUB_TOKEN"),
+		Owner:   os.Getenv("GITHUB_OWNER"),
+		Repo:    os.Getenv("GITHUB_REPO"),
+		BaseURL: os.Getenv("GITHUB_API_URL"),
+	}
+	require.NotEmpty(t, config.Token, "GITHUB_TOKEN is required")
+	require.NotEmpty(t, config.Owner, "GITHUB_OWNER is required")
+	require.NotEmpty(t, config.Repo, "GITHUB_REPO is required")
+	p, err := provider.NewGitHubProvider(config)
+	require.NoError(t, err)
+
+	var backend Backend = Project{Provider: p}
+	if os.Getenv("AIGILE_SELFTEST_EPHEMERAL_REPO") == "true" {
+		backend = GitHubRepository{Provider: p}
+	}
+	for _, outcome := range Run(context.Background(), backend, createIssues, Scenarios, Options{Dir: t.TempDir()}) {
+		assert.NoError(t, outcome.Err, outcome.Scenario)
+		assert.NoError(t, outcome.Cleanup, outcome.Scenario)
+	}
+}
@@ -0,0 +1,251 @@
+// Package testinfra runs aigile end to end against an issue provider in a temporary environment: it
+// creates a repository or project, generates a backlog into it, checks the created issues and
+// cleans up. It backs the integration tests and the selftest command.
+package testinfra
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/report"
+	"github.com/leocomelli/aigile/internal/store"
+)
+
+// Env is a temporary environment of an issue provider, with the project the issues of a run are
+// added to.
+type Env struct {
+	Name        string // Unique name of the environment, also the name of its project
+	Provider    provider.Provider
+	Project     *provider.ProjectInfo
+	ProjectName string
+
+	mu          sync.Mutex
+	issues      []int                             // Issues created in the environment
+	closeIssues bool                              // Close the issues on cleanup, as they outlive the environment
+	cleanups    []func(ctx context.Context) error // Run in reverse order on cleanup
+}
+
+// Track records the issues created by a run, to close them on cleanup.
+func (e *Env) Track(results []report.Result) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, r := range results {
+		for _, issue := range r.Issues {
+			if issue.Number > 0 {
+				e.issues = append(e.issues, issue.Number)
+			}
+		}
+	}
+}
+
+// OnCleanup registers a function run on cleanup, after the ones registered later.
+func (e *Env) OnCleanup(f func(ctx context.Context) error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.cleanups = append(e.cleanups, f)
+}
+
+// Cleanup closes the tracked issues, when the provider can, and removes the environment. It goes on
+// after a failure and returns all of them.
+func (e *Env) Cleanup(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var errs []error
+	if e.closeIssues && len(e.issues) > 0 {
+		if closer, ok := e.Provider.(provider.IssueCloser); ok {
+			for _, number := range e.issues {
+				if err := closer.CloseIssue(ctx, number); err != nil {
+					errs = append(errs, err)
+				}
+			}
+		} else {
+			slog.Warn("the provider cannot close issues, close them manually", "environment", e.Name, "issues", e.issues)
+		}
+	}
+	for i := len(e.cleanups) - 1; i >= 0; i-- {
+		if err := e.cleanups[i](ctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	e.issues, e.cleanups = nil, nil
+	return errors.Join(errs...)
+}
+
+// Backend sets up temporary environments of an issue provider.
+type Backend interface {
+	Setup(ctx context.Context, name string) (*Env, error)
+}
+
+// NewName returns a unique name for an environment, e.g. aigile-selftest-20240102-150405-1a2b3c.
+func NewName(prefix string) string {
+	suffix := make([]byte, 3)
+	_, _ = rand.Read(suffix)
+	return fmt.Sprintf("%s-%s-%s", prefix, time.Now().UTC().Format("20060102-150405"), hex.EncodeToString(suffix))
+}
+
+// Scenario is a backlog generated in an environment and the outcome expected from it.
+type Scenario struct {
+	Name string
+	Rows []Row
+	Want Want
+}
+
+// Want is the expected outcome of a scenario.
+type Want struct {
+	Stories   int  // Number of story issues created
+	Tasks     int  // Number of task issues created
+	InProject bool // Whether the stories are items of the project of the environment
+}
+
+// Scenarios are the default scenarios, a story with two tasks and a story without tasks, both
+// added to the project of the environment.
+var Scenarios = []Scenario{
+	{
+		Name: "story-with-tasks",
+		Rows: []Row{{
+			Type:     "User Story",
+			Context:  "As a user, I want to reset my password by email so that I can recover my account",
+			Criteria: []string{"A reset link is sent to the registered email", "The link expires after one hour"},
+			Tasks:    "2",
+		}},
+		Want: Want{Stories: 1, Tasks: 2, InProject: true},
+	},
+	{
+		Name: "story-without-tasks",
+		Rows: []Row{{
+			Type:     "User Story",
+			Context:  "As an admin, I want to export the list of users as CSV so that I can audit the accounts",
+			Criteria: []string{"The file has a row per user with the email and the last login"},
+			Tasks:    "no",
+		}},
+		Want: Want{Stories: 1, InProject: true},
+	},
+}
+
+// Runner generates the backlog file into an environment and returns the results of its rows.
+type Runner func(ctx context.Context, env *Env, file string) ([]report.Result, error)
+
+// Outcome is the outcome of a scenario.
+type Outcome struct {
+	Scenario string
+	Env      string
+	Results  []report.Result
+	Err      error // Setup, run or check failure, nil when the scenario passed
+	Cleanup  error // Cleanup failure, reported apart as it does not fail the scenario
+}
+
+// Options are the options of Run.
+type Options struct {
+	Dir  string // Directory of the backlog files
+	Keep bool   // Keep the environments for inspection instead of cleaning them up
+}
+
+// Run runs the scenarios in parallel, each in its own environment set up by the backend, and returns
+// their outcomes in order.
+func Run(ctx context.Context, backend Backend, run Runner, scenarios []Scenario, opts Options) []Outcome {
+	outcomes := make([]Outcome, len(scenarios))
+	var wg sync.WaitGroup
+	for i, s := range scenarios {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			outcomes[i] = runScenario(ctx, backend, run, s, opts)
+		}()
+	}
+	wg.Wait()
+	return outcomes
+}
+
+func runScenario(ctx context.Context, backend Backend, run Runner, s Scenario, opts Options) Outcome {
+	outcome := Outcome{Scenario: s.Name}
+	env, err := backend.Setup(ctx, NewName("aigile-selftest-"+s.Name))
+	if err != nil {
+		outcome.Err = fmt.Errorf("failed to set up environment: %w", err)
+		return outcome
+	}
+	outcome.Env = env.Name
+	defer func() {
+		if opts.Keep {
+			slog.Info("environment kept", "environment", env.Name)
+			return
+		}
+		// The run may have been canceled, the environment is removed regardless
+		outcome.Cleanup = env.Cleanup(context.WithoutCancel(ctx))
+	}()
+
+	file, err := WriteBacklog(opts.Dir, env, s.Rows)
+	if err != nil {
+		outcome.Err = err
+		return outcome
+	}
+	outcome.Results, err = run(ctx, env, file)
+	env.Track(outcome.Results)
+	if err != nil {
+		outcome.Err = fmt.Errorf("failed to generate backlog: %w", err)
+		return outcome
+	}
+	outcome.Err = Check(ctx, env, outcome.Results, s.Want)
+	return outcome
+}
+
+// Check checks the results of a run in an environment against the expected outcome, returning all
+// the differences found.
+func Check(ctx context.Context, env *Env, results []report.Result, want Want) error {
+	var problems []string
+	counts := map[string]int{}
+	var stories []int
+	for _, r := range results {
+		if r.Status == report.StatusFailed {
+			problems = append(problems, fmt.Sprintf("row %s failed: %s", r.Item.ID, r.Error))
+		}
+		for _, issue := range r.Issues {
+			counts[issue.Kind]++
+			if issue.Kind == store.KindStory {
+				stories = append(stories, issue.Number)
+			}
+		}
+	}
+	if counts[store.KindStory] != want.Stories {
+		problems = append(problems, fmt.Sprintf("%d stories created, want %d", counts[store.KindStory], want.Stories))
+	}
+	if counts[store.KindTask] != want.Tasks {
+		problems = append(problems, fmt.Sprintf("%d tasks created, want %d", counts[store.KindTask], want.Tasks))
+	}
+
+	if want.InProject {
+		switch reader, ok := env.Provider.(provider.ProjectReader); {
+		case env.Project == nil:
+			problems = append(problems, "the environment has no project")
+		case !ok:
+			slog.Warn("the provider cannot list the items of a project, project membership not checked", "environment", env.Name)
+		default:
+			items, err := reader.ListProjectItems(ctx, env.Project, "")
+			if err != nil {
+				return fmt.Errorf("failed to list project items: %w", err)
+			}
+			inProject := make(map[int]bool, len(items))
+			for _, item := range items {
+				inProject[item.Number] = true
+			}
+			for _, number := range stories {
+				if !inProject[number] {
+					problems = append(problems, fmt.Sprintf("story #%d is not in project %s", number, env.ProjectName))
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package testinfra
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/leocomelli/aigile/internal/provider"
+	"github.com/leocomelli/aigile/internal/reader"
+	"github.com/leocomelli/aigile/internal/report"
+	"github.com/leocomelli/aigile/internal/store"
+	"github.com/leocomelli/aigile/pkg/githubtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// createIssues is a Runner creating a story per row in the project of its Parent, with the tasks of
+// its Tasks column, without an LLM.
+func createIssues(ctx context.Context, env *Env, file string) ([]report.Result, error) {
+	items, err := reader.NewXLSXReader(file).Read()
+	if err != nil {
+		return nil, err
+	}
+	var results []report.Result
+	for _, item := range items {
+		project, err := env.Provider.GetProjectByName(ctx, item.Parent)
+		if err != nil {
+			return nil, err
+		}
+		result := report.Result{Item: item, Status: report.StatusCreated}
+		for i := range max(item.Tasks, 0) + 1 {
+			kind, title := store.KindStory, item.Context
+			if i > 0 {
+				kind, title = store.KindTask, fmt.Sprintf("Task %d", i)
+			}
+			created, err := env.Provider.CreateIssue(ctx, provider.CreateIssueRequest{Title: title, Project: project})
+			if err != nil {
+				return nil, err
+			}
+			result.Issues = append(result.Issues, report.Issue{Kind: kind, Number: created.GetNumber()})
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// TestRun tests running scenarios in parallel on fake servers, passing and failing.
+func TestRun(t *testing.T) {
+	failing := Scenarios[0]
+	failing.Name = "too-many-tasks"
+	failing.Want.Tasks = 3
+
+	outcomes := Run(context.Background(), Fake{}, createIssues, append(Scenarios, failing), Options{Dir: t.TempDir()})
+	require.Len(t, outcomes, 3)
+	for _, outcome := range outcomes[:2] {
+		assert.Contains(t, outcome.Env, "aigile-selftest-"+outcome.Scenario+"-")
+		assert.NoError(t, outcome.Err, outcome.Scenario)
+		assert.NoError(t, outcome.Cleanup, outcome.Scenario)
+		assert.Len(t, outcome.Results, 1)
+	}
+	assert.EqualError(t, outcomes[2].Err, "2 tasks created, want 3")
+}
+
+// TestProject tests that the project of an environment is deleted and its issues closed on cleanup.
+func TestProject(t *testing.T) {
+	ctx := context.Background()
+	server := githubtest.NewServer("owner", "repo")
+	defer server.Close()
+	p, err := provider.NewGitHubProvider(provider.GitHubConfig{Token: "token", Owner: "owner", Repo: "repo", BaseURL: server.BaseURL()})
+	require.NoError(t, err)
+
+	env, err := Project{Provider: p}.Setup(ctx, "scratch")
+	require.NoError(t, err)
+	file, err := WriteBacklog(t.TempDir(), env, Scenarios[0].Rows)
+	require.NoError(t, err)
+	results, err := createIssues(ctx, env, file)
+	require.NoError(t, err)
+	env.Track(results)
+	require.NoError(t, Check(ctx, env, results, Scenarios[0].Want))
+	assert.ErrorContains(t, Check(ctx, env, results, Want{Stories: 2, Tasks: 2}), "1 stories created, want 2")
+
+	require.NoError(t, env.Cleanup(ctx))
+	_, ok := server.Project("scratch")
+	assert.False(t, ok)
+	for _, issue := range server.Issues() {
+		assert.Equal(t, provider.StateClosed, issue.State)
+	}
+}
+
+// TestGitHubRepository tests that the repository of an environment is deleted on cleanup.
+func TestGitHubRepository(t *testing.T) {
+	ctx := context.Background()
+	server := githubtest.NewServer("owner", "repo")
+	defer server.Close()
+	p, err := provider.NewGitHubProvider(provider.GitHubConfig{Token: "token", Owner: "owner", Repo: "repo", BaseURL: server.BaseURL()})
+	require.NoError(t, err)
+
+	env, err := GitHubRepository{Provider: p}.Setup(ctx, "scratch")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scratch"}, server.Repositories())
+	require.NoError(t, env.Cleanup(ctx))
+	assert.Empty(t, server.Repositories())
+	_, ok := server.Project("scratch")
+	assert.False(t, ok)
+}
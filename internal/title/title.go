@@ -0,0 +1,91 @@
+// Package title derives issue titles from a template instead of the LLM, for teams with title
+// conventions the model does not follow reliably.
+package title
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SummaryLength is the maximum length, in characters, of the {{.Summary}} variable.
+const SummaryLength = 60
+
+// Variables are the template variables filled in by Render.
+var Variables = []string{"Type", "Parent", "Context", "Summary", "Row"}
+
+// variablePattern matches the template variables, e.g. {{.Summary}} or {{ .Summary }}.
+var variablePattern = regexp.MustCompile(`\{\{\s*\.?([^{}]*?)\s*\}\}`)
+
+// emptyGroups matches brackets and parentheses left empty by blank variables, e.g. "[] ".
+var emptyGroups = regexp.MustCompile(`\[\s*\]|\(\s*\)`)
+
+// Data holds the values of a row used to fill a title template.
+type Data struct {
+	Type    string
+	Parent  string
+	Context string
+	Row     int
+}
+
+// Template is a validated title template, e.g. "[{{.Parent}}] {{.Summary}}".
+type Template struct {
+	text string
+}
+
+// Parse validates a title template: it must not be blank and may only use the known variables.
+func Parse(text string) (*Template, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, errors.New("invalid title template: the template is empty")
+	}
+	for _, match := range variablePattern.FindAllStringSubmatch(text, -1) {
+		if !slices.Contains(Variables, match[1]) || !strings.HasPrefix(match[0], "{{.") || strings.ContainsAny(match[0], " \t") {
+			return nil, fmt.Errorf("invalid title template: unknown variable %s (expected one of %s)", match[0], strings.Join(Variables, ", "))
+		}
+	}
+	return &Template{text: text}, nil
+}
+
+// Render fills in the template with the values of a row. Brackets and parentheses left empty by
+// blank values are removed, e.g. "[] Pay by card" becomes "Pay by card".
+func (t *Template) Render(d Data) string {
+	values := map[string]string{
+		"{{.Type}}":    d.Type,
+		"{{.Parent}}":  strings.TrimSpace(d.Parent),
+		"{{.Context}}": oneLine(d.Context),
+		"{{.Summary}}": Summary(d.Context),
+		"{{.Row}}":     strconv.Itoa(d.Row),
+	}
+	// A single pass over the template, so the values are never expanded as variables themselves
+	title := variablePattern.ReplaceAllStringFunc(t.text, func(v string) string { return values[v] })
+	title = emptyGroups.ReplaceAllString(title, "")
+	return strings.Join(strings.Fields(title), " ")
+}
+
+// Summary returns a short summary of a text: its first sentence, cut at a word boundary when it
+// is longer than SummaryLength characters.
+func Summary(text string) string {
+	text = oneLine(text)
+	if i := strings.Index(text, ". "); i >= 0 {
+		text = text[:i]
+	}
+	text = strings.TrimSuffix(text, ".")
+	if utf8.RuneCountInString(text) <= SummaryLength {
+		return text
+	}
+	runes := []rune(text)
+	cut := string(runes[:SummaryLength])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:-") + "..."
+}
+
+// oneLine joins the lines of a text, collapsing the whitespace.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
@@ -0,0 +1,44 @@
+package title
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate_Render(t *testing.T) {
+	tmpl, err := Parse("[{{.Parent}}] {{.Summary}}")
+	require.NoError(t, err)
+	assert.Equal(t, "[Payments] Customers pay by card", tmpl.Render(Data{Parent: "Payments", Context: "Customers pay by card. Visa and Mastercard only."}))
+	assert.Equal(t, "Customers pay by card", tmpl.Render(Data{Context: "Customers pay by card"}))
+
+	tmpl, err = Parse("{{.Type}} ({{.Row}}): {{.Context}}")
+	require.NoError(t, err)
+	assert.Equal(t, "User Story (12): Refunds within 7 days", tmpl.Render(Data{Type: "User Story", Row: 12, Context: "Refunds\nwithin  7 days"}))
+
+	// Values are not expanded as variables
+	tmpl, err = Parse("[{{.Parent}}] {{.Summary}} #{{.Row}}")
+	require.NoError(t, err)
+	assert.Equal(t, "[Team {{.Row}}] Pay by card #12", tmpl.Render(Data{Parent: "Team {{.Row}}", Context: "Pay by card", Row: 12}))
+}
+
+func TestParse_Invalid(t *testing.T) {
+	_, err := Parse(" ")
+	assert.EqualError(t, err, "invalid title template: the template is empty")
+
+	_, err = Parse("{{.Team}}: {{.Summary}}")
+	assert.EqualError(t, err, "invalid title template: unknown variable {{.Team}} (expected one of Type, Parent, Context, Summary, Row)")
+
+	_, err = Parse("{{ .Summary }}")
+	assert.ErrorContains(t, err, "unknown variable {{ .Summary }}")
+}
+
+func TestSummary(t *testing.T) {
+	assert.Equal(t, "Pay by card", Summary("Pay by card."))
+	assert.Equal(t, "Pay by card", Summary("  Pay by card. Then refund.\nMore"))
+	long := strings.Repeat("checkout ", 10)
+	assert.Equal(t, "checkout checkout checkout checkout checkout checkout...", Summary(long))
+	assert.LessOrEqual(t, len(Summary(long)), SummaryLength+3)
+}
@@ -0,0 +1,123 @@
+// Package tokens estimates the number of LLM tokens of a text and splits long texts into chunks
+// that fit a token budget.
+package tokens
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// charsPerToken is the average number of characters of a word piece in tiktoken-style BPE
+// vocabularies (cl100k/o200k) for latin text.
+const charsPerToken = 4
+
+// Estimate returns an approximation of the number of tokens of text, close to what tiktoken-style
+// BPE encoders produce: words are split in pieces of about four characters, and every punctuation
+// mark, symbol and non-latin character counts as its own token.
+func Estimate(text string) int {
+	count := 0
+	wordLength := 0
+	flush := func() {
+		if wordLength > 0 {
+			count += (wordLength + charsPerToken - 1) / charsPerToken
+			wordLength = 0
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.IsSpace(r):
+			flush()
+		case r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			wordLength++
+		case unicode.IsLetter(r) && r <= unicode.MaxLatin1:
+			// Accented latin letters take about two ASCII characters in the vocabulary
+			wordLength += 2
+		default:
+			flush()
+			count++
+		}
+	}
+	flush()
+	return count
+}
+
+// Split splits text into chunks of at most maxTokens estimated tokens. Paragraphs are kept
+// together when possible, then sentences, then words.
+func Split(text string, maxTokens int) []string {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil
+	}
+	if maxTokens <= 0 || Estimate(text) <= maxTokens {
+		return []string{text}
+	}
+
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+	add := func(piece, separator string) {
+		pieceTokens := Estimate(piece)
+		if current.Len() > 0 && currentTokens+pieceTokens > maxTokens {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		if current.Len() > 0 {
+			current.WriteString(separator)
+		}
+		current.WriteString(piece)
+		currentTokens += pieceTokens
+	}
+
+	for _, paragraph := range splitParagraphs(text) {
+		if Estimate(paragraph) <= maxTokens {
+			add(paragraph, "\n\n")
+			continue
+		}
+		for _, sentence := range splitSentences(paragraph) {
+			if Estimate(sentence) <= maxTokens {
+				add(sentence, " ")
+				continue
+			}
+			for _, word := range strings.Fields(sentence) {
+				add(word, " ")
+			}
+		}
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+// splitParagraphs splits text on blank lines.
+func splitParagraphs(text string) []string {
+	var paragraphs []string
+	for _, p := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return paragraphs
+}
+
+// splitSentences splits text after sentence-ending punctuation followed by a space.
+func splitSentences(text string) []string {
+	var sentences []string
+	start := 0
+	runes := []rune(text)
+	for i, r := range runes {
+		if (r == '.' || r == '!' || r == '?' || r == '\n') && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])) {
+			if s := strings.TrimSpace(string(runes[start : i+1])); s != "" {
+				sentences = append(sentences, s)
+			}
+			start = i + 1
+		}
+	}
+	if s := strings.TrimSpace(string(runes[start:])); s != "" {
+		sentences = append(sentences, s)
+	}
+	return sentences
+}
@@ -0,0 +1,45 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEstimate(t *testing.T) {
+	assert.Equal(t, 0, Estimate(""))
+	assert.Equal(t, 0, Estimate("  \n\t"))
+	assert.Equal(t, 1, Estimate("card"))
+	assert.Equal(t, 2, Estimate("payments"))
+	// "As" "a" "user" "," "I" "want" "to" "pay" "." -> 9
+	assert.Equal(t, 9, Estimate("As a user, I want to pay."))
+	// Accented letters weigh more, symbols count as tokens
+	assert.Equal(t, 2, Estimate("cartão"))
+	assert.Equal(t, 3, Estimate("日本語"))
+
+	// Roughly four characters per token on plain prose
+	text := strings.Repeat("The checkout must support credit card payments and refunds. ", 100)
+	estimate := Estimate(text)
+	assert.InDelta(t, len(text)/4, estimate, float64(len(text))/10)
+}
+
+func TestSplit(t *testing.T) {
+	assert.Nil(t, Split("  ", 10))
+	assert.Equal(t, []string{"short text"}, Split("short text", 10))
+	assert.Equal(t, []string{"no limit"}, Split("no limit", 0))
+
+	text := "First paragraph about payments.\n\nSecond paragraph about refunds.\n\nThird paragraph about invoices."
+	chunks := Split(text, 20)
+	assert.Equal(t, []string{
+		"First paragraph about payments.\n\nSecond paragraph about refunds.",
+		"Third paragraph about invoices.",
+	}, chunks)
+
+	// Long paragraphs are split by sentence, and long sentences by word
+	long := "One two three four. Five six seven eight. " + strings.Repeat("word ", 10)
+	for _, chunk := range Split(long, 5) {
+		assert.LessOrEqual(t, Estimate(chunk), 5, chunk)
+	}
+	assert.Equal(t, strings.Fields(long), strings.Fields(strings.Join(Split(long, 5), " ")))
+}
@@ -0,0 +1,148 @@
+// Package version identifies the build of aigile, from the version, commit and build date set at
+// build time with -ldflags, and checks for a newer release on GitHub.
+package version
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"strings"
+)
+
+// Build metadata, set with -ldflags "-X github.com/leocomelli/aigile/internal/version.Version=v1.2.3 ...".
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = "" // Build date, RFC 3339
+)
+
+// ReleasesURL is the GitHub API endpoint of the latest release of aigile.
+var ReleasesURL = "https://api.github.com/repos/leocomelli/aigile/releases/latest"
+
+// Info identifies a build of aigile.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // Built from a working tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"`
+}
+
+// Get returns the build metadata. Builds without -ldflags, e.g. with go install, fall back to the
+// module version and the VCS information embedded by the Go toolchain.
+func Get() Info {
+	info := Info{Version: Version, Commit: Commit, Date: Date, GoVersion: runtime.Version(), Platform: runtime.GOOS + "/" + runtime.GOARCH}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, s := range build.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = s.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = s.Value
+			}
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+	return info
+}
+
+// Short returns the version with the abbreviated commit, e.g. v1.2.3 (1a2b3c4), as recorded with
+// the runs.
+func (i Info) Short() string {
+	commit := i.Commit
+	if len(commit) > 7 {
+		commit = commit[:7]
+	}
+	if i.Modified {
+		commit += "-dirty"
+	}
+	if commit == "" {
+		return i.Version
+	}
+	return fmt.Sprintf("%s (%s)", i.Version, commit)
+}
+
+// Release is a release of aigile published on GitHub.
+type Release struct {
+	Version string `json:"tag_name"`
+	URL     string `json:"html_url"`
+}
+
+// Latest returns the latest release published at ReleasesURL.
+func Latest(ctx context.Context, client *http.Client) (Release, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ReleasesURL, nil)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to create release request: %w", err)
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("failed to get latest release: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("failed to get latest release (status: %d)", resp.StatusCode)
+	}
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return Release{}, fmt.Errorf("failed to decode latest release: %w", err)
+	}
+	return release, nil
+}
+
+// Newer reports whether the latest version is newer than the current one. Both are semantic
+// versions with an optional v prefix; a current version that is not one, such as dev, is never
+// outdated.
+func Newer(latest, current string) bool {
+	l, ok := parse(latest)
+	if !ok {
+		return false
+	}
+	c, ok := parse(current)
+	if !ok {
+		return false
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i]
+		}
+	}
+	return false
+}
+
+// parse returns the major, minor and patch numbers of a semantic version, ignoring its pre-release
+// and build metadata.
+func parse(v string) ([3]int, bool) {
+	var numbers [3]int
+	v = strings.TrimPrefix(v, "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	parts := strings.Split(v, ".")
+	if len(parts) != 3 {
+		return numbers, false
+	}
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return numbers, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
@@ -0,0 +1,54 @@
+package version
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		expected        bool
+	}{
+		{"v1.3.0", "v1.2.9", true},
+		{"v1.10.0", "1.9.0", true},
+		{"v2.0.0", "v1.99.99", true},
+		{"v1.2.3", "v1.2.3", false},
+		{"v1.2.3", "v1.2.4-rc.1", false},
+		{"v1.2.3", "dev", false},
+		{"nightly", "v1.2.3", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, Newer(tt.latest, tt.current), "%s > %s", tt.latest, tt.current)
+	}
+}
+
+func TestInfo_Short(t *testing.T) {
+	assert.Equal(t, "v1.2.3 (1a2b3c4)", Info{Version: "v1.2.3", Commit: "1a2b3c4d5e6f"}.Short())
+	assert.Equal(t, "dev (1a2b3c4-dirty)", Info{Version: "dev", Commit: "1a2b3c4d5e6f", Modified: true}.Short())
+	assert.Equal(t, "v1.2.3", Info{Version: "v1.2.3"}.Short())
+}
+
+func TestLatest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github+json", r.Header.Get("Accept"))
+		_, _ = w.Write([]byte(`{"tag_name": "v1.3.0", "html_url": "https://github.com/leocomelli/aigile/releases/tag/v1.3.0"}`))
+	}))
+	defer server.Close()
+	defer func(url string) { ReleasesURL = url }(ReleasesURL)
+	ReleasesURL = server.URL
+
+	release, err := Latest(context.Background(), server.Client())
+	require.NoError(t, err)
+	assert.Equal(t, Release{Version: "v1.3.0", URL: "https://github.com/leocomelli/aigile/releases/tag/v1.3.0"}, release)
+
+	ReleasesURL = server.URL + "/missing"
+	server.Config.Handler = http.NotFoundHandler()
+	_, err = Latest(context.Background(), server.Client())
+	assert.EqualError(t, err, "failed to get latest release (status: 404)")
+}
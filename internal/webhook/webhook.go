@@ -0,0 +1,182 @@
+// Package webhook receives the GitHub webhooks of the serve command, so that aigile reacts to the
+// changes made to its issues in the tracker.
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxPayloadSize is the maximum size of the payloads GitHub delivers.
+const maxPayloadSize = 25 << 20
+
+// Issue is the issue of an event.
+type Issue struct {
+	Number      int    `json:"number"`
+	Title       string `json:"title"`
+	URL         string `json:"html_url"`
+	Body        string `json:"body"`
+	StateReason string `json:"state_reason"` // completed, not_planned or reopened
+}
+
+// Comment is the comment of an issue_comment event.
+type Comment struct {
+	ID          int64  `json:"id"`
+	Body        string `json:"body"`
+	Association string `json:"author_association"` // Relationship of the author with the repository, e.g. MEMBER
+	User        struct {
+		Login string `json:"login"`
+		Type  string `json:"type"` // User or Bot
+	} `json:"user"`
+}
+
+// trustedAssociations are the author associations allowed to run commands: the people with write
+// access to the repository.
+var trustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// Trusted returns whether the author of the comment is allowed to run commands.
+func (c Comment) Trusted() bool {
+	return c.User.Type != "Bot" && slices.Contains(trustedAssociations, c.Association)
+}
+
+// commandPrefix starts the lines of the comments that are commands, e.g. /aigile regenerate tasks.
+const commandPrefix = "/aigile"
+
+// ParseCommand returns the words of the first command of a comment body, without the prefix, and
+// whether it has one.
+func ParseCommand(body string) ([]string, bool) {
+	for line := range strings.Lines(body) {
+		fields := strings.Fields(line)
+		if len(fields) > 0 && fields[0] == commandPrefix {
+			return fields[1:], true
+		}
+	}
+	return nil, false
+}
+
+// payload is the subset of the event payloads read by the handler.
+type payload struct {
+	Action string `json:"action"`
+	Issue  struct {
+		Issue
+		PullRequest *struct{} `json:"pull_request"` // Set when the issue is a pull request
+	} `json:"issue"`
+	Comment    Comment `json:"comment"`
+	Repository struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+// Handler verifies the signature of the webhook deliveries of a repository and dispatches their
+// events to the registered functions. Events of other repositories and without function are
+// acknowledged and ignored; a function failing answers 500, so the delivery can be redelivered.
+type Handler struct {
+	secret     []byte
+	repository string
+	closed     func(ctx context.Context, issue Issue) error
+	command    func(ctx context.Context, issue Issue, comment Comment, args []string) error
+}
+
+// NewHandler creates a handler of the deliveries of repository (owner/name) signed with secret.
+func NewHandler(secret, repository string) *Handler {
+	return &Handler{secret: []byte(secret), repository: repository}
+}
+
+// OnIssueClosed registers the function called when an issue is closed.
+func (h *Handler) OnIssueClosed(fn func(ctx context.Context, issue Issue) error) {
+	h.closed = fn
+}
+
+// OnCommand registers the function called when a comment with a command, see ParseCommand, is added
+// to an issue by a trusted author. Comments of pull requests are ignored.
+func (h *Handler) OnCommand(fn func(ctx context.Context, issue Issue, comment Comment, args []string) error) {
+	h.command = fn
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+	if err := Verify(h.secret, body, r.Header.Get("X-Hub-Signature-256")); err != nil {
+		slog.Warn("webhook delivery rejected", "delivery", r.Header.Get("X-GitHub-Delivery"), "error", err)
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	if event == "ping" {
+		_, _ = fmt.Fprintln(w, "pong")
+		return
+	}
+	var p payload
+	if err := json.Unmarshal(body, &p); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if !strings.EqualFold(p.Repository.FullName, h.repository) {
+		slog.Debug("webhook event of another repository ignored", "event", event, "repository", p.Repository.FullName)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	if err := h.dispatch(r.Context(), event, p); err != nil {
+		slog.Error("failed to handle webhook event", "event", event, "action", p.Action, "error", err)
+		http.Error(w, "failed to handle event", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// dispatch calls the function registered for the event, if any.
+func (h *Handler) dispatch(ctx context.Context, event string, p payload) error {
+	switch {
+	case event == "issues" && p.Action == "closed" && h.closed != nil:
+		slog.Debug("issue closed", "number", p.Issue.Number, "reason", p.Issue.StateReason)
+		return h.closed(ctx, p.Issue.Issue)
+	case event == "issue_comment" && p.Action == "created" && p.Issue.PullRequest == nil && h.command != nil:
+		args, ok := ParseCommand(p.Comment.Body)
+		if !ok {
+			return nil
+		}
+		if !p.Comment.Trusted() {
+			slog.Warn("command of an untrusted author ignored", "number", p.Issue.Number, "author", p.Comment.User.Login, "association", p.Comment.Association)
+			return nil
+		}
+		slog.Info("command received", "number", p.Issue.Number, "author", p.Comment.User.Login, "command", strings.Join(args, " "))
+		return h.command(ctx, p.Issue.Issue, p.Comment, args)
+	default:
+		slog.Debug("webhook event ignored", "event", event, "action", p.Action)
+		return nil
+	}
+}
+
+// Verify checks that signature, the X-Hub-Signature-256 header of a delivery, is the HMAC-SHA256 of
+// body with secret.
+func Verify(secret, body []byte, signature string) error {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return errors.New("missing sha256 signature")
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return errors.New("invalid signature")
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return errors.New("signature mismatch")
+	}
+	return nil
+}
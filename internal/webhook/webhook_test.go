@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSecret = "s3cret"
+
+func sign(body string) string {
+	mac := hmac.New(sha256.New, []byte(testSecret))
+	mac.Write([]byte(body))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func deliver(h http.Handler, event, body, signature string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/github", strings.NewReader(body))
+	req.Header.Set("X-GitHub-Event", event)
+	req.Header.Set("X-Hub-Signature-256", signature)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestVerify(t *testing.T) {
+	body := []byte(`{"action":"closed"}`)
+	require.NoError(t, Verify([]byte(testSecret), body, sign(string(body))))
+	assert.ErrorContains(t, Verify([]byte("other"), body, sign(string(body))), "signature mismatch")
+	assert.ErrorContains(t, Verify([]byte(testSecret), body, ""), "missing sha256 signature")
+	assert.ErrorContains(t, Verify([]byte(testSecret), body, "sha256=zz"), "invalid signature")
+}
+
+func TestHandler_IssueClosed(t *testing.T) {
+	h := NewHandler(testSecret, "acme/backlog")
+	var closed []Issue
+	h.OnIssueClosed(func(_ context.Context, issue Issue) error {
+		closed = append(closed, issue)
+		if issue.Number == 13 {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	body := `{"action":"closed","issue":{"number":12,"title":"Login","html_url":"https://github.com/acme/backlog/issues/12","state_reason":"completed"},"repository":{"full_name":"Acme/Backlog"}}`
+	rec := deliver(h, "issues", body, sign(body))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	require.Len(t, closed, 1)
+	assert.Equal(t, Issue{Number: 12, Title: "Login", URL: "https://github.com/acme/backlog/issues/12", StateReason: "completed"}, closed[0])
+
+	// Unsigned deliveries are rejected
+	rec = deliver(h, "issues", body, "sha256=00")
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	// Other actions and repositories are ignored
+	for _, body := range []string{
+		`{"action":"opened","issue":{"number":12},"repository":{"full_name":"acme/backlog"}}`,
+		`{"action":"closed","issue":{"number":12},"repository":{"full_name":"acme/other"}}`,
+	} {
+		rec = deliver(h, "issues", body, sign(body))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+	assert.Len(t, closed, 1)
+
+	// Failures can be redelivered
+	body = `{"action":"closed","issue":{"number":13},"repository":{"full_name":"acme/backlog"}}`
+	rec = deliver(h, "issues", body, sign(body))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = deliver(h, "ping", `{"zen":"Keep it simple."}`, sign(`{"zen":"Keep it simple."}`))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestParseCommand(t *testing.T) {
+	args, ok := ParseCommand("Thanks!\r\n/aigile regenerate  tasks\n/aigile other")
+	require.True(t, ok)
+	assert.Equal(t, []string{"regenerate", "tasks"}, args)
+
+	_, ok = ParseCommand("see /aigile regenerate tasks")
+	assert.False(t, ok)
+	_, ok = ParseCommand("/aigiles regenerate tasks")
+	assert.False(t, ok)
+}
+
+func TestHandler_Command(t *testing.T) {
+	h := NewHandler(testSecret, "acme/backlog")
+	var commands [][]string
+	h.OnCommand(func(_ context.Context, issue Issue, comment Comment, args []string) error {
+		assert.Equal(t, 12, issue.Number)
+		assert.Equal(t, "octocat", comment.User.Login)
+		commands = append(commands, args)
+		return nil
+	})
+
+	for _, body := range []string{
+		`{"action":"created","issue":{"number":12},"comment":{"body":"/aigile regenerate tasks","author_association":"MEMBER","user":{"login":"octocat","type":"User"}},"repository":{"full_name":"acme/backlog"}}`,
+		// Not a command
+		`{"action":"created","issue":{"number":12},"comment":{"body":"LGTM","author_association":"MEMBER","user":{"login":"octocat","type":"User"}},"repository":{"full_name":"acme/backlog"}}`,
+		// Untrusted author
+		`{"action":"created","issue":{"number":12},"comment":{"body":"/aigile regenerate tasks","author_association":"NONE","user":{"login":"octocat","type":"User"}},"repository":{"full_name":"acme/backlog"}}`,
+		// Pull request
+		`{"action":"created","issue":{"number":12,"pull_request":{}},"comment":{"body":"/aigile regenerate tasks","author_association":"MEMBER","user":{"login":"octocat","type":"User"}},"repository":{"full_name":"acme/backlog"}}`,
+		// Edited comment
+		`{"action":"edited","issue":{"number":12},"comment":{"body":"/aigile regenerate tasks","author_association":"MEMBER","user":{"login":"octocat","type":"User"}},"repository":{"full_name":"acme/backlog"}}`,
+	} {
+		rec := deliver(h, "issue_comment", body, sign(body))
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	}
+	assert.Equal(t, [][]string{{"regenerate", "tasks"}}, commands)
+}
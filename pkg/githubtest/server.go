@@ -0,0 +1,1066 @@
+// Package githubtest provides an in-memory fake of the GitHub REST and GraphQL APIs used by aigile,
+// with fault injection (rate limits, server errors, GraphQL errors) to test retry and limit
+// handling without live credentials.
+package githubtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Issue is an issue stored by the fake server.
+type Issue struct {
+	Number      int      `json:"number"`
+	ID          int64    `json:"id"`
+	NodeID      string   `json:"node_id"`
+	Title       string   `json:"title"`
+	Body        string   `json:"body"`
+	Labels      []string `json:"-"`
+	Assignees   []string `json:"-"` // Logins of the assignees
+	State       string   `json:"state"`
+	StateReason string   `json:"state_reason,omitempty"` // Why a closed issue was closed, e.g. not_planned
+	Milestone   int      `json:"-"`                      // Number of the milestone, 0 for none
+	Comments    []string `json:"-"`
+}
+
+// PullRequest is a pull request opened on the fake server, with the files committed to its head
+// branch by path.
+type PullRequest struct {
+	Number int
+	Title  string
+	Body   string
+	Head   string
+	Base   string
+	Files  map[string]string
+}
+
+// defaultBranch is the default branch of the fake repository.
+const defaultBranch = "main"
+
+// Node IDs of the fake repository and of its owner.
+const (
+	repoNodeID  = "R_1"
+	ownerNodeID = "O_1"
+)
+
+// Project is a Project v2 stored by the fake server.
+type Project struct {
+	ID     string
+	Number int
+	Title  string
+	Items  []string                  // node IDs of the issues added to the project
+	Drafts []DraftIssue              // draft items created in the project
+	Fields map[string]map[string]any // field values by name, by item node ID
+	Schema []Field                   // fields of the project, listed by the fields query
+}
+
+// Field is a field of a Project v2 stored by the fake server. Options are the options of a single
+// select field or the iterations of an iteration field.
+type Field struct {
+	ID       string
+	Name     string
+	DataType string
+	Options  []FieldOption
+}
+
+// FieldOption is an option of a single select field or, with a start date, an iteration.
+type FieldOption struct {
+	ID        string
+	Name      string
+	StartDate string
+	Duration  int
+}
+
+// DraftIssue is a draft item of a Project v2 stored by the fake server.
+type DraftIssue struct {
+	ID    string
+	Title string
+	Body  string
+}
+
+// fault is an injected failure applied to the next matching requests.
+type fault struct {
+	remaining int
+	graphql   bool // only applies to GraphQL requests
+	apply     func(w http.ResponseWriter)
+}
+
+// Server is a fake GitHub API server for a single repository. The repositories created through the
+// API are kept by name and share its issues.
+type Server struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	owner       string
+	repo        string
+	issues      []*Issue
+	projects    []*Project
+	lastProject int             // last project number, not reused after a deletion
+	created     map[string]bool // repositories created through the API, sharing the issues of repo
+	subIssues   map[int][]int64
+	branches    map[string]map[string]string // files by path, by branch
+	pulls       []*PullRequest
+	milestones  []string // titles, numbered from 1
+	numbers     int      // last issue or pull request number, shared as in GitHub
+	faults      []*fault
+	requests    int
+	scopes      []string          // scopes of a classic token, reported in X-OAuth-Scopes when set
+	permissions map[string]bool   // permissions of the token on the repository, e.g. push, when set
+	grants      map[string]string // permissions of a fine-grained token, e.g. issues: write, enforced when set
+	expiration  time.Time         // expiration of the token, reported when set
+	hasIssues   bool
+}
+
+var (
+	issuesPath     = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues$`)
+	issuePath      = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues/(\d+)$`)
+	subIssuesPath  = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues/(\d+)/sub_issues$`)
+	commentsPath   = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues/(\d+)/comments$`)
+	refPath        = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/git/ref/heads/(.+)$`)
+	refsPath       = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/git/refs$`)
+	contentsPath   = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/contents/(.+)$`)
+	pullsPath      = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/pulls$`)
+	milestonesPath = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/milestones$`)
+	repoPath       = regexp.MustCompile(`^/repos/([^/]+)/([^/]+)$`)
+	createRepoPath = regexp.MustCompile(`^/(?:user|orgs/([^/]+))/repos$`)
+)
+
+// NewServer starts a fake GitHub server for owner/repo. Callers must Close it.
+func NewServer(owner, repo string) *Server {
+	s := &Server{owner: owner, repo: repo, subIssues: make(map[int][]int64), created: make(map[string]bool), branches: map[string]map[string]string{defaultBranch: {}}, hasIssues: true}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	return s
+}
+
+// BaseURL returns the API base URL to configure the GitHub client with.
+func (s *Server) BaseURL() string {
+	return s.URL + "/"
+}
+
+// AddProject registers a Project v2 owned by the repository owner.
+func (s *Server) AddProject(title string) Project {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return *s.newProject(title)
+}
+
+// newProject registers a Project v2 owned by the repository owner, numbered after the last one.
+func (s *Server) newProject(title string) *Project {
+	s.lastProject++
+	p := &Project{ID: fmt.Sprintf("PVT_%d", s.lastProject), Number: s.lastProject, Title: title}
+	s.projects = append(s.projects, p)
+	return p
+}
+
+// AddMilestone registers a milestone of the repository and returns its number.
+func (s *Server) AddMilestone(title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.milestones = append(s.milestones, title)
+	return len(s.milestones)
+}
+
+// Issues returns a copy of the issues created so far.
+func (s *Server) Issues() []Issue {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]Issue, 0, len(s.issues))
+	for _, i := range s.issues {
+		cp := *i
+		cp.Comments = append([]string(nil), i.Comments...)
+		result = append(result, cp)
+	}
+	return result
+}
+
+// PullRequests returns a copy of the pull requests opened so far.
+func (s *Server) PullRequests() []PullRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := make([]PullRequest, 0, len(s.pulls))
+	for _, p := range s.pulls {
+		cp := *p
+		cp.Files = make(map[string]string, len(s.branches[p.Head]))
+		for path, content := range s.branches[p.Head] {
+			cp.Files[path] = content
+		}
+		result = append(result, cp)
+	}
+	return result
+}
+
+// SubIssues returns the IDs of the sub-issues linked to the parent issue number.
+func (s *Server) SubIssues(parent int) []int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]int64(nil), s.subIssues[parent]...)
+}
+
+// SetItemField sets a field of the item of the issue number in the project with the given title:
+// a string for a single select field such as Status, a number for a number field.
+func (s *Server) SetItemField(projectTitle string, number int, field string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	issue := s.findIssue(number)
+	for _, p := range s.projects {
+		if p.Title != projectTitle || issue == nil {
+			continue
+		}
+		if p.Fields == nil {
+			p.Fields = map[string]map[string]any{}
+		}
+		if p.Fields[issue.NodeID] == nil {
+			p.Fields[issue.NodeID] = map[string]any{}
+		}
+		p.Fields[issue.NodeID][field] = value
+	}
+}
+
+// AddField adds a field to the project with the given title.
+func (s *Server) AddField(projectTitle string, field Field) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.Title == projectTitle {
+			p.Schema = append(p.Schema, field)
+		}
+	}
+}
+
+// Project returns the project with the given title.
+func (s *Server) Project(title string) (Project, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, p := range s.projects {
+		if p.Title == title {
+			cp := *p
+			cp.Items = append([]string(nil), p.Items...)
+			cp.Drafts = append([]DraftIssue(nil), p.Drafts...)
+			return cp, true
+		}
+	}
+	return Project{}, false
+}
+
+// Repositories returns the names of the repositories created through the API and not deleted.
+func (s *Server) Repositories() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	names := make([]string, 0, len(s.created))
+	for name := range s.created {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetScopes makes the server report the scopes of a classic token in the X-OAuth-Scopes header of
+// its responses. The header is not sent by default, as for fine-grained tokens and GitHub Apps.
+func (s *Server) SetScopes(scopes ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scopes = append([]string{}, scopes...)
+}
+
+// SetPermissions sets the permissions of the token on the repository reported with the repository,
+// e.g. {"pull": true} for a read-only access. They are not reported by default.
+func (s *Server) SetPermissions(permissions map[string]bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.permissions = permissions
+}
+
+// SetFineGrainedPermissions makes the server enforce the permissions of a fine-grained token, by
+// permission name (issues, contents, pull_requests or organization_projects) to read or write. The
+// requests needing a permission not granted fail as in GitHub: the REST ones with a 403 status and
+// the GraphQL ones on projects with a FORBIDDEN error.
+func (s *Server) SetFineGrainedPermissions(permissions map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.grants = permissions
+}
+
+// SetTokenExpiration makes the server report the expiration of the token in the
+// GitHub-Authentication-Token-Expiration header of its responses.
+func (s *Server) SetTokenExpiration(expiration time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.expiration = expiration
+}
+
+// DisableIssues disables the issues of the repository.
+func (s *Server) DisableIssues() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hasIssues = false
+}
+
+// Requests returns the number of requests received, including failed ones.
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+// RateLimit makes the next n requests fail with a primary rate limit response.
+func (s *Server) RateLimit(n int) {
+	reset := strconv.FormatInt(time.Now().Add(time.Minute).Unix(), 10)
+	s.addFault(n, false, func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Limit", "5000")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", reset)
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"message":           "API rate limit exceeded",
+			"documentation_url": "https://docs.github.com/rest/overview/resources-in-the-rest-api#rate-limiting",
+		})
+	})
+}
+
+// SecondaryRateLimit makes the next n requests fail with a secondary (abuse) rate limit response.
+func (s *Server) SecondaryRateLimit(n int, retryAfter time.Duration) {
+	s.addFault(n, false, func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"message":           "You have exceeded a secondary rate limit. Please wait a few minutes before you try again.",
+			"documentation_url": "https://docs.github.com/rest/overview/resources-in-the-rest-api#secondary-rate-limits",
+		})
+	})
+}
+
+// FailWithStatus makes the next n requests fail with the given HTTP status.
+func (s *Server) FailWithStatus(n, status int) {
+	s.addFault(n, false, func(w http.ResponseWriter) {
+		writeJSON(w, status, map[string]string{"message": http.StatusText(status)})
+	})
+}
+
+// RequireSSO makes the next n requests fail as when the organization enforces SAML single sign-on
+// and the token is not authorized for it.
+func (s *Server) RequireSSO(n int) {
+	s.addFault(n, false, func(w http.ResponseWriter) {
+		w.Header().Set("X-GitHub-SSO", "required; url=https://github.com/orgs/"+s.owner+"/sso?authorization_request=abc")
+		writeJSON(w, http.StatusForbidden, map[string]string{
+			"message":           "Resource protected by organization SAML enforcement. You must grant your Personal Access token access to this organization.",
+			"documentation_url": "https://docs.github.com/articles/authenticating-to-a-github-organization-with-saml-single-sign-on/",
+		})
+	})
+}
+
+// GraphQLErrors makes the next n GraphQL requests return the given errors with a 200 status.
+func (s *Server) GraphQLErrors(n int, errorType string, messages ...string) {
+	var errs []map[string]string
+	for _, m := range messages {
+		errs = append(errs, map[string]string{"type": errorType, "message": m})
+	}
+	s.addFault(n, true, func(w http.ResponseWriter) {
+		writeJSON(w, http.StatusOK, map[string]any{"data": nil, "errors": errs})
+	})
+}
+
+func (s *Server) addFault(n int, graphql bool, apply func(w http.ResponseWriter)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.faults = append(s.faults, &fault{remaining: n, graphql: graphql, apply: apply})
+}
+
+// nextFault returns the first pending fault matching the request, consuming one use of it.
+func (s *Server) nextFault(graphql bool) *fault {
+	for _, f := range s.faults {
+		if f.remaining > 0 && (!f.graphql || graphql) {
+			f.remaining--
+			return f
+		}
+	}
+	return nil
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests++
+
+	if s.scopes != nil {
+		w.Header().Set("X-OAuth-Scopes", strings.Join(s.scopes, ", "))
+	}
+	if !s.expiration.IsZero() {
+		w.Header().Set("GitHub-Authentication-Token-Expiration", s.expiration.UTC().Format("2006-01-02 15:04:05 MST"))
+	}
+	isGraphQL := r.URL.Path == "/graphql"
+	if f := s.nextFault(isGraphQL); f != nil {
+		f.apply(w)
+		return
+	}
+	if permission, level := restPermission(r); s.grants != nil && permission != "" && !granted(s.grants[permission], level) {
+		w.Header().Set("X-Accepted-GitHub-Permissions", permission+"="+level)
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Resource not accessible by personal access token"})
+		return
+	}
+
+	switch {
+	case isGraphQL && r.Method == http.MethodPost:
+		s.handleGraphQL(w, r)
+	case issuesPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.createIssue(w, r)
+	case issuesPath.MatchString(r.URL.Path) && r.Method == http.MethodGet:
+		s.listIssues(w, r)
+	case issuePath.MatchString(r.URL.Path) && r.Method == http.MethodGet:
+		s.getIssue(w, r)
+	case issuePath.MatchString(r.URL.Path) && r.Method == http.MethodPatch:
+		s.editIssue(w, r)
+	case subIssuesPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.addSubIssue(w, r)
+	case commentsPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.addComment(w, r)
+	case refPath.MatchString(r.URL.Path) && r.Method == http.MethodGet:
+		s.getRef(w, r)
+	case refsPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.createRef(w, r)
+	case contentsPath.MatchString(r.URL.Path) && r.Method == http.MethodPut:
+		s.putContents(w, r)
+	case pullsPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.createPull(w, r)
+	case milestonesPath.MatchString(r.URL.Path) && r.Method == http.MethodGet:
+		s.listMilestones(w, r)
+	case repoPath.MatchString(r.URL.Path) && r.Method == http.MethodGet:
+		s.getRepo(w, r)
+	case repoPath.MatchString(r.URL.Path) && r.Method == http.MethodDelete:
+		s.deleteRepo(w, r)
+	case createRepoPath.MatchString(r.URL.Path) && r.Method == http.MethodPost:
+		s.createRepo(w, r)
+	case r.URL.Path == "/user" && r.Method == http.MethodGet:
+		writeJSON(w, http.StatusOK, map[string]any{"login": s.owner, "node_id": ownerNodeID})
+	default:
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+	}
+}
+
+// restPermission returns the fine-grained permission needed by a REST request, with its level
+// (read or write), or an empty permission when none is enforced.
+func restPermission(r *http.Request) (string, string) {
+	level := "write"
+	if r.Method == http.MethodGet {
+		level = "read"
+	}
+	path := r.URL.Path
+	switch {
+	case issuesPath.MatchString(path), issuePath.MatchString(path), subIssuesPath.MatchString(path),
+		commentsPath.MatchString(path), milestonesPath.MatchString(path):
+		return "issues", level
+	case refPath.MatchString(path), refsPath.MatchString(path), contentsPath.MatchString(path):
+		return "contents", level
+	case pullsPath.MatchString(path):
+		return "pull_requests", level
+	}
+	return "", ""
+}
+
+// granted reports whether a permission granted at a level allows the needed level.
+func granted(level, needed string) bool {
+	return level == "write" || (level == "read" && needed == "read")
+}
+
+func (s *Server) getRepo(w http.ResponseWriter, r *http.Request) {
+	m := repoPath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	repo := s.repoJSON(m[2])
+	repo["has_issues"] = s.hasIssues
+	if s.permissions != nil {
+		repo["permissions"] = s.permissions
+	}
+	writeJSON(w, http.StatusOK, repo)
+}
+
+func (s *Server) repoJSON(name string) map[string]any {
+	return map[string]any{"node_id": repoNodeID, "owner": map[string]any{"login": s.owner, "node_id": ownerNodeID}, "name": name, "full_name": s.owner + "/" + name, "default_branch": defaultBranch, "private": false}
+}
+
+// createRepo creates a repository of the owner, as the authenticated user or as its organization.
+// The new repository shares the issues of the fake repository.
+func (s *Server) createRepo(w http.ResponseWriter, r *http.Request) {
+	m := createRepoPath.FindStringSubmatch(r.URL.Path)
+	if m[1] != "" && m[1] != s.owner {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+		return
+	}
+	if req.Name == "" || req.Name == s.repo || s.created[req.Name] {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Repository creation failed."})
+		return
+	}
+	s.created[req.Name] = true
+	writeJSON(w, http.StatusCreated, s.repoJSON(req.Name))
+}
+
+// deleteRepo deletes a repository created through the API. The fake repository cannot be deleted.
+func (s *Server) deleteRepo(w http.ResponseWriter, r *http.Request) {
+	m := repoPath.FindStringSubmatch(r.URL.Path)
+	if m[1] != s.owner || !s.created[m[2]] {
+		writeJSON(w, http.StatusForbidden, map[string]string{"message": "Must have admin rights to Repository."})
+		return
+	}
+	delete(s.created, m[2])
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// checkRepo reports whether the path targets the fake repository or one created through the API,
+// writing a 404 otherwise.
+func (s *Server) checkRepo(w http.ResponseWriter, m []string) bool {
+	if len(m) < 3 || m[1] != s.owner || (m[2] != s.repo && !s.created[m[2]]) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return false
+	}
+	return true
+}
+
+func (s *Server) findIssue(number int) *Issue {
+	for _, i := range s.issues {
+		if i.Number == number {
+			return i
+		}
+	}
+	return nil
+}
+
+func (s *Server) createIssue(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRepo(w, issuesPath.FindStringSubmatch(r.URL.Path)) {
+		return
+	}
+	var req struct {
+		Title     string   `json:"title"`
+		Body      string   `json:"body"`
+		Labels    []string `json:"labels"`
+		Assignees []string `json:"assignees"`
+		Milestone int      `json:"milestone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Title == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]any{
+			"message": "Validation Failed",
+			"errors":  []map[string]string{{"resource": "Issue", "field": "title", "code": "missing_field"}},
+		})
+		return
+	}
+	s.numbers++
+	n := s.numbers
+	issue := &Issue{Number: n, ID: int64(1000 + n), NodeID: fmt.Sprintf("I_%d", n), Title: req.Title, Body: req.Body, Labels: req.Labels,
+		Assignees: req.Assignees, Milestone: req.Milestone, State: "open"}
+	s.issues = append(s.issues, issue)
+	writeJSON(w, http.StatusCreated, s.issueJSON(issue))
+}
+
+// listIssues answers the issues of the repository, filtered by the labels (all of them), the state
+// (open by default) and the milestone number query parameters, in a single page.
+func (s *Server) listIssues(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRepo(w, issuesPath.FindStringSubmatch(r.URL.Path)) {
+		return
+	}
+	state := r.URL.Query().Get("state")
+	if state == "" {
+		state = "open"
+	}
+	var labels []string
+	if value := r.URL.Query().Get("labels"); value != "" {
+		labels = strings.Split(value, ",")
+	}
+	milestone := r.URL.Query().Get("milestone")
+	result := []map[string]any{}
+	for _, issue := range s.issues {
+		if milestone != "" && milestone != strconv.Itoa(issue.Milestone) {
+			continue
+		}
+		if (state == "all" || issue.State == state) && hasLabels(issue, labels) {
+			result = append(result, s.issueJSON(issue))
+		}
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// listMilestones answers the milestones of the repository, all open, in a single page.
+func (s *Server) listMilestones(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRepo(w, milestonesPath.FindStringSubmatch(r.URL.Path)) {
+		return
+	}
+	result := []map[string]any{}
+	for i, title := range s.milestones {
+		result = append(result, map[string]any{"number": i + 1, "title": title, "state": "open"})
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// hasLabels reports whether the issue has all the labels.
+func hasLabels(issue *Issue, labels []string) bool {
+	for _, want := range labels {
+		found := false
+		for _, l := range issue.Labels {
+			found = found || l == want
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// branchSHA is the fake commit SHA of a branch.
+func branchSHA(branch string) string {
+	return fmt.Sprintf("%040x", len(branch))
+}
+
+func (s *Server) getRef(w http.ResponseWriter, r *http.Request) {
+	m := refPath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	if _, ok := s.branches[m[3]]; !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ref": "refs/heads/" + m[3], "object": map[string]string{"sha": branchSHA(m[3]), "type": "commit"}})
+}
+
+func (s *Server) createRef(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRepo(w, refsPath.FindStringSubmatch(r.URL.Path)) {
+		return
+	}
+	var req struct {
+		Ref string `json:"ref"`
+		SHA string `json:"sha"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || !strings.HasPrefix(req.Ref, "refs/heads/") || req.SHA == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+	branch := strings.TrimPrefix(req.Ref, "refs/heads/")
+	if _, ok := s.branches[branch]; ok {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Reference already exists"})
+		return
+	}
+	s.branches[branch] = map[string]string{}
+	writeJSON(w, http.StatusCreated, map[string]any{"ref": req.Ref, "object": map[string]string{"sha": req.SHA, "type": "commit"}})
+}
+
+func (s *Server) putContents(w http.ResponseWriter, r *http.Request) {
+	m := contentsPath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	var req struct {
+		Message string `json:"message"`
+		Content []byte `json:"content"` // base64 encoded, decoded by encoding/json
+		Branch  string `json:"branch"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Message == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+	if req.Branch == "" {
+		req.Branch = defaultBranch
+	}
+	files, ok := s.branches[req.Branch]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Branch not found"})
+		return
+	}
+	files[m[3]] = string(req.Content)
+	writeJSON(w, http.StatusCreated, map[string]any{"content": map[string]string{"path": m[3]}, "commit": map[string]string{"sha": branchSHA(req.Branch)}})
+}
+
+func (s *Server) createPull(w http.ResponseWriter, r *http.Request) {
+	if !s.checkRepo(w, pullsPath.FindStringSubmatch(r.URL.Path)) {
+		return
+	}
+	var req struct {
+		Title string `json:"title"`
+		Body  string `json:"body"`
+		Head  string `json:"head"`
+		Base  string `json:"base"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Title == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+	_, headOK := s.branches[req.Head]
+	_, baseOK := s.branches[req.Base]
+	if !headOK || !baseOK {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed: head or base branch not found"})
+		return
+	}
+	s.numbers++
+	pull := &PullRequest{Number: s.numbers, Title: req.Title, Body: req.Body, Head: req.Head, Base: req.Base}
+	s.pulls = append(s.pulls, pull)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"number":   pull.Number,
+		"id":       int64(5000 + pull.Number),
+		"title":    pull.Title,
+		"body":     pull.Body,
+		"html_url": fmt.Sprintf("https://github.com/%s/%s/pull/%d", s.owner, s.repo, pull.Number),
+	})
+}
+
+func (s *Server) addComment(w http.ResponseWriter, r *http.Request) {
+	m := commentsPath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	n, _ := strconv.Atoi(m[3])
+	issue := s.findIssue(n)
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Body == "" {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+	issue.Comments = append(issue.Comments, req.Body)
+	writeJSON(w, http.StatusCreated, map[string]any{
+		"id":       len(issue.Comments),
+		"body":     req.Body,
+		"html_url": fmt.Sprintf("https://github.com/%s/%s/issues/%d#issuecomment-%d", s.owner, s.repo, n, len(issue.Comments)),
+	})
+}
+
+func (s *Server) getIssue(w http.ResponseWriter, r *http.Request) {
+	m := issuePath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	n, _ := strconv.Atoi(m[3])
+	issue := s.findIssue(n)
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, s.issueJSON(issue))
+}
+
+func (s *Server) editIssue(w http.ResponseWriter, r *http.Request) {
+	m := issuePath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	n, _ := strconv.Atoi(m[3])
+	issue := s.findIssue(n)
+	if issue == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"message": "Not Found"})
+		return
+	}
+	var req struct {
+		Title       *string   `json:"title"`
+		Body        *string   `json:"body"`
+		State       *string   `json:"state"`
+		StateReason *string   `json:"state_reason"`
+		Labels      *[]string `json:"labels"`
+		Milestone   *int      `json:"milestone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+		return
+	}
+	if req.Title != nil {
+		issue.Title = *req.Title
+	}
+	if req.Body != nil {
+		issue.Body = *req.Body
+	}
+	if req.State != nil {
+		issue.State = *req.State
+	}
+	if req.StateReason != nil {
+		issue.StateReason = *req.StateReason
+	}
+	if req.Labels != nil {
+		issue.Labels = *req.Labels
+	}
+	if req.Milestone != nil {
+		issue.Milestone = *req.Milestone
+	}
+	writeJSON(w, http.StatusOK, s.issueJSON(issue))
+}
+
+func (s *Server) addSubIssue(w http.ResponseWriter, r *http.Request) {
+	m := subIssuesPath.FindStringSubmatch(r.URL.Path)
+	if !s.checkRepo(w, m) {
+		return
+	}
+	parent, _ := strconv.Atoi(m[3])
+	var req struct {
+		SubIssueID int64 `json:"sub_issue_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || s.findIssue(parent) == nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"message": "Validation Failed"})
+		return
+	}
+	s.subIssues[parent] = append(s.subIssues[parent], req.SubIssueID)
+	writeJSON(w, http.StatusCreated, s.issueJSON(s.findIssue(parent)))
+}
+
+func (s *Server) issueJSON(i *Issue) map[string]any {
+	labels := make([]map[string]string, 0, len(i.Labels))
+	for _, l := range i.Labels {
+		labels = append(labels, map[string]string{"name": l})
+	}
+	result := map[string]any{
+		"number":   i.Number,
+		"id":       i.ID,
+		"node_id":  i.NodeID,
+		"title":    i.Title,
+		"body":     i.Body,
+		"state":    i.State,
+		"labels":   labels,
+		"html_url": fmt.Sprintf("https://github.com/%s/%s/issues/%d", s.owner, s.repo, i.Number),
+	}
+	if i.Milestone > 0 && i.Milestone <= len(s.milestones) {
+		result["milestone"] = map[string]any{"number": i.Milestone, "title": s.milestones[i.Milestone-1]}
+	}
+	return result
+}
+
+// handleGraphQL answers the GraphQL operations used by the GitHub provider, dispatching on the query text.
+func (s *Server) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Query     string         `json:"query"`
+		Variables map[string]any `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"message": "Problems parsing JSON"})
+		return
+	}
+	if query := strings.ToLower(req.Query); s.grants != nil && (strings.Contains(query, "projectv2") || strings.Contains(query, "projectsv2")) {
+		level := "write"
+		if !strings.HasPrefix(strings.TrimSpace(req.Query), "mutation") {
+			level = "read"
+		}
+		if !granted(s.grants["organization_projects"], level) {
+			writeGraphQLError(w, "FORBIDDEN", "Resource not accessible by personal access token")
+			return
+		}
+	}
+
+	switch {
+	case strings.Contains(req.Query, "addProjectV2DraftIssue"):
+		s.graphqlAddDraftIssue(w, req.Variables)
+	case strings.Contains(req.Query, "deleteProjectV2"):
+		s.graphqlDeleteProject(w, req.Variables)
+	case strings.Contains(req.Query, "createProjectV2"):
+		s.graphqlCreateProject(w, req.Variables)
+	case strings.Contains(req.Query, "addProjectV2ItemById"):
+		s.graphqlAddProjectItem(w, req.Variables)
+	case strings.Contains(req.Query, "fields(first:"):
+		s.graphqlProjectFields(w, req.Variables)
+	case strings.Contains(req.Query, "items(first:"):
+		s.graphqlProjectItems(w, req.Variables)
+	case strings.Contains(req.Query, "projectsV2("):
+		s.graphqlProjects(w)
+	case strings.Contains(req.Query, "issue(number:"):
+		s.graphqlIssue(w, req.Variables)
+	default:
+		writeGraphQLError(w, "UNKNOWN_OPERATION", "operation not supported by the fake server")
+	}
+}
+
+func (s *Server) graphqlProjects(w http.ResponseWriter) {
+	nodes := make([]map[string]any, 0, len(s.projects))
+	for _, p := range s.projects {
+		nodes = append(nodes, map[string]any{"id": p.ID, "number": p.Number, "title": p.Title})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+		"repositoryOwner": map[string]any{"projectsV2": map[string]any{"nodes": nodes, "totalCount": len(nodes)}},
+	}})
+}
+
+func (s *Server) graphqlIssue(w http.ResponseWriter, vars map[string]any) {
+	number, _ := vars["number"].(float64)
+	issue := s.findIssue(int(number))
+	if issue == nil {
+		writeGraphQLError(w, "NOT_FOUND", fmt.Sprintf("Could not resolve to an Issue with the number of %d.", int(number)))
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+		"repository": map[string]any{"issue": map[string]any{"id": issue.NodeID, "number": issue.Number, "title": issue.Title}},
+	}})
+}
+
+// graphqlProjectItems answers the items of a project, in a single page, with their field values.
+func (s *Server) graphqlProjectItems(w http.ResponseWriter, vars map[string]any) {
+	projectID, _ := vars["projectId"].(string)
+	for _, p := range s.projects {
+		if p.ID != projectID {
+			continue
+		}
+		nodes := []map[string]any{}
+		for _, nodeID := range p.Items {
+			for _, i := range s.issues {
+				if i.NodeID != nodeID {
+					continue
+				}
+				values := []map[string]any{}
+				for name, value := range p.Fields[nodeID] {
+					field := map[string]any{"field": map[string]any{"name": name}}
+					if text, ok := value.(string); ok {
+						field["name"] = text
+					} else {
+						field["number"] = value
+					}
+					values = append(values, field)
+				}
+				labels := []map[string]string{}
+				for _, l := range i.Labels {
+					labels = append(labels, map[string]string{"name": l})
+				}
+				nodes = append(nodes, map[string]any{
+					"fieldValues": map[string]any{"nodes": values},
+					"content": map[string]any{
+						"number": i.Number,
+						"title":  i.Title,
+						"url":    fmt.Sprintf("https://github.com/%s/%s/issues/%d", s.owner, s.repo, i.Number),
+						"state":  strings.ToUpper(i.State),
+						"labels": map[string]any{"nodes": labels},
+					},
+				})
+			}
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"node": map[string]any{"items": map[string]any{
+			"nodes":    nodes,
+			"pageInfo": map[string]any{"hasNextPage": false, "endCursor": ""},
+		}}}})
+		return
+	}
+	writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+projectID+"'")
+}
+
+func (s *Server) graphqlAddProjectItem(w http.ResponseWriter, vars map[string]any) {
+	projectID, _ := vars["projectId"].(string)
+	contentID, _ := vars["contentId"].(string)
+	for _, p := range s.projects {
+		if p.ID != projectID {
+			continue
+		}
+		for _, i := range s.issues {
+			if i.NodeID == contentID {
+				p.Items = append(p.Items, contentID)
+				writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+					"addProjectV2ItemById": map[string]any{"item": map[string]any{
+						"id":      fmt.Sprintf("PVTI_%d", len(p.Items)),
+						"content": map[string]any{"number": i.Number, "title": i.Title},
+					}},
+				}})
+				return
+			}
+		}
+	}
+	writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+projectID+"'")
+}
+
+// graphqlProjectFields answers the fields of a project, the iteration fields with all their
+// iterations as current ones.
+func (s *Server) graphqlProjectFields(w http.ResponseWriter, vars map[string]any) {
+	projectID, _ := vars["projectId"].(string)
+	for _, p := range s.projects {
+		if p.ID != projectID {
+			continue
+		}
+		nodes := []map[string]any{}
+		for _, f := range p.Schema {
+			node := map[string]any{"id": f.ID, "name": f.Name, "dataType": f.DataType}
+			var options, iterations []map[string]any
+			for _, o := range f.Options {
+				if o.StartDate != "" {
+					iterations = append(iterations, map[string]any{"id": o.ID, "title": o.Name, "startDate": o.StartDate, "duration": o.Duration})
+				} else {
+					options = append(options, map[string]any{"id": o.ID, "name": o.Name})
+				}
+			}
+			switch f.DataType {
+			case "SINGLE_SELECT":
+				node["options"] = options
+			case "ITERATION":
+				node["configuration"] = map[string]any{"iterations": iterations, "completedIterations": []any{}}
+			}
+			nodes = append(nodes, node)
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{"node": map[string]any{"fields": map[string]any{"nodes": nodes}}}})
+		return
+	}
+	writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+projectID+"'")
+}
+
+// graphqlCreateProject creates a project of the repository owner, like AddProject.
+func (s *Server) graphqlCreateProject(w http.ResponseWriter, vars map[string]any) {
+	ownerID, _ := vars["ownerId"].(string)
+	title, _ := vars["title"].(string)
+	if ownerID != ownerNodeID {
+		writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+ownerID+"'")
+		return
+	}
+	p := s.newProject(title)
+	writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+		"createProjectV2": map[string]any{"projectV2": map[string]any{"id": p.ID, "number": p.Number, "title": p.Title}},
+	}})
+}
+
+func (s *Server) graphqlDeleteProject(w http.ResponseWriter, vars map[string]any) {
+	projectID, _ := vars["projectId"].(string)
+	for i, p := range s.projects {
+		if p.ID == projectID {
+			s.projects = append(s.projects[:i], s.projects[i+1:]...)
+			writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+				"deleteProjectV2": map[string]any{"projectV2": map[string]any{"id": p.ID}},
+			}})
+			return
+		}
+	}
+	writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+projectID+"'")
+}
+
+func (s *Server) graphqlAddDraftIssue(w http.ResponseWriter, vars map[string]any) {
+	projectID, _ := vars["projectId"].(string)
+	title, _ := vars["title"].(string)
+	body, _ := vars["body"].(string)
+	for _, p := range s.projects {
+		if p.ID != projectID {
+			continue
+		}
+		draft := DraftIssue{ID: fmt.Sprintf("PVTI_draft_%d", len(p.Drafts)+1), Title: title, Body: body}
+		p.Drafts = append(p.Drafts, draft)
+		writeJSON(w, http.StatusOK, map[string]any{"data": map[string]any{
+			"addProjectV2DraftIssue": map[string]any{"projectItem": map[string]any{
+				"id":      draft.ID,
+				"content": map[string]any{"id": fmt.Sprintf("DI_%d", len(p.Drafts)), "title": title},
+			}},
+		}})
+		return
+	}
+	writeGraphQLError(w, "NOT_FOUND", "Could not resolve to a node with the global id of '"+projectID+"'")
+}
+
+func writeGraphQLError(w http.ResponseWriter, errorType, message string) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"data":   nil,
+		"errors": []map[string]string{{"type": errorType, "message": message}},
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,158 @@
+package githubtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func post(t *testing.T, url string, body any) (*http.Response, map[string]any) {
+	data, err := json.Marshal(body)
+	require.NoError(t, err)
+	resp, err := http.Post(url, "application/json", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var decoded map[string]any
+	_ = json.Unmarshal(raw, &decoded)
+	return resp, decoded
+}
+
+// TestServer_ListIssuesAndComments tests the issue list filters and the comment endpoint.
+func TestServer_ListIssuesAndComments(t *testing.T) {
+	s := NewServer("owner", "repo")
+	defer s.Close()
+	post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Historia", "labels": []string{"lang:es", "User Story"}})
+	post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story", "labels": []string{"User Story"}})
+
+	resp, err := http.Get(s.URL + "/repos/owner/repo/issues?labels=lang:es,User+Story")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var issues []map[string]any
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&issues))
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Historia", issues[0]["title"])
+
+	resp2, _ := post(t, s.URL+"/repos/owner/repo/issues/1/comments", map[string]any{"body": "Translation"})
+	assert.Equal(t, http.StatusCreated, resp2.StatusCode)
+	assert.Equal(t, []string{"Translation"}, s.Issues()[0].Comments)
+}
+
+// TestServer_CreateIssueAndSubIssue tests the issue and sub-issue endpoints.
+func TestServer_CreateIssueAndSubIssue(t *testing.T) {
+	s := NewServer("owner", "repo")
+	defer s.Close()
+
+	resp, body := post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story", "labels": []string{"User Story"}})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, float64(1), body["number"])
+	assert.Equal(t, "I_1", body["node_id"])
+
+	post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Task"})
+	resp, _ = post(t, s.URL+"/repos/owner/repo/issues/1/sub_issues", map[string]any{"sub_issue_id": 1002})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []int64{1002}, s.SubIssues(1))
+
+	issues := s.Issues()
+	require.Len(t, issues, 2)
+	assert.Equal(t, []string{"User Story"}, issues[0].Labels)
+
+	resp, _ = post(t, s.URL+"/repos/other/repo/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp, _ = post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": ""})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+}
+
+// TestServer_GraphQLProjects tests the Project v2 GraphQL operations.
+func TestServer_GraphQLProjects(t *testing.T) {
+	s := NewServer("owner", "repo")
+	defer s.Close()
+	project := s.AddProject("Board")
+	post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story"})
+
+	_, body := post(t, s.URL+"/graphql", map[string]any{"query": "query { repositoryOwner { projectsV2(first: 100) { nodes { id } } } }"})
+	nodes := body["data"].(map[string]any)["repositoryOwner"].(map[string]any)["projectsV2"].(map[string]any)["nodes"].([]any)
+	require.Len(t, nodes, 1)
+
+	_, body = post(t, s.URL+"/graphql", map[string]any{
+		"query":     "mutation { addProjectV2ItemById }",
+		"variables": map[string]any{"projectId": project.ID, "contentId": "I_1"},
+	})
+	assert.Nil(t, body["errors"])
+	got, ok := s.Project("Board")
+	require.True(t, ok)
+	assert.Equal(t, []string{"I_1"}, got.Items)
+
+	_, body = post(t, s.URL+"/graphql", map[string]any{"query": "query { viewer { login } }"})
+	assert.NotNil(t, body["errors"])
+}
+
+// TestServer_Repositories tests creating and deleting repositories and deleting projects.
+func TestServer_Repositories(t *testing.T) {
+	s := NewServer("owner", "repo")
+	defer s.Close()
+
+	resp, body := post(t, s.URL+"/orgs/owner/repos", map[string]any{"name": "scratch"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "owner/scratch", body["full_name"])
+	resp, _ = post(t, s.URL+"/user/repos", map[string]any{"name": "scratch"})
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+	resp, _ = post(t, s.URL+"/repos/owner/scratch/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, []string{"scratch"}, s.Repositories())
+
+	for name, status := range map[string]int{"scratch": http.StatusNoContent, "repo": http.StatusForbidden} {
+		req, err := http.NewRequest(http.MethodDelete, s.URL+"/repos/owner/"+name, nil)
+		require.NoError(t, err)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, status, resp.StatusCode, name)
+	}
+	assert.Empty(t, s.Repositories())
+
+	project := s.AddProject("Scratch")
+	_, body = post(t, s.URL+"/graphql", map[string]any{"query": "mutation { deleteProjectV2 }", "variables": map[string]any{"projectId": project.ID}})
+	assert.Nil(t, body["errors"])
+	_, ok := s.Project("Scratch")
+	assert.False(t, ok)
+	assert.Equal(t, 2, s.AddProject("Board").Number, "project numbers must not be reused")
+}
+
+// TestServer_Faults tests that injected faults are applied to the next requests only.
+func TestServer_Faults(t *testing.T) {
+	s := NewServer("owner", "repo")
+	defer s.Close()
+
+	s.RateLimit(1)
+	resp, body := post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "0", resp.Header.Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "API rate limit exceeded", body["message"])
+
+	s.SecondaryRateLimit(1, 30*time.Second)
+	resp, _ = post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	assert.Equal(t, "30", resp.Header.Get("Retry-After"))
+
+	s.FailWithStatus(1, http.StatusBadGateway)
+	resp, _ = post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	s.GraphQLErrors(1, "FORBIDDEN", "Resource not accessible by integration")
+	resp, _ = post(t, s.URL+"/repos/owner/repo/issues", map[string]any{"title": "Story"})
+	assert.Equal(t, http.StatusCreated, resp.StatusCode, "GraphQL faults must not affect REST requests")
+	_, body = post(t, s.URL+"/graphql", map[string]any{"query": "query { repositoryOwner { projectsV2(first: 100) { nodes { id } } } }"})
+	errs := body["errors"].([]any)
+	assert.Equal(t, "FORBIDDEN", errs[0].(map[string]any)["type"])
+
+	assert.Len(t, s.Issues(), 1)
+	assert.Equal(t, 5, s.Requests())
+}